@@ -4,13 +4,16 @@ import (
 	"context"
 	"crypto/tls"
 	"fmt"
+	"maps"
 	"net"
 	"runtime"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/miekg/dns"
 	"github.com/pkg/errors"
 	"github.com/shirou/gopsutil/v4/host"
 	"github.com/shirou/gopsutil/v4/load"
@@ -222,6 +225,25 @@ func (sa *StorkAgent) Ping(ctx context.Context, in *agentapi.PingReq) (*agentapi
 	return &rsp, nil
 }
 
+// Returns the configuration files of the Kea daemons with the hashes of
+// their contents. The hash is empty when the file cannot be read.
+func getKeaConfigFiles(keaApp *KeaApp) (configFiles []*agentapi.DaemonConfigFile) {
+	daemonNames := maps.Keys(keaApp.ConfigFiles)
+	for _, daemonName := range slices.Sorted(daemonNames) {
+		configPath := keaApp.ConfigFiles[daemonName]
+		hash, err := hashKeaConfigFile(configPath)
+		if err != nil {
+			log.WithError(err).WithField("path", configPath).Warn("Cannot hash Kea configuration file")
+		}
+		configFiles = append(configFiles, &agentapi.DaemonConfigFile{
+			Daemon: daemonName,
+			Path:   configPath,
+			Hash:   hash,
+		})
+	}
+	return
+}
+
 // Get state of machine.
 func (sa *StorkAgent) GetState(ctx context.Context, in *agentapi.GetStateReq) (*agentapi.GetStateRsp, error) {
 	vm, _ := mem.VirtualMemory()
@@ -243,9 +265,20 @@ func (sa *StorkAgent) GetState(ctx context.Context, in *agentapi.GetStateReq) (*
 			})
 		}
 
+		var configFiles []*agentapi.DaemonConfigFile
+		var bind9Config string
+		switch concreteApp := app.(type) {
+		case *KeaApp:
+			configFiles = getKeaConfigFiles(concreteApp)
+		case *Bind9App:
+			bind9Config = concreteApp.config
+		}
+
 		apps = append(apps, &agentapi.App{
 			Type:         app.GetBaseApp().Type,
 			AccessPoints: accessPoints,
+			ConfigFiles:  configFiles,
+			Bind9Config:  bind9Config,
 		})
 	}
 
@@ -555,6 +588,127 @@ func (sa *StorkAgent) ReceiveZones(req *agentapi.ReceiveZonesReq, server grpc.Se
 	return nil
 }
 
+// Transfers a DNS zone from a specified DNS server and returns its
+// resource records over the stream. The records are sent in chunks
+// as they are received from the DNS server.
+func (sa *StorkAgent) ReceiveZoneRRs(req *agentapi.ReceiveZoneRRsReq, server grpc.ServerStreamingServer[agentapi.ReceiveZoneRRsRsp]) error {
+	appI := sa.AppMonitor.GetApp(AppTypeBind9, AccessPointControl, req.ControlAddress, req.ControlPort)
+	var client *zoneTransferClient
+	switch app := appI.(type) {
+	case *Bind9App:
+		client = app.zoneTransferClient
+	default:
+		return status.New(codes.InvalidArgument, "attempted to transfer DNS zone from an unsupported app").Err()
+	}
+	if client == nil {
+		return status.New(codes.FailedPrecondition, "attempted to transfer DNS zone from an app for which zone transfer client was not instantiated").Err()
+	}
+	for rrs, err := range client.transferZone(server.Context(), req.ViewName, req.ZoneName) {
+		if err != nil {
+			return status.Error(codes.Unavailable, err.Error())
+		}
+		response := &agentapi.ReceiveZoneRRsRsp{}
+		for _, rr := range rrs {
+			response.Rrs = append(response.Rrs, rr.String())
+		}
+		if err = server.Send(response); err != nil {
+			return status.New(codes.Aborted, err.Error()).Err()
+		}
+	}
+	return nil
+}
+
+// Sends a dynamic update of a DNS zone to a specified DNS server. The
+// specified resource records are deleted and then the new records are
+// added in a single update. The update is signed with a key found in the
+// DNS server's configuration. The update errors are returned in the
+// response status.
+func (sa *StorkAgent) UpdateZone(ctx context.Context, req *agentapi.UpdateZoneReq) (*agentapi.UpdateZoneRsp, error) {
+	appI := sa.AppMonitor.GetApp(AppTypeBind9, AccessPointControl, req.ControlAddress, req.ControlPort)
+	var client *zoneUpdateClient
+	switch app := appI.(type) {
+	case *Bind9App:
+		client = app.zoneUpdateClient
+	default:
+		return nil, status.New(codes.InvalidArgument, "attempted to update DNS zone on an unsupported app").Err()
+	}
+	if client == nil {
+		return nil, status.New(codes.FailedPrecondition, "attempted to update DNS zone on an app for which zone update client was not instantiated").Err()
+	}
+	response := &agentapi.UpdateZoneRsp{
+		Status: &agentapi.Status{
+			Code: agentapi.Status_OK, // all ok
+		},
+	}
+	deleteRRs, err := parseZoneUpdateRRs(req.DeleteRRs)
+	if err == nil {
+		var addRRs []dns.RR
+		addRRs, err = parseZoneUpdateRRs(req.AddRRs)
+		if err == nil {
+			err = client.updateZone(ctx, req.ViewName, req.ZoneName, deleteRRs, addRRs)
+		}
+	}
+	if err != nil {
+		log.WithError(err).WithFields(log.Fields{
+			"zone": req.ZoneName,
+			"view": req.ViewName,
+		}).Error("Failed to update the zone")
+		response.Status.Code = agentapi.Status_ERROR
+		response.Status.Message = err.Error()
+	}
+	return response, nil
+}
+
+// Queries the resource records of the specified types at the apex of a DNS
+// zone on a specified DNS server. The query errors are returned in the
+// response status.
+func (sa *StorkAgent) QueryZoneRRs(ctx context.Context, req *agentapi.QueryZoneRRsReq) (*agentapi.QueryZoneRRsRsp, error) {
+	appI := sa.AppMonitor.GetApp(AppTypeBind9, AccessPointControl, req.ControlAddress, req.ControlPort)
+	var client *zoneTransferClient
+	switch app := appI.(type) {
+	case *Bind9App:
+		client = app.zoneTransferClient
+	default:
+		return nil, status.New(codes.InvalidArgument, "attempted to query DNS zone on an unsupported app").Err()
+	}
+	if client == nil {
+		return nil, status.New(codes.FailedPrecondition, "attempted to query DNS zone on an app for which zone transfer client was not instantiated").Err()
+	}
+	response := &agentapi.QueryZoneRRsRsp{
+		Status: &agentapi.Status{
+			Code: agentapi.Status_OK, // all ok
+		},
+	}
+	var (
+		rrTypes []uint16
+		err     error
+	)
+	for _, name := range req.RrTypes {
+		rrType, ok := dns.StringToType[strings.ToUpper(name)]
+		if !ok {
+			err = errors.Errorf("unsupported resource record type %s", name)
+			break
+		}
+		rrTypes = append(rrTypes, rrType)
+	}
+	if err == nil {
+		var rrs []dns.RR
+		rrs, err = client.queryZone(ctx, req.ViewName, req.ZoneName, rrTypes)
+		for _, rr := range rrs {
+			response.Rrs = append(response.Rrs, rr.String())
+		}
+	}
+	if err != nil {
+		log.WithError(err).WithFields(log.Fields{
+			"zone": req.ZoneName,
+			"view": req.ViewName,
+		}).Error("Failed to query the zone")
+		response.Status.Code = agentapi.Status_ERROR
+		response.Status.Message = err.Error()
+	}
+	return response, nil
+}
+
 // Starts the gRPC and HTTP listeners.
 func (sa *StorkAgent) Serve() error {
 	// Install gRPC API handlers.
@@ -13,16 +13,19 @@ import (
 	"testing"
 	"time"
 
+	"github.com/miekg/dns"
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/require"
 	gomock "go.uber.org/mock/gomock"
 	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/security/advancedtls"
 	"google.golang.org/grpc/status"
 	"gopkg.in/h2non/gock.v1"
 
 	"isc.org/stork"
 	agentapi "isc.org/stork/api"
+	keaconfig "isc.org/stork/appcfg/kea"
 	"isc.org/stork/appdata/bind9stats"
 	"isc.org/stork/hooks"
 	"isc.org/stork/testutil"
@@ -154,6 +157,40 @@ func TestPing(t *testing.T) {
 	require.NotNil(t, rsp)
 }
 
+// Test that the hashes of the Kea daemons' configuration files are
+// returned and that they match the hashes of the parsed configurations.
+func TestGetKeaConfigFiles(t *testing.T) {
+	sb := testutil.NewSandbox()
+	defer sb.Close()
+
+	configPath, err := sb.Write("kea-dhcp4.conf", `{
+		// Comments are allowed.
+		"Dhcp4": { "valid-lifetime": 4000 }
+	}`)
+	require.NoError(t, err)
+
+	keaApp := &KeaApp{
+		ConfigFiles: map[string]string{
+			"dhcp6": "/non/existing/kea-dhcp6.conf",
+			"dhcp4": configPath,
+		},
+	}
+	configFiles := getKeaConfigFiles(keaApp)
+	require.Len(t, configFiles, 2)
+
+	config, err := keaconfig.NewConfig(`{ "Dhcp4": { "valid-lifetime": 4000 } }`)
+	require.NoError(t, err)
+
+	require.Equal(t, "dhcp4", configFiles[0].Daemon)
+	require.Equal(t, configPath, configFiles[0].Path)
+	require.Equal(t, config.GetContentHash(), configFiles[0].Hash)
+
+	// The hash is empty when the file cannot be read.
+	require.Equal(t, "dhcp6", configFiles[1].Daemon)
+	require.Equal(t, "/non/existing/kea-dhcp6.conf", configFiles[1].Path)
+	require.Empty(t, configFiles[1].Hash)
+}
+
 // Check if GetState works.
 func TestGetState(t *testing.T) {
 	sa, ctx, teardown := setupAgentTest()
@@ -192,6 +229,7 @@ func TestGetState(t *testing.T) {
 			AccessPoints: accessPoints,
 		},
 		RndcClient: nil,
+		config:     "options { recursion no; };",
 	})
 	fam.Apps = apps
 	rsp, err = sa.GetState(ctx, &agentapi.GetStateReq{})
@@ -209,8 +247,10 @@ func TestGetState(t *testing.T) {
 	require.False(t, point.UseSecureProtocol)
 	require.EqualValues(t, 1234, point.Port)
 	require.Empty(t, point.Key)
+	require.Empty(t, keaApp.Bind9Config)
 
 	bind9App := rsp.Apps[1]
+	require.Equal(t, "options { recursion no; };", bind9App.Bind9Config)
 	require.Len(t, bind9App.AccessPoints, 2)
 	// sorted by port
 	point = bind9App.AccessPoints[0]
@@ -1348,3 +1388,363 @@ func TestReceiveZonesZoneInventoryBusy(t *testing.T) {
 	require.True(t, ok)
 	require.Equal(t, "ZONE_INVENTORY_BUSY_ERROR", info.Reason)
 }
+
+// Test successfully transferring the zone and returning its resource
+// records over the stream.
+func TestReceiveZoneRRs(t *testing.T) {
+	rrs := getTestZoneTransferRRs(t)
+	port, stop := startZoneTransferTestServer(t, rrs, nil)
+	defer stop()
+
+	sa, _, teardown := setupAgentTest()
+	defer teardown()
+
+	// Add a BIND9 app with the zone transfer client.
+	accessPoints := makeAccessPoint(AccessPointControl, "127.0.0.1", "key", 1234, false)
+	fam, _ := sa.AppMonitor.(*FakeAppMonitor)
+	fam.Apps = []App{
+		&Bind9App{
+			BaseApp: BaseApp{
+				Type:         AppTypeBind9,
+				AccessPoints: accessPoints,
+			},
+			zoneTransferClient: newZoneTransferClient("127.0.0.1", port, nil),
+		},
+	}
+
+	// Mock the streaming server.
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mock := NewMockServerStreamingServer[agentapi.ReceiveZoneRRsRsp](ctrl)
+	mock.EXPECT().Context().Return(context.Background())
+
+	// The records should be returned in two chunks as sent by the server.
+	var received []string
+	mock.EXPECT().Send(gomock.Any()).DoAndReturn(func(rsp *agentapi.ReceiveZoneRRsRsp) error {
+		received = append(received, rsp.Rrs...)
+		return nil
+	}).Times(2)
+
+	// Run the actual test.
+	err := sa.ReceiveZoneRRs(&agentapi.ReceiveZoneRRsReq{
+		ControlAddress: "127.0.0.1",
+		ControlPort:    1234,
+		ZoneName:       "example.com",
+		ViewName:       "_default",
+	}, mock)
+	require.NoError(t, err)
+	require.Len(t, received, len(rrs))
+	for i := range rrs {
+		require.Equal(t, rrs[i].String(), received[i])
+	}
+}
+
+// Test that an error is returned when the zone transfer fails.
+func TestReceiveZoneRRsTransferError(t *testing.T) {
+	rrs := getTestZoneTransferRRs(t)
+	port, stop := startZoneTransferTestServer(t, rrs, map[string]string{
+		"trusted-key.": "VO6xA4Tc1PWYaqMuPaf6wfkITb+c9/mkzlEaWJavejU=",
+	})
+	defer stop()
+
+	sa, _, teardown := setupAgentTest()
+	defer teardown()
+
+	// Add a BIND9 app with the zone transfer client. The request is not
+	// signed, so the server refuses it.
+	accessPoints := makeAccessPoint(AccessPointControl, "127.0.0.1", "key", 1234, false)
+	fam, _ := sa.AppMonitor.(*FakeAppMonitor)
+	fam.Apps = []App{
+		&Bind9App{
+			BaseApp: BaseApp{
+				Type:         AppTypeBind9,
+				AccessPoints: accessPoints,
+			},
+			zoneTransferClient: newZoneTransferClient("127.0.0.1", port, nil),
+		},
+	}
+
+	// Mock the streaming server.
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mock := NewMockServerStreamingServer[agentapi.ReceiveZoneRRsRsp](ctrl)
+	mock.EXPECT().Context().Return(context.Background())
+
+	// Run the actual test.
+	err := sa.ReceiveZoneRRs(&agentapi.ReceiveZoneRRsReq{
+		ControlAddress: "127.0.0.1",
+		ControlPort:    1234,
+		ZoneName:       "example.com",
+	}, mock)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to transfer zone example.com")
+	require.Equal(t, codes.Unavailable, status.Code(err))
+}
+
+// Test that an error is returned when the app has no zone transfer client.
+func TestReceiveZoneRRsNilZoneTransferClient(t *testing.T) {
+	sa, _, teardown := setupAgentTest()
+	defer teardown()
+
+	accessPoints := makeAccessPoint(AccessPointControl, "127.0.0.1", "key", 1234, false)
+	fam, _ := sa.AppMonitor.(*FakeAppMonitor)
+	fam.Apps = []App{
+		&Bind9App{
+			BaseApp: BaseApp{
+				Type:         AppTypeBind9,
+				AccessPoints: accessPoints,
+			},
+		},
+	}
+
+	// Mock the streaming server.
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mock := NewMockServerStreamingServer[agentapi.ReceiveZoneRRsRsp](ctrl)
+
+	// Run the actual test.
+	err := sa.ReceiveZoneRRs(&agentapi.ReceiveZoneRRsReq{
+		ControlAddress: "127.0.0.1",
+		ControlPort:    1234,
+		ZoneName:       "example.com",
+	}, mock)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "attempted to transfer DNS zone from an app for which zone transfer client was not instantiated")
+}
+
+// Test that an error is returned when the app is not a DNS server.
+func TestReceiveZoneRRsUnsupportedApp(t *testing.T) {
+	sa, _, teardown := setupAgentTest()
+	defer teardown()
+
+	accessPoints := makeAccessPoint(AccessPointControl, "127.0.0.1", "key", 1234, false)
+	fam, _ := sa.AppMonitor.(*FakeAppMonitor)
+	fam.Apps = []App{
+		&KeaApp{
+			BaseApp: BaseApp{
+				Type:         AppTypeKea,
+				AccessPoints: accessPoints,
+			},
+		},
+	}
+
+	// Mock the streaming server.
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mock := NewMockServerStreamingServer[agentapi.ReceiveZoneRRsRsp](ctrl)
+
+	// Run the actual test.
+	err := sa.ReceiveZoneRRs(&agentapi.ReceiveZoneRRsReq{
+		ControlAddress: "127.0.0.1",
+		ControlPort:    1234,
+		ZoneName:       "example.com",
+	}, mock)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "attempted to transfer DNS zone from an unsupported app")
+}
+
+// Test that the agent sends the zone update to the DNS server.
+func TestUpdateZoneRPC(t *testing.T) {
+	port, updates, stop := startZoneUpdateTestServer(t, map[string]string{
+		"update-key.": "VO6xA4Tc1PWYaqMuPaf6wfkITb+c9/mkzlEaWJavejU=",
+	}, dns.RcodeSuccess)
+	defer stop()
+
+	sa, _, teardown := setupAgentTest()
+	defer teardown()
+
+	accessPoints := makeAccessPoint(AccessPointControl, "127.0.0.1", "key", 1234, false)
+	fam, _ := sa.AppMonitor.(*FakeAppMonitor)
+	fam.Apps = []App{
+		&Bind9App{
+			BaseApp: BaseApp{
+				Type:         AppTypeBind9,
+				AccessPoints: accessPoints,
+			},
+			zoneUpdateClient: newZoneUpdateClient("127.0.0.1", port, getTestZoneUpdateConfig(t)),
+		},
+	}
+
+	rsp, err := sa.UpdateZone(context.Background(), &agentapi.UpdateZoneReq{
+		ControlAddress: "127.0.0.1",
+		ControlPort:    1234,
+		ZoneName:       "example.com",
+		ViewName:       "_default",
+		DeleteRRs:      []string{"www.example.com. 300 IN A 192.0.2.1"},
+		AddRRs:         []string{"www.example.com. 300 IN A 192.0.2.2"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, agentapi.Status_OK, rsp.Status.Code)
+
+	require.Len(t, updates, 1)
+	update := <-updates
+	require.Len(t, update.Ns, 2)
+
+	// Invalid record. The update should not be sent.
+	rsp, err = sa.UpdateZone(context.Background(), &agentapi.UpdateZoneReq{
+		ControlAddress: "127.0.0.1",
+		ControlPort:    1234,
+		ZoneName:       "example.com",
+		AddRRs:         []string{"www.example.com. 300 IN A foo"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, agentapi.Status_ERROR, rsp.Status.Code)
+	require.Contains(t, rsp.Status.Message, "failed to parse resource record")
+	require.Empty(t, updates)
+
+	// No key for the zone.
+	rsp, err = sa.UpdateZone(context.Background(), &agentapi.UpdateZoneReq{
+		ControlAddress: "127.0.0.1",
+		ControlPort:    1234,
+		ZoneName:       "example.org",
+		AddRRs:         []string{"www.example.org. 300 IN A 192.0.2.2"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, agentapi.Status_ERROR, rsp.Status.Code)
+	require.Contains(t, rsp.Status.Message, "no key allowed to update zone example.org")
+}
+
+// Test that an error is returned when the app has no zone update client.
+func TestUpdateZoneRPCNilZoneUpdateClient(t *testing.T) {
+	sa, _, teardown := setupAgentTest()
+	defer teardown()
+
+	accessPoints := makeAccessPoint(AccessPointControl, "127.0.0.1", "key", 1234, false)
+	fam, _ := sa.AppMonitor.(*FakeAppMonitor)
+	fam.Apps = []App{
+		&Bind9App{
+			BaseApp: BaseApp{
+				Type:         AppTypeBind9,
+				AccessPoints: accessPoints,
+			},
+		},
+	}
+
+	_, err := sa.UpdateZone(context.Background(), &agentapi.UpdateZoneReq{
+		ControlAddress: "127.0.0.1",
+		ControlPort:    1234,
+		ZoneName:       "example.com",
+	})
+	require.Error(t, err)
+	require.Equal(t, codes.FailedPrecondition, status.Code(err))
+}
+
+// Test that an error is returned when the app is not a DNS server.
+func TestUpdateZoneRPCUnsupportedApp(t *testing.T) {
+	sa, _, teardown := setupAgentTest()
+	defer teardown()
+
+	accessPoints := makeAccessPoint(AccessPointControl, "127.0.0.1", "key", 1234, false)
+	fam, _ := sa.AppMonitor.(*FakeAppMonitor)
+	fam.Apps = []App{
+		&KeaApp{
+			BaseApp: BaseApp{
+				Type:         AppTypeKea,
+				AccessPoints: accessPoints,
+			},
+		},
+	}
+
+	_, err := sa.UpdateZone(context.Background(), &agentapi.UpdateZoneReq{
+		ControlAddress: "127.0.0.1",
+		ControlPort:    1234,
+		ZoneName:       "example.com",
+	})
+	require.Error(t, err)
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+// Test that the agent queries the zone records from the DNS server.
+func TestQueryZoneRRsRPC(t *testing.T) {
+	rrs := getTestZoneTransferRRs(t)
+	port, stop := startZoneQueryTestServer(t, rrs[:4], nil, true)
+	defer stop()
+
+	sa, _, teardown := setupAgentTest()
+	defer teardown()
+
+	accessPoints := makeAccessPoint(AccessPointControl, "127.0.0.1", "key", 1234, false)
+	fam, _ := sa.AppMonitor.(*FakeAppMonitor)
+	fam.Apps = []App{
+		&Bind9App{
+			BaseApp: BaseApp{
+				Type:         AppTypeBind9,
+				AccessPoints: accessPoints,
+			},
+			zoneTransferClient: newZoneTransferClient("127.0.0.1", port, nil),
+		},
+	}
+
+	rsp, err := sa.QueryZoneRRs(context.Background(), &agentapi.QueryZoneRRsReq{
+		ControlAddress: "127.0.0.1",
+		ControlPort:    1234,
+		ZoneName:       "example.com",
+		ViewName:       "_default",
+		RrTypes:        []string{"SOA", "dnskey"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, agentapi.Status_OK, rsp.Status.Code)
+	require.Equal(t, []string{rrs[0].String()}, rsp.Rrs)
+
+	// Invalid record type. The query should not be sent.
+	rsp, err = sa.QueryZoneRRs(context.Background(), &agentapi.QueryZoneRRsReq{
+		ControlAddress: "127.0.0.1",
+		ControlPort:    1234,
+		ZoneName:       "example.com",
+		RrTypes:        []string{"SOA", "FOO"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, agentapi.Status_ERROR, rsp.Status.Code)
+	require.Contains(t, rsp.Status.Message, "unsupported resource record type FOO")
+	require.Empty(t, rsp.Rrs)
+}
+
+// Test that an error is returned when the app has no zone transfer client.
+func TestQueryZoneRRsRPCNilZoneTransferClient(t *testing.T) {
+	sa, _, teardown := setupAgentTest()
+	defer teardown()
+
+	accessPoints := makeAccessPoint(AccessPointControl, "127.0.0.1", "key", 1234, false)
+	fam, _ := sa.AppMonitor.(*FakeAppMonitor)
+	fam.Apps = []App{
+		&Bind9App{
+			BaseApp: BaseApp{
+				Type:         AppTypeBind9,
+				AccessPoints: accessPoints,
+			},
+		},
+	}
+
+	_, err := sa.QueryZoneRRs(context.Background(), &agentapi.QueryZoneRRsReq{
+		ControlAddress: "127.0.0.1",
+		ControlPort:    1234,
+		ZoneName:       "example.com",
+	})
+	require.Error(t, err)
+	require.Equal(t, codes.FailedPrecondition, status.Code(err))
+}
+
+// Test that an error is returned when the app is not a DNS server.
+func TestQueryZoneRRsRPCUnsupportedApp(t *testing.T) {
+	sa, _, teardown := setupAgentTest()
+	defer teardown()
+
+	accessPoints := makeAccessPoint(AccessPointControl, "127.0.0.1", "key", 1234, false)
+	fam, _ := sa.AppMonitor.(*FakeAppMonitor)
+	fam.Apps = []App{
+		&KeaApp{
+			BaseApp: BaseApp{
+				Type:         AppTypeKea,
+				AccessPoints: accessPoints,
+			},
+		},
+	}
+
+	_, err := sa.QueryZoneRRs(context.Background(), &agentapi.QueryZoneRRsReq{
+		ControlAddress: "127.0.0.1",
+		ControlPort:    1234,
+		ZoneName:       "example.com",
+	})
+	require.Error(t, err)
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+}
@@ -11,6 +11,7 @@ import (
 
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
+	bind9config "isc.org/stork/appcfg/bind9"
 	storkutil "isc.org/stork/util"
 )
 
@@ -48,6 +49,13 @@ type Bind9App struct {
 	BaseApp
 	RndcClient    *RndcClient // to communicate with BIND 9 via rndc
 	zoneInventory *zoneInventory
+	// Client transferring the zones from the DNS server.
+	zoneTransferClient *zoneTransferClient
+	// Client sending the dynamic updates of the zones to the DNS server.
+	zoneUpdateClient *zoneUpdateClient
+	// Preprocessed configuration with the key secrets obscured. It is
+	// sent to the server to review the configuration.
+	config string
 }
 
 // Get base information about BIND 9 app.
@@ -413,6 +421,13 @@ func getStatisticsChannelFromBind9Config(text string) (statsAddress string, stat
 	return statsAddress, statsPort
 }
 
+// Replaces the key secrets in the BIND 9 configuration with asterisks, so
+// the configuration can be sent to the server without revealing them.
+func obscureBind9ConfigSecrets(text string) string {
+	pattern := regexp.MustCompile(`(secret\s+)"[^"]*"`)
+	return pattern.ReplaceAllString(text, `${1}"********"`)
+}
+
 // Determine executable using base named directory or system default paths.
 func determineBinPath(baseNamedDir, executable string, executor storkutil.CommandExecutor) (string, error) {
 	// look for executable in base named directory and sbin or bin subdirectory
@@ -653,6 +668,14 @@ func detectBind9App(match []string, cwd string, executor storkutil.CommandExecut
 		log.Warn("To fix this problem, please configure `statistics-channels` in named.conf and ensure Stork-agent is able to access it.")
 	}
 
+	// Parse the configuration to find the keys used to sign the zone
+	// transfer requests. The zones can be transferred from the views
+	// without keys when parsing fails.
+	parsedConfig, err := bind9config.Parse(prefixedBind9ConfPath, strings.NewReader(cfgText))
+	if err != nil {
+		log.WithError(err).Warnf("Cannot parse BIND 9 config file %s; zone transfers will not be signed", prefixedBind9ConfPath)
+	}
+
 	// determine rndc details
 	rndcClient := NewRndcClient(executor)
 	err = rndcClient.DetermineDetails(
@@ -674,8 +697,11 @@ func detectBind9App(match []string, cwd string, executor storkutil.CommandExecut
 			Type:         AppTypeBind9,
 			AccessPoints: accessPoints,
 		},
-		RndcClient:    rndcClient,
-		zoneInventory: inventory,
+		RndcClient:         rndcClient,
+		zoneInventory:      inventory,
+		zoneTransferClient: newZoneTransferClient(ctrlAddress, DNSDefaultPort, parsedConfig),
+		zoneUpdateClient:   newZoneUpdateClient(ctrlAddress, DNSDefaultPort, parsedConfig),
+		config:             obscureBind9ConfigSecrets(cfgText),
 	}
 
 	return bind9App
@@ -303,6 +303,16 @@ func TestDetectBind9Step1ProcessCmdLine(t *testing.T) {
 	require.Equal(t, "1.1.1.1", point.Address)
 	require.EqualValues(t, 1111, point.Port)
 	require.EqualValues(t, "foo:hmac-sha256:abcd", point.Key)
+	// The configuration sent to the server must not reveal the secret.
+	require.Contains(t, app.config, `secret "********"`)
+	require.NotContains(t, app.config, "abcd")
+	// The zones are transferred from the DNS server listening on the
+	// control address. The parsed configuration holds the keys.
+	require.NotNil(t, app.zoneTransferClient)
+	require.Equal(t, "1.1.1.1", app.zoneTransferClient.address)
+	require.EqualValues(t, DNSDefaultPort, app.zoneTransferClient.port)
+	require.NotNil(t, app.zoneTransferClient.config)
+	require.NotNil(t, app.zoneTransferClient.config.GetKey("foo"))
 }
 
 // Checks detection with chroot STEP 1: if BIND9 detection takes -c parameter
@@ -832,6 +842,24 @@ func TestBind9RndcKeyString(t *testing.T) {
 	require.EqualValues(t, "foo:bar:baz", key.String())
 }
 
+// Test that the key secrets are obscured in the BIND 9 configuration.
+func TestObscureBind9ConfigSecrets(t *testing.T) {
+	config := `key "foo" {
+		algorithm "hmac-sha256";
+		secret "abcd";
+	};
+	key "bar" { algorithm hmac-md5; secret	"ef/gh=="; };
+	options { directory "/var/cache/bind"; };`
+
+	obscured := obscureBind9ConfigSecrets(config)
+	require.Equal(t, `key "foo" {
+		algorithm "hmac-sha256";
+		secret "********";
+	};
+	key "bar" { algorithm hmac-md5; secret	"********"; };
+	options { directory "/var/cache/bind"; };`, obscured)
+}
+
 // Test that the RNDC parameters are determined correctly if the RNDC key name
 // is not set and the default RNDC key exists.
 func TestDetermineDetailsUseDefaultKey(t *testing.T) {
@@ -26,6 +26,9 @@ type KeaApp struct {
 	// An empty list means that no daemons are running.
 	ActiveDaemons     []string
 	ConfiguredDaemons []string
+	// Paths to the configuration files of the Kea daemons detected from
+	// their command lines. The keys are the daemon names.
+	ConfigFiles map[string]string
 }
 
 // Get base information about Kea app.
@@ -220,6 +223,17 @@ func readKeaConfig(path string) (*keaconfig.Config, error) {
 	return config, err
 }
 
+// Computes a hash of the Kea daemon's configuration file contents. The server
+// uses the hash to detect when the configuration file changes and to compare
+// it with the hash of the configuration returned by the config-get command.
+func hashKeaConfigFile(path string) (string, error) {
+	config, err := readKeaConfig(path)
+	if err != nil {
+		return "", err
+	}
+	return config.GetContentHash(), nil
+}
+
 // Detect the Kea application by parsing the Kea CA process command line.
 // The match is a slice of: the full command line, the directory path of the
 // Kea CA executable, and the path to the Kea CA configuration file.
@@ -2,6 +2,7 @@ package agent
 
 import (
 	"fmt"
+	"path"
 	"regexp"
 	"slices"
 	"strings"
@@ -132,6 +133,14 @@ const (
 	namedProcName = "named"
 )
 
+// Names of the Kea daemons' processes mapped to the daemon names used
+// in the Kea Control Agent configuration.
+var keaDaemonProcNames = map[string]string{
+	"kea-dhcp4":     "dhcp4",
+	"kea-dhcp6":     "dhcp6",
+	"kea-dhcp-ddns": "d2",
+}
+
 // Creates an AppMonitor instance. It used to start it as well, but this is now done
 // by a dedicated method Start(). Make sure you call Start() before using app monitor.
 func NewAppMonitor() AppMonitor {
@@ -247,8 +256,12 @@ func (sm *appMonitor) detectApps(storkAgent *StorkAgent) {
 	// BIND 9 app is being detecting by browsing list of processes in the system
 	// where cmdline of the process contains given pattern with named substring.
 	bind9Pattern := regexp.MustCompile(`(.*?)named\s+(.*)`)
+	// The Kea daemons' configuration files are detected from their command
+	// lines. They are associated with the detected Kea apps.
+	keaDaemonPattern := regexp.MustCompile(`\s-c\s+(\S+)`)
 
 	var apps []App
+	keaConfigFiles := make(map[string]string)
 
 	processes, _ := sm.processManager.ListProcesses()
 
@@ -258,7 +271,9 @@ func (sm *appMonitor) detectApps(storkAgent *StorkAgent) {
 		cwd := ""
 		var err error
 
-		if procName == keaProcName || procName == namedProcName {
+		daemonName, isKeaDaemon := keaDaemonProcNames[procName]
+
+		if procName == keaProcName || procName == namedProcName || isKeaDaemon {
 			cmdline, err = p.GetCmdline()
 			if err != nil {
 				log.WithError(err).Warn("Cannot get process command line")
@@ -324,7 +339,31 @@ func (sm *appMonitor) detectApps(storkAgent *StorkAgent) {
 				}
 			}
 		default:
-			continue
+			if !isKeaDaemon {
+				continue
+			}
+			m := keaDaemonPattern.FindStringSubmatch(cmdline)
+			if m == nil {
+				continue
+			}
+			configPath := m[1]
+			if !strings.HasPrefix(configPath, "/") {
+				configPath = path.Join(cwd, configPath)
+			}
+			keaConfigFiles[daemonName] = configPath
+		}
+	}
+
+	// Associate the configuration files with the Kea apps configured
+	// to control the daemons.
+	for _, app := range apps {
+		if keaApp, ok := app.(*KeaApp); ok {
+			keaApp.ConfigFiles = make(map[string]string)
+			for _, daemonName := range keaApp.ConfiguredDaemons {
+				if configPath, ok := keaConfigFiles[daemonName]; ok {
+					keaApp.ConfigFiles[daemonName] = configPath
+				}
+			}
 		}
 	}
 
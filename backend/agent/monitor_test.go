@@ -257,6 +257,78 @@ func TestDetectApps(t *testing.T) {
 	require.NotEqual(t, apps[1].(*Bind9App).zoneInventory, apps3[1].(*Bind9App).zoneInventory)
 }
 
+// Test that the configuration files of the Kea daemons are detected and
+// associated with the Kea app controlling the daemons.
+func TestDetectAppsKeaConfigFiles(t *testing.T) {
+	// Arrange
+	sb := testutil.NewSandbox()
+	defer sb.Close()
+
+	keaConfPath, _ := sb.Write("kea-control-agent.conf", `{ "Control-agent": {
+		"http-host": "localhost",
+		"http-port": 45634,
+		"control-sockets": {
+			"dhcp4": {
+				"socket-type": "unix",
+				"socket-name": "/tmp/kea4-ctrl-socket"
+			},
+			"dhcp6": {
+				"socket-type": "unix",
+				"socket-name": "/tmp/kea6-ctrl-socket"
+			}
+		}
+	} }`)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	keaProcess := NewMockProcess(ctrl)
+	keaProcess.EXPECT().GetName().AnyTimes().Return("kea-ctrl-agent", nil)
+	keaProcess.EXPECT().GetCmdline().AnyTimes().Return(fmt.Sprintf(
+		"kea-ctrl-agent -c %s", keaConfPath,
+	), nil)
+	keaProcess.EXPECT().GetCwd().AnyTimes().Return("/etc/kea", nil)
+	keaProcess.EXPECT().GetPid().AnyTimes().Return(int32(1234))
+
+	// The configuration file path relative to the current working directory.
+	dhcp4Process := NewMockProcess(ctrl)
+	dhcp4Process.EXPECT().GetName().AnyTimes().Return("kea-dhcp4", nil)
+	dhcp4Process.EXPECT().GetCmdline().AnyTimes().Return("/usr/sbin/kea-dhcp4 -c kea-dhcp4.conf", nil)
+	dhcp4Process.EXPECT().GetCwd().AnyTimes().Return("/etc/kea", nil)
+
+	// The absolute configuration file path.
+	dhcp6Process := NewMockProcess(ctrl)
+	dhcp6Process.EXPECT().GetName().AnyTimes().Return("kea-dhcp6", nil)
+	dhcp6Process.EXPECT().GetCmdline().AnyTimes().Return("kea-dhcp6 -c /opt/kea/kea-dhcp6.conf", nil)
+	dhcp6Process.EXPECT().GetCwd().AnyTimes().Return("/", nil)
+
+	// The D2 is not configured in the Kea Control Agent.
+	d2Process := NewMockProcess(ctrl)
+	d2Process.EXPECT().GetName().AnyTimes().Return("kea-dhcp-ddns", nil)
+	d2Process.EXPECT().GetCmdline().AnyTimes().Return("kea-dhcp-ddns -c /etc/kea/kea-dhcp-ddns.conf", nil)
+	d2Process.EXPECT().GetCwd().AnyTimes().Return("/", nil)
+
+	processManager := NewMockProcessManager(ctrl)
+	processManager.EXPECT().ListProcesses().AnyTimes().Return([]Process{
+		dhcp4Process, keaProcess, dhcp6Process, d2Process,
+	}, nil)
+
+	am := &appMonitor{processManager: processManager, commander: newTestCommandExecutorDefault()}
+	sa := NewStorkAgent("foo", 42, am, NewBind9StatsClient(), HTTPClientConfig{}, NewHookManager(), "")
+
+	// Act
+	am.detectApps(sa)
+
+	// Assert
+	require.Len(t, am.apps, 1)
+	keaApp, ok := am.apps[0].(*KeaApp)
+	require.True(t, ok)
+	require.Equal(t, map[string]string{
+		"dhcp4": "/etc/kea/kea-dhcp4.conf",
+		"dhcp6": "/opt/kea/kea-dhcp6.conf",
+	}, keaApp.ConfigFiles)
+}
+
 // Test that the processes for which the command line cannot be read are
 // not skipped.
 func TestDetectAppsContinueOnNotAvailableCommandLine(t *testing.T) {
@@ -0,0 +1,186 @@
+package agent
+
+import (
+	"context"
+	"iter"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/pkg/errors"
+	bind9config "isc.org/stork/appcfg/bind9"
+)
+
+// Default port on which the DNS server responds to the DNS queries,
+// including the zone transfer requests.
+const DNSDefaultPort = 53
+
+// Timeout for establishing the connection and for reading the responses
+// during the zone transfer.
+const zoneTransferTimeout = 30 * time.Second
+
+// Validity of the TSIG signature in seconds (i.e., the permitted clock
+// skew between the agent and the DNS server).
+const zoneTransferTSIGFudge = 300
+
+// A client performing the zone transfers (AXFR) from the DNS server.
+//
+// If the zone belongs to a view associated with a key (via match-clients
+// clause), the transfer request is signed with this key (TSIG). The DNS
+// server uses the key to select the view from which the zone is returned.
+// The keys are read from the parsed DNS server's configuration.
+type zoneTransferClient struct {
+	address string
+	port    int64
+	config  *bind9config.Config
+	timeout time.Duration
+}
+
+// Instantiates the client transferring the zones from the DNS server
+// listening on the specified address and port. The configuration is
+// optional. If it is nil, the transfer requests are not signed.
+func newZoneTransferClient(address string, port int64, config *bind9config.Config) *zoneTransferClient {
+	return &zoneTransferClient{
+		address: address,
+		port:    port,
+		config:  config,
+		timeout: zoneTransferTimeout,
+	}
+}
+
+// Returns the name of the TSIG algorithm in the format expected by the DNS
+// library. The algorithm names in the BIND 9 configuration (e.g., hmac-sha256)
+// are not fully qualified. The hmac-md5 algorithm has a distinct name.
+func getTSIGAlgorithm(algorithm string) string {
+	algorithm = strings.ToLower(algorithm)
+	if algorithm == "hmac-md5" {
+		return dns.HmacMD5
+	}
+	return dns.Fqdn(algorithm)
+}
+
+// Returns the key used to sign the request to transfer the zone from the
+// specified view. It returns nil if the view is not associated with any key.
+func (client *zoneTransferClient) getViewKey(viewName string) (*bind9config.Key, error) {
+	if client.config == nil || viewName == "" {
+		return nil, nil
+	}
+	key, err := client.config.GetViewKey(viewName)
+	if err != nil {
+		return nil, errors.WithMessagef(err, "failed to find the key for view %s", viewName)
+	}
+	return key, nil
+}
+
+// Transfers the zone from the specified view of the DNS server. It returns
+// an iterator over the chunks of the resource records received from the
+// server. The iterator ends when an error occurs, the transfer completes or
+// the context is canceled.
+func (client *zoneTransferClient) transferZone(ctx context.Context, viewName, zoneName string) iter.Seq2[[]dns.RR, error] {
+	return func(yield func([]dns.RR, error) bool) {
+		key, err := client.getViewKey(viewName)
+		if err != nil {
+			_ = yield(nil, err)
+			return
+		}
+		request := &dns.Msg{}
+		request.SetAxfr(dns.Fqdn(zoneName))
+		transfer := &dns.Transfer{
+			DialTimeout: client.timeout,
+			ReadTimeout: client.timeout,
+		}
+		if key != nil {
+			algorithm, secret, err := key.GetAlgorithmSecret()
+			if err != nil {
+				_ = yield(nil, err)
+				return
+			}
+			keyName := dns.Fqdn(key.Name)
+			transfer.TsigSecret = map[string]string{keyName: secret}
+			request.SetTsig(keyName, getTSIGAlgorithm(algorithm), zoneTransferTSIGFudge, time.Now().Unix())
+		}
+		address := net.JoinHostPort(client.address, strconv.FormatInt(client.port, 10))
+		envelopes, err := transfer.In(request, address)
+		if err != nil {
+			_ = yield(nil, errors.Wrapf(err, "failed to transfer zone %s from %s", zoneName, address))
+			return
+		}
+		// The transfer runs in a goroutine that blocks until the envelopes
+		// are read. Drain the channel when returning early.
+		defer func() {
+			go func() {
+				for range envelopes {
+					// Discard the remaining envelopes.
+				}
+			}()
+		}()
+		for envelope := range envelopes {
+			if ctx.Err() != nil {
+				_ = yield(nil, errors.Wrapf(ctx.Err(), "transfer of zone %s from %s canceled", zoneName, address))
+				return
+			}
+			if envelope.Error != nil {
+				_ = yield(nil, errors.Wrapf(envelope.Error, "failed to transfer zone %s from %s", zoneName, address))
+				return
+			}
+			if !yield(envelope.RR, nil) {
+				return
+			}
+		}
+	}
+}
+
+// Queries the resource records of the specified types at the apex of the
+// zone in the specified view of the DNS server. The queries are sent over
+// TCP with the DNSSEC OK bit set, so the signatures and the DNSSEC records
+// are returned. Like the transfer requests, they are signed with the key
+// associated with the view. It returns an error if the server is not
+// authoritative for the zone or it responds with an error.
+func (client *zoneTransferClient) queryZone(ctx context.Context, viewName, zoneName string, rrTypes []uint16) ([]dns.RR, error) {
+	key, err := client.getViewKey(viewName)
+	if err != nil {
+		return nil, err
+	}
+	dnsClient := &dns.Client{
+		Net:     "tcp",
+		Timeout: client.timeout,
+	}
+	var (
+		keyName   string
+		algorithm string
+	)
+	if key != nil {
+		var secret string
+		algorithm, secret, err = key.GetAlgorithmSecret()
+		if err != nil {
+			return nil, err
+		}
+		keyName = dns.Fqdn(key.Name)
+		dnsClient.TsigSecret = map[string]string{keyName: secret}
+	}
+	address := net.JoinHostPort(client.address, strconv.FormatInt(client.port, 10))
+	var rrs []dns.RR
+	for _, rrType := range rrTypes {
+		request := &dns.Msg{}
+		request.SetQuestion(dns.Fqdn(zoneName), rrType)
+		request.SetEdns0(dns.DefaultMsgSize, true)
+		if key != nil {
+			request.SetTsig(keyName, getTSIGAlgorithm(algorithm), zoneTransferTSIGFudge, time.Now().Unix())
+		}
+		response, _, err := dnsClient.ExchangeContext(ctx, request, address)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to query %s records of zone %s from %s", dns.TypeToString[rrType], zoneName, address)
+		}
+		if response.Rcode != dns.RcodeSuccess {
+			return nil, errors.Errorf("DNS server %s rejected the query for %s records of zone %s: %s",
+				address, dns.TypeToString[rrType], zoneName, dns.RcodeToString[response.Rcode])
+		}
+		if !response.Authoritative {
+			return nil, errors.Errorf("DNS server %s is not authoritative for zone %s", address, zoneName)
+		}
+		rrs = append(rrs, response.Answer...)
+	}
+	return rrs, nil
+}
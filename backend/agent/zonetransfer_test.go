@@ -0,0 +1,291 @@
+package agent
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/require"
+	bind9config "isc.org/stork/appcfg/bind9"
+)
+
+// Returns a set of resource records of the example.com zone as returned
+// in the zone transfer, i.e., beginning and ending with the SOA record.
+func getTestZoneTransferRRs(t *testing.T) []dns.RR {
+	var rrs []dns.RR
+	for _, text := range []string{
+		"example.com. 3600 IN SOA ns1.example.com. admin.example.com. 2024031501 43200 3600 1209600 3600",
+		"example.com. 3600 IN NS ns1.example.com.",
+		"ns1.example.com. 3600 IN A 192.0.2.1",
+		"www.example.com. 300 IN AAAA 2001:db8::1",
+		"example.com. 3600 IN SOA ns1.example.com. admin.example.com. 2024031501 43200 3600 1209600 3600",
+	} {
+		rr, err := dns.NewRR(text)
+		require.NoError(t, err)
+		rrs = append(rrs, rr)
+	}
+	return rrs
+}
+
+// Starts a DNS server responding to the zone transfer requests with the
+// specified resource records. The records are sent in two chunks. If the
+// TSIG secrets are specified, the server refuses the unsigned requests.
+// It returns the port on which the server listens and a function stopping
+// the server.
+func startZoneTransferTestServer(t *testing.T, rrs []dns.RR, tsigSecrets map[string]string) (int64, func()) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	handler := dns.HandlerFunc(func(w dns.ResponseWriter, request *dns.Msg) {
+		if len(tsigSecrets) > 0 && (request.IsTsig() == nil || w.TsigStatus() != nil) {
+			response := &dns.Msg{}
+			response.SetRcode(request, dns.RcodeRefused)
+			_ = w.WriteMsg(response)
+			return
+		}
+		envelopes := make(chan *dns.Envelope)
+		go func() {
+			envelopes <- &dns.Envelope{RR: rrs[:2]}
+			envelopes <- &dns.Envelope{RR: rrs[2:]}
+			close(envelopes)
+		}()
+		_ = (&dns.Transfer{}).Out(w, request, envelopes)
+	})
+	started := make(chan struct{})
+	server := &dns.Server{
+		Listener:          listener,
+		Handler:           handler,
+		TsigSecret:        tsigSecrets,
+		NotifyStartedFunc: func() { close(started) },
+	}
+	go func() {
+		_ = server.ActivateAndServe()
+	}()
+	<-started
+	return int64(listener.Addr().(*net.TCPAddr).Port), func() {
+		_ = server.Shutdown()
+	}
+}
+
+// Parses the BIND 9 configuration with a view associated with a key.
+func getTestZoneTransferConfig(t *testing.T) *bind9config.Config {
+	config, err := bind9config.Parse("", strings.NewReader(`
+		key "trusted-key" {
+			algorithm hmac-sha256;
+			secret "VO6xA4Tc1PWYaqMuPaf6wfkITb+c9/mkzlEaWJavejU=";
+		};
+		view "trusted" {
+			match-clients { key "trusted-key"; };
+			zone "example.com" {
+				type primary;
+				file "/etc/bind/db.example.com";
+			};
+		};
+		view "guest" {
+			match-clients { any; };
+		};
+	`))
+	require.NoError(t, err)
+	return config
+}
+
+// Test converting the BIND 9 TSIG algorithm names.
+func TestGetTSIGAlgorithm(t *testing.T) {
+	require.Equal(t, dns.HmacSHA256, getTSIGAlgorithm("hmac-sha256"))
+	require.Equal(t, dns.HmacSHA512, getTSIGAlgorithm("HMAC-SHA512"))
+	require.Equal(t, dns.HmacMD5, getTSIGAlgorithm("hmac-md5"))
+}
+
+// Test transferring the zone without TSIG.
+func TestTransferZone(t *testing.T) {
+	rrs := getTestZoneTransferRRs(t)
+	port, stop := startZoneTransferTestServer(t, rrs, nil)
+	defer stop()
+
+	client := newZoneTransferClient("127.0.0.1", port, nil)
+	var received []dns.RR
+	for chunk, err := range client.transferZone(context.Background(), "_default", "example.com") {
+		require.NoError(t, err)
+		received = append(received, chunk...)
+	}
+	require.Len(t, received, len(rrs))
+	for i := range rrs {
+		require.Equal(t, rrs[i].String(), received[i].String())
+	}
+}
+
+// Test transferring the zone from the view associated with a key.
+func TestTransferZoneTSIG(t *testing.T) {
+	rrs := getTestZoneTransferRRs(t)
+	port, stop := startZoneTransferTestServer(t, rrs, map[string]string{
+		"trusted-key.": "VO6xA4Tc1PWYaqMuPaf6wfkITb+c9/mkzlEaWJavejU=",
+	})
+	defer stop()
+
+	client := newZoneTransferClient("127.0.0.1", port, getTestZoneTransferConfig(t))
+
+	var received []dns.RR
+	for chunk, err := range client.transferZone(context.Background(), "trusted", "example.com.") {
+		require.NoError(t, err)
+		received = append(received, chunk...)
+	}
+	require.Len(t, received, len(rrs))
+
+	// The view without a key. The server refuses the unsigned request.
+	var transferErr error
+	for _, err := range client.transferZone(context.Background(), "guest", "example.com") {
+		transferErr = err
+	}
+	require.ErrorContains(t, transferErr, "failed to transfer zone example.com")
+}
+
+// Test that stopping the iteration early does not block the transfer.
+func TestTransferZoneStopIteration(t *testing.T) {
+	rrs := getTestZoneTransferRRs(t)
+	port, stop := startZoneTransferTestServer(t, rrs, nil)
+	defer stop()
+
+	client := newZoneTransferClient("127.0.0.1", port, nil)
+	var received []dns.RR
+	for chunk, err := range client.transferZone(context.Background(), "", "example.com") {
+		require.NoError(t, err)
+		received = append(received, chunk...)
+		break
+	}
+	require.Len(t, received, 2)
+}
+
+// Test that an error is returned when the transfer context is canceled.
+func TestTransferZoneCanceled(t *testing.T) {
+	rrs := getTestZoneTransferRRs(t)
+	port, stop := startZoneTransferTestServer(t, rrs, nil)
+	defer stop()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	client := newZoneTransferClient("127.0.0.1", port, nil)
+	var transferErr error
+	for _, err := range client.transferZone(ctx, "", "example.com") {
+		transferErr = err
+	}
+	require.ErrorIs(t, transferErr, context.Canceled)
+}
+
+// Test that an error is returned when the DNS server is unreachable.
+func TestTransferZoneConnectionError(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	port := int64(listener.Addr().(*net.TCPAddr).Port)
+	listener.Close()
+
+	client := newZoneTransferClient("127.0.0.1", port, nil)
+	var transferErr error
+	for _, err := range client.transferZone(context.Background(), "", "example.com") {
+		transferErr = err
+	}
+	require.ErrorContains(t, transferErr, "failed to transfer zone example.com")
+}
+
+// Starts a DNS server responding to the queries with the specified resource
+// records of the matching type. The server responds authoritatively when
+// the authoritative flag is set. If the TSIG secrets are specified, the
+// server refuses the unsigned queries. It returns the port on which the
+// server listens and a function stopping the server.
+func startZoneQueryTestServer(t *testing.T, rrs []dns.RR, tsigSecrets map[string]string, authoritative bool) (int64, func()) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	handler := dns.HandlerFunc(func(w dns.ResponseWriter, request *dns.Msg) {
+		response := &dns.Msg{}
+		if len(tsigSecrets) > 0 && (request.IsTsig() == nil || w.TsigStatus() != nil) {
+			response.SetRcode(request, dns.RcodeRefused)
+			_ = w.WriteMsg(response)
+			return
+		}
+		response.SetReply(request)
+		response.Authoritative = authoritative
+		for _, rr := range rrs {
+			if rr.Header().Rrtype == request.Question[0].Qtype {
+				response.Answer = append(response.Answer, rr)
+			}
+		}
+		if tsig := request.IsTsig(); tsig != nil {
+			response.SetTsig(tsig.Hdr.Name, tsig.Algorithm, tsig.Fudge, int64(tsig.TimeSigned)) //nolint:gosec
+		}
+		_ = w.WriteMsg(response)
+	})
+	started := make(chan struct{})
+	server := &dns.Server{
+		Listener:          listener,
+		Handler:           handler,
+		TsigSecret:        tsigSecrets,
+		NotifyStartedFunc: func() { close(started) },
+	}
+	go func() {
+		_ = server.ActivateAndServe()
+	}()
+	<-started
+	return int64(listener.Addr().(*net.TCPAddr).Port), func() {
+		_ = server.Shutdown()
+	}
+}
+
+// Test querying the records at the zone apex.
+func TestQueryZone(t *testing.T) {
+	rrs := getTestZoneTransferRRs(t)
+	port, stop := startZoneQueryTestServer(t, rrs[:4], nil, true)
+	defer stop()
+
+	client := newZoneTransferClient("127.0.0.1", port, nil)
+	received, err := client.queryZone(context.Background(), "_default", "example.com", []uint16{dns.TypeSOA, dns.TypeNS, dns.TypeDNSKEY})
+	require.NoError(t, err)
+	require.Len(t, received, 2)
+	require.Equal(t, rrs[0].String(), received[0].String())
+	require.Equal(t, rrs[1].String(), received[1].String())
+}
+
+// Test querying the records of the zone in the view associated with a key.
+func TestQueryZoneTSIG(t *testing.T) {
+	rrs := getTestZoneTransferRRs(t)
+	port, stop := startZoneQueryTestServer(t, rrs[:4], map[string]string{
+		"trusted-key.": "VO6xA4Tc1PWYaqMuPaf6wfkITb+c9/mkzlEaWJavejU=",
+	}, true)
+	defer stop()
+
+	client := newZoneTransferClient("127.0.0.1", port, getTestZoneTransferConfig(t))
+	received, err := client.queryZone(context.Background(), "trusted", "example.com.", []uint16{dns.TypeSOA})
+	require.NoError(t, err)
+	require.Len(t, received, 1)
+	require.Equal(t, rrs[0].String(), received[0].String())
+
+	// The view without a key. The server refuses the unsigned query.
+	_, err = client.queryZone(context.Background(), "guest", "example.com", []uint16{dns.TypeSOA})
+	require.ErrorContains(t, err, "rejected the query for SOA records of zone example.com: REFUSED")
+}
+
+// Test that an error is returned when the server is not authoritative
+// for the zone.
+func TestQueryZoneNotAuthoritative(t *testing.T) {
+	rrs := getTestZoneTransferRRs(t)
+	port, stop := startZoneQueryTestServer(t, rrs[:4], nil, false)
+	defer stop()
+
+	client := newZoneTransferClient("127.0.0.1", port, nil)
+	_, err := client.queryZone(context.Background(), "", "example.com", []uint16{dns.TypeSOA})
+	require.ErrorContains(t, err, "is not authoritative for zone example.com")
+}
+
+// Test that an error is returned when the DNS server is unreachable.
+func TestQueryZoneConnectionError(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	port := int64(listener.Addr().(*net.TCPAddr).Port)
+	listener.Close()
+
+	client := newZoneTransferClient("127.0.0.1", port, nil)
+	_, err = client.queryZone(context.Background(), "", "example.com", []uint16{dns.TypeSOA})
+	require.ErrorContains(t, err, "failed to query SOA records of zone example.com")
+}
@@ -0,0 +1,114 @@
+package agent
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/pkg/errors"
+	bind9config "isc.org/stork/appcfg/bind9"
+)
+
+// Timeout for sending the dynamic update and receiving the response.
+const zoneUpdateTimeout = 30 * time.Second
+
+// A client sending the dynamic updates (RFC 2136) of the zones to the DNS
+// server.
+//
+// The updates are always signed with a key (TSIG) read from the parsed DNS
+// server's configuration. The key associated with the view (via
+// match-clients clause) is preferred because the DNS server uses it to
+// select the view. Otherwise, the key allowed to update the zone (via
+// allow-update clause) is used.
+type zoneUpdateClient struct {
+	address string
+	port    int64
+	config  *bind9config.Config
+	timeout time.Duration
+}
+
+// Instantiates the client updating the zones on the DNS server listening
+// on the specified address and port. The configuration is optional but
+// the updates fail without it because the key cannot be found.
+func newZoneUpdateClient(address string, port int64, config *bind9config.Config) *zoneUpdateClient {
+	return &zoneUpdateClient{
+		address: address,
+		port:    port,
+		config:  config,
+		timeout: zoneUpdateTimeout,
+	}
+}
+
+// Returns the key used to sign the update of the zone in the specified view.
+// It returns an error if the key is not found.
+func (client *zoneUpdateClient) getUpdateKey(viewName, zoneName string) (*bind9config.Key, error) {
+	if client.config == nil {
+		return nil, errors.Errorf("failed to find the key for updating zone %s; the DNS server configuration is not available", zoneName)
+	}
+	key, err := client.config.GetZoneUpdateKey(viewName, zoneName)
+	if err != nil {
+		return nil, errors.WithMessagef(err, "failed to find the key for updating zone %s in view %s", zoneName, viewName)
+	}
+	if key == nil {
+		return nil, errors.Errorf("no key allowed to update zone %s in view %s found in the DNS server configuration", zoneName, viewName)
+	}
+	return key, nil
+}
+
+// Parses the resource records in the presentation format.
+func parseZoneUpdateRRs(texts []string) ([]dns.RR, error) {
+	var rrs []dns.RR
+	for _, text := range texts {
+		rr, err := dns.NewRR(text)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to parse resource record %s", text)
+		}
+		if rr == nil {
+			return nil, errors.Errorf("resource record %s is empty", text)
+		}
+		rrs = append(rrs, rr)
+	}
+	return rrs, nil
+}
+
+// Sends the update of the zone in the specified view to the DNS server. The
+// specified records are deleted first, and then the new records are added.
+// The DNS server applies all of the changes or none of them. It returns an
+// error if the update fails or the server rejects it.
+func (client *zoneUpdateClient) updateZone(ctx context.Context, viewName, zoneName string, deleteRRs, addRRs []dns.RR) error {
+	key, err := client.getUpdateKey(viewName, zoneName)
+	if err != nil {
+		return err
+	}
+	algorithm, secret, err := key.GetAlgorithmSecret()
+	if err != nil {
+		return err
+	}
+	request := &dns.Msg{}
+	request.SetUpdate(dns.Fqdn(zoneName))
+	if len(deleteRRs) > 0 {
+		request.Remove(deleteRRs)
+	}
+	if len(addRRs) > 0 {
+		request.Insert(addRRs)
+	}
+	keyName := dns.Fqdn(key.Name)
+	request.SetTsig(keyName, getTSIGAlgorithm(algorithm), zoneTransferTSIGFudge, time.Now().Unix())
+
+	dnsClient := &dns.Client{
+		Net:        "tcp",
+		Timeout:    client.timeout,
+		TsigSecret: map[string]string{keyName: secret},
+	}
+	address := net.JoinHostPort(client.address, strconv.FormatInt(client.port, 10))
+	response, _, err := dnsClient.ExchangeContext(ctx, request, address)
+	if err != nil {
+		return errors.Wrapf(err, "failed to send the update of zone %s to %s", zoneName, address)
+	}
+	if response.Rcode != dns.RcodeSuccess {
+		return errors.Errorf("DNS server %s rejected the update of zone %s: %s", address, zoneName, dns.RcodeToString[response.Rcode])
+	}
+	return nil
+}
@@ -0,0 +1,161 @@
+package agent
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/require"
+	bind9config "isc.org/stork/appcfg/bind9"
+)
+
+// Starts a DNS server accepting the dynamic updates signed with the
+// specified TSIG secrets. The received updates are sent to the returned
+// channel. The server responds with the specified rcode. It returns the
+// port on which the server listens, the channel and a function stopping
+// the server.
+func startZoneUpdateTestServer(t *testing.T, tsigSecrets map[string]string, rcode int) (int64, chan *dns.Msg, func()) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	updates := make(chan *dns.Msg, 10)
+	handler := dns.HandlerFunc(func(w dns.ResponseWriter, request *dns.Msg) {
+		response := &dns.Msg{}
+		if request.IsTsig() == nil || w.TsigStatus() != nil {
+			response.SetRcode(request, dns.RcodeRefused)
+			_ = w.WriteMsg(response)
+			return
+		}
+		updates <- request
+		response.SetRcode(request, rcode)
+		tsig := request.IsTsig()
+		response.SetTsig(tsig.Hdr.Name, tsig.Algorithm, tsig.Fudge, int64(tsig.TimeSigned)) //nolint:gosec
+		_ = w.WriteMsg(response)
+	})
+	started := make(chan struct{})
+	server := &dns.Server{
+		Listener:          listener,
+		Handler:           handler,
+		TsigSecret:        tsigSecrets,
+		NotifyStartedFunc: func() { close(started) },
+		// The default function rejects the updates.
+		MsgAcceptFunc: func(dns.Header) dns.MsgAcceptAction {
+			return dns.MsgAccept
+		},
+	}
+	go func() {
+		_ = server.ActivateAndServe()
+	}()
+	<-started
+	return int64(listener.Addr().(*net.TCPAddr).Port), updates, func() {
+		_ = server.Shutdown()
+	}
+}
+
+// Parses the BIND 9 configuration with a zone allowing updates signed
+// with a key.
+func getTestZoneUpdateConfig(t *testing.T) *bind9config.Config {
+	config, err := bind9config.Parse("", strings.NewReader(`
+		key "update-key" {
+			algorithm hmac-sha256;
+			secret "VO6xA4Tc1PWYaqMuPaf6wfkITb+c9/mkzlEaWJavejU=";
+		};
+		zone "example.com" {
+			type primary;
+			file "/etc/bind/db.example.com";
+			allow-update { key "update-key"; };
+		};
+		zone "example.org" {
+			type primary;
+			file "/etc/bind/db.example.org";
+		};
+	`))
+	require.NoError(t, err)
+	return config
+}
+
+// Parses the resource records from the text.
+func parseTestRRs(t *testing.T, texts ...string) []dns.RR {
+	var rrs []dns.RR
+	for _, text := range texts {
+		rr, err := dns.NewRR(text)
+		require.NoError(t, err)
+		rrs = append(rrs, rr)
+	}
+	return rrs
+}
+
+// Test sending the signed update of the zone.
+func TestUpdateZone(t *testing.T) {
+	port, updates, stop := startZoneUpdateTestServer(t, map[string]string{
+		"update-key.": "VO6xA4Tc1PWYaqMuPaf6wfkITb+c9/mkzlEaWJavejU=",
+	}, dns.RcodeSuccess)
+	defer stop()
+
+	client := newZoneUpdateClient("127.0.0.1", port, getTestZoneUpdateConfig(t))
+	err := client.updateZone(context.Background(), "_default", "example.com",
+		parseTestRRs(t, "www.example.com. 300 IN A 192.0.2.1"),
+		parseTestRRs(t, "www.example.com. 300 IN A 192.0.2.2", "mail.example.com. 300 IN TXT \"hello\""),
+	)
+	require.NoError(t, err)
+
+	require.Len(t, updates, 1)
+	update := <-updates
+	require.Equal(t, dns.OpcodeUpdate, update.Opcode)
+	require.Len(t, update.Question, 1)
+	require.Equal(t, "example.com.", update.Question[0].Name)
+	require.Equal(t, dns.TypeSOA, update.Question[0].Qtype)
+
+	// The deleted record comes first.
+	require.Len(t, update.Ns, 3)
+	require.EqualValues(t, dns.ClassNONE, update.Ns[0].Header().Class)
+	require.Equal(t, "192.0.2.1", update.Ns[0].(*dns.A).A.String())
+	require.EqualValues(t, dns.ClassINET, update.Ns[1].Header().Class)
+	require.Equal(t, "192.0.2.2", update.Ns[1].(*dns.A).A.String())
+	require.Equal(t, []string{"hello"}, update.Ns[2].(*dns.TXT).Txt)
+
+	require.NotNil(t, update.IsTsig())
+	require.Equal(t, "update-key.", update.IsTsig().Hdr.Name)
+}
+
+// Test that an error is returned when the server rejects the update.
+func TestUpdateZoneRejected(t *testing.T) {
+	port, _, stop := startZoneUpdateTestServer(t, map[string]string{
+		"update-key.": "VO6xA4Tc1PWYaqMuPaf6wfkITb+c9/mkzlEaWJavejU=",
+	}, dns.RcodeNotZone)
+	defer stop()
+
+	client := newZoneUpdateClient("127.0.0.1", port, getTestZoneUpdateConfig(t))
+	err := client.updateZone(context.Background(), "", "example.com", nil,
+		parseTestRRs(t, "www.example.com. 300 IN A 192.0.2.2"))
+	require.ErrorContains(t, err, "rejected the update of zone example.com: NOTZONE")
+}
+
+// Test that the update is not sent when no key is allowed to update the
+// zone.
+func TestUpdateZoneNoKey(t *testing.T) {
+	client := newZoneUpdateClient("127.0.0.1", 53, getTestZoneUpdateConfig(t))
+	err := client.updateZone(context.Background(), "", "example.org", nil,
+		parseTestRRs(t, "www.example.org. 300 IN A 192.0.2.2"))
+	require.ErrorContains(t, err, "no key allowed to update zone example.org")
+
+	client = newZoneUpdateClient("127.0.0.1", 53, nil)
+	err = client.updateZone(context.Background(), "", "example.com", nil,
+		parseTestRRs(t, "www.example.com. 300 IN A 192.0.2.2"))
+	require.ErrorContains(t, err, "the DNS server configuration is not available")
+}
+
+// Test that an error is returned when the DNS server is unreachable.
+func TestUpdateZoneConnectionError(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	port := int64(listener.Addr().(*net.TCPAddr).Port)
+	listener.Close()
+
+	client := newZoneUpdateClient("127.0.0.1", port, getTestZoneUpdateConfig(t))
+	err = client.updateZone(context.Background(), "", "example.com", nil,
+		parseTestRRs(t, "www.example.com. 300 IN A 192.0.2.2"))
+	require.ErrorContains(t, err, "failed to send the update of zone example.com")
+}
@@ -1,11 +1,27 @@
 package bind9config
 
 import (
+	"net"
 	"path/filepath"
+	"strings"
 
 	"github.com/pkg/errors"
 )
 
+// An interface implemented by the clauses of the options, view and zone
+// statements. It provides uniform access to the clauses the statements
+// have in common.
+type clause interface {
+	getIdentifier() string
+	getOption() *Option
+	getAllow() *AllowClause
+	getAlsoNotify() *AlsoNotify
+}
+
+// Maximum nesting level of the address match lists and ACLs examined
+// when evaluating the address match lists.
+const maxAddressMatchListLevel = 5
+
 // Returns the view with the given name or nil if the view is not found.
 func (c *Config) GetView(viewName string) *View {
 	for _, statement := range c.Statements {
@@ -36,6 +52,61 @@ func (c *Config) GetACL(aclName string) *ACL {
 	return nil
 }
 
+// Returns the options statement or nil if the statement is not found.
+func (c *Config) GetOptions() *Options {
+	for _, statement := range c.Statements {
+		if statement.Options != nil {
+			return statement.Options
+		}
+	}
+	return nil
+}
+
+// Returns the statistics-channels statement or nil if the statement is
+// not found.
+func (c *Config) GetStatisticsChannels() *StatisticsChannels {
+	for _, statement := range c.Statements {
+		if statement.StatisticsChannels != nil {
+			return statement.StatisticsChannels
+		}
+	}
+	return nil
+}
+
+// Returns all views in the configuration.
+func (c *Config) GetViews() (views []*View) {
+	for _, statement := range c.Statements {
+		if statement.View != nil {
+			views = append(views, statement.View)
+		}
+	}
+	return
+}
+
+// Returns the zones defined at the top level of the configuration, i.e.,
+// outside of the views.
+func (c *Config) GetZones() (zones []*Zone) {
+	for _, statement := range c.Statements {
+		if statement.Zone != nil {
+			zones = append(zones, statement.Zone)
+		}
+	}
+	return
+}
+
+// Returns the identifiers of the top-level statements.
+func (c *Config) GetStatementIdentifiers() (identifiers []string) {
+	for _, statement := range c.Statements {
+		switch {
+		case statement.NamedStatement != nil:
+			identifiers = append(identifiers, statement.NamedStatement.Identifier)
+		case statement.UnnamedStatement != nil:
+			identifiers = append(identifiers, statement.UnnamedStatement.Identifier)
+		}
+	}
+	return
+}
+
 // Recursively searches for a key in the address-match-list. If the list
 // contains references to other ACLs, it searches for a key in the referenced
 // ACLs. It protects against infinite recursion by limiting the depth of the
@@ -86,6 +157,137 @@ func (c *Config) GetViewKey(viewName string) (*Key, error) {
 	return nil, nil
 }
 
+// Returns the zone with the given name defined in the specified view or nil
+// if the zone is not found. If the view is not found and the view name is
+// empty or _default, the zone is searched among the top-level zones. The
+// zone names are compared case-insensitively and regardless of the trailing
+// dot.
+func (c *Config) GetViewZone(viewName, zoneName string) *Zone {
+	var zones []*Zone
+	if view := c.GetView(viewName); view != nil {
+		zones = view.GetZones()
+	} else if viewName == "" || viewName == "_default" {
+		zones = c.GetZones()
+	}
+	zoneName = strings.TrimSuffix(zoneName, ".")
+	for _, zone := range zones {
+		if strings.EqualFold(strings.TrimSuffix(zone.Name, "."), zoneName) {
+			return zone
+		}
+	}
+	return nil
+}
+
+// Returns the key used to sign the dynamic updates of the zone in the
+// specified view or nil if no key is found. The key associated with the
+// view via match-clients clause takes precedence because the DNS server
+// selects the view by the key. Otherwise, the key is searched in the
+// allow-update clause of the zone, the view, or the options.
+func (c *Config) GetZoneUpdateKey(viewName, zoneName string) (*Key, error) {
+	key, err := c.GetViewKey(viewName)
+	if err != nil || key != nil {
+		return key, err
+	}
+	zone := c.GetViewZone(viewName, zoneName)
+	if zone == nil {
+		return nil, nil
+	}
+	allow := zone.GetAllowClause("allow-update")
+	if view := c.GetView(viewName); allow == nil && view != nil {
+		allow = view.GetAllowClause("allow-update")
+	}
+	if options := c.GetOptions(); allow == nil && options != nil {
+		allow = options.GetAllowClause("allow-update")
+	}
+	if allow == nil {
+		return nil, nil
+	}
+	return c.getKeyFromAddressMatchList(0, allow.AdressMatchList)
+}
+
+// Checks if the address match list matches any client. It is the case
+// when the list contains the "any" ACL or a prefix covering all IPv4 or
+// IPv6 addresses. The referenced ACLs and nested lists are also examined.
+// The negated elements are skipped.
+func (c *Config) MatchesAnyClient(addressMatchList *AddressMatchList) bool {
+	return c.matchesAnyClient(0, addressMatchList)
+}
+
+// Recursive implementation of the MatchesAnyClient function.
+func (c *Config) matchesAnyClient(level int, addressMatchList *AddressMatchList) bool {
+	if level > maxAddressMatchListLevel || addressMatchList == nil {
+		return false
+	}
+	for _, element := range addressMatchList.Elements {
+		switch {
+		case !element.IsMatchExpected():
+			continue
+		case element.ACL != nil:
+			if c.matchesAnyClient(level+1, element.ACL.AdressMatchList) {
+				return true
+			}
+		case element.IPAddress != "":
+			if element.IPAddress == "0.0.0.0/0" || element.IPAddress == "::/0" {
+				return true
+			}
+		case element.ACLName == "any":
+			return true
+		case element.ACLName != "":
+			if acl := c.GetACL(element.ACLName); acl != nil && c.matchesAnyClient(level+1, acl.AdressMatchList) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Checks if the address match list matches the clients by their addresses
+// rather than by the TSIG keys they sign the requests with. The loopback
+// addresses and the "localhost" ACL are ignored because the requests sent
+// from the local host are typically trusted. The referenced ACLs and nested
+// lists are also examined. The negated elements are skipped.
+func (c *Config) MatchesClientsWithoutKey(addressMatchList *AddressMatchList) bool {
+	return c.matchesClientsWithoutKey(0, addressMatchList)
+}
+
+// Recursive implementation of the MatchesClientsWithoutKey function.
+func (c *Config) matchesClientsWithoutKey(level int, addressMatchList *AddressMatchList) bool {
+	if level > maxAddressMatchListLevel || addressMatchList == nil {
+		return false
+	}
+	for _, element := range addressMatchList.Elements {
+		switch {
+		case !element.IsMatchExpected():
+			continue
+		case element.ACL != nil:
+			if c.matchesClientsWithoutKey(level+1, element.ACL.AdressMatchList) {
+				return true
+			}
+		case element.IPAddress != "":
+			if !isLoopbackAddress(element.IPAddress) {
+				return true
+			}
+		case element.ACLName == "any", element.ACLName == "localnets":
+			return true
+		case element.ACLName == "none", element.ACLName == "localhost":
+			continue
+		case element.ACLName != "":
+			if acl := c.GetACL(element.ACLName); acl != nil && c.matchesClientsWithoutKey(level+1, acl.AdressMatchList) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Checks if the address or prefix specified in the address match list
+// is a loopback address.
+func isLoopbackAddress(address string) bool {
+	address, _, _ = strings.Cut(address, "/")
+	ip := net.ParseIP(address)
+	return ip != nil && ip.IsLoopback()
+}
+
 // Returns true if the ACL element contains negation (!).
 func (el AddressMatchListElement) IsMatchExpected() bool {
 	return !el.Negation
@@ -148,3 +350,248 @@ func (c *Config) Expand(baseDir string) (*Config, error) {
 	}
 	return expanded, nil
 }
+
+// Returns the zones defined in the view.
+func (v *View) GetZones() (zones []*Zone) {
+	for _, clause := range v.Clauses {
+		if clause.Zone != nil {
+			zones = append(zones, clause.Zone)
+		}
+	}
+	return
+}
+
+// Returns the option with the given identifier or nil if the option is
+// not found.
+func (o *Options) GetOption(identifier string) *Option {
+	return getOption(o.Clauses, identifier)
+}
+
+// Returns the allow-* clause with the given identifier or nil if the
+// clause is not found.
+func (o *Options) GetAllowClause(identifier string) *AllowClause {
+	return getAllowClause(o.Clauses, identifier)
+}
+
+// Returns the also-notify clause or nil if the clause is not found.
+func (o *Options) GetAlsoNotify() *AlsoNotify {
+	return getAlsoNotify(o.Clauses)
+}
+
+// Returns the identifiers of all clauses.
+func (o *Options) GetClauseIdentifiers() []string {
+	return getClauseIdentifiers(o.Clauses)
+}
+
+// Returns the option with the given identifier or nil if the option is
+// not found.
+func (v *View) GetOption(identifier string) *Option {
+	return getOption(v.Clauses, identifier)
+}
+
+// Returns the allow-* clause with the given identifier or nil if the
+// clause is not found.
+func (v *View) GetAllowClause(identifier string) *AllowClause {
+	return getAllowClause(v.Clauses, identifier)
+}
+
+// Returns the also-notify clause or nil if the clause is not found.
+func (v *View) GetAlsoNotify() *AlsoNotify {
+	return getAlsoNotify(v.Clauses)
+}
+
+// Returns the identifiers of all clauses.
+func (v *View) GetClauseIdentifiers() []string {
+	return getClauseIdentifiers(v.Clauses)
+}
+
+// Returns the option with the given identifier or nil if the option is
+// not found.
+func (z *Zone) GetOption(identifier string) *Option {
+	return getOption(z.Clauses, identifier)
+}
+
+// Returns the allow-* clause with the given identifier or nil if the
+// clause is not found.
+func (z *Zone) GetAllowClause(identifier string) *AllowClause {
+	return getAllowClause(z.Clauses, identifier)
+}
+
+// Returns the also-notify clause or nil if the clause is not found.
+func (z *Zone) GetAlsoNotify() *AlsoNotify {
+	return getAlsoNotify(z.Clauses)
+}
+
+// Returns the identifiers of all clauses.
+func (z *Zone) GetClauseIdentifiers() []string {
+	return getClauseIdentifiers(z.Clauses)
+}
+
+// Returns the zone type (e.g., primary, secondary). The legacy type names,
+// master and slave, are converted to primary and secondary respectively.
+// It returns an empty string if the type is not specified.
+func (z *Zone) GetType() string {
+	option := z.GetOption("type")
+	if option == nil {
+		return ""
+	}
+	switch option.Contents {
+	case "master":
+		return "primary"
+	case "slave":
+		return "secondary"
+	default:
+		return option.Contents
+	}
+}
+
+// Generic function returning the option with the given identifier from
+// the list of clauses.
+func getOption[T clause](clauses []T, identifier string) *Option {
+	for _, clause := range clauses {
+		if option := clause.getOption(); option != nil && option.Identifier == identifier {
+			return option
+		}
+	}
+	return nil
+}
+
+// Generic function returning the allow-* clause with the given identifier
+// from the list of clauses.
+func getAllowClause[T clause](clauses []T, identifier string) *AllowClause {
+	for _, clause := range clauses {
+		if allow := clause.getAllow(); allow != nil && allow.Identifier == identifier {
+			return allow
+		}
+	}
+	return nil
+}
+
+// Generic function returning the also-notify clause from the list of
+// clauses.
+func getAlsoNotify[T clause](clauses []T) *AlsoNotify {
+	for _, clause := range clauses {
+		if alsoNotify := clause.getAlsoNotify(); alsoNotify != nil {
+			return alsoNotify
+		}
+	}
+	return nil
+}
+
+// Generic function returning the identifiers of the clauses.
+func getClauseIdentifiers[T clause](clauses []T) (identifiers []string) {
+	for _, clause := range clauses {
+		if identifier := clause.getIdentifier(); identifier != "" {
+			identifiers = append(identifiers, identifier)
+		}
+	}
+	return
+}
+
+// Returns the identifier of the options clause.
+func (c *OptionClause) getIdentifier() string {
+	switch {
+	case c.Allow != nil:
+		return c.Allow.Identifier
+	case c.AlsoNotify != nil:
+		return "also-notify"
+	case c.Option != nil:
+		return c.Option.Identifier
+	case c.GenericClause != nil:
+		return c.GenericClause.Identifier
+	default:
+		return ""
+	}
+}
+
+// Returns the option or nil if the clause is not an option.
+func (c *OptionClause) getOption() *Option {
+	return c.Option
+}
+
+// Returns the allow-* clause or nil if the clause is not an allow-* clause.
+func (c *OptionClause) getAllow() *AllowClause {
+	return c.Allow
+}
+
+// Returns the also-notify clause or nil if the clause is not an also-notify
+// clause.
+func (c *OptionClause) getAlsoNotify() *AlsoNotify {
+	return c.AlsoNotify
+}
+
+// Returns the identifier of the view clause.
+func (c *ViewClause) getIdentifier() string {
+	switch {
+	case c.MatchClients != nil:
+		return "match-clients"
+	case c.Zone != nil:
+		return "zone"
+	case c.Allow != nil:
+		return c.Allow.Identifier
+	case c.AlsoNotify != nil:
+		return "also-notify"
+	case c.NamedClause != nil:
+		return c.NamedClause.Identifier
+	case c.UnnamedClause != nil:
+		return c.UnnamedClause.Identifier
+	case c.Option != nil:
+		return c.Option.Identifier
+	case c.GenericClause != nil:
+		return c.GenericClause.Identifier
+	default:
+		return ""
+	}
+}
+
+// Returns the option or nil if the clause is not an option.
+func (c *ViewClause) getOption() *Option {
+	return c.Option
+}
+
+// Returns the allow-* clause or nil if the clause is not an allow-* clause.
+func (c *ViewClause) getAllow() *AllowClause {
+	return c.Allow
+}
+
+// Returns the also-notify clause or nil if the clause is not an also-notify
+// clause.
+func (c *ViewClause) getAlsoNotify() *AlsoNotify {
+	return c.AlsoNotify
+}
+
+// Returns the identifier of the zone clause.
+func (c *ZoneClause) getIdentifier() string {
+	switch {
+	case c.Allow != nil:
+		return c.Allow.Identifier
+	case c.AlsoNotify != nil:
+		return "also-notify"
+	case c.NamedClause != nil:
+		return c.NamedClause.Identifier
+	case c.UnnamedClause != nil:
+		return c.UnnamedClause.Identifier
+	case c.Option != nil:
+		return c.Option.Identifier
+	case c.GenericClause != nil:
+		return c.GenericClause.Identifier
+	default:
+		return ""
+	}
+}
+
+// Returns the option or nil if the clause is not an option.
+func (c *ZoneClause) getOption() *Option {
+	return c.Option
+}
+
+// Returns the allow-* clause or nil if the clause is not an allow-* clause.
+func (c *ZoneClause) getAllow() *AllowClause {
+	return c.Allow
+}
+
+// Returns the also-notify clause or nil if the clause is not an also-notify
+// clause.
+func (c *ZoneClause) getAlsoNotify() *AlsoNotify {
+	return c.AlsoNotify
+}
@@ -115,6 +115,108 @@ func TestGetViewKey(t *testing.T) {
 	require.Nil(t, key)
 }
 
+// Test getting the zone defined in a view or at the top level.
+func TestGetViewZone(t *testing.T) {
+	cfg, err := Parse("", strings.NewReader(`
+		zone "example.org" {
+			type primary;
+		};
+		view "trusted" {
+			zone "Example.com." {
+				type primary;
+			};
+		};
+	`))
+	require.NoError(t, err)
+
+	zone := cfg.GetViewZone("trusted", "example.com")
+	require.NotNil(t, zone)
+	require.Equal(t, "Example.com.", zone.Name)
+
+	zone = cfg.GetViewZone("_default", "example.org.")
+	require.NotNil(t, zone)
+	require.Equal(t, "example.org", zone.Name)
+
+	require.Nil(t, cfg.GetViewZone("trusted", "example.org"))
+	require.Nil(t, cfg.GetViewZone("guest", "example.org"))
+	require.Nil(t, cfg.GetViewZone("_default", "example.net"))
+}
+
+// Test getting the key used to sign the dynamic updates of the zone.
+func TestGetZoneUpdateKey(t *testing.T) {
+	cfg, err := Parse("", strings.NewReader(`
+		key "view-key" {
+			algorithm hmac-sha256;
+			secret "VO6xA4Tc1PWYaqMuPaf6wfkITb+c9/mkzlEaWJavejU=";
+		};
+		key "update-key" {
+			algorithm hmac-sha512;
+			secret "6L8DwXFboA7FDQJQP051hjFV/n9B3IR/SwDLX7y5czE=";
+		};
+		key "options-key" {
+			algorithm hmac-sha256;
+			secret "6L8DwXFboA7FDQJQP051hjFV/n9B3IR/SwDLX7y5czE=";
+		};
+		acl "updaters" {
+			!key "view-key";
+			key "update-key";
+		};
+		options {
+			allow-update { key "options-key"; };
+		};
+		view "trusted" {
+			match-clients { key "view-key"; };
+			zone "example.com" {
+				type primary;
+				allow-update { key "update-key"; };
+			};
+		};
+		view "guest" {
+			match-clients { any; };
+			zone "example.com" {
+				type primary;
+				allow-update { updaters; };
+			};
+			zone "example.org" {
+				type primary;
+			};
+			zone "example.net" {
+				type primary;
+				allow-update { 127.0.0.1; };
+			};
+		};
+	`))
+	require.NoError(t, err)
+
+	// The view key takes precedence.
+	key, err := cfg.GetZoneUpdateKey("trusted", "example.com")
+	require.NoError(t, err)
+	require.NotNil(t, key)
+	require.Equal(t, "view-key", key.Name)
+
+	// The key from the ACL referenced in the allow-update clause.
+	key, err = cfg.GetZoneUpdateKey("guest", "example.com")
+	require.NoError(t, err)
+	require.NotNil(t, key)
+	require.Equal(t, "update-key", key.Name)
+
+	// The key from the allow-update clause in the options.
+	key, err = cfg.GetZoneUpdateKey("guest", "example.org")
+	require.NoError(t, err)
+	require.NotNil(t, key)
+	require.Equal(t, "options-key", key.Name)
+
+	// The allow-update clause without a key.
+	key, err = cfg.GetZoneUpdateKey("guest", "example.net")
+	require.NoError(t, err)
+	require.Nil(t, key)
+
+	// Non-existing zone.
+	key, err = cfg.GetZoneUpdateKey("guest", "example.edu")
+	require.NoError(t, err)
+	require.Nil(t, key)
+}
+
 // Tests that IsMatchExpected returns true when the element does not
 // contain negation, false otherwise.
 func TestIsMatchExpected(t *testing.T) {
@@ -172,3 +274,92 @@ func TestGetAlgorithmSecretNoSecret(t *testing.T) {
 	_, _, err := key.GetAlgorithmSecret()
 	require.ErrorContains(t, err, "no algorithm or secret found in key test-key")
 }
+
+// Tests that the address match lists matching any client are recognized.
+func TestMatchesAnyClient(t *testing.T) {
+	config := `
+		acl all { any; };
+		acl nested { all; };
+		acl some { 192.0.2.0/24; };
+		acl cyclic1 { cyclic2; };
+		acl cyclic2 { cyclic1; };
+		options {
+			allow-query { any; };
+			allow-recursion { nested; };
+			allow-transfer { !any; some; };
+			allow-update { 0.0.0.0/0; };
+			allow-notify { ::/0; };
+			allow-query-cache { cyclic1; };
+		};
+	`
+	cfg, err := Parse("", strings.NewReader(config))
+	require.NoError(t, err)
+	options := cfg.GetOptions()
+	require.NotNil(t, options)
+
+	require.True(t, cfg.MatchesAnyClient(options.GetAllowClause("allow-query").AdressMatchList))
+	require.True(t, cfg.MatchesAnyClient(options.GetAllowClause("allow-recursion").AdressMatchList))
+	require.False(t, cfg.MatchesAnyClient(options.GetAllowClause("allow-transfer").AdressMatchList))
+	require.True(t, cfg.MatchesAnyClient(options.GetAllowClause("allow-update").AdressMatchList))
+	require.True(t, cfg.MatchesAnyClient(options.GetAllowClause("allow-notify").AdressMatchList))
+	require.False(t, cfg.MatchesAnyClient(options.GetAllowClause("allow-query-cache").AdressMatchList))
+	require.False(t, cfg.MatchesAnyClient(nil))
+}
+
+// Tests that the address match lists matching the clients by their
+// addresses rather than keys are recognized.
+func TestMatchesClientsWithoutKey(t *testing.T) {
+	config := `
+		acl keys { key "key1"; key "key2"; };
+		acl addresses { keys; 192.0.2.1; };
+		options {
+			allow-query { key "key1"; keys; };
+			allow-recursion { addresses; };
+			allow-transfer { !192.0.2.1; key "key1"; };
+			allow-update { localhost; 127.0.0.1; ::1; none; };
+			allow-notify { localnets; };
+			allow-query-cache { any; };
+		};
+	`
+	cfg, err := Parse("", strings.NewReader(config))
+	require.NoError(t, err)
+	options := cfg.GetOptions()
+	require.NotNil(t, options)
+
+	require.False(t, cfg.MatchesClientsWithoutKey(options.GetAllowClause("allow-query").AdressMatchList))
+	require.True(t, cfg.MatchesClientsWithoutKey(options.GetAllowClause("allow-recursion").AdressMatchList))
+	require.False(t, cfg.MatchesClientsWithoutKey(options.GetAllowClause("allow-transfer").AdressMatchList))
+	require.False(t, cfg.MatchesClientsWithoutKey(options.GetAllowClause("allow-update").AdressMatchList))
+	require.True(t, cfg.MatchesClientsWithoutKey(options.GetAllowClause("allow-notify").AdressMatchList))
+	require.True(t, cfg.MatchesClientsWithoutKey(options.GetAllowClause("allow-query-cache").AdressMatchList))
+	require.False(t, cfg.MatchesClientsWithoutKey(nil))
+}
+
+// Tests that the top-level statements, views and zones are returned.
+func TestGetStatementsViewsAndZones(t *testing.T) {
+	cfg, err := ParseFile("testdata/named.conf")
+	require.NoError(t, err)
+	require.NotNil(t, cfg)
+
+	require.Equal(t, []string{"controls", "logging"}, cfg.GetStatementIdentifiers())
+
+	views := cfg.GetViews()
+	require.Len(t, views, 2)
+	require.Equal(t, "trusted", views[0].Name)
+	require.Equal(t, "guest", views[1].Name)
+	require.Equal(t, "no", views[0].GetOption("recursion").Contents)
+
+	zones := views[0].GetZones()
+	require.Len(t, zones, 2)
+	require.Equal(t, "bind9.example.com", zones[0].Name)
+	require.Equal(t, "primary", zones[0].GetType())
+	require.Equal(t, "pdns.example.com", zones[1].Name)
+	require.Equal(t, "forward", zones[1].GetType())
+
+	zones = cfg.GetZones()
+	require.Len(t, zones, 1)
+	require.Equal(t, "nsd.example.com", zones[0].Name)
+
+	require.NotNil(t, cfg.GetOptions())
+	require.NotNil(t, cfg.GetStatisticsChannels())
+}
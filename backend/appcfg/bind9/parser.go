@@ -39,6 +39,13 @@ type Statement struct {
 	// The "zone" statement is used to define a DNS zone.
 	Zone *Zone `parser:"| 'zone' @@"`
 
+	// The "options" statement is used to define the global server options.
+	Options *Options `parser:"| 'options' @@"`
+
+	// The "statistics-channels" statement is used to define the HTTP
+	// channels exposing the server statistics.
+	StatisticsChannels *StatisticsChannels `parser:"| 'statistics-channels' @@"`
+
 	// A generic catch-all named statement. It is used to parse any statement
 	// not covered explicitly above and having the following format:
 	//
@@ -148,12 +155,18 @@ type ViewClause struct {
 	MatchClients *MatchClients `parser:"'match-clients' @@"`
 	// The zone clause associating the zone with a view.
 	Zone *Zone `parser:"| 'zone' @@"`
+	// The allow-query, allow-recursion, allow-transfer etc. clause.
+	Allow *AllowClause `parser:"| @@"`
+	// The also-notify clause.
+	AlsoNotify *AlsoNotify `parser:"| 'also-notify' @@"`
 	// Any namedClause clause.
 	NamedClause *NamedStatement `parser:"| @@"`
 	// Any unnamedClause clause.
 	UnnamedClause *UnnamedStatement `parser:"| @@"`
 	// Any option clause.
 	Option *Option `parser:"| @@"`
+	// Any other clause.
+	GenericClause *GenericClause `parser:"| @@"`
 }
 
 // Zone is the statement used to define a zone. The zone has the following format:
@@ -174,12 +187,108 @@ type Zone struct {
 
 // ZoneClause is a single clause of a zone statement.
 type ZoneClause struct {
+	// The allow-query, allow-transfer, allow-update etc. clause.
+	Allow *AllowClause `parser:"@@"`
+	// The also-notify clause.
+	AlsoNotify *AlsoNotify `parser:"| 'also-notify' @@"`
 	// Any namedClause clause.
-	NamedClause *NamedStatement `parser:"@@"`
+	NamedClause *NamedStatement `parser:"| @@"`
 	// Any unnamedClause clause.
 	UnnamedClause *UnnamedStatement `parser:"| @@"`
 	// Any option clause.
 	Option *Option `parser:"| @@"`
+	// Any other clause.
+	GenericClause *GenericClause `parser:"| @@"`
+}
+
+// Options is the statement used to define the global server options. The
+// options have the following format:
+//
+//	options {
+//		<option-clauses> ...
+//	};
+//
+// See: https://bind9.readthedocs.io/en/stable/reference.html#options-block-grammar.
+type Options struct {
+	// The list of clauses (e.g., allow-transfer, recursion etc.).
+	Clauses []*OptionClause `parser:"'{' ( @@ ';'* )* '}'"`
+}
+
+// OptionClause is a single clause of the options statement.
+type OptionClause struct {
+	// The allow-query, allow-recursion, allow-transfer etc. clause.
+	Allow *AllowClause `parser:"@@"`
+	// The also-notify clause.
+	AlsoNotify *AlsoNotify `parser:"| 'also-notify' @@"`
+	// Any option clause.
+	Option *Option `parser:"| @@"`
+	// Any other clause.
+	GenericClause *GenericClause `parser:"| @@"`
+}
+
+// AllowClause is a clause granting access to the server functions, e.g.
+// to the recursive queries or zone transfers, to the clients matching the
+// address match list. It has the following format:
+//
+//	<identifier> [ port <integer> ] [ transport <string> ] { <address-match-list> };
+//
+// The port and transport are only allowed in the allow-transfer clause.
+// They are ignored by the parser.
+//
+// See: https://bind9.readthedocs.io/en/stable/reference.html#term-allow-transfer.
+type AllowClause struct {
+	// The Identifier of the clause, e.g. allow-transfer.
+	Identifier string `parser:"@( 'allow-notify' | 'allow-query' | 'allow-query-cache' | 'allow-query-cache-on' | 'allow-query-on' | 'allow-recursion' | 'allow-recursion-on' | 'allow-transfer' | 'allow-update' | 'allow-update-forwarding' )"`
+	// The list of address match list elements between curly braces.
+	AdressMatchList *AddressMatchList `parser:"( 'port' Number )? ( 'transport' ( Ident | String ) )? '{' @@ '}'"`
+}
+
+// AlsoNotify is the clause specifying the servers to which the NOTIFY
+// messages are sent in addition to the servers listed in the zone's NS
+// records. It has the following format:
+//
+//	also-notify [ port <integer> ] { ( <remote-servers> | <ip_address> [ port <integer> ] ) [ key <string> ] [ tls <string> ]; ... };
+//
+// See: https://bind9.readthedocs.io/en/stable/reference.html#term-also-notify.
+type AlsoNotify struct {
+	// The default port of the servers.
+	Port string `parser:"( 'port' @Number )?"`
+	// The list of servers between curly braces.
+	Servers []*RemoteServer `parser:"'{' ( @@ ';'+ )* '}'"`
+}
+
+// RemoteServer is a single server specified in the also-notify clause.
+// It is specified by an IP address or by the name of the remote-servers
+// list.
+type RemoteServer struct {
+	Address string `parser:"( @IPv4Address | @IPv6Address | @IPv4AddressQuoted | @IPv6AddressQuoted | @Ident | @String )"`
+	Port    string `parser:"( 'port' @Number )?"`
+	Key     string `parser:"( 'key' ( @Ident | @String ) )?"`
+	TLS     string `parser:"( 'tls' ( @Ident | @String ) )?"`
+}
+
+// StatisticsChannels is the statement used to define the HTTP channels
+// exposing the server statistics. It has the following format:
+//
+//	statistics-channels {
+//		inet ( <ipv4_address> | <ipv6_address> | * ) [ port ( <integer> | * ) ] [ allow { <address_match_element>; ... } ];
+//	};
+//
+// See: https://bind9.readthedocs.io/en/stable/reference.html#statistics-channels-block-grammar.
+type StatisticsChannels struct {
+	// The list of the channels.
+	Clauses []*InetClause `parser:"'{' ( @@ ';'* )* '}'"`
+}
+
+// InetClause is a single channel defined in the statistics-channels
+// statement.
+type InetClause struct {
+	// The address on which the channel listens or an asterisk.
+	Address string `parser:"'inet' ( @IPv4Address | @IPv6Address | @IPv4AddressQuoted | @IPv6AddressQuoted | @'*' )"`
+	// The port on which the channel listens or an asterisk.
+	Port string `parser:"( 'port' ( @Number | @'*' ) )?"`
+	// The optional list of clients allowed to connect to the channel.
+	Allow *AddressMatchList `parser:"( 'allow' '{' @@ '}' )?"`
 }
 
 // MatchClients is the clause for associations with ACLs. It can be used in the
@@ -226,7 +335,20 @@ type UnnamedStatement struct {
 // Many options in the options statement have this format.
 type Option struct {
 	Identifier string `parser:"@Ident"`
-	Contents   string `parser:"( @String | @Ident )"`
+	Contents   string `parser:"( @String | @Ident | @Number ) (?= ';' )"`
+}
+
+// GenericClause is a generic catch-all clause. It is used to parse any
+// clause not covered explicitly by the parser, e.g.:
+//
+//	listen-on port 53 { 127.0.0.1; };
+//
+// The clause contents are discarded.
+type GenericClause struct {
+	// The Identifier of the clause.
+	Identifier string `parser:"@Ident"`
+	// The Contents of the clause.
+	Contents *GenericClauseValue `parser:"@@"`
 }
 
 // GenericClauseContents is used to parse any type of contents. It is
@@ -263,6 +385,37 @@ func (b *GenericClauseContents) Parse(lex *lexer.PeekingLexer) error {
 	}
 }
 
+// GenericClauseValue is used to parse the value of a generic clause. It
+// consumes and discards all tokens until the semicolon ending the clause,
+// EOF or extraneous closing brace is found.
+type GenericClauseValue struct{}
+
+// Parses the value of a generic clause.
+func (v *GenericClauseValue) Parse(lex *lexer.PeekingLexer) error {
+	cnt := 0
+	for {
+		// Get the next token without consuming it.
+		token := lex.Peek()
+		switch {
+		case token.EOF():
+			return nil
+		case token.Value == ";" && cnt == 0:
+			// The end of the clause.
+			return nil
+		case token.Value == "{":
+			cnt++
+		case token.Value == "}":
+			cnt--
+			if cnt < 0 {
+				// Extraneous closing brace found.
+				return nil
+			}
+		}
+		// Consume the token.
+		_ = lex.Next()
+	}
+}
+
 // Parses the Bind9 configuration from a file using custom lexer.
 func ParseFile(filename string) (*Config, error) {
 	file, err := os.Open(filename)
@@ -293,11 +446,11 @@ func Parse(filename string, fileReader io.Reader) (*Config, error) {
 		{Name: "IPv4Address", Pattern: `(?:([0-9]{1,3}\.){3}(?:[0-9]{1,3}))(?:/(?:[0-9]{1,2}))?`},
 		// IPv6 addresses and subnets can be specified with or without quotes.
 		// This variant assumes the lack of quotes.
-		{Name: "IPv6Address", Pattern: `(?:([0-9a-fA-F]{1,4}:{1,2}){1,8})(?:/[0-9]{1,3})?`},
+		{Name: "IPv6Address", Pattern: `(?:[0-9a-fA-F]{0,4}:){2,7}[0-9a-fA-F]{0,4}(?:/[0-9]{1,3})?`},
 		// IPv4 addresses and subnets can be specified with quotes.
 		{Name: "IPv4AddressQuoted", Pattern: `"(?:([0-9]{1,3}\.){3}(?:[0-9]{1,3}))(?:/(?:[0-9]{1,2}))?"`},
 		// IPv6 addresses and subnets can be specified with quotes.
-		{Name: "IPv6AddressQuoted", Pattern: `"(?:([0-9a-fA-F]{1,4}:{1,2}){1,8})(?:/[0-9]{1,3})?"`},
+		{Name: "IPv6AddressQuoted", Pattern: `"(?:[0-9a-fA-F]{0,4}:){2,7}[0-9a-fA-F]{0,4}(?:/[0-9]{1,3})?"`},
 		// Strings are always quoted.
 		{Name: "String", Pattern: `"(\\"|[^"])*"`},
 		// Numbers.
@@ -308,11 +461,14 @@ func Parse(filename string, fileReader io.Reader) (*Config, error) {
 		// The identifier handles this second case.
 		{Name: "Ident", Pattern: `[0-9a-zA-Z-_]+`},
 		// Punctuation characters.
-		{Name: "Punct", Pattern: `[;,.{}!]`},
+		{Name: "Punct", Pattern: `[;,.{}!*]`},
 		// Whitespace characters.
 		{Name: "Whitespace", Pattern: `[ \t\n\r]+`},
 		// End of line characters.
 		{Name: "EOL", Pattern: `[\n\r]+`},
+		// Any other character, e.g. a percent sign in the values like 90%.
+		// Such characters are only accepted in the generic clauses.
+		{Name: "Other", Pattern: `.`},
 	})
 
 	parser := participle.MustBuild[Config](
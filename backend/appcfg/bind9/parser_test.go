@@ -2,6 +2,7 @@ package bind9config
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -45,11 +46,23 @@ func TestParseFile(t *testing.T) {
 	require.NotNil(t, cfg.Statements[4].UnnamedStatement)
 	require.Equal(t, "controls", cfg.Statements[4].UnnamedStatement.Identifier)
 
-	require.NotNil(t, cfg.Statements[5].UnnamedStatement)
-	require.Equal(t, "statistics-channels", cfg.Statements[5].UnnamedStatement.Identifier)
-
-	require.NotNil(t, cfg.Statements[6].UnnamedStatement)
-	require.Equal(t, "options", cfg.Statements[6].UnnamedStatement.Identifier)
+	require.NotNil(t, cfg.Statements[5].StatisticsChannels)
+	require.Len(t, cfg.Statements[5].StatisticsChannels.Clauses, 1)
+	require.Equal(t, "127.0.0.1", cfg.Statements[5].StatisticsChannels.Clauses[0].Address)
+	require.Equal(t, "8053", cfg.Statements[5].StatisticsChannels.Clauses[0].Port)
+	require.NotNil(t, cfg.Statements[5].StatisticsChannels.Clauses[0].Allow)
+	require.Len(t, cfg.Statements[5].StatisticsChannels.Clauses[0].Allow.Elements, 1)
+
+	require.NotNil(t, cfg.Statements[6].Options)
+	require.Len(t, cfg.Statements[6].Options.Clauses, 4)
+	require.NotNil(t, cfg.Statements[6].Options.Clauses[0].Allow)
+	require.Equal(t, "allow-query", cfg.Statements[6].Options.Clauses[0].Allow.Identifier)
+	require.NotNil(t, cfg.Statements[6].Options.Clauses[1].Allow)
+	require.Equal(t, "allow-transfer", cfg.Statements[6].Options.Clauses[1].Allow.Identifier)
+	require.NotNil(t, cfg.Statements[6].Options.Clauses[2].Option)
+	require.Equal(t, "dnssec-validation", cfg.Statements[6].Options.Clauses[2].Option.Identifier)
+	require.NotNil(t, cfg.Statements[6].Options.Clauses[3].Option)
+	require.Equal(t, "recursion", cfg.Statements[6].Options.Clauses[3].Option.Identifier)
 
 	require.NotNil(t, cfg.Statements[7].View)
 	require.Equal(t, "trusted", cfg.Statements[7].View.Name)
@@ -192,3 +205,94 @@ func TestParseIncludeSelf(t *testing.T) {
 	require.Len(t, cfg.Statements[1].ACL.AdressMatchList.Elements, 1)
 	require.Equal(t, "1.2.3.4", cfg.Statements[1].ACL.AdressMatchList.Elements[0].IPAddress)
 }
+
+// Test parsing the options, view and zone clauses recognized by the parser
+// and falling back to the generic clauses for the other clauses.
+func TestParseOptionsViewsAndZones(t *testing.T) {
+	config := `
+		options {
+			directory "/var/cache/bind";
+			listen-on port 53 { 127.0.0.1; };
+			listen-on-v6 { ::1; fe80::1; };
+			max-cache-size 90%;
+			recursion yes;
+			allow-recursion { any; };
+			allow-transfer port 853 transport tls { key "xfr-key"; 2001:db8::/32; };
+			also-notify port 5353 { 192.0.2.1 port 53 key "notify-key"; secondaries; };
+			notify explicit;
+		};
+		statistics-channels {
+			inet * port 8053 allow { any; };
+			inet ::1 port *;
+		};
+		view "internal" {
+			match-clients { localnets; };
+			server 192.0.2.2 { keys { "xfr-key"; }; };
+			allow-query-cache { localhost; };
+			zone "example.com" {
+				type master;
+				file "/etc/bind/db.example.com";
+				allow-transfer { none; };
+				also-notify { };
+				update-policy { grant "update-key" zonesub ANY; };
+			};
+		};
+	`
+	cfg, err := Parse("", strings.NewReader(config))
+	require.NoError(t, err)
+	require.NotNil(t, cfg)
+	require.Len(t, cfg.Statements, 3)
+
+	options := cfg.GetOptions()
+	require.NotNil(t, options)
+	require.Equal(t, []string{
+		"directory", "listen-on", "listen-on-v6", "max-cache-size", "recursion",
+		"allow-recursion", "allow-transfer", "also-notify", "notify",
+	}, options.GetClauseIdentifiers())
+
+	require.NotNil(t, options.GetOption("recursion"))
+	require.Equal(t, "yes", options.GetOption("recursion").Contents)
+	require.Equal(t, "explicit", options.GetOption("notify").Contents)
+	require.Nil(t, options.GetOption("listen-on"))
+
+	allowTransfer := options.GetAllowClause("allow-transfer")
+	require.NotNil(t, allowTransfer)
+	require.Len(t, allowTransfer.AdressMatchList.Elements, 2)
+	require.Equal(t, "xfr-key", allowTransfer.AdressMatchList.Elements[0].KeyID)
+	require.Equal(t, "2001:db8::/32", allowTransfer.AdressMatchList.Elements[1].IPAddress)
+	require.Nil(t, options.GetAllowClause("allow-query"))
+
+	alsoNotify := options.GetAlsoNotify()
+	require.NotNil(t, alsoNotify)
+	require.Equal(t, "5353", alsoNotify.Port)
+	require.Len(t, alsoNotify.Servers, 2)
+	require.Equal(t, "192.0.2.1", alsoNotify.Servers[0].Address)
+	require.Equal(t, "53", alsoNotify.Servers[0].Port)
+	require.Equal(t, "notify-key", alsoNotify.Servers[0].Key)
+	require.Equal(t, "secondaries", alsoNotify.Servers[1].Address)
+
+	channels := cfg.GetStatisticsChannels()
+	require.NotNil(t, channels)
+	require.Len(t, channels.Clauses, 2)
+	require.Equal(t, "*", channels.Clauses[0].Address)
+	require.Equal(t, "8053", channels.Clauses[0].Port)
+	require.NotNil(t, channels.Clauses[0].Allow)
+	require.Equal(t, "::1", channels.Clauses[1].Address)
+	require.Equal(t, "*", channels.Clauses[1].Port)
+	require.Nil(t, channels.Clauses[1].Allow)
+
+	views := cfg.GetViews()
+	require.Len(t, views, 1)
+	require.Equal(t, []string{"match-clients", "server", "allow-query-cache", "zone"}, views[0].GetClauseIdentifiers())
+	require.NotNil(t, views[0].GetAllowClause("allow-query-cache"))
+	require.Nil(t, views[0].GetAlsoNotify())
+
+	zones := views[0].GetZones()
+	require.Len(t, zones, 1)
+	require.Equal(t, "example.com", zones[0].Name)
+	require.Equal(t, "primary", zones[0].GetType())
+	require.Equal(t, []string{"type", "file", "allow-transfer", "also-notify", "update-policy"}, zones[0].GetClauseIdentifiers())
+	require.NotNil(t, zones[0].GetAlsoNotify())
+	require.Empty(t, zones[0].GetAlsoNotify().Servers)
+	require.Empty(t, cfg.GetZones())
+}
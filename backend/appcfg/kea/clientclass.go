@@ -1,9 +1,128 @@
 package keaconfig
 
-// Represents a client class in Kea configuration.
-// todo: it currently only contains class name because it is
-// all we need for current use cases. It will have extra fields
-// when we need them.
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Represents a client class in Kea configuration. The structure contains
+// the parameters common for the DHCPv4 and DHCPv6 servers and the
+// DHCPv4-specific parameters. The DHCPv4-specific parameters are ignored
+// for the DHCPv6 server.
 type ClientClass struct {
-	Name string `json:"name"`
+	PreferredLifetimeParameters
+	ValidLifetimeParameters
+	Name                 string             `json:"name"`
+	Test                 *string            `json:"test,omitempty"`
+	TemplateTest         *string            `json:"template-test,omitempty"`
+	OnlyIfRequired       *bool              `json:"only-if-required,omitempty"`
+	OnlyInAdditionalList *bool              `json:"only-in-additional-list,omitempty"`
+	OptionData           []SingleOptionData `json:"option-data,omitempty"`
+	BootFileName         *string            `json:"boot-file-name,omitempty"`
+	NextServer           *string            `json:"next-server,omitempty"`
+	ServerHostname       *string            `json:"server-hostname,omitempty"`
+	UserContext          map[string]any     `json:"user-context,omitempty"`
+}
+
+// Names of the client classes built into Kea. They can be referenced
+// without being defined in the configuration.
+var builtinClientClasses = []string{"ALL", "KNOWN", "UNKNOWN", "BOOTP", "DROP"}
+
+// Prefixes of the names of the client classes assigned by Kea or its hooks,
+// e.g., VENDOR_CLASS_docsis3.0 or HA_server1.
+var builtinClientClassPrefixes = []string{"VENDOR_CLASS_", "HA_", "AFTER_", "EXTERNAL_", "SPAWN_"}
+
+// Checks if the client class with the specified name is built into Kea or
+// assigned by Kea or its hooks, i.e., it needs not be defined in the
+// configuration to be referenced.
+func IsBuiltinClientClass(name string) bool {
+	for _, builtin := range builtinClientClasses {
+		if name == builtin {
+			return true
+		}
+	}
+	for _, prefix := range builtinClientClassPrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns the index of the client class with the specified name in the
+// raw list of the client classes or -1 if such class does not exist.
+func findRawClientClass(classes []any, name string) int {
+	for i, class := range classes {
+		if class, ok := class.(RawConfig); ok && class["name"] == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// Returns a client class with the specified name or nil if such class
+// does not exist.
+func (c *Config) GetClientClass(name string) *ClientClass {
+	for _, class := range c.GetClientClasses() {
+		if class.Name == name {
+			return &class
+		}
+	}
+	return nil
+}
+
+// Appends a new client class to the DHCP server configuration. Kea evaluates
+// the classes in the order in which they are specified, so the new class is
+// evaluated last. It returns an error if the class with the same name
+// already exists.
+func (c *Config) AddClientClass(class *ClientClass) error {
+	classes, root, err := c.getRawDHCPConfigList("client-classes")
+	if err != nil {
+		return err
+	}
+	if findRawClientClass(classes, class.Name) >= 0 {
+		return errors.Errorf("client class %s already exists", class.Name)
+	}
+	raw, err := convertToRawConfig(class)
+	if err != nil {
+		return err
+	}
+	classes = append(classes, raw)
+	return c.setRawDHCPConfigList(root, "client-classes", classes)
+}
+
+// Replaces an existing client class in the DHCP server configuration. The
+// class is found by name and its position on the list of the classes is
+// preserved. It returns an error if the class does not exist.
+func (c *Config) UpdateClientClass(class *ClientClass) error {
+	classes, root, err := c.getRawDHCPConfigList("client-classes")
+	if err != nil {
+		return err
+	}
+	index := findRawClientClass(classes, class.Name)
+	if index < 0 {
+		return errors.Errorf("client class %s does not exist", class.Name)
+	}
+	raw, err := convertToRawConfig(class)
+	if err != nil {
+		return err
+	}
+	classes[index] = raw
+	return c.setRawDHCPConfigList(root, "client-classes", classes)
+}
+
+// Removes a client class with the specified name from the DHCP server
+// configuration. It returns an error if the class does not exist.
+func (c *Config) DeleteClientClass(name string) error {
+	classes, root, err := c.getRawDHCPConfigList("client-classes")
+	if err != nil {
+		return err
+	}
+	index := findRawClientClass(classes, name)
+	if index < 0 {
+		return errors.Errorf("client class %s does not exist", name)
+	}
+	classes = append(classes[:index], classes[index+1:]...)
+	return c.setRawDHCPConfigList(root, "client-classes", classes)
 }
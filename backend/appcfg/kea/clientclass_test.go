@@ -0,0 +1,205 @@
+package keaconfig
+
+import (
+	"testing"
+
+	require "github.com/stretchr/testify/require"
+	storkutil "isc.org/stork/util"
+)
+
+// Returns test Kea configuration with two client classes.
+func getTestConfigWithClientClasses(t *testing.T) *Config {
+	configStr := `{
+        "Dhcp4": {
+            "client-classes": [
+                {
+                    "name": "foo",
+                    "test": "substring(option[61].hex,0,3) == 'foo'",
+                    "next-server": "192.0.2.1",
+                    "valid-lifetime": 1000,
+                    "option-data": [
+                        {
+                            "code": 6,
+                            "data": "192.0.2.2"
+                        }
+                    ]
+                },
+                {
+                    "name": "bar",
+                    "only-if-required": true,
+                    "unknown-parameter": "baz"
+                }
+            ],
+            "hash": "1234"
+        }
+    }`
+	cfg, err := NewConfig(configStr)
+	require.NoError(t, err)
+	require.NotNil(t, cfg)
+	return cfg
+}
+
+// Test that the client class parameters are parsed.
+func TestGetClientClassParameters(t *testing.T) {
+	cfg := getTestConfigWithClientClasses(t)
+
+	class := cfg.GetClientClass("foo")
+	require.NotNil(t, class)
+	require.Equal(t, "foo", class.Name)
+	require.NotNil(t, class.Test)
+	require.Equal(t, "substring(option[61].hex,0,3) == 'foo'", *class.Test)
+	require.NotNil(t, class.NextServer)
+	require.Equal(t, "192.0.2.1", *class.NextServer)
+	require.NotNil(t, class.ValidLifetime)
+	require.EqualValues(t, 1000, *class.ValidLifetime)
+	require.Len(t, class.OptionData, 1)
+	require.EqualValues(t, 6, class.OptionData[0].Code)
+
+	class = cfg.GetClientClass("bar")
+	require.NotNil(t, class)
+	require.NotNil(t, class.OnlyIfRequired)
+	require.True(t, *class.OnlyIfRequired)
+	require.Nil(t, class.Test)
+
+	require.Nil(t, cfg.GetClientClass("baz"))
+}
+
+// Test that a client class can be added to the configuration.
+func TestAddClientClass(t *testing.T) {
+	cfg := getTestConfigWithClientClasses(t)
+
+	err := cfg.AddClientClass(&ClientClass{
+		Name: "baz",
+		Test: storkutil.Ptr("member('KNOWN')"),
+	})
+	require.NoError(t, err)
+
+	classes := cfg.GetClientClasses()
+	require.Len(t, classes, 3)
+	require.Equal(t, "foo", classes[0].Name)
+	require.Equal(t, "bar", classes[1].Name)
+	require.Equal(t, "baz", classes[2].Name)
+	require.NotNil(t, classes[2].Test)
+	require.Equal(t, "member('KNOWN')", *classes[2].Test)
+
+	// The raw configuration should have been updated too.
+	rawClasses := cfg.Raw["Dhcp4"].(RawConfig)["client-classes"].([]any)
+	require.Len(t, rawClasses, 3)
+	require.Equal(t, "baz", rawClasses[2].(RawConfig)["name"])
+	// Unknown parameters of other classes should be preserved.
+	require.Equal(t, "baz", rawClasses[1].(RawConfig)["unknown-parameter"])
+	// The hash is no longer valid.
+	require.NotContains(t, cfg.Raw, "hash")
+}
+
+// Test that a client class can be added to the configuration lacking
+// the client-classes list.
+func TestAddClientClassNoClasses(t *testing.T) {
+	cfg, err := NewConfig(`{"Dhcp6": {}}`)
+	require.NoError(t, err)
+
+	err = cfg.AddClientClass(&ClientClass{
+		Name: "foo",
+		PreferredLifetimeParameters: PreferredLifetimeParameters{
+			PreferredLifetime: storkutil.Ptr(int64(3000)),
+		},
+	})
+	require.NoError(t, err)
+
+	classes := cfg.GetClientClasses()
+	require.Len(t, classes, 1)
+	require.Equal(t, "foo", classes[0].Name)
+	require.NotNil(t, classes[0].PreferredLifetime)
+	require.EqualValues(t, 3000, *classes[0].PreferredLifetime)
+}
+
+// Test that adding a duplicate client class fails.
+func TestAddClientClassDuplicate(t *testing.T) {
+	cfg := getTestConfigWithClientClasses(t)
+
+	err := cfg.AddClientClass(&ClientClass{
+		Name: "foo",
+	})
+	require.ErrorContains(t, err, "client class foo already exists")
+	require.Len(t, cfg.GetClientClasses(), 2)
+}
+
+// Test that adding a client class to a non-DHCP server configuration fails.
+func TestAddClientClassNonDHCP(t *testing.T) {
+	cfg, err := NewConfig(`{"Control-agent": {}}`)
+	require.NoError(t, err)
+
+	err = cfg.AddClientClass(&ClientClass{
+		Name: "foo",
+	})
+	require.ErrorContains(t, err, "client-classes can only be configured for the DHCP servers")
+}
+
+// Test that an existing client class can be updated and that its position
+// on the list of classes is preserved.
+func TestUpdateClientClass(t *testing.T) {
+	cfg := getTestConfigWithClientClasses(t)
+
+	err := cfg.UpdateClientClass(&ClientClass{
+		Name: "foo",
+		Test: storkutil.Ptr("member('UNKNOWN')"),
+	})
+	require.NoError(t, err)
+
+	classes := cfg.GetClientClasses()
+	require.Len(t, classes, 2)
+	require.Equal(t, "foo", classes[0].Name)
+	require.NotNil(t, classes[0].Test)
+	require.Equal(t, "member('UNKNOWN')", *classes[0].Test)
+	require.Nil(t, classes[0].NextServer)
+	require.Empty(t, classes[0].OptionData)
+	require.Equal(t, "bar", classes[1].Name)
+	require.NotContains(t, cfg.Raw, "hash")
+}
+
+// Test that updating a non-existing client class fails.
+func TestUpdateClientClassNonExisting(t *testing.T) {
+	cfg := getTestConfigWithClientClasses(t)
+
+	err := cfg.UpdateClientClass(&ClientClass{
+		Name: "baz",
+	})
+	require.ErrorContains(t, err, "client class baz does not exist")
+}
+
+// Test that a client class can be deleted from the configuration.
+func TestDeleteClientClass(t *testing.T) {
+	cfg := getTestConfigWithClientClasses(t)
+
+	err := cfg.DeleteClientClass("foo")
+	require.NoError(t, err)
+
+	classes := cfg.GetClientClasses()
+	require.Len(t, classes, 1)
+	require.Equal(t, "bar", classes[0].Name)
+
+	// Deleting the last class should remove the list.
+	err = cfg.DeleteClientClass("bar")
+	require.NoError(t, err)
+	require.Empty(t, cfg.GetClientClasses())
+	require.NotContains(t, cfg.Raw["Dhcp4"], "client-classes")
+}
+
+// Test that deleting a non-existing client class fails.
+func TestDeleteClientClassNonExisting(t *testing.T) {
+	cfg := getTestConfigWithClientClasses(t)
+
+	err := cfg.DeleteClientClass("baz")
+	require.ErrorContains(t, err, "client class baz does not exist")
+	require.Len(t, cfg.GetClientClasses(), 2)
+}
+
+// Test recognizing the built-in client classes.
+func TestIsBuiltinClientClass(t *testing.T) {
+	for _, name := range []string{"ALL", "KNOWN", "UNKNOWN", "BOOTP", "DROP", "VENDOR_CLASS_docsis3.0", "HA_server1"} {
+		require.True(t, IsBuiltinClientClass(name), name)
+	}
+	for _, name := range []string{"foo", "known", "VENDOR_CLASS"} {
+		require.False(t, IsBuiltinClientClass(name), name)
+	}
+}
@@ -1,16 +1,73 @@
 package keaconfig
 
+import (
+	"encoding/json"
+	"net"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
 var _ commonConfigAccessor = (*D2Config)(nil)
 
+// Direction of the DNS updates performed for the DDNS domains. The forward
+// domains hold the mappings of the names to the addresses. The reverse
+// domains hold the mappings of the addresses to the names.
+type D2DDNSDirection string
+
+// Valid DNS update directions.
+const (
+	D2ForwardDDNS D2DDNSDirection = "forward-ddns"
+	D2ReverseDDNS D2DDNSDirection = "reverse-ddns"
+)
+
 // Represents a D2 (DHCP-DDNS) Kea configuration.
 type D2Config struct {
-	HookLibraries []HookLibrary `json:"hooks-libraries,omitempty"`
-	Loggers       []Logger      `json:"loggers,omitempty"`
+	HookLibraries []HookLibrary    `json:"hooks-libraries,omitempty"`
+	Loggers       []Logger         `json:"loggers,omitempty"`
+	ForwardDDNS   *D2DDNSDomainSet `json:"forward-ddns,omitempty"`
+	ReverseDDNS   *D2DDNSDomainSet `json:"reverse-ddns,omitempty"`
+	TSIGKeys      []D2TSIGKey      `json:"tsig-keys,omitempty"`
 }
 
 // Represents settable D2 (DHCP-DDNS) Kea configuration.
 type SettableD2Config struct{}
 
+// Represents the forward-ddns or reverse-ddns configuration.
+type D2DDNSDomainSet struct {
+	DDNSDomains []D2DDNSDomain `json:"ddns-domains,omitempty"`
+}
+
+// Represents a DDNS domain. The D2 server sends the DNS updates for the
+// names belonging to the domain to the domain's DNS servers.
+type D2DDNSDomain struct {
+	Name       string        `json:"name"`
+	KeyName    string        `json:"key-name,omitempty"`
+	DNSServers []D2DNSServer `json:"dns-servers"`
+}
+
+// Represents a DNS server receiving the DNS updates for a DDNS domain.
+type D2DNSServer struct {
+	HostName  string `json:"hostname,omitempty"`
+	IPAddress string `json:"ip-address"`
+	Port      int64  `json:"port,omitempty"`
+	KeyName   string `json:"key-name,omitempty"`
+}
+
+// Represents a TSIG key used to sign the DNS updates.
+type D2TSIGKey struct {
+	Name       string `json:"name"`
+	Algorithm  string `json:"algorithm"`
+	DigestBits int64  `json:"digest-bits,omitempty"`
+	Secret     string `json:"secret,omitempty"`
+	SecretFile string `json:"secret-file,omitempty"`
+}
+
+// Checks if the direction is valid.
+func (direction D2DDNSDirection) IsValid() bool {
+	return direction == D2ForwardDDNS || direction == D2ReverseDDNS
+}
+
 // Returns the hook libraries configured in the D2 server.
 func (c *D2Config) GetHookLibraries() HookLibraries {
 	return c.HookLibraries
@@ -20,3 +77,198 @@ func (c *D2Config) GetHookLibraries() HookLibraries {
 func (c *D2Config) GetLoggers() []Logger {
 	return c.Loggers
 }
+
+// Returns the DDNS domains configured for the specified direction.
+func (c *D2Config) GetDDNSDomains(direction D2DDNSDirection) []D2DDNSDomain {
+	set := c.ForwardDDNS
+	if direction == D2ReverseDDNS {
+		set = c.ReverseDDNS
+	}
+	if set == nil {
+		return nil
+	}
+	return set.DDNSDomains
+}
+
+// Returns the TSIG key with the specified name or nil if it doesn't exist.
+func (c *D2Config) GetTSIGKey(name string) *D2TSIGKey {
+	for i := range c.TSIGKeys {
+		if c.TSIGKeys[i].Name == name {
+			return &c.TSIGKeys[i]
+		}
+	}
+	return nil
+}
+
+// Checks if the domain names are equal. The names are case-insensitive,
+// and the trailing dot is optional.
+func isSameDomainName(name1, name2 string) bool {
+	return strings.EqualFold(strings.TrimSuffix(name1, "."), strings.TrimSuffix(name2, "."))
+}
+
+// Validates the DDNS domain. It checks that the domain has a name and at
+// least one DNS server with a valid IP address. The TSIG keys referenced
+// by the domain and its servers must be configured in the D2 server.
+func (d D2DDNSDomain) Validate(config *D2Config) error {
+	if len(strings.TrimSpace(d.Name)) == 0 {
+		return errors.New("DDNS domain name must not be empty")
+	}
+	if len(d.DNSServers) == 0 {
+		return errors.Errorf("DDNS domain %s must have at least one DNS server", d.Name)
+	}
+	keyNames := []string{d.KeyName}
+	for _, server := range d.DNSServers {
+		if net.ParseIP(server.IPAddress) == nil {
+			return errors.Errorf("invalid IP address %s of the DNS server in DDNS domain %s", server.IPAddress, d.Name)
+		}
+		if server.Port < 0 || server.Port > 65535 {
+			return errors.Errorf("invalid port %d of the DNS server in DDNS domain %s", server.Port, d.Name)
+		}
+		keyNames = append(keyNames, server.KeyName)
+	}
+	for _, keyName := range keyNames {
+		if len(keyName) > 0 && config.GetTSIGKey(keyName) == nil {
+			return errors.Errorf("TSIG key %s used in DDNS domain %s does not exist", keyName, d.Name)
+		}
+	}
+	return nil
+}
+
+// Returns the DDNS domains configured in the D2 server for the specified
+// direction.
+func (c *Config) GetDDNSDomains(direction D2DDNSDirection) []D2DDNSDomain {
+	if !c.IsD2() {
+		return nil
+	}
+	return c.D2Config.GetDDNSDomains(direction)
+}
+
+// Returns the DDNS domain with the specified name and direction or nil
+// if such domain doesn't exist.
+func (c *Config) GetDDNSDomain(direction D2DDNSDirection, name string) *D2DDNSDomain {
+	for _, domain := range c.GetDDNSDomains(direction) {
+		if isSameDomainName(domain.Name, name) {
+			return &domain
+		}
+	}
+	return nil
+}
+
+// Returns the raw list of the DDNS domains for the specified direction.
+// The second returned value is the map holding the list (i.e., the map
+// under the forward-ddns or reverse-ddns key). The map is created when
+// it doesn't exist. It returns an error if the configuration does not
+// belong to a D2 server.
+func (c *Config) getRawDDNSDomains(direction D2DDNSDirection) ([]any, RawConfig, error) {
+	if !c.IsD2() {
+		return nil, nil, errors.New("DDNS domains can only be configured for the D2 servers")
+	}
+	if !direction.IsValid() {
+		return nil, nil, errors.Errorf("invalid DDNS domain direction %s", direction)
+	}
+	root, ok := c.Raw["DhcpDdns"].(RawConfig)
+	if !ok {
+		return nil, nil, errors.New("invalid DhcpDdns configuration structure")
+	}
+	set, ok := root[string(direction)].(RawConfig)
+	if !ok {
+		if root[string(direction)] != nil {
+			return nil, nil, errors.Errorf("invalid %s configuration structure", direction)
+		}
+		set = RawConfig{}
+		root[string(direction)] = set
+	}
+	var list []any
+	if set["ddns-domains"] != nil {
+		if list, ok = set["ddns-domains"].([]any); !ok {
+			return nil, nil, errors.Errorf("invalid %s configuration structure", direction)
+		}
+	}
+	return list, set, nil
+}
+
+// Finds the index of the DDNS domain in the raw list of the DDNS domains.
+// It returns -1 if the domain doesn't exist.
+func findRawDDNSDomain(list []any, name string) int {
+	for i, item := range list {
+		if raw, ok := item.(RawConfig); ok {
+			if rawName, ok := raw["name"].(string); ok && isSameDomainName(rawName, name) {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// Replaces the raw list of the DDNS domains and parses the updated raw
+// configuration into the server-specific structures.
+func (c *Config) setRawDDNSDomains(set RawConfig, list []any) error {
+	set["ddns-domains"] = list
+	// The configuration has changed, so the hash is no longer valid.
+	delete(c.Raw, "hash")
+	data, err := json.Marshal(c.Raw)
+	if err != nil {
+		return errors.Wrap(err, "problem serializing Kea configuration with modified DDNS domains")
+	}
+	err = c.unmarshalIntoAccessibleConfig(data)
+	return errors.WithMessage(err, "problem parsing Kea configuration with modified DDNS domains")
+}
+
+// Appends a new DDNS domain to the D2 server configuration. It returns an
+// error if the domain is invalid or the domain with the same name already
+// exists for the direction.
+func (c *Config) AddDDNSDomain(direction D2DDNSDirection, domain *D2DDNSDomain) error {
+	list, set, err := c.getRawDDNSDomains(direction)
+	if err != nil {
+		return err
+	}
+	if err = domain.Validate(c.D2Config); err != nil {
+		return err
+	}
+	if findRawDDNSDomain(list, domain.Name) >= 0 {
+		return errors.Errorf("DDNS domain %s already exists in %s", domain.Name, direction)
+	}
+	raw, err := convertToRawConfig(domain)
+	if err != nil {
+		return err
+	}
+	return c.setRawDDNSDomains(set, append(list, raw))
+}
+
+// Replaces an existing DDNS domain in the D2 server configuration. The
+// domain is found by name. It returns an error if the domain is invalid
+// or does not exist.
+func (c *Config) UpdateDDNSDomain(direction D2DDNSDirection, domain *D2DDNSDomain) error {
+	list, set, err := c.getRawDDNSDomains(direction)
+	if err != nil {
+		return err
+	}
+	if err = domain.Validate(c.D2Config); err != nil {
+		return err
+	}
+	index := findRawDDNSDomain(list, domain.Name)
+	if index < 0 {
+		return errors.Errorf("DDNS domain %s does not exist in %s", domain.Name, direction)
+	}
+	raw, err := convertToRawConfig(domain)
+	if err != nil {
+		return err
+	}
+	list[index] = raw
+	return c.setRawDDNSDomains(set, list)
+}
+
+// Removes the DDNS domain with the specified name from the D2 server
+// configuration. It returns an error if the domain does not exist.
+func (c *Config) DeleteDDNSDomain(direction D2DDNSDirection, name string) error {
+	list, set, err := c.getRawDDNSDomains(direction)
+	if err != nil {
+		return err
+	}
+	index := findRawDDNSDomain(list, name)
+	if index < 0 {
+		return errors.Errorf("DDNS domain %s does not exist in %s", name, direction)
+	}
+	list = append(list[:index], list[index+1:]...)
+	return c.setRawDDNSDomains(set, list)
+}
@@ -40,3 +40,128 @@ func TestGetD2Loggers(t *testing.T) {
 	require.Equal(t, "DEBUG", libraries[0].Severity)
 	require.EqualValues(t, 99, libraries[0].DebugLevel)
 }
+
+// Returns a test D2 server configuration with DDNS domains and TSIG keys.
+func getTestD2Config(t *testing.T) *Config {
+	config, err := NewConfig(`{
+		"DhcpDdns": {
+			"tsig-keys": [
+				{
+					"name": "key1",
+					"algorithm": "HMAC-SHA256",
+					"secret": "LSWXnfkKZjdPJI5QxlpnfQ=="
+				}
+			],
+			"forward-ddns": {
+				"ddns-domains": [
+					{
+						"name": "example.org.",
+						"key-name": "key1",
+						"dns-servers": [
+							{
+								"ip-address": "192.0.2.1",
+								"port": 53
+							}
+						]
+					}
+				]
+			}
+		}
+	}`)
+	require.NoError(t, err)
+	return config
+}
+
+// Test getting the DDNS domains and TSIG keys from the D2 configuration.
+func TestGetDDNSDomains(t *testing.T) {
+	config := getTestD2Config(t)
+
+	domains := config.GetDDNSDomains(D2ForwardDDNS)
+	require.Len(t, domains, 1)
+	require.Equal(t, "example.org.", domains[0].Name)
+	require.Equal(t, "key1", domains[0].KeyName)
+	require.Len(t, domains[0].DNSServers, 1)
+	require.Equal(t, "192.0.2.1", domains[0].DNSServers[0].IPAddress)
+	require.EqualValues(t, 53, domains[0].DNSServers[0].Port)
+	require.Empty(t, config.GetDDNSDomains(D2ReverseDDNS))
+
+	// The names are case-insensitive and the trailing dot is optional.
+	require.NotNil(t, config.GetDDNSDomain(D2ForwardDDNS, "EXAMPLE.org"))
+	require.Nil(t, config.GetDDNSDomain(D2ReverseDDNS, "example.org."))
+
+	key := config.D2Config.GetTSIGKey("key1")
+	require.NotNil(t, key)
+	require.Equal(t, "HMAC-SHA256", key.Algorithm)
+	require.Nil(t, config.D2Config.GetTSIGKey("key2"))
+}
+
+// Test validating the DDNS domain.
+func TestValidateDDNSDomain(t *testing.T) {
+	config := getTestD2Config(t)
+
+	domain := D2DDNSDomain{
+		Name:    "example.com.",
+		KeyName: "key1",
+		DNSServers: []D2DNSServer{
+			{
+				IPAddress: "2001:db8:1::1",
+			},
+		},
+	}
+	require.NoError(t, domain.Validate(config.D2Config))
+
+	domain.KeyName = "key2"
+	require.ErrorContains(t, domain.Validate(config.D2Config), "TSIG key key2")
+	domain.KeyName = ""
+
+	domain.DNSServers[0].IPAddress = "foo"
+	require.ErrorContains(t, domain.Validate(config.D2Config), "invalid IP address foo")
+
+	domain.DNSServers = nil
+	require.ErrorContains(t, domain.Validate(config.D2Config), "at least one DNS server")
+
+	domain.Name = ""
+	require.ErrorContains(t, domain.Validate(config.D2Config), "name must not be empty")
+}
+
+// Test adding, updating and deleting the DDNS domains.
+func TestAddUpdateDeleteDDNSDomain(t *testing.T) {
+	config := getTestD2Config(t)
+
+	domain := &D2DDNSDomain{
+		Name: "2.0.192.in-addr.arpa.",
+		DNSServers: []D2DNSServer{
+			{
+				IPAddress: "192.0.2.1",
+			},
+		},
+	}
+	require.NoError(t, config.AddDDNSDomain(D2ReverseDDNS, domain))
+	require.Len(t, config.GetDDNSDomains(D2ReverseDDNS), 1)
+	require.Len(t, config.GetDDNSDomains(D2ForwardDDNS), 1)
+	require.Error(t, config.AddDDNSDomain(D2ReverseDDNS, domain))
+
+	domain.KeyName = "key1"
+	require.NoError(t, config.UpdateDDNSDomain(D2ReverseDDNS, domain))
+	require.Equal(t, "key1", config.GetDDNSDomain(D2ReverseDDNS, domain.Name).KeyName)
+
+	// The raw configuration should be updated too.
+	raw, err := config.GetRawConfig()
+	require.NoError(t, err)
+	rawDomains := raw["DhcpDdns"].(RawConfig)["reverse-ddns"].(RawConfig)["ddns-domains"].([]any)
+	require.Len(t, rawDomains, 1)
+	require.Equal(t, "key1", rawDomains[0].(RawConfig)["key-name"])
+
+	require.NoError(t, config.DeleteDDNSDomain(D2ForwardDDNS, "example.org"))
+	require.Empty(t, config.GetDDNSDomains(D2ForwardDDNS))
+	require.Error(t, config.DeleteDDNSDomain(D2ForwardDDNS, "example.org"))
+	require.Error(t, config.UpdateDDNSDomain(D2ForwardDDNS, domain))
+
+	// Invalid direction.
+	require.Error(t, config.AddDDNSDomain("foo", domain))
+
+	// Not a D2 configuration.
+	config, err = NewConfig(`{ "Dhcp4": { } }`)
+	require.NoError(t, err)
+	require.Error(t, config.AddDDNSDomain(D2ForwardDDNS, domain))
+}
@@ -9,6 +9,10 @@ type DatabaseConfig interface {
 	GetAllDatabases() Databases
 }
 
+// Server tag associating the configuration elements held in the
+// configuration backend with all servers.
+const ServerTagAll = "all"
+
 // Configuration of the Kea configuration backend connections.
 type ConfigControl struct {
 	ConfigDatabases     []Database `json:"config-databases"`
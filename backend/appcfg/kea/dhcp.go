@@ -202,7 +202,9 @@ type CommonDHCPConfig struct {
 	Loggers                 []Logger                 `json:"loggers,omitempty"`
 	MultiThreading          *MultiThreading          `json:"multi-threading,omitempty"`
 	OptionData              []SingleOptionData       `json:"option-data,omitempty"`
+	OptionDefs              []OptionDef              `json:"option-def,omitempty"`
 	Reservations            []Reservation            `json:"reservations,omitempty"`
+	ServerTag               *string                  `json:"server-tag,omitempty"`
 	StoreExtendedInfo       *bool                    `json:"store-extended-info,omitempty"`
 }
 
@@ -563,7 +563,7 @@ func TestSetDHCPv4OptionData(t *testing.T) {
 			Name:       "routers",
 			AlwaysSend: true,
 			Code:       3,
-			CSVFormat:  true,
+			CSVFormat:  storkutil.Ptr(true),
 			Data:       "foobar",
 			Space:      "dhcp4",
 		},
@@ -573,7 +573,7 @@ func TestSetDHCPv4OptionData(t *testing.T) {
 	require.Equal(t, "routers", options[0].Name)
 	require.True(t, options[0].AlwaysSend)
 	require.EqualValues(t, 3, options[0].Code)
-	require.True(t, options[0].CSVFormat)
+	require.Equal(t, storkutil.Ptr(true), options[0].CSVFormat)
 	require.Equal(t, "foobar", options[0].Data)
 	require.Equal(t, "dhcp4", options[0].Space)
 
@@ -592,7 +592,7 @@ func TestSetDHCPv4Options(t *testing.T) {
 			Name:       "routers",
 			AlwaysSend: true,
 			Code:       3,
-			CSVFormat:  true,
+			CSVFormat:  storkutil.Ptr(true),
 			Data:       "foobar",
 			Space:      "dhcp4",
 		},
@@ -605,7 +605,7 @@ func TestSetDHCPv4Options(t *testing.T) {
 	require.Equal(t, "routers", option.Name)
 	require.True(t, option.AlwaysSend)
 	require.EqualValues(t, 3, option.Code)
-	require.True(t, option.CSVFormat)
+	require.Equal(t, storkutil.Ptr(true), option.CSVFormat)
 	require.Equal(t, "foobar", option.Data)
 	require.Equal(t, "dhcp4", option.Space)
 }
@@ -1167,7 +1167,7 @@ func TestSetDHCPv6OptionData(t *testing.T) {
 			Name:       "routers",
 			AlwaysSend: true,
 			Code:       3,
-			CSVFormat:  true,
+			CSVFormat:  storkutil.Ptr(true),
 			Data:       "foobar",
 			Space:      "dhcp6",
 		},
@@ -1177,7 +1177,7 @@ func TestSetDHCPv6OptionData(t *testing.T) {
 	require.Equal(t, "routers", options[0].Name)
 	require.True(t, options[0].AlwaysSend)
 	require.EqualValues(t, 3, options[0].Code)
-	require.True(t, options[0].CSVFormat)
+	require.Equal(t, storkutil.Ptr(true), options[0].CSVFormat)
 	require.Equal(t, "foobar", options[0].Data)
 	require.Equal(t, "dhcp6", options[0].Space)
 
@@ -1196,7 +1196,7 @@ func TestSetDHCPv6Options(t *testing.T) {
 			Name:       "routers",
 			AlwaysSend: true,
 			Code:       3,
-			CSVFormat:  true,
+			CSVFormat:  storkutil.Ptr(true),
 			Data:       "foobar",
 			Space:      "dhcp6",
 		},
@@ -1209,7 +1209,7 @@ func TestSetDHCPv6Options(t *testing.T) {
 	require.Equal(t, "routers", option.Name)
 	require.True(t, option.AlwaysSend)
 	require.EqualValues(t, 3, option.Code)
-	require.True(t, option.CSVFormat)
+	require.Equal(t, storkutil.Ptr(true), option.CSVFormat)
 	require.Equal(t, "foobar", option.Data)
 	require.Equal(t, "dhcp6", option.Space)
 }
@@ -0,0 +1,479 @@
+package keaconfig
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/alecthomas/participle/v2"
+	"github.com/alecthomas/participle/v2/lexer"
+	"github.com/pkg/errors"
+)
+
+// Type of the value a client classification expression (or its part)
+// evaluates to.
+type expressionType int
+
+const (
+	expressionTypeBoolean expressionType = iota
+	expressionTypeString
+	expressionTypeInteger
+)
+
+// Returns the expression type name used in the error messages.
+func (t expressionType) String() string {
+	switch t {
+	case expressionTypeBoolean:
+		return "boolean"
+	case expressionTypeInteger:
+		return "integer"
+	default:
+		return "string"
+	}
+}
+
+// Checks if the value of the specified type can be used where the value
+// of this type is expected. Kea accepts integers where strings are
+// expected.
+func (t expressionType) accepts(other expressionType) bool {
+	return t == other || (t == expressionTypeString && other == expressionTypeInteger)
+}
+
+// Signature of a function available in the client classification
+// expressions.
+type expressionFunction struct {
+	args   []expressionType
+	result expressionType
+}
+
+// Functions available in the client classification expressions.
+var expressionFunctions = map[string]expressionFunction{
+	"member":       {[]expressionType{expressionTypeString}, expressionTypeBoolean},
+	"substring":    {[]expressionType{expressionTypeString, expressionTypeInteger, expressionTypeInteger}, expressionTypeString},
+	"split":        {[]expressionType{expressionTypeString, expressionTypeString, expressionTypeInteger}, expressionTypeString},
+	"concat":       {[]expressionType{expressionTypeString, expressionTypeString}, expressionTypeString},
+	"ifelse":       {[]expressionType{expressionTypeBoolean, expressionTypeString, expressionTypeString}, expressionTypeString},
+	"hexstring":    {[]expressionType{expressionTypeString, expressionTypeString}, expressionTypeString},
+	"addrtotext":   {[]expressionType{expressionTypeString}, expressionTypeString},
+	"int8totext":   {[]expressionType{expressionTypeString}, expressionTypeString},
+	"int16totext":  {[]expressionType{expressionTypeString}, expressionTypeString},
+	"int32totext":  {[]expressionType{expressionTypeString}, expressionTypeString},
+	"uint8totext":  {[]expressionType{expressionTypeString}, expressionTypeString},
+	"uint16totext": {[]expressionType{expressionTypeString}, expressionTypeString},
+	"uint32totext": {[]expressionType{expressionTypeString}, expressionTypeString},
+	"lcase":        {[]expressionType{expressionTypeString}, expressionTypeString},
+	"ucase":        {[]expressionType{expressionTypeString}, expressionTypeString},
+}
+
+// Packet fields and option values available in the client classification
+// expressions. The keys are the references with the indexes stripped,
+// e.g., option[61].hex is represented as option[].hex.
+var expressionReferences = map[string]expressionType{
+	"option[].hex":             expressionTypeString,
+	"option[].text":            expressionTypeString,
+	"option[].exists":          expressionTypeBoolean,
+	"option[].option[].hex":    expressionTypeString,
+	"option[].option[].text":   expressionTypeString,
+	"option[].option[].exists": expressionTypeBoolean,
+	"relay4[].hex":             expressionTypeString,
+	"relay4[].text":            expressionTypeString,
+	"relay4[].exists":          expressionTypeBoolean,
+	"relay6[].peeraddr":        expressionTypeString,
+	"relay6[].linkaddr":        expressionTypeString,
+	"relay6[].option[].hex":    expressionTypeString,
+	"relay6[].option[].text":   expressionTypeString,
+	"relay6[].option[].exists": expressionTypeBoolean,
+	"vendor.enterprise":        expressionTypeString,
+	"vendor[].exists":          expressionTypeBoolean,
+	"vendor[].option[].hex":    expressionTypeString,
+	"vendor[].option[].exists": expressionTypeBoolean,
+	"vendor-class.enterprise":  expressionTypeString,
+	"vendor-class[].exists":    expressionTypeBoolean,
+	"vendor-class[].data":      expressionTypeString,
+	"vendor-class[].data[]":    expressionTypeString,
+	"pkt.iface":                expressionTypeString,
+	"pkt.src":                  expressionTypeString,
+	"pkt.dst":                  expressionTypeString,
+	"pkt.len":                  expressionTypeString,
+	"pkt4.mac":                 expressionTypeString,
+	"pkt4.hlen":                expressionTypeString,
+	"pkt4.htype":               expressionTypeString,
+	"pkt4.ciaddr":              expressionTypeString,
+	"pkt4.giaddr":              expressionTypeString,
+	"pkt4.yiaddr":              expressionTypeString,
+	"pkt4.siaddr":              expressionTypeString,
+	"pkt4.msgtype":             expressionTypeString,
+	"pkt4.transid":             expressionTypeString,
+	"pkt6.msgtype":             expressionTypeString,
+	"pkt6.transid":             expressionTypeString,
+}
+
+// Expression is the root of the Kea client classification expression AST.
+// It is a list of the operands of the "or" operator. An expression without
+// the "or" operator has a single operand.
+type Expression struct {
+	Pos lexer.Position
+	Or  []*AndExpression `parser:"@@ ( 'or' @@ )*"`
+
+	// Type of the value the expression evaluates to.
+	valueType expressionType
+	// Names of the classes referenced in the member() functions and
+	// the known and unknown keywords.
+	referencedClasses []string
+}
+
+// AndExpression is a list of the operands of the "and" operator.
+type AndExpression struct {
+	Pos lexer.Position
+	And []*NotExpression `parser:"@@ ( 'and' @@ )*"`
+}
+
+// NotExpression is an optionally negated comparison.
+type NotExpression struct {
+	Pos        lexer.Position
+	Not        *NotExpression        `parser:"  'not' @@"`
+	Comparison *ComparisonExpression `parser:"| @@"`
+}
+
+// ComparisonExpression is a nested expression in parentheses, a comparison
+// of two values or a single value.
+type ComparisonExpression struct {
+	Pos    lexer.Position
+	Nested *Expression       `parser:"  '(' @@ ')'"`
+	Left   *ConcatExpression `parser:"| @@"`
+	Right  *ConcatExpression `parser:"  ( '==' @@ )?"`
+}
+
+// ConcatExpression is a list of the operands of the "+" (concatenation)
+// operator.
+type ConcatExpression struct {
+	Pos      lexer.Position
+	Operands []*Operand `parser:"@@ ( '+' @@ )*"`
+}
+
+// Operand is a literal, a function call or a reference to a packet field
+// or an option.
+type Operand struct {
+	Pos       lexer.Position
+	String    *string       `parser:"  @String"`
+	HexString *string       `parser:"| @HexString"`
+	IPAddress *string       `parser:"| @( IPv4Address | IPv6Address )"`
+	Integer   *string       `parser:"| @Integer"`
+	Call      *FunctionCall `parser:"| @@"`
+	Reference *Reference    `parser:"| @@"`
+}
+
+// FunctionCall is a call of a function, e.g., substring().
+type FunctionCall struct {
+	Pos  lexer.Position
+	Name string        `parser:"@Ident '('"`
+	Args []*Expression `parser:"( @@ ( ',' @@ )* )? ')'"`
+}
+
+// Reference is a reference to a packet field or an option, e.g.,
+// option[61].hex or pkt4.mac.
+type Reference struct {
+	Pos    lexer.Position
+	Name   string            `parser:"@Ident"`
+	Index  *string           `parser:"( '[' @( Integer | Ident | '*' ) ']' )?"`
+	Fields []*ReferenceField `parser:"( '.' @@ )*"`
+}
+
+// ReferenceField is a single field of a reference, e.g., hex in
+// option[61].hex.
+type ReferenceField struct {
+	Pos   lexer.Position
+	Name  string  `parser:"@Ident"`
+	Index *string `parser:"( '[' @( Integer | Ident ) ']' )?"`
+}
+
+// Parses the Kea client classification expression, e.g., the test or
+// template-test parameter of a client class. Besides the syntax, it
+// verifies that the functions, packet fields and options are known, and
+// the types of the arguments and operands are correct. It doesn't verify
+// the existence of the referenced client classes.
+func ParseExpression(text string) (*Expression, error) {
+	expressionLexer := lexer.MustSimple([]lexer.SimpleRule{
+		// Strings are enclosed in single quotes.
+		{Name: "String", Pattern: `'[^']*'`},
+		{Name: "HexString", Pattern: `0[xX][0-9a-fA-F]+`},
+		{Name: "IPv4Address", Pattern: `(?:[0-9]{1,3}\.){3}[0-9]{1,3}`},
+		{Name: "IPv6Address", Pattern: `(?:[0-9a-fA-F]{0,4}:){2,7}[0-9a-fA-F]{0,4}`},
+		{Name: "Integer", Pattern: `-?[0-9]+`},
+		// Keywords, function names, packet fields and option names.
+		{Name: "Ident", Pattern: `[a-zA-Z_][a-zA-Z0-9_-]*`},
+		{Name: "Operator", Pattern: `==|[()\[\],.+*]`},
+		{Name: "Whitespace", Pattern: `[ \t\n\r]+`},
+	})
+
+	parser := participle.MustBuild[Expression](
+		participle.Lexer(expressionLexer),
+		// Remove the single quotes from the strings.
+		participle.Map(func(token lexer.Token) (lexer.Token, error) {
+			token.Value = token.Value[1 : len(token.Value)-1]
+			return token, nil
+		}, "String"),
+		participle.Elide("Whitespace"),
+		participle.UseLookahead(2),
+	)
+	expression, err := parser.ParseString("", text)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse expression %s", text)
+	}
+	validator := &expressionValidator{}
+	valueType, err := validator.validateExpression(expression)
+	if err != nil {
+		return nil, errors.WithMessagef(err, "invalid expression %s", text)
+	}
+	expression.valueType = valueType
+	expression.referencedClasses = validator.referencedClasses
+	return expression, nil
+}
+
+// Checks if the expression evaluates to a boolean value. The test
+// expressions must evaluate to a boolean value.
+func (e *Expression) IsBoolean() bool {
+	return e.valueType == expressionTypeBoolean
+}
+
+// Returns the names of the client classes referenced in the expression
+// using the member() function or the known and unknown keywords. The names
+// are returned in the order of their occurrence and without duplicates.
+func (e *Expression) GetReferencedClasses() []string {
+	return e.referencedClasses
+}
+
+// Returns the operand if the expression consists of a single operand.
+// Otherwise, it returns nil.
+func (e *Expression) getOperand() *Operand {
+	if len(e.Or) != 1 || len(e.Or[0].And) != 1 {
+		return nil
+	}
+	comparison := e.Or[0].And[0].Comparison
+	if comparison == nil || comparison.Left == nil || comparison.Right != nil || len(comparison.Left.Operands) != 1 {
+		return nil
+	}
+	return comparison.Left.Operands[0]
+}
+
+// Checks if the reference is a keyword (i.e., a name without an index and
+// fields) with the specified name.
+func (r *Reference) isKeyword(name string) bool {
+	return r.Name == name && r.Index == nil && len(r.Fields) == 0
+}
+
+// Walks over the parsed expression, verifies the types of the operands
+// and collects the referenced client classes.
+type expressionValidator struct {
+	referencedClasses []string
+}
+
+// Verifies that all operands of the logical operators are boolean.
+func requireBooleanOperands(pos lexer.Position, operator string, valueTypes []expressionType) error {
+	for _, valueType := range valueTypes {
+		if valueType != expressionTypeBoolean {
+			return errors.Errorf("%s: operand of the %s operator must be boolean but is %s", pos, operator, valueType)
+		}
+	}
+	return nil
+}
+
+// Validates the expression and returns its value type.
+func (v *expressionValidator) validateExpression(e *Expression) (expressionType, error) {
+	var valueTypes []expressionType
+	for _, operand := range e.Or {
+		valueType, err := v.validateAndExpression(operand)
+		if err != nil {
+			return valueType, err
+		}
+		valueTypes = append(valueTypes, valueType)
+	}
+	if len(valueTypes) == 1 {
+		return valueTypes[0], nil
+	}
+	return expressionTypeBoolean, requireBooleanOperands(e.Pos, "or", valueTypes)
+}
+
+// Validates the "and" expression and returns its value type.
+func (v *expressionValidator) validateAndExpression(e *AndExpression) (expressionType, error) {
+	var valueTypes []expressionType
+	for _, operand := range e.And {
+		valueType, err := v.validateNotExpression(operand)
+		if err != nil {
+			return valueType, err
+		}
+		valueTypes = append(valueTypes, valueType)
+	}
+	if len(valueTypes) == 1 {
+		return valueTypes[0], nil
+	}
+	return expressionTypeBoolean, requireBooleanOperands(e.Pos, "and", valueTypes)
+}
+
+// Validates the "not" expression and returns its value type.
+func (v *expressionValidator) validateNotExpression(e *NotExpression) (expressionType, error) {
+	if e.Not == nil {
+		return v.validateComparisonExpression(e.Comparison)
+	}
+	valueType, err := v.validateNotExpression(e.Not)
+	if err != nil {
+		return valueType, err
+	}
+	return expressionTypeBoolean, requireBooleanOperands(e.Pos, "not", []expressionType{valueType})
+}
+
+// Validates the nested expression or comparison and returns its value
+// type.
+func (v *expressionValidator) validateComparisonExpression(e *ComparisonExpression) (expressionType, error) {
+	if e.Nested != nil {
+		valueType, err := v.validateExpression(e.Nested)
+		if err != nil {
+			return valueType, err
+		}
+		if valueType != expressionTypeBoolean {
+			return valueType, errors.Errorf("%s: expression in parentheses must be boolean but is %s", e.Pos, valueType)
+		}
+		return valueType, nil
+	}
+	leftType, err := v.validateConcatExpression(e.Left)
+	if err != nil || e.Right == nil {
+		return leftType, err
+	}
+	rightType, err := v.validateConcatExpression(e.Right)
+	if err != nil {
+		return rightType, err
+	}
+	for _, valueType := range []expressionType{leftType, rightType} {
+		if !expressionTypeString.accepts(valueType) {
+			return expressionTypeBoolean, errors.Errorf("%s: operand of the == operator must be a string but is %s", e.Pos, valueType)
+		}
+	}
+	return expressionTypeBoolean, nil
+}
+
+// Validates the concatenation and returns its value type.
+func (v *expressionValidator) validateConcatExpression(e *ConcatExpression) (expressionType, error) {
+	var valueTypes []expressionType
+	for _, operand := range e.Operands {
+		valueType, err := v.validateOperand(operand)
+		if err != nil {
+			return valueType, err
+		}
+		valueTypes = append(valueTypes, valueType)
+	}
+	if len(valueTypes) == 1 {
+		return valueTypes[0], nil
+	}
+	for _, valueType := range valueTypes {
+		if !expressionTypeString.accepts(valueType) {
+			return expressionTypeString, errors.Errorf("%s: operand of the + operator must be a string but is %s", e.Pos, valueType)
+		}
+	}
+	return expressionTypeString, nil
+}
+
+// Validates the operand and returns its value type.
+func (v *expressionValidator) validateOperand(o *Operand) (expressionType, error) {
+	switch {
+	case o.Integer != nil:
+		return expressionTypeInteger, nil
+	case o.Call != nil:
+		return v.validateFunctionCall(o.Call)
+	case o.Reference != nil:
+		return v.validateReference(o.Reference)
+	default:
+		return expressionTypeString, nil
+	}
+}
+
+// Validates the function call and returns its value type.
+func (v *expressionValidator) validateFunctionCall(c *FunctionCall) (expressionType, error) {
+	function, ok := expressionFunctions[c.Name]
+	if !ok {
+		return expressionTypeString, errors.Errorf("%s: unknown function %s", c.Pos, c.Name)
+	}
+	if len(c.Args) != len(function.args) {
+		return function.result, errors.Errorf("%s: function %s expects %d arguments but %d were given",
+			c.Pos, c.Name, len(function.args), len(c.Args))
+	}
+	for i, arg := range c.Args {
+		operand := arg.getOperand()
+		switch {
+		case c.Name == "member":
+			// The class name must be a string literal.
+			if operand == nil || operand.String == nil {
+				return function.result, errors.Errorf("%s: argument of the member function must be a class name in quotes", arg.Pos)
+			}
+			v.addReferencedClass(*operand.String)
+			continue
+		case c.Name == "substring" && i == 2 && operand != nil && operand.Reference != nil && operand.Reference.isKeyword("all"):
+			// The substring length can be specified as "all".
+			continue
+		}
+		valueType, err := v.validateExpression(arg)
+		if err != nil {
+			return function.result, err
+		}
+		if function.args[i] == expressionTypeInteger && (operand == nil || operand.Integer == nil) {
+			return function.result, errors.Errorf("%s: argument %d of the %s function must be an integer literal", arg.Pos, i+1, c.Name)
+		}
+		if !function.args[i].accepts(valueType) {
+			return function.result, errors.Errorf("%s: argument %d of the %s function must be %s but is %s",
+				arg.Pos, i+1, c.Name, function.args[i], valueType)
+		}
+	}
+	return function.result, nil
+}
+
+// Records the client class referenced in the expression unless it has
+// already been recorded.
+func (v *expressionValidator) addReferencedClass(name string) {
+	if !slices.Contains(v.referencedClasses, name) {
+		v.referencedClasses = append(v.referencedClasses, name)
+	}
+}
+
+// Validates the reference to a packet field or an option and returns its
+// value type.
+func (v *expressionValidator) validateReference(r *Reference) (expressionType, error) {
+	if r.isKeyword("known") || r.isKeyword("unknown") {
+		// The known and unknown keywords are the shorthands of
+		// member('KNOWN') and not member('KNOWN').
+		v.addReferencedClass("KNOWN")
+		return expressionTypeBoolean, nil
+	}
+	key := r.Name
+	if r.Index != nil {
+		isInteger := isExpressionInteger(*r.Index)
+		switch {
+		case *r.Index == "*" && r.Name != "vendor" && r.Name != "vendor-class":
+			return expressionTypeString, errors.Errorf("%s: %s does not accept a wildcard index", r.Pos, r.Name)
+		case *r.Index != "*" && !isInteger && r.Name != "option":
+			return expressionTypeString, errors.Errorf("%s: %s index must be an integer", r.Pos, r.Name)
+		}
+		key += "[]"
+	}
+	for _, field := range r.Fields {
+		key += "." + field.Name
+		if field.Index != nil {
+			if !isExpressionInteger(*field.Index) && field.Name != "option" {
+				return expressionTypeString, errors.Errorf("%s: %s index must be an integer", field.Pos, field.Name)
+			}
+			key += "[]"
+		}
+	}
+	valueType, ok := expressionReferences[key]
+	if !ok {
+		return expressionTypeString, errors.Errorf("%s: unknown token %s", r.Pos, strings.ReplaceAll(key, "[]", "[...]"))
+	}
+	return valueType, nil
+}
+
+// Checks if the token is an integer.
+func isExpressionInteger(token string) bool {
+	for i, c := range token {
+		if (c < '0' || c > '9') && (i > 0 || c != '-') {
+			return false
+		}
+	}
+	return len(token) > 0
+}
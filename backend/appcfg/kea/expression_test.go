@@ -0,0 +1,125 @@
+package keaconfig
+
+import (
+	"testing"
+
+	require "github.com/stretchr/testify/require"
+)
+
+// Test parsing valid boolean expressions.
+func TestParseBooleanExpression(t *testing.T) {
+	expressions := []string{
+		"substring(option[61].hex,0,3) == 'foo'",
+		"option[host-name].text == 'example' or pkt4.mac == 0x010203040506",
+		"option[60].exists and not option[61].exists",
+		"option[82].option[1].hex == 0x0102",
+		"relay4[1].exists",
+		"relay6[0].option[37].hex == 0x1234 and relay6[1].peeraddr == 2001:db8::1",
+		"vendor[*].exists or vendor[4491].option[1].exists",
+		"vendor.enterprise == 4491",
+		"vendor-class[4491].data[1] == 'docsis3.0' or vendor-class.enterprise == 4491",
+		"pkt.src == 192.0.2.1 and pkt.iface == 'eth0'",
+		"pkt6.msgtype == 1 or pkt6.transid == 0x0102",
+		"substring('foobar', -4, all) == 'ob'",
+		"split(option[12].text, '.', 1) == 'host'",
+		"ifelse(option[1].exists, 'yes', 'no') == 'yes'",
+		"lcase(concat(option[12].text, 'a')) + ucase('b') == 'hosta' + 'B'",
+		"(member('foo') or member('bar')) and not member('foo')",
+		"not not member('KNOWN')",
+		"known and option[1].exists",
+		"unknown or not known",
+		"ifelse(unknown, 'a', 'b') == 'a'",
+	}
+	for _, text := range expressions {
+		expression, err := ParseExpression(text)
+		require.NoError(t, err, text)
+		require.NotNil(t, expression, text)
+		require.True(t, expression.IsBoolean(), text)
+	}
+}
+
+// Test parsing valid string expressions.
+func TestParseStringExpression(t *testing.T) {
+	expressions := []string{
+		"option[61].hex",
+		"substring(option[61].hex, 0, 3)",
+		"ifelse(member('foo'), 'foo', pkt4.mac)",
+		"hexstring(pkt4.mac, ':') + 'a'",
+		"'foo'",
+	}
+	for _, text := range expressions {
+		expression, err := ParseExpression(text)
+		require.NoError(t, err, text)
+		require.NotNil(t, expression, text)
+		require.False(t, expression.IsBoolean(), text)
+	}
+}
+
+// Test that the classes referenced in the member() function are returned
+// in order and without duplicates.
+func TestExpressionGetReferencedClasses(t *testing.T) {
+	expression, err := ParseExpression("member('foo') and (member('bar') or not member('foo')) and ifelse(member('baz'), 'a', 'b') == 'a'")
+	require.NoError(t, err)
+	require.Equal(t, []string{"foo", "bar", "baz"}, expression.GetReferencedClasses())
+
+	// The known and unknown keywords refer to the KNOWN class.
+	expression, err = ParseExpression("member('foo') and (known or unknown)")
+	require.NoError(t, err)
+	require.Equal(t, []string{"foo", "KNOWN"}, expression.GetReferencedClasses())
+
+	expression, err = ParseExpression("option[1].exists")
+	require.NoError(t, err)
+	require.Empty(t, expression.GetReferencedClasses())
+}
+
+// Test that the syntax errors are reported.
+func TestParseExpressionSyntaxError(t *testing.T) {
+	expressions := []string{
+		"",
+		"member('foo'",
+		"option[61].hex ==",
+		"option[61.hex == 'foo'",
+		"'foo' == 'bar' 'baz'",
+		"member('foo') and",
+		"substring('foo', 0, 1) = 'f'",
+	}
+	for _, text := range expressions {
+		expression, err := ParseExpression(text)
+		require.Error(t, err, text)
+		require.ErrorContains(t, err, "failed to parse expression", text)
+		require.Nil(t, expression, text)
+	}
+}
+
+// Test that the unknown functions, packet fields and options, and invalid
+// types of the operands are reported.
+func TestParseExpressionInvalid(t *testing.T) {
+	expressions := map[string]string{
+		"foo('bar')":                          "1:1: unknown function foo",
+		"substring('foo', 0) == 'f'":          "1:1: function substring expects 3 arguments but 2 were given",
+		"substring('foo', 'a', 1) == 'f'":     "1:18: argument 2 of the substring function must be an integer literal",
+		"concat(member('a'), 'b') == 'ab'":    "1:8: argument 1 of the concat function must be string but is boolean",
+		"ifelse('a', 'b', 'c') == 'b'":        "1:8: argument 1 of the ifelse function must be boolean but is string",
+		"member(option[1].hex)":               "1:8: argument of the member function must be a class name in quotes",
+		"option[61].value == 'foo'":           "1:1: unknown token option[...].value",
+		"pkt4.foo == 'bar'":                   "1:1: unknown token pkt4.foo",
+		"relay6[*].peeraddr == 2001:db8::1":   "1:1: relay6 does not accept a wildcard index",
+		"relay4[foo].exists":                  "1:1: relay4 index must be an integer",
+		"vendor-class[4491].data[foo] == 'a'": "1:20: data index must be an integer",
+		"member('foo') == 'foo'":              "1:1: operand of the == operator must be a string but is boolean",
+		"member('foo') + 'bar' == 'foobar'":   "1:1: operand of the + operator must be a string but is boolean",
+		"member('foo') and option[1].hex":     "1:1: operand of the and operator must be boolean but is string",
+		"option[1].hex or member('foo')":      "1:1: operand of the or operator must be boolean but is string",
+		"not option[1].hex":                   "1:1: operand of the not operator must be boolean but is string",
+		"(option[1].hex)":                     "1:1: expression in parentheses must be boolean but is string",
+		"known == 'foo'":                      "1:1: operand of the == operator must be a string but is boolean",
+		"known[1]":                            "1:1: unknown token known[...]",
+		"unknown.exists":                      "1:1: unknown token unknown.exists",
+	}
+	for text, message := range expressions {
+		expression, err := ParseExpression(text)
+		require.Error(t, err, text)
+		require.ErrorContains(t, err, "invalid expression "+text+": "+message, text)
+		require.Nil(t, expression, text)
+	}
+}
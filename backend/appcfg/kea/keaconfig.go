@@ -229,6 +229,59 @@ func (c *Config) GetAllDatabases() (databases Databases) {
 	return
 }
 
+// Returns the configuration backend database when the DHCP server fetches
+// its configuration from the database and the libdhcp_cb_cmds hook library
+// is loaded to manage this configuration. Otherwise, it returns nil. If
+// multiple databases are specified, the first one is returned because Kea
+// only supports one configuration backend database.
+func (c *Config) GetConfigBackend() *Database {
+	databases := c.GetAllDatabases()
+	if len(databases.Config) == 0 {
+		return nil
+	}
+	if _, _, ok := c.GetHookLibrary("libdhcp_cb_cmds"); !ok {
+		return nil
+	}
+	return &databases.Config[0]
+}
+
+// Returns the server tag used by the DHCP server to select its configuration
+// elements from the configuration backend. It returns the "all" tag when the
+// server tag is not specified.
+func (c *Config) GetServerTag() string {
+	if accessor := c.getDHCPConfigAccessor(); accessor != nil {
+		if serverTag := accessor.GetCommonDHCPConfig().ServerTag; serverTag != nil && len(*serverTag) > 0 {
+			return *serverTag
+		}
+	}
+	return ServerTagAll
+}
+
+// Returns the top-level configuration parameters without the hash value
+// returned by Kea in the config-get response. The hash is not a part of
+// the configuration contents and it is removed when the configuration is
+// modified. The returned map is a shallow copy of the raw configuration.
+func (c *Config) GetContents() map[string]any {
+	contents := make(map[string]any, len(c.Raw))
+	for key, value := range c.Raw {
+		if key == "hash" {
+			continue
+		}
+		contents[key] = value
+	}
+	return contents
+}
+
+// Returns a hash of the configuration contents. The hash doesn't depend
+// on the formatting, comments and the order of the configuration
+// parameters, so the hash of the configuration returned by the config-get
+// command can be compared with the hash of the configuration file read
+// from the disk. The hash value returned by Kea in the config-get response
+// is excluded.
+func (c *Config) GetContentHash() string {
+	return NewHasher().Hash(c.GetContents())
+}
+
 // Returns DHCP cache parameters.
 func (c *Config) GetCacheParameters() (parameters CacheParameters) {
 	if accessor := c.getDHCPConfigAccessor(); accessor != nil {
@@ -247,6 +300,14 @@ func (c *Config) GetClientClasses() (clientClasses []ClientClass) {
 	return
 }
 
+// Returns custom option definitions.
+func (c *Config) GetOptionDefs() (optionDefs []OptionDef) {
+	if accessor := c.getDHCPConfigAccessor(); accessor != nil {
+		optionDefs = accessor.GetCommonDHCPConfig().OptionDefs
+	}
+	return
+}
+
 // Returns DHCP DDNS parameters.
 func (c *Config) GetDDNSParameters() (parameters DDNSParameters) {
 	if accessor := c.getDHCPConfigAccessor(); accessor != nil {
@@ -496,6 +557,68 @@ func hideSensitiveData(obj *map[string]any) {
 	}
 }
 
+// Returns the raw list stored under the specified key in the DHCP server
+// configuration (e.g., client-classes). The second returned value is the
+// top-level map of the DHCP server configuration (i.e., the map under the
+// Dhcp4 or Dhcp6 key). It returns an error if the configuration does not
+// belong to a DHCP server.
+func (c *Config) getRawDHCPConfigList(key string) ([]any, RawConfig, error) {
+	var rootName string
+	switch {
+	case c.IsDHCPv4():
+		rootName = "Dhcp4"
+	case c.IsDHCPv6():
+		rootName = "Dhcp6"
+	default:
+		return nil, nil, errors.Errorf("%s can only be configured for the DHCP servers", key)
+	}
+	root, ok := c.Raw[rootName].(RawConfig)
+	if !ok {
+		return nil, nil, errors.Errorf("invalid %s configuration structure", rootName)
+	}
+	var list []any
+	if root[key] != nil {
+		if list, ok = root[key].([]any); !ok {
+			return nil, nil, errors.Errorf("invalid %s configuration structure", key)
+		}
+	}
+	return list, root, nil
+}
+
+// Replaces the list stored under the specified key in the raw DHCP server
+// configuration and parses the updated raw configuration into the
+// server-specific structures. The list is removed from the configuration
+// when it is empty.
+func (c *Config) setRawDHCPConfigList(root RawConfig, key string, list []any) error {
+	if len(list) == 0 {
+		delete(root, key)
+	} else {
+		root[key] = list
+	}
+	// The configuration has changed, so the hash is no longer valid.
+	delete(c.Raw, "hash")
+	data, err := json.Marshal(c.Raw)
+	if err != nil {
+		return errors.Wrapf(err, "problem serializing Kea configuration with modified %s", key)
+	}
+	err = c.unmarshalIntoAccessibleConfig(data)
+	return errors.WithMessagef(err, "problem parsing Kea configuration with modified %s", key)
+}
+
+// Converts a configuration element (e.g., a client class) to the raw map,
+// so it can be inserted into the raw configuration.
+func convertToRawConfig(element any) (RawConfig, error) {
+	data, err := json.Marshal(element)
+	if err != nil {
+		return nil, errors.Wrap(err, "problem serializing configuration element")
+	}
+	var raw RawConfig
+	if err = json.Unmarshal(data, &raw); err != nil {
+		return nil, errors.Wrap(err, "problem parsing configuration element")
+	}
+	return raw, nil
+}
+
 // Merges raw configuration into current configuration.
 func (c *Config) Merge(source RawConfigAccessor) error {
 	// Get source and destination raw configurations. The merge is performed
@@ -697,6 +697,136 @@ func TestGetAllDatabases(t *testing.T) {
 	})
 }
 
+// Test that the configuration backend is returned when the config
+// databases are specified and the cb_cmds hook library is loaded.
+func TestGetConfigBackend(t *testing.T) {
+	cfg, err := NewConfig(`{
+		"Dhcp4": {
+			"config-control": {
+				"config-databases": [
+					{
+						"type": "mysql",
+						"name": "kea"
+					}
+				]
+			},
+			"hooks-libraries": [
+				{
+					"library": "/usr/lib/kea/libdhcp_cb_cmds.so"
+				}
+			]
+		}
+	}`)
+	require.NoError(t, err)
+
+	backend := cfg.GetConfigBackend()
+	require.NotNil(t, backend)
+	require.Equal(t, "mysql", backend.Type)
+	require.Equal(t, "kea", backend.Name)
+}
+
+// Test that no configuration backend is returned when the cb_cmds hook
+// library is not loaded or the config databases are not specified.
+func TestGetConfigBackendNotInUse(t *testing.T) {
+	cfg, err := NewConfig(`{
+		"Dhcp4": {
+			"config-control": {
+				"config-databases": [
+					{
+						"type": "mysql",
+						"name": "kea"
+					}
+				]
+			}
+		}
+	}`)
+	require.NoError(t, err)
+	require.Nil(t, cfg.GetConfigBackend())
+
+	cfg, err = NewConfig(`{
+		"Dhcp6": {
+			"hooks-libraries": [
+				{
+					"library": "/usr/lib/kea/libdhcp_cb_cmds.so"
+				}
+			]
+		}
+	}`)
+	require.NoError(t, err)
+	require.Nil(t, cfg.GetConfigBackend())
+
+	cfg, err = NewConfig(`{
+		"Control-agent": { }
+	}`)
+	require.NoError(t, err)
+	require.Nil(t, cfg.GetConfigBackend())
+}
+
+// Test getting the server tag.
+func TestGetServerTag(t *testing.T) {
+	cfg, err := NewConfig(`{
+		"Dhcp4": {
+			"server-tag": "server1"
+		}
+	}`)
+	require.NoError(t, err)
+	require.Equal(t, "server1", cfg.GetServerTag())
+
+	// The default server tag is "all".
+	cfg, err = NewConfig(`{
+		"Dhcp6": { }
+	}`)
+	require.NoError(t, err)
+	require.Equal(t, ServerTagAll, cfg.GetServerTag())
+}
+
+// Test that the configuration contents exclude the hash returned by Kea
+// and that the raw configuration is not modified.
+func TestGetContents(t *testing.T) {
+	cfg, err := NewConfig(`{
+		"Dhcp4": { "valid-lifetime": 4000 },
+		"hash": "1234"
+	}`)
+	require.NoError(t, err)
+
+	contents := cfg.GetContents()
+	require.Len(t, contents, 1)
+	require.Contains(t, contents, "Dhcp4")
+	require.NotContains(t, contents, "hash")
+	require.Contains(t, cfg.Raw, "hash")
+}
+
+// Test that the configuration content hash doesn't depend on the
+// formatting, comments, parameters order and the hash returned by Kea.
+func TestGetContentHash(t *testing.T) {
+	cfg1, err := NewConfig(`{
+		// A comment.
+		"Dhcp4": {
+			"valid-lifetime": 4000,
+			"subnet4": [ { "id": 1, "subnet": "192.0.2.0/24" } ]
+		}
+	}`)
+	require.NoError(t, err)
+
+	cfg2, err := NewConfig(`{
+		"Dhcp4": { "subnet4": [ { "subnet": "192.0.2.0/24", "id": 1 } ], "valid-lifetime": 4000 },
+		"hash": "1234"
+	}`)
+	require.NoError(t, err)
+
+	require.NotEmpty(t, cfg1.GetContentHash())
+	require.Equal(t, cfg1.GetContentHash(), cfg2.GetContentHash())
+
+	cfg3, err := NewConfig(`{
+		"Dhcp4": {
+			"valid-lifetime": 3000,
+			"subnet4": [ { "id": 1, "subnet": "192.0.2.0/24" } ]
+		}
+	}`)
+	require.NoError(t, err)
+	require.NotEqual(t, cfg1.GetContentHash(), cfg3.GetContentHash())
+}
+
 // Test that caching parameters are parsed and returned correctly.
 func TestGetCacheParameters(t *testing.T) {
 	configStr := `{
@@ -1740,14 +1870,14 @@ func TestGetDHCPOptions4(t *testing.T) {
 
 	require.False(t, options[0].AlwaysSend)
 	require.EqualValues(t, 3, options[0].Code)
-	require.True(t, options[0].CSVFormat)
+	require.Equal(t, storkutil.Ptr(true), options[0].CSVFormat)
 	require.Equal(t, "10.0.0.1", options[0].Data)
 	require.Equal(t, "routers", options[0].Name)
 	require.Equal(t, dhcpmodel.DHCPv4OptionSpace, options[0].Space)
 
 	require.True(t, options[1].AlwaysSend)
 	require.EqualValues(t, 6, options[1].Code)
-	require.True(t, options[1].CSVFormat)
+	require.Equal(t, storkutil.Ptr(true), options[1].CSVFormat)
 	require.Equal(t, "192.0.3.1, 192.0.3.2", options[1].Data)
 	require.Equal(t, "domain-name-servers", options[1].Name)
 	require.Equal(t, dhcpmodel.DHCPv4OptionSpace, options[0].Space)
@@ -1786,14 +1916,14 @@ func TestGetDHCPOptions6(t *testing.T) {
 
 	require.False(t, options[0].AlwaysSend)
 	require.EqualValues(t, 23, options[0].Code)
-	require.True(t, options[0].CSVFormat)
+	require.Equal(t, storkutil.Ptr(true), options[0].CSVFormat)
 	require.Equal(t, "2001:db8:1::1", options[0].Data)
 	require.Equal(t, "dns-servers", options[0].Name)
 	require.Equal(t, dhcpmodel.DHCPv6OptionSpace, options[0].Space)
 
 	require.True(t, options[1].AlwaysSend)
 	require.EqualValues(t, 27, options[1].Code)
-	require.True(t, options[1].CSVFormat)
+	require.Equal(t, storkutil.Ptr(true), options[1].CSVFormat)
 	require.Equal(t, "2001:db8:1::2, 2001:db8:1::3", options[1].Data)
 	require.Equal(t, "nis-servers", options[1].Name)
 	require.Equal(t, dhcpmodel.DHCPv6OptionSpace, options[0].Space)
@@ -2113,16 +2243,17 @@ func TestSettingDHCPv4GlobalParameters(t *testing.T) {
 		{
 			AlwaysSend: true,
 			Code:       42,
-			CSVFormat:  true,
+			CSVFormat:  storkutil.Ptr(true),
 			Data:       "foo",
 			Name:       "forty-two",
 			Space:      "dhcp4",
 		},
 		{
-			Code:  24,
-			Data:  "bar",
-			Name:  "twenty-four",
-			Space: "dhcp4",
+			Code:      24,
+			CSVFormat: storkutil.Ptr(false),
+			Data:      "bar",
+			Name:      "twenty-four",
+			Space:     "dhcp4",
 		},
 	})
 	require.NoError(t, err)
@@ -0,0 +1,74 @@
+package keaconfig
+
+// Groups the lease lifetime and timer parameters which can be specified
+// at the global, shared network and subnet levels.
+type LifetimeParameters struct {
+	TimerParameters
+	ValidLifetimeParameters
+	PreferredLifetimeParameters
+}
+
+// Returns the lifetime parameters specified at the global level.
+func (c *Config) GetLifetimeParameters() LifetimeParameters {
+	return LifetimeParameters{
+		TimerParameters:             c.GetTimerParameters(),
+		ValidLifetimeParameters:     c.GetValidLifetimeParameters(),
+		PreferredLifetimeParameters: c.GetPreferredLifetimeParameters(),
+	}
+}
+
+// Returns the lifetime parameters specified for the shared network.
+func (p *SharedNetworkParameters) GetLifetimeParameters() LifetimeParameters {
+	return LifetimeParameters{
+		TimerParameters:             p.TimerParameters,
+		ValidLifetimeParameters:     p.ValidLifetimeParameters,
+		PreferredLifetimeParameters: p.PreferredLifetimeParameters,
+	}
+}
+
+// Returns the lifetime parameters specified for the subnet.
+func (p *SubnetParameters) GetLifetimeParameters() LifetimeParameters {
+	return LifetimeParameters{
+		TimerParameters:             p.TimerParameters,
+		ValidLifetimeParameters:     p.ValidLifetimeParameters,
+		PreferredLifetimeParameters: p.PreferredLifetimeParameters,
+	}
+}
+
+// Checks if any of the lifetime parameters is specified.
+func (p LifetimeParameters) IsAnySpecified() bool {
+	return p != (LifetimeParameters{})
+}
+
+// Returns the first non-nil value.
+func inheritValue[T any](values ...*T) *T {
+	for _, value := range values {
+		if value != nil {
+			return value
+		}
+	}
+	return nil
+}
+
+// Returns the effective lifetime parameters for a configuration scope.
+// The levels must be ordered from the most specific one (e.g., a subnet)
+// to the least specific one (e.g., the global level). Following the Kea
+// configuration inheritance scheme, each parameter is taken from the most
+// specific level at which it is specified.
+func GetEffectiveLifetimeParameters(levels ...LifetimeParameters) (effective LifetimeParameters) {
+	for i := len(levels) - 1; i >= 0; i-- {
+		level := levels[i]
+		effective.RenewTimer = inheritValue(level.RenewTimer, effective.RenewTimer)
+		effective.RebindTimer = inheritValue(level.RebindTimer, effective.RebindTimer)
+		effective.T1Percent = inheritValue(level.T1Percent, effective.T1Percent)
+		effective.T2Percent = inheritValue(level.T2Percent, effective.T2Percent)
+		effective.CalculateTeeTimes = inheritValue(level.CalculateTeeTimes, effective.CalculateTeeTimes)
+		effective.ValidLifetime = inheritValue(level.ValidLifetime, effective.ValidLifetime)
+		effective.MinValidLifetime = inheritValue(level.MinValidLifetime, effective.MinValidLifetime)
+		effective.MaxValidLifetime = inheritValue(level.MaxValidLifetime, effective.MaxValidLifetime)
+		effective.PreferredLifetime = inheritValue(level.PreferredLifetime, effective.PreferredLifetime)
+		effective.MinPreferredLifetime = inheritValue(level.MinPreferredLifetime, effective.MinPreferredLifetime)
+		effective.MaxPreferredLifetime = inheritValue(level.MaxPreferredLifetime, effective.MaxPreferredLifetime)
+	}
+	return
+}
@@ -0,0 +1,88 @@
+package keaconfig
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	storkutil "isc.org/stork/util"
+)
+
+// Test getting the lifetime parameters at different configuration levels.
+func TestGetLifetimeParameters(t *testing.T) {
+	config, err := NewConfig(`{
+		"Dhcp6": {
+			"renew-timer": 1000,
+			"rebind-timer": 2000,
+			"valid-lifetime": 4000,
+			"preferred-lifetime": 3000,
+			"shared-networks": [
+				{
+					"name": "foo",
+					"valid-lifetime": 5000,
+					"subnet6": [
+						{
+							"id": 1,
+							"subnet": "2001:db8:1::/64",
+							"min-preferred-lifetime": 100
+						}
+					]
+				}
+			]
+		}
+	}`)
+	require.NoError(t, err)
+
+	global := config.GetLifetimeParameters()
+	require.EqualValues(t, 1000, *global.RenewTimer)
+	require.EqualValues(t, 2000, *global.RebindTimer)
+	require.EqualValues(t, 4000, *global.ValidLifetime)
+	require.EqualValues(t, 3000, *global.PreferredLifetime)
+	require.True(t, global.IsAnySpecified())
+
+	sharedNetworks := config.GetSharedNetworks(false)
+	require.Len(t, sharedNetworks, 1)
+	network := sharedNetworks[0].GetSharedNetworkParameters().GetLifetimeParameters()
+	require.EqualValues(t, 5000, *network.ValidLifetime)
+	require.Nil(t, network.RenewTimer)
+
+	subnet := sharedNetworks[0].GetSubnets()[0].GetSubnetParameters().GetLifetimeParameters()
+	require.EqualValues(t, 100, *subnet.MinPreferredLifetime)
+	require.Nil(t, subnet.ValidLifetime)
+
+	require.False(t, LifetimeParameters{}.IsAnySpecified())
+}
+
+// Test that the effective lifetime parameters are inherited from the
+// less specific levels.
+func TestGetEffectiveLifetimeParameters(t *testing.T) {
+	subnet := LifetimeParameters{
+		TimerParameters: TimerParameters{
+			RenewTimer: storkutil.Ptr(int64(100)),
+		},
+	}
+	network := LifetimeParameters{
+		TimerParameters: TimerParameters{
+			RenewTimer:  storkutil.Ptr(int64(200)),
+			RebindTimer: storkutil.Ptr(int64(300)),
+		},
+	}
+	global := LifetimeParameters{
+		TimerParameters: TimerParameters{
+			RebindTimer: storkutil.Ptr(int64(400)),
+		},
+		ValidLifetimeParameters: ValidLifetimeParameters{
+			ValidLifetime: storkutil.Ptr(int64(500)),
+		},
+	}
+	effective := GetEffectiveLifetimeParameters(subnet, network, global)
+	require.EqualValues(t, 100, *effective.RenewTimer)
+	require.EqualValues(t, 300, *effective.RebindTimer)
+	require.EqualValues(t, 500, *effective.ValidLifetime)
+	require.Nil(t, effective.PreferredLifetime)
+	require.Nil(t, effective.MaxValidLifetime)
+
+	// The input levels should not be modified.
+	require.Nil(t, subnet.RebindTimer)
+
+	require.Equal(t, LifetimeParameters{}, GetEffectiveLifetimeParameters())
+}
@@ -1,7 +1,9 @@
 package keaconfig
 
 import (
+	"encoding/hex"
 	"fmt"
+	"slices"
 	"strings"
 
 	errors "github.com/pkg/errors"
@@ -14,12 +16,18 @@ import (
 type SingleOptionData struct {
 	AlwaysSend bool   `json:"always-send,omitempty"`
 	Code       uint16 `json:"code,omitempty"`
-	CSVFormat  bool   `json:"csv-format"`
+	CSVFormat  *bool  `json:"csv-format,omitempty"`
 	Data       string `json:"data,omitempty"`
 	Name       string `json:"name,omitempty"`
 	Space      string `json:"space,omitempty"`
 }
 
+// Checks if the option data are specified as comma separated values. Kea
+// assumes this format when the csv-format parameter is not specified.
+func (data SingleOptionData) IsCSVFormat() bool {
+	return data.CSVFormat == nil || *data.CSVFormat
+}
+
 // Creates a SingleOptionData instance from the DHCP option model used
 // by Stork (e.g., from an option held in the Stork database). If the
 // option has a definition, it uses the Kea's csv-format setting and
@@ -29,10 +37,11 @@ type SingleOptionData struct {
 func CreateSingleOptionData(daemonID int64, lookup DHCPOptionDefinitionLookup, option dhcpmodel.DHCPOptionAccessor) (*SingleOptionData, error) {
 	// Create Kea representation of the option. Set csv-format to
 	// true for all options for which the definitions are known.
+	csvFormat := lookup.DefinitionExists(daemonID, option)
 	data := &SingleOptionData{
 		AlwaysSend: option.IsAlwaysSend(),
 		Code:       option.GetCode(),
-		CSVFormat:  lookup.DefinitionExists(daemonID, option),
+		CSVFormat:  &csvFormat,
 		Name:       option.GetName(),
 		Space:      option.GetSpace(),
 	}
@@ -47,21 +56,21 @@ func CreateSingleOptionData(daemonID int64, lookup DHCPOptionDefinitionLookup, o
 		case dhcpmodel.BinaryField:
 			value, err = ConvertBinaryField(field)
 		case dhcpmodel.StringField:
-			value, err = ConvertStringField(field, data.CSVFormat)
+			value, err = ConvertStringField(field, csvFormat)
 		case dhcpmodel.BoolField:
-			value, err = ConvertBoolField(field, data.CSVFormat)
+			value, err = ConvertBoolField(field, csvFormat)
 		case dhcpmodel.Uint8Field, dhcpmodel.Uint16Field, dhcpmodel.Uint32Field, dhcpmodel.Int8Field, dhcpmodel.Int16Field, dhcpmodel.Int32Field:
-			value, err = ConvertIntField(field, data.CSVFormat)
+			value, err = ConvertIntField(field, csvFormat)
 		case dhcpmodel.IPv4AddressField:
-			value, err = ConvertIPv4AddressField(field, data.CSVFormat)
+			value, err = ConvertIPv4AddressField(field, csvFormat)
 		case dhcpmodel.IPv6AddressField:
-			value, err = ConvertIPv6AddressField(field, data.CSVFormat)
+			value, err = ConvertIPv6AddressField(field, csvFormat)
 		case dhcpmodel.IPv6PrefixField:
-			value, err = ConvertIPv6PrefixField(field, data.CSVFormat)
+			value, err = ConvertIPv6PrefixField(field, csvFormat)
 		case dhcpmodel.PsidField:
-			value, err = ConvertPsidField(field, data.CSVFormat)
+			value, err = ConvertPsidField(field, csvFormat)
 		case dhcpmodel.FqdnField:
-			value, err = ConvertFqdnField(field, data.CSVFormat)
+			value, err = ConvertFqdnField(field, csvFormat)
 		default:
 			err = errors.Errorf("unsupported option field type %s", field.GetFieldType())
 		}
@@ -72,7 +81,7 @@ func CreateSingleOptionData(daemonID int64, lookup DHCPOptionDefinitionLookup, o
 		// of hexadecimal digits representing the value.
 		converted = append(converted, value)
 	}
-	if data.CSVFormat {
+	if csvFormat {
 		// Use comma separated values.
 		data.Data = strings.Join(converted, ",")
 	} else {
@@ -130,7 +139,7 @@ func CreateDHCPOption(optionData SingleOptionData, universe storkutil.IPType, lo
 	}
 
 	// Option data specified as comma separated values.
-	if optionData.CSVFormat {
+	if optionData.IsCSVFormat() {
 		values := splitByComma(data)
 		for i, raw := range values {
 			v := strings.TrimSpace(raw)
@@ -217,3 +226,172 @@ func splitByComma(value string) []string {
 	result = append(result, current)
 	return result
 }
+
+// Maximum length of the DHCPv6 option payload. The length of the DHCPv6
+// option is carried in two octets. The length of the DHCPv4 option is
+// carried in a single octet, but Kea splits the longer DHCPv4 options into
+// multiple instances (RFC 3396), so their length is not limited.
+const maxDHCPv6OptionDataLength = 65535
+
+// Standard options that Kea supports but for which Stork has no option
+// definitions because Kea uses custom parsers for them. Their option data
+// cannot be validated.
+var stdOptionsWithoutDefinitions = map[storkutil.IPType][]uint16{
+	storkutil.IPv4: {
+		121, // classless-static-route
+	},
+}
+
+// Validates the option data against the standard option definitions and
+// the custom option definitions (i.e., the option-def list) specified for
+// the server. It verifies that the option definition exists when the option
+// data is specified as comma separated values, that the values match the
+// option field types, and that the encoded DHCPv6 option payload does not
+// exceed the maximum option length. The csv-format parameter defaults to
+// true when it is not specified. Option data lacking the data value is not
+// validated because the option contents may be inherited or the option
+// may be empty.
+func ValidateSingleOptionData(optionData SingleOptionData, universe storkutil.IPType, optionDefs []OptionDef) error {
+	if optionData.Code == 0 && optionData.Name == "" {
+		return errors.New("option lacks both code and name")
+	}
+	space := OptionDef{Space: optionData.Space}.getSpaceOrDefault(universe)
+	def := findOptionDefinition(optionData, space, universe, optionDefs)
+	if def == nil && optionData.Code == 0 {
+		return errors.Errorf("unknown option %s in the %s option space", optionData.Name, space)
+	}
+	data := strings.TrimSpace(optionData.Data)
+	if len(data) == 0 {
+		return nil
+	}
+	var (
+		length int
+		err    error
+	)
+	switch {
+	case !optionData.IsCSVFormat():
+		length, err = getHexOptionDataLength(data)
+	case def != nil:
+		length, err = getCSVOptionDataLength(data, def, universe)
+	case slices.Contains(stdOptionsWithoutDefinitions[universe], optionData.Code) && (space == dhcpmodel.DHCPv4OptionSpace || space == dhcpmodel.DHCPv6OptionSpace):
+		return nil
+	default:
+		return errors.Errorf("option with code %d in the %s option space has no definition; add the option definition or specify the option data in the hex format with csv-format set to false",
+			optionData.Code, space)
+	}
+	if err != nil {
+		return err
+	}
+	if universe == storkutil.IPv6 && length > maxDHCPv6OptionDataLength {
+		return errors.Errorf("option data length %d exceeds the maximum option length of %d bytes", length, maxDHCPv6OptionDataLength)
+	}
+	return nil
+}
+
+// Searches for the option definition by code (or name when the code is
+// not specified) and space. The custom option definitions take precedence
+// over the standard option definitions.
+func findOptionDefinition(optionData SingleOptionData, space string, universe storkutil.IPType, optionDefs []OptionDef) DHCPOptionDefinition {
+	for _, def := range optionDefs {
+		if def.getSpaceOrDefault(universe) != space {
+			continue
+		}
+		if (optionData.Code != 0 && def.Code == optionData.Code) || (optionData.Code == 0 && def.Name == optionData.Name) {
+			return def
+		}
+	}
+	lookup := NewStdDHCPOptionDefinitionLookup()
+	if optionData.Code != 0 {
+		return lookup.FindByCodeSpace(optionData.Code, space, universe)
+	}
+	return lookup.FindByNameSpace(optionData.Name, space, universe)
+}
+
+// Validates the option data specified as a string of hexadecimal digits
+// and returns the length of the option payload. The digits may be separated
+// with colons or spaces, and may be preceded with 0x.
+func getHexOptionDataLength(data string) (int, error) {
+	sanitized := strings.ReplaceAll(strings.ReplaceAll(data, " ", ""), ":", "")
+	sanitized = strings.TrimPrefix(strings.TrimPrefix(sanitized, "0x"), "0X")
+	// Kea accepts an odd number of digits assuming the leading zero.
+	if len(sanitized)%2 != 0 {
+		sanitized = "0" + sanitized
+	}
+	decoded, err := hex.DecodeString(sanitized)
+	if err != nil {
+		return 0, errors.Errorf("option data %s is not a valid string of hexadecimal digits", data)
+	}
+	return len(decoded), nil
+}
+
+// Validates the option data specified as comma separated values against
+// the option definition and returns the length of the encoded option
+// payload.
+func getCSVOptionDataLength(data string, def DHCPOptionDefinition, universe storkutil.IPType) (int, error) {
+	if def.GetType() == EmptyOption {
+		return 0, errors.Errorf("option %s carries no data but %s was specified", def.GetName(), data)
+	}
+	values := splitByComma(data)
+	if def.GetType() == RecordOption && len(values) < len(def.GetRecordTypes()) {
+		return 0, errors.Errorf("option %s requires %d values but %d were specified",
+			def.GetName(), len(def.GetRecordTypes()), len(values))
+	}
+	length := 0
+	for i, value := range values {
+		fieldType, ok := GetDHCPOptionDefinitionFieldType(def, i)
+		if !ok {
+			return 0, errors.Errorf("too many values specified for option %s", def.GetName())
+		}
+		fieldLength, err := getOptionFieldLength(fieldType, strings.TrimSpace(value), universe)
+		if err != nil {
+			return 0, errors.WithMessagef(err, "invalid value of option %s", def.GetName())
+		}
+		length += fieldLength
+	}
+	return length, nil
+}
+
+// Parses the option field value and returns the length of the encoded
+// option field.
+func getOptionFieldLength(fieldType dhcpmodel.DHCPOptionFieldType, value string, universe storkutil.IPType) (int, error) {
+	switch fieldType {
+	case TupleOption:
+		// The tuple length is carried in one octet in DHCPv4 and
+		// in two octets in DHCPv6.
+		if universe == storkutil.IPv6 {
+			return len(value) + 2, nil
+		}
+		return len(value) + 1, nil
+	case dhcpmodel.BinaryField:
+		return getHexOptionDataLength(value)
+	}
+	field, err := ParseDHCPOptionField(fieldType, value)
+	if err != nil {
+		return 0, err
+	}
+	var encoded string
+	switch fieldType {
+	case dhcpmodel.StringField:
+		encoded, err = ConvertStringField(field, false)
+	case dhcpmodel.BoolField:
+		encoded, err = ConvertBoolField(field, false)
+	case dhcpmodel.Uint8Field, dhcpmodel.Uint16Field, dhcpmodel.Uint32Field, dhcpmodel.Int8Field, dhcpmodel.Int16Field, dhcpmodel.Int32Field:
+		encoded, err = ConvertIntField(field, false)
+	case dhcpmodel.IPv4AddressField:
+		encoded, err = ConvertIPv4AddressField(field, false)
+	case dhcpmodel.IPv6AddressField:
+		encoded, err = ConvertIPv6AddressField(field, false)
+	case dhcpmodel.IPv6PrefixField:
+		encoded, err = ConvertIPv6PrefixField(field, false)
+	case dhcpmodel.PsidField:
+		encoded, err = ConvertPsidField(field, false)
+	case dhcpmodel.FqdnField:
+		encoded, err = ConvertFqdnField(field, false)
+	default:
+		err = errors.Errorf("unsupported option field type %s", fieldType)
+	}
+	if err != nil {
+		return 0, err
+	}
+	return len(encoded) / 2, nil
+}
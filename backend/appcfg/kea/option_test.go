@@ -2,6 +2,7 @@ package keaconfig_test
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 
 	require "github.com/stretchr/testify/require"
@@ -166,7 +167,7 @@ func TestCreateSingleOptionDataMultipleFields(t *testing.T) {
 	// Make sure that the conversion was correct.
 	require.True(t, data.AlwaysSend)
 	require.EqualValues(t, 1600, data.Code)
-	require.True(t, data.CSVFormat)
+	require.Equal(t, storkutil.Ptr(true), data.CSVFormat)
 	require.Equal(t, "foobar", data.Space)
 	require.Equal(t, "bar", data.Name)
 
@@ -200,7 +201,7 @@ func TestCreateSingleOptionDataBinaryField(t *testing.T) {
 	// Make sure the option was converted ok.
 	require.False(t, data.AlwaysSend)
 	require.EqualValues(t, 1678, data.Code)
-	require.True(t, data.CSVFormat)
+	require.Equal(t, storkutil.Ptr(true), data.CSVFormat)
 	require.Empty(t, data.Space)
 	require.Empty(t, data.Name)
 
@@ -287,7 +288,7 @@ func TestCreateSingleOptionDataNoDefinition(t *testing.T) {
 	// Make sure that the conversion was correct.
 	require.True(t, data.AlwaysSend)
 	require.EqualValues(t, 16, data.Code)
-	require.False(t, data.CSVFormat)
+	require.Equal(t, storkutil.Ptr(false), data.CSVFormat)
 	require.Equal(t, "foo", data.Space)
 	require.Equal(t, "bar", data.Name)
 
@@ -301,7 +302,7 @@ func TestCreateDHCPOptionCSV(t *testing.T) {
 	optionData := keaconfig.SingleOptionData{
 		AlwaysSend: true,
 		Code:       244,
-		CSVFormat:  true,
+		CSVFormat:  storkutil.Ptr(true),
 		Data:       "192.0.2.1, xyz, true, 1020, 3000::/64, 90/2, foobar.example.com., 2001:db8:1::12, -5",
 		Name:       "foo",
 		Space:      "bar",
@@ -366,7 +367,7 @@ func TestCreateDHCPOptionHex(t *testing.T) {
 	optionData := keaconfig.SingleOptionData{
 		AlwaysSend: false,
 		Code:       2048,
-		CSVFormat:  false,
+		CSVFormat:  storkutil.Ptr(false),
 		Data:       "01 02 03 04 05 06 07 08 09 0A",
 		Name:       "foobar",
 		Space:      "baz",
@@ -396,7 +397,7 @@ func TestCreateDHCPOptionHex(t *testing.T) {
 func TestCreateDHCPOptionEmpty(t *testing.T) {
 	optionData := keaconfig.SingleOptionData{
 		Code:      333,
-		CSVFormat: true,
+		CSVFormat: storkutil.Ptr(true),
 		Name:      "foobar",
 		Space:     "baz",
 	}
@@ -480,7 +481,7 @@ func TestCreateDHCPOptionEncapsulateDHCPv6Suboption(t *testing.T) {
 func TestCreateStandardDHCPOption(t *testing.T) {
 	optionData := keaconfig.SingleOptionData{
 		Code:      89,
-		CSVFormat: true,
+		CSVFormat: storkutil.Ptr(true),
 		Data:      "10, 9, 6, 192.0.2.1, 3000::/64",
 		Name:      "s46-rule",
 		Space:     "s46-cont-mape-options",
@@ -525,7 +526,7 @@ func TestCreateStandardDHCPOption(t *testing.T) {
 func TestCreateStandardDHCPOptionBinary(t *testing.T) {
 	optionData := keaconfig.SingleOptionData{
 		Code:      97,
-		CSVFormat: true,
+		CSVFormat: storkutil.Ptr(true),
 		Data:      "1, 010203040102",
 		Name:      "uuid-guid",
 		Space:     "dhcp4",
@@ -598,7 +599,7 @@ func TestSplitByComma(t *testing.T) {
 	for i, c := range cases {
 		t.Run(fmt.Sprint(i), func(t *testing.T) {
 			optionData := keaconfig.SingleOptionData{
-				Code: 42, CSVFormat: true, Name: "foo-bar", Space: "dhcp4",
+				Code: 42, CSVFormat: storkutil.Ptr(true), Name: "foo-bar", Space: "dhcp4",
 				Data: c,
 			}
 
@@ -614,3 +615,103 @@ func TestSplitByComma(t *testing.T) {
 		})
 	}
 }
+
+// Test that valid option data pass the validation.
+func TestValidateSingleOptionData(t *testing.T) {
+	optionDefs := []keaconfig.OptionDef{
+		{
+			Code:       222,
+			Name:       "foo",
+			OptionType: "record",
+			// Fields: uint8, ipv4-address, string.
+			RecordTypes: "uint8, ipv4-address, string",
+		},
+		{
+			Code:       1,
+			Name:       "bar",
+			Space:      "foo-space",
+			OptionType: "uint16",
+			Array:      true,
+		},
+		{
+			Code:        224,
+			Name:        "baz",
+			OptionType:  "record",
+			RecordTypes: "boolean, binary",
+		},
+	}
+	for _, optionData := range []keaconfig.SingleOptionData{
+		{Code: 6, CSVFormat: storkutil.Ptr(true), Data: "192.0.2.1, 192.0.2.2"},
+		{Code: 224, Data: "true, 0102"},
+		{Name: "domain-name-servers", CSVFormat: storkutil.Ptr(true), Data: "192.0.2.1"},
+		{Name: "domain-name", CSVFormat: storkutil.Ptr(true), Data: "example.org"},
+		{Code: 15, CSVFormat: storkutil.Ptr(false), Data: "0x6578616d706c65"},
+		{Code: 15, CSVFormat: storkutil.Ptr(false), Data: "65:78:61"},
+		{Code: 222, CSVFormat: storkutil.Ptr(true), Data: "1, 192.0.2.1, foo"},
+		{Name: "foo", CSVFormat: storkutil.Ptr(true), Data: "1, 192.0.2.1, foo"},
+		{Code: 1, Space: "foo-space", CSVFormat: storkutil.Ptr(true), Data: "1, 2, 3"},
+		{Code: 223, CSVFormat: storkutil.Ptr(false), Data: "0102"},
+		{Code: 121, CSVFormat: storkutil.Ptr(true), Data: "10.0.0.0/8 - 192.0.2.1"},
+		{Name: "domain-name-servers"},
+		{Code: 152, CSVFormat: storkutil.Ptr(true)},
+		// The csv-format defaults to true.
+		{Code: 6, Data: "192.0.2.1, 192.0.2.2"},
+		// Kea splits the long DHCPv4 options into multiple instances.
+		{Code: 15, Data: strings.Repeat("a", 256)},
+	} {
+		require.NoError(t, keaconfig.ValidateSingleOptionData(optionData, storkutil.IPv4, optionDefs), "%+v", optionData)
+	}
+}
+
+// Test that valid DHCPv6 option data pass the validation.
+func TestValidateSingleOptionDataDHCPv6(t *testing.T) {
+	for _, optionData := range []keaconfig.SingleOptionData{
+		{Name: "dns-servers", CSVFormat: storkutil.Ptr(true), Data: "2001:db8:1::1, 2001:db8:1::2"},
+		{Code: 24, CSVFormat: storkutil.Ptr(true), Data: "mail.example.org., foo.example.org."},
+		{Code: 7, CSVFormat: storkutil.Ptr(true), Data: "255"},
+		{Code: 1234, CSVFormat: storkutil.Ptr(false), Data: "01 02 03"},
+	} {
+		require.NoError(t, keaconfig.ValidateSingleOptionData(optionData, storkutil.IPv6, nil), "%+v", optionData)
+	}
+
+	// The DHCPv6 option length is limited.
+	optionData := keaconfig.SingleOptionData{Code: 1234, CSVFormat: storkutil.Ptr(false), Data: strings.Repeat("01", 65536)}
+	require.ErrorContains(t, keaconfig.ValidateSingleOptionData(optionData, storkutil.IPv6, nil),
+		"option data length 65536 exceeds the maximum option length of 65535 bytes")
+}
+
+// Test that invalid option data are reported.
+func TestValidateSingleOptionDataInvalid(t *testing.T) {
+	optionDefs := []keaconfig.OptionDef{
+		{
+			Code:        222,
+			Name:        "foo",
+			OptionType:  "record",
+			RecordTypes: "uint8, ipv4-address",
+		},
+	}
+	testCases := []struct {
+		name       string
+		optionData keaconfig.SingleOptionData
+		err        string
+	}{
+		{"no code nor name", keaconfig.SingleOptionData{Data: "01"}, "option lacks both code and name"},
+		{"unknown name", keaconfig.SingleOptionData{Name: "baz", Data: "01"}, "unknown option baz in the dhcp4 option space"},
+		{"unknown code", keaconfig.SingleOptionData{Code: 223, CSVFormat: storkutil.Ptr(true), Data: "1"}, "option with code 223 in the dhcp4 option space has no definition"},
+		{"invalid address", keaconfig.SingleOptionData{Code: 6, CSVFormat: storkutil.Ptr(true), Data: "192.0.2.1, foo"}, "invalid value of option domain-name-servers: foo is neither an IP address nor prefix"},
+		{"IPv6 address in IPv4 option", keaconfig.SingleOptionData{Code: 3, CSVFormat: storkutil.Ptr(true), Data: "2001:db8:1::1"}, "2001:db8:1::1 is not a valid IPv4 address option field value"},
+		{"too many values", keaconfig.SingleOptionData{Code: 1, CSVFormat: storkutil.Ptr(true), Data: "255.255.255.0, 255.255.0.0"}, "too many values specified for option subnet-mask"},
+		{"too few record values", keaconfig.SingleOptionData{Code: 222, CSVFormat: storkutil.Ptr(true), Data: "1"}, "option foo requires 2 values but 1 were specified"},
+		{"value out of range", keaconfig.SingleOptionData{Code: 222, CSVFormat: storkutil.Ptr(true), Data: "300, 192.0.2.1"}, "300 is not a valid uint8 option field value"},
+		{"invalid hex", keaconfig.SingleOptionData{Code: 6, CSVFormat: storkutil.Ptr(false), Data: "foo"}, "option data foo is not a valid string of hexadecimal digits"},
+		{"no csv-format and unknown code", keaconfig.SingleOptionData{Code: 223, Data: "0102"}, "option with code 223 in the dhcp4 option space has no definition"},
+		{"empty option with data", keaconfig.SingleOptionData{Code: 152, Space: "dhcp-agent-options-space", CSVFormat: storkutil.Ptr(true), Data: "1"}, "option virtual-subnet-select-ctrl carries no data but 1 was specified"},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			err := keaconfig.ValidateSingleOptionData(testCase.optionData, storkutil.IPv4, optionDefs)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), testCase.err)
+		})
+	}
+}
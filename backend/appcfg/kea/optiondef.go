@@ -1,6 +1,13 @@
 package keaconfig
 
-import dhcpmodel "isc.org/stork/datamodel/dhcp"
+import (
+	"slices"
+	"strings"
+
+	"github.com/pkg/errors"
+	dhcpmodel "isc.org/stork/datamodel/dhcp"
+	storkutil "isc.org/stork/util"
+)
 
 // DHCP option type enum, as defined in Kea.
 type DHCPOptionType = string
@@ -8,8 +15,9 @@ type DHCPOptionType = string
 // Valid DHCP option types.
 const (
 	EmptyOption       DHCPOptionType = "empty"
+	BinaryOption      DHCPOptionType = "binary"
 	StringOption      DHCPOptionType = "string"
-	BoolOption        DHCPOptionType = "bool"
+	BooleanOption     DHCPOptionType = "boolean"
 	Uint8Option       DHCPOptionType = "uint8"
 	Uint16Option      DHCPOptionType = "uint16"
 	Uint32Option      DHCPOptionType = "uint32"
@@ -91,6 +99,243 @@ func (def dhcpOptionDefinition) GetType() DHCPOptionType {
 	return def.OptionType
 }
 
+// Custom DHCP option definition in Kea configuration (i.e., an element of
+// the option-def list). In contrast to the standard option definitions, the
+// record types are specified as a comma-separated list.
+type OptionDef struct {
+	Array       bool           `json:"array,omitempty"`
+	Code        uint16         `json:"code"`
+	Encapsulate string         `json:"encapsulate,omitempty"`
+	Name        string         `json:"name"`
+	RecordTypes string         `json:"record-types,omitempty"`
+	Space       string         `json:"space,omitempty"`
+	OptionType  DHCPOptionType `json:"type"`
+}
+
+// Checks if the option is an array (has an array of option fields).
+func (def OptionDef) GetArray() bool {
+	return def.Array
+}
+
+// Returns option code.
+func (def OptionDef) GetCode() uint16 {
+	return def.Code
+}
+
+// Returns option space encapsulated by the option.
+func (def OptionDef) GetEncapsulate() string {
+	return def.Encapsulate
+}
+
+// Returns option name.
+func (def OptionDef) GetName() string {
+	return def.Name
+}
+
+// Returns record types (when an option is a record of different fields).
+// The types are parsed from the comma-separated list.
+func (def OptionDef) GetRecordTypes() (recordTypes []DHCPOptionType) {
+	for _, recordType := range strings.Split(def.RecordTypes, ",") {
+		if recordType = strings.TrimSpace(recordType); recordType != "" {
+			recordTypes = append(recordTypes, recordType)
+		}
+	}
+	return
+}
+
+// Returns option space.
+func (def OptionDef) GetSpace() string {
+	return def.Space
+}
+
+// Returns option type.
+func (def OptionDef) GetType() DHCPOptionType {
+	return def.OptionType
+}
+
+// Checks if the custom option definition is valid for the specified
+// universe. Besides the basic sanity checks, it verifies that the definition
+// does not redefine a standard option, i.e., that the standard option with
+// the same code doesn't exist in the same option space.
+func (def OptionDef) Validate(universe storkutil.IPType) error {
+	if def.Name == "" {
+		return errors.New("option definition name must not be empty")
+	}
+	if def.Code == 0 || (universe == storkutil.IPv4 && def.Code > 254) {
+		return errors.Errorf("option definition %s has invalid code %d", def.Name, def.Code)
+	}
+	if !isValidOptionFieldType(def.OptionType) && def.OptionType != EmptyOption && def.OptionType != RecordOption {
+		return errors.Errorf("option definition %s has invalid type %s", def.Name, def.OptionType)
+	}
+	if def.Array && def.OptionType == EmptyOption {
+		return errors.Errorf("option definition %s must not be an array of empty fields", def.Name)
+	}
+	recordTypes := def.GetRecordTypes()
+	switch {
+	case def.OptionType == RecordOption && len(recordTypes) == 0:
+		return errors.Errorf("option definition %s is a record but lacks record types", def.Name)
+	case def.OptionType != RecordOption && len(recordTypes) > 0:
+		return errors.Errorf("option definition %s specifies record types but it is not a record", def.Name)
+	}
+	for _, recordType := range recordTypes {
+		if !isValidOptionFieldType(recordType) {
+			return errors.Errorf("option definition %s has invalid record type %s", def.Name, recordType)
+		}
+	}
+	space := def.getSpaceOrDefault(universe)
+	if stdDef := NewStdDHCPOptionDefinitionLookup().FindByCodeSpace(def.Code, space, universe); stdDef != nil {
+		return errors.Errorf("option definition %s conflicts with the standard option %s (code %d) in the %s option space",
+			def.Name, stdDef.GetName(), def.Code, space)
+	}
+	return nil
+}
+
+// Returns the option space of the definition. If the space has not been
+// specified, it returns the top-level option space for the universe, as
+// Kea does.
+func (def OptionDef) getSpaceOrDefault(universe storkutil.IPType) string {
+	if def.Space != "" {
+		return def.Space
+	}
+	if universe == storkutil.IPv6 {
+		return dhcpmodel.DHCPv6OptionSpace
+	}
+	return dhcpmodel.DHCPv4OptionSpace
+}
+
+// Checks if the specified type can be used as an option field type (i.e.,
+// as an option type or as one of the record types). The type names are
+// the ones accepted by Kea. In particular, Kea doesn't accept the bool
+// field type used by Stork internally for the boolean option fields.
+func isValidOptionFieldType(optionType DHCPOptionType) bool {
+	return slices.Contains([]DHCPOptionType{
+		BinaryOption,
+		StringOption,
+		BooleanOption,
+		Uint8Option,
+		Uint16Option,
+		Uint32Option,
+		Int8Option,
+		Int16Option,
+		Int32Option,
+		IPv4AddressOption,
+		IPv6AddressOption,
+		IPv6PrefixOption,
+		PsidOption,
+		FqdnOption,
+		TupleOption,
+	}, optionType)
+}
+
+// Returns the universe of the DHCP server configuration.
+func (c *Config) getUniverse() storkutil.IPType {
+	if c.IsDHCPv6() {
+		return storkutil.IPv6
+	}
+	return storkutil.IPv4
+}
+
+// Returns the index of the option definition with the specified code and
+// space in the raw list of the option definitions or -1 if such definition
+// does not exist.
+func (c *Config) findRawOptionDef(optionDefs []any, code uint16, space string) int {
+	universe := c.getUniverse()
+	space = OptionDef{Space: space}.getSpaceOrDefault(universe)
+	for i, optionDef := range optionDefs {
+		rawDef, ok := optionDef.(RawConfig)
+		if !ok {
+			continue
+		}
+		rawCode, ok := rawDef["code"].(float64)
+		if !ok || uint16(rawCode) != code {
+			continue
+		}
+		rawSpace, _ := rawDef["space"].(string)
+		if (OptionDef{Space: rawSpace}).getSpaceOrDefault(universe) == space {
+			return i
+		}
+	}
+	return -1
+}
+
+// Returns a custom option definition with the specified code and space or
+// nil if such definition does not exist. An empty space denotes the top-level
+// option space.
+func (c *Config) GetOptionDef(code uint16, space string) *OptionDef {
+	universe := c.getUniverse()
+	space = OptionDef{Space: space}.getSpaceOrDefault(universe)
+	for _, def := range c.GetOptionDefs() {
+		if def.Code == code && def.getSpaceOrDefault(universe) == space {
+			return &def
+		}
+	}
+	return nil
+}
+
+// Appends a new custom option definition to the DHCP server configuration.
+// It returns an error if the definition is invalid, redefines a standard
+// option or the definition with the same code and space already exists.
+func (c *Config) AddOptionDef(def *OptionDef) error {
+	optionDefs, root, err := c.getRawDHCPConfigList("option-def")
+	if err != nil {
+		return err
+	}
+	if err = def.Validate(c.getUniverse()); err != nil {
+		return err
+	}
+	if c.findRawOptionDef(optionDefs, def.Code, def.Space) >= 0 {
+		return errors.Errorf("option definition with code %d in the %s option space already exists",
+			def.Code, def.getSpaceOrDefault(c.getUniverse()))
+	}
+	raw, err := convertToRawConfig(def)
+	if err != nil {
+		return err
+	}
+	optionDefs = append(optionDefs, raw)
+	return c.setRawDHCPConfigList(root, "option-def", optionDefs)
+}
+
+// Replaces an existing custom option definition in the DHCP server
+// configuration. The definition is found by code and space. It returns
+// an error if the definition is invalid or does not exist.
+func (c *Config) UpdateOptionDef(def *OptionDef) error {
+	optionDefs, root, err := c.getRawDHCPConfigList("option-def")
+	if err != nil {
+		return err
+	}
+	if err = def.Validate(c.getUniverse()); err != nil {
+		return err
+	}
+	index := c.findRawOptionDef(optionDefs, def.Code, def.Space)
+	if index < 0 {
+		return errors.Errorf("option definition with code %d in the %s option space does not exist",
+			def.Code, def.getSpaceOrDefault(c.getUniverse()))
+	}
+	raw, err := convertToRawConfig(def)
+	if err != nil {
+		return err
+	}
+	optionDefs[index] = raw
+	return c.setRawDHCPConfigList(root, "option-def", optionDefs)
+}
+
+// Removes a custom option definition with the specified code and space from
+// the DHCP server configuration. It returns an error if the definition does
+// not exist.
+func (c *Config) DeleteOptionDef(code uint16, space string) error {
+	optionDefs, root, err := c.getRawDHCPConfigList("option-def")
+	if err != nil {
+		return err
+	}
+	index := c.findRawOptionDef(optionDefs, code, space)
+	if index < 0 {
+		return errors.Errorf("option definition with code %d in the %s option space does not exist",
+			code, OptionDef{Space: space}.getSpaceOrDefault(c.getUniverse()))
+	}
+	optionDefs = append(optionDefs[:index], optionDefs[index+1:]...)
+	return c.setRawDHCPConfigList(root, "option-def", optionDefs)
+}
+
 // Given the option definition, find field type at specified position.
 // First option field has position 0. If the position is out of bounds,
 // the second returned parameter is false and the option field type
@@ -110,11 +355,21 @@ func GetDHCPOptionDefinitionFieldType(def DHCPOptionDefinition, position int) (d
 			return "", false
 		}
 		recordPosition := position % len(recordTypes)
-		return recordTypes[recordPosition], true
+		return getOptionFieldType(recordTypes[recordPosition]), true
 	default:
 		if position > 0 && !def.GetArray() {
 			return "", false
 		}
-		return def.GetType(), true
+		return getOptionFieldType(def.GetType()), true
+	}
+}
+
+// Converts the option definition type to the option field type. The
+// Kea boolean type corresponds to the bool field type. Other types have
+// the same names.
+func getOptionFieldType(optionType DHCPOptionType) dhcpmodel.DHCPOptionFieldType {
+	if optionType == BooleanOption {
+		return dhcpmodel.BoolField
 	}
+	return optionType
 }
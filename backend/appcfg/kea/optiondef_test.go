@@ -5,6 +5,7 @@ import (
 
 	require "github.com/stretchr/testify/require"
 	dhcpmodel "isc.org/stork/datamodel/dhcp"
+	storkutil "isc.org/stork/util"
 )
 
 //go:generate mockgen -package=keaconfig_test -destination=optiondefmock_test.go isc.org/stork/appcfg/kea DHCPOptionDefinition
@@ -62,6 +63,29 @@ func TestDHCPOptionDefinitionFieldTypeSimple(t *testing.T) {
 	require.Empty(t, fieldType)
 }
 
+// Check that the boolean option type is converted to the bool option
+// field type, also in the records.
+func TestDHCPOptionDefinitionFieldTypeBoolean(t *testing.T) {
+	def := &dhcpOptionDefinition{
+		OptionType: BooleanOption,
+	}
+	fieldType, ok := GetDHCPOptionDefinitionFieldType(def, 0)
+	require.True(t, ok)
+	require.Equal(t, dhcpmodel.BoolField, fieldType)
+
+	def = &dhcpOptionDefinition{
+		OptionType:  RecordOption,
+		RecordTypes: []DHCPOptionType{Uint8Option, BooleanOption, BinaryOption},
+	}
+	fieldType, ok = GetDHCPOptionDefinitionFieldType(def, 1)
+	require.True(t, ok)
+	require.Equal(t, dhcpmodel.BoolField, fieldType)
+
+	fieldType, ok = GetDHCPOptionDefinitionFieldType(def, 2)
+	require.True(t, ok)
+	require.Equal(t, dhcpmodel.BinaryField, fieldType)
+}
+
 // Check that the same option field type is returned regardless of
 // the option for an option comprising an array.
 func TestDHCPOptionDefinitionFieldTypeSimpleArray(t *testing.T) {
@@ -140,3 +164,213 @@ func TestDHCPOptionDefinitionFieldTypeRecordNoRecordTypes(t *testing.T) {
 	require.False(t, ok)
 	require.Empty(t, fieldType)
 }
+
+// Returns test Kea configuration with two custom option definitions.
+func getTestConfigWithOptionDefs(t *testing.T) *Config {
+	configStr := `{
+        "Dhcp4": {
+            "option-def": [
+                {
+                    "name": "foo",
+                    "code": 222,
+                    "type": "uint32",
+                    "space": "dhcp4"
+                },
+                {
+                    "name": "bar",
+                    "code": 1,
+                    "type": "record",
+                    "record-types": "uint8, ipv4-address",
+                    "space": "vendor-encapsulated-options-space"
+                }
+            ],
+            "hash": "1234"
+        }
+    }`
+	cfg, err := NewConfig(configStr)
+	require.NoError(t, err)
+	require.NotNil(t, cfg)
+	return cfg
+}
+
+// Test that the custom option definitions are parsed and implement the
+// DHCPOptionDefinition interface.
+func TestGetOptionDefs(t *testing.T) {
+	cfg := getTestConfigWithOptionDefs(t)
+
+	defs := cfg.GetOptionDefs()
+	require.Len(t, defs, 2)
+
+	def := cfg.GetOptionDef(222, "")
+	require.NotNil(t, def)
+	require.Equal(t, "foo", def.GetName())
+	require.EqualValues(t, 222, def.GetCode())
+	require.Equal(t, Uint32Option, def.GetType())
+	require.Empty(t, def.GetRecordTypes())
+
+	def = cfg.GetOptionDef(1, "vendor-encapsulated-options-space")
+	require.NotNil(t, def)
+	require.Equal(t, "bar", def.GetName())
+	require.Equal(t, RecordOption, def.GetType())
+	require.Equal(t, []DHCPOptionType{Uint8Option, IPv4AddressOption}, def.GetRecordTypes())
+
+	require.Nil(t, cfg.GetOptionDef(1, "dhcp4"))
+}
+
+// Test option definition validation.
+func TestValidateOptionDef(t *testing.T) {
+	require.NoError(t, OptionDef{Name: "foo", Code: 222, OptionType: StringOption}.Validate(storkutil.IPv4))
+	require.NoError(t, OptionDef{Name: "foo", Code: 1000, OptionType: EmptyOption}.Validate(storkutil.IPv6))
+	require.NoError(t, OptionDef{
+		Name:        "foo",
+		Code:        222,
+		OptionType:  RecordOption,
+		RecordTypes: "uint8,string",
+		Array:       true,
+	}.Validate(storkutil.IPv4))
+
+	// Kea names of the binary and boolean types.
+	require.NoError(t, OptionDef{Name: "foo", Code: 222, OptionType: BinaryOption}.Validate(storkutil.IPv4))
+	require.NoError(t, OptionDef{Name: "foo", Code: 222, OptionType: BooleanOption, Array: true}.Validate(storkutil.IPv4))
+	require.NoError(t, OptionDef{
+		Name:        "foo",
+		Code:        1000,
+		OptionType:  RecordOption,
+		RecordTypes: "boolean, uint8, binary",
+	}.Validate(storkutil.IPv6))
+
+	require.ErrorContains(t, OptionDef{Code: 222, OptionType: StringOption}.Validate(storkutil.IPv4),
+		"option definition name must not be empty")
+	require.ErrorContains(t, OptionDef{Name: "foo", Code: 0, OptionType: StringOption}.Validate(storkutil.IPv6),
+		"option definition foo has invalid code 0")
+	require.ErrorContains(t, OptionDef{Name: "foo", Code: 255, OptionType: StringOption}.Validate(storkutil.IPv4),
+		"option definition foo has invalid code 255")
+	require.ErrorContains(t, OptionDef{Name: "foo", Code: 222, OptionType: "uint64"}.Validate(storkutil.IPv4),
+		"option definition foo has invalid type uint64")
+	// The bool type is not accepted by Kea.
+	require.ErrorContains(t, OptionDef{Name: "foo", Code: 222, OptionType: "bool"}.Validate(storkutil.IPv4),
+		"option definition foo has invalid type bool")
+	require.ErrorContains(t, OptionDef{Name: "foo", Code: 222, OptionType: RecordOption, RecordTypes: "uint8,bool"}.Validate(storkutil.IPv4),
+		"option definition foo has invalid record type bool")
+	require.ErrorContains(t, OptionDef{Name: "foo", Code: 222, OptionType: EmptyOption, Array: true}.Validate(storkutil.IPv4),
+		"option definition foo must not be an array of empty fields")
+	require.ErrorContains(t, OptionDef{Name: "foo", Code: 222, OptionType: RecordOption}.Validate(storkutil.IPv4),
+		"option definition foo is a record but lacks record types")
+	require.ErrorContains(t, OptionDef{Name: "foo", Code: 222, OptionType: StringOption, RecordTypes: "uint8"}.Validate(storkutil.IPv4),
+		"option definition foo specifies record types but it is not a record")
+	require.ErrorContains(t, OptionDef{Name: "foo", Code: 222, OptionType: RecordOption, RecordTypes: "uint8,record"}.Validate(storkutil.IPv4),
+		"option definition foo has invalid record type record")
+}
+
+// Test that a custom option definition must not redefine a standard option.
+func TestValidateOptionDefStdConflict(t *testing.T) {
+	err := OptionDef{Name: "foo", Code: 6, OptionType: IPv4AddressOption}.Validate(storkutil.IPv4)
+	require.ErrorContains(t, err, "option definition foo conflicts with the standard option")
+	err = OptionDef{Name: "foo", Code: 23, OptionType: IPv6AddressOption, Space: "dhcp6"}.Validate(storkutil.IPv6)
+	require.ErrorContains(t, err, "option definition foo conflicts with the standard option")
+
+	// The same code in a custom option space is fine.
+	require.NoError(t, OptionDef{Name: "foo", Code: 6, OptionType: IPv4AddressOption, Space: "foo-space"}.Validate(storkutil.IPv4))
+}
+
+// Test that a custom option definition can be added to the configuration.
+func TestAddOptionDef(t *testing.T) {
+	cfg := getTestConfigWithOptionDefs(t)
+
+	err := cfg.AddOptionDef(&OptionDef{
+		Name:       "baz",
+		Code:       223,
+		OptionType: StringOption,
+	})
+	require.NoError(t, err)
+
+	defs := cfg.GetOptionDefs()
+	require.Len(t, defs, 3)
+	require.Equal(t, "baz", defs[2].Name)
+	require.NotNil(t, cfg.GetOptionDef(223, "dhcp4"))
+
+	rawDefs := cfg.Raw["Dhcp4"].(RawConfig)["option-def"].([]any)
+	require.Len(t, rawDefs, 3)
+	require.NotContains(t, cfg.Raw, "hash")
+}
+
+// Test that adding an option definition to the configuration lacking the
+// option-def list succeeds.
+func TestAddOptionDefNoDefs(t *testing.T) {
+	cfg, err := NewConfig(`{"Dhcp6": {}}`)
+	require.NoError(t, err)
+
+	err = cfg.AddOptionDef(&OptionDef{
+		Name:       "foo",
+		Code:       1000,
+		OptionType: Uint16Option,
+		Array:      true,
+	})
+	require.NoError(t, err)
+
+	def := cfg.GetOptionDef(1000, "dhcp6")
+	require.NotNil(t, def)
+	require.True(t, def.Array)
+}
+
+// Test that adding a duplicate or invalid option definition fails.
+func TestAddOptionDefDuplicateInvalid(t *testing.T) {
+	cfg := getTestConfigWithOptionDefs(t)
+
+	err := cfg.AddOptionDef(&OptionDef{
+		Name:       "baz",
+		Code:       222,
+		OptionType: StringOption,
+		Space:      "dhcp4",
+	})
+	require.ErrorContains(t, err, "option definition with code 222 in the dhcp4 option space already exists")
+
+	err = cfg.AddOptionDef(&OptionDef{
+		Name:       "baz",
+		Code:       3,
+		OptionType: IPv4AddressOption,
+	})
+	require.ErrorContains(t, err, "conflicts with the standard option")
+	require.Len(t, cfg.GetOptionDefs(), 2)
+}
+
+// Test that an existing option definition can be updated.
+func TestUpdateOptionDef(t *testing.T) {
+	cfg := getTestConfigWithOptionDefs(t)
+
+	err := cfg.UpdateOptionDef(&OptionDef{
+		Name:       "foo-updated",
+		Code:       222,
+		OptionType: StringOption,
+	})
+	require.NoError(t, err)
+
+	defs := cfg.GetOptionDefs()
+	require.Len(t, defs, 2)
+	require.Equal(t, "foo-updated", defs[0].Name)
+	require.Equal(t, StringOption, defs[0].OptionType)
+
+	err = cfg.UpdateOptionDef(&OptionDef{
+		Name:       "foo",
+		Code:       224,
+		OptionType: StringOption,
+	})
+	require.ErrorContains(t, err, "option definition with code 224 in the dhcp4 option space does not exist")
+}
+
+// Test that an option definition can be deleted from the configuration.
+func TestDeleteOptionDef(t *testing.T) {
+	cfg := getTestConfigWithOptionDefs(t)
+
+	err := cfg.DeleteOptionDef(1, "vendor-encapsulated-options-space")
+	require.NoError(t, err)
+	require.Len(t, cfg.GetOptionDefs(), 1)
+
+	err = cfg.DeleteOptionDef(1, "vendor-encapsulated-options-space")
+	require.ErrorContains(t, err, "option definition with code 1 in the vendor-encapsulated-options-space option space does not exist")
+
+	err = cfg.DeleteOptionDef(222, "")
+	require.NoError(t, err)
+	require.Empty(t, cfg.GetOptionDefs())
+	require.NotContains(t, cfg.Raw["Dhcp4"], "option-def")
+}
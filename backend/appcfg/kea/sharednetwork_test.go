@@ -8,6 +8,7 @@ import (
 	gomock "go.uber.org/mock/gomock"
 	keaconfig "isc.org/stork/appcfg/kea"
 	dhcpmodel "isc.org/stork/datamodel/dhcp"
+	storkutil "isc.org/stork/util"
 )
 
 //go:generate mockgen -package=keaconfig_test -destination=sharednetworkmock_test.go isc.org/stork/appcfg/kea SharedNetworkAccessor
@@ -165,7 +166,7 @@ func TestDecodeAllKeysSharedNetwork4(t *testing.T) {
 	require.Len(t, params.OptionData, 1)
 	require.True(t, params.OptionData[0].AlwaysSend)
 	require.EqualValues(t, 3, params.OptionData[0].Code)
-	require.True(t, params.OptionData[0].CSVFormat)
+	require.Equal(t, storkutil.Ptr(true), params.OptionData[0].CSVFormat)
 	require.Equal(t, "192.0.3.1", params.OptionData[0].Data)
 	require.Equal(t, "routers", params.OptionData[0].Name)
 	require.Equal(t, "dhcp4", params.OptionData[0].Space)
@@ -217,7 +218,7 @@ func TestDecodeAllKeysSharedNetwork6(t *testing.T) {
 	require.Len(t, params.OptionData, 1)
 	require.True(t, params.OptionData[0].AlwaysSend)
 	require.EqualValues(t, 7, params.OptionData[0].Code)
-	require.True(t, params.OptionData[0].CSVFormat)
+	require.Equal(t, storkutil.Ptr(true), params.OptionData[0].CSVFormat)
 	require.Equal(t, "15", params.OptionData[0].Data)
 	require.Equal(t, "preference", params.OptionData[0].Name)
 	require.Equal(t, "dhcp6", params.OptionData[0].Space)
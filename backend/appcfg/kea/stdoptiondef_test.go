@@ -50,3 +50,18 @@ func TestFindDHCPv6OptionDefinitionNotExists(t *testing.T) {
 	def := lookup.FindByCodeSpace(11, "foo", storkutil.IPv6)
 	require.Nil(t, def)
 }
+
+// Test that a standard option definition can be found by name and space.
+func TestFindOptionDefinitionByName(t *testing.T) {
+	lookup := NewStdDHCPOptionDefinitionLookup()
+	def := lookup.FindByNameSpace("domain-name-servers", "dhcp4", storkutil.IPv4)
+	require.NotNil(t, def)
+	require.EqualValues(t, 6, def.GetCode())
+
+	def = lookup.FindByNameSpace("dns-servers", "dhcp6", storkutil.IPv6)
+	require.NotNil(t, def)
+	require.EqualValues(t, 23, def.GetCode())
+
+	require.Nil(t, lookup.FindByNameSpace("dns-servers", "dhcp4", storkutil.IPv4))
+	require.Nil(t, lookup.FindByNameSpace("domain-name-servers", "dhcp6", storkutil.IPv6))
+}
@@ -58,7 +58,7 @@ func TestDecodeAllKeysSubnet4(t *testing.T) {
 	require.Len(t, params.GetDHCPOptions(), 1)
 	require.True(t, params.GetDHCPOptions()[0].AlwaysSend)
 	require.EqualValues(t, 3, params.GetDHCPOptions()[0].Code)
-	require.True(t, params.GetDHCPOptions()[0].CSVFormat)
+	require.Equal(t, storkutil.Ptr(true), params.GetDHCPOptions()[0].CSVFormat)
 	require.Equal(t, "192.0.3.1", params.GetDHCPOptions()[0].Data)
 	require.Equal(t, "routers", params.GetDHCPOptions()[0].Name)
 	require.Equal(t, "dhcp4", params.GetDHCPOptions()[0].Space)
@@ -272,7 +272,7 @@ func TestDecodeAllKeysSubnet6(t *testing.T) {
 	require.Len(t, params.GetDHCPOptions(), 1)
 	require.True(t, params.GetDHCPOptions()[0].AlwaysSend)
 	require.EqualValues(t, 7, params.GetDHCPOptions()[0].Code)
-	require.True(t, params.GetDHCPOptions()[0].CSVFormat)
+	require.Equal(t, storkutil.Ptr(true), params.GetDHCPOptions()[0].CSVFormat)
 	require.Equal(t, "15", params.GetDHCPOptions()[0].Data)
 	require.Equal(t, "preference", params.GetDHCPOptions()[0].Name)
 	require.Equal(t, "dhcp6", params.GetDHCPOptions()[0].Space)
@@ -0,0 +1,194 @@
+package keactrl
+
+import (
+	keaconfig "isc.org/stork/appcfg/kea"
+)
+
+const (
+	ConfigBackendPull         CommandName = "config-backend-pull"
+	RemoteClass4Del           CommandName = "remote-class4-del"
+	RemoteClass6Del           CommandName = "remote-class6-del"
+	RemoteClass4Set           CommandName = "remote-class4-set"
+	RemoteClass6Set           CommandName = "remote-class6-set"
+	RemoteGlobalParameter4Del CommandName = "remote-global-parameter4-del"
+	RemoteGlobalParameter6Del CommandName = "remote-global-parameter6-del"
+	RemoteGlobalParameter4Set CommandName = "remote-global-parameter4-set"
+	RemoteGlobalParameter6Set CommandName = "remote-global-parameter6-set"
+	RemoteNetwork4Del         CommandName = "remote-network4-del"
+	RemoteNetwork6Del         CommandName = "remote-network6-del"
+	RemoteNetwork4Set         CommandName = "remote-network4-set"
+	RemoteNetwork6Set         CommandName = "remote-network6-set"
+	RemoteOption4GlobalDel    CommandName = "remote-option4-global-del"
+	RemoteOption6GlobalDel    CommandName = "remote-option6-global-del"
+	RemoteOption4GlobalSet    CommandName = "remote-option4-global-set"
+	RemoteOption6GlobalSet    CommandName = "remote-option6-global-set"
+	RemoteOptionDef4Del       CommandName = "remote-option-def4-del"
+	RemoteOptionDef6Del       CommandName = "remote-option-def6-del"
+	RemoteOptionDef4Set       CommandName = "remote-option-def4-set"
+	RemoteOptionDef6Set       CommandName = "remote-option-def6-set"
+	RemoteSubnet4DelByID      CommandName = "remote-subnet4-del-by-id"
+	RemoteSubnet6DelByID      CommandName = "remote-subnet6-del-by-id"
+	RemoteSubnet4Set          CommandName = "remote-subnet4-set"
+	RemoteSubnet6Set          CommandName = "remote-subnet6-set"
+)
+
+// Selects the configuration backend database to which the cb_cmds
+// commands are applied.
+type RemoteSelector struct {
+	Type string `json:"type"`
+}
+
+// Creates the configuration backend selector from the database
+// configuration.
+func NewRemoteSelector(database *keaconfig.Database) *RemoteSelector {
+	return &RemoteSelector{
+		Type: database.Type,
+	}
+}
+
+// Represents an IPv4 subnet in the remote-subnet4-set command. The
+// command requires the shared network name to be explicitly specified,
+// even when the subnet does not belong to any shared network.
+type remoteSubnet4 struct {
+	*keaconfig.Subnet4
+	SharedNetworkName *string `json:"shared-network-name"`
+}
+
+// Represents an IPv6 subnet in the remote-subnet6-set command.
+type remoteSubnet6 struct {
+	*keaconfig.Subnet6
+	SharedNetworkName *string `json:"shared-network-name"`
+}
+
+// Represents an option identified by code and space in the commands
+// deleting the options and option definitions.
+type remoteOptionKey struct {
+	Code  uint16 `json:"code"`
+	Space string `json:"space"`
+}
+
+// Creates a command for the configuration backend with the remote selector
+// and, optionally, the server tags.
+func newRemoteCommand(commandName CommandName, remote *RemoteSelector, serverTags []string, daemonNames ...DaemonName) *Command {
+	command := NewCommandBase(commandName, daemonNames...).WithArgument("remote", remote)
+	if len(serverTags) > 0 {
+		command = command.WithArgument("server-tags", serverTags)
+	}
+	return command
+}
+
+// Selects the command name for the specified family.
+func selectCommandName(family int, commandName4, commandName6 CommandName) CommandName {
+	if family == 4 {
+		return commandName4
+	}
+	return commandName6
+}
+
+// Creates config-backend-pull command.
+func NewCommandConfigBackendPull(daemonNames ...DaemonName) *Command {
+	return NewCommandBase(ConfigBackendPull, daemonNames...)
+}
+
+// Creates remote-subnet4-set command. The shared network name is nil when
+// the subnet does not belong to any shared network.
+func NewCommandRemoteSubnet4Set(remote *RemoteSelector, serverTag string, subnet *keaconfig.Subnet4, sharedNetworkName *string, daemonNames ...DaemonName) *Command {
+	return newRemoteCommand(RemoteSubnet4Set, remote, []string{serverTag}, daemonNames...).
+		WithArrayArgument("subnets", remoteSubnet4{Subnet4: subnet, SharedNetworkName: sharedNetworkName})
+}
+
+// Creates remote-subnet6-set command. The shared network name is nil when
+// the subnet does not belong to any shared network.
+func NewCommandRemoteSubnet6Set(remote *RemoteSelector, serverTag string, subnet *keaconfig.Subnet6, sharedNetworkName *string, daemonNames ...DaemonName) *Command {
+	return newRemoteCommand(RemoteSubnet6Set, remote, []string{serverTag}, daemonNames...).
+		WithArrayArgument("subnets", remoteSubnet6{Subnet6: subnet, SharedNetworkName: sharedNetworkName})
+}
+
+// Creates remote-subnet4-del-by-id or remote-subnet6-del-by-id depending
+// on the family.
+func NewCommandRemoteSubnetDelByID(family int, remote *RemoteSelector, subnetID int64, daemonNames ...DaemonName) *Command {
+	return newRemoteCommand(selectCommandName(family, RemoteSubnet4DelByID, RemoteSubnet6DelByID), remote, nil, daemonNames...).
+		WithArrayArgument("subnets", &keaconfig.SubnetCmdsDeletedSubnet{ID: subnetID})
+}
+
+// Creates remote-network4-set command. The subnets belonging to the
+// shared network are not included in the command. They must be set with
+// the remote-subnet4-set commands.
+func NewCommandRemoteNetwork4Set(remote *RemoteSelector, serverTag string, sharedNetwork *keaconfig.SharedNetwork4, daemonNames ...DaemonName) *Command {
+	sharedNetworkCopy := *sharedNetwork
+	sharedNetworkCopy.Subnet4 = nil
+	return newRemoteCommand(RemoteNetwork4Set, remote, []string{serverTag}, daemonNames...).
+		WithArrayArgument("shared-networks", &sharedNetworkCopy)
+}
+
+// Creates remote-network6-set command. The subnets belonging to the
+// shared network are not included in the command. They must be set with
+// the remote-subnet6-set commands.
+func NewCommandRemoteNetwork6Set(remote *RemoteSelector, serverTag string, sharedNetwork *keaconfig.SharedNetwork6, daemonNames ...DaemonName) *Command {
+	sharedNetworkCopy := *sharedNetwork
+	sharedNetworkCopy.Subnet6 = nil
+	return newRemoteCommand(RemoteNetwork6Set, remote, []string{serverTag}, daemonNames...).
+		WithArrayArgument("shared-networks", &sharedNetworkCopy)
+}
+
+// Creates remote-network4-del or remote-network6-del depending on the family.
+func NewCommandRemoteNetworkDel(family int, remote *RemoteSelector, name string, subnetsAction keaconfig.SharedNetworkSubnetsAction, daemonNames ...DaemonName) *Command {
+	return newRemoteCommand(selectCommandName(family, RemoteNetwork4Del, RemoteNetwork6Del), remote, nil, daemonNames...).
+		WithArrayArgument("shared-networks", map[string]string{"name": name}).
+		WithArgument("subnets-action", subnetsAction)
+}
+
+// Creates remote-global-parameter4-set or remote-global-parameter6-set
+// depending on the family. The parameters map holds the parameter names
+// and their values.
+func NewCommandRemoteGlobalParameterSet(family int, remote *RemoteSelector, serverTag string, parameters map[string]any, daemonNames ...DaemonName) *Command {
+	return newRemoteCommand(selectCommandName(family, RemoteGlobalParameter4Set, RemoteGlobalParameter6Set), remote, []string{serverTag}, daemonNames...).
+		WithArgument("parameters", parameters)
+}
+
+// Creates remote-global-parameter4-del or remote-global-parameter6-del
+// depending on the family.
+func NewCommandRemoteGlobalParameterDel(family int, remote *RemoteSelector, serverTag string, names []string, daemonNames ...DaemonName) *Command {
+	return newRemoteCommand(selectCommandName(family, RemoteGlobalParameter4Del, RemoteGlobalParameter6Del), remote, []string{serverTag}, daemonNames...).
+		WithArgument("parameters", names)
+}
+
+// Creates remote-option4-global-set or remote-option6-global-set depending
+// on the family.
+func NewCommandRemoteOptionGlobalSet(family int, remote *RemoteSelector, serverTag string, option *keaconfig.SingleOptionData, daemonNames ...DaemonName) *Command {
+	return newRemoteCommand(selectCommandName(family, RemoteOption4GlobalSet, RemoteOption6GlobalSet), remote, []string{serverTag}, daemonNames...).
+		WithArrayArgument("options", option)
+}
+
+// Creates remote-option4-global-del or remote-option6-global-del depending
+// on the family.
+func NewCommandRemoteOptionGlobalDel(family int, remote *RemoteSelector, serverTag string, code uint16, space string, daemonNames ...DaemonName) *Command {
+	return newRemoteCommand(selectCommandName(family, RemoteOption4GlobalDel, RemoteOption6GlobalDel), remote, []string{serverTag}, daemonNames...).
+		WithArrayArgument("options", remoteOptionKey{Code: code, Space: space})
+}
+
+// Creates remote-option-def4-set or remote-option-def6-set depending on
+// the family.
+func NewCommandRemoteOptionDefSet(family int, remote *RemoteSelector, serverTag string, optionDef *keaconfig.OptionDef, daemonNames ...DaemonName) *Command {
+	return newRemoteCommand(selectCommandName(family, RemoteOptionDef4Set, RemoteOptionDef6Set), remote, []string{serverTag}, daemonNames...).
+		WithArrayArgument("option-defs", optionDef)
+}
+
+// Creates remote-option-def4-del or remote-option-def6-del depending on
+// the family.
+func NewCommandRemoteOptionDefDel(family int, remote *RemoteSelector, serverTag string, code uint16, space string, daemonNames ...DaemonName) *Command {
+	return newRemoteCommand(selectCommandName(family, RemoteOptionDef4Del, RemoteOptionDef6Del), remote, []string{serverTag}, daemonNames...).
+		WithArrayArgument("option-defs", remoteOptionKey{Code: code, Space: space})
+}
+
+// Creates remote-class4-set or remote-class6-set depending on the family.
+func NewCommandRemoteClassSet(family int, remote *RemoteSelector, serverTag string, clientClass *keaconfig.ClientClass, daemonNames ...DaemonName) *Command {
+	return newRemoteCommand(selectCommandName(family, RemoteClass4Set, RemoteClass6Set), remote, []string{serverTag}, daemonNames...).
+		WithArrayArgument("client-classes", clientClass)
+}
+
+// Creates remote-class4-del or remote-class6-del depending on the family.
+func NewCommandRemoteClassDel(family int, remote *RemoteSelector, name string, daemonNames ...DaemonName) *Command {
+	return newRemoteCommand(selectCommandName(family, RemoteClass4Del, RemoteClass6Del), remote, nil, daemonNames...).
+		WithArrayArgument("client-classes", map[string]string{"name": name})
+}
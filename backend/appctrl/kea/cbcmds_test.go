@@ -0,0 +1,377 @@
+package keactrl
+
+import (
+	"testing"
+
+	require "github.com/stretchr/testify/require"
+	keaconfig "isc.org/stork/appcfg/kea"
+	storkutil "isc.org/stork/util"
+)
+
+// Tests creating the remote selector from the database configuration.
+func TestNewRemoteSelector(t *testing.T) {
+	remote := NewRemoteSelector(&keaconfig.Database{
+		Type: "mysql",
+		Name: "kea",
+		Host: "localhost",
+	})
+	require.NotNil(t, remote)
+	require.Equal(t, "mysql", remote.Type)
+}
+
+// Tests config-backend-pull command.
+func TestNewCommandConfigBackendPull(t *testing.T) {
+	command := NewCommandConfigBackendPull(DHCPv4)
+	require.NotNil(t, command)
+	require.JSONEq(t, `{
+		"command": "config-backend-pull",
+		"service": ["dhcp4"]
+	}`, command.Marshal())
+}
+
+// Tests remote-subnet4-set command.
+func TestNewCommandRemoteSubnet4Set(t *testing.T) {
+	command := NewCommandRemoteSubnet4Set(&RemoteSelector{Type: "mysql"}, "all", &keaconfig.Subnet4{
+		MandatorySubnetParameters: keaconfig.MandatorySubnetParameters{
+			ID:     5,
+			Subnet: "192.0.2.0/24",
+		},
+	}, nil, DHCPv4)
+	require.NotNil(t, command)
+	require.JSONEq(t, `{
+		"command": "remote-subnet4-set",
+		"service": ["dhcp4"],
+		"arguments": {
+			"remote": {
+				"type": "mysql"
+			},
+			"server-tags": ["all"],
+			"subnets": [
+				{
+					"id": 5,
+					"subnet": "192.0.2.0/24",
+					"shared-network-name": null
+				}
+			]
+		}
+	}`, command.Marshal())
+}
+
+// Tests remote-subnet6-set command with a shared network name.
+func TestNewCommandRemoteSubnet6Set(t *testing.T) {
+	command := NewCommandRemoteSubnet6Set(&RemoteSelector{Type: "postgresql"}, "server1", &keaconfig.Subnet6{
+		MandatorySubnetParameters: keaconfig.MandatorySubnetParameters{
+			ID:     5,
+			Subnet: "2001:db8:1::/64",
+		},
+	}, storkutil.Ptr("foo"), DHCPv6)
+	require.NotNil(t, command)
+	require.JSONEq(t, `{
+		"command": "remote-subnet6-set",
+		"service": ["dhcp6"],
+		"arguments": {
+			"remote": {
+				"type": "postgresql"
+			},
+			"server-tags": ["server1"],
+			"subnets": [
+				{
+					"id": 5,
+					"subnet": "2001:db8:1::/64",
+					"shared-network-name": "foo"
+				}
+			]
+		}
+	}`, command.Marshal())
+}
+
+// Tests remote-subnet4-del-by-id and remote-subnet6-del-by-id commands.
+func TestNewCommandRemoteSubnetDelByID(t *testing.T) {
+	command := NewCommandRemoteSubnetDelByID(4, &RemoteSelector{Type: "mysql"}, 5, DHCPv4)
+	require.NotNil(t, command)
+	require.JSONEq(t, `{
+		"command": "remote-subnet4-del-by-id",
+		"service": ["dhcp4"],
+		"arguments": {
+			"remote": {
+				"type": "mysql"
+			},
+			"subnets": [
+				{
+					"id": 5
+				}
+			]
+		}
+	}`, command.Marshal())
+
+	command = NewCommandRemoteSubnetDelByID(6, &RemoteSelector{Type: "mysql"}, 5, DHCPv6)
+	require.NotNil(t, command)
+	require.EqualValues(t, RemoteSubnet6DelByID, command.GetCommand())
+}
+
+// Tests remote-network4-set command. The subnets should be excluded.
+func TestNewCommandRemoteNetwork4Set(t *testing.T) {
+	sharedNetwork := &keaconfig.SharedNetwork4{
+		Name:          "foo",
+		Authoritative: storkutil.Ptr(true),
+		Subnet4: []keaconfig.Subnet4{
+			{
+				MandatorySubnetParameters: keaconfig.MandatorySubnetParameters{
+					ID:     5,
+					Subnet: "192.0.2.0/24",
+				},
+			},
+		},
+	}
+	command := NewCommandRemoteNetwork4Set(&RemoteSelector{Type: "mysql"}, "all", sharedNetwork, DHCPv4)
+	require.NotNil(t, command)
+	require.JSONEq(t, `{
+		"command": "remote-network4-set",
+		"service": ["dhcp4"],
+		"arguments": {
+			"remote": {
+				"type": "mysql"
+			},
+			"server-tags": ["all"],
+			"shared-networks": [
+				{
+					"name": "foo",
+					"authoritative": true
+				}
+			]
+		}
+	}`, command.Marshal())
+	// The original shared network should be intact.
+	require.Len(t, sharedNetwork.Subnet4, 1)
+}
+
+// Tests remote-network6-set command. The subnets should be excluded.
+func TestNewCommandRemoteNetwork6Set(t *testing.T) {
+	sharedNetwork := &keaconfig.SharedNetwork6{
+		Name: "foo",
+		Subnet6: []keaconfig.Subnet6{
+			{
+				MandatorySubnetParameters: keaconfig.MandatorySubnetParameters{
+					ID:     5,
+					Subnet: "2001:db8:1::/64",
+				},
+			},
+		},
+	}
+	command := NewCommandRemoteNetwork6Set(&RemoteSelector{Type: "mysql"}, "all", sharedNetwork, DHCPv6)
+	require.NotNil(t, command)
+	require.JSONEq(t, `{
+		"command": "remote-network6-set",
+		"service": ["dhcp6"],
+		"arguments": {
+			"remote": {
+				"type": "mysql"
+			},
+			"server-tags": ["all"],
+			"shared-networks": [
+				{
+					"name": "foo"
+				}
+			]
+		}
+	}`, command.Marshal())
+	require.Len(t, sharedNetwork.Subnet6, 1)
+}
+
+// Tests remote-network4-del command.
+func TestNewCommandRemoteNetworkDel(t *testing.T) {
+	command := NewCommandRemoteNetworkDel(4, &RemoteSelector{Type: "mysql"}, "foo", keaconfig.SharedNetworkSubnetsActionDelete, DHCPv4)
+	require.NotNil(t, command)
+	require.JSONEq(t, `{
+		"command": "remote-network4-del",
+		"service": ["dhcp4"],
+		"arguments": {
+			"remote": {
+				"type": "mysql"
+			},
+			"shared-networks": [
+				{
+					"name": "foo"
+				}
+			],
+			"subnets-action": "delete"
+		}
+	}`, command.Marshal())
+}
+
+// Tests remote-global-parameter4-set and remote-global-parameter6-del commands.
+func TestNewCommandRemoteGlobalParameters(t *testing.T) {
+	command := NewCommandRemoteGlobalParameterSet(4, &RemoteSelector{Type: "mysql"}, "server1", map[string]any{
+		"valid-lifetime":           3600,
+		"dhcp-ddns.enable-updates": true,
+		"ddns-qualifying-suffix":   "example.org",
+	}, DHCPv4)
+	require.NotNil(t, command)
+	require.JSONEq(t, `{
+		"command": "remote-global-parameter4-set",
+		"service": ["dhcp4"],
+		"arguments": {
+			"remote": {
+				"type": "mysql"
+			},
+			"server-tags": ["server1"],
+			"parameters": {
+				"valid-lifetime": 3600,
+				"dhcp-ddns.enable-updates": true,
+				"ddns-qualifying-suffix": "example.org"
+			}
+		}
+	}`, command.Marshal())
+
+	command = NewCommandRemoteGlobalParameterDel(6, &RemoteSelector{Type: "mysql"}, "server1", []string{"valid-lifetime"}, DHCPv6)
+	require.NotNil(t, command)
+	require.JSONEq(t, `{
+		"command": "remote-global-parameter6-del",
+		"service": ["dhcp6"],
+		"arguments": {
+			"remote": {
+				"type": "mysql"
+			},
+			"server-tags": ["server1"],
+			"parameters": ["valid-lifetime"]
+		}
+	}`, command.Marshal())
+}
+
+// Tests remote-option4-global-set and remote-option4-global-del commands.
+func TestNewCommandRemoteOptionGlobal(t *testing.T) {
+	command := NewCommandRemoteOptionGlobalSet(4, &RemoteSelector{Type: "mysql"}, "all", &keaconfig.SingleOptionData{
+		Code:      6,
+		CSVFormat: storkutil.Ptr(true),
+		Data:      "192.0.2.1",
+		Space:     "dhcp4",
+	}, DHCPv4)
+	require.NotNil(t, command)
+	require.JSONEq(t, `{
+		"command": "remote-option4-global-set",
+		"service": ["dhcp4"],
+		"arguments": {
+			"remote": {
+				"type": "mysql"
+			},
+			"server-tags": ["all"],
+			"options": [
+				{
+					"code": 6,
+					"csv-format": true,
+					"data": "192.0.2.1",
+					"space": "dhcp4"
+				}
+			]
+		}
+	}`, command.Marshal())
+
+	command = NewCommandRemoteOptionGlobalDel(4, &RemoteSelector{Type: "mysql"}, "all", 6, "dhcp4", DHCPv4)
+	require.NotNil(t, command)
+	require.JSONEq(t, `{
+		"command": "remote-option4-global-del",
+		"service": ["dhcp4"],
+		"arguments": {
+			"remote": {
+				"type": "mysql"
+			},
+			"server-tags": ["all"],
+			"options": [
+				{
+					"code": 6,
+					"space": "dhcp4"
+				}
+			]
+		}
+	}`, command.Marshal())
+}
+
+// Tests remote-option-def6-set and remote-option-def6-del commands.
+func TestNewCommandRemoteOptionDef(t *testing.T) {
+	command := NewCommandRemoteOptionDefSet(6, &RemoteSelector{Type: "mysql"}, "all", &keaconfig.OptionDef{
+		Code:       222,
+		Name:       "foo",
+		Space:      "dhcp6",
+		OptionType: "uint32",
+	}, DHCPv6)
+	require.NotNil(t, command)
+	require.JSONEq(t, `{
+		"command": "remote-option-def6-set",
+		"service": ["dhcp6"],
+		"arguments": {
+			"remote": {
+				"type": "mysql"
+			},
+			"server-tags": ["all"],
+			"option-defs": [
+				{
+					"code": 222,
+					"name": "foo",
+					"space": "dhcp6",
+					"type": "uint32"
+				}
+			]
+		}
+	}`, command.Marshal())
+
+	command = NewCommandRemoteOptionDefDel(6, &RemoteSelector{Type: "mysql"}, "all", 222, "dhcp6", DHCPv6)
+	require.NotNil(t, command)
+	require.JSONEq(t, `{
+		"command": "remote-option-def6-del",
+		"service": ["dhcp6"],
+		"arguments": {
+			"remote": {
+				"type": "mysql"
+			},
+			"server-tags": ["all"],
+			"option-defs": [
+				{
+					"code": 222,
+					"space": "dhcp6"
+				}
+			]
+		}
+	}`, command.Marshal())
+}
+
+// Tests remote-class4-set and remote-class4-del commands.
+func TestNewCommandRemoteClass(t *testing.T) {
+	command := NewCommandRemoteClassSet(4, &RemoteSelector{Type: "mysql"}, "all", &keaconfig.ClientClass{
+		Name: "foo",
+		Test: storkutil.Ptr("member('ALL')"),
+	}, DHCPv4)
+	require.NotNil(t, command)
+	require.JSONEq(t, `{
+		"command": "remote-class4-set",
+		"service": ["dhcp4"],
+		"arguments": {
+			"remote": {
+				"type": "mysql"
+			},
+			"server-tags": ["all"],
+			"client-classes": [
+				{
+					"name": "foo",
+					"test": "member('ALL')"
+				}
+			]
+		}
+	}`, command.Marshal())
+
+	command = NewCommandRemoteClassDel(4, &RemoteSelector{Type: "mysql"}, "foo", DHCPv4)
+	require.NotNil(t, command)
+	require.JSONEq(t, `{
+		"command": "remote-class4-del",
+		"service": ["dhcp4"],
+		"arguments": {
+			"remote": {
+				"type": "mysql"
+			},
+			"client-classes": [
+				{
+					"name": "foo"
+				}
+			]
+		}
+	}`, command.Marshal())
+}
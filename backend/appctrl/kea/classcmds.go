@@ -0,0 +1,36 @@
+package keactrl
+
+import keaconfig "isc.org/stork/appcfg/kea"
+
+const (
+	ClassAdd    CommandName = "class-add"
+	ClassDel    CommandName = "class-del"
+	ClassGet    CommandName = "class-get"
+	ClassList   CommandName = "class-list"
+	ClassUpdate CommandName = "class-update"
+)
+
+// Creates class-add command.
+func NewCommandClassAdd(clientClass *keaconfig.ClientClass, daemonNames ...DaemonName) *Command {
+	return NewCommandBase(ClassAdd, daemonNames...).WithArrayArgument("client-classes", clientClass)
+}
+
+// Creates class-update command.
+func NewCommandClassUpdate(clientClass *keaconfig.ClientClass, daemonNames ...DaemonName) *Command {
+	return NewCommandBase(ClassUpdate, daemonNames...).WithArrayArgument("client-classes", clientClass)
+}
+
+// Creates class-del command.
+func NewCommandClassDel(name string, daemonNames ...DaemonName) *Command {
+	return NewCommandBase(ClassDel, daemonNames...).WithArgument("name", name)
+}
+
+// Creates class-get command.
+func NewCommandClassGet(name string, daemonNames ...DaemonName) *Command {
+	return NewCommandBase(ClassGet, daemonNames...).WithArgument("name", name)
+}
+
+// Creates class-list command.
+func NewCommandClassList(daemonNames ...DaemonName) *Command {
+	return NewCommandBase(ClassList, daemonNames...)
+}
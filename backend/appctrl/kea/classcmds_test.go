@@ -0,0 +1,93 @@
+package keactrl
+
+import (
+	"testing"
+
+	require "github.com/stretchr/testify/require"
+	keaconfig "isc.org/stork/appcfg/kea"
+	storkutil "isc.org/stork/util"
+)
+
+// Tests class-add command.
+func TestNewCommandClassAdd(t *testing.T) {
+	command := NewCommandClassAdd(&keaconfig.ClientClass{
+		Name:       "foo",
+		Test:       storkutil.Ptr("member('KNOWN')"),
+		NextServer: storkutil.Ptr("192.0.2.1"),
+	}, DHCPv4)
+	require.NotNil(t, command)
+	require.JSONEq(t, `{
+		"command": "class-add",
+		"service": ["dhcp4"],
+		"arguments": {
+			"client-classes": [
+				{
+					"name": "foo",
+					"test": "member('KNOWN')",
+					"next-server": "192.0.2.1"
+				}
+			]
+		}
+	}`, command.Marshal())
+}
+
+// Tests class-update command.
+func TestNewCommandClassUpdate(t *testing.T) {
+	command := NewCommandClassUpdate(&keaconfig.ClientClass{
+		Name:           "foo",
+		OnlyIfRequired: storkutil.Ptr(true),
+		PreferredLifetimeParameters: keaconfig.PreferredLifetimeParameters{
+			PreferredLifetime: storkutil.Ptr(int64(3000)),
+		},
+	}, DHCPv6)
+	require.NotNil(t, command)
+	require.JSONEq(t, `{
+		"command": "class-update",
+		"service": ["dhcp6"],
+		"arguments": {
+			"client-classes": [
+				{
+					"name": "foo",
+					"only-if-required": true,
+					"preferred-lifetime": 3000
+				}
+			]
+		}
+	}`, command.Marshal())
+}
+
+// Tests class-del command.
+func TestNewCommandClassDel(t *testing.T) {
+	command := NewCommandClassDel("foo", DHCPv4)
+	require.NotNil(t, command)
+	require.JSONEq(t, `{
+		"command": "class-del",
+		"service": ["dhcp4"],
+		"arguments": {
+			"name": "foo"
+		}
+	}`, command.Marshal())
+}
+
+// Tests class-get command.
+func TestNewCommandClassGet(t *testing.T) {
+	command := NewCommandClassGet("foo", DHCPv6)
+	require.NotNil(t, command)
+	require.JSONEq(t, `{
+		"command": "class-get",
+		"service": ["dhcp6"],
+		"arguments": {
+			"name": "foo"
+		}
+	}`, command.Marshal())
+}
+
+// Tests class-list command.
+func TestNewCommandClassList(t *testing.T) {
+	command := NewCommandClassList(DHCPv4)
+	require.NotNil(t, command)
+	require.JSONEq(t, `{
+		"command": "class-list",
+		"service": ["dhcp4"]
+	}`, command.Marshal())
+}
@@ -18,8 +18,47 @@ const (
 	Lease4GetByHWAddress CommandName = "lease4-get-by-hw-address"
 	StatLease4Get        CommandName = "stat-lease4-get"
 	StatLease6Get        CommandName = "stat-lease6-get"
+	Lease4Add            CommandName = "lease4-add"
+	Lease6Add            CommandName = "lease6-add"
+	Lease4Update         CommandName = "lease4-update"
+	Lease6Update         CommandName = "lease6-update"
+	Lease4Del            CommandName = "lease4-del"
+	Lease6Del            CommandName = "lease6-del"
+	Lease4Wipe           CommandName = "lease4-wipe"
+	Lease4GetPage        CommandName = "lease4-get-page"
+	Lease6GetPage        CommandName = "lease6-get-page"
+	Lease4GetAll         CommandName = "lease4-get-all"
+	Lease6GetAll         CommandName = "lease6-get-all"
 )
 
+// Value of the from argument of the lease4-get-page and lease6-get-page
+// commands to fetch the first page of leases.
+const LeasePageStart = "start"
+
+// Lease parameters specified in the commands adding or updating a lease.
+// The DHCPv6 specific parameters (e.g., DUID, IAID) are omitted in the
+// commands sent to the DHCPv4 server.
+type LeaseParams struct {
+	IPAddress         string         `json:"ip-address"`
+	SubnetID          int64          `json:"subnet-id,omitempty"`
+	HWAddress         string         `json:"hw-address,omitempty"`
+	ClientID          string         `json:"client-id,omitempty"`
+	DUID              string         `json:"duid,omitempty"`
+	IAID              *int64         `json:"iaid,omitempty"`
+	Type              LeaseType      `json:"type,omitempty"`
+	PrefixLength      int64          `json:"prefix-len,omitempty"`
+	ValidLifetime     *int64         `json:"valid-lft,omitempty"`
+	PreferredLifetime *int64         `json:"preferred-lft,omitempty"`
+	Hostname          string         `json:"hostname,omitempty"`
+	FqdnFwd           bool           `json:"fqdn-fwd,omitempty"`
+	FqdnRev           bool           `json:"fqdn-rev,omitempty"`
+	State             *int64         `json:"state,omitempty"`
+	UserContext       map[string]any `json:"user-context,omitempty"`
+	// Instructs Kea to create the lease if it doesn't exist when
+	// updating the lease.
+	ForceCreate bool `json:"force-create,omitempty"`
+}
+
 // Creates lease4-get command.
 func NewCommandLease4Get(ipAddress string, daemons ...DaemonName) *Command {
 	return NewCommandBase(Lease4Get, daemons...).WithArgument("ip-address", ipAddress)
@@ -31,3 +70,84 @@ func NewCommandLease6Get(leaseType LeaseType, ipAddress string, daemons ...Daemo
 		WithArgument("type", leaseType).
 		WithArgument("ip-address", ipAddress)
 }
+
+// Creates lease4-add command.
+func NewCommandLease4Add(lease *LeaseParams, daemons ...DaemonName) *Command {
+	return NewCommandBase(Lease4Add, daemons...).WithArguments(lease)
+}
+
+// Creates lease6-add command.
+func NewCommandLease6Add(lease *LeaseParams, daemons ...DaemonName) *Command {
+	return NewCommandBase(Lease6Add, daemons...).WithArguments(lease)
+}
+
+// Creates lease4-update command.
+func NewCommandLease4Update(lease *LeaseParams, daemons ...DaemonName) *Command {
+	return NewCommandBase(Lease4Update, daemons...).WithArguments(lease)
+}
+
+// Creates lease6-update command.
+func NewCommandLease6Update(lease *LeaseParams, daemons ...DaemonName) *Command {
+	return NewCommandBase(Lease6Update, daemons...).WithArguments(lease)
+}
+
+// Creates lease4-del command.
+func NewCommandLease4Del(ipAddress string, daemons ...DaemonName) *Command {
+	return NewCommandBase(Lease4Del, daemons...).WithArgument("ip-address", ipAddress)
+}
+
+// Creates lease6-del command.
+func NewCommandLease6Del(leaseType LeaseType, ipAddress string, daemons ...DaemonName) *Command {
+	return NewCommandBase(Lease6Del, daemons...).
+		WithArgument("type", leaseType).
+		WithArgument("ip-address", ipAddress)
+}
+
+// Creates lease4-wipe command. The subnet-id argument is only included
+// when the subnet ID is greater than 0. Otherwise, the command removes
+// the leases from all subnets.
+func NewCommandLease4Wipe(subnetID int64, daemons ...DaemonName) *Command {
+	command := NewCommandBase(Lease4Wipe, daemons...)
+	if subnetID > 0 {
+		command = command.WithArgument("subnet-id", subnetID)
+	}
+	return command
+}
+
+// Creates lease4-get-page command. The from argument should be set to
+// LeasePageStart to fetch the first page or to the last address returned
+// in the previous page to fetch the next page.
+func NewCommandLease4GetPage(from string, limit int64, daemons ...DaemonName) *Command {
+	return NewCommandBase(Lease4GetPage, daemons...).
+		WithArgument("from", from).
+		WithArgument("limit", limit)
+}
+
+// Creates lease6-get-page command. The from argument should be set to
+// LeasePageStart to fetch the first page or to the last address returned
+// in the previous page to fetch the next page.
+func NewCommandLease6GetPage(from string, limit int64, daemons ...DaemonName) *Command {
+	return NewCommandBase(Lease6GetPage, daemons...).
+		WithArgument("from", from).
+		WithArgument("limit", limit)
+}
+
+// Creates lease4-get-all command. If the subnet IDs are specified, only
+// the leases belonging to these subnets are returned.
+func NewCommandLease4GetAll(subnetIDs []int64, daemons ...DaemonName) *Command {
+	command := NewCommandBase(Lease4GetAll, daemons...)
+	if len(subnetIDs) > 0 {
+		command = command.WithArgument("subnets", subnetIDs)
+	}
+	return command
+}
+
+// Creates lease6-get-all command. If the subnet IDs are specified, only
+// the leases belonging to these subnets are returned.
+func NewCommandLease6GetAll(subnetIDs []int64, daemons ...DaemonName) *Command {
+	command := NewCommandBase(Lease6GetAll, daemons...)
+	if len(subnetIDs) > 0 {
+		command = command.WithArgument("subnets", subnetIDs)
+	}
+	return command
+}
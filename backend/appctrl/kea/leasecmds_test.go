@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	require "github.com/stretchr/testify/require"
+	storkutil "isc.org/stork/util"
 )
 
 // Tests lease4-get command.
@@ -34,3 +35,197 @@ func TestNewCommandLease6Get(t *testing.T) {
 
 	}`, command.Marshal())
 }
+
+// Tests lease4-add command.
+func TestNewCommandLease4Add(t *testing.T) {
+	command := NewCommandLease4Add(&LeaseParams{
+		IPAddress:     "192.0.2.1",
+		SubnetID:      1,
+		HWAddress:     "01:02:03:04:05:06",
+		ValidLifetime: storkutil.Ptr(int64(3600)),
+		Hostname:      "myhost.example.org",
+	}, DHCPv4)
+	require.NotNil(t, command)
+	require.JSONEq(t, `{
+		"command": "lease4-add",
+		"service": ["dhcp4"],
+		"arguments": {
+			"ip-address": "192.0.2.1",
+			"subnet-id": 1,
+			"hw-address": "01:02:03:04:05:06",
+			"valid-lft": 3600,
+			"hostname": "myhost.example.org"
+		}
+	}`, command.Marshal())
+}
+
+// Tests lease6-add command.
+func TestNewCommandLease6Add(t *testing.T) {
+	command := NewCommandLease6Add(&LeaseParams{
+		IPAddress: "2001:db8:1::1",
+		DUID:      "01:02:03:04",
+		IAID:      storkutil.Ptr(int64(0)),
+		Type:      LeaseTypeNA,
+	}, DHCPv6)
+	require.NotNil(t, command)
+	require.JSONEq(t, `{
+		"command": "lease6-add",
+		"service": ["dhcp6"],
+		"arguments": {
+			"ip-address": "2001:db8:1::1",
+			"duid": "01:02:03:04",
+			"iaid": 0,
+			"type": "IA_NA"
+		}
+	}`, command.Marshal())
+}
+
+// Tests lease4-update command.
+func TestNewCommandLease4Update(t *testing.T) {
+	command := NewCommandLease4Update(&LeaseParams{
+		IPAddress:   "192.0.2.1",
+		HWAddress:   "01:02:03:04:05:06",
+		State:       storkutil.Ptr(int64(0)),
+		ForceCreate: true,
+	}, DHCPv4)
+	require.NotNil(t, command)
+	require.JSONEq(t, `{
+		"command": "lease4-update",
+		"service": ["dhcp4"],
+		"arguments": {
+			"ip-address": "192.0.2.1",
+			"hw-address": "01:02:03:04:05:06",
+			"state": 0,
+			"force-create": true
+		}
+	}`, command.Marshal())
+}
+
+// Tests lease6-update command.
+func TestNewCommandLease6Update(t *testing.T) {
+	command := NewCommandLease6Update(&LeaseParams{
+		IPAddress: "2001:db8:1::1",
+		DUID:      "01:02:03:04",
+		IAID:      storkutil.Ptr(int64(1)),
+	}, DHCPv6)
+	require.NotNil(t, command)
+	require.JSONEq(t, `{
+		"command": "lease6-update",
+		"service": ["dhcp6"],
+		"arguments": {
+			"ip-address": "2001:db8:1::1",
+			"duid": "01:02:03:04",
+			"iaid": 1
+		}
+	}`, command.Marshal())
+}
+
+// Tests lease4-del command.
+func TestNewCommandLease4Del(t *testing.T) {
+	command := NewCommandLease4Del("192.0.2.1", DHCPv4)
+	require.NotNil(t, command)
+	require.JSONEq(t, `{
+		"command": "lease4-del",
+		"service": ["dhcp4"],
+		"arguments": {
+			"ip-address": "192.0.2.1"
+		}
+	}`, command.Marshal())
+}
+
+// Tests lease6-del command.
+func TestNewCommandLease6Del(t *testing.T) {
+	command := NewCommandLease6Del(LeaseTypePD, "2001:db8:1::", DHCPv6)
+	require.NotNil(t, command)
+	require.JSONEq(t, `{
+		"command": "lease6-del",
+		"service": ["dhcp6"],
+		"arguments": {
+			"type": "IA_PD",
+			"ip-address": "2001:db8:1::"
+		}
+	}`, command.Marshal())
+}
+
+// Tests lease4-wipe command.
+func TestNewCommandLease4Wipe(t *testing.T) {
+	command := NewCommandLease4Wipe(5, DHCPv4)
+	require.NotNil(t, command)
+	require.JSONEq(t, `{
+		"command": "lease4-wipe",
+		"service": ["dhcp4"],
+		"arguments": {
+			"subnet-id": 5
+		}
+	}`, command.Marshal())
+
+	// Wipe leases in all subnets.
+	command = NewCommandLease4Wipe(0, DHCPv4)
+	require.NotNil(t, command)
+	require.JSONEq(t, `{
+		"command": "lease4-wipe",
+		"service": ["dhcp4"]
+	}`, command.Marshal())
+}
+
+// Tests lease4-get-page command.
+func TestNewCommandLease4GetPage(t *testing.T) {
+	command := NewCommandLease4GetPage(LeasePageStart, 100, DHCPv4)
+	require.NotNil(t, command)
+	require.JSONEq(t, `{
+		"command": "lease4-get-page",
+		"service": ["dhcp4"],
+		"arguments": {
+			"from": "start",
+			"limit": 100
+		}
+	}`, command.Marshal())
+}
+
+// Tests lease6-get-page command.
+func TestNewCommandLease6GetPage(t *testing.T) {
+	command := NewCommandLease6GetPage("2001:db8:1::10", 10, DHCPv6)
+	require.NotNil(t, command)
+	require.JSONEq(t, `{
+		"command": "lease6-get-page",
+		"service": ["dhcp6"],
+		"arguments": {
+			"from": "2001:db8:1::10",
+			"limit": 10
+		}
+	}`, command.Marshal())
+}
+
+// Tests lease4-get-all command.
+func TestNewCommandLease4GetAll(t *testing.T) {
+	command := NewCommandLease4GetAll([]int64{1, 2}, DHCPv4)
+	require.NotNil(t, command)
+	require.JSONEq(t, `{
+		"command": "lease4-get-all",
+		"service": ["dhcp4"],
+		"arguments": {
+			"subnets": [ 1, 2 ]
+		}
+	}`, command.Marshal())
+
+	// All subnets.
+	command = NewCommandLease4GetAll(nil, DHCPv4)
+	require.NotNil(t, command)
+	require.JSONEq(t, `{
+		"command": "lease4-get-all",
+		"service": ["dhcp4"]
+	}`, command.Marshal())
+}
+
+// Tests lease6-get-all command.
+func TestNewCommandLease6GetAll(t *testing.T) {
+	command := NewCommandLease6GetAll([]int64{3}, DHCPv6)
+	require.NotNil(t, command)
+	require.JSONEq(t, `{
+		"command": "lease6-get-all",
+		"service": ["dhcp6"],
+		"arguments": {
+			"subnets": [ 3 ]
+		}
+	}`, command.Marshal())
+}
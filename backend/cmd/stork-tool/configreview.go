@@ -0,0 +1,236 @@
+package main
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/urfave/cli/v2"
+
+	"isc.org/stork/server/configreview"
+	dbmodel "isc.org/stork/server/database/model"
+	storkutil "isc.org/stork/util"
+)
+
+// Loads the Kea configuration from the file and creates the daemon holding
+// it. The daemon name is inferred from the configuration root.
+func loadConfigReviewDaemon(filename string, id int64, version string) (*dbmodel.Daemon, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read the configuration file '%s'", filename)
+	}
+	config, err := dbmodel.NewKeaConfigFromJSON(string(data))
+	if err != nil {
+		return nil, errors.WithMessagef(err, "failed to parse the configuration file '%s'", filename)
+	}
+	var name string
+	switch {
+	case config.IsDHCPv4():
+		name = dbmodel.DaemonNameDHCPv4
+	case config.IsDHCPv6():
+		name = dbmodel.DaemonNameDHCPv6
+	case config.IsCtrlAgent():
+		name = dbmodel.DaemonNameCA
+	case config.IsD2():
+		name = dbmodel.DaemonNameD2
+	default:
+		return nil, errors.Errorf("the configuration file '%s' lacks the Dhcp4, Dhcp6, Control-agent or DhcpDdns root", filename)
+	}
+	daemon := dbmodel.NewKeaDaemon(name, true)
+	daemon.ID = id
+	daemon.Version = version
+	if err = daemon.SetConfig(config); err != nil {
+		return nil, errors.WithMessagef(err, "failed to process the configuration file '%s'", filename)
+	}
+	return daemon, nil
+}
+
+// A single entry of the configuration review output in the JSON format.
+type configReviewJSONResult struct {
+	File     string `json:"file"`
+	Daemon   string `json:"daemon"`
+	Checker  string `json:"checker"`
+	Severity string `json:"severity"`
+	Content  string `json:"content,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+// Test suites in the JUnit XML format. Each reviewed file is a test suite
+// and each finding is a failed test case.
+type configReviewJUnitSuites struct {
+	XMLName  xml.Name                 `xml:"testsuites"`
+	Name     string                   `xml:"name,attr"`
+	Tests    int                      `xml:"tests,attr"`
+	Failures int                      `xml:"failures,attr"`
+	Errors   int                      `xml:"errors,attr"`
+	Suites   []configReviewJUnitSuite `xml:"testsuite"`
+}
+
+// Test suite in the JUnit XML format.
+type configReviewJUnitSuite struct {
+	Name      string                  `xml:"name,attr"`
+	Tests     int                     `xml:"tests,attr"`
+	Failures  int                     `xml:"failures,attr"`
+	Errors    int                     `xml:"errors,attr"`
+	TestCases []configReviewJUnitCase `xml:"testcase"`
+}
+
+// Test case in the JUnit XML format.
+type configReviewJUnitCase struct {
+	Name      string                    `xml:"name,attr"`
+	ClassName string                    `xml:"classname,attr"`
+	Failure   *configReviewJUnitFailure `xml:"failure,omitempty"`
+	Error     *configReviewJUnitFailure `xml:"error,omitempty"`
+}
+
+// Failure or error of the test case in the JUnit XML format.
+type configReviewJUnitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// Writes the configuration review results in the specified format. The
+// labels map the daemon IDs to the names of the reviewed files.
+func writeConfigReviewResults(writer io.Writer, format string, results []*configreview.OfflineResult, daemons []*dbmodel.Daemon, labels map[int64]string) error {
+	label := func(daemon *dbmodel.Daemon) string {
+		return labels[daemon.ID]
+	}
+	switch format {
+	case "text":
+		for _, result := range results {
+			if result.Err != nil {
+				fmt.Fprintf(writer, "%s: [failed] %s: %s\n", label(result.Daemon), result.CheckerName, result.Err)
+				continue
+			}
+			fmt.Fprintf(writer, "%s: [%s] %s: %s\n", label(result.Daemon), result.Severity, result.CheckerName, result.FormatContent(label))
+		}
+		fmt.Fprintf(writer, "Reviewed %s, found %s\n",
+			storkutil.FormatNoun(int64(len(daemons)), "configuration", "s"),
+			storkutil.FormatNoun(int64(countConfigReviewIssues(results, configreview.SeverityInfo)), "issue", "s"))
+	case "json":
+		jsonResults := []configReviewJSONResult{}
+		for _, result := range results {
+			jsonResult := configReviewJSONResult{
+				File:     label(result.Daemon),
+				Daemon:   result.Daemon.Name,
+				Checker:  result.CheckerName,
+				Severity: string(result.Severity),
+			}
+			if result.Err != nil {
+				jsonResult.Error = result.Err.Error()
+			} else {
+				jsonResult.Content = result.FormatContent(label)
+			}
+			jsonResults = append(jsonResults, jsonResult)
+		}
+		encoder := json.NewEncoder(writer)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(jsonResults)
+	case "junit":
+		suites := configReviewJUnitSuites{
+			Name: "stork-tool config-review",
+		}
+		for _, daemon := range daemons {
+			suite := configReviewJUnitSuite{
+				Name: label(daemon),
+			}
+			for _, result := range results {
+				if result.Daemon.ID != daemon.ID {
+					continue
+				}
+				testCase := configReviewJUnitCase{
+					Name:      result.CheckerName,
+					ClassName: daemon.Name,
+				}
+				if result.Err != nil {
+					testCase.Error = &configReviewJUnitFailure{
+						Message: result.Err.Error(),
+						Type:    "error",
+					}
+					suite.Errors++
+				} else {
+					content := result.FormatContent(label)
+					testCase.Failure = &configReviewJUnitFailure{
+						Message: content,
+						Type:    string(result.Severity),
+						Text:    content,
+					}
+					suite.Failures++
+				}
+				suite.TestCases = append(suite.TestCases, testCase)
+				suite.Tests++
+			}
+			suites.Tests += suite.Tests
+			suites.Failures += suite.Failures
+			suites.Errors += suite.Errors
+			suites.Suites = append(suites.Suites, suite)
+		}
+		if _, err := io.WriteString(writer, xml.Header); err != nil {
+			return errors.Wrap(err, "failed to write the configuration review results")
+		}
+		encoder := xml.NewEncoder(writer)
+		encoder.Indent("", "  ")
+		if err := encoder.Encode(suites); err != nil {
+			return errors.Wrap(err, "failed to write the configuration review results")
+		}
+		fmt.Fprintln(writer)
+	default:
+		return errors.Errorf("unsupported output format %s; it can be one of 'text', 'json', 'junit'", format)
+	}
+	return nil
+}
+
+// Returns the number of the found issues with the severity equal to or
+// higher than the specified severity. The checker errors are not counted.
+func countConfigReviewIssues(results []*configreview.OfflineResult, severity configreview.Severity) (count int) {
+	for _, result := range results {
+		if result.Err == nil && result.Severity.IsAtLeast(severity) {
+			count++
+		}
+	}
+	return
+}
+
+// Execute config-review command. It reviews the Kea configuration files
+// without connecting to the database and prints the found issues. It
+// returns an error with a non-zero exit code if any of the found issues
+// has the severity equal to or higher than the specified severity.
+func runConfigReview(settings *cli.Context) error {
+	format := settings.String("format")
+	switch format {
+	case "text", "json", "junit":
+	default:
+		return errors.Errorf("unsupported output format %s; it can be one of 'text', 'json', 'junit'", format)
+	}
+	severity, err := configreview.ParseSeverity(settings.String("severity"))
+	if err != nil {
+		return err
+	}
+
+	var daemons []*dbmodel.Daemon
+	labels := make(map[int64]string)
+	for i, filename := range settings.StringSlice("config") {
+		id := int64(i + 1)
+		daemon, err := loadConfigReviewDaemon(filename, id, settings.String("kea-version"))
+		if err != nil {
+			return err
+		}
+		daemons = append(daemons, daemon)
+		labels[id] = filename
+	}
+
+	results := configreview.ReviewOffline(daemons)
+	if err = writeConfigReviewResults(settings.App.Writer, format, results, daemons, labels); err != nil {
+		return err
+	}
+
+	if count := countConfigReviewIssues(results, severity); count > 0 {
+		return cli.Exit(fmt.Sprintf("found %s with the %s or higher severity",
+			storkutil.FormatNoun(int64(count), "configuration issue", "s"), severity), 1)
+	}
+	return nil
+}
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+
+	dbmodel "isc.org/stork/server/database/model"
+)
+
+// Returns the DHCPv4 server configuration with the HA hook library and
+// the specified subnet pool. The subnet option lacks the csv-format
+// parameter, so it is validated as comma separated values.
+func getConfigReviewTestConfig(thisServerName, pool string) string {
+	return `{
+        "Dhcp4": {
+            "hooks-libraries": [
+                {
+                    "library": "/usr/lib/kea/libdhcp_lease_cmds.so"
+                },
+                {
+                    "library": "/usr/lib/kea/libdhcp_ha.so",
+                    "parameters": {
+                        "high-availability": [
+                            {
+                                "this-server-name": "` + thisServerName + `",
+                                "mode": "load-balancing",
+                                "peers": [
+                                    { "name": "server1", "url": "http://192.0.2.1:8001/", "role": "primary" },
+                                    { "name": "server2", "url": "http://192.0.2.2:8001/", "role": "secondary" }
+                                ]
+                            }
+                        ]
+                    }
+                }
+            ],
+            "subnet4": [
+                {
+                    "id": 1,
+                    "subnet": "192.0.2.0/24",
+                    "pools": [ { "pool": "` + pool + `" } ],
+                    "option-data": [ { "name": "routers", "data": "192.0.2.1" } ]
+                }
+            ]
+        }
+    }`
+}
+
+// Writes the configuration files of the HA partners with the divergent
+// pools and returns their locations.
+func writeConfigReviewTestFiles(t *testing.T) (string, string) {
+	dir := t.TempDir()
+	server1 := filepath.Join(dir, "server1.json")
+	server2 := filepath.Join(dir, "server2.json")
+	require.NoError(t, os.WriteFile(server1, []byte(getConfigReviewTestConfig("server1", "192.0.2.10-192.0.2.100")), 0o600))
+	require.NoError(t, os.WriteFile(server2, []byte(getConfigReviewTestConfig("server2", "192.0.2.10-192.0.2.200")), 0o600))
+	return server1, server2
+}
+
+// Runs the config-review command with the specified arguments. It returns
+// the command output and the error.
+func runConfigReviewTestCommand(t *testing.T, args ...string) (string, error) {
+	var output bytes.Buffer
+	app := setupApp()
+	app.Writer = &output
+	app.ExitErrHandler = func(*cli.Context, error) {}
+	err := app.Run(append([]string{"stork-tool", "config-review"}, args...))
+	return output.String(), err
+}
+
+// Test that the daemon is created from the configuration file and its
+// name is inferred from the configuration root.
+func TestLoadConfigReviewDaemon(t *testing.T) {
+	dir := t.TempDir()
+	configs := map[string]string{
+		dbmodel.DaemonNameDHCPv4: `{ "Dhcp4": { } }`,
+		dbmodel.DaemonNameDHCPv6: `{ "Dhcp6": { } }`,
+		dbmodel.DaemonNameCA:     `{ "Control-agent": { } }`,
+		dbmodel.DaemonNameD2:     `{ "DhcpDdns": { } }`,
+	}
+	for name, config := range configs {
+		filename := filepath.Join(dir, name+".json")
+		require.NoError(t, os.WriteFile(filename, []byte(config), 0o600))
+
+		daemon, err := loadConfigReviewDaemon(filename, 5, "2.6.0")
+		require.NoError(t, err, name)
+		require.Equal(t, name, daemon.Name)
+		require.EqualValues(t, 5, daemon.ID)
+		require.Equal(t, "2.6.0", daemon.Version)
+		require.NotNil(t, daemon.KeaDaemon.Config)
+	}
+}
+
+// Test that loading the invalid configuration files fails.
+func TestLoadConfigReviewDaemonError(t *testing.T) {
+	dir := t.TempDir()
+
+	_, err := loadConfigReviewDaemon(filepath.Join(dir, "missing.json"), 1, "")
+	require.ErrorContains(t, err, "failed to read the configuration file")
+
+	filename := filepath.Join(dir, "invalid.json")
+	require.NoError(t, os.WriteFile(filename, []byte(`{ "Dhcp4": `), 0o600))
+	_, err = loadConfigReviewDaemon(filename, 1, "")
+	require.ErrorContains(t, err, "failed to parse the configuration file")
+
+	filename = filepath.Join(dir, "unknown.json")
+	require.NoError(t, os.WriteFile(filename, []byte(`{ "Dhcp5": { } }`), 0o600))
+	_, err = loadConfigReviewDaemon(filename, 1, "")
+	require.ErrorContains(t, err, "lacks the Dhcp4, Dhcp6, Control-agent or DhcpDdns root")
+}
+
+// Test that the config review prints the found issues in the text format
+// and returns the non-zero exit code.
+func TestConfigReviewText(t *testing.T) {
+	server1, server2 := writeConfigReviewTestFiles(t)
+
+	output, err := runConfigReviewTestCommand(t, "-c", server1, "-c", server2)
+
+	require.Error(t, err)
+	var exitCoder cli.ExitCoder
+	require.ErrorAs(t, err, &exitCoder)
+	require.Equal(t, 1, exitCoder.ExitCode())
+	require.ErrorContains(t, err, "found 2 configuration issues with the warning or higher severity")
+
+	require.Contains(t, output, server1+": [error] ha_peers_consistency: Kea "+server1+" configuration is inconsistent")
+	require.Contains(t, output, "Differences with "+server2+": address pools of subnet 192.0.2.0/24 differ.")
+	require.Contains(t, output, server2+": [error] ha_peers_consistency: Kea "+server2+" configuration is inconsistent")
+	require.Contains(t, output, server1+": [info] stat_cmds_presence:")
+	require.Contains(t, output, "Reviewed 2 configurations, found 4 issues")
+}
+
+// Test that the issues below the specified severity don't cause the
+// non-zero exit code.
+func TestConfigReviewSeverity(t *testing.T) {
+	server1, _ := writeConfigReviewTestFiles(t)
+
+	output, err := runConfigReviewTestCommand(t, "-c", server1, "-s", "warning")
+	require.NoError(t, err)
+	require.Contains(t, output, "Reviewed 1 configuration, found 1 issue\n")
+
+	_, err = runConfigReviewTestCommand(t, "-c", server1, "-s", "info")
+	require.ErrorContains(t, err, "found 1 configuration issue with the info or higher severity")
+
+	_, err = runConfigReviewTestCommand(t, "-c", server1, "-s", "critical")
+	require.ErrorContains(t, err, "unsupported severity critical")
+}
+
+// Test that the config review prints the found issues in the JSON format.
+func TestConfigReviewJSON(t *testing.T) {
+	server1, server2 := writeConfigReviewTestFiles(t)
+
+	output, err := runConfigReviewTestCommand(t, "-c", server1, "-c", server2, "-f", "json", "-s", "error")
+	require.Error(t, err)
+
+	var results []configReviewJSONResult
+	require.NoError(t, json.Unmarshal([]byte(output), &results))
+	require.Len(t, results, 4)
+	require.Equal(t, server1, results[0].File)
+	require.Equal(t, dbmodel.DaemonNameDHCPv4, results[0].Daemon)
+	require.Equal(t, "stat_cmds_presence", results[0].Checker)
+	require.Equal(t, "info", results[0].Severity)
+	require.Equal(t, "ha_peers_consistency", results[1].Checker)
+	require.Equal(t, "error", results[1].Severity)
+	require.Contains(t, results[1].Content, "Differences with "+server2)
+	require.Empty(t, results[1].Error)
+}
+
+// Test that the config review prints the found issues in the JUnit XML
+// format.
+func TestConfigReviewJUnit(t *testing.T) {
+	server1, server2 := writeConfigReviewTestFiles(t)
+
+	output, err := runConfigReviewTestCommand(t, "-c", server1, "-c", server2, "-f", "junit")
+	require.Error(t, err)
+
+	require.Contains(t, output, `<?xml version="1.0" encoding="UTF-8"?>`)
+	require.Contains(t, output, `<testsuites name="stork-tool config-review" tests="4" failures="4" errors="0">`)
+	require.Contains(t, output, `<testsuite name="`+server1+`" tests="2" failures="2" errors="0">`)
+	require.Contains(t, output, `<testcase name="ha_peers_consistency" classname="dhcp4">`)
+	require.Contains(t, output, `<failure message="Kea `+server2+` configuration is inconsistent`)
+}
+
+// Test that an unsupported output format is rejected.
+func TestConfigReviewUnsupportedFormat(t *testing.T) {
+	server1, _ := writeConfigReviewTestFiles(t)
+
+	output, err := runConfigReviewTestCommand(t, "-c", server1, "-f", "yaml")
+	require.ErrorContains(t, err, "unsupported output format yaml")
+	require.Empty(t, output)
+}
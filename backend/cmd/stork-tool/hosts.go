@@ -0,0 +1,230 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/urfave/cli/v2"
+
+	dbmodel "isc.org/stork/server/database/model"
+	"isc.org/stork/server/gen/models"
+	"isc.org/stork/server/hostio"
+	storkutil "isc.org/stork/util"
+)
+
+// Returns the format of the host reservations file. If the format has not
+// been specified explicitly, it is inferred from the file extension.
+func getHostsFileFormat(settings *cli.Context, filename string) (hostio.Format, error) {
+	format := settings.String("format")
+	if format == "" {
+		format = strings.TrimPrefix(filepath.Ext(filename), ".")
+		if format == "" {
+			return "", errors.New("unable to infer the file format from the file name; please use --format option to specify it")
+		}
+	}
+	return hostio.ParseFormat(format)
+}
+
+// Execute hosts-export command. It exports the host reservations from the
+// database to a file or to stdout.
+func runHostsExport(settings *cli.Context) error {
+	filename := settings.String("file")
+	format, err := getHostsFileFormat(settings, filename)
+	if err != nil {
+		return err
+	}
+
+	db := getDBConn(settings)
+	defer db.Close()
+
+	var writer io.Writer = os.Stdout
+	if filename != "" {
+		file, err := os.OpenFile(filename, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+		if err != nil {
+			return errors.Wrapf(err, "failed to open output file '%s'", filename)
+		}
+		defer file.Close()
+		writer = file
+	}
+
+	count, err := hostio.Export(db, dbmodel.NewDHCPOptionDefinitionLookup(), writer, format)
+	if err != nil {
+		return err
+	}
+	if filename != "" {
+		log.WithField("file", filename).Infof("Exported %d host reservations", count)
+	}
+	return nil
+}
+
+// Client of the Stork server REST API used to import the host
+// reservations. The host reservations must be imported by the server
+// because it holds the connections with the Stork agents.
+type hostsImportClient struct {
+	url    string
+	client *http.Client
+}
+
+// Creates the client for the server with the specified URL.
+func newHostsImportClient(url string) (*hostsImportClient, error) {
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return &hostsImportClient{
+		url: strings.TrimSuffix(url, "/") + "/api",
+		client: &http.Client{
+			Jar: jar,
+			// Importing a large number of host reservations takes time.
+			Timeout: 30 * time.Minute,
+		},
+	}, nil
+}
+
+// Sends a request to the server. The body is serialized to JSON. If the
+// response is successful, it is deserialized into the result. Otherwise,
+// the error message returned by the server is returned as an error.
+func (c *hostsImportClient) send(method, path string, body, result any) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		reader = bytes.NewReader(data)
+	}
+	request, err := http.NewRequest(method, c.url+path, reader)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	request.Header.Set("Content-Type", "application/json")
+	response, err := c.client.Do(request)
+	if err != nil {
+		return errors.Wrapf(err, "failed to send request to %s", c.url+path)
+	}
+	defer response.Body.Close()
+	data, err := io.ReadAll(response.Body)
+	if err != nil {
+		return errors.Wrapf(err, "failed to read response from %s", c.url+path)
+	}
+	if response.StatusCode != http.StatusOK {
+		var apiError models.APIError
+		if err = json.Unmarshal(data, &apiError); err == nil && apiError.Message != nil {
+			return errors.Errorf("server returned status %d: %s", response.StatusCode, *apiError.Message)
+		}
+		return errors.Errorf("server returned status %d", response.StatusCode)
+	}
+	if result != nil {
+		if err = json.Unmarshal(data, result); err != nil {
+			return errors.Wrapf(err, "failed to parse response from %s", c.url+path)
+		}
+	}
+	return nil
+}
+
+// Logs in to the server using the internal authentication method.
+func (c *hostsImportClient) login(user, password string) error {
+	return c.send(http.MethodPost, "/sessions", &models.SessionCredentials{
+		AuthenticationMethodID: storkutil.Ptr("internal"),
+		Identifier:             &user,
+		Secret:                 &password,
+	}, nil)
+}
+
+// Logs out from the server.
+func (c *hostsImportClient) logout() error {
+	return c.send(http.MethodDelete, "/sessions", nil, nil)
+}
+
+// Sends the host reservations to the server and returns the import result.
+func (c *hostsImportClient) importHosts(hosts *models.HostsImport) (*models.HostsImportResult, error) {
+	result := &models.HostsImportResult{}
+	if err := c.send(http.MethodPost, "/hosts/import", hosts, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Execute hosts-import command. It reads the host reservations from the
+// file, verifies that the file can be parsed and sends it to the Stork
+// server which validates the host reservations and applies them in the
+// Kea servers. It logs the errors for the host reservations that couldn't
+// be imported.
+func runHostsImport(settings *cli.Context) error {
+	filename := settings.String("file")
+	format, err := getHostsFileFormat(settings, filename)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return errors.Wrapf(err, "failed to read input file '%s'", filename)
+	}
+	// Make sure the file is well-formed before sending it to the server.
+	records, err := hostio.Read(bytes.NewReader(data), format)
+	if err != nil {
+		return err
+	}
+	log.WithField("file", filename).Infof("Read %d host reservations", len(records))
+
+	password := settings.String("password")
+	if password == "" {
+		password, err = storkutil.GetSecretInTerminal("server password: ")
+		if err != nil {
+			return errors.WithMessage(err, "the server password must be specified with --password option or STORK_TOOL_SERVER_PASSWORD variable")
+		}
+	}
+
+	client, err := newHostsImportClient(settings.String("server-url"))
+	if err != nil {
+		return err
+	}
+	if err = client.login(settings.String("user"), password); err != nil {
+		return errors.WithMessage(err, "failed to log in to the server")
+	}
+	defer func() {
+		_ = client.logout()
+	}()
+
+	result, err := client.importHosts(&models.HostsImport{
+		Format:    storkutil.Ptr(string(format)),
+		Data:      storkutil.Ptr(string(data)),
+		DaemonIds: settings.Int64Slice("daemon-id"),
+		BatchSize: settings.Int64("batch-size"),
+	})
+	if err != nil {
+		return errors.WithMessage(err, "failed to import host reservations")
+	}
+
+	for _, row := range result.Rows {
+		if row.Status == "imported" {
+			continue
+		}
+		log.WithFields(log.Fields{
+			"row":        row.Row,
+			"identifier": row.IdentifierType + "=" + row.Identifier,
+			"subnet":     row.Subnet,
+			"status":     row.Status,
+		}).Error(row.Error)
+	}
+	log.WithFields(log.Fields{
+		"total":    result.Total,
+		"imported": result.Imported,
+		"invalid":  result.Invalid,
+		"failed":   result.Failed,
+	}).Info("Finished importing host reservations")
+
+	if result.Imported != result.Total {
+		return errors.Errorf("%d of %d host reservations could not be imported", result.Total-result.Imported, result.Total)
+	}
+	return nil
+}
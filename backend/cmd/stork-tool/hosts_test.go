@@ -0,0 +1,103 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"isc.org/stork/server/gen/models"
+	storkutil "isc.org/stork/util"
+)
+
+// Test that the client logs in to the server, imports the host
+// reservations and logs out.
+func TestHostsImportClient(t *testing.T) {
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		body, _ := io.ReadAll(r.Body)
+		switch r.URL.Path {
+		case "/api/sessions":
+			if r.Method == http.MethodPost {
+				var credentials models.SessionCredentials
+				require.NoError(t, json.Unmarshal(body, &credentials))
+				require.Equal(t, "internal", *credentials.AuthenticationMethodID)
+				require.Equal(t, "admin", *credentials.Identifier)
+				require.Equal(t, "secret", *credentials.Secret)
+				http.SetCookie(w, &http.Cookie{Name: "session", Value: "abc", Path: "/"})
+			}
+			w.WriteHeader(http.StatusOK)
+		case "/api/hosts/import":
+			// The session cookie should be sent.
+			cookie, err := r.Cookie("session")
+			require.NoError(t, err)
+			require.Equal(t, "abc", cookie.Value)
+
+			var hosts models.HostsImport
+			require.NoError(t, json.Unmarshal(body, &hosts))
+			require.Equal(t, "csv", *hosts.Format)
+			require.EqualValues(t, 10, hosts.BatchSize)
+			require.Equal(t, []int64{1, 2}, hosts.DaemonIds)
+			_ = json.NewEncoder(w).Encode(&models.HostsImportResult{
+				Total:    2,
+				Imported: 1,
+				Invalid:  1,
+				Rows: []*models.HostsImportRowResult{
+					{Row: 1, Status: "imported"},
+					{Row: 2, Status: "invalid", Error: "invalid identifier"},
+				},
+			})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client, err := newHostsImportClient(server.URL + "/")
+	require.NoError(t, err)
+
+	err = client.login("admin", "secret")
+	require.NoError(t, err)
+
+	result, err := client.importHosts(&models.HostsImport{
+		Format:    storkutil.Ptr("csv"),
+		Data:      storkutil.Ptr("identifier-type,identifier\n"),
+		DaemonIds: []int64{1, 2},
+		BatchSize: 10,
+	})
+	require.NoError(t, err)
+	require.NotNil(t, result)
+	require.EqualValues(t, 2, result.Total)
+	require.Len(t, result.Rows, 2)
+	require.Equal(t, "invalid identifier", result.Rows[1].Error)
+
+	err = client.logout()
+	require.NoError(t, err)
+
+	require.Equal(t, []string{
+		"POST /api/sessions",
+		"POST /api/hosts/import",
+		"DELETE /api/sessions",
+	}, requests)
+}
+
+// Test that the client returns the error message sent by the server.
+func TestHostsImportClientError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(&models.APIError{
+			Message: storkutil.Ptr("Cannot authenticate user"),
+		})
+	}))
+	defer server.Close()
+
+	client, err := newHostsImportClient(server.URL)
+	require.NoError(t, err)
+
+	err = client.login("admin", "wrong")
+	require.ErrorContains(t, err, "server returned status 400: Cannot authenticate user")
+}
@@ -450,6 +450,100 @@ func setupApp() *cli.App {
 			EnvVars: []string{"STORK_TOOL_CERT_FILE"},
 		})
 
+	var hostsExportFlags []cli.Flag
+	hostsExportFlags = append(hostsExportFlags, dbFlags...)
+	hostsExportFlags = append(hostsExportFlags,
+		&cli.StringFlag{
+			Name:    "file",
+			Usage:   "The file location where the host reservations should be saved; if not provided, then they are printed to stdout",
+			Aliases: []string{"o"},
+			EnvVars: []string{"STORK_TOOL_HOSTS_FILE"},
+		},
+		&cli.StringFlag{
+			Name:    "format",
+			Usage:   "The file format; it can be one of 'csv', 'json'; if not provided, then it is inferred from the file extension",
+			Aliases: []string{"f"},
+			EnvVars: []string{"STORK_TOOL_HOSTS_FORMAT"},
+		})
+
+	hostsImportFlags := []cli.Flag{
+		&cli.StringFlag{
+			Name:     "file",
+			Usage:    "The file location from which the host reservations will be read",
+			Required: true,
+			Aliases:  []string{"i"},
+			EnvVars:  []string{"STORK_TOOL_HOSTS_FILE"},
+		},
+		&cli.StringFlag{
+			Name:    "format",
+			Usage:   "The file format; it can be one of 'csv', 'json'; if not provided, then it is inferred from the file extension",
+			Aliases: []string{"f"},
+			EnvVars: []string{"STORK_TOOL_HOSTS_FORMAT"},
+		},
+		&cli.StringFlag{
+			Name:    "server-url",
+			Usage:   "The URL of the Stork Server",
+			Value:   "http://localhost:8080",
+			Aliases: []string{"s"},
+			EnvVars: []string{"STORK_TOOL_SERVER_URL"},
+		},
+		&cli.StringFlag{
+			Name:     "user",
+			Usage:    "The login of the Stork Server user importing the host reservations",
+			Required: true,
+			Aliases:  []string{"u"},
+			EnvVars:  []string{"STORK_TOOL_SERVER_USER"},
+		},
+		&cli.StringFlag{
+			Name:    "password",
+			Usage:   "The password of the Stork Server user; if not provided, then the tool prompts for it",
+			Aliases: []string{"p"},
+			EnvVars: []string{"STORK_TOOL_SERVER_PASSWORD"},
+		},
+		&cli.Int64SliceFlag{
+			Name:    "daemon-id",
+			Usage:   "The ID of the daemon in which the global host reservations (lacking the subnet) are created; it can be specified multiple times",
+			Aliases: []string{"d"},
+			EnvVars: []string{"STORK_TOOL_HOSTS_DAEMON_ID"},
+		},
+		&cli.Int64Flag{
+			Name:    "batch-size",
+			Usage:   "The number of host reservations validated and applied in a single batch; if not provided, then the server default is used",
+			Aliases: []string{"b"},
+			EnvVars: []string{"STORK_TOOL_HOSTS_BATCH_SIZE"},
+		},
+	}
+
+	configReviewFlags := []cli.Flag{
+		&cli.StringSliceFlag{
+			Name:     "config",
+			Usage:    "The Kea configuration file location; it can be specified multiple times, e.g., to review the configurations of the HA partners",
+			Required: true,
+			Aliases:  []string{"c"},
+			EnvVars:  []string{"STORK_TOOL_CONFIG_REVIEW_CONFIG"},
+		},
+		&cli.StringFlag{
+			Name:    "format",
+			Usage:   "The output format; it can be one of 'text', 'json', 'junit'",
+			Value:   "text",
+			Aliases: []string{"f"},
+			EnvVars: []string{"STORK_TOOL_CONFIG_REVIEW_FORMAT"},
+		},
+		&cli.StringFlag{
+			Name:    "severity",
+			Usage:   "The lowest severity of the found issues causing a non-zero exit code; it can be one of 'info', 'warning', 'error'",
+			Value:   "warning",
+			Aliases: []string{"s"},
+			EnvVars: []string{"STORK_TOOL_CONFIG_REVIEW_SEVERITY"},
+		},
+		&cli.StringFlag{
+			Name:    "kea-version",
+			Usage:   "The version of the Kea servers using the configurations; if not provided, then the latest version is assumed",
+			Aliases: []string{"k"},
+			EnvVars: []string{"STORK_TOOL_CONFIG_REVIEW_KEA_VERSION"},
+		},
+	}
+
 	hookInspectFlags := []cli.Flag{
 		&cli.StringFlag{
 			Name:     "path",
@@ -500,7 +594,7 @@ func setupApp() *cli.App {
 	app := &cli.App{
 		Name:  "Stork Tool",
 		Usage: "A tool for managing Stork Server.",
-		Description: `The tool operates in four areas:
+		Description: `The tool operates in six areas:
 
    - Certificate Management - it allows for exporting Stork Server keys, certificates,
      and tokens that are used to secure communication between the Stork Server
@@ -512,6 +606,12 @@ func setupApp() *cli.App {
    - Database Migration - it allows for performing database schema migrations,
      overwriting the db schema version and getting its current value;
 
+   - Configuration Review - it allows for reviewing Kea configuration files
+     without the database, e.g., before deploying them;
+
+   - Host Reservations - it allows for exporting host reservations from the
+     database and importing them in bulk via the Stork Server;
+
    - Static Views Deployment - it allows for setting custom content in selected
      Stork views (e.g., custom welcome message on the login page).`,
 		Version:  stork.Version,
@@ -642,6 +742,35 @@ func setupApp() *cli.App {
 				Category:    "Certificates Management",
 				Action:      runCertImport,
 			},
+			// HOST RESERVATIONS
+			{
+				Name:        "hosts-export",
+				Usage:       "Export host reservations to a CSV or JSON file",
+				UsageText:   "stork-tool hosts-export [options for db connection] [-o filename] [-f format]",
+				Description: ``,
+				Flags:       hostsExportFlags,
+				Category:    "Host Reservations",
+				Action:      runHostsExport,
+			},
+			{
+				Name:        "hosts-import",
+				Usage:       "Import host reservations from a CSV or JSON file via the Stork Server",
+				UsageText:   "stork-tool hosts-import -i filename [-f format] [-s url] -u user [-p password] [-d daemon-id] [-b batch-size]",
+				Description: ``,
+				Flags:       hostsImportFlags,
+				Category:    "Host Reservations",
+				Action:      runHostsImport,
+			},
+			// CONFIGURATION REVIEW
+			{
+				Name:        "config-review",
+				Usage:       "Review Kea configuration files without the database",
+				UsageText:   "stork-tool config-review -c filename [-c filename ...] [-f format] [-s severity] [-k version]",
+				Description: ``,
+				Flags:       configReviewFlags,
+				Category:    "Configuration Review",
+				Action:      runConfigReview,
+			},
 			{
 				Name:        "hook-inspect",
 				Usage:       "Prints details about hooks",
@@ -48,6 +48,9 @@ func getExpectedMainFragments() []string {
 		"db-reset",
 		"db-version",
 		"db-set-version",
+		"hosts-export",
+		"hosts-import",
+		"config-review",
 	}
 }
 
@@ -0,0 +1,81 @@
+package configreviewcallouts
+
+import "context"
+
+// Selects the daemons which configurations are reviewed by a checker.
+// The values correspond to the dispatch group selectors of the
+// configuration review dispatcher in the server.
+type DaemonSelector int
+
+// List of the supported daemon selectors.
+const (
+	EachDaemon DaemonSelector = iota
+	KeaDaemon
+	KeaCADaemon
+	KeaDHCPDaemon
+	KeaDHCPv4Daemon
+	KeaDHCPv6Daemon
+	KeaD2Daemon
+	Bind9Daemon
+)
+
+// Type of the event triggering a configuration review. The values
+// correspond to the triggers of the configuration review dispatcher in
+// the server.
+type Trigger string
+
+// List of the supported triggers.
+const (
+	// Config review is triggered manually by a user over REST API.
+	ManualRun Trigger = "manual"
+	// Config review is triggered as a result of the configuration change.
+	ConfigModified Trigger = "config change"
+	// Config review is triggered as a result of the hosts modifications in
+	// the host database.
+	DBHostsModified Trigger = "host reservations change"
+	// Config review is triggered as a result of the configuration change of
+	// the Stork agent.
+	StorkAgentConfigModified Trigger = "Stork agent config change"
+)
+
+// The daemon which configuration is reviewed. It's a data transfer object
+// (DTO) to avoid using heavy dbmodel dependencies.
+type Daemon struct {
+	ID      int64
+	Name    string
+	Version string
+	AppID   int64
+	// The daemon configuration. It is the Kea configuration in the JSON
+	// format or the BIND 9 configuration with the secrets obscured. It is
+	// empty if the configuration hasn't been fetched yet.
+	Config string
+}
+
+// The configuration review report describing the found issue. The content
+// may contain the {daemon} placeholder which is replaced with the link to
+// the reviewed daemon when the report is displayed.
+type Report struct {
+	Content string
+}
+
+// The configuration checker provided by a hook.
+type Checker interface {
+	// Returns a unique name of the checker. It must not collide with the
+	// names of the checkers built into the server.
+	GetName() string
+	// Returns the selector of the daemons reviewed by the checker.
+	GetSelector() DaemonSelector
+	// Returns the triggers for which the checker is run.
+	GetTriggers() []Trigger
+	// Reviews the daemon configuration. Returns nil report if no issues
+	// were found.
+	Check(ctx context.Context, daemon *Daemon) (*Report, error)
+}
+
+// Set of callouts used to extend the configuration review with the
+// site-specific checkers.
+type ConfigReviewCallouts interface {
+	// Returns the configuration checkers provided by the hook. It is
+	// called once on the server startup.
+	GetCheckers() []Checker
+}
@@ -9,6 +9,7 @@ import (
 	"strconv"
 	"sync"
 
+	"github.com/miekg/dns"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 	"google.golang.org/grpc"
@@ -39,6 +40,9 @@ type ConnectedAgents interface {
 	ForwardToKeaOverHTTP(ctx context.Context, app ControlledApp, commands []keactrl.SerializableCommand, cmdResponses ...interface{}) (*KeaCmdsResult, error)
 	TailTextFile(ctx context.Context, machine dbmodel.MachineTag, path string, offset int64) ([]string, error)
 	ReceiveZones(ctx context.Context, app ControlledApp, filter *bind9stats.ZoneFilter) iter.Seq2[*bind9stats.ExtendedZone, error]
+	ReceiveZoneRRs(ctx context.Context, app ControlledApp, zoneName, viewName string) iter.Seq2[[]dns.RR, error]
+	UpdateZone(ctx context.Context, app ControlledApp, zoneName, viewName string, deleteRRs, addRRs []dns.RR) error
+	QueryZoneRRs(ctx context.Context, app ControlledApp, zoneName, viewName string, rrTypes []uint16) ([]dns.RR, error)
 }
 
 // Interface representing a connector to a selected agent over gRPC.
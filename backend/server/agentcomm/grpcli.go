@@ -10,6 +10,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/miekg/dns"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 	"google.golang.org/genproto/googleapis/rpc/errdetails"
@@ -46,6 +47,19 @@ const (
 type App struct {
 	Type         string
 	AccessPoints []AccessPoint
+	ConfigFiles  []DaemonConfigFile
+	// Preprocessed BIND 9 configuration with the key secrets obscured.
+	// It is empty for other apps.
+	Bind9Config string
+}
+
+// The configuration file of a daemon belonging to the application detected
+// by an agent. The hash is computed from the file contents and it can be
+// compared with the hash of the daemon's running configuration.
+type DaemonConfigFile struct {
+	Daemon string
+	Path   string
+	Hash   string
 }
 
 // Currently supported types are: "kea" and "bind9".
@@ -365,9 +379,20 @@ func (agents *connectedAgentsImpl) GetState(ctx context.Context, machine dbmodel
 			})
 		}
 
+		var configFiles []DaemonConfigFile
+		for _, file := range app.ConfigFiles {
+			configFiles = append(configFiles, DaemonConfigFile{
+				Daemon: file.Daemon,
+				Path:   file.Path,
+				Hash:   file.Hash,
+			})
+		}
+
 		apps = append(apps, &App{
 			Type:         app.Type,
 			AccessPoints: accessPoints,
+			ConfigFiles:  configFiles,
+			Bind9Config:  app.Bind9Config,
 		})
 	}
 
@@ -1011,3 +1036,241 @@ func (agents *connectedAgentsImpl) ReceiveZones(ctx context.Context, app Control
 		}
 	}
 }
+
+// Receive resource records of a DNS zone over the stream from a selected
+// agent. The agent transfers the zone (AXFR) from the DNS server and sends
+// the records in chunks as they are received. It returns an iterator over
+// the chunks of the records. The iterator ends when an error occurs.
+func (agents *connectedAgentsImpl) ReceiveZoneRRs(ctx context.Context, app ControlledApp, zoneName, viewName string) iter.Seq2[[]dns.RR, error] {
+	return func(yield func([]dns.RR, error) bool) {
+		// Get control access point for the specified app. It will be sent
+		// in the request to the agent, so the agent can identify the DNS
+		// server.
+		ctrlAddress, ctrlPort, _, _, err := app.GetControlAccessPoint()
+		if err != nil {
+			_ = yield(nil, err)
+			return
+		}
+		// Get the agent's state. It holds the connection with the agent.
+		agentAddressPort := net.JoinHostPort(app.GetMachineTag().GetAddress(), strconv.FormatInt(app.GetMachineTag().GetAgentPort(), 10))
+		agent, err := agents.getConnectedAgent(agentAddressPort)
+		if err != nil {
+			_ = yield(nil, err)
+			return
+		}
+		request := &agentapi.ReceiveZoneRRsReq{
+			ControlAddress: ctrlAddress,
+			ControlPort:    ctrlPort,
+			ZoneName:       zoneName,
+			ViewName:       viewName,
+		}
+		// Retry with a new connection if the cached one is broken. See
+		// ReceiveZones for details.
+		var stream grpc.ServerStreamingClient[agentapi.ReceiveZoneRRsRsp]
+		if stream, err = agent.connector.createClient().ReceiveZoneRRs(ctx, request); err != nil {
+			if err = agent.connector.connect(); err == nil {
+				stream, err = agent.connector.createClient().ReceiveZoneRRs(ctx, request)
+			}
+		}
+		if err != nil {
+			_ = yield(nil, errors.Wrapf(err, "failed to transfer zone %s from the agent %s", zoneName, agentAddressPort))
+			return
+		}
+		for {
+			response, err := stream.Recv()
+			if err != nil {
+				if !errors.Is(err, io.EOF) {
+					_ = yield(nil, errors.Wrapf(err, "failed to transfer zone %s from the agent %s", zoneName, agentAddressPort))
+				}
+				return
+			}
+			rrs := make([]dns.RR, 0, len(response.GetRrs()))
+			for _, text := range response.GetRrs() {
+				rr, err := dns.NewRR(text)
+				if err != nil {
+					_ = yield(nil, errors.Wrapf(err, "failed to parse resource record %s of zone %s received from the agent %s", text, zoneName, agentAddressPort))
+					return
+				}
+				rrs = append(rrs, rr)
+			}
+			if !yield(rrs, nil) {
+				// Stop if the caller no longer iterates over the records.
+				return
+			}
+		}
+	}
+}
+
+// Sends a dynamic update (RFC 2136) of a DNS zone to a selected DNS server
+// via the agent. The specified resource records are deleted and then the new
+// records are added in a single update, so the DNS server applies all of the
+// changes or none of them. The agent signs the update with a key found in the
+// DNS server's configuration. It returns an error when the communication with
+// the agent fails or the DNS server rejects the update.
+func (agents *connectedAgentsImpl) UpdateZone(ctx context.Context, app ControlledApp, zoneName, viewName string, deleteRRs, addRRs []dns.RR) error {
+	agentAddress := app.GetMachineTag().GetAddress()
+	agentPort := app.GetMachineTag().GetAgentPort()
+
+	// Get control access point for the specified app. It will be sent
+	// in the request to the agent, so the agent can identify the DNS
+	// server.
+	ctrlAddress, ctrlPort, _, _, err := app.GetControlAccessPoint()
+	if err != nil {
+		return err
+	}
+
+	addrPort := net.JoinHostPort(agentAddress, strconv.FormatInt(agentPort, 10))
+
+	req := &agentapi.UpdateZoneReq{
+		ControlAddress: ctrlAddress,
+		ControlPort:    ctrlPort,
+		ZoneName:       zoneName,
+		ViewName:       viewName,
+	}
+	for _, rr := range deleteRRs {
+		req.DeleteRRs = append(req.DeleteRRs, rr.String())
+	}
+	for _, rr := range addRRs {
+		req.AddRRs = append(req.AddRRs, rr.String())
+	}
+
+	// Send the request via queue.
+	agentResponse, err := agents.sendAndRecvViaQueue(addrPort, req)
+
+	stats := agents.getConnectedAgentStats(agentAddress, agentPort)
+	if stats == nil {
+		return errors.Errorf("failed to get statistics for the non-existing agent %s", addrPort)
+	}
+
+	stats.mutex.Lock()
+	defer stats.mutex.Unlock()
+
+	// Check connectivity with the Stork agent by examining the returned error.
+	commIssue, details := agents.checkAgentCommState(stats, req, err)
+	switch commIssue {
+	case CommErrorNew:
+		log.WithFields(log.Fields{
+			"agent": addrPort,
+			"zone":  zoneName,
+		}).Warn("Failed to update the zone via the Stork agent")
+		agents.eventCenter.AddErrorEvent("communication with Stork agent on {machine} to update the zone failed", app.GetMachineTag(), dbmodel.SSEConnectivity, details)
+
+	case CommErrorReset:
+		agents.eventCenter.AddWarningEvent("communication with Stork agent on {machine} to update the zone succeeded", app.GetMachineTag(), dbmodel.SSEConnectivity, details)
+
+	case CommErrorContinued:
+		log.WithFields(log.Fields{
+			"agent": addrPort,
+			"zone":  zoneName,
+		}).Warn("Failed to update the zone via the Stork agent; the agent is still not responding")
+	default:
+		// Communication with the agent was ok and is still ok.
+	}
+
+	// If there was an error in communication with the agent, there is no need
+	// to check the response because it is probably nil anyway. Return an error.
+	if err != nil {
+		return errors.Wrapf(err, "failed to update zone %s via the agent %s", zoneName, addrPort)
+	}
+
+	response, ok := agentResponse.(*agentapi.UpdateZoneRsp)
+	if !ok || response == nil {
+		return errors.Errorf("wrong response to updating the zone from the Stork agent %s", addrPort)
+	}
+
+	// Check the status code.
+	if response.Status.Code != agentapi.Status_OK {
+		return errors.New(response.Status.Message)
+	}
+
+	return nil
+}
+
+// Queries the resource records of the specified types at the apex of a DNS
+// zone on a selected DNS server via the agent. It is used to get the SOA
+// and the DNSSEC records of the zone without transferring the whole zone.
+// It returns an error when the communication with the agent fails or the
+// DNS server responds with an error.
+func (agents *connectedAgentsImpl) QueryZoneRRs(ctx context.Context, app ControlledApp, zoneName, viewName string, rrTypes []uint16) ([]dns.RR, error) {
+	agentAddress := app.GetMachineTag().GetAddress()
+	agentPort := app.GetMachineTag().GetAgentPort()
+
+	// Get control access point for the specified app. It will be sent
+	// in the request to the agent, so the agent can identify the DNS
+	// server.
+	ctrlAddress, ctrlPort, _, _, err := app.GetControlAccessPoint()
+	if err != nil {
+		return nil, err
+	}
+
+	addrPort := net.JoinHostPort(agentAddress, strconv.FormatInt(agentPort, 10))
+
+	req := &agentapi.QueryZoneRRsReq{
+		ControlAddress: ctrlAddress,
+		ControlPort:    ctrlPort,
+		ZoneName:       zoneName,
+		ViewName:       viewName,
+	}
+	for _, rrType := range rrTypes {
+		req.RrTypes = append(req.RrTypes, dns.TypeToString[rrType])
+	}
+
+	// Send the request via queue.
+	agentResponse, err := agents.sendAndRecvViaQueue(addrPort, req)
+
+	stats := agents.getConnectedAgentStats(agentAddress, agentPort)
+	if stats == nil {
+		return nil, errors.Errorf("failed to get statistics for the non-existing agent %s", addrPort)
+	}
+
+	stats.mutex.Lock()
+	defer stats.mutex.Unlock()
+
+	// Check connectivity with the Stork agent by examining the returned error.
+	commIssue, details := agents.checkAgentCommState(stats, req, err)
+	switch commIssue {
+	case CommErrorNew:
+		log.WithFields(log.Fields{
+			"agent": addrPort,
+			"zone":  zoneName,
+		}).Warn("Failed to query the zone via the Stork agent")
+		agents.eventCenter.AddErrorEvent("communication with Stork agent on {machine} to query the zone failed", app.GetMachineTag(), dbmodel.SSEConnectivity, details)
+
+	case CommErrorReset:
+		agents.eventCenter.AddWarningEvent("communication with Stork agent on {machine} to query the zone succeeded", app.GetMachineTag(), dbmodel.SSEConnectivity, details)
+
+	case CommErrorContinued:
+		log.WithFields(log.Fields{
+			"agent": addrPort,
+			"zone":  zoneName,
+		}).Warn("Failed to query the zone via the Stork agent; the agent is still not responding")
+	default:
+		// Communication with the agent was ok and is still ok.
+	}
+
+	// If there was an error in communication with the agent, there is no need
+	// to check the response because it is probably nil anyway. Return an error.
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to query zone %s via the agent %s", zoneName, addrPort)
+	}
+
+	response, ok := agentResponse.(*agentapi.QueryZoneRRsRsp)
+	if !ok || response == nil {
+		return nil, errors.Errorf("wrong response to querying the zone from the Stork agent %s", addrPort)
+	}
+
+	// Check the status code.
+	if response.Status.Code != agentapi.Status_OK {
+		return nil, errors.New(response.Status.Message)
+	}
+
+	rrs := make([]dns.RR, 0, len(response.Rrs))
+	for _, text := range response.Rrs {
+		rr, err := dns.NewRR(text)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to parse resource record %s of zone %s received from the agent %s", text, zoneName, addrPort)
+		}
+		rrs = append(rrs, rr)
+	}
+	return rrs, nil
+}
@@ -6,6 +6,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/miekg/dns"
 	pkgerrors "github.com/pkg/errors"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
@@ -160,6 +161,11 @@ func TestGetState(t *testing.T) {
 				Type:         AppTypeKea,
 				AccessPoints: makeAccessPoint(AccessPointControl, "1.2.3.4", "", 1234),
 			},
+			{
+				Type:         AppTypeBind9,
+				AccessPoints: makeAccessPoint(AccessPointControl, "1.2.3.4", "", 953),
+				Bind9Config:  "options { recursion no; };",
+			},
 		},
 	}
 	mockAgentClient.EXPECT().
@@ -174,7 +180,11 @@ func TestGetState(t *testing.T) {
 	})
 	require.NoError(t, err)
 	require.Equal(t, expVer, state.AgentVersion)
+	require.Len(t, state.Apps, 2)
 	require.Equal(t, AppTypeKea, state.Apps[0].Type)
+	require.Empty(t, state.Apps[0].Bind9Config)
+	require.Equal(t, AppTypeBind9, state.Apps[1].Type)
+	require.Equal(t, "options { recursion no; };", state.Apps[1].Bind9Config)
 }
 
 // Test error case for GetState.
@@ -1028,3 +1038,350 @@ func TestReceiveZones(t *testing.T) {
 		require.EqualValues(t, 100, zone.TotalZoneCount)
 	}
 }
+
+// Test receiving the resource records of a zone transferred by the agent.
+func TestReceiveZoneRRs(t *testing.T) {
+	// Create an app.
+	app := &dbmodel.App{
+		Machine: &dbmodel.Machine{
+			Address:   "127.0.0.1",
+			AgentPort: 8080,
+		},
+		AccessPoints: []*dbmodel.AccessPoint{{
+			Type:    dbmodel.AccessPointControl,
+			Address: "localhost",
+			Port:    8000,
+			Key:     "",
+		}},
+	}
+
+	ctrl := gomock.NewController(t)
+	mockAgentClient, agents := setupGrpcliTestCase(ctrl)
+	defer ctrl.Finish()
+
+	// The mock returns the records in two chunks.
+	mockStreamingClient := NewMockServerStreamingClient[agentapi.ReceiveZoneRRsRsp](ctrl)
+	gomock.InOrder(
+		mockStreamingClient.EXPECT().Recv().Return(&agentapi.ReceiveZoneRRsRsp{
+			Rrs: []string{
+				"example.com.\t3600\tIN\tSOA\tns1.example.com. admin.example.com. 2024031501 43200 3600 1209600 3600",
+				"example.com.\t3600\tIN\tNS\tns1.example.com.",
+			},
+		}, nil),
+		mockStreamingClient.EXPECT().Recv().Return(&agentapi.ReceiveZoneRRsRsp{
+			Rrs: []string{
+				"ns1.example.com.\t3600\tIN\tA\t192.0.2.1",
+			},
+		}, nil),
+		mockStreamingClient.EXPECT().Recv().Return(nil, io.EOF),
+	)
+
+	// Make sure the request contains the zone and view.
+	mockAgentClient.EXPECT().ReceiveZoneRRs(gomock.Any(), gomock.Cond(func(r any) bool {
+		request := r.(*agentapi.ReceiveZoneRRsReq)
+		return request.ControlAddress == "localhost" && request.ControlPort == 8000 &&
+			request.ZoneName == "example.com" && request.ViewName == "_default"
+	})).Return(mockStreamingClient, nil)
+
+	var rrs []dns.RR
+	for chunk, err := range agents.ReceiveZoneRRs(context.Background(), app, "example.com", "_default") {
+		require.NoError(t, err)
+		rrs = append(rrs, chunk...)
+	}
+	require.Len(t, rrs, 3)
+	require.Equal(t, dns.TypeSOA, rrs[0].Header().Rrtype)
+	require.Equal(t, dns.TypeNS, rrs[1].Header().Rrtype)
+	require.Equal(t, dns.TypeA, rrs[2].Header().Rrtype)
+	require.Equal(t, "ns1.example.com.", rrs[2].Header().Name)
+}
+
+// Test that an error is returned when the agent fails to transfer the zone.
+func TestReceiveZoneRRsStreamError(t *testing.T) {
+	// Create an app.
+	app := &dbmodel.App{
+		Machine: &dbmodel.Machine{
+			Address:   "127.0.0.1",
+			AgentPort: 8080,
+		},
+		AccessPoints: []*dbmodel.AccessPoint{{
+			Type:    dbmodel.AccessPointControl,
+			Address: "localhost",
+			Port:    8000,
+			Key:     "",
+		}},
+	}
+
+	ctrl := gomock.NewController(t)
+	mockAgentClient, agents := setupGrpcliTestCase(ctrl)
+	defer ctrl.Finish()
+
+	mockStreamingClient := NewMockServerStreamingClient[agentapi.ReceiveZoneRRsRsp](ctrl)
+	mockStreamingClient.EXPECT().Recv().Return(nil, &testError{})
+	mockAgentClient.EXPECT().ReceiveZoneRRs(gomock.Any(), gomock.Any()).Return(mockStreamingClient, nil)
+
+	var transferErr error
+	for chunk, err := range agents.ReceiveZoneRRs(context.Background(), app, "example.com", "_default") {
+		require.Nil(t, chunk)
+		transferErr = err
+	}
+	require.ErrorContains(t, transferErr, "failed to transfer zone example.com")
+	require.ErrorContains(t, transferErr, "test error")
+}
+
+// Test that an error is returned when the agent returns an invalid record.
+func TestReceiveZoneRRsParseError(t *testing.T) {
+	// Create an app.
+	app := &dbmodel.App{
+		Machine: &dbmodel.Machine{
+			Address:   "127.0.0.1",
+			AgentPort: 8080,
+		},
+		AccessPoints: []*dbmodel.AccessPoint{{
+			Type:    dbmodel.AccessPointControl,
+			Address: "localhost",
+			Port:    8000,
+			Key:     "",
+		}},
+	}
+
+	ctrl := gomock.NewController(t)
+	mockAgentClient, agents := setupGrpcliTestCase(ctrl)
+	defer ctrl.Finish()
+
+	mockStreamingClient := NewMockServerStreamingClient[agentapi.ReceiveZoneRRsRsp](ctrl)
+	mockStreamingClient.EXPECT().Recv().Return(&agentapi.ReceiveZoneRRsRsp{
+		Rrs: []string{"example.com. IN FOO bar"},
+	}, nil)
+	mockAgentClient.EXPECT().ReceiveZoneRRs(gomock.Any(), gomock.Any()).Return(mockStreamingClient, nil)
+
+	var transferErr error
+	for _, err := range agents.ReceiveZoneRRs(context.Background(), app, "example.com", "_default") {
+		transferErr = err
+	}
+	require.ErrorContains(t, transferErr, "failed to parse resource record example.com. IN FOO bar")
+}
+
+// Test sending the zone update via the agent.
+func TestUpdateZone(t *testing.T) {
+	app := &dbmodel.App{
+		Machine: &dbmodel.Machine{
+			Address:   "127.0.0.1",
+			AgentPort: 8080,
+		},
+		AccessPoints: []*dbmodel.AccessPoint{{
+			Type:    dbmodel.AccessPointControl,
+			Address: "localhost",
+			Port:    8000,
+			Key:     "",
+		}},
+	}
+
+	ctrl := gomock.NewController(t)
+	mockAgentClient, agents := setupGrpcliTestCase(ctrl)
+	defer ctrl.Finish()
+
+	// Make sure the request contains the zone, view and the records.
+	mockAgentClient.EXPECT().UpdateZone(gomock.Any(), gomock.Cond(func(r any) bool {
+		request := r.(*agentapi.UpdateZoneReq)
+		return request.ControlAddress == "localhost" && request.ControlPort == 8000 &&
+			request.ZoneName == "example.com" && request.ViewName == "_default" &&
+			len(request.DeleteRRs) == 1 && request.DeleteRRs[0] == "www.example.com.\t300\tIN\tA\t192.0.2.1" &&
+			len(request.AddRRs) == 1 && request.AddRRs[0] == "www.example.com.\t300\tIN\tA\t192.0.2.2"
+	})).Return(&agentapi.UpdateZoneRsp{
+		Status: &agentapi.Status{
+			Code: agentapi.Status_OK,
+		},
+	}, nil)
+
+	deleteRR, err := dns.NewRR("www.example.com. 300 IN A 192.0.2.1")
+	require.NoError(t, err)
+	addRR, err := dns.NewRR("www.example.com. 300 IN A 192.0.2.2")
+	require.NoError(t, err)
+
+	err = agents.UpdateZone(context.Background(), app, "example.com", "_default", []dns.RR{deleteRR}, []dns.RR{addRR})
+	require.NoError(t, err)
+}
+
+// Test that an error is returned when the DNS server rejects the update.
+func TestUpdateZoneRejected(t *testing.T) {
+	app := &dbmodel.App{
+		Machine: &dbmodel.Machine{
+			Address:   "127.0.0.1",
+			AgentPort: 8080,
+		},
+		AccessPoints: []*dbmodel.AccessPoint{{
+			Type:    dbmodel.AccessPointControl,
+			Address: "localhost",
+			Port:    8000,
+			Key:     "",
+		}},
+	}
+
+	ctrl := gomock.NewController(t)
+	mockAgentClient, agents := setupGrpcliTestCase(ctrl)
+	defer ctrl.Finish()
+
+	mockAgentClient.EXPECT().UpdateZone(gomock.Any(), gomock.Any()).Return(&agentapi.UpdateZoneRsp{
+		Status: &agentapi.Status{
+			Code:    agentapi.Status_ERROR,
+			Message: "DNS server rejected the update of zone example.com: REFUSED",
+		},
+	}, nil)
+
+	err := agents.UpdateZone(context.Background(), app, "example.com", "_default", nil, nil)
+	require.ErrorContains(t, err, "REFUSED")
+
+	// The rejection is not a communication error.
+	agent, err := agents.getConnectedAgent("127.0.0.1:8080")
+	require.NoError(t, err)
+	require.Zero(t, agent.stats.GetTotalErrorCount())
+}
+
+// Test that an error is returned when the communication with the agent
+// fails.
+func TestUpdateZoneError(t *testing.T) {
+	app := &dbmodel.App{
+		Machine: &dbmodel.Machine{
+			Address:   "127.0.0.1",
+			AgentPort: 8080,
+		},
+		AccessPoints: []*dbmodel.AccessPoint{{
+			Type:    dbmodel.AccessPointControl,
+			Address: "localhost",
+			Port:    8000,
+			Key:     "",
+		}},
+	}
+
+	ctrl := gomock.NewController(t)
+	mockAgentClient, agents := setupGrpcliTestCase(ctrl)
+	defer ctrl.Finish()
+
+	mockAgentClient.EXPECT().UpdateZone(gomock.Any(), gomock.Any()).AnyTimes().
+		Return(nil, pkgerrors.New("update error"))
+
+	err := agents.UpdateZone(context.Background(), app, "example.com", "_default", nil, nil)
+	require.ErrorContains(t, err, "failed to update zone example.com")
+
+	agent, err := agents.getConnectedAgent("127.0.0.1:8080")
+	require.NoError(t, err)
+	require.EqualValues(t, 1, agent.stats.GetTotalErrorCount())
+}
+
+// Test querying the zone records via the agent.
+func TestQueryZoneRRs(t *testing.T) {
+	app := &dbmodel.App{
+		Machine: &dbmodel.Machine{
+			Address:   "127.0.0.1",
+			AgentPort: 8080,
+		},
+		AccessPoints: []*dbmodel.AccessPoint{{
+			Type:    dbmodel.AccessPointControl,
+			Address: "localhost",
+			Port:    8000,
+			Key:     "",
+		}},
+	}
+
+	ctrl := gomock.NewController(t)
+	mockAgentClient, agents := setupGrpcliTestCase(ctrl)
+	defer ctrl.Finish()
+
+	// Make sure the request contains the zone, view and the record types.
+	mockAgentClient.EXPECT().QueryZoneRRs(gomock.Any(), gomock.Cond(func(r any) bool {
+		request := r.(*agentapi.QueryZoneRRsReq)
+		return request.ControlAddress == "localhost" && request.ControlPort == 8000 &&
+			request.ZoneName == "example.com" && request.ViewName == "_default" &&
+			len(request.RrTypes) == 2 && request.RrTypes[0] == "SOA" && request.RrTypes[1] == "DNSKEY"
+	})).Return(&agentapi.QueryZoneRRsRsp{
+		Status: &agentapi.Status{
+			Code: agentapi.Status_OK,
+		},
+		Rrs: []string{
+			"example.com. 3600 IN SOA ns1.example.com. admin.example.com. 2024031501 43200 3600 1209600 3600",
+		},
+	}, nil)
+
+	rrs, err := agents.QueryZoneRRs(context.Background(), app, "example.com", "_default", []uint16{dns.TypeSOA, dns.TypeDNSKEY})
+	require.NoError(t, err)
+	require.Len(t, rrs, 1)
+	require.EqualValues(t, 2024031501, rrs[0].(*dns.SOA).Serial)
+}
+
+// Test that an error is returned when the DNS server responds with an
+// error or the returned records are invalid.
+func TestQueryZoneRRsRejected(t *testing.T) {
+	app := &dbmodel.App{
+		Machine: &dbmodel.Machine{
+			Address:   "127.0.0.1",
+			AgentPort: 8080,
+		},
+		AccessPoints: []*dbmodel.AccessPoint{{
+			Type:    dbmodel.AccessPointControl,
+			Address: "localhost",
+			Port:    8000,
+			Key:     "",
+		}},
+	}
+
+	ctrl := gomock.NewController(t)
+	mockAgentClient, agents := setupGrpcliTestCase(ctrl)
+	defer ctrl.Finish()
+
+	gomock.InOrder(
+		mockAgentClient.EXPECT().QueryZoneRRs(gomock.Any(), gomock.Any()).Return(&agentapi.QueryZoneRRsRsp{
+			Status: &agentapi.Status{
+				Code:    agentapi.Status_ERROR,
+				Message: "DNS server rejected the query for SOA records of zone example.com: REFUSED",
+			},
+		}, nil),
+		mockAgentClient.EXPECT().QueryZoneRRs(gomock.Any(), gomock.Any()).Return(&agentapi.QueryZoneRRsRsp{
+			Status: &agentapi.Status{
+				Code: agentapi.Status_OK,
+			},
+			Rrs: []string{"example.com. IN FOO bar"},
+		}, nil),
+	)
+
+	_, err := agents.QueryZoneRRs(context.Background(), app, "example.com", "_default", []uint16{dns.TypeSOA})
+	require.ErrorContains(t, err, "REFUSED")
+
+	_, err = agents.QueryZoneRRs(context.Background(), app, "example.com", "_default", []uint16{dns.TypeSOA})
+	require.ErrorContains(t, err, "failed to parse resource record example.com. IN FOO bar")
+
+	// The rejection is not a communication error.
+	agent, err := agents.getConnectedAgent("127.0.0.1:8080")
+	require.NoError(t, err)
+	require.Zero(t, agent.stats.GetTotalErrorCount())
+}
+
+// Test that an error is returned when the communication with the agent
+// fails.
+func TestQueryZoneRRsError(t *testing.T) {
+	app := &dbmodel.App{
+		Machine: &dbmodel.Machine{
+			Address:   "127.0.0.1",
+			AgentPort: 8080,
+		},
+		AccessPoints: []*dbmodel.AccessPoint{{
+			Type:    dbmodel.AccessPointControl,
+			Address: "localhost",
+			Port:    8000,
+			Key:     "",
+		}},
+	}
+
+	ctrl := gomock.NewController(t)
+	mockAgentClient, agents := setupGrpcliTestCase(ctrl)
+	defer ctrl.Finish()
+
+	mockAgentClient.EXPECT().QueryZoneRRs(gomock.Any(), gomock.Any()).AnyTimes().
+		Return(nil, pkgerrors.New("query error"))
+
+	_, err := agents.QueryZoneRRs(context.Background(), app, "example.com", "_default", []uint16{dns.TypeSOA})
+	require.ErrorContains(t, err, "failed to query zone example.com")
+
+	agent, err := agents.getConnectedAgent("127.0.0.1:8080")
+	require.NoError(t, err)
+	require.EqualValues(t, 1, agent.stats.GetTotalErrorCount())
+}
@@ -81,6 +81,10 @@ func doCall(ctx context.Context, agent *agentState, in interface{}) (interface{}
 		response, err = client.ForwardToKeaOverHTTP(ctx, inData, bigMessageOptions...)
 	case *agentapi.TailTextFileReq:
 		response, err = client.TailTextFile(ctx, inData, bigMessageOptions...)
+	case *agentapi.UpdateZoneReq:
+		response, err = client.UpdateZone(ctx, inData)
+	case *agentapi.QueryZoneRRsReq:
+		response, err = client.QueryZoneRRs(ctx, inData)
 	default:
 		err = errors.New("doCall: unsupported request type")
 	}
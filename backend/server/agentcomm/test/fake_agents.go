@@ -4,6 +4,7 @@ import (
 	"context"
 	"iter"
 
+	"github.com/miekg/dns"
 	keactrl "isc.org/stork/appctrl/kea"
 	"isc.org/stork/appdata/bind9stats"
 	"isc.org/stork/server/agentcomm"
@@ -189,3 +190,21 @@ func (fa *FakeAgents) TailTextFile(ctx context.Context, machine dbmodel.MachineT
 func (fa *FakeAgents) ReceiveZones(ctx context.Context, app agentcomm.ControlledApp, filter *bind9stats.ZoneFilter) iter.Seq2[*bind9stats.ExtendedZone, error] {
 	return nil
 }
+
+// FakeAgents specific implementation of the function which transfers the zone
+// from the agent.
+func (fa *FakeAgents) ReceiveZoneRRs(ctx context.Context, app agentcomm.ControlledApp, zoneName, viewName string) iter.Seq2[[]dns.RR, error] {
+	return nil
+}
+
+// FakeAgents specific implementation of the function which updates the zone
+// via the agent.
+func (fa *FakeAgents) UpdateZone(ctx context.Context, app agentcomm.ControlledApp, zoneName, viewName string, deleteRRs, addRRs []dns.RR) error {
+	return nil
+}
+
+// FakeAgents specific implementation of the function which queries the zone
+// via the agent.
+func (fa *FakeAgents) QueryZoneRRs(ctx context.Context, app agentcomm.ControlledApp, zoneName, viewName string, rrTypes []uint16) ([]dns.RR, error) {
+	return nil, nil
+}
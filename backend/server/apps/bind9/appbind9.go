@@ -12,6 +12,7 @@ import (
 	dbops "isc.org/stork/server/database"
 	dbmodel "isc.org/stork/server/database/model"
 	"isc.org/stork/server/eventcenter"
+	storkutil "isc.org/stork/util"
 )
 
 // Provide example date format how named returns dates.
@@ -181,6 +182,25 @@ func GetAppState(ctx context.Context, agents agentcomm.ConnectedAgents, dbApp *d
 	GetAppStatistics(ctx, agents, dbApp)
 }
 
+// Sets the configuration reported by the agent for the BIND 9 daemon of
+// the app. It returns true if the configuration has changed since it was
+// last set, indicating that the configuration should be reviewed. The
+// configuration is empty when it is reported by an older agent. It is
+// ignored in this case.
+func SetDaemonConfig(dbApp *dbmodel.App, config string) bool {
+	if len(config) == 0 || len(dbApp.Daemons) == 0 || dbApp.Daemons[0].Bind9Daemon == nil {
+		return false
+	}
+	bind9Daemon := dbApp.Daemons[0].Bind9Daemon
+	configHash := storkutil.Fnv128(config)
+	if configHash == bind9Daemon.ConfigHash {
+		return false
+	}
+	bind9Daemon.Config = config
+	bind9Daemon.ConfigHash = configHash
+	return true
+}
+
 // Inserts or updates information about BIND 9 app in the database.
 func CommitAppIntoDB(db *dbops.PgDB, app *dbmodel.App, eventCenter eventcenter.EventCenter) (err error) {
 	if app.ID == 0 {
@@ -158,3 +158,36 @@ func TestCommitAppIntoDB(t *testing.T) {
 	require.Len(t, returned.AccessPoints, 1)
 	require.EqualValues(t, 2345, returned.AccessPoints[0].Port)
 }
+
+// Test that the daemon configuration and its hash are set and the
+// configuration changes are detected.
+func TestSetDaemonConfig(t *testing.T) {
+	app := &dbmodel.App{
+		Type: dbmodel.AppTypeBind9,
+		Daemons: []*dbmodel.Daemon{
+			dbmodel.NewBind9Daemon(true),
+		},
+	}
+
+	// New configuration.
+	require.True(t, SetDaemonConfig(app, "options { recursion no; };"))
+	require.Equal(t, "options { recursion no; };", app.Daemons[0].Bind9Daemon.Config)
+	hash := app.Daemons[0].Bind9Daemon.ConfigHash
+	require.NotEmpty(t, hash)
+
+	// Same configuration.
+	require.False(t, SetDaemonConfig(app, "options { recursion no; };"))
+	require.Equal(t, hash, app.Daemons[0].Bind9Daemon.ConfigHash)
+
+	// Modified configuration.
+	require.True(t, SetDaemonConfig(app, "options { recursion yes; };"))
+	require.Equal(t, "options { recursion yes; };", app.Daemons[0].Bind9Daemon.Config)
+	require.NotEqual(t, hash, app.Daemons[0].Bind9Daemon.ConfigHash)
+
+	// Empty configuration is ignored.
+	require.False(t, SetDaemonConfig(app, ""))
+	require.Equal(t, "options { recursion yes; };", app.Daemons[0].Bind9Daemon.Config)
+
+	// No daemons.
+	require.False(t, SetDaemonConfig(&dbmodel.App{}, "options { recursion no; };"))
+}
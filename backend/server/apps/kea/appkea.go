@@ -50,6 +50,7 @@ type VersionGetResponse struct {
 type AppStateMeta struct {
 	Events            []*dbmodel.Event
 	SameConfigDaemons map[string]bool
+	RestartedDaemons  map[string]bool
 }
 
 // Convenience function called from getStateFromCA and getStateFromDaemons which searches
@@ -317,7 +318,7 @@ func GetAppState(ctx context.Context, agents agentcomm.ConnectedAgents, dbApp *d
 		return nil
 	}
 
-	newActive, overrideDaemons, newDaemons, events, sameConfigDaemons := findChangesAndRaiseEvents(dbApp, daemonsMap, daemonsErrors)
+	newActive, overrideDaemons, newDaemons, events, sameConfigDaemons, restartedDaemons := findChangesAndRaiseEvents(dbApp, daemonsMap, daemonsErrors)
 
 	// update app state
 	dbApp.Active = newActive
@@ -329,11 +330,75 @@ func GetAppState(ctx context.Context, agents agentcomm.ConnectedAgents, dbApp *d
 	state := &AppStateMeta{
 		Events:            events,
 		SameConfigDaemons: sameConfigDaemons,
+		RestartedDaemons:  restartedDaemons,
 	}
 
 	return state
 }
 
+// Sets the flags indicating that the daemons have unsaved configuration
+// changes. The running configuration returned by the config-get command
+// includes the default values, so its hash cannot be compared with the hash
+// of the configuration file reported by the agent. Instead, the hash of the
+// running configuration is remembered when the running configuration is
+// assumed to match the file, i.e., when the daemon is seen for the first
+// time, when the file changes (e.g., after the config-write command) and
+// when the daemon restarts. The daemon has unsaved configuration changes
+// when its running configuration differs from the remembered one. The flags
+// remain unchanged for the inactive daemons. If the flag changes, an event
+// is added to the app state. The state is nil for the new apps and no events
+// are raised for them.
+func DetectUnsavedConfigChanges(dbApp *dbmodel.App, configFiles []agentcomm.DaemonConfigFile, state *AppStateMeta) {
+	for _, daemon := range dbApp.Daemons {
+		if daemon.KeaDaemon == nil || !daemon.Active {
+			continue
+		}
+		var configFile *agentcomm.DaemonConfigFile
+		for i := range configFiles {
+			if configFiles[i].Daemon == daemon.Name {
+				configFile = &configFiles[i]
+				break
+			}
+		}
+		if configFile == nil || len(configFile.Hash) == 0 || daemon.KeaDaemon.Config == nil {
+			// The agent couldn't determine the file hash, so it is
+			// unknown whether the configuration has been saved.
+			daemon.KeaDaemon.ConfigFileHash = ""
+			daemon.KeaDaemon.SavedConfigHash = ""
+			daemon.KeaDaemon.UnsavedConfigChanges = false
+			continue
+		}
+		restarted := state != nil && state.RestartedDaemons[daemon.Name]
+		fileChanged := configFile.Hash != daemon.KeaDaemon.ConfigFileHash
+		if !restarted && !fileChanged && len(daemon.KeaDaemon.SavedConfigHash) > 0 && state != nil && state.SameConfigDaemons[daemon.Name] {
+			// Neither the configuration nor the file have changed.
+			continue
+		}
+		configHash := daemon.KeaDaemon.Config.GetContentHash()
+		if restarted || fileChanged || len(daemon.KeaDaemon.SavedConfigHash) == 0 {
+			daemon.KeaDaemon.SavedConfigHash = configHash
+		}
+		daemon.KeaDaemon.ConfigFileHash = configFile.Hash
+		unsaved := configHash != daemon.KeaDaemon.SavedConfigHash && configHash != configFile.Hash
+		if unsaved == daemon.KeaDaemon.UnsavedConfigChanges {
+			continue
+		}
+		daemon.KeaDaemon.UnsavedConfigChanges = unsaved
+		if state == nil {
+			continue
+		}
+		daemon.App = dbApp
+		var ev *dbmodel.Event
+		if unsaved {
+			details := fmt.Sprintf("The running configuration differs from the configuration file %s. The changes will be lost when the daemon is restarted unless they are written to the file.", configFile.Path)
+			ev = eventcenter.CreateEvent(dbmodel.EvWarning, "{daemon} has unsaved configuration changes", details, dbApp.Machine, dbApp, daemon)
+		} else {
+			ev = eventcenter.CreateEvent(dbmodel.EvInfo, "{daemon} configuration has been saved to the configuration file", dbApp.Machine, dbApp, daemon)
+		}
+		state.Events = append(state.Events, ev)
+	}
+}
+
 // Determines whether the new app is active or inactive based on the
 // active/inactive state of its daemons. It returns a boolean flag
 // indicating whether the app is active or not and the list of
@@ -363,8 +428,9 @@ func createNewAppState(daemonsMap map[string]*dbmodel.Daemon) (active bool, daem
 // indicating whether the app is considered active or inactive after update;
 // a boolean flag indicating whether daemons in the app should be replaced with
 // daemons returned in 3rd argument; list of events to be passed to the event
-// center; map of names of daemons for which configuration remains the same.
-func findChangesAndRaiseEvents(dbApp *dbmodel.App, daemonsMap map[string]*dbmodel.Daemon, daemonsErrors map[string]string) (bool, bool, []*dbmodel.Daemon, []*dbmodel.Event, map[string]bool) {
+// center; map of names of daemons for which configuration remains the same;
+// map of names of daemons which have been restarted or became reachable.
+func findChangesAndRaiseEvents(dbApp *dbmodel.App, daemonsMap map[string]*dbmodel.Daemon, daemonsErrors map[string]string) (bool, bool, []*dbmodel.Daemon, []*dbmodel.Event, map[string]bool, map[string]bool) {
 	var (
 		newDaemons []*dbmodel.Daemon
 		events     []*dbmodel.Event
@@ -396,11 +462,12 @@ func findChangesAndRaiseEvents(dbApp *dbmodel.App, daemonsMap map[string]*dbmode
 		// The events variable carries the list of generated events. The last value
 		// indicates that we have detected no daemons with no configuration change.
 		// In fact, we didn't go that far to check that.
-		return false, false, nil, events, nil
+		return false, false, nil, events, nil, nil
 	}
 
 	newActive := true
 	sameConfigDaemons := make(map[string]bool)
+	restartedDaemons := make(map[string]bool)
 
 	// Let's make sure that all daemons have a back pointer to the app because
 	// it will be needed by event center to generate events.
@@ -437,6 +504,7 @@ func findChangesAndRaiseEvents(dbApp *dbmodel.App, daemonsMap map[string]*dbmode
 			if daemon.Active && !oldDaemon.Active {
 				// Daemon was inactive and now it is active again.
 				text += "reachable now"
+				restartedDaemons[daemon.Name] = true
 			} else if !daemon.Active && oldDaemon.Active {
 				// Daemon was active and now it is inactive. This has higher
 				// severity.
@@ -449,6 +517,7 @@ func findChangesAndRaiseEvents(dbApp *dbmodel.App, daemonsMap map[string]*dbmode
 
 			// Check if daemon has been restarted.
 		} else if daemon.Uptime < oldDaemon.Uptime {
+			restartedDaemons[daemon.Name] = true
 			text := "{daemon} has been restarted"
 			ev := eventcenter.CreateEvent(dbmodel.EvWarning, text, dbApp.Machine, dbApp, oldDaemon)
 			events = append(events, ev)
@@ -471,7 +540,7 @@ func findChangesAndRaiseEvents(dbApp *dbmodel.App, daemonsMap map[string]*dbmode
 		}
 	}
 
-	return newActive, true, newDaemons, events, sameConfigDaemons
+	return newActive, true, newDaemons, events, sameConfigDaemons, restartedDaemons
 }
 
 // Detects a situation that the daemon configuration remains the same after update
@@ -639,6 +708,20 @@ func CommitAppIntoDB(db *dbops.PgDB, app *dbmodel.App, eventCenter eventcenter.E
 				}
 			}
 
+			// Store the daemon's configuration in the configuration history
+			// if it differs from the most recent revision.
+			if daemon.KeaDaemon != nil && daemon.KeaDaemon.Config != nil {
+				if _, err = dbmodel.AddKeaConfigRevision(tx, daemon); err != nil {
+					return err
+				}
+			}
+
+			// Replace the DDNS domains of the D2 daemon with the domains
+			// from its current configuration.
+			if err = dbmodel.CommitDDNSDomainsFromDaemon(tx, daemon); err != nil {
+				return err
+			}
+
 			// Remove daemon associations with hosts, subnets and shared networks.
 			err = deleteDaemonAssociations(tx, daemon)
 			if err != nil {
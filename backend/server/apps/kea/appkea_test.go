@@ -2,10 +2,12 @@ package kea
 
 import (
 	"context"
+	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/require"
 	keactrl "isc.org/stork/appctrl/kea"
+	"isc.org/stork/server/agentcomm"
 	agentcommtest "isc.org/stork/server/agentcomm/test"
 	dbmodel "isc.org/stork/server/database/model"
 	dbtest "isc.org/stork/server/database/test"
@@ -401,6 +403,229 @@ func TestGetDaemonHooksFrom1Daemon(t *testing.T) {
 	require.Equal(t, "hook_def.so", hooks[1])
 }
 
+// Returns the configuration of the DHCPv4 server as returned by the config-get
+// command. It includes the default values of the parameters not specified in
+// the configuration file and the hash of the configuration.
+func getTestConfigGetOutput(validLifetime int) string {
+	return fmt.Sprintf(`{
+		"Dhcp4": {
+			"authoritative": false,
+			"boot-file-name": "",
+			"calculate-tee-times": false,
+			"client-classes": [],
+			"control-socket": {
+				"socket-name": "/var/run/kea/kea4-ctrl-socket",
+				"socket-type": "unix"
+			},
+			"decline-probation-period": 86400,
+			"dhcp4o6-port": 0,
+			"echo-client-id": true,
+			"expired-leases-processing": {
+				"flush-reclaimed-timer-wait-time": 25,
+				"hold-reclaimed-time": 3600,
+				"max-reclaim-leases": 100,
+				"max-reclaim-time": 250,
+				"reclaim-timer-wait-time": 10,
+				"unwarned-reclaim-cycles": 5
+			},
+			"hooks-libraries": [],
+			"host-reservation-identifiers": [ "hw-address", "duid", "circuit-id", "client-id" ],
+			"interfaces-config": {
+				"interfaces": [ "eth0" ],
+				"re-detect": true
+			},
+			"lease-database": {
+				"type": "memfile",
+				"lfc-interval": 3600
+			},
+			"match-client-id": true,
+			"next-server": "0.0.0.0",
+			"option-data": [],
+			"option-def": [],
+			"rebind-timer": 2000,
+			"renew-timer": 1000,
+			"server-hostname": "",
+			"shared-networks": [],
+			"subnet4": [
+				{
+					"4o6-interface": "",
+					"4o6-interface-id": "",
+					"4o6-subnet": "",
+					"id": 1,
+					"option-data": [],
+					"pools": [
+						{
+							"option-data": [],
+							"pool": "192.0.2.1-192.0.2.200"
+						}
+					],
+					"relay": {
+						"ip-addresses": []
+					},
+					"reservations": [],
+					"subnet": "192.0.2.0/24"
+				}
+			],
+			"valid-lifetime": %d
+		},
+		"hash": "5C3C90EF7035249E2FF74D003C19F34EE0B83A3D329E741B52B2EF95A2C9CC5C"
+	}`, validLifetime)
+}
+
+// Test detecting the unsaved changes in the running configurations of the
+// daemons. The running configurations returned by the config-get command
+// include the default values, so they differ from the configuration files
+// even when there are no unsaved changes.
+func TestDetectUnsavedConfigChanges(t *testing.T) {
+	// The configuration file lacks the default values.
+	fileConfig, err := dbmodel.NewKeaConfigFromJSON(`{
+		// Configuration file comment.
+		"Dhcp4": {
+			"interfaces-config": {
+				"interfaces": [ "eth0" ]
+			},
+			"control-socket": {
+				"socket-type": "unix",
+				"socket-name": "/var/run/kea/kea4-ctrl-socket"
+			},
+			"lease-database": {
+				"type": "memfile",
+				"lfc-interval": 3600
+			},
+			"renew-timer": 1000,
+			"rebind-timer": 2000,
+			"valid-lifetime": 4000,
+			"subnet4": [
+				{
+					"id": 1,
+					"subnet": "192.0.2.0/24",
+					"pools": [ { "pool": "192.0.2.1-192.0.2.200" } ]
+				}
+			]
+		}
+	}`)
+	require.NoError(t, err)
+	fileHash := fileConfig.GetContentHash()
+
+	runningConfig, err := dbmodel.NewKeaConfigFromJSON(getTestConfigGetOutput(4000))
+	require.NoError(t, err)
+	require.NotEqual(t, fileHash, runningConfig.GetContentHash())
+
+	dbApp := &dbmodel.App{
+		ID:   1,
+		Type: dbmodel.AppTypeKea,
+		Machine: &dbmodel.Machine{
+			ID:      1,
+			Address: "localhost",
+		},
+		Daemons: []*dbmodel.Daemon{
+			{
+				ID:     1,
+				Name:   dbmodel.DaemonNameDHCPv4,
+				Active: true,
+				KeaDaemon: &dbmodel.KeaDaemon{
+					Config: runningConfig,
+				},
+			},
+		},
+	}
+	daemon := dbApp.Daemons[0]
+	state := &AppStateMeta{}
+	configFiles := []agentcomm.DaemonConfigFile{
+		{
+			Daemon: dbmodel.DaemonNameDHCPv4,
+			Path:   "/etc/kea/kea-dhcp4.conf",
+			Hash:   fileHash,
+		},
+	}
+
+	// The running configuration is assumed to match the file when the
+	// daemon is seen for the first time.
+	DetectUnsavedConfigChanges(dbApp, configFiles, state)
+	require.Equal(t, fileHash, daemon.KeaDaemon.ConfigFileHash)
+	require.Equal(t, runningConfig.GetContentHash(), daemon.KeaDaemon.SavedConfigHash)
+	require.False(t, daemon.KeaDaemon.UnsavedConfigChanges)
+	require.Empty(t, state.Events)
+
+	// Nothing has changed.
+	state.SameConfigDaemons = map[string]bool{dbmodel.DaemonNameDHCPv4: true}
+	DetectUnsavedConfigChanges(dbApp, configFiles, state)
+	require.False(t, daemon.KeaDaemon.UnsavedConfigChanges)
+	require.Empty(t, state.Events)
+
+	// The running configuration has been modified with the config-set
+	// command but it hasn't been written to the file.
+	state.SameConfigDaemons = nil
+	daemon.KeaDaemon.Config, err = dbmodel.NewKeaConfigFromJSON(getTestConfigGetOutput(5000))
+	require.NoError(t, err)
+	DetectUnsavedConfigChanges(dbApp, configFiles, state)
+	require.True(t, daemon.KeaDaemon.UnsavedConfigChanges)
+	require.Len(t, state.Events, 1)
+	require.Equal(t, dbmodel.EvWarning, state.Events[0].Level)
+	require.Contains(t, state.Events[0].Details, "/etc/kea/kea-dhcp4.conf")
+
+	// The configuration has been written to the file with the config-write
+	// command. The file includes the default values now.
+	configFiles[0].Hash = daemon.KeaDaemon.Config.GetContentHash()
+	DetectUnsavedConfigChanges(dbApp, configFiles, state)
+	require.False(t, daemon.KeaDaemon.UnsavedConfigChanges)
+	require.Len(t, state.Events, 2)
+	require.Equal(t, dbmodel.EvInfo, state.Events[1].Level)
+
+	// Modify the running configuration again.
+	daemon.KeaDaemon.Config, err = dbmodel.NewKeaConfigFromJSON(getTestConfigGetOutput(6000))
+	require.NoError(t, err)
+	DetectUnsavedConfigChanges(dbApp, configFiles, state)
+	require.True(t, daemon.KeaDaemon.UnsavedConfigChanges)
+	require.Len(t, state.Events, 3)
+
+	// The daemon has been restarted and loaded the configuration from the
+	// file. The unsaved changes have been lost.
+	daemon.KeaDaemon.Config, err = dbmodel.NewKeaConfigFromJSON(getTestConfigGetOutput(5000))
+	require.NoError(t, err)
+	state.RestartedDaemons = map[string]bool{dbmodel.DaemonNameDHCPv4: true}
+	DetectUnsavedConfigChanges(dbApp, configFiles, state)
+	require.False(t, daemon.KeaDaemon.UnsavedConfigChanges)
+	require.Len(t, state.Events, 4)
+	require.Equal(t, dbmodel.EvInfo, state.Events[3].Level)
+
+	// The file hash is unknown.
+	daemon.KeaDaemon.UnsavedConfigChanges = true
+	DetectUnsavedConfigChanges(dbApp, nil, state)
+	require.Empty(t, daemon.KeaDaemon.ConfigFileHash)
+	require.Empty(t, daemon.KeaDaemon.SavedConfigHash)
+	require.False(t, daemon.KeaDaemon.UnsavedConfigChanges)
+	require.Len(t, state.Events, 4)
+}
+
+// Test that the restarted daemons and the daemons which became reachable
+// are returned by the function detecting the changes in the app state.
+func TestFindChangesAndRaiseEventsRestartedDaemons(t *testing.T) {
+	dbApp := &dbmodel.App{
+		ID: 1,
+		Machine: &dbmodel.Machine{
+			ID: 1,
+		},
+		Daemons: []*dbmodel.Daemon{
+			{Name: dbmodel.DaemonNameCA, Active: true, Uptime: 100},
+			{Name: dbmodel.DaemonNameDHCPv4, Active: true, Uptime: 100},
+			{Name: dbmodel.DaemonNameDHCPv6, Active: false},
+			{Name: dbmodel.DaemonNameD2, Active: true, Uptime: 100},
+		},
+	}
+	daemonsMap := map[string]*dbmodel.Daemon{
+		dbmodel.DaemonNameCA:     {Name: dbmodel.DaemonNameCA, Active: true, Uptime: 200},
+		dbmodel.DaemonNameDHCPv4: {Name: dbmodel.DaemonNameDHCPv4, Active: true, Uptime: 10},
+		dbmodel.DaemonNameDHCPv6: {Name: dbmodel.DaemonNameDHCPv6, Active: true, Uptime: 10},
+		dbmodel.DaemonNameD2:     {Name: dbmodel.DaemonNameD2, Active: false},
+	}
+
+	_, _, _, _, _, restartedDaemons := findChangesAndRaiseEvents(dbApp, daemonsMap, map[string]string{})
+	require.Len(t, restartedDaemons, 2)
+	require.True(t, restartedDaemons[dbmodel.DaemonNameDHCPv4])
+	require.True(t, restartedDaemons[dbmodel.DaemonNameDHCPv6])
+}
+
 // Tests that Kea can be added and then updated in the database.
 func TestCommitAppIntoDB(t *testing.T) {
 	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
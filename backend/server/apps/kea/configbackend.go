@@ -0,0 +1,329 @@
+package kea
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+
+	"github.com/pkg/errors"
+	keaconfig "isc.org/stork/appcfg/kea"
+	keactrl "isc.org/stork/appctrl/kea"
+	dhcpmodel "isc.org/stork/datamodel/dhcp"
+	dbmodel "isc.org/stork/server/database/model"
+	storkutil "isc.org/stork/util"
+)
+
+// Describes the configuration backend from which a Kea DHCP daemon fetches
+// its configuration. Such a daemon is configured with the cb_cmds commands
+// (e.g., remote-subnet4-set) rather than the subnet_cmds or config-set
+// commands because the changes applied with these commands would be
+// overwritten by the next configuration fetch from the database.
+type daemonConfigBackend struct {
+	remote    *keactrl.RemoteSelector
+	serverTag string
+	family    int
+}
+
+// Returns the configuration backend used by the daemon or nil if the daemon
+// doesn't use the configuration backend or lacks the libdhcp_cb_cmds hook
+// library.
+func getDaemonConfigBackend(daemon *dbmodel.Daemon) *daemonConfigBackend {
+	if daemon == nil || daemon.KeaDaemon == nil || daemon.KeaDaemon.Config == nil {
+		return nil
+	}
+	database := daemon.KeaDaemon.Config.GetConfigBackend()
+	if database == nil {
+		return nil
+	}
+	family := 4
+	if daemon.KeaDaemon.Config.IsDHCPv6() {
+		family = 6
+	}
+	return &daemonConfigBackend{
+		remote:    keactrl.NewRemoteSelector(database),
+		serverTag: daemon.KeaDaemon.Config.GetServerTag(),
+		family:    family,
+	}
+}
+
+// Checks if the daemon can be configured with the subnet_cmds commands or
+// the cb_cmds commands.
+func hasSubnetCommands(daemon *dbmodel.Daemon) bool {
+	if getDaemonConfigBackend(daemon) != nil {
+		return true
+	}
+	_, _, exists := daemon.KeaDaemon.Config.GetHookLibrary("libdhcp_subnet_cmds")
+	return exists
+}
+
+// Creates the commands persisting the configuration changes applied to
+// the daemon. The config-write command writes the configuration to a file.
+// If the refreshStatistics flag is set, the config-write is followed by the
+// config-reload for Kea versions up to 2.6.0. These versions do not update
+// statistics after modifying pools with the subnet_cmds hook library, and
+// there is no lighter command to force the statistics update unfortunately.
+// The daemons using the configuration backend don't persist the changes in
+// the files. They are instructed to fetch the changes from the database
+// with the config-backend-pull command instead. If the writeConfig flag is
+// false, no commands are created for the daemons not using the configuration
+// backend. The changes remain unsaved until the configuration is explicitly
+// written. Note that the config-reload cannot be sent in this case because
+// it would revert the changes.
+func createPersistConfigCommands(daemon *dbmodel.Daemon, refreshStatistics, writeConfig bool) (commands []ConfigCommand) {
+	if getDaemonConfigBackend(daemon) != nil {
+		commands = append(commands, ConfigCommand{
+			Command:  keactrl.NewCommandConfigBackendPull(daemon.Name),
+			App:      daemon.App,
+			DaemonID: daemon.ID,
+		})
+		return
+	}
+	if !writeConfig {
+		return
+	}
+	commands = append(commands, ConfigCommand{
+		Command:  keactrl.NewCommandBase(keactrl.ConfigWrite, daemon.Name),
+		App:      daemon.App,
+		DaemonID: daemon.ID,
+	})
+	if !refreshStatistics {
+		return
+	}
+	version := storkutil.ParseSemanticVersionOrLatest(daemon.Version)
+	if version.LessThan(storkutil.NewSemanticVersion(2, 6, 0)) {
+		commands = append(commands, ConfigCommand{
+			Command:  keactrl.NewCommandBase(keactrl.ConfigReload, daemon.Name),
+			App:      daemon.App,
+			DaemonID: daemon.ID,
+		})
+	}
+	return
+}
+
+// Creates the config-write commands for the daemons holding the host
+// reservations managed with the host_cmds hook library in their
+// configurations. Such reservations live in the daemons' memory when
+// no hosts database is configured, and they would be lost upon restart
+// unless written to the configuration files. One command is created for
+// each daemon. No commands are created if the writeConfig flag is false.
+func createHostPersistConfigCommands(localHosts []dbmodel.LocalHost, writeConfig bool) (commands []ConfigCommand) {
+	if !writeConfig {
+		return
+	}
+	var daemonIDs []int64
+	for _, lh := range localHosts {
+		daemon := lh.Daemon
+		if !lh.DataSource.IsAPI() || daemon == nil || daemon.KeaDaemon == nil || daemon.KeaDaemon.Config == nil {
+			continue
+		}
+		if slices.Contains(daemonIDs, lh.DaemonID) || len(daemon.KeaDaemon.Config.GetAllDatabases().Hosts) > 0 {
+			continue
+		}
+		daemonIDs = append(daemonIDs, lh.DaemonID)
+		commands = append(commands, ConfigCommand{
+			Command:  keactrl.NewCommandBase(keactrl.ConfigWrite, daemon.Name),
+			App:      daemon.App,
+			DaemonID: lh.DaemonID,
+		})
+	}
+	return
+}
+
+// Creates the remote-network4-set or remote-network6-set command for the
+// shared network, followed by the remote-subnet4-set or remote-subnet6-set
+// commands for the subnets belonging to this shared network.
+func createRemoteSharedNetworkCommands(backend *daemonConfigBackend, daemon *dbmodel.Daemon, lookup keaconfig.DHCPOptionDefinitionLookup, sharedNetwork *dbmodel.SharedNetwork) (commands []ConfigCommand, err error) {
+	appCommand := ConfigCommand{
+		App:      daemon.App,
+		DaemonID: daemon.ID,
+	}
+	switch sharedNetwork.Family {
+	case 4:
+		sharedNetwork4, err := keaconfig.CreateSharedNetwork4(daemon.ID, lookup, sharedNetwork)
+		if err != nil {
+			return nil, err
+		}
+		appCommand.Command = keactrl.NewCommandRemoteNetwork4Set(backend.remote, backend.serverTag, sharedNetwork4, daemon.Name)
+		commands = append(commands, appCommand)
+		for i := range sharedNetwork4.Subnet4 {
+			appCommand.Command = keactrl.NewCommandRemoteSubnet4Set(backend.remote, backend.serverTag, &sharedNetwork4.Subnet4[i], &sharedNetwork4.Name, daemon.Name)
+			commands = append(commands, appCommand)
+		}
+	default:
+		sharedNetwork6, err := keaconfig.CreateSharedNetwork6(daemon.ID, lookup, sharedNetwork)
+		if err != nil {
+			return nil, err
+		}
+		appCommand.Command = keactrl.NewCommandRemoteNetwork6Set(backend.remote, backend.serverTag, sharedNetwork6, daemon.Name)
+		commands = append(commands, appCommand)
+		for i := range sharedNetwork6.Subnet6 {
+			appCommand.Command = keactrl.NewCommandRemoteSubnet6Set(backend.remote, backend.serverTag, &sharedNetwork6.Subnet6[i], &sharedNetwork6.Name, daemon.Name)
+			commands = append(commands, appCommand)
+		}
+	}
+	return commands, nil
+}
+
+// Creates the remote-subnet4-set or remote-subnet6-set command for the
+// subnet. The subnet is associated with the shared network having the
+// specified name or it is a top-level subnet if the name is empty.
+func createRemoteSubnetCommand(backend *daemonConfigBackend, daemon *dbmodel.Daemon, lookup keaconfig.DHCPOptionDefinitionLookup, subnet *dbmodel.Subnet, sharedNetworkName string) (ConfigCommand, error) {
+	appCommand := ConfigCommand{
+		App:      daemon.App,
+		DaemonID: daemon.ID,
+	}
+	var sharedNetworkNamePtr *string
+	if sharedNetworkName != "" {
+		sharedNetworkNamePtr = &sharedNetworkName
+	}
+	switch subnet.GetFamily() {
+	case 4:
+		subnet4, err := keaconfig.CreateSubnet4(daemon.ID, lookup, subnet)
+		if err != nil {
+			return appCommand, err
+		}
+		appCommand.Command = keactrl.NewCommandRemoteSubnet4Set(backend.remote, backend.serverTag, subnet4, sharedNetworkNamePtr, daemon.Name)
+	default:
+		subnet6, err := keaconfig.CreateSubnet6(daemon.ID, lookup, subnet)
+		if err != nil {
+			return appCommand, err
+		}
+		appCommand.Command = keactrl.NewCommandRemoteSubnet6Set(backend.remote, backend.serverTag, subnet6, sharedNetworkNamePtr, daemon.Name)
+	}
+	return appCommand, nil
+}
+
+// Creates the commands setting and deleting the global parameters and the
+// global options in the configuration backend. The partial configuration
+// designates the modified parameters. The configurations before and after
+// the update are compared, so the commands are only created for the
+// parameters that have actually changed. The map parameters (e.g.,
+// dhcp-ddns) are set in the configuration backend using their scoped
+// names (e.g., dhcp-ddns.enable-updates).
+func createRemoteGlobalParametersCommands(backend *daemonConfigBackend, daemon *dbmodel.Daemon, before, after *keaconfig.Config, partial keaconfig.RawConfigAccessor) (commands []ConfigCommand, err error) {
+	rootName := "Dhcp4"
+	if backend.family == 6 {
+		rootName = "Dhcp6"
+	}
+	partialConfig, err := partial.GetRawConfig()
+	if err != nil {
+		return nil, err
+	}
+	partialParameters, _ := partialConfig[rootName].(map[string]any)
+	beforeParameters, _ := before.Raw[rootName].(map[string]any)
+	afterParameters, _ := after.Raw[rootName].(map[string]any)
+
+	var (
+		setParameters     = make(map[string]any)
+		deletedParameters []string
+		optionsChanged    bool
+	)
+	for name := range partialParameters {
+		if name == "option-data" {
+			optionsChanged = true
+			continue
+		}
+		beforeValue, beforeOk := beforeParameters[name]
+		afterValue, afterOk := afterParameters[name]
+		beforeMap, beforeIsMap := beforeValue.(map[string]any)
+		afterMap, afterIsMap := afterValue.(map[string]any)
+		if beforeIsMap || afterIsMap {
+			// Compare the scoped parameters.
+			scopedNames := make(map[string]bool)
+			for scopedName := range beforeMap {
+				scopedNames[scopedName] = true
+			}
+			for scopedName := range afterMap {
+				scopedNames[scopedName] = true
+			}
+			for scopedName := range scopedNames {
+				beforeScopedValue, beforeScopedOk := beforeMap[scopedName]
+				afterScopedValue, afterScopedOk := afterMap[scopedName]
+				qualifiedName := fmt.Sprintf("%s.%s", name, scopedName)
+				switch {
+				case !afterScopedOk && beforeScopedOk:
+					deletedParameters = append(deletedParameters, qualifiedName)
+				case afterScopedOk && !reflect.DeepEqual(beforeScopedValue, afterScopedValue):
+					setParameters[qualifiedName] = afterScopedValue
+				}
+			}
+			continue
+		}
+		switch {
+		case !afterOk && beforeOk:
+			deletedParameters = append(deletedParameters, name)
+		case afterOk && !reflect.DeepEqual(beforeValue, afterValue):
+			setParameters[name] = afterValue
+		}
+	}
+	appCommand := ConfigCommand{
+		App:      daemon.App,
+		DaemonID: daemon.ID,
+	}
+	if len(setParameters) > 0 {
+		appCommand.Command = keactrl.NewCommandRemoteGlobalParameterSet(backend.family, backend.remote, backend.serverTag, setParameters, daemon.Name)
+		commands = append(commands, appCommand)
+	}
+	if len(deletedParameters) > 0 {
+		sort.Strings(deletedParameters)
+		appCommand.Command = keactrl.NewCommandRemoteGlobalParameterDel(backend.family, backend.remote, backend.serverTag, deletedParameters, daemon.Name)
+		commands = append(commands, appCommand)
+	}
+	if !optionsChanged {
+		return commands, nil
+	}
+	optionCommands, err := createRemoteGlobalOptionsCommands(backend, daemon, before.GetDHCPOptions(), after.GetDHCPOptions())
+	if err != nil {
+		return nil, err
+	}
+	return append(commands, optionCommands...), nil
+}
+
+// Creates the commands setting the new and modified global options and
+// deleting the removed global options in the configuration backend.
+func createRemoteGlobalOptionsCommands(backend *daemonConfigBackend, daemon *dbmodel.Daemon, beforeOptions, afterOptions []keaconfig.SingleOptionData) (commands []ConfigCommand, err error) {
+	defaultSpace := dhcpmodel.DHCPv4OptionSpace
+	if backend.family == 6 {
+		defaultSpace = dhcpmodel.DHCPv6OptionSpace
+	}
+	getSpace := func(option keaconfig.SingleOptionData) string {
+		if option.Space == "" {
+			return defaultSpace
+		}
+		return option.Space
+	}
+	findOption := func(options []keaconfig.SingleOptionData, option keaconfig.SingleOptionData) *keaconfig.SingleOptionData {
+		for i := range options {
+			if getSpace(options[i]) != getSpace(option) {
+				continue
+			}
+			if (option.Code != 0 && options[i].Code == option.Code) || (option.Code == 0 && options[i].Name == option.Name) {
+				return &options[i]
+			}
+		}
+		return nil
+	}
+	appCommand := ConfigCommand{
+		App:      daemon.App,
+		DaemonID: daemon.ID,
+	}
+	for _, option := range beforeOptions {
+		if findOption(afterOptions, option) != nil {
+			continue
+		}
+		if option.Code == 0 {
+			return nil, errors.Errorf("unable to delete global option %s without the code from the configuration backend", option.Name)
+		}
+		appCommand.Command = keactrl.NewCommandRemoteOptionGlobalDel(backend.family, backend.remote, backend.serverTag, option.Code, getSpace(option), daemon.Name)
+		commands = append(commands, appCommand)
+	}
+	for i, option := range afterOptions {
+		if existing := findOption(beforeOptions, option); existing != nil && reflect.DeepEqual(*existing, option) {
+			continue
+		}
+		appCommand.Command = keactrl.NewCommandRemoteOptionGlobalSet(backend.family, backend.remote, backend.serverTag, &afterOptions[i], daemon.Name)
+		commands = append(commands, appCommand)
+	}
+	return commands, nil
+}
@@ -0,0 +1,393 @@
+package kea
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	keaconfig "isc.org/stork/appcfg/kea"
+	keactrl "isc.org/stork/appctrl/kea"
+	dbmodel "isc.org/stork/server/database/model"
+	storkutil "isc.org/stork/util"
+)
+
+// Returns a test daemon using the configuration backend.
+func getTestConfigBackendDaemon(t *testing.T, id int64, serverConfig string) dbmodel.Daemon {
+	daemonConfig, err := dbmodel.NewKeaConfigFromJSON(serverConfig)
+	require.NoError(t, err)
+	name := dbmodel.DaemonNameDHCPv4
+	if daemonConfig.IsDHCPv6() {
+		name = dbmodel.DaemonNameDHCPv6
+	}
+	return dbmodel.Daemon{
+		ID:      id,
+		Name:    name,
+		Version: "2.6.0",
+		KeaDaemon: &dbmodel.KeaDaemon{
+			Config: daemonConfig,
+		},
+		App: &dbmodel.App{
+			AccessPoints: []*dbmodel.AccessPoint{
+				{
+					Type:    dbmodel.AccessPointControl,
+					Address: "192.0.2.1",
+					Port:    1234,
+				},
+			},
+		},
+	}
+}
+
+// Test getting the configuration backend used by the daemon.
+func TestGetDaemonConfigBackend(t *testing.T) {
+	daemon := getTestConfigBackendDaemon(t, 1, `{
+		"Dhcp6": {
+			"server-tag": "server1",
+			"config-control": {
+				"config-databases": [
+					{
+						"type": "postgresql",
+						"name": "kea"
+					}
+				]
+			},
+			"hooks-libraries": [
+				{
+					"library": "/usr/lib/kea/libdhcp_cb_cmds.so"
+				}
+			]
+		}
+	}`)
+	backend := getDaemonConfigBackend(&daemon)
+	require.NotNil(t, backend)
+	require.Equal(t, "postgresql", backend.remote.Type)
+	require.Equal(t, "server1", backend.serverTag)
+	require.Equal(t, 6, backend.family)
+	require.True(t, hasSubnetCommands(&daemon))
+
+	// The daemon without the cb_cmds hook library.
+	daemon = getTestConfigBackendDaemon(t, 1, `{
+		"Dhcp4": {
+			"config-control": {
+				"config-databases": [
+					{
+						"type": "mysql",
+						"name": "kea"
+					}
+				]
+			}
+		}
+	}`)
+	require.Nil(t, getDaemonConfigBackend(&daemon))
+	require.False(t, hasSubnetCommands(&daemon))
+
+	// The daemon without the configuration.
+	daemon.KeaDaemon.Config = nil
+	require.Nil(t, getDaemonConfigBackend(&daemon))
+	require.Nil(t, getDaemonConfigBackend(nil))
+}
+
+// Test creating the commands persisting the configuration changes.
+func TestCreatePersistConfigCommands(t *testing.T) {
+	daemon := getTestConfigBackendDaemon(t, 1, `{
+		"Dhcp4": {
+			"hooks-libraries": [
+				{
+					"library": "/usr/lib/kea/libdhcp_subnet_cmds.so"
+				}
+			]
+		}
+	}`)
+
+	// The config-write command should be sent.
+	commands := createPersistConfigCommands(&daemon, true, true)
+	require.Len(t, commands, 1)
+	require.Equal(t, keactrl.ConfigWrite, commands[0].Command.GetCommand())
+	require.EqualValues(t, 1, commands[0].DaemonID)
+	require.Equal(t, daemon.App, commands[0].App)
+
+	// The config-reload command should follow the config-write for the
+	// older Kea versions when the statistics refresh is requested.
+	daemon.Version = "2.4.0"
+	commands = createPersistConfigCommands(&daemon, true, true)
+	require.Len(t, commands, 2)
+	require.Equal(t, keactrl.ConfigWrite, commands[0].Command.GetCommand())
+	require.Equal(t, keactrl.ConfigReload, commands[1].Command.GetCommand())
+
+	commands = createPersistConfigCommands(&daemon, false, true)
+	require.Len(t, commands, 1)
+	require.Equal(t, keactrl.ConfigWrite, commands[0].Command.GetCommand())
+
+	// No commands should be sent when writing the configuration is disabled.
+	commands = createPersistConfigCommands(&daemon, true, false)
+	require.Empty(t, commands)
+
+	// The daemon using the configuration backend should fetch the changes
+	// even when writing the configuration is disabled.
+	daemon = getTestConfigBackendDaemon(t, 2, `{
+		"Dhcp4": {
+			"config-control": {
+				"config-databases": [
+					{
+						"type": "mysql",
+						"name": "kea"
+					}
+				]
+			},
+			"hooks-libraries": [
+				{
+					"library": "/usr/lib/kea/libdhcp_cb_cmds.so"
+				}
+			]
+		}
+	}`)
+	daemon.Version = "2.4.0"
+	commands = createPersistConfigCommands(&daemon, true, false)
+	require.Len(t, commands, 1)
+	require.JSONEq(t, `{
+		"command": "config-backend-pull",
+		"service": [ "dhcp4" ]
+	}`, commands[0].Command.Marshal())
+	require.EqualValues(t, 2, commands[0].DaemonID)
+}
+
+// Test that the commands are only created for the changed global parameters
+// and options.
+func TestCreateRemoteGlobalParametersCommands(t *testing.T) {
+	daemon := getTestConfigBackendDaemon(t, 1, `{
+		"Dhcp4": {
+			"server-tag": "server1",
+			"valid-lifetime": 3000,
+			"renew-timer": 1000,
+			"ddns-send-updates": true,
+			"dhcp-ddns": {
+				"enable-updates": false,
+				"server-ip": "127.0.0.1"
+			},
+			"option-data": [
+				{
+					"code": 6,
+					"data": "192.0.2.1"
+				},
+				{
+					"code": 3,
+					"data": "192.0.2.254"
+				},
+				{
+					"code": 15,
+					"data": "example.org"
+				}
+			],
+			"config-control": {
+				"config-databases": [
+					{
+						"type": "mysql",
+						"name": "kea"
+					}
+				]
+			},
+			"hooks-libraries": [
+				{
+					"library": "/usr/lib/kea/libdhcp_cb_cmds.so"
+				}
+			]
+		}
+	}`)
+	backend := getDaemonConfigBackend(&daemon)
+	require.NotNil(t, backend)
+
+	partial, err := keaconfig.NewConfig(`{
+		"Dhcp4": {
+			"valid-lifetime": 1111,
+			"renew-timer": 1000,
+			"ddns-send-updates": null,
+			"dhcp-ddns": {
+				"enable-updates": true,
+				"server-ip": null
+			},
+			"option-data": [
+				{
+					"code": 6,
+					"data": "192.0.2.2"
+				},
+				{
+					"code": 15,
+					"data": "example.org"
+				}
+			]
+		}
+	}`)
+	require.NoError(t, err)
+
+	daemons, err := copyDaemonsWithConfigs([]dbmodel.Daemon{daemon})
+	require.NoError(t, err)
+	after := daemons[0].KeaDaemon.Config.Config
+	require.NoError(t, after.Merge(partial))
+
+	commands, err := createRemoteGlobalParametersCommands(backend, &daemon, daemon.KeaDaemon.Config.Config, after, partial)
+	require.NoError(t, err)
+	require.Len(t, commands, 4)
+
+	require.JSONEq(t, `{
+		"command": "remote-global-parameter4-set",
+		"service": [ "dhcp4" ],
+		"arguments": {
+			"remote": {
+				"type": "mysql"
+			},
+			"server-tags": [ "server1" ],
+			"parameters": {
+				"valid-lifetime": 1111,
+				"dhcp-ddns.enable-updates": true
+			}
+		}
+	}`, commands[0].Command.Marshal())
+	require.JSONEq(t, `{
+		"command": "remote-global-parameter4-del",
+		"service": [ "dhcp4" ],
+		"arguments": {
+			"remote": {
+				"type": "mysql"
+			},
+			"server-tags": [ "server1" ],
+			"parameters": [ "ddns-send-updates", "dhcp-ddns.server-ip" ]
+		}
+	}`, commands[1].Command.Marshal())
+	require.JSONEq(t, `{
+		"command": "remote-option4-global-del",
+		"service": [ "dhcp4" ],
+		"arguments": {
+			"remote": {
+				"type": "mysql"
+			},
+			"server-tags": [ "server1" ],
+			"options": [
+				{
+					"code": 3,
+					"space": "dhcp4"
+				}
+			]
+		}
+	}`, commands[2].Command.Marshal())
+	require.JSONEq(t, `{
+		"command": "remote-option4-global-set",
+		"service": [ "dhcp4" ],
+		"arguments": {
+			"remote": {
+				"type": "mysql"
+			},
+			"server-tags": [ "server1" ],
+			"options": [
+				{
+					"code": 6,
+					"data": "192.0.2.2"
+				}
+			]
+		}
+	}`, commands[3].Command.Marshal())
+
+	for _, command := range commands {
+		require.EqualValues(t, 1, command.DaemonID)
+		require.Equal(t, daemon.App, command.App)
+	}
+}
+
+// Test that no commands are created when the global parameters haven't
+// changed.
+func TestCreateRemoteGlobalParametersCommandsNoChange(t *testing.T) {
+	daemon := getTestConfigBackendDaemon(t, 1, `{
+		"Dhcp4": {
+			"valid-lifetime": 3000,
+			"config-control": {
+				"config-databases": [
+					{
+						"type": "mysql",
+						"name": "kea"
+					}
+				]
+			},
+			"hooks-libraries": [
+				{
+					"library": "/usr/lib/kea/libdhcp_cb_cmds.so"
+				}
+			]
+		}
+	}`)
+	backend := getDaemonConfigBackend(&daemon)
+	require.NotNil(t, backend)
+
+	partial := keaconfig.NewSettableDHCPv4Config()
+	partial.SetValidLifetime(storkutil.Ptr(int64(3000)))
+
+	daemons, err := copyDaemonsWithConfigs([]dbmodel.Daemon{daemon})
+	require.NoError(t, err)
+	after := daemons[0].KeaDaemon.Config.Config
+	require.NoError(t, after.Merge(partial))
+
+	commands, err := createRemoteGlobalParametersCommands(backend, &daemon, daemon.KeaDaemon.Config.Config, after, partial)
+	require.NoError(t, err)
+	require.Empty(t, commands)
+}
+
+// Test that the config-write commands are created for the daemons holding
+// the host reservations in their configurations.
+func TestCreateHostPersistConfigCommands(t *testing.T) {
+	daemon1 := getTestConfigBackendDaemon(t, 1, `{
+		"Dhcp4": {
+			"hooks-libraries": [
+				{
+					"library": "/usr/lib/kea/libdhcp_host_cmds.so"
+				}
+			]
+		}
+	}`)
+	daemon2 := getTestConfigBackendDaemon(t, 2, `{
+		"Dhcp4": {
+			"hosts-database": {
+				"type": "mysql",
+				"name": "kea"
+			},
+			"hooks-libraries": [
+				{
+					"library": "/usr/lib/kea/libdhcp_host_cmds.so"
+				}
+			]
+		}
+	}`)
+	localHosts := []dbmodel.LocalHost{
+		{
+			DaemonID:   1,
+			Daemon:     &daemon1,
+			DataSource: dbmodel.HostDataSourceAPI,
+		},
+		// The reservations in the hosts database don't need to be written.
+		{
+			DaemonID:   2,
+			Daemon:     &daemon2,
+			DataSource: dbmodel.HostDataSourceAPI,
+		},
+		// The reservations from the configuration file are not managed
+		// with the host_cmds.
+		{
+			DaemonID:   1,
+			Daemon:     &daemon1,
+			DataSource: dbmodel.HostDataSourceConfig,
+		},
+		// Only one command should be created for the daemon.
+		{
+			DaemonID:   1,
+			Daemon:     &daemon1,
+			DataSource: dbmodel.HostDataSourceAPI,
+		},
+	}
+	commands := createHostPersistConfigCommands(localHosts, true)
+	require.Len(t, commands, 1)
+	require.JSONEq(t, `{
+		"command": "config-write",
+		"service": [ "dhcp4" ]
+	}`, commands[0].Command.Marshal())
+	require.EqualValues(t, 1, commands[0].DaemonID)
+	require.Equal(t, daemon1.App, commands[0].App)
+
+	// No commands when writing the configuration is disabled.
+	require.Empty(t, createHostPersistConfigCommands(localHosts, false))
+}
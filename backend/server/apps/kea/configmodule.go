@@ -3,9 +3,12 @@ package kea
 import (
 	"context"
 	"encoding/json"
+	"fmt"
+	"slices"
 
 	"github.com/go-pg/pg/v10"
 	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
 	keaconfig "isc.org/stork/appcfg/kea"
 	keactrl "isc.org/stork/appctrl/kea"
 	"isc.org/stork/server/config"
@@ -24,6 +27,8 @@ var (
 type ConfigCommand struct {
 	Command *keactrl.Command
 	App     *dbmodel.App
+	// ID of the daemon to which the command is sent.
+	DaemonID int64
 }
 
 type GlobalConfigRecipeParams struct {
@@ -45,6 +50,8 @@ type HostConfigRecipeParams struct {
 	HostAfterUpdate *dbmodel.Host
 	// Edited or deleted host ID.
 	HostID *int64
+	// Instances of the hosts (reservations) added in bulk by the import.
+	ImportedHosts []*dbmodel.Host
 }
 
 // A structure embedded in the ConfigRecipe grouping parameters used
@@ -79,6 +86,33 @@ type SubnetConfigRecipeParams struct {
 	SubnetID *int64
 }
 
+// A structure embedded in the ConfigRecipe grouping parameters used
+// in transactions adding, updating and deleting client classes. The
+// daemons' configurations before and after the update are held in
+// the GlobalConfigRecipeParams.
+type ClientClassConfigRecipeParams struct {
+	// An instance of the client class after it has been added or updated.
+	// This instance is held in the context until it is committed or scheduled
+	// for committing later.
+	ClientClassAfterUpdate *keaconfig.ClientClass
+	// Edited or deleted client class name.
+	ClientClassName *string
+}
+
+// A structure embedded in the ConfigRecipe grouping parameters used
+// in transactions adding, updating and deleting custom option definitions.
+// The daemons' configurations before and after the update are held in
+// the GlobalConfigRecipeParams.
+type OptionDefConfigRecipeParams struct {
+	// An instance of the option definition after it has been added or
+	// updated.
+	OptionDefAfterUpdate *keaconfig.OptionDef
+	// Code of the edited or deleted option definition.
+	OptionDefCode *uint16
+	// Option space of the edited or deleted option definition.
+	OptionDefSpace *string
+}
+
 // Represents a Kea config change recipe. A recipe is associated with
 // each config update and may comprise several commands sent to different
 // Kea servers. Other data stored in the recipe structure are used in the
@@ -101,6 +135,12 @@ type ConfigRecipe struct {
 	// Embedded structure holding the parameters appropriate for the
 	// subnet management.
 	SubnetConfigRecipeParams
+	// Embedded structure holding the parameters appropriate for the
+	// client class management.
+	ClientClassConfigRecipeParams
+	// Embedded structure holding the parameters appropriate for the
+	// option definition management.
+	OptionDefConfigRecipeParams
 }
 
 // A configuration manager module responsible for the Kea configuration.
@@ -145,6 +185,8 @@ func (module *ConfigModule) Commit(ctx context.Context) (context.Context, error)
 			ctx, err = module.commitGlobalParametersUpdate(ctx)
 		case "host_add":
 			ctx, err = module.commitHostAdd(ctx)
+		case "hosts_import":
+			ctx, err = module.commitHostsImport(ctx)
 		case "host_update":
 			ctx, err = module.commitHostUpdate(ctx)
 		case "host_delete":
@@ -161,16 +203,52 @@ func (module *ConfigModule) Commit(ctx context.Context) (context.Context, error)
 			ctx, err = module.commitSubnetUpdate(ctx)
 		case "subnet_delete":
 			ctx, err = module.commitSubnetDelete(ctx)
+		case "client_class_add", "client_class_update", "client_class_delete":
+			ctx, err = module.commitDaemonConfigChanges(ctx, "client class")
+		case "option_def_add", "option_def_update", "option_def_delete":
+			ctx, err = module.commitDaemonConfigChanges(ctx, "option definition")
+		case "ddns_domains_update":
+			ctx, err = module.commitDaemonConfigChanges(ctx, "DDNS domain")
+		case "config_rollback":
+			ctx, err = module.commitDaemonConfigChanges(ctx, "configuration revision")
+		case "config_write":
+			// Writing the configuration to the file doesn't change the
+			// configuration, so there is no revision cause to remember.
+			if ctx, err = module.commitChanges(ctx); err != nil {
+				return ctx, err
+			}
+			continue
 		default:
 			err = errors.Errorf("unknown operation %s when called Commit()", pu.Operation)
 		}
 		if err != nil {
 			return ctx, err
 		}
+		module.addConfigRevisionCauses(ctx, pu)
 	}
 	return ctx, err
 }
 
+// Remembers the user and the operation which caused the changes in the
+// daemons' configurations, so the configuration revisions subsequently
+// fetched by the state puller can be associated with them. A failure to
+// remember the cause is not fatal because the changes have already been
+// applied.
+func (module *ConfigModule) addConfigRevisionCauses(ctx context.Context, update *config.Update[ConfigRecipe]) {
+	userID, _ := config.GetValueAsInt64(ctx, config.UserContextKey)
+	var daemonIDs []int64
+	for _, command := range update.Recipe.Commands {
+		if command.DaemonID == 0 || slices.Contains(daemonIDs, command.DaemonID) {
+			continue
+		}
+		daemonIDs = append(daemonIDs, command.DaemonID)
+		err := dbmodel.AddKeaConfigRevisionCause(module.manager.GetDB(), command.DaemonID, userID, update.Operation)
+		if err != nil {
+			log.WithError(err).Warnf("Problem with remembering the cause of the configuration change for daemon %d", command.DaemonID)
+		}
+	}
+}
+
 // Begins updating global configuration parameters for one or more Kea servers.
 // Since the configuration update can be performed for multiple daemons in a
 // single transaction it is possible to specify multiple daemon IDs for which
@@ -215,10 +293,15 @@ func (module *ConfigModule) ApplyGlobalParametersUpdate(ctx context.Context, dae
 	}
 	// Retrieve the existing daemons with their configurations. The partial
 	// configurations will be merged into them.
-	existingDaemons := recipe.KeaDaemonsBeforeConfigUpdate
-	if existingDaemons == nil {
+	if recipe.KeaDaemonsBeforeConfigUpdate == nil {
 		return ctx, errors.New("internal server error - existing Kea configs cannot be nil when committing global parameters update")
 	}
+	// Work on the copies of the configurations to keep the original
+	// configurations intact.
+	existingDaemons, err := copyDaemonsWithConfigs(recipe.KeaDaemonsBeforeConfigUpdate)
+	if err != nil {
+		return ctx, err
+	}
 	var (
 		commands         []ConfigCommand
 		updatedDaemonIDs []int64
@@ -226,19 +309,32 @@ func (module *ConfigModule) ApplyGlobalParametersUpdate(ctx context.Context, dae
 	// Iterate over the received partial configs and match them with the daemons
 	// for which the config update is performed.
 	for _, daemonSettableConfig := range daemonSettableConfigs {
-		for _, existingDaemon := range existingDaemons {
+		for i, existingDaemon := range existingDaemons {
 			if daemonSettableConfig.GetID() == existingDaemon.ID {
 				// Merge the partial configuration into the existing configuration.
 				err = existingDaemon.KeaDaemon.Config.Merge(daemonSettableConfig.GetEntity())
 				if err != nil {
 					return ctx, err
 				}
+				updatedDaemonIDs = append(updatedDaemonIDs, daemonSettableConfig.GetID())
+				// The daemons using the configuration backend only receive the
+				// changed parameters.
+				if backend := getDaemonConfigBackend(&existingDaemon); backend != nil {
+					remoteCommands, err := createRemoteGlobalParametersCommands(backend, &existingDaemon,
+						recipe.KeaDaemonsBeforeConfigUpdate[i].KeaDaemon.Config.Config,
+						existingDaemon.KeaDaemon.Config.Config, daemonSettableConfig.GetEntity())
+					if err != nil {
+						return ctx, err
+					}
+					commands = append(commands, remoteCommands...)
+					continue
+				}
 				appCommand := ConfigCommand{
-					Command: keactrl.NewCommandConfigSet(existingDaemon.KeaDaemon.Config.Config, existingDaemon.Name),
-					App:     existingDaemon.App,
+					Command:  keactrl.NewCommandConfigSet(existingDaemon.KeaDaemon.Config.Config, existingDaemon.Name),
+					App:      existingDaemon.App,
+					DaemonID: existingDaemon.ID,
 				}
 				commands = append(commands, appCommand)
-				updatedDaemonIDs = append(updatedDaemonIDs, daemonSettableConfig.GetID())
 			}
 		}
 	}
@@ -247,11 +343,7 @@ func (module *ConfigModule) ApplyGlobalParametersUpdate(ctx context.Context, dae
 	}
 	// Each config-set must come with config-write to persist the configuration.
 	for _, existingDaemon := range existingDaemons {
-		appCommand := ConfigCommand{
-			Command: keactrl.NewCommandBase(keactrl.ConfigWrite, existingDaemon.Name),
-			App:     existingDaemon.App,
-		}
-		commands = append(commands, appCommand)
+		commands = append(commands, createPersistConfigCommands(&existingDaemon, false, config.IsConfigWriteEnabled(ctx))...)
 	}
 	// Remember the modified configurations.
 	recipe.KeaDaemonsAfterConfigUpdate = existingDaemons
@@ -307,37 +399,118 @@ func (module *ConfigModule) ApplyHostAdd(ctx context.Context, host *dbmodel.Host
 	if len(host.LocalHosts) == 0 {
 		return ctx, errors.New("applied host is not associated with any daemon")
 	}
+	commands, err := module.createHostAddCommands(host)
+	if err != nil {
+		return ctx, err
+	}
+	commands = append(commands, createHostPersistConfigCommands(host.LocalHosts, config.IsConfigWriteEnabled(ctx))...)
+	recipe := &ConfigRecipe{
+		HostConfigRecipeParams: HostConfigRecipeParams{
+			HostAfterUpdate: host,
+		},
+		Commands: commands,
+	}
+	if ctx, err = config.SetRecipeForUpdate(ctx, 0, recipe); err != nil {
+		return ctx, err
+	}
+	return ctx, nil
+}
+
+// Returns the reservation-add commands creating the host reservation in
+// the daemons associated with the host.
+func (module *ConfigModule) createHostAddCommands(host *dbmodel.Host) ([]ConfigCommand, error) {
 	var commands []ConfigCommand
 	for _, lh := range host.LocalHosts {
 		if lh.Daemon == nil {
-			return ctx, errors.New("applied host is associated with nil daemon")
+			return nil, errors.New("applied host is associated with nil daemon")
 		}
 		if lh.Daemon.App == nil {
-			return ctx, errors.New("applied host is associated with nil app")
+			return nil, errors.New("applied host is associated with nil app")
 		}
 		// Convert the host information to Kea reservation.
 		lookup := module.manager.GetDHCPOptionDefinitionLookup()
 		reservation, err := keaconfig.CreateHostCmdsReservation(lh.DaemonID, lookup, host)
 		if err != nil {
-			return ctx, err
+			return nil, err
 		}
 		// Associate the command with an app receiving this command.
 		appCommand := ConfigCommand{
-			Command: keactrl.NewCommandReservationAdd(reservation, lh.Daemon.Name),
-			App:     lh.Daemon.App,
+			Command:  keactrl.NewCommandReservationAdd(reservation, lh.Daemon.Name),
+			App:      lh.Daemon.App,
+			DaemonID: lh.DaemonID,
 		}
 		commands = append(commands, appCommand)
 	}
-	var err error
+	return commands, nil
+}
+
+// Begins adding the host reservations in bulk. It initializes transaction
+// state.
+func (module *ConfigModule) BeginHostsImport(ctx context.Context) (context.Context, error) {
+	// Create transaction state.
+	state := config.NewTransactionStateWithUpdate[ConfigRecipe]("kea", "hosts_import")
+	ctx = context.WithValue(ctx, config.StateContextKey, *state)
+	return ctx, nil
+}
+
+// Applies the host reservations added in bulk (e.g., imported from a file).
+// It prepares the reservation-add commands for all hosts, so they are sent
+// in a single transaction. Unlike adding the hosts one by one, the
+// configuration of each daemon is written once, after all hosts are added.
+func (module *ConfigModule) ApplyHostsImport(ctx context.Context, hosts []*dbmodel.Host) (context.Context, error) {
+	if len(hosts) == 0 {
+		return ctx, errors.New("no hosts to import")
+	}
+	var (
+		commands   []ConfigCommand
+		localHosts []dbmodel.LocalHost
+	)
+	for _, host := range hosts {
+		if len(host.LocalHosts) == 0 {
+			return ctx, errors.New("applied host is not associated with any daemon")
+		}
+		hostCommands, err := module.createHostAddCommands(host)
+		if err != nil {
+			return ctx, err
+		}
+		commands = append(commands, hostCommands...)
+		localHosts = append(localHosts, host.LocalHosts...)
+	}
+	commands = append(commands, createHostPersistConfigCommands(localHosts, config.IsConfigWriteEnabled(ctx))...)
 	recipe := &ConfigRecipe{
 		HostConfigRecipeParams: HostConfigRecipeParams{
-			HostAfterUpdate: host,
+			ImportedHosts: hosts,
 		},
 		Commands: commands,
 	}
-	if ctx, err = config.SetRecipeForUpdate(ctx, 0, recipe); err != nil {
+	return config.SetRecipeForUpdate(ctx, 0, recipe)
+}
+
+// Create the host reservations added in bulk in the Kea servers. The hosts
+// are added to the Stork database in a single database transaction.
+func (module *ConfigModule) commitHostsImport(ctx context.Context) (context.Context, error) {
+	state, ok := config.GetTransactionState[ConfigRecipe](ctx)
+	if !ok {
+		return ctx, errors.New("context lacks state")
+	}
+	var err error
+	ctx, err = module.commitChanges(ctx)
+	if err != nil {
 		return ctx, err
 	}
+	err = module.manager.GetDB().RunInTransaction(context.Background(), func(tx *pg.Tx) error {
+		for _, update := range state.Updates {
+			for _, host := range update.Recipe.ImportedHosts {
+				if err := dbmodel.AddHost(tx, host); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return ctx, errors.WithMessage(err, "hosts have been successfully added to Kea but adding them to the Stork database failed")
+	}
 	return ctx, nil
 }
 
@@ -443,8 +616,9 @@ func (module *ConfigModule) ApplyHostUpdate(ctx context.Context, host *dbmodel.H
 		}
 		// Associate the command with an app receiving this command.
 		appCommand := ConfigCommand{
-			Command: keactrl.NewCommandReservationDel(deleteArguments, lh.Daemon.Name),
-			App:     lh.Daemon.App,
+			Command:  keactrl.NewCommandReservationDel(deleteArguments, lh.Daemon.Name),
+			App:      lh.Daemon.App,
+			DaemonID: lh.DaemonID,
 		}
 		commands = append(commands, appCommand)
 	}
@@ -467,11 +641,16 @@ func (module *ConfigModule) ApplyHostUpdate(ctx context.Context, host *dbmodel.H
 		}
 		// Create command arguments.
 		appCommand := ConfigCommand{
-			Command: keactrl.NewCommandReservationAdd(reservation, lh.Daemon.Name),
-			App:     lh.Daemon.App,
+			Command:  keactrl.NewCommandReservationAdd(reservation, lh.Daemon.Name),
+			App:      lh.Daemon.App,
+			DaemonID: lh.DaemonID,
 		}
 		commands = append(commands, appCommand)
 	}
+	// Write the configurations of the daemons from which the reservation
+	// has been deleted or to which it has been added.
+	localHosts := append(slices.Clone(existingHost.LocalHosts), host.LocalHosts...)
+	commands = append(commands, createHostPersistConfigCommands(localHosts, config.IsConfigWriteEnabled(ctx))...)
 
 	// Append the local hosts from the configuration file to the edited host.
 	// The edit form doesn't attach them to the data sent to the server.
@@ -543,11 +722,13 @@ func (module *ConfigModule) ApplyHostDelete(ctx context.Context, host *dbmodel.H
 		}
 		// Associate the command with an app receiving this command.
 		appCommand := ConfigCommand{
-			Command: keactrl.NewCommandReservationDel(reservation, lh.Daemon.Name),
-			App:     lh.Daemon.App,
+			Command:  keactrl.NewCommandReservationDel(reservation, lh.Daemon.Name),
+			App:      lh.Daemon.App,
+			DaemonID: lh.DaemonID,
 		}
 		commands = append(commands, appCommand)
 	}
+	commands = append(commands, createHostPersistConfigCommands(host.LocalHosts, config.IsConfigWriteEnabled(ctx))...)
 	daemonIDs, _ := ctx.Value(config.DaemonsContextKey).([]int64)
 	// Create transaction state.
 	state := config.NewTransactionStateWithUpdate[ConfigRecipe]("kea", "host_delete", daemonIDs...)
@@ -623,38 +804,65 @@ func (module *ConfigModule) commitChanges(ctx context.Context) (context.Context,
 	if !ok {
 		return ctx, errors.New("context lacks state")
 	}
+	var commands []ConfigCommand
 	for _, update := range state.Updates {
-		// Retrieve associations between the commands and apps.
-		// Iterate over the associations.
-		for _, acs := range update.Recipe.Commands {
-			// Send the command to Kea.
-			var response keactrl.ResponseList
-			result, err := module.manager.GetConnectedAgents().ForwardToKeaOverHTTP(context.Background(), acs.App, []keactrl.SerializableCommand{acs.Command}, &response)
-			// There was no error in communication between the server and the agent but
-			// the agent could have issues with the Kea response.
-			if err == nil {
-				// Let's check if the agent found errors in communication with Kea.
-				// If not, the individual Kea instances could return error codes as
-				// a result of processing the commands.
-				if err = result.GetFirstError(); err == nil {
-					for _, r := range response {
-						// Let's check if the individual Kea servers returned error
-						// codes for the processed commands.
-						if err = keactrl.GetResponseError(r); err != nil {
-							break
-						}
-					}
-				}
-			}
-			if err != nil {
-				err = errors.WithMessagef(err, "%s command to %s failed", acs.Command.GetCommand(), acs.App.GetName())
-				return ctx, err
+		commands = append(commands, update.Recipe.Commands...)
+	}
+	// The daemons belonging to the same HA relationship are configured
+	// as a unit. Make sure they are all reachable before sending any
+	// changes in the transaction and remember their configurations to
+	// revert the changes if they can't be applied to all of them.
+	pairs, err := module.findHAPairs(commands)
+	if err != nil {
+		return ctx, err
+	}
+	for i := range pairs {
+		if err = module.verifyHAPair(&pairs[i]); err != nil {
+			return ctx, err
+		}
+	}
+	// Commands successfully applied to the daemons in the transaction.
+	applied := make(map[int64][]ConfigCommand)
+	// Retrieve associations between the commands and apps.
+	// Iterate over the associations.
+	for _, acs := range commands {
+		// Send the command to Kea.
+		if _, err = module.sendCommand(acs); err != nil {
+			err = errors.WithMessagef(err, "%s command to %s failed", acs.Command.GetCommand(), acs.App.GetName())
+			if pair := findHAPairByDaemonID(pairs, acs.DaemonID); pair != nil {
+				err = module.revertHAPair(pair, applied, err)
 			}
+			return ctx, err
 		}
+		applied[acs.DaemonID] = append(applied[acs.DaemonID], acs)
 	}
 	return ctx, nil
 }
 
+// Sends the command to Kea and checks whether it has been successfully
+// processed by the daemons. It returns the daemons' responses.
+func (module *ConfigModule) sendCommand(acs ConfigCommand) (keactrl.ResponseList, error) {
+	var response keactrl.ResponseList
+	result, err := module.manager.GetConnectedAgents().ForwardToKeaOverHTTP(context.Background(), acs.App, []keactrl.SerializableCommand{acs.Command}, &response)
+	// There was no error in communication between the server and the agent but
+	// the agent could have issues with the Kea response.
+	if err == nil {
+		// Let's check if the agent found errors in communication with Kea.
+		// If not, the individual Kea instances could return error codes as
+		// a result of processing the commands.
+		if err = result.GetFirstError(); err == nil {
+			for _, r := range response {
+				// Let's check if the individual Kea servers returned error
+				// codes for the processed commands.
+				if err = keactrl.GetResponseError(r); err != nil {
+					break
+				}
+			}
+		}
+	}
+	return response, err
+}
+
 // Begins adding a new shared network. It initializes transaction state.
 func (module *ConfigModule) BeginSharedNetworkAdd(ctx context.Context) (context.Context, error) {
 	// Create transaction state.
@@ -686,8 +894,17 @@ func (module *ConfigModule) ApplySharedNetworkAdd(ctx context.Context, sharedNet
 		}
 		// Convert the shared network information to Kea shared network.
 		lookup := module.manager.GetDHCPOptionDefinitionLookup()
+		if backend := getDaemonConfigBackend(lsn.Daemon); backend != nil {
+			remoteCommands, err := createRemoteSharedNetworkCommands(backend, lsn.Daemon, lookup, sharedNetwork)
+			if err != nil {
+				return ctx, err
+			}
+			commands = append(commands, remoteCommands...)
+			continue
+		}
 		appCommand := ConfigCommand{
-			App: lsn.Daemon.App,
+			App:      lsn.Daemon.App,
+			DaemonID: lsn.DaemonID,
 		}
 		switch sharedNetwork.Family {
 		case 4:
@@ -710,21 +927,7 @@ func (module *ConfigModule) ApplySharedNetworkAdd(ctx context.Context, sharedNet
 	// Create the commands to write the updated configuration to files. The shared network
 	// changes won't persist across the servers' restarts otherwise.
 	for _, lsn := range sharedNetwork.LocalSharedNetworks {
-		commands = append(commands, ConfigCommand{
-			Command: keactrl.NewCommandBase(keactrl.ConfigWrite, lsn.Daemon.Name),
-			App:     lsn.Daemon.App,
-		})
-		// Kea versions up to 2.6.0 do not update statistics after modifying pools with the
-		// subnet_cmds hook library. Therefore, for these versions we send the config-reload
-		// command to force the statistics update. There is no lighter command to force the
-		// statistics update unfortunately.
-		version := storkutil.ParseSemanticVersionOrLatest(lsn.Daemon.Version)
-		if version.LessThan(storkutil.NewSemanticVersion(2, 6, 0)) {
-			commands = append(commands, ConfigCommand{
-				Command: keactrl.NewCommandBase(keactrl.ConfigReload, lsn.Daemon.Name),
-				App:     lsn.Daemon.App,
-			})
-		}
+		commands = append(commands, createPersistConfigCommands(lsn.Daemon, true, config.IsConfigWriteEnabled(ctx))...)
 	}
 
 	// Store the data in the existing recipe.
@@ -791,7 +994,7 @@ func (module *ConfigModule) BeginSharedNetworkUpdate(ctx context.Context, shared
 		if ls.Daemon.KeaDaemon.Config == nil {
 			return ctx, errors.Errorf("configuration not found for daemon %d", ls.DaemonID)
 		}
-		if _, _, exists := ls.Daemon.KeaDaemon.Config.GetHookLibrary("libdhcp_subnet_cmds"); !exists {
+		if !hasSubnetCommands(ls.Daemon) {
 			return ctx, errors.WithStack(config.NewNoSubnetCmdsHookError())
 		}
 		daemonIDs = append(daemonIDs, ls.DaemonID)
@@ -843,12 +1046,24 @@ func (module *ConfigModule) ApplySharedNetworkUpdate(ctx context.Context, shared
 		}
 		// Convert the updated shared network information to Kea shared network.
 		lookup := module.manager.GetDHCPOptionDefinitionLookup()
+		// The remote-network4-set and remote-network6-set commands replace the
+		// shared network in the configuration backend. There is no need to
+		// delete it first.
+		if backend := getDaemonConfigBackend(lsn.Daemon); backend != nil {
+			remoteCommands, err := createRemoteSharedNetworkCommands(backend, lsn.Daemon, lookup, sharedNetwork)
+			if err != nil {
+				return ctx, err
+			}
+			commands = append(commands, remoteCommands...)
+			continue
+		}
 		appCommand := ConfigCommand{}
 		switch sharedNetwork.Family {
 		case 4:
 			deletedSharedNetwork4 := keaconfig.CreateSubnetCmdsDeletedSharedNetwork(lsn.DaemonID, existingSharedNetwork, keaconfig.SharedNetworkSubnetsActionDelete)
 			appCommand.Command = keactrl.NewCommandNetwork4Del(deletedSharedNetwork4, lsn.Daemon.Name)
 			appCommand.App = lsn.Daemon.App
+			appCommand.DaemonID = lsn.DaemonID
 			commands = append(commands, appCommand)
 
 			sharedNetwork4, err := keaconfig.CreateSharedNetwork4(lsn.DaemonID, lookup, sharedNetwork)
@@ -862,6 +1077,7 @@ func (module *ConfigModule) ApplySharedNetworkUpdate(ctx context.Context, shared
 			deletedSharedNetwork6 := keaconfig.CreateSubnetCmdsDeletedSharedNetwork(lsn.DaemonID, existingSharedNetwork, keaconfig.SharedNetworkSubnetsActionDelete)
 			appCommand.Command = keactrl.NewCommandNetwork6Del(deletedSharedNetwork6, lsn.Daemon.Name)
 			appCommand.App = lsn.Daemon.App
+			appCommand.DaemonID = lsn.DaemonID
 			commands = append(commands, appCommand)
 
 			sharedNetwork6, err := keaconfig.CreateSharedNetwork6(lsn.DaemonID, lookup, sharedNetwork)
@@ -887,12 +1103,15 @@ func (module *ConfigModule) ApplySharedNetworkUpdate(ctx context.Context, shared
 		if deletedLocalSharedNetwork != nil {
 			appCommand := ConfigCommand{}
 			deletedKeaSharedNetwork := keaconfig.CreateSubnetCmdsDeletedSharedNetwork(deletedLocalSharedNetwork.DaemonID, existingSharedNetwork, keaconfig.SharedNetworkSubnetsActionDelete)
-			if sharedNetwork.Family == 6 {
+			if backend := getDaemonConfigBackend(deletedLocalSharedNetwork.Daemon); backend != nil {
+				appCommand.Command = keactrl.NewCommandRemoteNetworkDel(sharedNetwork.Family, backend.remote, deletedKeaSharedNetwork.Name, deletedKeaSharedNetwork.SubnetsAction, deletedLocalSharedNetwork.Daemon.Name)
+			} else if sharedNetwork.Family == 6 {
 				appCommand.Command = keactrl.NewCommandNetwork6Del(deletedKeaSharedNetwork, deletedLocalSharedNetwork.Daemon.Name)
 			} else {
 				appCommand.Command = keactrl.NewCommandNetwork4Del(deletedKeaSharedNetwork, deletedLocalSharedNetwork.Daemon.Name)
 			}
 			appCommand.App = deletedLocalSharedNetwork.Daemon.App
+			appCommand.DaemonID = deletedLocalSharedNetwork.DaemonID
 			commands = append(commands, appCommand)
 			deletedLocalSharedNetworks = append(deletedLocalSharedNetworks, deletedLocalSharedNetwork)
 		}
@@ -900,21 +1119,7 @@ func (module *ConfigModule) ApplySharedNetworkUpdate(ctx context.Context, shared
 	// Create the commands to write the updated configuration to files. The shared network
 	// changes won't persist across the servers' restarts otherwise.
 	for _, lsn := range append(sharedNetwork.LocalSharedNetworks, deletedLocalSharedNetworks...) {
-		commands = append(commands, ConfigCommand{
-			Command: keactrl.NewCommandBase(keactrl.ConfigWrite, lsn.Daemon.Name),
-			App:     lsn.Daemon.App,
-		})
-		// Kea versions up to 2.6.0 do not update statistics after modifying pools with the
-		// subnet_cmds hook library. Therefore, for these versions we send the config-reload
-		// command to force the statistics update. There is no lighter command to force the
-		// statistics update unfortunately.
-		version := storkutil.ParseSemanticVersionOrLatest(lsn.Daemon.Version)
-		if version.LessThan(storkutil.NewSemanticVersion(2, 6, 0)) {
-			commands = append(commands, ConfigCommand{
-				Command: keactrl.NewCommandBase(keactrl.ConfigReload, lsn.Daemon.Name),
-				App:     lsn.Daemon.App,
-			})
-		}
+		commands = append(commands, createPersistConfigCommands(lsn.Daemon, true, config.IsConfigWriteEnabled(ctx))...)
 	}
 
 	// Store the data in the existing recipe.
@@ -982,21 +1187,21 @@ func (module *ConfigModule) ApplySharedNetworkDelete(ctx context.Context, shared
 		arguments := deletedSharedNetwork
 		// Associate the command with an app receiving this command.
 		appCommand := ConfigCommand{}
-		switch sharedNetwork.Family {
-		case 4:
+		switch backend := getDaemonConfigBackend(lsn.Daemon); {
+		case backend != nil:
+			appCommand.Command = keactrl.NewCommandRemoteNetworkDel(sharedNetwork.Family, backend.remote, arguments.Name, arguments.SubnetsAction, lsn.Daemon.Name)
+		case sharedNetwork.Family == 4:
 			appCommand.Command = keactrl.NewCommandNetwork4Del(arguments, lsn.Daemon.Name)
 		default:
 			appCommand.Command = keactrl.NewCommandNetwork6Del(arguments, lsn.Daemon.Name)
 		}
 		appCommand.App = lsn.Daemon.App
+		appCommand.DaemonID = lsn.DaemonID
 		commands = append(commands, appCommand)
 	}
 	// Persist the configuration changes.
 	for _, ls := range sharedNetwork.LocalSharedNetworks {
-		commands = append(commands, ConfigCommand{
-			Command: keactrl.NewCommandBase(keactrl.ConfigWrite, ls.Daemon.Name),
-			App:     ls.Daemon.App,
-		})
+		commands = append(commands, createPersistConfigCommands(ls.Daemon, false, config.IsConfigWriteEnabled(ctx))...)
 	}
 	daemonIDs, _ := ctx.Value(config.DaemonsContextKey).([]int64)
 	// Create transaction state.
@@ -1077,6 +1282,16 @@ func (module *ConfigModule) ApplySubnetAdd(ctx context.Context, subnet *dbmodel.
 		}
 		// Convert the updated subnet information to Kea subnet.
 		lookup := module.manager.GetDHCPOptionDefinitionLookup()
+		// The subnet and its association with the shared network are stored
+		// in the configuration backend with a single command.
+		if backend := getDaemonConfigBackend(ls.Daemon); backend != nil {
+			appCommand, err := createRemoteSubnetCommand(backend, ls.Daemon, lookup, subnet, sharedNetworkNameAfterUpdate)
+			if err != nil {
+				return ctx, err
+			}
+			commands = append(commands, appCommand)
+			continue
+		}
 		appCommand := ConfigCommand{}
 		switch subnet.GetFamily() {
 		case 4:
@@ -1087,6 +1302,7 @@ func (module *ConfigModule) ApplySubnetAdd(ctx context.Context, subnet *dbmodel.
 			}
 			appCommand.Command = keactrl.NewCommandSubnet4Add(subnet4, ls.Daemon.Name)
 			appCommand.App = ls.Daemon.App
+			appCommand.DaemonID = ls.DaemonID
 			commands = append(commands, appCommand)
 
 			// If the subnet is associated with a shared network, add this association
@@ -1103,6 +1319,7 @@ func (module *ConfigModule) ApplySubnetAdd(ctx context.Context, subnet *dbmodel.
 			}
 			appCommand.Command = keactrl.NewCommandSubnet6Add(subnet6, ls.Daemon.Name)
 			appCommand.App = ls.Daemon.App
+			appCommand.DaemonID = ls.DaemonID
 			commands = append(commands, appCommand)
 
 			// If the subnet is associated with a new shared network, add this association
@@ -1116,21 +1333,7 @@ func (module *ConfigModule) ApplySubnetAdd(ctx context.Context, subnet *dbmodel.
 	// Create the commands to write the updated configuration to files. The subnet
 	// changes won't persist across the servers' restarts otherwise.
 	for _, ls := range subnet.LocalSubnets {
-		commands = append(commands, ConfigCommand{
-			Command: keactrl.NewCommandBase(keactrl.ConfigWrite, ls.Daemon.Name),
-			App:     ls.Daemon.App,
-		})
-		// Kea versions up to 2.6.0 do not update statistics after modifying pools with the
-		// subnet_cmds hook library. Therefore, for these versions we send the config-reload
-		// command to force the statistics update. There is no lighter command to force the
-		// statistics update unfortunately.
-		version := storkutil.ParseSemanticVersionOrLatest(ls.Daemon.Version)
-		if version.LessThan(storkutil.NewSemanticVersion(2, 6, 0)) {
-			commands = append(commands, ConfigCommand{
-				Command: keactrl.NewCommandBase(keactrl.ConfigReload, ls.Daemon.Name),
-				App:     ls.Daemon.App,
-			})
-		}
+		commands = append(commands, createPersistConfigCommands(ls.Daemon, true, config.IsConfigWriteEnabled(ctx))...)
 	}
 
 	// Store the data in the recipe.
@@ -1201,7 +1404,7 @@ func (module *ConfigModule) BeginSubnetUpdate(ctx context.Context, subnetID int6
 		if ls.Daemon.KeaDaemon.Config == nil {
 			return ctx, errors.Errorf("configuration not found for daemon %d", ls.DaemonID)
 		}
-		if _, _, exists := ls.Daemon.KeaDaemon.Config.GetHookLibrary("libdhcp_subnet_cmds"); !exists {
+		if !hasSubnetCommands(ls.Daemon) {
 			return ctx, errors.WithStack(config.NewNoSubnetCmdsHookError())
 		}
 		daemonIDs = append(daemonIDs, ls.DaemonID)
@@ -1271,6 +1474,17 @@ func (module *ConfigModule) ApplySubnetUpdate(ctx context.Context, subnet *dbmod
 		}
 		// Convert the updated subnet information to Kea subnet.
 		lookup := module.manager.GetDHCPOptionDefinitionLookup()
+		// The remote-subnet4-set and remote-subnet6-set commands add or replace
+		// the subnet in the configuration backend, including its association
+		// with the shared network.
+		if backend := getDaemonConfigBackend(ls.Daemon); backend != nil {
+			appCommand, err := createRemoteSubnetCommand(backend, ls.Daemon, lookup, subnet, sharedNetworkNameAfterUpdate)
+			if err != nil {
+				return ctx, err
+			}
+			commands = append(commands, appCommand)
+			continue
+		}
 		appCommand := ConfigCommand{}
 		switch subnet.GetFamily() {
 		case 4:
@@ -1286,6 +1500,7 @@ func (module *ConfigModule) ApplySubnetUpdate(ctx context.Context, subnet *dbmod
 				appCommand.Command = keactrl.NewCommandSubnet4Add(subnet4, ls.Daemon.Name)
 			}
 			appCommand.App = ls.Daemon.App
+			appCommand.DaemonID = ls.DaemonID
 			commands = append(commands, appCommand)
 
 			// If the association of the subnet with the shared network hasn't changed we
@@ -1320,6 +1535,7 @@ func (module *ConfigModule) ApplySubnetUpdate(ctx context.Context, subnet *dbmod
 				appCommand.Command = keactrl.NewCommandSubnet6Add(subnet6, ls.Daemon.Name)
 			}
 			appCommand.App = ls.Daemon.App
+			appCommand.DaemonID = ls.DaemonID
 			commands = append(commands, appCommand)
 
 			if sharedNetworkNameBeforeUpdate == sharedNetworkNameAfterUpdate {
@@ -1354,41 +1570,37 @@ func (module *ConfigModule) ApplySubnetUpdate(ctx context.Context, subnet *dbmod
 			}
 		}
 		if removedLocalSubnet != nil {
+			removedLocalSubnets = append(removedLocalSubnets, removedLocalSubnet)
+			if backend := getDaemonConfigBackend(removedLocalSubnet.Daemon); backend != nil {
+				commands = append(commands, ConfigCommand{
+					Command:  keactrl.NewCommandRemoteSubnetDelByID(subnet.GetFamily(), backend.remote, removedLocalSubnet.LocalSubnetID, removedLocalSubnet.Daemon.Name),
+					App:      removedLocalSubnet.Daemon.App,
+					DaemonID: removedLocalSubnet.DaemonID,
+				})
+				continue
+			}
 			if sharedNetworkNameBeforeUpdate != "" {
 				// If the deleted subnet belongs to a shared network we first need to remove
 				// this subnet from a shared network. This is a limitation of Kea 2.6.0.
 				commands = append(commands, ConfigCommand{
-					Command: keactrl.NewCommandNetworkSubnetDel(subnet.GetFamily(), sharedNetworkNameBeforeUpdate, removedLocalSubnet.LocalSubnetID, removedLocalSubnet.Daemon.Name),
-					App:     removedLocalSubnet.Daemon.App,
+					Command:  keactrl.NewCommandNetworkSubnetDel(subnet.GetFamily(), sharedNetworkNameBeforeUpdate, removedLocalSubnet.LocalSubnetID, removedLocalSubnet.Daemon.Name),
+					App:      removedLocalSubnet.Daemon.App,
+					DaemonID: removedLocalSubnet.DaemonID,
 				})
 			}
 			// Delete the subnet.
 			commands = append(commands, ConfigCommand{
-				Command: keactrl.NewCommandSubnetDel(subnet.GetFamily(), &keaconfig.SubnetCmdsDeletedSubnet{ID: removedLocalSubnet.LocalSubnetID}, removedLocalSubnet.Daemon.Name),
-				App:     removedLocalSubnet.Daemon.App,
+				Command:  keactrl.NewCommandSubnetDel(subnet.GetFamily(), &keaconfig.SubnetCmdsDeletedSubnet{ID: removedLocalSubnet.LocalSubnetID}, removedLocalSubnet.Daemon.Name),
+				App:      removedLocalSubnet.Daemon.App,
+				DaemonID: removedLocalSubnet.DaemonID,
 			})
-			removedLocalSubnets = append(removedLocalSubnets, removedLocalSubnet)
 		}
 	}
 
 	// Create the commands to write the updated configuration to files. The subnet
 	// changes won't persist across the servers' restarts otherwise.
 	for _, ls := range append(subnet.LocalSubnets, removedLocalSubnets...) {
-		commands = append(commands, ConfigCommand{
-			Command: keactrl.NewCommandBase(keactrl.ConfigWrite, ls.Daemon.Name),
-			App:     ls.Daemon.App,
-		})
-		// Kea versions up to 2.6.0 do not update statistics after modifying pools with the
-		// subnet_cmds hook library. Therefore, for these versions we send the config-reload
-		// command to force the statistics update. There is no lighter command to force the
-		// statistics update unfortunately.
-		version := storkutil.ParseSemanticVersionOrLatest(ls.Daemon.Version)
-		if version.LessThan(storkutil.NewSemanticVersion(2, 6, 0)) {
-			commands = append(commands, ConfigCommand{
-				Command: keactrl.NewCommandBase(keactrl.ConfigReload, ls.Daemon.Name),
-				App:     ls.Daemon.App,
-			})
-		}
+		commands = append(commands, createPersistConfigCommands(ls.Daemon, true, config.IsConfigWriteEnabled(ctx))...)
 	}
 
 	// Store the data in the existing recipe.
@@ -1439,27 +1651,34 @@ func (module *ConfigModule) ApplySubnetDelete(ctx context.Context, subnet *dbmod
 		if err != nil {
 			return ctx, err
 		}
+		if backend := getDaemonConfigBackend(ls.Daemon); backend != nil {
+			commands = append(commands, ConfigCommand{
+				Command:  keactrl.NewCommandRemoteSubnetDelByID(subnet.GetFamily(), backend.remote, deletedSubnet.ID, ls.Daemon.Name),
+				App:      ls.Daemon.App,
+				DaemonID: ls.DaemonID,
+			})
+			continue
+		}
 		// If the deleted subnet belongs to a shared network we first need to remove
 		// this subnet from a shared network. This is a Kea limitation described in
 		// https://gitlab.isc.org/isc-projects/kea/-/issues/3455.
 		if subnet.SharedNetwork != nil && subnet.SharedNetwork.Name != "" {
 			commands = append(commands, ConfigCommand{
-				Command: keactrl.NewCommandNetworkSubnetDel(subnet.GetFamily(), subnet.SharedNetwork.Name, ls.LocalSubnetID, ls.Daemon.Name),
-				App:     ls.Daemon.App,
+				Command:  keactrl.NewCommandNetworkSubnetDel(subnet.GetFamily(), subnet.SharedNetwork.Name, ls.LocalSubnetID, ls.Daemon.Name),
+				App:      ls.Daemon.App,
+				DaemonID: ls.DaemonID,
 			})
 		}
 		// Delete the subnet.
 		commands = append(commands, ConfigCommand{
-			Command: keactrl.NewCommandSubnetDel(subnet.GetFamily(), deletedSubnet, ls.Daemon.Name),
-			App:     ls.Daemon.App,
+			Command:  keactrl.NewCommandSubnetDel(subnet.GetFamily(), deletedSubnet, ls.Daemon.Name),
+			App:      ls.Daemon.App,
+			DaemonID: ls.DaemonID,
 		})
 	}
 	// Persist the configuration changes.
 	for _, ls := range subnet.LocalSubnets {
-		commands = append(commands, ConfigCommand{
-			Command: keactrl.NewCommandBase(keactrl.ConfigWrite, ls.Daemon.Name),
-			App:     ls.Daemon.App,
-		})
+		commands = append(commands, createPersistConfigCommands(ls.Daemon, false, config.IsConfigWriteEnabled(ctx))...)
 	}
 	daemonIDs, _ := ctx.Value(config.DaemonsContextKey).([]int64)
 	// Create transaction state.
@@ -1499,3 +1718,978 @@ func (module *ConfigModule) commitSubnetDelete(ctx context.Context) (context.Con
 	}
 	return ctx, nil
 }
+
+// Begins adding a new client class. It initializes transaction state.
+func (module *ConfigModule) BeginClientClassAdd(ctx context.Context) (context.Context, error) {
+	// Create transaction state.
+	state := config.NewTransactionStateWithUpdate[ConfigRecipe]("kea", "client_class_add")
+	ctx = context.WithValue(ctx, config.StateContextKey, *state)
+	return ctx, nil
+}
+
+// Applies new client class to the specified daemons. It prepares necessary
+// commands to be sent to Kea upon commit. The class-add command is used
+// for the daemons with the libdhcp_class_cmds hook library. The config-set
+// command is sent to the remaining daemons. The daemons' configurations are
+// modified to include the new class, so they can be stored in the database
+// after the commit.
+func (module *ConfigModule) ApplyClientClassAdd(ctx context.Context, clientClass *keaconfig.ClientClass, daemons []dbmodel.Daemon) (context.Context, error) {
+	if len(daemons) == 0 {
+		return ctx, errors.Errorf("applied client class %s is not associated with any daemon", clientClass.Name)
+	}
+	recipe, err := config.GetRecipeForUpdate[ConfigRecipe](ctx, 0)
+	if err != nil {
+		return ctx, err
+	}
+	// Keep the original configurations to show the differences in the preview.
+	recipe.KeaDaemonsBeforeConfigUpdate = daemons
+	daemons, err = copyDaemonsWithConfigs(daemons)
+	if err != nil {
+		return ctx, err
+	}
+	var commands []ConfigCommand
+	for _, daemon := range daemons {
+		if err := validateClientClassDaemon(clientClass.Name, &daemon); err != nil {
+			return ctx, err
+		}
+		if daemon.KeaDaemon.Config.GetClientClass(clientClass.Name) != nil {
+			return ctx, errors.WithStack(config.NewClientClassExistsError(clientClass.Name, daemon.ID))
+		}
+		if err := daemon.KeaDaemon.Config.AddClientClass(clientClass); err != nil {
+			return ctx, err
+		}
+		classCommand := keactrl.NewCommandClassAdd(clientClass, daemon.Name)
+		if backend := getDaemonConfigBackend(&daemon); backend != nil {
+			classCommand = keactrl.NewCommandRemoteClassSet(backend.family, backend.remote, backend.serverTag, clientClass, daemon.Name)
+		}
+		commands = append(commands, createClientClassCommand(&daemon, classCommand))
+	}
+	commands = append(commands, createConfigWriteCommands(daemons, config.IsConfigWriteEnabled(ctx))...)
+
+	// Store the data in the recipe.
+	recipe.ClientClassAfterUpdate = clientClass
+	recipe.ClientClassName = storkutil.Ptr(clientClass.Name)
+	recipe.KeaDaemonsAfterConfigUpdate = daemons
+	recipe.Commands = commands
+	return config.SetRecipeForUpdate(ctx, 0, recipe)
+}
+
+// Begins a client class update. It fetches the specified daemons from the
+// database and checks that the class exists in their configurations. Then,
+// it locks the daemons' configurations for updates.
+func (module *ConfigModule) BeginClientClassUpdate(ctx context.Context, name string, daemonIDs []int64) (context.Context, error) {
+	// Get the daemons with their configurations from the database.
+	daemons, err := dbmodel.GetDaemonsByIDs(module.manager.GetDB(), daemonIDs)
+	if err != nil {
+		// Internal database error.
+		return ctx, err
+	}
+	// Some daemons do not exist.
+	if len(daemons) != len(daemonIDs) || len(daemonIDs) == 0 {
+		return ctx, errors.WithStack(config.NewSomeDaemonsNotFoundError(daemonIDs...))
+	}
+	for _, daemon := range daemons {
+		if err := validateClientClassDaemon(name, &daemon); err != nil {
+			return ctx, err
+		}
+		if daemon.KeaDaemon.Config.GetClientClass(name) == nil {
+			return ctx, errors.WithStack(config.NewClientClassNotFoundError(name, daemon.ID))
+		}
+	}
+	// Try to lock configurations.
+	ctx, err = module.manager.Lock(ctx, daemonIDs...)
+	if err != nil {
+		return ctx, errors.WithStack(config.NewLockError())
+	}
+	// Create transaction state.
+	state := config.NewTransactionStateWithUpdate[ConfigRecipe]("kea", "client_class_update", daemonIDs...)
+	recipe := &ConfigRecipe{
+		GlobalConfigRecipeParams: GlobalConfigRecipeParams{
+			KeaDaemonsBeforeConfigUpdate: daemons,
+		},
+		ClientClassConfigRecipeParams: ClientClassConfigRecipeParams{
+			ClientClassName: storkutil.Ptr(name),
+		},
+	}
+	if err := state.SetRecipeForUpdate(0, recipe); err != nil {
+		return ctx, err
+	}
+	ctx = context.WithValue(ctx, config.StateContextKey, *state)
+	return ctx, nil
+}
+
+// Applies updated client class. It prepares necessary commands to be sent to
+// Kea upon commit. Renaming the class is not supported because the class-update
+// command finds the updated class by name.
+func (module *ConfigModule) ApplyClientClassUpdate(ctx context.Context, clientClass *keaconfig.ClientClass) (context.Context, error) {
+	recipe, err := config.GetRecipeForUpdate[ConfigRecipe](ctx, 0)
+	if err != nil {
+		return ctx, err
+	}
+	if recipe.KeaDaemonsBeforeConfigUpdate == nil || recipe.ClientClassName == nil {
+		return ctx, errors.New("internal server error - existing Kea configs and client class name cannot be nil when applying client class update")
+	}
+	if clientClass.Name != *recipe.ClientClassName {
+		return ctx, errors.Errorf("renaming client class %s to %s is not supported", *recipe.ClientClassName, clientClass.Name)
+	}
+	// Work on the copies of the configurations to keep the original
+	// configurations intact.
+	daemons, err := copyDaemonsWithConfigs(recipe.KeaDaemonsBeforeConfigUpdate)
+	if err != nil {
+		return ctx, err
+	}
+	var commands []ConfigCommand
+	for _, daemon := range daemons {
+		if err := validateClientClassDaemon(clientClass.Name, &daemon); err != nil {
+			return ctx, err
+		}
+		if daemon.KeaDaemon.Config.GetClientClass(clientClass.Name) == nil {
+			return ctx, errors.WithStack(config.NewClientClassNotFoundError(clientClass.Name, daemon.ID))
+		}
+		if err := daemon.KeaDaemon.Config.UpdateClientClass(clientClass); err != nil {
+			return ctx, err
+		}
+		classCommand := keactrl.NewCommandClassUpdate(clientClass, daemon.Name)
+		if backend := getDaemonConfigBackend(&daemon); backend != nil {
+			classCommand = keactrl.NewCommandRemoteClassSet(backend.family, backend.remote, backend.serverTag, clientClass, daemon.Name)
+		}
+		commands = append(commands, createClientClassCommand(&daemon, classCommand))
+	}
+	commands = append(commands, createConfigWriteCommands(daemons, config.IsConfigWriteEnabled(ctx))...)
+
+	// Store the data in the recipe.
+	recipe.ClientClassAfterUpdate = clientClass
+	recipe.KeaDaemonsAfterConfigUpdate = daemons
+	recipe.Commands = commands
+	return config.SetRecipeForUpdate(ctx, 0, recipe)
+}
+
+// Creates requests to delete a client class from the specified daemons. It
+// prepares necessary commands to be sent to Kea upon commit.
+func (module *ConfigModule) ApplyClientClassDelete(ctx context.Context, name string, daemons []dbmodel.Daemon) (context.Context, error) {
+	if len(daemons) == 0 {
+		return ctx, errors.Errorf("deleted client class %s is not associated with any daemon", name)
+	}
+	// Keep the original configurations to show the differences in the preview.
+	daemonsBeforeUpdate := daemons
+	daemons, err := copyDaemonsWithConfigs(daemons)
+	if err != nil {
+		return ctx, err
+	}
+	var (
+		commands  []ConfigCommand
+		daemonIDs []int64
+	)
+	for _, daemon := range daemons {
+		if err := validateClientClassDaemon(name, &daemon); err != nil {
+			return ctx, err
+		}
+		if daemon.KeaDaemon.Config.GetClientClass(name) == nil {
+			return ctx, errors.WithStack(config.NewClientClassNotFoundError(name, daemon.ID))
+		}
+		if err := daemon.KeaDaemon.Config.DeleteClientClass(name); err != nil {
+			return ctx, err
+		}
+		classCommand := keactrl.NewCommandClassDel(name, daemon.Name)
+		if backend := getDaemonConfigBackend(&daemon); backend != nil {
+			classCommand = keactrl.NewCommandRemoteClassDel(backend.family, backend.remote, name, daemon.Name)
+		}
+		commands = append(commands, createClientClassCommand(&daemon, classCommand))
+		daemonIDs = append(daemonIDs, daemon.ID)
+	}
+	commands = append(commands, createConfigWriteCommands(daemons, config.IsConfigWriteEnabled(ctx))...)
+
+	// Create transaction state.
+	state := config.NewTransactionStateWithUpdate[ConfigRecipe]("kea", "client_class_delete", daemonIDs...)
+	recipe := ConfigRecipe{
+		Commands: commands,
+		GlobalConfigRecipeParams: GlobalConfigRecipeParams{
+			KeaDaemonsBeforeConfigUpdate: daemonsBeforeUpdate,
+			KeaDaemonsAfterConfigUpdate:  daemons,
+		},
+		ClientClassConfigRecipeParams: ClientClassConfigRecipeParams{
+			ClientClassName: storkutil.Ptr(name),
+		},
+	}
+	if err := state.SetRecipeForUpdate(0, &recipe); err != nil {
+		return ctx, err
+	}
+	ctx = context.WithValue(ctx, config.StateContextKey, *state)
+	return ctx, nil
+}
+
+// Sends commands to Kea to add, update or delete a configuration element
+// (e.g., a client class) held in the daemons' configurations. It also updates
+// the respective configurations in the Stork database. The element name is
+// used in the error messages.
+func (module *ConfigModule) commitDaemonConfigChanges(ctx context.Context, element string) (context.Context, error) {
+	state, ok := config.GetTransactionState[ConfigRecipe](ctx)
+	if !ok {
+		return ctx, errors.New("context lacks state")
+	}
+	var err error
+	ctx, err = module.commitChanges(ctx)
+	if err != nil {
+		return ctx, err
+	}
+	for _, update := range state.Updates {
+		if update.Recipe.KeaDaemonsAfterConfigUpdate == nil {
+			return ctx, errors.Errorf("server logic error: the update.Recipe.KeaDaemonsAfterConfigUpdate cannot be nil when committing the %s changes", element)
+		}
+		err = module.manager.GetDB().RunInTransaction(context.Background(), func(tx *pg.Tx) error {
+			for _, daemon := range update.Recipe.KeaDaemonsAfterConfigUpdate {
+				if err := dbmodel.UpdateDaemon(tx, &daemon); err != nil {
+					return err
+				}
+				// Keep the DDNS domains of the D2 daemon in sync with
+				// its configuration.
+				if err := dbmodel.CommitDDNSDomainsFromDaemon(tx, &daemon); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return ctx, errors.WithMessagef(err, "%s has been successfully updated in Kea but updating the configuration in the Stork database failed", element)
+		}
+	}
+	return ctx, nil
+}
+
+// Checks that the daemon has the configuration and the app which are
+// required to manage the client classes.
+func validateClientClassDaemon(name string, daemon *dbmodel.Daemon) error {
+	if daemon.KeaDaemon == nil || daemon.KeaDaemon.Config == nil {
+		return errors.Errorf("configuration not found for daemon %d when modifying client class %s", daemon.ID, name)
+	}
+	if daemon.App == nil {
+		return errors.Errorf("client class %s is associated with daemon %d having nil app", name, daemon.ID)
+	}
+	return nil
+}
+
+// Returns a command to be sent to the daemon to apply the client class
+// change. If the daemon has the libdhcp_class_cmds hook library or uses
+// the configuration backend the specified command is returned. Otherwise,
+// the config-set command with the daemon's configuration is returned. The
+// daemon's configuration must already include the change.
+func createClientClassCommand(daemon *dbmodel.Daemon, classCommand *keactrl.Command) ConfigCommand {
+	command := ConfigCommand{
+		Command:  classCommand,
+		App:      daemon.App,
+		DaemonID: daemon.ID,
+	}
+	if getDaemonConfigBackend(daemon) != nil {
+		return command
+	}
+	if _, _, exists := daemon.KeaDaemon.Config.GetHookLibrary("libdhcp_class_cmds"); !exists {
+		command.Command = keactrl.NewCommandConfigSet(daemon.KeaDaemon.Config.Config, daemon.Name)
+	}
+	return command
+}
+
+// Creates the commands to write the updated configuration to files. The
+// changes won't persist across the servers' restarts otherwise. The daemons
+// using the configuration backend are instructed to fetch the changes from
+// the database instead. The writeConfig flag indicates whether the changes
+// should be written to the files.
+func createConfigWriteCommands(daemons []dbmodel.Daemon, writeConfig bool) (commands []ConfigCommand) {
+	for _, daemon := range daemons {
+		commands = append(commands, createPersistConfigCommands(&daemon, false, writeConfig)...)
+	}
+	return
+}
+
+// Begins adding a new option definition. It initializes transaction state.
+func (module *ConfigModule) BeginOptionDefAdd(ctx context.Context) (context.Context, error) {
+	// Create transaction state.
+	state := config.NewTransactionStateWithUpdate[ConfigRecipe]("kea", "option_def_add")
+	ctx = context.WithValue(ctx, config.StateContextKey, *state)
+	return ctx, nil
+}
+
+// Applies new option definition to the specified daemons. The definition
+// is validated against the standard option definitions of each daemon's
+// universe, so it never redefines a standard option. The daemons using the
+// configuration backend receive the remote-option-def4-set or
+// remote-option-def6-set command followed by the config-backend-pull
+// command. The other daemons receive the config-set command, followed by
+// the config-write command.
+func (module *ConfigModule) ApplyOptionDefAdd(ctx context.Context, optionDef *keaconfig.OptionDef, daemons []dbmodel.Daemon) (context.Context, error) {
+	if len(daemons) == 0 {
+		return ctx, errors.Errorf("applied option definition %s is not associated with any daemon", optionDef.Name)
+	}
+	recipe, err := config.GetRecipeForUpdate[ConfigRecipe](ctx, 0)
+	if err != nil {
+		return ctx, err
+	}
+	// Keep the original configurations to show the differences in the preview.
+	recipe.KeaDaemonsBeforeConfigUpdate = daemons
+	daemons, err = copyDaemonsWithConfigs(daemons)
+	if err != nil {
+		return ctx, err
+	}
+	var commands []ConfigCommand
+	for _, daemon := range daemons {
+		if err := validateOptionDefDaemon(optionDef, &daemon); err != nil {
+			return ctx, err
+		}
+		if daemon.KeaDaemon.Config.GetOptionDef(optionDef.Code, optionDef.Space) != nil {
+			return ctx, errors.WithStack(config.NewOptionDefExistsError(optionDef.Code, optionDef.Space, daemon.ID))
+		}
+		if err := daemon.KeaDaemon.Config.AddOptionDef(optionDef); err != nil {
+			return ctx, err
+		}
+		commands = append(commands, createOptionDefSetCommand(&daemon, optionDef))
+	}
+	commands = append(commands, createConfigWriteCommands(daemons, config.IsConfigWriteEnabled(ctx))...)
+
+	// Store the data in the recipe.
+	recipe.OptionDefAfterUpdate = optionDef
+	recipe.OptionDefCode = storkutil.Ptr(optionDef.Code)
+	recipe.OptionDefSpace = storkutil.Ptr(optionDef.Space)
+	recipe.KeaDaemonsAfterConfigUpdate = daemons
+	recipe.Commands = commands
+	return config.SetRecipeForUpdate(ctx, 0, recipe)
+}
+
+// Begins an option definition update. It fetches the specified daemons from
+// the database and checks that the definition exists in their configurations.
+// Then, it locks the daemons' configurations for updates.
+func (module *ConfigModule) BeginOptionDefUpdate(ctx context.Context, code uint16, space string, daemonIDs []int64) (context.Context, error) {
+	// Get the daemons with their configurations from the database.
+	daemons, err := dbmodel.GetDaemonsByIDs(module.manager.GetDB(), daemonIDs)
+	if err != nil {
+		// Internal database error.
+		return ctx, err
+	}
+	// Some daemons do not exist.
+	if len(daemons) != len(daemonIDs) || len(daemonIDs) == 0 {
+		return ctx, errors.WithStack(config.NewSomeDaemonsNotFoundError(daemonIDs...))
+	}
+	for _, daemon := range daemons {
+		if err := validateOptionDefDaemon(nil, &daemon); err != nil {
+			return ctx, err
+		}
+		if daemon.KeaDaemon.Config.GetOptionDef(code, space) == nil {
+			return ctx, errors.WithStack(config.NewOptionDefNotFoundError(code, space, daemon.ID))
+		}
+	}
+	// Try to lock configurations.
+	ctx, err = module.manager.Lock(ctx, daemonIDs...)
+	if err != nil {
+		return ctx, errors.WithStack(config.NewLockError())
+	}
+	// Create transaction state.
+	state := config.NewTransactionStateWithUpdate[ConfigRecipe]("kea", "option_def_update", daemonIDs...)
+	recipe := &ConfigRecipe{
+		GlobalConfigRecipeParams: GlobalConfigRecipeParams{
+			KeaDaemonsBeforeConfigUpdate: daemons,
+		},
+		OptionDefConfigRecipeParams: OptionDefConfigRecipeParams{
+			OptionDefCode:  storkutil.Ptr(code),
+			OptionDefSpace: storkutil.Ptr(space),
+		},
+	}
+	if err := state.SetRecipeForUpdate(0, recipe); err != nil {
+		return ctx, err
+	}
+	ctx = context.WithValue(ctx, config.StateContextKey, *state)
+	return ctx, nil
+}
+
+// Applies updated option definition. It prepares necessary commands to be
+// sent to Kea upon commit. Changing the option code or space is not supported
+// because these parameters identify the updated definition.
+func (module *ConfigModule) ApplyOptionDefUpdate(ctx context.Context, optionDef *keaconfig.OptionDef) (context.Context, error) {
+	recipe, err := config.GetRecipeForUpdate[ConfigRecipe](ctx, 0)
+	if err != nil {
+		return ctx, err
+	}
+	if recipe.KeaDaemonsBeforeConfigUpdate == nil || recipe.OptionDefCode == nil || recipe.OptionDefSpace == nil {
+		return ctx, errors.New("internal server error - existing Kea configs and option definition code and space cannot be nil when applying option definition update")
+	}
+	if optionDef.Code != *recipe.OptionDefCode || optionDef.Space != *recipe.OptionDefSpace {
+		return ctx, errors.Errorf("changing the code or space of the option definition %s is not supported", optionDef.Name)
+	}
+	// Work on the copies of the configurations to keep the original
+	// configurations intact.
+	daemons, err := copyDaemonsWithConfigs(recipe.KeaDaemonsBeforeConfigUpdate)
+	if err != nil {
+		return ctx, err
+	}
+	var commands []ConfigCommand
+	for _, daemon := range daemons {
+		if err := validateOptionDefDaemon(optionDef, &daemon); err != nil {
+			return ctx, err
+		}
+		if daemon.KeaDaemon.Config.GetOptionDef(optionDef.Code, optionDef.Space) == nil {
+			return ctx, errors.WithStack(config.NewOptionDefNotFoundError(optionDef.Code, optionDef.Space, daemon.ID))
+		}
+		if err := daemon.KeaDaemon.Config.UpdateOptionDef(optionDef); err != nil {
+			return ctx, err
+		}
+		commands = append(commands, createOptionDefSetCommand(&daemon, optionDef))
+	}
+	commands = append(commands, createConfigWriteCommands(daemons, config.IsConfigWriteEnabled(ctx))...)
+
+	// Store the data in the recipe.
+	recipe.OptionDefAfterUpdate = optionDef
+	recipe.KeaDaemonsAfterConfigUpdate = daemons
+	recipe.Commands = commands
+	return config.SetRecipeForUpdate(ctx, 0, recipe)
+}
+
+// Creates requests to delete an option definition from the specified daemons.
+// It prepares necessary commands to be sent to Kea upon commit.
+func (module *ConfigModule) ApplyOptionDefDelete(ctx context.Context, code uint16, space string, daemons []dbmodel.Daemon) (context.Context, error) {
+	if len(daemons) == 0 {
+		return ctx, errors.Errorf("deleted option definition with code %d is not associated with any daemon", code)
+	}
+	// Keep the original configurations to show the differences in the preview.
+	daemonsBeforeUpdate := daemons
+	daemons, err := copyDaemonsWithConfigs(daemons)
+	if err != nil {
+		return ctx, err
+	}
+	var (
+		commands  []ConfigCommand
+		daemonIDs []int64
+	)
+	for _, daemon := range daemons {
+		if err := validateOptionDefDaemon(nil, &daemon); err != nil {
+			return ctx, err
+		}
+		if daemon.KeaDaemon.Config.GetOptionDef(code, space) == nil {
+			return ctx, errors.WithStack(config.NewOptionDefNotFoundError(code, space, daemon.ID))
+		}
+		if err := daemon.KeaDaemon.Config.DeleteOptionDef(code, space); err != nil {
+			return ctx, err
+		}
+		command := createConfigSetCommand(&daemon)
+		if backend := getDaemonConfigBackend(&daemon); backend != nil {
+			command.Command = keactrl.NewCommandRemoteOptionDefDel(backend.family, backend.remote, backend.serverTag, code, space, daemon.Name)
+		}
+		commands = append(commands, command)
+		daemonIDs = append(daemonIDs, daemon.ID)
+	}
+	commands = append(commands, createConfigWriteCommands(daemons, config.IsConfigWriteEnabled(ctx))...)
+
+	// Create transaction state.
+	state := config.NewTransactionStateWithUpdate[ConfigRecipe]("kea", "option_def_delete", daemonIDs...)
+	recipe := ConfigRecipe{
+		Commands: commands,
+		GlobalConfigRecipeParams: GlobalConfigRecipeParams{
+			KeaDaemonsBeforeConfigUpdate: daemonsBeforeUpdate,
+			KeaDaemonsAfterConfigUpdate:  daemons,
+		},
+		OptionDefConfigRecipeParams: OptionDefConfigRecipeParams{
+			OptionDefCode:  storkutil.Ptr(code),
+			OptionDefSpace: storkutil.Ptr(space),
+		},
+	}
+	if err := state.SetRecipeForUpdate(0, &recipe); err != nil {
+		return ctx, err
+	}
+	ctx = context.WithValue(ctx, config.StateContextKey, *state)
+	return ctx, nil
+}
+
+// Checks that the daemon has the configuration and the app which are
+// required to manage the option definitions. If the option definition is
+// specified, it is also validated for the daemon's universe.
+func validateOptionDefDaemon(optionDef *keaconfig.OptionDef, daemon *dbmodel.Daemon) error {
+	if daemon.KeaDaemon == nil || daemon.KeaDaemon.Config == nil {
+		return errors.Errorf("configuration not found for daemon %d when modifying option definition", daemon.ID)
+	}
+	if daemon.App == nil {
+		return errors.Errorf("option definition is associated with daemon %d having nil app", daemon.ID)
+	}
+	if optionDef == nil {
+		return nil
+	}
+	universe := storkutil.IPv4
+	if daemon.KeaDaemon.Config.IsDHCPv6() {
+		universe = storkutil.IPv6
+	}
+	if err := optionDef.Validate(universe); err != nil {
+		return errors.WithStack(config.NewInvalidOptionDefError(err.Error(), daemon.ID))
+	}
+	return nil
+}
+
+// Returns the config-set command with the daemon's configuration. The
+// daemon's configuration must already include the change.
+func createConfigSetCommand(daemon *dbmodel.Daemon) ConfigCommand {
+	return ConfigCommand{
+		Command:  keactrl.NewCommandConfigSet(daemon.KeaDaemon.Config.Config, daemon.Name),
+		App:      daemon.App,
+		DaemonID: daemon.ID,
+	}
+}
+
+// Returns a command to be sent to the daemon to add or update the option
+// definition. It is the remote-option-def4-set or remote-option-def6-set
+// command for the daemons using the configuration backend. Otherwise, it
+// is the config-set command with the daemon's configuration.
+func createOptionDefSetCommand(daemon *dbmodel.Daemon, optionDef *keaconfig.OptionDef) ConfigCommand {
+	command := createConfigSetCommand(daemon)
+	if backend := getDaemonConfigBackend(daemon); backend != nil {
+		command.Command = keactrl.NewCommandRemoteOptionDefSet(backend.family, backend.remote, backend.serverTag, optionDef, daemon.Name)
+	}
+	return command
+}
+
+// Creates requests to restore the daemon's configuration from the specified
+// configuration revision. It prepares the config-set command with the
+// configuration held in the revision and, optionally, the config-write
+// command persisting the restored configuration in the configuration file.
+// It locks the daemon's configuration, so the rollback doesn't interfere
+// with other configuration updates. The caller must release the lock with
+// the config manager's Done function after committing the changes. The
+// configuration of the daemon using the configuration backend cannot be
+// restored because the config-set command doesn't change the configuration
+// held in the backend.
+func (module *ConfigModule) ApplyConfigRollback(ctx context.Context, daemon dbmodel.Daemon, revision *dbmodel.KeaConfigRevision, writeConfig bool) (context.Context, error) {
+	if revision == nil || revision.Config == nil {
+		return ctx, errors.Errorf("no configuration revision specified for daemon %d", daemon.ID)
+	}
+	if revision.DaemonID != daemon.ID {
+		return ctx, errors.Errorf("configuration revision %d does not belong to daemon %d", revision.ID, daemon.ID)
+	}
+	if daemon.KeaDaemon == nil || daemon.KeaDaemon.Config == nil {
+		return ctx, errors.Errorf("configuration not found for daemon %d when restoring configuration revision %d", daemon.ID, revision.ID)
+	}
+	if daemon.App == nil {
+		return ctx, errors.Errorf("daemon %d has nil app when restoring configuration revision %d", daemon.ID, revision.ID)
+	}
+	if getDaemonConfigBackend(&daemon) != nil {
+		return ctx, errors.Errorf("daemon %d fetches its configuration from the configuration backend and its configuration revision %d cannot be restored", daemon.ID, revision.ID)
+	}
+	// Keep the original configuration to show the differences in the preview.
+	daemonsBeforeUpdate := []dbmodel.Daemon{daemon}
+	daemons, err := copyDaemonsWithConfigs(daemonsBeforeUpdate)
+	if err != nil {
+		return ctx, err
+	}
+	// Try to lock the configuration.
+	ctx, err = module.manager.Lock(ctx, daemon.ID)
+	if err != nil {
+		return ctx, errors.WithStack(config.NewLockError())
+	}
+	// The configuration hash is cleared, so the state puller detects the
+	// configuration change and stores the restored configuration as a new
+	// revision.
+	if err = daemons[0].SetConfig(revision.Config); err != nil {
+		return ctx, err
+	}
+	commands := []ConfigCommand{createConfigSetCommand(&daemons[0])}
+	if writeConfig {
+		commands = append(commands, createConfigWriteCommands(daemons, true)...)
+	}
+
+	// Create transaction state.
+	state := config.NewTransactionStateWithUpdate[ConfigRecipe]("kea", "config_rollback", daemon.ID)
+	recipe := ConfigRecipe{
+		Commands: commands,
+		GlobalConfigRecipeParams: GlobalConfigRecipeParams{
+			KeaDaemonsBeforeConfigUpdate: daemonsBeforeUpdate,
+			KeaDaemonsAfterConfigUpdate:  daemons,
+		},
+	}
+	if err := state.SetRecipeForUpdate(0, &recipe); err != nil {
+		return ctx, err
+	}
+	ctx = context.WithValue(ctx, config.StateContextKey, *state)
+	return ctx, nil
+}
+
+// Creates a request to write the daemon's running configuration to the
+// configuration file. It is used to explicitly persist the configuration
+// changes when they haven't been automatically written upon commit. The
+// daemons using the configuration backend don't hold their configurations
+// in the files, so the configuration cannot be written for them.
+func (module *ConfigModule) ApplyConfigWrite(ctx context.Context, daemon dbmodel.Daemon) (context.Context, error) {
+	if daemon.KeaDaemon == nil || daemon.KeaDaemon.Config == nil {
+		return ctx, errors.Errorf("configuration not found for daemon %d when writing the configuration", daemon.ID)
+	}
+	if daemon.App == nil {
+		return ctx, errors.Errorf("daemon %d has nil app when writing the configuration", daemon.ID)
+	}
+	if getDaemonConfigBackend(&daemon) != nil {
+		return ctx, errors.Errorf("daemon %d fetches its configuration from the configuration backend and its configuration cannot be written to a file", daemon.ID)
+	}
+	state := config.NewTransactionStateWithUpdate[ConfigRecipe]("kea", "config_write", daemon.ID)
+	recipe := ConfigRecipe{
+		Commands: createPersistConfigCommands(&daemon, false, true),
+	}
+	if err := state.SetRecipeForUpdate(0, &recipe); err != nil {
+		return ctx, err
+	}
+	ctx = context.WithValue(ctx, config.StateContextKey, *state)
+	return ctx, nil
+}
+
+// Begins updating the DDNS domains of the D2 daemon. It fetches the daemon
+// from the database and checks that it is a D2 daemon with a configuration.
+// Then, it locks the daemon's configuration for updates. The subsequent
+// calls to ApplyDDNSDomainAdd, ApplyDDNSDomainUpdate and ApplyDDNSDomainDelete
+// accumulate the changes in the transaction.
+func (module *ConfigModule) BeginDDNSDomainsUpdate(ctx context.Context, daemonID int64) (context.Context, error) {
+	daemon, err := dbmodel.GetDaemonByID(module.manager.GetDB(), daemonID)
+	if err != nil {
+		// Internal database error.
+		return ctx, err
+	}
+	if daemon == nil {
+		return ctx, errors.WithStack(config.NewSomeDaemonsNotFoundError(daemonID))
+	}
+	if err := validateDDNSDomainDaemon(daemon); err != nil {
+		return ctx, err
+	}
+	// Try to lock configurations.
+	ctx, err = module.manager.Lock(ctx, daemonID)
+	if err != nil {
+		return ctx, errors.WithStack(config.NewLockError())
+	}
+	// Create transaction state.
+	state := config.NewTransactionStateWithUpdate[ConfigRecipe]("kea", "ddns_domains_update", daemonID)
+	recipe := &ConfigRecipe{
+		GlobalConfigRecipeParams: GlobalConfigRecipeParams{
+			KeaDaemonsBeforeConfigUpdate: []dbmodel.Daemon{*daemon},
+		},
+	}
+	if err := state.SetRecipeForUpdate(0, recipe); err != nil {
+		return ctx, err
+	}
+	ctx = context.WithValue(ctx, config.StateContextKey, *state)
+	return ctx, nil
+}
+
+// Applies a new DDNS domain to the D2 daemon's configuration.
+func (module *ConfigModule) ApplyDDNSDomainAdd(ctx context.Context, direction keaconfig.D2DDNSDirection, domain *keaconfig.D2DDNSDomain) (context.Context, error) {
+	return module.applyDDNSDomainChange(ctx, func(daemon *dbmodel.Daemon) error {
+		if err := validateDDNSDomain(daemon, direction, domain); err != nil {
+			return err
+		}
+		if daemon.KeaDaemon.Config.GetDDNSDomain(direction, domain.Name) != nil {
+			return errors.WithStack(config.NewDDNSDomainExistsError(string(direction), domain.Name, daemon.ID))
+		}
+		return daemon.KeaDaemon.Config.AddDDNSDomain(direction, domain)
+	})
+}
+
+// Applies an updated DDNS domain to the D2 daemon's configuration. The
+// domain is identified by its name and direction.
+func (module *ConfigModule) ApplyDDNSDomainUpdate(ctx context.Context, direction keaconfig.D2DDNSDirection, domain *keaconfig.D2DDNSDomain) (context.Context, error) {
+	return module.applyDDNSDomainChange(ctx, func(daemon *dbmodel.Daemon) error {
+		if err := validateDDNSDomain(daemon, direction, domain); err != nil {
+			return err
+		}
+		if daemon.KeaDaemon.Config.GetDDNSDomain(direction, domain.Name) == nil {
+			return errors.WithStack(config.NewDDNSDomainNotFoundError(string(direction), domain.Name, daemon.ID))
+		}
+		return daemon.KeaDaemon.Config.UpdateDDNSDomain(direction, domain)
+	})
+}
+
+// Removes the DDNS domain with the specified name and direction from the
+// D2 daemon's configuration.
+func (module *ConfigModule) ApplyDDNSDomainDelete(ctx context.Context, direction keaconfig.D2DDNSDirection, name string) (context.Context, error) {
+	return module.applyDDNSDomainChange(ctx, func(daemon *dbmodel.Daemon) error {
+		if !direction.IsValid() {
+			return errors.WithStack(config.NewInvalidDDNSDomainError(fmt.Sprintf("invalid direction %s", direction), daemon.ID))
+		}
+		if daemon.KeaDaemon.Config.GetDDNSDomain(direction, name) == nil {
+			return errors.WithStack(config.NewDDNSDomainNotFoundError(string(direction), name, daemon.ID))
+		}
+		return daemon.KeaDaemon.Config.DeleteDDNSDomain(direction, name)
+	})
+}
+
+// Applies a change to the configuration of the D2 daemon held in the
+// transaction. The changes are accumulated, so the configuration modified
+// by the previous calls is modified by the subsequent calls. The config-set
+// and config-write commands are recreated with the modified configuration.
+func (module *ConfigModule) applyDDNSDomainChange(ctx context.Context, change func(*dbmodel.Daemon) error) (context.Context, error) {
+	recipe, err := config.GetRecipeForUpdate[ConfigRecipe](ctx, 0)
+	if err != nil {
+		return ctx, err
+	}
+	if len(recipe.KeaDaemonsBeforeConfigUpdate) == 0 {
+		return ctx, errors.New("internal server error - existing Kea configs cannot be nil when applying DDNS domain changes")
+	}
+	daemons := recipe.KeaDaemonsAfterConfigUpdate
+	if daemons == nil {
+		// Work on the copies of the configurations to keep the original
+		// configurations intact.
+		if daemons, err = copyDaemonsWithConfigs(recipe.KeaDaemonsBeforeConfigUpdate); err != nil {
+			return ctx, err
+		}
+	}
+	for i := range daemons {
+		if err = change(&daemons[i]); err != nil {
+			return ctx, err
+		}
+	}
+	var commands []ConfigCommand
+	for i := range daemons {
+		commands = append(commands, createConfigSetCommand(&daemons[i]))
+	}
+	commands = append(commands, createConfigWriteCommands(daemons, config.IsConfigWriteEnabled(ctx))...)
+
+	// Store the data in the recipe.
+	recipe.KeaDaemonsAfterConfigUpdate = daemons
+	recipe.Commands = commands
+	return config.SetRecipeForUpdate(ctx, 0, recipe)
+}
+
+// Checks that the daemon is a D2 daemon having the configuration and the
+// app which are required to manage the DDNS domains.
+func validateDDNSDomainDaemon(daemon *dbmodel.Daemon) error {
+	if daemon.Name != dbmodel.DaemonNameD2 {
+		return errors.Errorf("DDNS domains can only be managed for the D2 daemons; daemon %d is %s", daemon.ID, daemon.Name)
+	}
+	if daemon.KeaDaemon == nil || daemon.KeaDaemon.Config == nil || !daemon.KeaDaemon.Config.IsD2() {
+		return errors.Errorf("configuration not found for daemon %d when modifying DDNS domains", daemon.ID)
+	}
+	if daemon.App == nil {
+		return errors.Errorf("DDNS domains are associated with daemon %d having nil app", daemon.ID)
+	}
+	return nil
+}
+
+// Validates the DDNS domain against the D2 daemon's configuration.
+func validateDDNSDomain(daemon *dbmodel.Daemon, direction keaconfig.D2DDNSDirection, domain *keaconfig.D2DDNSDomain) error {
+	if !direction.IsValid() {
+		return errors.WithStack(config.NewInvalidDDNSDomainError(fmt.Sprintf("invalid direction %s", direction), daemon.ID))
+	}
+	if err := domain.Validate(daemon.KeaDaemon.Config.D2Config); err != nil {
+		return errors.WithStack(config.NewInvalidDDNSDomainError(err.Error(), daemon.ID))
+	}
+	return nil
+}
+
+// Returns copies of the daemons holding deep copies of their configurations.
+// The configurations can be modified in the copies without affecting the
+// original daemons' configurations.
+func copyDaemonsWithConfigs(daemons []dbmodel.Daemon) ([]dbmodel.Daemon, error) {
+	copiedDaemons := make([]dbmodel.Daemon, 0, len(daemons))
+	for _, daemon := range daemons {
+		if daemon.KeaDaemon != nil {
+			keaDaemon := *daemon.KeaDaemon
+			if keaDaemon.Config != nil {
+				serializedConfig, err := json.Marshal(keaDaemon.Config)
+				if err != nil {
+					return nil, errors.Wrapf(err, "failed to copy the configuration of the daemon %d", daemon.ID)
+				}
+				keaDaemon.Config, err = dbmodel.NewKeaConfigFromJSON(string(serializedConfig))
+				if err != nil {
+					return nil, errors.WithMessagef(err, "failed to copy the configuration of the daemon %d", daemon.ID)
+				}
+			}
+			daemon.KeaDaemon = &keaDaemon
+		}
+		copiedDaemons = append(copiedDaemons, daemon)
+	}
+	return copiedDaemons, nil
+}
+
+// Returns a preview of the changes to be applied by the transaction. The
+// preview is grouped by daemons and it includes the commands that would be
+// sent to each daemon upon commit and the differences between the affected
+// parts of the daemon's configuration before and after the update. This
+// function neither sends any commands nor modifies the transaction state,
+// so the transaction can be committed or canceled after the preview. If
+// the hideSensitiveData flag is set, the passwords, secrets and tokens are
+// removed from the returned commands and the compared configurations.
+func (module *ConfigModule) Preview(ctx context.Context, hideSensitiveData bool) ([]config.DaemonChangesPreview, error) {
+	state, ok := config.GetTransactionState[ConfigRecipe](ctx)
+	if !ok {
+		return nil, errors.New("context lacks state")
+	}
+	var previews []config.DaemonChangesPreview
+	for _, update := range state.Updates {
+		// The changes are computed once per daemon for each update.
+		updatedDaemons := make(map[int64]bool)
+		for _, command := range update.Recipe.Commands {
+			index := slices.IndexFunc(previews, func(preview config.DaemonChangesPreview) bool {
+				return preview.DaemonID == command.DaemonID
+			})
+			if index < 0 {
+				preview := config.DaemonChangesPreview{
+					DaemonID: command.DaemonID,
+				}
+				if command.App != nil {
+					preview.AppID = command.App.ID
+					preview.AppName = command.App.Name
+				}
+				if command.Command != nil && len(command.Command.Daemons) > 0 {
+					preview.DaemonName = string(command.Command.Daemons[0])
+				}
+				previews = append(previews, preview)
+				index = len(previews) - 1
+			}
+			if !updatedDaemons[command.DaemonID] {
+				changes, err := module.getDaemonChanges(update, command.DaemonID, hideSensitiveData)
+				if err != nil {
+					return nil, err
+				}
+				previews[index].Changes = append(previews[index].Changes, changes...)
+				updatedDaemons[command.DaemonID] = true
+			}
+			previewCommand := command.Command
+			if hideSensitiveData && previewCommand != nil && previewCommand.Arguments != nil {
+				// The command is sent upon commit, so the copy must be modified.
+				arguments, err := copyWithoutSensitiveData(previewCommand.Arguments)
+				if err != nil {
+					return nil, errors.WithMessagef(err, "failed to hide sensitive data in the %s command", previewCommand.GetCommand())
+				}
+				previewCommand = &keactrl.Command{
+					Command:   previewCommand.Command,
+					Daemons:   previewCommand.Daemons,
+					Arguments: arguments,
+				}
+			}
+			previews[index].Commands = append(previews[index].Commands, previewCommand)
+		}
+	}
+	return previews, nil
+}
+
+// Returns the differences between the affected parts of the specified daemon's
+// configuration before and after the update. The compared parts depend on the
+// operation. For the host reservations, subnets and shared networks, these
+// are the respective configuration elements converted to the Kea format and
+// placed under their keys, so an added element is reported under that key
+// rather than as the whole document. For the other operations, these are
+// the daemon's configurations without the bookkeeping parameters. The
+// sensitive data are removed from the compared parts if requested.
+func (module *ConfigModule) getDaemonChanges(update *config.Update[ConfigRecipe], daemonID int64, hideSensitiveData bool) ([]storkutil.JSONDiffEntry, error) {
+	var before, after any
+	recipe := update.Recipe
+	switch update.Operation {
+	case "host_add", "host_update":
+		before, after = map[string]any{}, map[string]any{}
+		lookup := module.manager.GetDHCPOptionDefinitionLookup()
+		for i, host := range []*dbmodel.Host{recipe.HostBeforeUpdate, recipe.HostAfterUpdate} {
+			if host == nil || host.GetLocalHost(daemonID) == nil {
+				continue
+			}
+			reservation, err := keaconfig.CreateHostCmdsReservation(daemonID, lookup, host)
+			if err != nil {
+				return nil, err
+			}
+			element := map[string]any{"reservation": reservation}
+			if i == 0 {
+				before = element
+			} else {
+				after = element
+			}
+		}
+	case "subnet_add", "subnet_update":
+		before, after = map[string]any{}, map[string]any{}
+		lookup := module.manager.GetDHCPOptionDefinitionLookup()
+		for i, subnet := range []*dbmodel.Subnet{recipe.SubnetBeforeUpdate, recipe.SubnetAfterUpdate} {
+			if subnet == nil || subnet.GetLocalSubnet(daemonID) == nil {
+				continue
+			}
+			var element map[string]any
+			if subnet.GetFamily() == 4 {
+				subnet4, err := keaconfig.CreateSubnet4(daemonID, lookup, subnet)
+				if err != nil {
+					return nil, err
+				}
+				element = map[string]any{"subnet4": subnet4}
+			} else {
+				subnet6, err := keaconfig.CreateSubnet6(daemonID, lookup, subnet)
+				if err != nil {
+					return nil, err
+				}
+				element = map[string]any{"subnet6": subnet6}
+			}
+			if i == 0 {
+				before = element
+			} else {
+				after = element
+			}
+		}
+	case "shared_network_add", "shared_network_update":
+		before, after = map[string]any{}, map[string]any{}
+		lookup := module.manager.GetDHCPOptionDefinitionLookup()
+		for i, sharedNetwork := range []*dbmodel.SharedNetwork{recipe.SharedNetworkBeforeUpdate, recipe.SharedNetworkAfterUpdate} {
+			if sharedNetwork == nil || sharedNetwork.GetLocalSharedNetwork(daemonID) == nil {
+				continue
+			}
+			var element map[string]any
+			if sharedNetwork.Family == 4 {
+				sharedNetwork4, err := keaconfig.CreateSharedNetwork4(daemonID, lookup, sharedNetwork)
+				if err != nil {
+					return nil, err
+				}
+				element = map[string]any{"shared-network4": sharedNetwork4}
+			} else {
+				sharedNetwork6, err := keaconfig.CreateSharedNetwork6(daemonID, lookup, sharedNetwork)
+				if err != nil {
+					return nil, err
+				}
+				element = map[string]any{"shared-network6": sharedNetwork6}
+			}
+			if i == 0 {
+				before = element
+			} else {
+				after = element
+			}
+		}
+	default:
+		for i, daemons := range [][]dbmodel.Daemon{recipe.KeaDaemonsBeforeConfigUpdate, recipe.KeaDaemonsAfterConfigUpdate} {
+			for _, daemon := range daemons {
+				if daemon.ID != daemonID || daemon.KeaDaemon == nil || daemon.KeaDaemon.Config == nil {
+					continue
+				}
+				// The hash is dropped from the updated configuration, so
+				// it must not be compared.
+				if i == 0 {
+					before = daemon.KeaDaemon.Config.GetContents()
+				} else {
+					after = daemon.KeaDaemon.Config.GetContents()
+				}
+			}
+		}
+	}
+	if hideSensitiveData {
+		var err error
+		if before, err = copyWithoutSensitiveData(before); err != nil {
+			return nil, errors.WithMessagef(err, "failed to hide sensitive data in the configuration of the daemon %d", daemonID)
+		}
+		if after, err = copyWithoutSensitiveData(after); err != nil {
+			return nil, errors.WithMessagef(err, "failed to hide sensitive data in the configuration of the daemon %d", daemonID)
+		}
+	}
+	changes, err := storkutil.JSONDiff(before, after)
+	if err != nil {
+		return nil, errors.WithMessagef(err, "failed to compare the configurations of the daemon %d", daemonID)
+	}
+	return changes, nil
+}
+
+// Returns a deep copy of the specified configuration element with the
+// passwords, secrets and tokens removed. The element is shared with the
+// transaction state, so it must not be modified in place.
+func copyWithoutSensitiveData(element any) (any, error) {
+	if element == nil {
+		return nil, nil
+	}
+	serialized, err := json.Marshal(element)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to serialize the configuration element")
+	}
+	var copied any
+	if err = json.Unmarshal(serialized, &copied); err != nil {
+		return nil, errors.Wrap(err, "failed to copy the configuration element")
+	}
+	if contents, ok := copied.(map[string]any); ok {
+		(&keaconfig.Config{Raw: contents}).HideSensitiveData()
+	}
+	return copied, nil
+}
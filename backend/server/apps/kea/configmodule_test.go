@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"slices"
 	"testing"
 	"time"
 
@@ -19,6 +20,7 @@ import (
 	dbmodel "isc.org/stork/server/database/model"
 	dbmodeltest "isc.org/stork/server/database/model/test"
 	dbtest "isc.org/stork/server/database/test"
+	"isc.org/stork/server/eventcenter"
 	storktest "isc.org/stork/server/test/dbmodel"
 	storkutil "isc.org/stork/util"
 )
@@ -30,6 +32,7 @@ type testManager struct {
 	agents       agentcomm.ConnectedAgents
 	lookup       keaconfig.DHCPOptionDefinitionLookup
 	daemonLocker config.DaemonLocker
+	eventCenter  eventcenter.EventCenter
 
 	locks map[int64]bool
 }
@@ -42,6 +45,7 @@ func newTestManager(server config.ManagerAccessors) *testManager {
 		lookup:       server.GetDHCPOptionDefinitionLookup(),
 		locks:        make(map[int64]bool),
 		daemonLocker: server.GetDaemonLocker(),
+		eventCenter:  server.GetEventCenter(),
 	}
 }
 
@@ -67,6 +71,12 @@ func (tm *testManager) GetDaemonLocker() config.DaemonLocker {
 	return tm.daemonLocker
 }
 
+// Returns an interface to the event center. It is nil unless the test
+// specifies the event center.
+func (tm *testManager) GetEventCenter() eventcenter.EventCenter {
+	return tm.eventCenter
+}
+
 // Applies locks on specified daemons.
 func (tm *testManager) Lock(ctx context.Context, daemonIDs ...int64) (context.Context, error) {
 	for _, id := range daemonIDs {
@@ -710,6 +720,177 @@ func TestCommitHostAdd(t *testing.T) {
 	require.Len(t, newHost.LocalHosts, 2)
 }
 
+// Test the first stage of importing hosts in bulk.
+func TestBeginHostsImport(t *testing.T) {
+	manager := newTestManager(&appstest.ManagerAccessorsWrapper{
+		DefLookup: dbmodel.NewDHCPOptionDefinitionLookup(),
+	})
+	module := NewConfigModule(manager)
+	require.NotNil(t, module)
+
+	ctx, err := module.BeginHostsImport(context.Background())
+	require.NoError(t, err)
+
+	// There should be one update stored in the transaction state.
+	state, ok := config.GetTransactionState[ConfigRecipe](ctx)
+	require.True(t, ok)
+	require.Len(t, state.Updates, 1)
+	require.Equal(t, datamodel.AppTypeKea, state.Updates[0].Target)
+	require.Equal(t, "hosts_import", state.Updates[0].Operation)
+}
+
+// Test second stage of importing hosts in bulk. The configuration of
+// each daemon should be written once, after adding all hosts.
+func TestApplyHostsImport(t *testing.T) {
+	manager := newTestManager(&appstest.ManagerAccessorsWrapper{
+		DefLookup: dbmodel.NewDHCPOptionDefinitionLookup(),
+	})
+	module := NewConfigModule(manager)
+	require.NotNil(t, module)
+
+	state := config.NewTransactionStateWithUpdate[ConfigRecipe](datamodel.AppTypeKea, "hosts_import")
+	ctx := context.WithValue(context.Background(), config.StateContextKey, *state)
+
+	daemon := getTestConfigBackendDaemon(t, 1, `{
+		"Dhcp4": {
+			"hooks-libraries": [
+				{
+					"library": "/usr/lib/kea/libdhcp_host_cmds.so"
+				}
+			]
+		}
+	}`)
+	var hosts []*dbmodel.Host
+	for i := 0; i < 2; i++ {
+		hosts = append(hosts, &dbmodel.Host{
+			HostIdentifiers: []dbmodel.HostIdentifier{
+				{
+					Type:  "hw-address",
+					Value: []byte{1, 2, 3, 4, 5, byte(i)},
+				},
+			},
+			LocalHosts: []dbmodel.LocalHost{
+				{
+					DaemonID:   1,
+					Daemon:     &daemon,
+					DataSource: dbmodel.HostDataSourceAPI,
+				},
+			},
+		})
+	}
+	ctx, err := module.ApplyHostsImport(ctx, hosts)
+	require.NoError(t, err)
+
+	returnedState, ok := config.GetTransactionState[ConfigRecipe](ctx)
+	require.True(t, ok)
+	require.Len(t, returnedState.Updates, 1)
+	update := returnedState.Updates[0]
+	require.Equal(t, "hosts_import", update.Operation)
+	require.NotNil(t, update.Recipe)
+	require.Len(t, update.Recipe.ImportedHosts, 2)
+
+	// Two reservation-add commands and a single config-write.
+	commands := update.Recipe.Commands
+	require.Len(t, commands, 3)
+	require.JSONEq(t, `{
+		"command": "reservation-add",
+		"service": [ "dhcp4" ],
+		"arguments": {
+			"reservation": {
+				"subnet-id": 0,
+				"hw-address": "010203040500"
+			}
+		}
+	}`, commands[0].Command.Marshal())
+	require.JSONEq(t, `{
+		"command": "reservation-add",
+		"service": [ "dhcp4" ],
+		"arguments": {
+			"reservation": {
+				"subnet-id": 0,
+				"hw-address": "010203040501"
+			}
+		}
+	}`, commands[1].Command.Marshal())
+	require.JSONEq(t, `{
+		"command": "config-write",
+		"service": [ "dhcp4" ]
+	}`, commands[2].Command.Marshal())
+
+	// Importing no hosts is an error.
+	_, err = module.ApplyHostsImport(ctx, nil)
+	require.Error(t, err)
+}
+
+// Test committing the hosts imported in bulk.
+func TestCommitHostsImport(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	_, apps := storktest.AddTestHosts(t, db)
+
+	agents := agentcommtest.NewKeaFakeAgents()
+	manager := newTestManager(&appstest.ManagerAccessorsWrapper{
+		DB:        db,
+		Agents:    agents,
+		DefLookup: dbmodel.NewDHCPOptionDefinitionLookup(),
+	})
+	module := NewConfigModule(manager)
+	require.NotNil(t, module)
+
+	ctx, err := module.BeginHostsImport(context.Background())
+	require.NoError(t, err)
+
+	var hosts []*dbmodel.Host
+	for i := 0; i < 2; i++ {
+		hosts = append(hosts, &dbmodel.Host{
+			HostIdentifiers: []dbmodel.HostIdentifier{
+				{
+					Type:  "hw-address",
+					Value: []byte{1, 2, 3, 4, 5, byte(i)},
+				},
+			},
+			LocalHosts: []dbmodel.LocalHost{
+				{
+					DaemonID: apps[0].Daemons[0].KeaDaemon.DaemonID,
+					Daemon: &dbmodel.Daemon{
+						Name: "dhcp4",
+						App: &dbmodel.App{
+							AccessPoints: []*dbmodel.AccessPoint{
+								{
+									Type:    dbmodel.AccessPointControl,
+									Address: "192.0.2.1",
+									Port:    1234,
+								},
+							},
+						},
+					},
+					DataSource: dbmodel.HostDataSourceAPI,
+				},
+			},
+		})
+	}
+	ctx, err = module.ApplyHostsImport(ctx, hosts)
+	require.NoError(t, err)
+
+	_, err = module.Commit(ctx)
+	require.NoError(t, err)
+
+	require.Len(t, agents.RecordedCommands, 2)
+	for _, command := range agents.RecordedCommands {
+		require.EqualValues(t, "reservation-add", command.GetCommand())
+	}
+
+	// Make sure that the hosts have been added to the database.
+	for _, host := range hosts {
+		require.NotZero(t, host.ID)
+		newHost, err := dbmodel.GetHost(db, host.ID)
+		require.NoError(t, err)
+		require.NotNil(t, newHost)
+		require.Len(t, newHost.LocalHosts, 1)
+	}
+}
+
 // Test that error is returned when Kea response contains error status code.
 func TestCommitHostAddResponseWithErrorStatus(t *testing.T) {
 	// Create the config manager instance "connected to" fake agents.
@@ -4725,6 +4906,299 @@ func TestCommitSubnetUpdateResponseWithErrorStatus(t *testing.T) {
 	require.Len(t, agents.RecordedCommands, 1)
 }
 
+// Creates two servers belonging to the same HA relationship and returns
+// the subnet shared by these servers. It is used in the tests verifying
+// that the configuration changes are applied to both HA peers or none
+// of them.
+func addTestHAPair(t *testing.T, db *pg.DB) dbmodel.Subnet {
+	serverConfig := `{
+		"Dhcp4": {
+			"subnet4": [
+				{
+					"id": 1,
+					"subnet": "192.0.2.0/24"
+				}
+			]
+		}
+	}`
+	var daemons []*dbmodel.Daemon
+	for i := 0; i < 2; i++ {
+		server, err := dbmodeltest.NewKeaDHCPv4Server(db)
+		require.NoError(t, err)
+		err = server.Configure(serverConfig)
+		require.NoError(t, err)
+
+		app, err := server.GetKea()
+		require.NoError(t, err)
+
+		err = CommitAppIntoDB(db, app, &storktest.FakeEventCenter{}, nil, dbmodel.NewDHCPOptionDefinitionLookup())
+		require.NoError(t, err)
+		daemons = append(daemons, app.Daemons[0])
+	}
+	service := &dbmodel.Service{
+		BaseService: dbmodel.BaseService{
+			Name:        "server1",
+			ServiceType: "ha_dhcp",
+			Daemons:     daemons,
+		},
+		HAService: &dbmodel.BaseHAService{
+			HAType:       dbmodel.HATypeDhcp4,
+			HAMode:       dbmodel.HAModeHotStandby,
+			Relationship: "server1",
+			PrimaryID:    daemons[0].ID,
+			SecondaryID:  daemons[1].ID,
+		},
+	}
+	err := dbmodel.AddService(db, service)
+	require.NoError(t, err)
+
+	subnets, err := dbmodel.GetSubnetsByPrefix(db, "192.0.2.0/24")
+	require.NoError(t, err)
+	require.Len(t, subnets, 1)
+	require.Len(t, subnets[0].LocalSubnets, 2)
+	return subnets[0]
+}
+
+// Returns a function mocking a Kea response with the specified status
+// and arguments.
+func mockKeaResponse(result int, arguments string) func(int, []interface{}) {
+	return func(callNo int, cmdResponses []interface{}) {
+		json := fmt.Sprintf(`[
+			{
+				"result": %d,
+				"text": "status text",
+				"arguments": %s
+			}
+		]`, result, arguments)
+		command := keactrl.NewCommandBase(keactrl.ConfigGet, keactrl.DHCPv4)
+		_ = keactrl.UnmarshalResponseList(command, []byte(json), cmdResponses[0])
+	}
+}
+
+// Creates the context with the subnet update for the tests verifying that
+// the configuration changes are applied to both HA peers or none of them.
+func applyTestHAPairSubnetUpdate(t *testing.T, module *ConfigModule, subnet dbmodel.Subnet) context.Context {
+	daemonIDs := []int64{subnet.LocalSubnets[0].DaemonID, subnet.LocalSubnets[1].DaemonID}
+	ctx := context.WithValue(context.Background(), config.DaemonsContextKey, daemonIDs)
+
+	state := config.NewTransactionStateWithUpdate[ConfigRecipe](datamodel.AppTypeKea, "subnet_update", daemonIDs...)
+	recipe := ConfigRecipe{
+		SubnetConfigRecipeParams: SubnetConfigRecipeParams{
+			SubnetBeforeUpdate: &subnet,
+		},
+	}
+	err := state.SetRecipeForUpdate(0, &recipe)
+	require.NoError(t, err)
+	ctx = context.WithValue(ctx, config.StateContextKey, *state)
+
+	modifiedSubnet := subnet
+	modifiedSubnet.CreatedAt = time.Time{}
+	modifiedSubnet.LocalSubnets[0].KeaParameters.Allocator = storkutil.Ptr("random")
+	modifiedSubnet.LocalSubnets[1].KeaParameters.Allocator = storkutil.Ptr("random")
+
+	ctx, err = module.ApplySubnetUpdate(ctx, &modifiedSubnet)
+	require.NoError(t, err)
+	return ctx
+}
+
+// Test that the changes applied to one of the HA peers are reverted when
+// they can't be applied to the other peer.
+func TestCommitSubnetUpdateHAPairRevert(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	agents := agentcommtest.NewKeaFakeAgents(
+		// Fetching the current configurations.
+		mockKeaResponse(0, `{"Dhcp4": {"valid-lifetime": 1000}}`),
+		mockKeaResponse(0, `{"Dhcp4": {"valid-lifetime": 2000}}`),
+		// The first peer is successfully updated.
+		mockKeaResponse(0, `{}`),
+		// Updating the second peer fails.
+		mockKeaResponse(1, `{}`),
+		// Reverting the changes in the first peer.
+		mockKeaResponse(0, `{}`),
+	)
+	eventCenter := &storktest.FakeEventCenter{}
+	manager := newTestManager(&appstest.ManagerAccessorsWrapper{
+		DB:          db,
+		Agents:      agents,
+		DefLookup:   dbmodel.NewDHCPOptionDefinitionLookup(),
+		EventCenter: eventCenter,
+	})
+	module := NewConfigModule(manager)
+	require.NotNil(t, module)
+
+	subnet := addTestHAPair(t, db)
+	ctx := applyTestHAPairSubnetUpdate(t, module, subnet)
+
+	_, err := module.Commit(ctx)
+	require.ErrorContains(t, err, "subnet4-update command to")
+
+	// The config-get commands should be sent to both peers before applying
+	// the changes. The changes applied to the first peer should be reverted
+	// with its original configuration.
+	require.Len(t, agents.RecordedCommands, 5)
+	require.EqualValues(t, keactrl.ConfigGet, agents.RecordedCommands[0].GetCommand())
+	require.EqualValues(t, keactrl.ConfigGet, agents.RecordedCommands[1].GetCommand())
+	require.EqualValues(t, keactrl.Subnet4Update, agents.RecordedCommands[2].GetCommand())
+	require.EqualValues(t, keactrl.Subnet4Update, agents.RecordedCommands[3].GetCommand())
+	require.JSONEq(t, `{
+		"command": "config-set",
+		"service": [ "dhcp4" ],
+		"arguments": {
+			"Dhcp4": {
+				"valid-lifetime": 1000
+			}
+		}
+	}`, agents.RecordedCommands[4].Marshal())
+	require.Equal(t, agents.RecordedURLs[2], agents.RecordedURLs[4])
+
+	// The outcome should be recorded as an event.
+	require.Len(t, eventCenter.Events, 1)
+	require.Equal(t, dbmodel.EvWarning, eventCenter.Events[0].Level)
+	require.Contains(t, eventCenter.Events[0].Text, "Reverted configuration changes")
+	require.EqualValues(t, subnet.LocalSubnets[0].DaemonID, eventCenter.Events[0].Relations.DaemonID)
+}
+
+// Test that the changes applied to the HA peers by the earlier updates
+// in the transaction are reverted when a later update fails.
+func TestCommitSubnetUpdateHAPairRevertMultipleUpdates(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	agents := agentcommtest.NewKeaFakeAgents(
+		// Fetching the current configurations.
+		mockKeaResponse(0, `{"Dhcp4": {"valid-lifetime": 1000}}`),
+		mockKeaResponse(0, `{"Dhcp4": {"valid-lifetime": 2000}}`),
+		// The first update is successfully applied to both peers.
+		mockKeaResponse(0, `{}`),
+		mockKeaResponse(0, `{}`),
+		// Applying the second update to the first peer fails.
+		mockKeaResponse(1, `{}`),
+		// Reverting the changes in both peers.
+		mockKeaResponse(0, `{}`),
+		mockKeaResponse(0, `{}`),
+	)
+	eventCenter := &storktest.FakeEventCenter{}
+	manager := newTestManager(&appstest.ManagerAccessorsWrapper{
+		DB:          db,
+		Agents:      agents,
+		DefLookup:   dbmodel.NewDHCPOptionDefinitionLookup(),
+		EventCenter: eventCenter,
+	})
+	module := NewConfigModule(manager)
+	require.NotNil(t, module)
+
+	subnet := addTestHAPair(t, db)
+	ctx := applyTestHAPairSubnetUpdate(t, module, subnet)
+
+	// Add another update to the transaction.
+	state, ok := config.GetTransactionState[ConfigRecipe](ctx)
+	require.True(t, ok)
+	update := *state.Updates[0]
+	state.Updates = append(state.Updates, &update)
+	ctx = context.WithValue(ctx, config.StateContextKey, state)
+
+	_, err := module.Commit(ctx)
+	require.ErrorContains(t, err, "subnet4-update command to")
+
+	// Both peers should be reverted with their original configurations
+	// because they both received the commands of the first update.
+	require.Len(t, agents.RecordedCommands, 7)
+	for i := 2; i < 5; i++ {
+		require.EqualValues(t, keactrl.Subnet4Update, agents.RecordedCommands[i].GetCommand())
+	}
+	require.JSONEq(t, `{
+		"command": "config-set",
+		"service": [ "dhcp4" ],
+		"arguments": {
+			"Dhcp4": {
+				"valid-lifetime": 1000
+			}
+		}
+	}`, agents.RecordedCommands[5].Marshal())
+	require.JSONEq(t, `{
+		"command": "config-set",
+		"service": [ "dhcp4" ],
+		"arguments": {
+			"Dhcp4": {
+				"valid-lifetime": 2000
+			}
+		}
+	}`, agents.RecordedCommands[6].Marshal())
+
+	require.Len(t, eventCenter.Events, 2)
+	require.Equal(t, dbmodel.EvWarning, eventCenter.Events[0].Level)
+	require.Equal(t, dbmodel.EvWarning, eventCenter.Events[1].Level)
+}
+
+// Test that an error is returned and the event is recorded when the changes
+// applied to one of the HA peers can't be reverted.
+func TestCommitSubnetUpdateHAPairRevertError(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	agents := agentcommtest.NewKeaFakeAgents(
+		mockKeaResponse(0, `{"Dhcp4": {"valid-lifetime": 1000}}`),
+		mockKeaResponse(0, `{"Dhcp4": {"valid-lifetime": 2000}}`),
+		mockKeaResponse(0, `{}`),
+		// Both updating the second peer and reverting the first peer fail.
+		mockKeaResponse(1, `{}`),
+	)
+	eventCenter := &storktest.FakeEventCenter{}
+	manager := newTestManager(&appstest.ManagerAccessorsWrapper{
+		DB:          db,
+		Agents:      agents,
+		DefLookup:   dbmodel.NewDHCPOptionDefinitionLookup(),
+		EventCenter: eventCenter,
+	})
+	module := NewConfigModule(manager)
+	require.NotNil(t, module)
+
+	subnet := addTestHAPair(t, db)
+	ctx := applyTestHAPairSubnetUpdate(t, module, subnet)
+
+	_, err := module.Commit(ctx)
+	require.ErrorContains(t, err, "failed to revert the changes")
+	require.ErrorContains(t, err, "subnet4-update command to")
+
+	require.Len(t, agents.RecordedCommands, 5)
+	require.EqualValues(t, keactrl.ConfigSet, agents.RecordedCommands[4].GetCommand())
+
+	require.Len(t, eventCenter.Events, 1)
+	require.Equal(t, dbmodel.EvError, eventCenter.Events[0].Level)
+}
+
+// Test that no changes are applied to the HA peers when one of them is
+// unreachable.
+func TestCommitSubnetUpdateHAPairUnreachable(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	agents := agentcommtest.NewKeaFakeAgents(
+		mockKeaResponse(0, `{"Dhcp4": {"valid-lifetime": 1000}}`),
+		mockKeaResponse(1, `{}`),
+	)
+	manager := newTestManager(&appstest.ManagerAccessorsWrapper{
+		DB:        db,
+		Agents:    agents,
+		DefLookup: dbmodel.NewDHCPOptionDefinitionLookup(),
+	})
+	module := NewConfigModule(manager)
+	require.NotNil(t, module)
+
+	subnet := addTestHAPair(t, db)
+	ctx := applyTestHAPairSubnetUpdate(t, module, subnet)
+
+	_, err := module.Commit(ctx)
+	require.ErrorContains(t, err, "is unreachable")
+
+	// Only the config-get commands should be sent.
+	require.Len(t, agents.RecordedCommands, 2)
+	require.EqualValues(t, keactrl.ConfigGet, agents.RecordedCommands[0].GetCommand())
+	require.EqualValues(t, keactrl.ConfigGet, agents.RecordedCommands[1].GetCommand())
+}
+
 // Test second stage of deleting an IPv4 subnet.
 func TestApplySubnet4Delete(t *testing.T) {
 	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
@@ -5116,3 +5590,1982 @@ func TestCommitSubnetDelete(t *testing.T) {
 	require.NoError(t, err)
 	require.Nil(t, returnedSubnet)
 }
+
+// Returns test daemons used in the client class management tests. The
+// first daemon has the libdhcp_class_cmds hook library. The second
+// daemon lacks this library.
+func getTestClientClassDaemons(t *testing.T) []dbmodel.Daemon {
+	config1, err := dbmodel.NewKeaConfigFromJSON(`{
+		"Dhcp4": {
+			"client-classes": [
+				{
+					"name": "foo",
+					"test": "member('KNOWN')"
+				}
+			],
+			"hooks-libraries": [
+				{
+					"library": "/usr/lib/kea/libdhcp_class_cmds.so"
+				}
+			]
+		}
+	}`)
+	require.NoError(t, err)
+
+	config2, err := dbmodel.NewKeaConfigFromJSON(`{
+		"Dhcp4": {
+			"client-classes": [
+				{
+					"name": "foo",
+					"test": "member('KNOWN')"
+				}
+			]
+		}
+	}`)
+	require.NoError(t, err)
+
+	return []dbmodel.Daemon{
+		{
+			ID:   1,
+			Name: dbmodel.DaemonNameDHCPv4,
+			KeaDaemon: &dbmodel.KeaDaemon{
+				Config: config1,
+			},
+			App: &dbmodel.App{
+				AccessPoints: []*dbmodel.AccessPoint{
+					{
+						Type:    dbmodel.AccessPointControl,
+						Address: "192.0.2.1",
+						Port:    1234,
+					},
+				},
+			},
+		},
+		{
+			ID:   2,
+			Name: dbmodel.DaemonNameDHCPv4,
+			KeaDaemon: &dbmodel.KeaDaemon{
+				Config: config2,
+			},
+			App: &dbmodel.App{
+				AccessPoints: []*dbmodel.AccessPoint{
+					{
+						Type:    dbmodel.AccessPointControl,
+						Address: "192.0.2.2",
+						Port:    2345,
+					},
+				},
+			},
+		},
+	}
+}
+
+// Test first stage of adding a new client class.
+func TestBeginClientClassAdd(t *testing.T) {
+	module := NewConfigModule(nil)
+	require.NotNil(t, module)
+
+	ctx, err := module.BeginClientClassAdd(context.Background())
+	require.NoError(t, err)
+
+	state, ok := config.GetTransactionState[ConfigRecipe](ctx)
+	require.True(t, ok)
+	require.Len(t, state.Updates, 1)
+	require.Equal(t, datamodel.AppTypeKea, state.Updates[0].Target)
+	require.Equal(t, "client_class_add", state.Updates[0].Operation)
+}
+
+// Test second stage of adding a new client class. The class-add command
+// should be sent to the daemon with the libdhcp_class_cmds hook library
+// and the config-set command should be sent to the other daemon.
+func TestApplyClientClassAdd(t *testing.T) {
+	module := NewConfigModule(nil)
+	require.NotNil(t, module)
+
+	daemons := getTestClientClassDaemons(t)
+
+	ctx, err := module.BeginClientClassAdd(context.Background())
+	require.NoError(t, err)
+
+	clientClass := &keaconfig.ClientClass{
+		Name: "bar",
+		Test: storkutil.Ptr("member('UNKNOWN')"),
+	}
+	ctx, err = module.ApplyClientClassAdd(ctx, clientClass, daemons)
+	require.NoError(t, err)
+
+	state, ok := config.GetTransactionState[ConfigRecipe](ctx)
+	require.True(t, ok)
+	require.Len(t, state.Updates, 1)
+	recipe := state.Updates[0].Recipe
+	require.Equal(t, clientClass, recipe.ClientClassAfterUpdate)
+	require.NotNil(t, recipe.ClientClassName)
+	require.Equal(t, "bar", *recipe.ClientClassName)
+	require.Len(t, recipe.KeaDaemonsAfterConfigUpdate, 2)
+
+	// The daemons' configurations should include the new class.
+	for _, daemon := range recipe.KeaDaemonsAfterConfigUpdate {
+		require.NotNil(t, daemon.KeaDaemon.Config.GetClientClass("bar"))
+	}
+
+	commands := recipe.Commands
+	require.Len(t, commands, 4)
+	require.JSONEq(t, `{
+		"command": "class-add",
+		"service": [ "dhcp4" ],
+		"arguments": {
+			"client-classes": [
+				{
+					"name": "bar",
+					"test": "member('UNKNOWN')"
+				}
+			]
+		}
+	}`, commands[0].Command.Marshal())
+	require.Equal(t, keactrl.ConfigSet, commands[1].Command.GetCommand())
+	require.JSONEq(t, `{
+		"command": "config-set",
+		"service": [ "dhcp4" ],
+		"arguments": {
+			"Dhcp4": {
+				"client-classes": [
+					{
+						"name": "foo",
+						"test": "member('KNOWN')"
+					},
+					{
+						"name": "bar",
+						"test": "member('UNKNOWN')"
+					}
+				]
+			}
+		}
+	}`, commands[1].Command.Marshal())
+	for i := 2; i < 4; i++ {
+		require.JSONEq(t, `{
+			"command": "config-write",
+			"service": [ "dhcp4" ]
+		}`, commands[i].Command.Marshal())
+	}
+	require.Equal(t, daemons[0].App, commands[0].App)
+	require.Equal(t, daemons[1].App, commands[1].App)
+}
+
+// Test that adding a client class that already exists fails.
+func TestApplyClientClassAddExisting(t *testing.T) {
+	module := NewConfigModule(nil)
+	require.NotNil(t, module)
+
+	daemons := getTestClientClassDaemons(t)
+
+	ctx, err := module.BeginClientClassAdd(context.Background())
+	require.NoError(t, err)
+
+	_, err = module.ApplyClientClassAdd(ctx, &keaconfig.ClientClass{Name: "foo"}, daemons)
+	var existsError *config.ClientClassExistsError
+	require.ErrorAs(t, err, &existsError)
+}
+
+// Test that adding a client class without daemons fails.
+func TestApplyClientClassAddNoDaemons(t *testing.T) {
+	module := NewConfigModule(nil)
+	require.NotNil(t, module)
+
+	ctx, err := module.BeginClientClassAdd(context.Background())
+	require.NoError(t, err)
+
+	_, err = module.ApplyClientClassAdd(ctx, &keaconfig.ClientClass{Name: "foo"}, []dbmodel.Daemon{})
+	require.ErrorContains(t, err, "applied client class foo is not associated with any daemon")
+}
+
+// Test first stage of updating a client class. It checks that the daemons'
+// configurations are fetched from the database and the locks are applied.
+func TestBeginClientClassUpdate(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	agents := agentcommtest.NewKeaFakeAgents()
+	manager := newTestManager(&appstest.ManagerAccessorsWrapper{
+		DB:        db,
+		Agents:    agents,
+		DefLookup: dbmodel.NewDHCPOptionDefinitionLookup(),
+	})
+
+	module := NewConfigModule(manager)
+	require.NotNil(t, module)
+
+	server, err := dbmodeltest.NewKeaDHCPv4Server(db)
+	require.NoError(t, err)
+	err = server.Configure(`{
+		"Dhcp4": {
+			"client-classes": [
+				{
+					"name": "foo"
+				}
+			]
+		}
+	}`)
+	require.NoError(t, err)
+
+	app, err := server.GetKea()
+	require.NoError(t, err)
+
+	err = CommitAppIntoDB(db, app, &storktest.FakeEventCenter{}, nil, dbmodel.NewDHCPOptionDefinitionLookup())
+	require.NoError(t, err)
+
+	daemonID := app.Daemons[0].ID
+
+	// The class does not exist.
+	_, err = module.BeginClientClassUpdate(context.Background(), "bar", []int64{daemonID})
+	var notFoundError *config.ClientClassNotFoundError
+	require.ErrorAs(t, err, &notFoundError)
+	require.Empty(t, manager.locks)
+
+	// The daemon does not exist.
+	_, err = module.BeginClientClassUpdate(context.Background(), "foo", []int64{daemonID + 1})
+	var daemonsNotFoundError *config.SomeDaemonsNotFoundError
+	require.ErrorAs(t, err, &daemonsNotFoundError)
+
+	ctx, err := module.BeginClientClassUpdate(context.Background(), "foo", []int64{daemonID})
+	require.NoError(t, err)
+	require.Contains(t, manager.locks, daemonID)
+
+	state, ok := config.GetTransactionState[ConfigRecipe](ctx)
+	require.True(t, ok)
+	require.Len(t, state.Updates, 1)
+	require.Equal(t, "client_class_update", state.Updates[0].Operation)
+	require.Equal(t, []int64{daemonID}, state.Updates[0].DaemonIDs)
+	recipe := state.Updates[0].Recipe
+	require.NotNil(t, recipe.ClientClassName)
+	require.Equal(t, "foo", *recipe.ClientClassName)
+	require.Len(t, recipe.KeaDaemonsBeforeConfigUpdate, 1)
+}
+
+// Test second stage of updating a client class.
+func TestApplyClientClassUpdate(t *testing.T) {
+	module := NewConfigModule(nil)
+	require.NotNil(t, module)
+
+	daemons := getTestClientClassDaemons(t)
+
+	state := config.NewTransactionStateWithUpdate[ConfigRecipe](datamodel.AppTypeKea, "client_class_update", 1, 2)
+	recipe := ConfigRecipe{
+		GlobalConfigRecipeParams: GlobalConfigRecipeParams{
+			KeaDaemonsBeforeConfigUpdate: daemons,
+		},
+		ClientClassConfigRecipeParams: ClientClassConfigRecipeParams{
+			ClientClassName: storkutil.Ptr("foo"),
+		},
+	}
+	err := state.SetRecipeForUpdate(0, &recipe)
+	require.NoError(t, err)
+	ctx := context.WithValue(context.Background(), config.StateContextKey, *state)
+
+	// Renaming the class is not allowed.
+	_, err = module.ApplyClientClassUpdate(ctx, &keaconfig.ClientClass{Name: "bar"})
+	require.ErrorContains(t, err, "renaming client class foo to bar is not supported")
+
+	ctx, err = module.ApplyClientClassUpdate(ctx, &keaconfig.ClientClass{
+		Name:       "foo",
+		NextServer: storkutil.Ptr("192.0.2.10"),
+	})
+	require.NoError(t, err)
+
+	returnedState, ok := config.GetTransactionState[ConfigRecipe](ctx)
+	require.True(t, ok)
+	require.Len(t, returnedState.Updates, 1)
+	commands := returnedState.Updates[0].Recipe.Commands
+	require.Len(t, commands, 4)
+	require.JSONEq(t, `{
+		"command": "class-update",
+		"service": [ "dhcp4" ],
+		"arguments": {
+			"client-classes": [
+				{
+					"name": "foo",
+					"next-server": "192.0.2.10"
+				}
+			]
+		}
+	}`, commands[0].Command.Marshal())
+	require.JSONEq(t, `{
+		"command": "config-set",
+		"service": [ "dhcp4" ],
+		"arguments": {
+			"Dhcp4": {
+				"client-classes": [
+					{
+						"name": "foo",
+						"next-server": "192.0.2.10"
+					}
+				]
+			}
+		}
+	}`, commands[1].Command.Marshal())
+	require.Equal(t, keactrl.ConfigWrite, commands[2].Command.GetCommand())
+	require.Equal(t, keactrl.ConfigWrite, commands[3].Command.GetCommand())
+}
+
+// Test preparing the commands deleting a client class.
+func TestApplyClientClassDelete(t *testing.T) {
+	module := NewConfigModule(nil)
+	require.NotNil(t, module)
+
+	daemons := getTestClientClassDaemons(t)
+
+	// The class does not exist.
+	_, err := module.ApplyClientClassDelete(context.Background(), "bar", daemons)
+	var notFoundError *config.ClientClassNotFoundError
+	require.ErrorAs(t, err, &notFoundError)
+
+	daemons = getTestClientClassDaemons(t)
+	ctx, err := module.ApplyClientClassDelete(context.Background(), "foo", daemons)
+	require.NoError(t, err)
+
+	state, ok := config.GetTransactionState[ConfigRecipe](ctx)
+	require.True(t, ok)
+	require.Len(t, state.Updates, 1)
+	require.Equal(t, "client_class_delete", state.Updates[0].Operation)
+	require.Equal(t, []int64{1, 2}, state.Updates[0].DaemonIDs)
+
+	recipe := state.Updates[0].Recipe
+	require.NotNil(t, recipe.ClientClassName)
+	require.Equal(t, "foo", *recipe.ClientClassName)
+	require.Len(t, recipe.KeaDaemonsAfterConfigUpdate, 2)
+
+	commands := recipe.Commands
+	require.Len(t, commands, 4)
+	require.JSONEq(t, `{
+		"command": "class-del",
+		"service": [ "dhcp4" ],
+		"arguments": {
+			"name": "foo"
+		}
+	}`, commands[0].Command.Marshal())
+	require.JSONEq(t, `{
+		"command": "config-set",
+		"service": [ "dhcp4" ],
+		"arguments": {
+			"Dhcp4": {}
+		}
+	}`, commands[1].Command.Marshal())
+	require.Equal(t, keactrl.ConfigWrite, commands[2].Command.GetCommand())
+	require.Equal(t, keactrl.ConfigWrite, commands[3].Command.GetCommand())
+}
+
+// Test committing a new client class. It checks that the commands are sent
+// to Kea and the configuration is updated in the database.
+func TestCommitClientClassAdd(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	agents := agentcommtest.NewKeaFakeAgents()
+	manager := newTestManager(&appstest.ManagerAccessorsWrapper{
+		DB:        db,
+		Agents:    agents,
+		DefLookup: dbmodel.NewDHCPOptionDefinitionLookup(),
+	})
+
+	module := NewConfigModule(manager)
+	require.NotNil(t, module)
+
+	server, err := dbmodeltest.NewKeaDHCPv4Server(db)
+	require.NoError(t, err)
+	err = server.Configure(`{
+		"Dhcp4": {
+			"hooks-libraries": [
+				{
+					"library": "/usr/lib/kea/libdhcp_class_cmds.so"
+				}
+			]
+		}
+	}`)
+	require.NoError(t, err)
+
+	app, err := server.GetKea()
+	require.NoError(t, err)
+
+	err = CommitAppIntoDB(db, app, &storktest.FakeEventCenter{}, nil, dbmodel.NewDHCPOptionDefinitionLookup())
+	require.NoError(t, err)
+
+	daemons, err := dbmodel.GetDaemonsByIDs(db, []int64{app.Daemons[0].ID})
+	require.NoError(t, err)
+
+	ctx, err := module.BeginClientClassAdd(context.Background())
+	require.NoError(t, err)
+
+	ctx, err = module.ApplyClientClassAdd(ctx, &keaconfig.ClientClass{
+		Name: "foo",
+		Test: storkutil.Ptr("member('KNOWN')"),
+	}, daemons)
+	require.NoError(t, err)
+
+	_, err = module.Commit(ctx)
+	require.NoError(t, err)
+
+	require.Len(t, agents.RecordedCommands, 2)
+	require.Equal(t, keactrl.ClassAdd, agents.RecordedCommands[0].GetCommand())
+	require.Equal(t, keactrl.ConfigWrite, agents.RecordedCommands[1].GetCommand())
+
+	// Make sure that the configuration has been updated in the database.
+	updatedDaemons, err := dbmodel.GetDaemonsByIDs(db, []int64{app.Daemons[0].ID})
+	require.NoError(t, err)
+	require.Len(t, updatedDaemons, 1)
+	clientClass := updatedDaemons[0].KeaDaemon.Config.GetClientClass("foo")
+	require.NotNil(t, clientClass)
+	require.NotNil(t, clientClass.Test)
+	require.Equal(t, "member('KNOWN')", *clientClass.Test)
+}
+
+// Test scheduling and committing client class deletion.
+func TestCommitScheduledClientClassDelete(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	agents := agentcommtest.NewKeaFakeAgents()
+	manager := newTestManager(&appstest.ManagerAccessorsWrapper{
+		DB:        db,
+		Agents:    agents,
+		DefLookup: dbmodel.NewDHCPOptionDefinitionLookup(),
+	})
+
+	module := NewConfigModule(manager)
+	require.NotNil(t, module)
+
+	server, err := dbmodeltest.NewKeaDHCPv4Server(db)
+	require.NoError(t, err)
+	err = server.Configure(`{
+		"Dhcp4": {
+			"client-classes": [
+				{
+					"name": "foo"
+				},
+				{
+					"name": "bar"
+				}
+			]
+		}
+	}`)
+	require.NoError(t, err)
+
+	app, err := server.GetKea()
+	require.NoError(t, err)
+
+	err = CommitAppIntoDB(db, app, &storktest.FakeEventCenter{}, nil, dbmodel.NewDHCPOptionDefinitionLookup())
+	require.NoError(t, err)
+
+	user := &dbmodel.SystemUser{
+		Login:    "test",
+		Lastname: "test",
+		Name:     "test",
+	}
+	_, err = dbmodel.CreateUser(db, user)
+	require.NoError(t, err)
+	require.NotZero(t, user.ID)
+
+	daemons, err := dbmodel.GetDaemonsByIDs(db, []int64{app.Daemons[0].ID})
+	require.NoError(t, err)
+
+	ctx := context.WithValue(context.Background(), config.UserContextKey, int64(user.ID))
+	ctx, err = module.ApplyClientClassDelete(ctx, "foo", daemons)
+	require.NoError(t, err)
+
+	ctx = manager.scheduleAndGetChange(ctx, t)
+
+	_, err = module.Commit(ctx)
+	require.NoError(t, err)
+
+	// The daemon lacks the libdhcp_class_cmds, so the config-set is sent.
+	require.Len(t, agents.RecordedCommands, 2)
+	require.Equal(t, keactrl.ConfigSet, agents.RecordedCommands[0].GetCommand())
+	require.Equal(t, keactrl.ConfigWrite, agents.RecordedCommands[1].GetCommand())
+
+	updatedDaemons, err := dbmodel.GetDaemonsByIDs(db, []int64{app.Daemons[0].ID})
+	require.NoError(t, err)
+	require.Len(t, updatedDaemons, 1)
+	require.Nil(t, updatedDaemons[0].KeaDaemon.Config.GetClientClass("foo"))
+	require.NotNil(t, updatedDaemons[0].KeaDaemon.Config.GetClientClass("bar"))
+}
+
+// Returns test daemons with the configurations holding a custom option
+// definition.
+func getTestOptionDefDaemons(t *testing.T) []dbmodel.Daemon {
+	config1, err := dbmodel.NewKeaConfigFromJSON(`{
+		"Dhcp4": {
+			"option-def": [
+				{
+					"name": "foo",
+					"code": 222,
+					"type": "uint32",
+					"space": "foo-space"
+				}
+			]
+		}
+	}`)
+	require.NoError(t, err)
+
+	config2, err := dbmodel.NewKeaConfigFromJSON(`{
+		"Dhcp6": {
+			"option-def": [
+				{
+					"name": "foo",
+					"code": 222,
+					"type": "uint32",
+					"space": "foo-space"
+				}
+			]
+		}
+	}`)
+	require.NoError(t, err)
+
+	return []dbmodel.Daemon{
+		{
+			ID:   1,
+			Name: dbmodel.DaemonNameDHCPv4,
+			KeaDaemon: &dbmodel.KeaDaemon{
+				Config: config1,
+			},
+			App: &dbmodel.App{
+				AccessPoints: []*dbmodel.AccessPoint{
+					{
+						Type:    dbmodel.AccessPointControl,
+						Address: "192.0.2.1",
+						Port:    1234,
+					},
+				},
+			},
+		},
+		{
+			ID:   2,
+			Name: dbmodel.DaemonNameDHCPv6,
+			KeaDaemon: &dbmodel.KeaDaemon{
+				Config: config2,
+			},
+			App: &dbmodel.App{
+				AccessPoints: []*dbmodel.AccessPoint{
+					{
+						Type:    dbmodel.AccessPointControl,
+						Address: "192.0.2.2",
+						Port:    2345,
+					},
+				},
+			},
+		},
+	}
+}
+
+// Test first stage of adding a new option definition.
+func TestBeginOptionDefAdd(t *testing.T) {
+	module := NewConfigModule(nil)
+	require.NotNil(t, module)
+
+	ctx, err := module.BeginOptionDefAdd(context.Background())
+	require.NoError(t, err)
+
+	state, ok := config.GetTransactionState[ConfigRecipe](ctx)
+	require.True(t, ok)
+	require.Len(t, state.Updates, 1)
+	require.Equal(t, datamodel.AppTypeKea, state.Updates[0].Target)
+	require.Equal(t, "option_def_add", state.Updates[0].Operation)
+}
+
+// Test second stage of adding a new option definition. The config-set
+// and config-write commands should be prepared for each daemon.
+func TestApplyOptionDefAdd(t *testing.T) {
+	module := NewConfigModule(nil)
+	require.NotNil(t, module)
+
+	daemons := getTestOptionDefDaemons(t)
+
+	ctx, err := module.BeginOptionDefAdd(context.Background())
+	require.NoError(t, err)
+
+	optionDef := &keaconfig.OptionDef{
+		Name:        "bar",
+		Code:        1,
+		OptionType:  keaconfig.RecordOption,
+		RecordTypes: "uint8,string",
+		Space:       "bar-space",
+	}
+	ctx, err = module.ApplyOptionDefAdd(ctx, optionDef, daemons)
+	require.NoError(t, err)
+
+	state, ok := config.GetTransactionState[ConfigRecipe](ctx)
+	require.True(t, ok)
+	require.Len(t, state.Updates, 1)
+	recipe := state.Updates[0].Recipe
+	require.Equal(t, optionDef, recipe.OptionDefAfterUpdate)
+	require.NotNil(t, recipe.OptionDefCode)
+	require.EqualValues(t, 1, *recipe.OptionDefCode)
+	require.NotNil(t, recipe.OptionDefSpace)
+	require.Equal(t, "bar-space", *recipe.OptionDefSpace)
+	require.Len(t, recipe.KeaDaemonsAfterConfigUpdate, 2)
+
+	for _, daemon := range recipe.KeaDaemonsAfterConfigUpdate {
+		require.NotNil(t, daemon.KeaDaemon.Config.GetOptionDef(1, "bar-space"))
+	}
+
+	commands := recipe.Commands
+	require.Len(t, commands, 4)
+	require.JSONEq(t, `{
+		"command": "config-set",
+		"service": [ "dhcp4" ],
+		"arguments": {
+			"Dhcp4": {
+				"option-def": [
+					{
+						"name": "foo",
+						"code": 222,
+						"type": "uint32",
+						"space": "foo-space"
+					},
+					{
+						"name": "bar",
+						"code": 1,
+						"type": "record",
+						"record-types": "uint8,string",
+						"space": "bar-space"
+					}
+				]
+			}
+		}
+	}`, commands[0].Command.Marshal())
+	require.Equal(t, keactrl.ConfigSet, commands[1].Command.GetCommand())
+	require.Equal(t, keactrl.ConfigWrite, commands[2].Command.GetCommand())
+	require.Equal(t, keactrl.ConfigWrite, commands[3].Command.GetCommand())
+	require.Equal(t, daemons[0].App, commands[0].App)
+	require.Equal(t, daemons[1].App, commands[1].App)
+}
+
+// Test that adding an option definition which redefines a standard option
+// or already exists fails.
+func TestApplyOptionDefAddInvalid(t *testing.T) {
+	module := NewConfigModule(nil)
+	require.NotNil(t, module)
+
+	ctx, err := module.BeginOptionDefAdd(context.Background())
+	require.NoError(t, err)
+
+	// The code 23 is the standard DNS servers option in the DHCPv6 space.
+	_, err = module.ApplyOptionDefAdd(ctx, &keaconfig.OptionDef{
+		Name:       "bar",
+		Code:       23,
+		OptionType: keaconfig.IPv6AddressOption,
+		Space:      "dhcp6",
+	}, getTestOptionDefDaemons(t)[1:])
+	var invalidError *config.InvalidOptionDefError
+	require.ErrorAs(t, err, &invalidError)
+
+	_, err = module.ApplyOptionDefAdd(ctx, &keaconfig.OptionDef{
+		Name:       "bar",
+		Code:       222,
+		OptionType: keaconfig.StringOption,
+		Space:      "foo-space",
+	}, getTestOptionDefDaemons(t))
+	var existsError *config.OptionDefExistsError
+	require.ErrorAs(t, err, &existsError)
+
+	_, err = module.ApplyOptionDefAdd(ctx, &keaconfig.OptionDef{Name: "bar"}, []dbmodel.Daemon{})
+	require.ErrorContains(t, err, "applied option definition bar is not associated with any daemon")
+}
+
+// Test first stage of updating an option definition. It checks that the
+// daemons' configurations are fetched from the database and the locks are
+// applied.
+func TestBeginOptionDefUpdate(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	manager := newTestManager(&appstest.ManagerAccessorsWrapper{
+		DB: db,
+	})
+	module := NewConfigModule(manager)
+	require.NotNil(t, module)
+
+	server, err := dbmodeltest.NewKeaDHCPv4Server(db)
+	require.NoError(t, err)
+	err = server.Configure(`{
+		"Dhcp4": {
+			"option-def": [
+				{
+					"name": "foo",
+					"code": 222,
+					"type": "uint32"
+				}
+			]
+		}
+	}`)
+	require.NoError(t, err)
+
+	app, err := server.GetKea()
+	require.NoError(t, err)
+
+	err = CommitAppIntoDB(db, app, &storktest.FakeEventCenter{}, nil, dbmodel.NewDHCPOptionDefinitionLookup())
+	require.NoError(t, err)
+
+	daemonID := app.Daemons[0].ID
+
+	// Non-existing option definition.
+	_, err = module.BeginOptionDefUpdate(context.Background(), 223, "dhcp4", []int64{daemonID})
+	var notFoundError *config.OptionDefNotFoundError
+	require.ErrorAs(t, err, &notFoundError)
+
+	// Non-existing daemon.
+	_, err = module.BeginOptionDefUpdate(context.Background(), 222, "dhcp4", []int64{daemonID + 1000})
+	var daemonsNotFoundError *config.SomeDaemonsNotFoundError
+	require.ErrorAs(t, err, &daemonsNotFoundError)
+
+	ctx, err := module.BeginOptionDefUpdate(context.Background(), 222, "dhcp4", []int64{daemonID})
+	require.NoError(t, err)
+	require.Contains(t, manager.locks, daemonID)
+
+	state, ok := config.GetTransactionState[ConfigRecipe](ctx)
+	require.True(t, ok)
+	require.Len(t, state.Updates, 1)
+	require.Equal(t, "option_def_update", state.Updates[0].Operation)
+	recipe := state.Updates[0].Recipe
+	require.Len(t, recipe.KeaDaemonsBeforeConfigUpdate, 1)
+	require.NotNil(t, recipe.OptionDefCode)
+	require.EqualValues(t, 222, *recipe.OptionDefCode)
+}
+
+// Test second stage of updating an option definition.
+func TestApplyOptionDefUpdate(t *testing.T) {
+	module := NewConfigModule(nil)
+	require.NotNil(t, module)
+
+	daemons := getTestOptionDefDaemons(t)
+
+	state := config.NewTransactionStateWithUpdate[ConfigRecipe](datamodel.AppTypeKea, "option_def_update", 1, 2)
+	recipe := ConfigRecipe{
+		GlobalConfigRecipeParams: GlobalConfigRecipeParams{
+			KeaDaemonsBeforeConfigUpdate: daemons,
+		},
+		OptionDefConfigRecipeParams: OptionDefConfigRecipeParams{
+			OptionDefCode:  storkutil.Ptr(uint16(222)),
+			OptionDefSpace: storkutil.Ptr("foo-space"),
+		},
+	}
+	err := state.SetRecipeForUpdate(0, &recipe)
+	require.NoError(t, err)
+	ctx := context.WithValue(context.Background(), config.StateContextKey, *state)
+
+	// Changing the code is not allowed.
+	_, err = module.ApplyOptionDefUpdate(ctx, &keaconfig.OptionDef{
+		Name:       "foo",
+		Code:       223,
+		OptionType: keaconfig.StringOption,
+		Space:      "foo-space",
+	})
+	require.ErrorContains(t, err, "changing the code or space of the option definition foo is not supported")
+
+	ctx, err = module.ApplyOptionDefUpdate(ctx, &keaconfig.OptionDef{
+		Name:       "foo",
+		Code:       222,
+		OptionType: keaconfig.StringOption,
+		Space:      "foo-space",
+	})
+	require.NoError(t, err)
+
+	returnedState, ok := config.GetTransactionState[ConfigRecipe](ctx)
+	require.True(t, ok)
+	require.Len(t, returnedState.Updates, 1)
+	commands := returnedState.Updates[0].Recipe.Commands
+	require.Len(t, commands, 4)
+	require.JSONEq(t, `{
+		"command": "config-set",
+		"service": [ "dhcp4" ],
+		"arguments": {
+			"Dhcp4": {
+				"option-def": [
+					{
+						"name": "foo",
+						"code": 222,
+						"type": "string",
+						"space": "foo-space"
+					}
+				]
+			}
+		}
+	}`, commands[0].Command.Marshal())
+	require.Equal(t, keactrl.ConfigSet, commands[1].Command.GetCommand())
+	require.Equal(t, keactrl.ConfigWrite, commands[2].Command.GetCommand())
+	require.Equal(t, keactrl.ConfigWrite, commands[3].Command.GetCommand())
+}
+
+// Test preparing the commands deleting an option definition.
+func TestApplyOptionDefDelete(t *testing.T) {
+	module := NewConfigModule(nil)
+	require.NotNil(t, module)
+
+	// The option definition does not exist.
+	_, err := module.ApplyOptionDefDelete(context.Background(), 223, "foo-space", getTestOptionDefDaemons(t))
+	var notFoundError *config.OptionDefNotFoundError
+	require.ErrorAs(t, err, &notFoundError)
+
+	ctx, err := module.ApplyOptionDefDelete(context.Background(), 222, "foo-space", getTestOptionDefDaemons(t))
+	require.NoError(t, err)
+
+	state, ok := config.GetTransactionState[ConfigRecipe](ctx)
+	require.True(t, ok)
+	require.Len(t, state.Updates, 1)
+	require.Equal(t, "option_def_delete", state.Updates[0].Operation)
+	require.Equal(t, []int64{1, 2}, state.Updates[0].DaemonIDs)
+
+	commands := state.Updates[0].Recipe.Commands
+	require.Len(t, commands, 4)
+	require.JSONEq(t, `{
+		"command": "config-set",
+		"service": [ "dhcp4" ],
+		"arguments": {
+			"Dhcp4": {}
+		}
+	}`, commands[0].Command.Marshal())
+	require.JSONEq(t, `{
+		"command": "config-set",
+		"service": [ "dhcp6" ],
+		"arguments": {
+			"Dhcp6": {}
+		}
+	}`, commands[1].Command.Marshal())
+	require.Equal(t, keactrl.ConfigWrite, commands[2].Command.GetCommand())
+	require.Equal(t, keactrl.ConfigWrite, commands[3].Command.GetCommand())
+}
+
+// Test committing a new option definition. It checks that the commands are
+// sent to Kea and the configuration is updated in the database.
+func TestCommitOptionDefAdd(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	agents := agentcommtest.NewKeaFakeAgents()
+	manager := newTestManager(&appstest.ManagerAccessorsWrapper{
+		DB:        db,
+		Agents:    agents,
+		DefLookup: dbmodel.NewDHCPOptionDefinitionLookup(),
+	})
+
+	module := NewConfigModule(manager)
+	require.NotNil(t, module)
+
+	server, err := dbmodeltest.NewKeaDHCPv4Server(db)
+	require.NoError(t, err)
+	err = server.Configure(`{
+		"Dhcp4": {}
+	}`)
+	require.NoError(t, err)
+
+	app, err := server.GetKea()
+	require.NoError(t, err)
+
+	err = CommitAppIntoDB(db, app, &storktest.FakeEventCenter{}, nil, dbmodel.NewDHCPOptionDefinitionLookup())
+	require.NoError(t, err)
+
+	daemons, err := dbmodel.GetDaemonsByIDs(db, []int64{app.Daemons[0].ID})
+	require.NoError(t, err)
+
+	ctx, err := module.BeginOptionDefAdd(context.Background())
+	require.NoError(t, err)
+
+	ctx, err = module.ApplyOptionDefAdd(ctx, &keaconfig.OptionDef{
+		Name:       "foo",
+		Code:       222,
+		OptionType: keaconfig.StringOption,
+	}, daemons)
+	require.NoError(t, err)
+
+	_, err = module.Commit(ctx)
+	require.NoError(t, err)
+
+	require.Len(t, agents.RecordedCommands, 2)
+	require.Equal(t, keactrl.ConfigSet, agents.RecordedCommands[0].GetCommand())
+	require.Equal(t, keactrl.ConfigWrite, agents.RecordedCommands[1].GetCommand())
+
+	// Make sure that the configuration has been updated in the database.
+	updatedDaemons, err := dbmodel.GetDaemonsByIDs(db, []int64{app.Daemons[0].ID})
+	require.NoError(t, err)
+	require.Len(t, updatedDaemons, 1)
+	optionDef := updatedDaemons[0].KeaDaemon.Config.GetOptionDef(222, "dhcp4")
+	require.NotNil(t, optionDef)
+	require.Equal(t, "foo", optionDef.Name)
+}
+
+// Test previewing the global parameters update. The preview should include
+// the commands and the configuration changes for each daemon. The original
+// configurations should remain intact.
+func TestPreviewGlobalParametersUpdate(t *testing.T) {
+	daemons := getTestClientClassDaemons(t)
+	daemons[0].App.ID = 10
+	daemons[0].App.Name = "kea@192.0.2.1"
+	// The hash returned by Kea is removed from the updated configurations.
+	// It must not be reported as a change.
+	for _, daemon := range daemons {
+		daemon.KeaDaemon.Config.Raw["hash"] = "1234"
+	}
+
+	module := NewConfigModule(nil)
+	require.NotNil(t, module)
+
+	state := config.NewTransactionStateWithUpdate[ConfigRecipe](datamodel.AppTypeKea, "global_parameters_update", 1, 2)
+	recipe := ConfigRecipe{
+		GlobalConfigRecipeParams: GlobalConfigRecipeParams{
+			KeaDaemonsBeforeConfigUpdate: daemons,
+		},
+	}
+	err := state.SetRecipeForUpdate(0, &recipe)
+	require.NoError(t, err)
+	ctx := context.WithValue(context.Background(), config.StateContextKey, *state)
+
+	config1 := keaconfig.NewSettableDHCPv4Config()
+	config1.SetValidLifetime(storkutil.Ptr(int64(1111)))
+	config2 := keaconfig.NewSettableDHCPv4Config()
+	config2.SetValidLifetime(storkutil.Ptr(int64(2222)))
+
+	ctx, err = module.ApplyGlobalParametersUpdate(ctx, []config.AnnotatedEntity[*keaconfig.SettableConfig]{
+		*config.NewAnnotatedEntity(1, config1),
+		*config.NewAnnotatedEntity(2, config2),
+	})
+	require.NoError(t, err)
+
+	previews, err := module.Preview(ctx, false)
+	require.NoError(t, err)
+	require.Len(t, previews, 2)
+
+	require.EqualValues(t, 1, previews[0].DaemonID)
+	require.Equal(t, dbmodel.DaemonNameDHCPv4, previews[0].DaemonName)
+	require.EqualValues(t, 10, previews[0].AppID)
+	require.Equal(t, "kea@192.0.2.1", previews[0].AppName)
+	require.Len(t, previews[0].Commands, 2)
+	require.Equal(t, keactrl.ConfigSet, previews[0].Commands[0].GetCommand())
+	require.Equal(t, keactrl.ConfigWrite, previews[0].Commands[1].GetCommand())
+	require.Len(t, previews[0].Changes, 1)
+	require.Equal(t, "/Dhcp4/valid-lifetime", previews[0].Changes[0].Path)
+	require.Equal(t, storkutil.JSONDiffOperationAdd, previews[0].Changes[0].Operation)
+	require.EqualValues(t, 1111, previews[0].Changes[0].After)
+
+	require.EqualValues(t, 2, previews[1].DaemonID)
+	require.Len(t, previews[1].Commands, 2)
+	require.Len(t, previews[1].Changes, 1)
+	require.EqualValues(t, 2222, previews[1].Changes[0].After)
+
+	// The original configurations should not be modified.
+	for _, daemon := range daemons {
+		require.Nil(t, daemon.KeaDaemon.Config.GetValidLifetimeParameters().ValidLifetime)
+	}
+}
+
+// Test previewing a new host reservation. The whole reservation should be
+// reported as added under the reservation key.
+func TestPreviewHostAdd(t *testing.T) {
+	daemon := &dbmodel.Daemon{
+		ID:   1,
+		Name: dbmodel.DaemonNameDHCPv4,
+		App: &dbmodel.App{
+			ID:   10,
+			Name: "kea@192.0.2.1",
+		},
+	}
+	host := &dbmodel.Host{
+		HostIdentifiers: []dbmodel.HostIdentifier{
+			{
+				Type:  "hw-address",
+				Value: []byte{1, 2, 3, 4, 5, 6},
+			},
+		},
+		LocalHosts: []dbmodel.LocalHost{
+			{
+				DaemonID:   1,
+				Daemon:     daemon,
+				Hostname:   "foo.example.org",
+				DataSource: dbmodel.HostDataSourceAPI,
+			},
+		},
+	}
+
+	manager := newTestManager(&appstest.ManagerAccessorsWrapper{
+		DefLookup: dbmodel.NewDHCPOptionDefinitionLookup(),
+	})
+	module := NewConfigModule(manager)
+	require.NotNil(t, module)
+
+	ctx := context.WithValue(context.Background(), config.StateContextKey,
+		*config.NewTransactionStateWithUpdate[ConfigRecipe](datamodel.AppTypeKea, "host_add", 1))
+
+	ctx, err := module.ApplyHostAdd(ctx, host)
+	require.NoError(t, err)
+
+	previews, err := module.Preview(ctx, false)
+	require.NoError(t, err)
+	require.Len(t, previews, 1)
+
+	require.Len(t, previews[0].Changes, 1)
+	require.Equal(t, "/reservation", previews[0].Changes[0].Path)
+	require.Equal(t, storkutil.JSONDiffOperationAdd, previews[0].Changes[0].Operation)
+	require.Nil(t, previews[0].Changes[0].Before)
+	require.IsType(t, map[string]any{}, previews[0].Changes[0].After)
+	require.Equal(t, "foo.example.org", previews[0].Changes[0].After.(map[string]any)["hostname"])
+}
+
+// Test previewing the host reservation update. The preview should include
+// the differences between the reservations before and after the update.
+func TestPreviewHostUpdate(t *testing.T) {
+	daemon := &dbmodel.Daemon{
+		ID:   1,
+		Name: dbmodel.DaemonNameDHCPv4,
+		App: &dbmodel.App{
+			ID:   10,
+			Name: "kea@192.0.2.1",
+		},
+	}
+	hostBeforeUpdate := &dbmodel.Host{
+		ID: 1,
+		HostIdentifiers: []dbmodel.HostIdentifier{
+			{
+				Type:  "hw-address",
+				Value: []byte{1, 2, 3, 4, 5, 6},
+			},
+		},
+		LocalHosts: []dbmodel.LocalHost{
+			{
+				DaemonID:   1,
+				Daemon:     daemon,
+				Hostname:   "foo.example.org",
+				DataSource: dbmodel.HostDataSourceAPI,
+			},
+		},
+	}
+	hostAfterUpdate := &dbmodel.Host{
+		ID: 1,
+		HostIdentifiers: []dbmodel.HostIdentifier{
+			{
+				Type:  "hw-address",
+				Value: []byte{1, 2, 3, 4, 5, 6},
+			},
+		},
+		LocalHosts: []dbmodel.LocalHost{
+			{
+				DaemonID:   1,
+				Daemon:     daemon,
+				Hostname:   "bar.example.org",
+				DataSource: dbmodel.HostDataSourceAPI,
+			},
+		},
+	}
+
+	manager := newTestManager(&appstest.ManagerAccessorsWrapper{
+		DefLookup: dbmodel.NewDHCPOptionDefinitionLookup(),
+	})
+	module := NewConfigModule(manager)
+	require.NotNil(t, module)
+
+	state := config.NewTransactionStateWithUpdate[ConfigRecipe](datamodel.AppTypeKea, "host_update", 1)
+	recipe := ConfigRecipe{
+		HostConfigRecipeParams: HostConfigRecipeParams{
+			HostBeforeUpdate: hostBeforeUpdate,
+		},
+	}
+	err := state.SetRecipeForUpdate(0, &recipe)
+	require.NoError(t, err)
+	ctx := context.WithValue(context.Background(), config.StateContextKey, *state)
+
+	ctx, err = module.ApplyHostUpdate(ctx, hostAfterUpdate)
+	require.NoError(t, err)
+
+	previews, err := module.Preview(ctx, false)
+	require.NoError(t, err)
+	require.Len(t, previews, 1)
+
+	require.EqualValues(t, 1, previews[0].DaemonID)
+	require.EqualValues(t, 10, previews[0].AppID)
+	require.Len(t, previews[0].Commands, 2)
+	require.Equal(t, keactrl.ReservationDel, previews[0].Commands[0].GetCommand())
+	require.Equal(t, keactrl.ReservationAdd, previews[0].Commands[1].GetCommand())
+
+	require.Len(t, previews[0].Changes, 1)
+	require.Equal(t, "/reservation/hostname", previews[0].Changes[0].Path)
+	require.Equal(t, storkutil.JSONDiffOperationReplace, previews[0].Changes[0].Operation)
+	require.Equal(t, "foo.example.org", previews[0].Changes[0].Before)
+	require.Equal(t, "bar.example.org", previews[0].Changes[0].After)
+}
+
+// Test previewing a new client class applied on a copy of the transaction
+// state. The original transaction state should remain intact, so the
+// transaction can be submitted after the preview.
+func TestPreviewClientClassUpdate(t *testing.T) {
+	module := NewConfigModule(nil)
+	require.NotNil(t, module)
+
+	daemons := getTestClientClassDaemons(t)
+
+	state := config.NewTransactionStateWithUpdate[ConfigRecipe](datamodel.AppTypeKea, "client_class_update", 1, 2)
+	recipe := ConfigRecipe{
+		GlobalConfigRecipeParams: GlobalConfigRecipeParams{
+			KeaDaemonsBeforeConfigUpdate: daemons,
+		},
+		ClientClassConfigRecipeParams: ClientClassConfigRecipeParams{
+			ClientClassName: storkutil.Ptr("foo"),
+		},
+	}
+	err := state.SetRecipeForUpdate(0, &recipe)
+	require.NoError(t, err)
+	originalCtx := context.WithValue(context.Background(), config.StateContextKey, *state)
+
+	ctx, err := config.CopyTransactionState(originalCtx)
+	require.NoError(t, err)
+	ctx, err = module.ApplyClientClassUpdate(ctx, &keaconfig.ClientClass{
+		Name: "foo",
+		Test: storkutil.Ptr("member('UNKNOWN')"),
+	})
+	require.NoError(t, err)
+
+	previews, err := module.Preview(ctx, false)
+	require.NoError(t, err)
+	require.Len(t, previews, 2)
+
+	// The first daemon has the libdhcp_class_cmds hook library.
+	require.Len(t, previews[0].Commands, 2)
+	require.Equal(t, keactrl.ClassUpdate, previews[0].Commands[0].GetCommand())
+	require.Equal(t, keactrl.ConfigWrite, previews[0].Commands[1].GetCommand())
+
+	require.Len(t, previews[1].Commands, 2)
+	require.Equal(t, keactrl.ConfigSet, previews[1].Commands[0].GetCommand())
+	require.Equal(t, keactrl.ConfigWrite, previews[1].Commands[1].GetCommand())
+
+	for _, preview := range previews {
+		require.Len(t, preview.Changes, 1)
+		require.Equal(t, "/Dhcp4/client-classes/0/test", preview.Changes[0].Path)
+		require.Equal(t, storkutil.JSONDiffOperationReplace, preview.Changes[0].Operation)
+		require.Equal(t, "member('KNOWN')", preview.Changes[0].Before)
+		require.Equal(t, "member('UNKNOWN')", preview.Changes[0].After)
+	}
+
+	// The original transaction state should not be modified.
+	originalRecipe, err := config.GetRecipeForUpdate[ConfigRecipe](originalCtx, 0)
+	require.NoError(t, err)
+	require.Empty(t, originalRecipe.Commands)
+	require.Nil(t, originalRecipe.KeaDaemonsAfterConfigUpdate)
+	for _, daemon := range originalRecipe.KeaDaemonsBeforeConfigUpdate {
+		require.Equal(t, "member('KNOWN')", *daemon.KeaDaemon.Config.GetClientClass("foo").Test)
+	}
+}
+
+// Test previewing the option definition deletion. The preview should
+// include the removed option definition.
+func TestPreviewOptionDefDelete(t *testing.T) {
+	module := NewConfigModule(nil)
+	require.NotNil(t, module)
+
+	daemons := getTestOptionDefDaemons(t)
+
+	ctx, err := module.ApplyOptionDefDelete(context.Background(), 222, "foo-space", daemons[:1])
+	require.NoError(t, err)
+
+	previews, err := module.Preview(ctx, false)
+	require.NoError(t, err)
+	require.Len(t, previews, 1)
+	require.Len(t, previews[0].Commands, 2)
+	require.Len(t, previews[0].Changes, 1)
+	require.Equal(t, "/Dhcp4/option-def", previews[0].Changes[0].Path)
+	require.Equal(t, storkutil.JSONDiffOperationRemove, previews[0].Changes[0].Operation)
+	require.Nil(t, previews[0].Changes[0].After)
+}
+
+// Test that an error is returned when the preview is requested for the
+// context lacking the transaction state.
+func TestPreviewNoState(t *testing.T) {
+	module := NewConfigModule(nil)
+	require.NotNil(t, module)
+
+	previews, err := module.Preview(context.Background(), false)
+	require.ErrorContains(t, err, "context lacks state")
+	require.Nil(t, previews)
+}
+
+// Test preparing the commands restoring the daemon's configuration from
+// a configuration revision.
+func TestApplyConfigRollback(t *testing.T) {
+	manager := newTestManager(&appstest.ManagerAccessorsWrapper{})
+	module := NewConfigModule(manager)
+	require.NotNil(t, module)
+
+	daemons := getTestClientClassDaemons(t)
+
+	revisionConfig, err := dbmodel.NewKeaConfigFromJSON(`{
+		"Dhcp4": {
+			"valid-lifetime": 1000
+		}
+	}`)
+	require.NoError(t, err)
+
+	revision := &dbmodel.KeaConfigRevision{
+		ID:       10,
+		DaemonID: daemons[0].ID,
+		Config:   revisionConfig,
+	}
+
+	ctx, err := module.ApplyConfigRollback(context.Background(), daemons[0], revision, true)
+	require.NoError(t, err)
+
+	// The daemon's configuration should be locked.
+	require.Contains(t, manager.locks, daemons[0].ID)
+
+	state, ok := config.GetTransactionState[ConfigRecipe](ctx)
+	require.True(t, ok)
+	require.Len(t, state.Updates, 1)
+	require.Equal(t, "config_rollback", state.Updates[0].Operation)
+	require.Equal(t, []int64{daemons[0].ID}, state.Updates[0].DaemonIDs)
+
+	recipe := state.Updates[0].Recipe
+	require.Len(t, recipe.KeaDaemonsBeforeConfigUpdate, 1)
+	require.Len(t, recipe.KeaDaemonsAfterConfigUpdate, 1)
+	require.Empty(t, recipe.KeaDaemonsAfterConfigUpdate[0].KeaDaemon.ConfigHash)
+	require.EqualValues(t, 1000, *recipe.KeaDaemonsAfterConfigUpdate[0].KeaDaemon.Config.GetValidLifetimeParameters().ValidLifetime)
+
+	// The original configuration should be unchanged.
+	require.NotNil(t, daemons[0].KeaDaemon.Config.GetClientClass("foo"))
+	require.Nil(t, daemons[0].KeaDaemon.Config.GetValidLifetimeParameters().ValidLifetime)
+
+	commands := recipe.Commands
+	require.Len(t, commands, 2)
+	require.JSONEq(t, `{
+		"command": "config-set",
+		"service": [ "dhcp4" ],
+		"arguments": {
+			"Dhcp4": {
+				"valid-lifetime": 1000
+			}
+		}
+	}`, commands[0].Command.Marshal())
+	require.Equal(t, keactrl.ConfigWrite, commands[1].Command.GetCommand())
+
+	// The config-write command should not be sent when not requested.
+	ctx, err = module.ApplyConfigRollback(context.Background(), daemons[0], revision, false)
+	require.NoError(t, err)
+	state, ok = config.GetTransactionState[ConfigRecipe](ctx)
+	require.True(t, ok)
+	require.Len(t, state.Updates[0].Recipe.Commands, 1)
+
+	// The preview should show the differences between the current and the
+	// restored configuration.
+	previews, err := module.Preview(ctx, false)
+	require.NoError(t, err)
+	require.Len(t, previews, 1)
+	require.Len(t, previews[0].Changes, 3)
+	require.Equal(t, "/Dhcp4/client-classes", previews[0].Changes[0].Path)
+	require.Equal(t, storkutil.JSONDiffOperationRemove, previews[0].Changes[0].Operation)
+	require.Equal(t, "/Dhcp4/hooks-libraries", previews[0].Changes[1].Path)
+	require.Equal(t, storkutil.JSONDiffOperationRemove, previews[0].Changes[1].Operation)
+	require.Equal(t, "/Dhcp4/valid-lifetime", previews[0].Changes[2].Path)
+	require.Equal(t, storkutil.JSONDiffOperationAdd, previews[0].Changes[2].Operation)
+}
+
+// Test that the sensitive data are hidden in the preview of the commands
+// and the configuration changes when requested, and that the commands to
+// be sent upon commit are not modified.
+func TestPreviewHideSensitiveData(t *testing.T) {
+	manager := newTestManager(&appstest.ManagerAccessorsWrapper{})
+	module := NewConfigModule(manager)
+	require.NotNil(t, module)
+
+	daemons := getTestClientClassDaemons(t)
+
+	revisionConfig, err := dbmodel.NewKeaConfigFromJSON(`{
+		"Dhcp4": {
+			"lease-database": {
+				"type": "postgresql",
+				"user": "kea",
+				"password": "secret-password"
+			}
+		}
+	}`)
+	require.NoError(t, err)
+
+	revision := &dbmodel.KeaConfigRevision{
+		ID:       10,
+		DaemonID: daemons[0].ID,
+		Config:   revisionConfig,
+	}
+
+	ctx, err := module.ApplyConfigRollback(context.Background(), daemons[0], revision, false)
+	require.NoError(t, err)
+
+	// The sensitive data are hidden.
+	previews, err := module.Preview(ctx, true)
+	require.NoError(t, err)
+	require.Len(t, previews, 1)
+	require.Len(t, previews[0].Commands, 1)
+	require.NotContains(t, previews[0].Commands[0].Marshal(), "secret-password")
+	require.JSONEq(t, `{
+		"command": "config-set",
+		"service": [ "dhcp4" ],
+		"arguments": {
+			"Dhcp4": {
+				"lease-database": {
+					"type": "postgresql",
+					"user": "kea",
+					"password": null
+				}
+			}
+		}
+	}`, previews[0].Commands[0].Marshal())
+
+	index := slices.IndexFunc(previews[0].Changes, func(change storkutil.JSONDiffEntry) bool {
+		return change.Path == "/Dhcp4/lease-database"
+	})
+	require.GreaterOrEqual(t, index, 0)
+	require.Equal(t, map[string]any{
+		"type":     "postgresql",
+		"user":     "kea",
+		"password": nil,
+	}, previews[0].Changes[index].After)
+
+	// The command sent upon commit still includes the password.
+	state, ok := config.GetTransactionState[ConfigRecipe](ctx)
+	require.True(t, ok)
+	require.Contains(t, state.Updates[0].Recipe.Commands[0].Command.Marshal(), "secret-password")
+
+	// The sensitive data are returned when they are not hidden.
+	previews, err = module.Preview(ctx, false)
+	require.NoError(t, err)
+	require.Contains(t, previews[0].Commands[0].Marshal(), "secret-password")
+	index = slices.IndexFunc(previews[0].Changes, func(change storkutil.JSONDiffEntry) bool {
+		return change.Path == "/Dhcp4/lease-database"
+	})
+	require.GreaterOrEqual(t, index, 0)
+	require.Equal(t, "secret-password", previews[0].Changes[index].After.(map[string]any)["password"])
+}
+
+// Test that restoring the configuration revision fails for invalid input.
+func TestApplyConfigRollbackInvalid(t *testing.T) {
+	module := NewConfigModule(nil)
+	require.NotNil(t, module)
+
+	daemons := getTestClientClassDaemons(t)
+
+	// No revision.
+	_, err := module.ApplyConfigRollback(context.Background(), daemons[0], nil, true)
+	require.ErrorContains(t, err, "no configuration revision specified for daemon 1")
+
+	// The daemon using the configuration backend.
+	daemon := getTestConfigBackendDaemon(t, 1, getTestConfigBackendConfig4())
+	_, err = module.ApplyConfigRollback(context.Background(), daemon, &dbmodel.KeaConfigRevision{
+		ID:       10,
+		DaemonID: daemon.ID,
+		Config:   daemon.KeaDaemon.Config,
+	}, true)
+	require.ErrorContains(t, err, "daemon 1 fetches its configuration from the configuration backend and its configuration revision 10 cannot be restored")
+
+	// The revision belongs to another daemon.
+	revision := &dbmodel.KeaConfigRevision{
+		ID:       10,
+		DaemonID: daemons[1].ID,
+		Config:   daemons[1].KeaDaemon.Config,
+	}
+	_, err = module.ApplyConfigRollback(context.Background(), daemons[0], revision, true)
+	require.ErrorContains(t, err, "configuration revision 10 does not belong to daemon 1")
+
+	// No app.
+	revision.DaemonID = daemons[0].ID
+	daemons[0].App = nil
+	_, err = module.ApplyConfigRollback(context.Background(), daemons[0], revision, true)
+	require.ErrorContains(t, err, "daemon 1 has nil app")
+}
+
+// Test committing the configuration restored from a revision. It checks
+// that the commands are sent to Kea, the configuration is updated in the
+// database and the subsequent revision is associated with the rollback.
+func TestCommitConfigRollback(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	agents := agentcommtest.NewKeaFakeAgents()
+	manager := newTestManager(&appstest.ManagerAccessorsWrapper{
+		DB:        db,
+		Agents:    agents,
+		DefLookup: dbmodel.NewDHCPOptionDefinitionLookup(),
+	})
+
+	module := NewConfigModule(manager)
+	require.NotNil(t, module)
+
+	user := &dbmodel.SystemUser{
+		Login:    "test",
+		Lastname: "test",
+		Name:     "test",
+	}
+	_, err := dbmodel.CreateUser(db, user)
+	require.NoError(t, err)
+
+	server, err := dbmodeltest.NewKeaDHCPv4Server(db)
+	require.NoError(t, err)
+	err = server.Configure(`{
+		"Dhcp4": {
+			"valid-lifetime": 1000
+		}
+	}`)
+	require.NoError(t, err)
+
+	app, err := server.GetKea()
+	require.NoError(t, err)
+
+	// Committing the app should store the first configuration revision.
+	err = CommitAppIntoDB(db, app, &storktest.FakeEventCenter{}, nil, dbmodel.NewDHCPOptionDefinitionLookup())
+	require.NoError(t, err)
+
+	revision, err := dbmodel.GetLatestKeaConfigRevision(db, app.Daemons[0].ID)
+	require.NoError(t, err)
+	require.NotNil(t, revision)
+
+	// Change the configuration and store it as a new revision.
+	daemon, err := dbmodel.GetDaemonByID(db, app.Daemons[0].ID)
+	require.NoError(t, err)
+	err = daemon.SetConfigFromJSON(`{
+		"Dhcp4": {
+			"valid-lifetime": 2000
+		}
+	}`)
+	require.NoError(t, err)
+	err = dbmodel.UpdateDaemon(db, daemon)
+	require.NoError(t, err)
+	newRevision, err := dbmodel.AddKeaConfigRevision(db, daemon)
+	require.NoError(t, err)
+	require.NotNil(t, newRevision)
+
+	// Restore the first revision.
+	ctx := context.WithValue(context.Background(), config.UserContextKey, int64(user.ID))
+	ctx, err = module.ApplyConfigRollback(ctx, *daemon, revision, true)
+	require.NoError(t, err)
+
+	_, err = module.Commit(ctx)
+	require.NoError(t, err)
+
+	require.Len(t, agents.RecordedCommands, 2)
+	require.Equal(t, keactrl.ConfigSet, agents.RecordedCommands[0].GetCommand())
+	require.Equal(t, keactrl.ConfigWrite, agents.RecordedCommands[1].GetCommand())
+
+	// Make sure that the configuration has been updated in the database.
+	daemon, err = dbmodel.GetDaemonByID(db, app.Daemons[0].ID)
+	require.NoError(t, err)
+	require.EqualValues(t, 1000, *daemon.KeaDaemon.Config.GetValidLifetimeParameters().ValidLifetime)
+
+	// The next revision fetched by the puller should be associated with
+	// the user and the rollback operation.
+	daemon.KeaDaemon.ConfigHash = revision.ConfigHash
+	rollbackRevision, err := dbmodel.AddKeaConfigRevision(db, daemon)
+	require.NoError(t, err)
+	require.NotNil(t, rollbackRevision)
+	require.EqualValues(t, user.ID, rollbackRevision.UserID)
+	require.Equal(t, "config_rollback", rollbackRevision.Operation)
+}
+
+// Returns the test configuration of the DHCPv4 server using the
+// configuration backend.
+func getTestConfigBackendConfig4() string {
+	return `{
+		"Dhcp4": {
+			"server-tag": "server1",
+			"valid-lifetime": 3000,
+			"client-classes": [
+				{
+					"name": "foo",
+					"test": "member('KNOWN')"
+				}
+			],
+			"config-control": {
+				"config-databases": [
+					{
+						"type": "mysql",
+						"name": "kea"
+					}
+				]
+			},
+			"hooks-libraries": [
+				{
+					"library": "/usr/lib/kea/libdhcp_cb_cmds.so"
+				}
+			]
+		}
+	}`
+}
+
+// Test that the global parameters are set in the configuration backend
+// for the daemon using this backend.
+func TestApplyGlobalParametersUpdateConfigBackend(t *testing.T) {
+	daemons := []dbmodel.Daemon{
+		getTestConfigBackendDaemon(t, 1, getTestConfigBackendConfig4()),
+	}
+	manager := newTestManager(&appstest.ManagerAccessorsWrapper{
+		DefLookup: dbmodel.NewDHCPOptionDefinitionLookup(),
+	})
+	module := NewConfigModule(manager)
+
+	state := config.NewTransactionStateWithUpdate[ConfigRecipe](datamodel.AppTypeKea, "global_parameters_update", 1)
+	err := state.SetRecipeForUpdate(0, &ConfigRecipe{
+		GlobalConfigRecipeParams: GlobalConfigRecipeParams{
+			KeaDaemonsBeforeConfigUpdate: daemons,
+		},
+	})
+	require.NoError(t, err)
+	ctx := context.WithValue(context.Background(), config.StateContextKey, *state)
+
+	settableConfig := keaconfig.NewSettableDHCPv4Config()
+	settableConfig.SetValidLifetime(storkutil.Ptr(int64(1111)))
+
+	ctx, err = module.ApplyGlobalParametersUpdate(ctx, []config.AnnotatedEntity[*keaconfig.SettableConfig]{
+		*config.NewAnnotatedEntity(1, settableConfig),
+	})
+	require.NoError(t, err)
+
+	recipe, err := config.GetRecipeForUpdate[ConfigRecipe](ctx, 0)
+	require.NoError(t, err)
+	require.Len(t, recipe.Commands, 2)
+	require.JSONEq(t, `{
+		"command": "remote-global-parameter4-set",
+		"service": [ "dhcp4" ],
+		"arguments": {
+			"remote": {
+				"type": "mysql"
+			},
+			"server-tags": [ "server1" ],
+			"parameters": {
+				"valid-lifetime": 1111
+			}
+		}
+	}`, recipe.Commands[0].Command.Marshal())
+	require.Equal(t, keactrl.ConfigBackendPull, recipe.Commands[1].Command.GetCommand())
+
+	// The configuration held in Stork should be updated.
+	require.Len(t, recipe.KeaDaemonsAfterConfigUpdate, 1)
+	require.EqualValues(t, 1111, *recipe.KeaDaemonsAfterConfigUpdate[0].KeaDaemon.Config.GetValidLifetimeParameters().ValidLifetime)
+}
+
+// Test that the shared network and its subnets are set in the configuration
+// backend for the daemon using this backend.
+func TestApplySharedNetworkAddConfigBackend(t *testing.T) {
+	daemon := getTestConfigBackendDaemon(t, 1, getTestConfigBackendConfig4())
+	manager := newTestManager(&appstest.ManagerAccessorsWrapper{
+		DefLookup: dbmodel.NewDHCPOptionDefinitionLookup(),
+	})
+	module := NewConfigModule(manager)
+
+	ctx, err := module.BeginSharedNetworkAdd(context.Background())
+	require.NoError(t, err)
+
+	sharedNetwork := &dbmodel.SharedNetwork{
+		Name:   "foo",
+		Family: 4,
+		LocalSharedNetworks: []*dbmodel.LocalSharedNetwork{
+			{
+				DaemonID: daemon.ID,
+				Daemon:   &daemon,
+			},
+		},
+		Subnets: []dbmodel.Subnet{
+			{
+				Prefix: "192.0.2.0/24",
+				LocalSubnets: []*dbmodel.LocalSubnet{
+					{
+						DaemonID:      daemon.ID,
+						Daemon:        &daemon,
+						LocalSubnetID: 5,
+					},
+				},
+			},
+		},
+	}
+	ctx, err = module.ApplySharedNetworkAdd(ctx, sharedNetwork)
+	require.NoError(t, err)
+
+	recipe, err := config.GetRecipeForUpdate[ConfigRecipe](ctx, 0)
+	require.NoError(t, err)
+	require.Len(t, recipe.Commands, 3)
+	require.JSONEq(t, `{
+		"command": "remote-network4-set",
+		"service": [ "dhcp4" ],
+		"arguments": {
+			"remote": {
+				"type": "mysql"
+			},
+			"server-tags": [ "server1" ],
+			"shared-networks": [
+				{
+					"name": "foo"
+				}
+			]
+		}
+	}`, recipe.Commands[0].Command.Marshal())
+	require.JSONEq(t, `{
+		"command": "remote-subnet4-set",
+		"service": [ "dhcp4" ],
+		"arguments": {
+			"remote": {
+				"type": "mysql"
+			},
+			"server-tags": [ "server1" ],
+			"subnets": [
+				{
+					"id": 5,
+					"subnet": "192.0.2.0/24",
+					"shared-network-name": "foo"
+				}
+			]
+		}
+	}`, recipe.Commands[1].Command.Marshal())
+	require.Equal(t, keactrl.ConfigBackendPull, recipe.Commands[2].Command.GetCommand())
+}
+
+// Test that the subnet is deleted from the configuration backend for the
+// daemon using this backend.
+func TestApplySubnetDeleteConfigBackend(t *testing.T) {
+	daemon := getTestConfigBackendDaemon(t, 1, getTestConfigBackendConfig4())
+	module := NewConfigModule(nil)
+
+	subnet := &dbmodel.Subnet{
+		ID:     10,
+		Prefix: "192.0.2.0/24",
+		SharedNetwork: &dbmodel.SharedNetwork{
+			Name: "foo",
+		},
+		LocalSubnets: []*dbmodel.LocalSubnet{
+			{
+				DaemonID:      daemon.ID,
+				Daemon:        &daemon,
+				LocalSubnetID: 5,
+			},
+		},
+	}
+	ctx, err := module.ApplySubnetDelete(context.Background(), subnet)
+	require.NoError(t, err)
+
+	recipe, err := config.GetRecipeForUpdate[ConfigRecipe](ctx, 0)
+	require.NoError(t, err)
+	// The subnet should not be removed from the shared network first.
+	require.Len(t, recipe.Commands, 2)
+	require.JSONEq(t, `{
+		"command": "remote-subnet4-del-by-id",
+		"service": [ "dhcp4" ],
+		"arguments": {
+			"remote": {
+				"type": "mysql"
+			},
+			"subnets": [
+				{
+					"id": 5
+				}
+			]
+		}
+	}`, recipe.Commands[0].Command.Marshal())
+	require.Equal(t, keactrl.ConfigBackendPull, recipe.Commands[1].Command.GetCommand())
+}
+
+// Test that the client classes and option definitions are set in the
+// configuration backend for the daemon using this backend.
+func TestApplyClientClassAndOptionDefConfigBackend(t *testing.T) {
+	daemons := []dbmodel.Daemon{
+		getTestConfigBackendDaemon(t, 1, getTestConfigBackendConfig4()),
+	}
+	module := NewConfigModule(nil)
+
+	ctx, err := module.BeginClientClassAdd(context.Background())
+	require.NoError(t, err)
+	ctx, err = module.ApplyClientClassAdd(ctx, &keaconfig.ClientClass{
+		Name: "bar",
+		Test: storkutil.Ptr("member('UNKNOWN')"),
+	}, daemons)
+	require.NoError(t, err)
+
+	recipe, err := config.GetRecipeForUpdate[ConfigRecipe](ctx, 0)
+	require.NoError(t, err)
+	require.Len(t, recipe.Commands, 2)
+	require.JSONEq(t, `{
+		"command": "remote-class4-set",
+		"service": [ "dhcp4" ],
+		"arguments": {
+			"remote": {
+				"type": "mysql"
+			},
+			"server-tags": [ "server1" ],
+			"client-classes": [
+				{
+					"name": "bar",
+					"test": "member('UNKNOWN')"
+				}
+			]
+		}
+	}`, recipe.Commands[0].Command.Marshal())
+	require.Equal(t, keactrl.ConfigBackendPull, recipe.Commands[1].Command.GetCommand())
+
+	ctx, err = module.ApplyClientClassDelete(context.Background(), "foo", daemons)
+	require.NoError(t, err)
+	recipe, err = config.GetRecipeForUpdate[ConfigRecipe](ctx, 0)
+	require.NoError(t, err)
+	require.Len(t, recipe.Commands, 2)
+	require.Equal(t, keactrl.RemoteClass4Del, recipe.Commands[0].Command.GetCommand())
+
+	ctx, err = module.BeginOptionDefAdd(context.Background())
+	require.NoError(t, err)
+	ctx, err = module.ApplyOptionDefAdd(ctx, &keaconfig.OptionDef{
+		Code:       222,
+		Name:       "foo",
+		Space:      "dhcp4",
+		OptionType: "uint32",
+	}, daemons)
+	require.NoError(t, err)
+	recipe, err = config.GetRecipeForUpdate[ConfigRecipe](ctx, 0)
+	require.NoError(t, err)
+	require.Len(t, recipe.Commands, 2)
+	require.Equal(t, keactrl.RemoteOptionDef4Set, recipe.Commands[0].Command.GetCommand())
+	require.Equal(t, keactrl.ConfigBackendPull, recipe.Commands[1].Command.GetCommand())
+}
+
+// Returns a test D2 daemon configuration with the DDNS domains.
+const testD2Config = `{
+	"DhcpDdns": {
+		"tsig-keys": [
+			{
+				"name": "key1",
+				"algorithm": "HMAC-SHA256",
+				"secret": "LSWXnfkKZjdPJI5QxlpnfQ=="
+			}
+		],
+		"forward-ddns": {
+			"ddns-domains": [
+				{
+					"name": "example.org.",
+					"dns-servers": [
+						{
+							"ip-address": "192.0.2.1"
+						}
+					]
+				}
+			]
+		}
+	}
+}`
+
+// Returns a transaction state for updating the DDNS domains of the test
+// D2 daemon.
+func getTestDDNSDomainsContext(t *testing.T) context.Context {
+	d2Config, err := dbmodel.NewKeaConfigFromJSON(testD2Config)
+	require.NoError(t, err)
+
+	daemon := dbmodel.Daemon{
+		ID:   1,
+		Name: dbmodel.DaemonNameD2,
+		KeaDaemon: &dbmodel.KeaDaemon{
+			Config: d2Config,
+		},
+		App: &dbmodel.App{
+			AccessPoints: []*dbmodel.AccessPoint{
+				{
+					Type:    dbmodel.AccessPointControl,
+					Address: "192.0.2.1",
+					Port:    1234,
+				},
+			},
+		},
+	}
+	state := config.NewTransactionStateWithUpdate[ConfigRecipe](datamodel.AppTypeKea, "ddns_domains_update", 1)
+	recipe := ConfigRecipe{
+		GlobalConfigRecipeParams: GlobalConfigRecipeParams{
+			KeaDaemonsBeforeConfigUpdate: []dbmodel.Daemon{daemon},
+		},
+	}
+	err = state.SetRecipeForUpdate(0, &recipe)
+	require.NoError(t, err)
+	return context.WithValue(context.Background(), config.StateContextKey, *state)
+}
+
+// Test beginning the DDNS domains update.
+func TestBeginDDNSDomainsUpdate(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	manager := newTestManager(&appstest.ManagerAccessorsWrapper{
+		DB: db,
+	})
+	module := NewConfigModule(manager)
+	require.NotNil(t, module)
+
+	server, err := dbmodeltest.NewKeaD2Server(db)
+	require.NoError(t, err)
+	err = server.Configure(testD2Config)
+	require.NoError(t, err)
+
+	dhcp4, err := dbmodeltest.NewKeaDHCPv4Server(db)
+	require.NoError(t, err)
+	err = dhcp4.Configure(`{ "Dhcp4": {} }`)
+	require.NoError(t, err)
+
+	// Non-existing daemon.
+	_, err = module.BeginDDNSDomainsUpdate(context.Background(), server.ID+1000)
+	var daemonsNotFoundError *config.SomeDaemonsNotFoundError
+	require.ErrorAs(t, err, &daemonsNotFoundError)
+
+	// Not a D2 daemon.
+	_, err = module.BeginDDNSDomainsUpdate(context.Background(), dhcp4.ID)
+	require.ErrorContains(t, err, "only be managed for the D2 daemons")
+
+	ctx, err := module.BeginDDNSDomainsUpdate(context.Background(), server.ID)
+	require.NoError(t, err)
+	require.Contains(t, manager.locks, server.ID)
+
+	state, ok := config.GetTransactionState[ConfigRecipe](ctx)
+	require.True(t, ok)
+	require.Len(t, state.Updates, 1)
+	require.Equal(t, "ddns_domains_update", state.Updates[0].Operation)
+	require.Equal(t, []int64{server.ID}, state.Updates[0].DaemonIDs)
+	require.Len(t, state.Updates[0].Recipe.KeaDaemonsBeforeConfigUpdate, 1)
+}
+
+// Test that the DDNS domain changes are accumulated in the transaction
+// and that the config-set and config-write commands are prepared.
+func TestApplyDDNSDomainChanges(t *testing.T) {
+	module := NewConfigModule(nil)
+	require.NotNil(t, module)
+
+	ctx := getTestDDNSDomainsContext(t)
+
+	ctx, err := module.ApplyDDNSDomainAdd(ctx, keaconfig.D2ReverseDDNS, &keaconfig.D2DDNSDomain{
+		Name:    "2.0.192.in-addr.arpa.",
+		KeyName: "key1",
+		DNSServers: []keaconfig.D2DNSServer{
+			{
+				IPAddress: "192.0.2.2",
+				Port:      5300,
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	ctx, err = module.ApplyDDNSDomainDelete(ctx, keaconfig.D2ForwardDDNS, "example.org")
+	require.NoError(t, err)
+
+	ctx, err = module.ApplyDDNSDomainUpdate(ctx, keaconfig.D2ReverseDDNS, &keaconfig.D2DDNSDomain{
+		Name: "2.0.192.in-addr.arpa.",
+		DNSServers: []keaconfig.D2DNSServer{
+			{
+				IPAddress: "192.0.2.3",
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	state, ok := config.GetTransactionState[ConfigRecipe](ctx)
+	require.True(t, ok)
+	require.Len(t, state.Updates, 1)
+	recipe := state.Updates[0].Recipe
+
+	// The original configuration should be intact.
+	require.Len(t, recipe.KeaDaemonsBeforeConfigUpdate[0].KeaDaemon.Config.GetDDNSDomains(keaconfig.D2ForwardDDNS), 1)
+	require.Empty(t, recipe.KeaDaemonsBeforeConfigUpdate[0].KeaDaemon.Config.GetDDNSDomains(keaconfig.D2ReverseDDNS))
+
+	// The updated configuration should include all changes.
+	require.Len(t, recipe.KeaDaemonsAfterConfigUpdate, 1)
+	updated := recipe.KeaDaemonsAfterConfigUpdate[0].KeaDaemon.Config
+	require.Empty(t, updated.GetDDNSDomains(keaconfig.D2ForwardDDNS))
+	domains := updated.GetDDNSDomains(keaconfig.D2ReverseDDNS)
+	require.Len(t, domains, 1)
+	require.Empty(t, domains[0].KeyName)
+	require.Equal(t, "192.0.2.3", domains[0].DNSServers[0].IPAddress)
+
+	require.Len(t, recipe.Commands, 2)
+	require.Equal(t, keactrl.ConfigSet, recipe.Commands[0].Command.GetCommand())
+	require.Equal(t, keactrl.ConfigWrite, recipe.Commands[1].Command.GetCommand())
+	require.Contains(t, recipe.Commands[0].Command.Marshal(), "192.0.2.3")
+	require.NotContains(t, recipe.Commands[0].Command.Marshal(), "example.org")
+}
+
+// Test that the invalid DDNS domain changes are rejected.
+func TestApplyDDNSDomainChangesInvalid(t *testing.T) {
+	module := NewConfigModule(nil)
+	require.NotNil(t, module)
+
+	ctx := getTestDDNSDomainsContext(t)
+
+	domain := &keaconfig.D2DDNSDomain{
+		Name: "example.org.",
+		DNSServers: []keaconfig.D2DNSServer{
+			{
+				IPAddress: "192.0.2.1",
+			},
+		},
+	}
+	_, err := module.ApplyDDNSDomainAdd(ctx, keaconfig.D2ForwardDDNS, domain)
+	var existsError *config.DDNSDomainExistsError
+	require.ErrorAs(t, err, &existsError)
+
+	_, err = module.ApplyDDNSDomainUpdate(ctx, keaconfig.D2ReverseDDNS, domain)
+	var notFoundError *config.DDNSDomainNotFoundError
+	require.ErrorAs(t, err, &notFoundError)
+
+	_, err = module.ApplyDDNSDomainDelete(ctx, keaconfig.D2ReverseDDNS, "example.org.")
+	require.ErrorAs(t, err, &notFoundError)
+
+	var invalidError *config.InvalidDDNSDomainError
+	_, err = module.ApplyDDNSDomainAdd(ctx, "foo", domain)
+	require.ErrorAs(t, err, &invalidError)
+
+	domain.Name = "example.com."
+	domain.KeyName = "key2"
+	_, err = module.ApplyDDNSDomainAdd(ctx, keaconfig.D2ForwardDDNS, domain)
+	require.ErrorAs(t, err, &invalidError)
+}
+
+// Test committing the DDNS domain changes. It checks that the commands
+// are sent to Kea and that the configuration and the DDNS domains are
+// updated in the database.
+func TestCommitDDNSDomainsUpdate(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	agents := agentcommtest.NewKeaFakeAgents()
+	manager := newTestManager(&appstest.ManagerAccessorsWrapper{
+		DB:     db,
+		Agents: agents,
+	})
+
+	module := NewConfigModule(manager)
+	require.NotNil(t, module)
+
+	server, err := dbmodeltest.NewKeaD2Server(db)
+	require.NoError(t, err)
+	err = server.Configure(testD2Config)
+	require.NoError(t, err)
+
+	app, err := server.GetKea()
+	require.NoError(t, err)
+
+	err = CommitAppIntoDB(db, app, &storktest.FakeEventCenter{}, nil, dbmodel.NewDHCPOptionDefinitionLookup())
+	require.NoError(t, err)
+
+	domains, err := dbmodel.GetDDNSDomainsByDaemonID(db, server.ID)
+	require.NoError(t, err)
+	require.Len(t, domains, 1)
+
+	ctx, err := module.BeginDDNSDomainsUpdate(context.Background(), server.ID)
+	require.NoError(t, err)
+
+	ctx, err = module.ApplyDDNSDomainAdd(ctx, keaconfig.D2ReverseDDNS, &keaconfig.D2DDNSDomain{
+		Name: "2.0.192.in-addr.arpa.",
+		DNSServers: []keaconfig.D2DNSServer{
+			{
+				IPAddress: "192.0.2.2",
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	_, err = module.Commit(ctx)
+	require.NoError(t, err)
+
+	require.Len(t, agents.RecordedCommands, 2)
+	require.Equal(t, keactrl.ConfigSet, agents.RecordedCommands[0].GetCommand())
+	require.Equal(t, keactrl.ConfigWrite, agents.RecordedCommands[1].GetCommand())
+
+	domains, err = dbmodel.GetDDNSDomainsByDaemonID(db, server.ID)
+	require.NoError(t, err)
+	require.Len(t, domains, 2)
+	require.Equal(t, keaconfig.D2ReverseDDNS, domains[1].Direction)
+	require.Equal(t, "2.0.192.in-addr.arpa.", domains[1].Name)
+}
@@ -0,0 +1,196 @@
+package kea
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/pkg/errors"
+	keaconfig "isc.org/stork/appcfg/kea"
+	keactrl "isc.org/stork/appctrl/kea"
+	dbmodel "isc.org/stork/server/database/model"
+)
+
+// A group of daemons belonging to the same HA relationship and receiving
+// the commands in a config update. Such daemons are configured as a unit.
+// If the changes can't be applied to one of them, the changes already
+// applied to the other daemons are reverted to keep their configurations
+// consistent.
+type haPair struct {
+	// HA service the daemons belong to.
+	service *dbmodel.Service
+	// Daemons receiving the commands.
+	daemons []*dbmodel.Daemon
+	// Configurations of the daemons before applying the changes. They
+	// are indexed by the daemon IDs.
+	configs map[int64]*keaconfig.Config
+}
+
+// Checks if the daemon belongs to the HA pair.
+func (pair *haPair) hasDaemon(daemonID int64) bool {
+	return slices.ContainsFunc(pair.daemons, func(daemon *dbmodel.Daemon) bool {
+		return daemon.ID == daemonID
+	})
+}
+
+// Returns the HA pair the daemon belongs to or nil if the daemon does not
+// belong to any of the pairs.
+func findHAPairByDaemonID(pairs []haPair, daemonID int64) *haPair {
+	for i := range pairs {
+		if pairs[i].hasDaemon(daemonID) {
+			return &pairs[i]
+		}
+	}
+	return nil
+}
+
+// Finds the HA relationships with at least two daemons receiving the
+// specified commands. The daemons of such relationships are configured
+// as a unit.
+func (module *ConfigModule) findHAPairs(commands []ConfigCommand) ([]haPair, error) {
+	db := module.manager.GetDB()
+	if db == nil {
+		return nil, nil
+	}
+	// The apps are associated with the commands rather than the daemons
+	// returned with the services because they include the machines
+	// required to send the commands.
+	apps := make(map[int64]*dbmodel.App)
+	var appIDs []int64
+	for _, command := range commands {
+		if command.App == nil {
+			continue
+		}
+		if _, ok := apps[command.DaemonID]; !ok {
+			apps[command.DaemonID] = command.App
+		}
+		if !slices.Contains(appIDs, command.App.ID) {
+			appIDs = append(appIDs, command.App.ID)
+		}
+	}
+	var (
+		pairs      []haPair
+		serviceIDs []int64
+	)
+	for _, appID := range appIDs {
+		services, err := dbmodel.GetDetailedServicesByAppID(db, appID)
+		if err != nil {
+			return nil, err
+		}
+		for i := range services {
+			if services[i].HAService == nil || slices.Contains(serviceIDs, services[i].ID) {
+				continue
+			}
+			serviceIDs = append(serviceIDs, services[i].ID)
+			pair := haPair{
+				service: &services[i],
+				configs: make(map[int64]*keaconfig.Config),
+			}
+			for _, daemon := range services[i].Daemons {
+				if app, ok := apps[daemon.ID]; ok {
+					daemon.App = app
+					pair.daemons = append(pair.daemons, daemon)
+				}
+			}
+			if len(pair.daemons) > 1 {
+				pairs = append(pairs, pair)
+			}
+		}
+	}
+	return pairs, nil
+}
+
+// Checks that all daemons in the HA pair are reachable before applying
+// any changes. It fetches the daemons' current configurations which are
+// used to revert the changes if they are only partially applied.
+func (module *ConfigModule) verifyHAPair(pair *haPair) error {
+	for _, daemon := range pair.daemons {
+		response, err := module.sendCommand(ConfigCommand{
+			Command:  keactrl.NewCommandBase(keactrl.ConfigGet, daemon.Name),
+			App:      daemon.App,
+			DaemonID: daemon.ID,
+		})
+		if err == nil && (len(response) == 0 || response[0].Arguments == nil) {
+			err = errors.Errorf("no configuration returned by %s", daemon.Name)
+		}
+		if err != nil {
+			return errors.WithMessagef(err, "%s daemon of %s in the HA relationship %s is unreachable; the configuration changes have not been applied",
+				daemon.Name, daemon.App.GetName(), pair.service.Name)
+		}
+		pair.configs[daemon.ID] = keaconfig.NewConfigFromMap(response[0].Arguments)
+	}
+	return nil
+}
+
+// Reverts the changes applied to the daemons of the HA pair when the
+// changes couldn't be applied to one of them. It sends the config-set
+// command with the configuration fetched before applying the changes
+// and, if the changes have been written to the configuration file, the
+// config-write command. The outcome is recorded as an event. The returned
+// error combines the original error with the outcome.
+func (module *ConfigModule) revertHAPair(pair *haPair, applied map[int64][]ConfigCommand, cause error) error {
+	var failed []string
+	for _, daemon := range pair.daemons {
+		commands := applied[daemon.ID]
+		if len(commands) == 0 {
+			continue
+		}
+		err := module.revertHAPairDaemon(daemon, pair.configs[daemon.ID], commands)
+		if err != nil {
+			failed = append(failed, fmt.Sprintf("%s of %s", daemon.Name, daemon.App.GetName()))
+			module.addHAPairEvent(true, "Failed to revert partially applied configuration changes in {daemon} belonging to the HA relationship; the HA peers may be inconsistent",
+				daemon, fmt.Sprintf("%s; %s", cause, err))
+			continue
+		}
+		module.addHAPairEvent(false, "Reverted configuration changes in {daemon} because they could not be applied to its HA peer",
+			daemon, cause.Error())
+	}
+	if len(failed) > 0 {
+		return errors.WithMessagef(cause, "failed to revert the changes applied to %v in the HA relationship %s", failed, pair.service.Name)
+	}
+	return cause
+}
+
+// Reverts the changes applied to a single daemon of the HA pair.
+func (module *ConfigModule) revertHAPairDaemon(daemon *dbmodel.Daemon, config *keaconfig.Config, applied []ConfigCommand) error {
+	// The changes are stored in the database shared by the daemons using
+	// the configuration backend. They can't be reverted with config-set.
+	if getDaemonConfigBackend(daemon) != nil {
+		return errors.Errorf("%s uses the configuration backend and its configuration must be restored manually", daemon.Name)
+	}
+	commands := []ConfigCommand{
+		{
+			Command:  keactrl.NewCommandConfigSet(config, daemon.Name),
+			App:      daemon.App,
+			DaemonID: daemon.ID,
+		},
+	}
+	if slices.ContainsFunc(applied, func(command ConfigCommand) bool {
+		return command.Command.GetCommand() == keactrl.ConfigWrite
+	}) {
+		commands = append(commands, ConfigCommand{
+			Command:  keactrl.NewCommandBase(keactrl.ConfigWrite, daemon.Name),
+			App:      daemon.App,
+			DaemonID: daemon.ID,
+		})
+	}
+	for _, command := range commands {
+		if _, err := module.sendCommand(command); err != nil {
+			return errors.WithMessagef(err, "%s command to %s failed", command.Command.GetCommand(), daemon.App.GetName())
+		}
+	}
+	return nil
+}
+
+// Records an event describing the outcome of reverting the changes in
+// the daemon belonging to the HA pair.
+func (module *ConfigModule) addHAPairEvent(failed bool, text string, daemon *dbmodel.Daemon, details string) {
+	eventCenter := module.manager.GetEventCenter()
+	if eventCenter == nil {
+		return
+	}
+	if failed {
+		eventCenter.AddErrorEvent(text, daemon, daemon.App, details)
+		return
+	}
+	eventCenter.AddWarningEvent(text, daemon, daemon.App, details)
+}
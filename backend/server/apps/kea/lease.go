@@ -608,3 +608,275 @@ func FindLeasesByHostID(db *dbops.PgDB, agents agentcomm.ConnectedAgents, hostID
 
 	return leases, conflicts, erredApps, err
 }
+
+// Returns an error if the daemon is not a DHCP daemon or it lacks the
+// libdhcp_lease_cmds hooks library required to manipulate the leases.
+func checkLeaseCmdsDaemon(daemon *dbmodel.Daemon) error {
+	if daemon.Name != dbmodel.DaemonNameDHCPv4 && daemon.Name != dbmodel.DaemonNameDHCPv6 {
+		return errors.Errorf("%s is not a DHCP daemon", daemon.Name)
+	}
+	if daemon.KeaDaemon == nil || daemon.KeaDaemon.Config == nil {
+		return errors.Errorf("configuration of the %s daemon is not available", daemon.Name)
+	}
+	if _, _, ok := daemon.KeaDaemon.Config.GetHookLibrary("libdhcp_lease_cmds"); !ok {
+		return errors.Errorf("%s daemon lacks the libdhcp_lease_cmds hooks library", daemon.Name)
+	}
+	return nil
+}
+
+// Sends a command modifying the leases to the daemon and checks the
+// response. It returns a boolean flag indicating whether Kea returned
+// the empty status, e.g., when the deleted lease doesn't exist.
+func sendLeaseCommand(agents agentcomm.ConnectedAgents, daemon *dbmodel.Daemon, command *keactrl.Command) (empty bool, err error) {
+	if err = checkLeaseCmdsDaemon(daemon); err != nil {
+		return false, err
+	}
+	var response keactrl.ResponseList
+	ctx := context.Background()
+	respResult, err := agents.ForwardToKeaOverHTTP(ctx, daemon.App, []keactrl.SerializableCommand{command}, &response)
+	if err != nil {
+		return false, err
+	}
+	if err = respResult.GetFirstError(); err != nil {
+		return false, err
+	}
+	if len(response) == 0 {
+		return false, errors.Errorf("invalid response to %s command received", command.GetCommand())
+	}
+	if response[0].Result == keactrl.ResponseEmpty {
+		return true, nil
+	}
+	return false, keactrl.GetResponseError(response[0])
+}
+
+// Sends the lease4-add or lease6-add command to the daemon, depending on
+// the daemon type.
+func AddLease(agents agentcomm.ConnectedAgents, daemon *dbmodel.Daemon, lease *keactrl.LeaseParams) error {
+	command := keactrl.NewCommandLease4Add(lease, daemon.Name)
+	if daemon.Name == dbmodel.DaemonNameDHCPv6 {
+		command = keactrl.NewCommandLease6Add(lease, daemon.Name)
+	}
+	_, err := sendLeaseCommand(agents, daemon, command)
+	return err
+}
+
+// Sends the lease4-update or lease6-update command to the daemon, depending
+// on the daemon type.
+func UpdateLease(agents agentcomm.ConnectedAgents, daemon *dbmodel.Daemon, lease *keactrl.LeaseParams) error {
+	command := keactrl.NewCommandLease4Update(lease, daemon.Name)
+	if daemon.Name == dbmodel.DaemonNameDHCPv6 {
+		command = keactrl.NewCommandLease6Update(lease, daemon.Name)
+	}
+	_, err := sendLeaseCommand(agents, daemon, command)
+	return err
+}
+
+// Sends the lease4-del or lease6-del command to the daemon, depending on
+// the daemon type. The lease type is ignored for the DHCPv4 daemon. It
+// returns false if the lease doesn't exist.
+func DeleteLease(agents agentcomm.ConnectedAgents, daemon *dbmodel.Daemon, leaseType keactrl.LeaseType, ipAddress string) (bool, error) {
+	command := keactrl.NewCommandLease4Del(ipAddress, daemon.Name)
+	if daemon.Name == dbmodel.DaemonNameDHCPv6 {
+		command = keactrl.NewCommandLease6Del(leaseType, ipAddress, daemon.Name)
+	}
+	empty, err := sendLeaseCommand(agents, daemon, command)
+	return !empty && err == nil, err
+}
+
+// Sends the lease4-wipe command to the DHCPv4 daemon. It removes the leases
+// from the subnet with the specified ID or from all subnets if the subnet
+// ID is 0.
+func WipeLeases4(agents agentcomm.ConnectedAgents, daemon *dbmodel.Daemon, subnetID int64) error {
+	if daemon.Name != dbmodel.DaemonNameDHCPv4 {
+		return errors.Errorf("wiping leases is only supported by the %s daemon", dbmodel.DaemonNameDHCPv4)
+	}
+	_, err := sendLeaseCommand(agents, daemon, keactrl.NewCommandLease4Wipe(subnetID, daemon.Name))
+	return err
+}
+
+// Number of leases fetched from Kea in a single lease4-get-page or
+// lease6-get-page command.
+var leasesPageLimit int64 = 1000
+
+// Criteria for selecting the leases belonging to a subnet. The nil
+// values match all leases.
+type LeaseFilter struct {
+	// Lease state, e.g., keadata.LeaseStateDeclined.
+	State *int
+	// Selects the leases expiring at or after the specified time
+	// (in seconds since epoch).
+	ExpiresAfter *int64
+	// Selects the leases expiring before the specified time (in seconds
+	// since epoch).
+	ExpiresBefore *int64
+	// Selects the leases belonging to the pool.
+	PoolLowerBound net.IP
+	PoolUpperBound net.IP
+}
+
+// Sets the pool bounds in the filter. The pool is specified as an
+// address range (e.g., 192.0.2.10-192.0.2.100) or a prefix (e.g.,
+// 2001:db8:1::/64).
+func (filter *LeaseFilter) SetPool(pool string) error {
+	lb, ub, err := storkutil.ParseIPRange(pool)
+	if err != nil {
+		return err
+	}
+	filter.PoolLowerBound = lb.To16()
+	filter.PoolUpperBound = ub.To16()
+	return nil
+}
+
+// Checks if the lease matches the filter.
+func (filter *LeaseFilter) matches(lease *dbmodel.Lease) bool {
+	if filter == nil {
+		return true
+	}
+	if filter.State != nil && lease.State != *filter.State {
+		return false
+	}
+	expire := int64(lease.CLTT) + int64(lease.ValidLifetime)
+	if filter.ExpiresAfter != nil && expire < *filter.ExpiresAfter {
+		return false
+	}
+	if filter.ExpiresBefore != nil && expire >= *filter.ExpiresBefore {
+		return false
+	}
+	if filter.PoolLowerBound != nil && filter.PoolUpperBound != nil {
+		parsed := storkutil.ParseIP(lease.IPAddress)
+		if parsed == nil || !parsed.IsInRange(filter.PoolLowerBound, filter.PoolUpperBound) {
+			return false
+		}
+	}
+	return true
+}
+
+// Sends a command returning multiple leases (e.g., lease4-get-page) to
+// the daemon and returns the leases.
+func getLeasesFromDaemon(agents agentcomm.ConnectedAgents, daemon *dbmodel.Daemon, command *keactrl.Command) ([]dbmodel.Lease, error) {
+	response := make([]LeaseGetMultipleResponse, 1)
+	ctx := context.Background()
+	respResult, err := agents.ForwardToKeaOverHTTP(ctx, daemon.App, []keactrl.SerializableCommand{command}, &response)
+	if err != nil {
+		return nil, err
+	}
+	if err = respResult.GetFirstError(); err != nil {
+		return nil, err
+	}
+	if len(response) == 0 {
+		return nil, errors.Errorf("invalid response to %s command received", command.GetCommand())
+	}
+	if response[0].Result == keactrl.ResponseEmpty {
+		return nil, nil
+	}
+	if err = validateGetLeasesResponse(command.GetCommand(), response[0].Result, response[0].Arguments); err != nil {
+		return nil, err
+	}
+	return response[0].Arguments.Leases, nil
+}
+
+// Iterates over all leases of the daemon serving the local subnet using
+// the lease4-get-page or lease6-get-page commands. Kea doesn't filter
+// the leases by subnet in these commands, so the function only calls the
+// callback for the leases belonging to the local subnet and matching the
+// filter.
+func iterateLocalSubnetLeases(agents agentcomm.ConnectedAgents, localSubnet *dbmodel.LocalSubnet, family int, filter *LeaseFilter, callback func(lease *dbmodel.Lease)) error {
+	daemon := localSubnet.Daemon
+	from := keactrl.LeasePageStart
+	for {
+		command := keactrl.NewCommandLease4GetPage(from, leasesPageLimit, daemon.Name)
+		if family == 6 {
+			command = keactrl.NewCommandLease6GetPage(from, leasesPageLimit, daemon.Name)
+		}
+		leases, err := getLeasesFromDaemon(agents, daemon, command)
+		if err != nil {
+			return err
+		}
+		for i := range leases {
+			if int64(leases[i].SubnetID) == localSubnet.LocalSubnetID && filter.matches(&leases[i]) {
+				callback(&leases[i])
+			}
+		}
+		if int64(len(leases)) < leasesPageLimit {
+			return nil
+		}
+		from = leases[len(leases)-1].IPAddress
+	}
+}
+
+// Returns the local subnets served by the daemons capable of returning
+// the leases, i.e., having the libdhcp_lease_cmds hooks library.
+func getLeaseCmdsLocalSubnets(subnet *dbmodel.Subnet) (localSubnets []*dbmodel.LocalSubnet) {
+	for _, localSubnet := range subnet.LocalSubnets {
+		if localSubnet.Daemon == nil || localSubnet.Daemon.App == nil {
+			continue
+		}
+		if err := checkLeaseCmdsDaemon(localSubnet.Daemon); err != nil {
+			continue
+		}
+		localSubnets = append(localSubnets, localSubnet)
+	}
+	return localSubnets
+}
+
+// Returns a page of leases belonging to the subnet and matching the
+// filter. It pages through the leases of all daemons serving the subnet,
+// so the leases held by multiple servers (e.g., in HA configuration) are
+// returned for each server. The offset and limit select the returned
+// leases among all matching leases. The limit of 0 returns all matching
+// leases. The second returned value is the total number of matching
+// leases. The apps that returned an error are returned in the third
+// value. The leases from these apps are not included in the result.
+func GetSubnetLeases(agents agentcomm.ConnectedAgents, subnet *dbmodel.Subnet, filter *LeaseFilter, offset, limit int64) (leases []dbmodel.Lease, total int64, erredApps []*dbmodel.App) {
+	for _, localSubnet := range getLeaseCmdsLocalSubnets(subnet) {
+		app := localSubnet.Daemon.App
+		// The leases of the app are dropped when a page fails.
+		leasesBefore, totalBefore := len(leases), total
+		err := iterateLocalSubnetLeases(agents, localSubnet, subnet.GetFamily(), filter, func(lease *dbmodel.Lease) {
+			if total >= offset && (limit <= 0 || int64(len(leases)) < limit) {
+				lease.ID = total + 1
+				lease.AppID = app.ID
+				lease.App = app
+				leases = append(leases, *lease)
+			}
+			total++
+		})
+		if err != nil {
+			log.WithError(err).Warnf("Problem fetching leases of subnet %s from %s", subnet.Prefix, app.Name)
+			erredApps = append(erredApps, app)
+			leases, total = leases[:leasesBefore], totalBefore
+		}
+	}
+	return leases, total, erredApps
+}
+
+// Returns all leases belonging to the subnet and matching the filter. It
+// sends the lease4-get-all or lease6-get-all command with the subnet
+// identifier to all daemons serving the subnet. The apps that returned
+// an error are returned in the second value.
+func GetAllSubnetLeases(agents agentcomm.ConnectedAgents, subnet *dbmodel.Subnet, filter *LeaseFilter) (leases []dbmodel.Lease, erredApps []*dbmodel.App) {
+	for _, localSubnet := range getLeaseCmdsLocalSubnets(subnet) {
+		daemon := localSubnet.Daemon
+		subnetIDs := []int64{localSubnet.LocalSubnetID}
+		command := keactrl.NewCommandLease4GetAll(subnetIDs, daemon.Name)
+		if subnet.GetFamily() == 6 {
+			command = keactrl.NewCommandLease6GetAll(subnetIDs, daemon.Name)
+		}
+		daemonLeases, err := getLeasesFromDaemon(agents, daemon, command)
+		if err != nil {
+			log.WithError(err).Warnf("Problem fetching leases of subnet %s from %s", subnet.Prefix, daemon.App.Name)
+			erredApps = append(erredApps, daemon.App)
+			continue
+		}
+		for i := range daemonLeases {
+			if !filter.matches(&daemonLeases[i]) {
+				continue
+			}
+			daemonLeases[i].ID = int64(len(leases)) + 1
+			daemonLeases[i].AppID = daemon.App.ID
+			daemonLeases[i].App = daemon.App
+			leases = append(leases, daemonLeases[i])
+		}
+	}
+	return leases, erredApps
+}
@@ -1,6 +1,7 @@
 package kea
 
 import (
+	"fmt"
 	"testing"
 
 	require "github.com/stretchr/testify/require"
@@ -11,6 +12,7 @@ import (
 	agentcommtest "isc.org/stork/server/agentcomm/test"
 	dbmodel "isc.org/stork/server/database/model"
 	dbtest "isc.org/stork/server/database/test"
+	storkutil "isc.org/stork/util"
 )
 
 // Generates a success mock response to commands fetching a single
@@ -1850,3 +1852,455 @@ func TestFindHostLeaseConflictsSkipDetection(t *testing.T) {
 		})
 	}
 }
+
+// Returns a test DHCP daemon with the lease_cmds hooks library.
+func getTestLeaseCmdsDaemon(t *testing.T, family int) dbmodel.Daemon {
+	return getTestConfigBackendDaemon(t, 1, fmt.Sprintf(`{
+		"Dhcp%d": {
+			"hooks-libraries": [
+				{
+					"library": "/usr/lib/kea/libdhcp_lease_cmds.so"
+				}
+			]
+		}
+	}`, family))
+}
+
+// Test adding the DHCPv4 and DHCPv6 leases.
+func TestAddLease(t *testing.T) {
+	agents := agentcommtest.NewKeaFakeAgents(mockKeaResponse(0, `{}`))
+
+	daemon := getTestLeaseCmdsDaemon(t, 4)
+	err := AddLease(agents, &daemon, &keactrl.LeaseParams{
+		IPAddress: "192.0.2.1",
+		HWAddress: "01:02:03:04:05:06",
+	})
+	require.NoError(t, err)
+
+	daemon = getTestLeaseCmdsDaemon(t, 6)
+	err = AddLease(agents, &daemon, &keactrl.LeaseParams{
+		IPAddress: "2001:db8:1::1",
+		DUID:      "01:02:03:04",
+		IAID:      storkutil.Ptr(int64(1)),
+	})
+	require.NoError(t, err)
+
+	require.Len(t, agents.RecordedCommands, 2)
+	require.JSONEq(t, `{
+		"command": "lease4-add",
+		"service": [ "dhcp4" ],
+		"arguments": {
+			"ip-address": "192.0.2.1",
+			"hw-address": "01:02:03:04:05:06"
+		}
+	}`, agents.RecordedCommands[0].Marshal())
+	require.JSONEq(t, `{
+		"command": "lease6-add",
+		"service": [ "dhcp6" ],
+		"arguments": {
+			"ip-address": "2001:db8:1::1",
+			"duid": "01:02:03:04",
+			"iaid": 1
+		}
+	}`, agents.RecordedCommands[1].Marshal())
+}
+
+// Test that an error is returned when Kea fails to add the lease.
+func TestAddLeaseError(t *testing.T) {
+	agents := agentcommtest.NewKeaFakeAgents(mockKeaResponse(1, `{}`))
+
+	daemon := getTestLeaseCmdsDaemon(t, 4)
+	err := AddLease(agents, &daemon, &keactrl.LeaseParams{
+		IPAddress: "192.0.2.1",
+	})
+	require.ErrorContains(t, err, "error status (1)")
+}
+
+// Test that no commands are sent to the daemons lacking the lease_cmds
+// hooks library.
+func TestAddLeaseNoLeaseCmds(t *testing.T) {
+	agents := agentcommtest.NewKeaFakeAgents(mockKeaResponse(0, `{}`))
+
+	daemon := getTestConfigBackendDaemon(t, 1, `{ "Dhcp4": { } }`)
+	err := AddLease(agents, &daemon, &keactrl.LeaseParams{
+		IPAddress: "192.0.2.1",
+	})
+	require.ErrorContains(t, err, "lacks the libdhcp_lease_cmds hooks library")
+	require.Empty(t, agents.RecordedCommands)
+}
+
+// Test updating the DHCPv4 and DHCPv6 leases.
+func TestUpdateLease(t *testing.T) {
+	agents := agentcommtest.NewKeaFakeAgents(mockKeaResponse(0, `{}`))
+
+	daemon := getTestLeaseCmdsDaemon(t, 4)
+	err := UpdateLease(agents, &daemon, &keactrl.LeaseParams{
+		IPAddress:   "192.0.2.1",
+		Hostname:    "myhost",
+		ForceCreate: true,
+	})
+	require.NoError(t, err)
+
+	daemon = getTestLeaseCmdsDaemon(t, 6)
+	err = UpdateLease(agents, &daemon, &keactrl.LeaseParams{
+		IPAddress: "2001:db8:1::1",
+	})
+	require.NoError(t, err)
+
+	require.Len(t, agents.RecordedCommands, 2)
+	require.JSONEq(t, `{
+		"command": "lease4-update",
+		"service": [ "dhcp4" ],
+		"arguments": {
+			"ip-address": "192.0.2.1",
+			"hostname": "myhost",
+			"force-create": true
+		}
+	}`, agents.RecordedCommands[0].Marshal())
+	require.EqualValues(t, keactrl.Lease6Update, agents.RecordedCommands[1].GetCommand())
+}
+
+// Test deleting the DHCPv4 and DHCPv6 leases.
+func TestDeleteLease(t *testing.T) {
+	agents := agentcommtest.NewKeaFakeAgents(
+		mockKeaResponse(0, `{}`),
+		mockKeaResponse(0, `{}`),
+		// The lease does not exist.
+		mockKeaResponse(3, `{}`),
+	)
+
+	daemon := getTestLeaseCmdsDaemon(t, 4)
+	deleted, err := DeleteLease(agents, &daemon, keactrl.LeaseTypeNA, "192.0.2.1")
+	require.NoError(t, err)
+	require.True(t, deleted)
+
+	daemon = getTestLeaseCmdsDaemon(t, 6)
+	deleted, err = DeleteLease(agents, &daemon, keactrl.LeaseTypePD, "2001:db8:1::")
+	require.NoError(t, err)
+	require.True(t, deleted)
+
+	deleted, err = DeleteLease(agents, &daemon, keactrl.LeaseTypeNA, "2001:db8:1::1")
+	require.NoError(t, err)
+	require.False(t, deleted)
+
+	require.Len(t, agents.RecordedCommands, 3)
+	require.JSONEq(t, `{
+		"command": "lease4-del",
+		"service": [ "dhcp4" ],
+		"arguments": {
+			"ip-address": "192.0.2.1"
+		}
+	}`, agents.RecordedCommands[0].Marshal())
+	require.JSONEq(t, `{
+		"command": "lease6-del",
+		"service": [ "dhcp6" ],
+		"arguments": {
+			"type": "IA_PD",
+			"ip-address": "2001:db8:1::"
+		}
+	}`, agents.RecordedCommands[1].Marshal())
+}
+
+// Test wiping the DHCPv4 leases.
+func TestWipeLeases4(t *testing.T) {
+	agents := agentcommtest.NewKeaFakeAgents(mockKeaResponse(0, `{}`))
+
+	daemon := getTestLeaseCmdsDaemon(t, 4)
+	err := WipeLeases4(agents, &daemon, 12)
+	require.NoError(t, err)
+
+	require.Len(t, agents.RecordedCommands, 1)
+	require.JSONEq(t, `{
+		"command": "lease4-wipe",
+		"service": [ "dhcp4" ],
+		"arguments": {
+			"subnet-id": 12
+		}
+	}`, agents.RecordedCommands[0].Marshal())
+
+	// The DHCPv6 daemon is not supported.
+	daemon = getTestLeaseCmdsDaemon(t, 6)
+	err = WipeLeases4(agents, &daemon, 12)
+	require.Error(t, err)
+	require.Len(t, agents.RecordedCommands, 1)
+}
+
+// Returns a test subnet served by two daemons with the lease_cmds hooks
+// library.
+func getTestLeasesSubnet(t *testing.T, family int) *dbmodel.Subnet {
+	daemon1 := getTestLeaseCmdsDaemon(t, family)
+	daemon1.App.ID = 1
+	daemon1.App.Name = "kea1"
+	daemon2 := getTestLeaseCmdsDaemon(t, family)
+	daemon2.ID = 2
+	daemon2.App.ID = 2
+	daemon2.App.Name = "kea2"
+	prefix := "192.0.2.0/24"
+	if family == 6 {
+		prefix = "2001:db8:1::/64"
+	}
+	return &dbmodel.Subnet{
+		ID:     1,
+		Prefix: prefix,
+		LocalSubnets: []*dbmodel.LocalSubnet{
+			{
+				DaemonID:      daemon1.ID,
+				Daemon:        &daemon1,
+				LocalSubnetID: 1,
+			},
+			{
+				DaemonID:      daemon2.ID,
+				Daemon:        &daemon2,
+				LocalSubnetID: 5,
+			},
+		},
+	}
+}
+
+// Test the lease filter.
+func TestLeaseFilterMatches(t *testing.T) {
+	lease := &dbmodel.Lease{
+		Lease: keadata.Lease{
+			IPAddress:     "192.0.2.10",
+			CLTT:          1000,
+			ValidLifetime: 500,
+			State:         keadata.LeaseStateDeclined,
+		},
+	}
+
+	var filter *LeaseFilter
+	require.True(t, filter.matches(lease))
+
+	filter = &LeaseFilter{}
+	require.True(t, filter.matches(lease))
+
+	filter.State = storkutil.Ptr(keadata.LeaseStateDeclined)
+	require.True(t, filter.matches(lease))
+	filter.State = storkutil.Ptr(keadata.LeaseStateDefault)
+	require.False(t, filter.matches(lease))
+	filter.State = nil
+
+	// The lease expires at 1500.
+	filter.ExpiresAfter = storkutil.Ptr(int64(1500))
+	require.True(t, filter.matches(lease))
+	filter.ExpiresAfter = storkutil.Ptr(int64(1501))
+	require.False(t, filter.matches(lease))
+	filter.ExpiresAfter = nil
+
+	filter.ExpiresBefore = storkutil.Ptr(int64(1501))
+	require.True(t, filter.matches(lease))
+	filter.ExpiresBefore = storkutil.Ptr(int64(1500))
+	require.False(t, filter.matches(lease))
+	filter.ExpiresBefore = nil
+
+	require.NoError(t, filter.SetPool("192.0.2.5 - 192.0.2.10"))
+	require.True(t, filter.matches(lease))
+	require.NoError(t, filter.SetPool("192.0.2.0/29"))
+	require.False(t, filter.matches(lease))
+	require.Error(t, filter.SetPool("foo"))
+}
+
+// Test paging through the leases of a subnet served by multiple daemons.
+func TestGetSubnetLeases(t *testing.T) {
+	leasesPageLimit = 2
+	defer func() {
+		leasesPageLimit = 1000
+	}()
+
+	mocks := []func(int, []interface{}){
+		// The first page of the first daemon contains a lease from a
+		// different subnet.
+		mockKeaResponse(0, `{
+			"leases": [
+				{
+					"ip-address": "192.0.2.1",
+					"hw-address": "01:02:03:04:05:06",
+					"subnet-id": 1,
+					"cltt": 1000,
+					"valid-lft": 3600
+				},
+				{
+					"ip-address": "192.0.3.1",
+					"hw-address": "01:02:03:04:05:07",
+					"subnet-id": 2,
+					"cltt": 1000,
+					"valid-lft": 3600
+				}
+			],
+			"count": 2
+		}`),
+		// The second page of the first daemon is shorter than the limit.
+		mockKeaResponse(0, `{
+			"leases": [
+				{
+					"ip-address": "192.0.3.2",
+					"hw-address": "01:02:03:04:05:08",
+					"subnet-id": 1,
+					"cltt": 1000,
+					"valid-lft": 3600,
+					"state": 1
+				}
+			],
+			"count": 1
+		}`),
+		// The second daemon returns an error.
+		mockKeaResponse(1, `{}`),
+	}
+	subnet := getTestLeasesSubnet(t, 4)
+
+	agents := agentcommtest.NewKeaFakeAgents(mocks...)
+	leases, total, erredApps := GetSubnetLeases(agents, subnet, nil, 0, 10)
+	require.EqualValues(t, 2, total)
+	require.Len(t, leases, 2)
+	require.EqualValues(t, 1, leases[0].ID)
+	require.Equal(t, "192.0.2.1", leases[0].IPAddress)
+	require.EqualValues(t, 1, leases[0].AppID)
+	require.EqualValues(t, 2, leases[1].ID)
+	require.Equal(t, "192.0.3.2", leases[1].IPAddress)
+	require.Len(t, erredApps, 1)
+	require.Equal(t, "kea2", erredApps[0].Name)
+
+	// The next page should be fetched starting from the last returned lease.
+	require.Len(t, agents.RecordedCommands, 3)
+	require.JSONEq(t, `{
+		"command": "lease4-get-page",
+		"service": [ "dhcp4" ],
+		"arguments": {
+			"from": "start",
+			"limit": 2
+		}
+	}`, agents.RecordedCommands[0].Marshal())
+	require.JSONEq(t, `{
+		"command": "lease4-get-page",
+		"service": [ "dhcp4" ],
+		"arguments": {
+			"from": "192.0.3.1",
+			"limit": 2
+		}
+	}`, agents.RecordedCommands[1].Marshal())
+
+	// Get the second lease only.
+	agents = agentcommtest.NewKeaFakeAgents(mocks...)
+	leases, total, _ = GetSubnetLeases(agents, subnet, nil, 1, 1)
+	require.EqualValues(t, 2, total)
+	require.Len(t, leases, 1)
+	require.EqualValues(t, 2, leases[0].ID)
+	require.Equal(t, "192.0.3.2", leases[0].IPAddress)
+
+	// The offset beyond the number of leases returns no leases.
+	agents = agentcommtest.NewKeaFakeAgents(mocks...)
+	leases, total, _ = GetSubnetLeases(agents, subnet, nil, 2, 10)
+	require.EqualValues(t, 2, total)
+	require.Empty(t, leases)
+
+	// Filter the leases by state.
+	agents = agentcommtest.NewKeaFakeAgents(mocks...)
+	filter := &LeaseFilter{
+		State: storkutil.Ptr(keadata.LeaseStateDeclined),
+	}
+	leases, total, _ = GetSubnetLeases(agents, subnet, filter, 0, 10)
+	require.EqualValues(t, 1, total)
+	require.Len(t, leases, 1)
+	require.Equal(t, "192.0.3.2", leases[0].IPAddress)
+}
+
+// Test that the leases of the app are not returned when fetching one of
+// its pages fails.
+func TestGetSubnetLeasesPageError(t *testing.T) {
+	leasesPageLimit = 2
+	defer func() {
+		leasesPageLimit = 1000
+	}()
+
+	mocks := []func(int, []interface{}){
+		// The first page of the first daemon is full.
+		mockKeaResponse(0, `{
+			"leases": [
+				{
+					"ip-address": "192.0.2.1",
+					"hw-address": "01:02:03:04:05:06",
+					"subnet-id": 1,
+					"cltt": 1000,
+					"valid-lft": 3600
+				},
+				{
+					"ip-address": "192.0.2.2",
+					"hw-address": "01:02:03:04:05:07",
+					"subnet-id": 1,
+					"cltt": 1000,
+					"valid-lft": 3600
+				}
+			],
+			"count": 2
+		}`),
+		// The second page of the first daemon fails.
+		mockKeaResponse(1, `{}`),
+		// The second daemon returns one lease.
+		mockKeaResponse(0, `{
+			"leases": [
+				{
+					"ip-address": "192.0.2.3",
+					"hw-address": "01:02:03:04:05:08",
+					"subnet-id": 5,
+					"cltt": 1000,
+					"valid-lft": 3600
+				}
+			],
+			"count": 1
+		}`),
+	}
+	subnet := getTestLeasesSubnet(t, 4)
+
+	agents := agentcommtest.NewKeaFakeAgents(mocks...)
+	leases, total, erredApps := GetSubnetLeases(agents, subnet, nil, 0, 10)
+	require.EqualValues(t, 1, total)
+	require.Len(t, leases, 1)
+	require.EqualValues(t, 1, leases[0].ID)
+	require.Equal(t, "192.0.2.3", leases[0].IPAddress)
+	require.Len(t, erredApps, 1)
+	require.Equal(t, "kea1", erredApps[0].Name)
+}
+
+// Test getting all leases of a subnet with the lease6-get-all command.
+func TestGetAllSubnetLeases(t *testing.T) {
+	agents := agentcommtest.NewKeaFakeAgents(
+		mockKeaResponse(0, `{
+			"leases": [
+				{
+					"ip-address": "2001:db8:1::1",
+					"duid": "01:02:03:04",
+					"iaid": 1,
+					"subnet-id": 1,
+					"cltt": 1000,
+					"valid-lft": 3600,
+					"type": "IA_NA"
+				}
+			],
+			"count": 1
+		}`),
+		mockKeaResponse(3, `{}`),
+	)
+	subnet := getTestLeasesSubnet(t, 6)
+	leases, erredApps := GetAllSubnetLeases(agents, subnet, nil)
+	require.Len(t, leases, 1)
+	require.EqualValues(t, 1, leases[0].ID)
+	require.Equal(t, "2001:db8:1::1", leases[0].IPAddress)
+	require.EqualValues(t, 1, leases[0].AppID)
+	require.Empty(t, erredApps)
+
+	require.Len(t, agents.RecordedCommands, 2)
+	require.JSONEq(t, `{
+		"command": "lease6-get-all",
+		"service": [ "dhcp6" ],
+		"arguments": {
+			"subnets": [ 1 ]
+		}
+	}`, agents.RecordedCommands[0].Marshal())
+	require.JSONEq(t, `{
+		"command": "lease6-get-all",
+		"service": [ "dhcp6" ],
+		"arguments": {
+			"subnets": [ 5 ]
+		}
+	}`, agents.RecordedCommands[1].Marshal())
+}
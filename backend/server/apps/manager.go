@@ -4,6 +4,7 @@ import (
 	"context"
 	"crypto/rand"
 	"encoding/json"
+	"fmt"
 	"math"
 	"math/big"
 	"sync"
@@ -11,12 +12,15 @@ import (
 
 	"github.com/go-pg/pg/v10"
 	pkgerrors "github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
 	keaconfig "isc.org/stork/appcfg/kea"
 	"isc.org/stork/datamodel"
 	"isc.org/stork/server/agentcomm"
 	"isc.org/stork/server/apps/kea"
 	"isc.org/stork/server/config"
 	dbmodel "isc.org/stork/server/database/model"
+	"isc.org/stork/server/eventcenter"
+	storkutil "isc.org/stork/util"
 )
 
 // Holds a pair of a context and its cancel function.
@@ -53,6 +57,9 @@ type configManagerImpl struct {
 	// The locker that manages the daemon configuration locks in the
 	// application.
 	locker config.DaemonLocker
+	// An interface to the event center used to notify the users about the
+	// executed scheduled configuration changes. It may be nil.
+	eventCenter eventcenter.EventCenter
 }
 
 // Generates new context ID. This ID is returned to the client when the
@@ -82,12 +89,13 @@ func (manager *configManagerImpl) generateContextID() (int64, error) {
 // instance of the Stork Server holding the state.).
 func NewManager(server config.ManagerAccessors) config.Manager {
 	manager := &configManagerImpl{
-		db:       server.GetDB(),
-		agents:   server.GetConnectedAgents(),
-		lookup:   server.GetDHCPOptionDefinitionLookup(),
-		locker:   server.GetDaemonLocker(),
-		contexts: make(map[int64]contextPair),
-		mutex:    &sync.RWMutex{},
+		db:          server.GetDB(),
+		agents:      server.GetConnectedAgents(),
+		lookup:      server.GetDHCPOptionDefinitionLookup(),
+		locker:      server.GetDaemonLocker(),
+		eventCenter: server.GetEventCenter(),
+		contexts:    make(map[int64]contextPair),
+		mutex:       &sync.RWMutex{},
 	}
 	keaConfigModule := kea.NewConfigModule(manager)
 	manager.kea = keaConfigModule
@@ -122,6 +130,11 @@ func (manager *configManagerImpl) GetDaemonLocker() config.DaemonLocker {
 	return manager.locker
 }
 
+// Returns the event center instance used by the configuration manager.
+func (manager *configManagerImpl) GetEventCenter() eventcenter.EventCenter {
+	return manager.eventCenter
+}
+
 // Creates the context for use with the configuration manager. It sets the
 // unique context ID and a user identifier used to associate the context
 // and the configuration change transaction with a user applying the
@@ -133,6 +146,16 @@ func (manager *configManagerImpl) CreateContext(userID int64) (context.Context,
 	}
 	ctx := context.WithValue(context.Background(), config.ContextIDKey, id)
 	ctx = context.WithValue(ctx, config.UserContextKey, userID)
+	// Remember whether the committed changes should be written to the
+	// Kea configuration files. They are written by default.
+	if manager.db != nil {
+		writeConfig, err := dbmodel.GetSettingBool(manager.db, "kea_config_auto_write")
+		if err != nil {
+			log.WithError(err).Warn("Problem getting the setting controlling writing the Kea configurations; the changes will be written")
+		} else {
+			ctx = context.WithValue(ctx, config.ConfigWriteContextKey, writeConfig)
+		}
+	}
 	return ctx, nil
 }
 
@@ -330,13 +353,35 @@ func (manager *configManagerImpl) CommitDue() error {
 			errText = err.Error()
 		}
 		// Mark the current config change as executed.
-		if err = dbmodel.SetScheduledConfigChangeExecuted(manager.GetDB(), change.ID, errText); err != nil {
+		if err := dbmodel.SetScheduledConfigChangeExecuted(manager.GetDB(), change.ID, errText); err != nil {
 			return err
 		}
+		manager.notifyConfigChangeExecuted(change, err)
 	}
 	return nil
 }
 
+// Issues an event informing that the scheduled config change has been
+// executed. The err parameter holds an error returned while committing
+// the change or nil if the change was committed successfully.
+func (manager *configManagerImpl) notifyConfigChangeExecuted(change dbmodel.ScheduledConfigChange, err error) {
+	if manager.eventCenter == nil {
+		return
+	}
+	objects := []any{}
+	text := fmt.Sprintf("scheduled config change %d", change.ID)
+	if change.User != nil {
+		text += " submitted by {user}"
+		objects = append(objects, change.User)
+	}
+	if err != nil {
+		objects = append(objects, err)
+		manager.eventCenter.AddErrorEvent(text+" failed", objects...)
+		return
+	}
+	manager.eventCenter.AddInfoEvent(text+" executed", objects...)
+}
+
 // Schedules sending the changes queued in the context to one or multiple daemons.
 // The deadline parameter specifies the time when the changes should be committed.
 func (manager *configManagerImpl) Schedule(ctx context.Context, deadline time.Time) (context.Context, error) {
@@ -371,3 +416,19 @@ func (manager *configManagerImpl) Schedule(ctx context.Context, deadline time.Ti
 	}
 	return ctx, nil
 }
+
+// Interval at which the scheduled configuration changes executor checks
+// for the due configuration changes.
+const ScheduledConfigChangesExecutorInterval = 10 * time.Second
+
+// Creates the periodic executor committing the scheduled configuration
+// changes for which the deadline has expired.
+func NewScheduledConfigChangesExecutor(manager config.Manager) (*storkutil.PeriodicExecutor, error) {
+	return storkutil.NewPeriodicExecutor(
+		"scheduled config changes executor",
+		manager.CommitDue,
+		func() (time.Duration, error) {
+			return ScheduledConfigChangesExecutorInterval, nil
+		},
+	)
+}
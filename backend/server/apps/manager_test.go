@@ -15,6 +15,7 @@ import (
 	"isc.org/stork/server/config"
 	dbmodel "isc.org/stork/server/database/model"
 	dbtest "isc.org/stork/server/database/test"
+	storktest "isc.org/stork/server/test/dbmodel"
 	storkutil "isc.org/stork/util"
 )
 
@@ -109,6 +110,32 @@ func TestCreateContext(t *testing.T) {
 	require.Len(t, ids, 10)
 }
 
+// Test that the created context holds the setting controlling whether
+// the configuration changes are written to the configuration files.
+func TestCreateContextConfigWrite(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	err := dbmodel.InitializeSettings(db, 0)
+	require.NoError(t, err)
+
+	manager := NewManager(&appstest.ManagerAccessorsWrapper{
+		DB: db,
+	})
+	require.NotNil(t, manager)
+
+	ctx, err := manager.CreateContext(1)
+	require.NoError(t, err)
+	require.True(t, config.IsConfigWriteEnabled(ctx))
+
+	err = dbmodel.SetSettingBool(db, "kea_config_auto_write", false)
+	require.NoError(t, err)
+
+	ctx, err = manager.CreateContext(1)
+	require.NoError(t, err)
+	require.False(t, config.IsConfigWriteEnabled(ctx))
+}
+
 // Test that a created context can be remembered and then recovered
 // by context ID and user ID.
 func TestRememberRecoverContext(t *testing.T) {
@@ -474,8 +501,10 @@ func TestCommitDue(t *testing.T) {
 	require.NoError(t, err)
 	require.NotZero(t, user.ID)
 
+	eventCenter := &storktest.FakeEventCenter{}
 	manager := NewManager(&appstest.ManagerAccessorsWrapper{
-		DB: db,
+		DB:          db,
+		EventCenter: eventCenter,
 	})
 	require.NotNil(t, manager)
 
@@ -542,6 +571,15 @@ func TestCommitDue(t *testing.T) {
 	changes, err = dbmodel.GetDueConfigChanges(db)
 	require.NoError(t, err)
 	require.Empty(t, changes)
+
+	// The events informing about the executed changes should be issued.
+	require.Len(t, eventCenter.Events, 2)
+	for _, event := range eventCenter.Events {
+		require.Equal(t, dbmodel.EvInfo, event.Level)
+		require.Contains(t, event.Text, "submitted by <user")
+		require.Contains(t, event.Text, "executed")
+		require.EqualValues(t, user.ID, event.Relations.UserID)
+	}
 }
 
 // Test that errors are recorded in the database when committing due
@@ -560,8 +598,10 @@ func TestCommitDueErrors(t *testing.T) {
 	require.NoError(t, err)
 	require.NotZero(t, user.ID)
 
+	eventCenter := &storktest.FakeEventCenter{}
 	manager := NewManager(&appstest.ManagerAccessorsWrapper{
-		DB: db,
+		DB:          db,
+		EventCenter: eventCenter,
 	})
 	require.NotNil(t, manager)
 
@@ -611,6 +651,14 @@ func TestCommitDueErrors(t *testing.T) {
 		require.True(t, change.Executed)
 		require.Equal(t, "custom test error", change.Error)
 	}
+
+	// The events informing about the failures should be issued.
+	require.Len(t, eventCenter.Events, 2)
+	for _, event := range eventCenter.Events {
+		require.Equal(t, dbmodel.EvError, event.Level)
+		require.Contains(t, event.Text, "failed")
+		require.Equal(t, "custom test error", event.Details)
+	}
 }
 
 // Test that due changes are dropped if the user is deleted.
@@ -803,3 +851,17 @@ func TestSchedule(t *testing.T) {
 	require.EqualValues(t, 1, tags[1].GetAppID())
 	require.Equal(t, dbmodel.AppTypeKea, tags[1].GetAppType())
 }
+
+// Test creating the executor committing the scheduled config changes.
+func TestNewScheduledConfigChangesExecutor(t *testing.T) {
+	manager := NewManager(&appstest.ManagerAccessorsWrapper{})
+	require.NotNil(t, manager)
+
+	executor, err := NewScheduledConfigChangesExecutor(manager)
+	require.NoError(t, err)
+	require.NotNil(t, executor)
+	defer executor.Shutdown()
+
+	require.Equal(t, "scheduled config changes executor", executor.GetName())
+	require.Equal(t, ScheduledConfigChangesExecutorInterval, executor.GetInterval())
+}
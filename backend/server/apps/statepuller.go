@@ -135,6 +135,17 @@ func appCompare(dbApp *dbmodel.App, app *agentcomm.App) bool {
 	return controlPortEqual
 }
 
+// Returns the app discovered by the agent matching the app from the database.
+// It returns nil if no matching app has been discovered.
+func findDiscoveredApp(dbApp *dbmodel.App, discoveredApps []*agentcomm.App) *agentcomm.App {
+	for _, app := range discoveredApps {
+		if appCompare(dbApp, app) {
+			return app
+		}
+	}
+	return nil
+}
+
 // Get old apps from the machine db object and new apps retrieved from the machine remotely
 // and merge them into one list of all, unique apps.
 func mergeNewAndOldApps(db *dbops.PgDB, dbMachine *dbmodel.Machine, discoveredApps []*agentcomm.App) ([]*dbmodel.App, string) {
@@ -270,7 +281,8 @@ func UpdateMachineAndAppsState(ctx context.Context, db *dbops.PgDB, dbMachine *d
 
 	// take old apps from db and new apps fetched from the machine
 	// and match them and prepare a list of all apps
-	allApps, errStr := mergeNewAndOldApps(db, dbMachine, state.Apps)
+	discoveredApps := state.Apps
+	allApps, errStr := mergeNewAndOldApps(db, dbMachine, discoveredApps)
 	if errStr != "" {
 		return errStr
 	}
@@ -281,6 +293,9 @@ func UpdateMachineAndAppsState(ctx context.Context, db *dbops.PgDB, dbMachine *d
 		switch dbApp.Type {
 		case dbmodel.AppTypeKea:
 			state := kea.GetAppState(ctx2, agents, dbApp, eventCenter)
+			if app := findDiscoveredApp(dbApp, discoveredApps); app != nil {
+				kea.DetectUnsavedConfigChanges(dbApp, app.ConfigFiles, state)
+			}
 			err = kea.CommitAppIntoDB(db, dbApp, eventCenter, state, lookup)
 			if err == nil {
 				// Let's now identify new daemons or the daemons with updated
@@ -289,7 +304,14 @@ func UpdateMachineAndAppsState(ctx context.Context, db *dbops.PgDB, dbMachine *d
 			}
 		case dbmodel.AppTypeBind9:
 			bind9.GetAppState(ctx2, agents, dbApp, eventCenter)
+			configChanged := false
+			if app := findDiscoveredApp(dbApp, discoveredApps); app != nil {
+				configChanged = bind9.SetDaemonConfig(dbApp, app.Bind9Config)
+			}
 			err = bind9.CommitAppIntoDB(db, dbApp, eventCenter)
+			if err == nil {
+				conditionallyBeginBind9ConfigReviews(dbApp, configChanged, reviewDispatcher)
+			}
 		default:
 			err = nil
 		}
@@ -307,6 +329,23 @@ func UpdateMachineAndAppsState(ctx context.Context, db *dbops.PgDB, dbMachine *d
 	return ""
 }
 
+// This function checks if a new config review of the BIND 9 daemon should
+// be performed. It is performed when the daemon's configuration or the
+// dispatcher's signature has changed. The review is not performed when
+// the configuration hasn't been reported by the agent.
+func conditionallyBeginBind9ConfigReviews(dbApp *dbmodel.App, configChanged bool, reviewDispatcher configreview.Dispatcher) {
+	for i, daemon := range dbApp.Daemons {
+		if daemon.Bind9Daemon == nil || len(daemon.Bind9Daemon.Config) == 0 {
+			continue
+		}
+		if !configChanged && daemon.ConfigReview != nil &&
+			daemon.ConfigReview.Signature == reviewDispatcher.GetSignature() {
+			continue
+		}
+		_ = reviewDispatcher.BeginReview(dbApp.Daemons[i], configreview.Triggers{configreview.ConfigModified}, nil)
+	}
+}
+
 // This function iterates over the app's daemons and checks if a new config
 // review should be performed. It is performed when daemon's configuration
 // or dispatcher's signature has changed.
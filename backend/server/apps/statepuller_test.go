@@ -225,3 +225,52 @@ func TestConditionallyBeginKeaConfigReviews(t *testing.T) {
 	require.Equal(t, configreview.StorkAgentConfigModified, dispatcher.CallLog[6].Triggers[0])
 	require.Equal(t, configreview.ConfigModified, dispatcher.CallLog[6].Triggers[1])
 }
+
+// Test that the BIND 9 daemon configuration review is initiated when the
+// configuration or the dispatcher's signature has changed.
+func TestConditionallyBeginBind9ConfigReviews(t *testing.T) {
+	app := &dbmodel.App{
+		Daemons: []*dbmodel.Daemon{
+			{
+				Name: "named",
+				Bind9Daemon: &dbmodel.Bind9Daemon{
+					Config: "options { recursion no; };",
+				},
+			},
+		},
+	}
+
+	dispatcher := &storktest.FakeDispatcher{}
+
+	// New daemon with no review. The review should be initiated.
+	conditionallyBeginBind9ConfigReviews(app, false, dispatcher)
+	require.Len(t, dispatcher.CallLog, 1)
+	require.Equal(t, "BeginReview", dispatcher.CallLog[0].CallName)
+	require.Equal(t, configreview.Triggers{configreview.ConfigModified}, dispatcher.CallLog[0].Triggers)
+
+	// The daemon has been reviewed and neither the configuration nor
+	// the dispatcher's signature have changed.
+	app.Daemons[0].ConfigReview = &dbmodel.ConfigReview{
+		Signature: "",
+	}
+	conditionallyBeginBind9ConfigReviews(app, false, dispatcher)
+	require.Len(t, dispatcher.CallLog, 2)
+	require.Equal(t, "GetSignature", dispatcher.CallLog[1].CallName)
+
+	// Modified configuration.
+	conditionallyBeginBind9ConfigReviews(app, true, dispatcher)
+	require.Len(t, dispatcher.CallLog, 3)
+	require.Equal(t, "BeginReview", dispatcher.CallLog[2].CallName)
+
+	// Modified dispatcher's signature.
+	dispatcher.Signature = "new signature"
+	conditionallyBeginBind9ConfigReviews(app, false, dispatcher)
+	require.Len(t, dispatcher.CallLog, 5)
+	require.Equal(t, "GetSignature", dispatcher.CallLog[3].CallName)
+	require.Equal(t, "BeginReview", dispatcher.CallLog[4].CallName)
+
+	// No configuration.
+	app.Daemons[0].Bind9Daemon.Config = ""
+	conditionallyBeginBind9ConfigReviews(app, true, dispatcher)
+	require.Len(t, dispatcher.CallLog, 5)
+}
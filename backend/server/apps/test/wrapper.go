@@ -5,6 +5,7 @@ import (
 	keaconfig "isc.org/stork/appcfg/kea"
 	agentcomm "isc.org/stork/server/agentcomm"
 	"isc.org/stork/server/config"
+	"isc.org/stork/server/eventcenter"
 )
 
 // Implements ManagerAccessors interface for unit tests.
@@ -13,6 +14,7 @@ type ManagerAccessorsWrapper struct {
 	Agents       agentcomm.ConnectedAgents
 	DefLookup    keaconfig.DHCPOptionDefinitionLookup
 	DaemonLocker config.DaemonLocker
+	EventCenter  eventcenter.EventCenter
 }
 
 // Returns an instance of the database handler used by the configuration manager.
@@ -36,3 +38,8 @@ func (w ManagerAccessorsWrapper) GetDHCPOptionDefinitionLookup() keaconfig.DHCPO
 func (w ManagerAccessorsWrapper) GetDaemonLocker() config.DaemonLocker {
 	return w.DaemonLocker
 }
+
+// Returns an interface to the event center.
+func (w ManagerAccessorsWrapper) GetEventCenter() eventcenter.EventCenter {
+	return w.EventCenter
+}
@@ -7,9 +7,12 @@ import (
 	"github.com/go-pg/pg/v10"
 	pkgerrors "github.com/pkg/errors"
 	keaconfig "isc.org/stork/appcfg/kea"
+	keactrl "isc.org/stork/appctrl/kea"
 	"isc.org/stork/datamodel"
 	agentcomm "isc.org/stork/server/agentcomm"
 	dbmodel "isc.org/stork/server/database/model"
+	"isc.org/stork/server/eventcenter"
+	storkutil "isc.org/stork/util"
 )
 
 var _ TransactionStateAccessor = (*TransactionState[any])(nil)
@@ -22,6 +25,8 @@ var _ TransactionStateAccessor = (*TransactionState[any])(nil)
 type TransactionStateAccessor interface {
 	// Returns config updates with the Recipe type any.
 	GetUpdates() []*Update[any]
+	// Returns a copy of the transaction state.
+	Copy() TransactionStateAccessor
 }
 
 // A structure describing a single configuration update that may be applied
@@ -87,12 +92,27 @@ func (ae AnnotatedEntity[T]) GetEntity() T {
 	return ae.entity
 }
 
+// Describes the changes that a config update transaction would make in
+// a single daemon. It comprises the commands that would be sent to the
+// daemon and the structural differences between the affected part of the
+// daemon's configuration before and after the update.
+type DaemonChangesPreview struct {
+	DaemonID   int64
+	DaemonName string
+	AppID      int64
+	AppName    string
+	Commands   []*keactrl.Command
+	Changes    []storkutil.JSONDiffEntry
+}
+
 // Interface of the Kea configuration module.
 type KeaModule interface {
 	BeginGlobalParametersUpdate(context.Context, []int64) (context.Context, error)
 	ApplyGlobalParametersUpdate(context.Context, []AnnotatedEntity[*keaconfig.SettableConfig]) (context.Context, error)
 	BeginHostAdd(context.Context) (context.Context, error)
 	ApplyHostAdd(context.Context, *dbmodel.Host) (context.Context, error)
+	BeginHostsImport(context.Context) (context.Context, error)
+	ApplyHostsImport(context.Context, []*dbmodel.Host) (context.Context, error)
 	BeginHostUpdate(context.Context, int64) (context.Context, error)
 	ApplyHostUpdate(context.Context, *dbmodel.Host) (context.Context, error)
 	BeginHostDelete(context.Context) (context.Context, error)
@@ -107,6 +127,23 @@ type KeaModule interface {
 	BeginSubnetUpdate(context.Context, int64) (context.Context, error)
 	ApplySubnetUpdate(context.Context, *dbmodel.Subnet) (context.Context, error)
 	ApplySubnetDelete(context.Context, *dbmodel.Subnet) (context.Context, error)
+	BeginClientClassAdd(context.Context) (context.Context, error)
+	ApplyClientClassAdd(context.Context, *keaconfig.ClientClass, []dbmodel.Daemon) (context.Context, error)
+	BeginClientClassUpdate(context.Context, string, []int64) (context.Context, error)
+	ApplyClientClassUpdate(context.Context, *keaconfig.ClientClass) (context.Context, error)
+	ApplyClientClassDelete(context.Context, string, []dbmodel.Daemon) (context.Context, error)
+	BeginOptionDefAdd(context.Context) (context.Context, error)
+	ApplyOptionDefAdd(context.Context, *keaconfig.OptionDef, []dbmodel.Daemon) (context.Context, error)
+	BeginOptionDefUpdate(context.Context, uint16, string, []int64) (context.Context, error)
+	ApplyOptionDefUpdate(context.Context, *keaconfig.OptionDef) (context.Context, error)
+	ApplyOptionDefDelete(context.Context, uint16, string, []dbmodel.Daemon) (context.Context, error)
+	BeginDDNSDomainsUpdate(context.Context, int64) (context.Context, error)
+	ApplyDDNSDomainAdd(context.Context, keaconfig.D2DDNSDirection, *keaconfig.D2DDNSDomain) (context.Context, error)
+	ApplyDDNSDomainUpdate(context.Context, keaconfig.D2DDNSDirection, *keaconfig.D2DDNSDomain) (context.Context, error)
+	ApplyDDNSDomainDelete(context.Context, keaconfig.D2DDNSDirection, string) (context.Context, error)
+	ApplyConfigRollback(context.Context, dbmodel.Daemon, *dbmodel.KeaConfigRevision, bool) (context.Context, error)
+	ApplyConfigWrite(context.Context, dbmodel.Daemon) (context.Context, error)
+	Preview(context.Context, bool) ([]DaemonChangesPreview, error)
 }
 
 // Interface of the Kea configuration module used by the manager to
@@ -149,6 +186,9 @@ type ManagerAccessors interface {
 	// Returns an interface to the instance providing the daemon
 	// configurations' locking mechanism.
 	GetDaemonLocker() DaemonLocker
+	// Returns an interface to the event center. The manager uses it to
+	// notify the users about the executed scheduled configuration changes.
+	GetEventCenter() eventcenter.EventCenter
 }
 
 // Configuration manager interface exposing functions available to the
@@ -181,6 +221,19 @@ func (state TransactionState[T]) GetUpdates() (updates []*Update[any]) {
 	return
 }
 
+// Returns a copy of the transaction state. The updates are copied, so
+// setting the recipes in the copy does not affect the original state. This
+// function belongs to the TransactionStateAccessor interface.
+func (state TransactionState[T]) Copy() TransactionStateAccessor {
+	updates := make([]*Update[T], 0, len(state.Updates))
+	for _, update := range state.Updates {
+		updateCopy := *update
+		updates = append(updates, &updateCopy)
+	}
+	state.Updates = updates
+	return state
+}
+
 // Creates new config update instance.
 func NewUpdate[T any](target datamodel.AppType, operation string, daemonIDs ...int64) *Update[T] {
 	return &Update[T]{
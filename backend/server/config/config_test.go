@@ -103,3 +103,25 @@ func TestGetUpdates(t *testing.T) {
 		require.Equal(t, "foo", recipe.param)
 	}
 }
+
+// Test that the copied transaction state can be modified without
+// affecting the original state.
+func TestCopyTransactionState(t *testing.T) {
+	state := NewTransactionStateWithUpdate[testRecipe](datamodel.AppTypeKea, "host_update", 1)
+	state.Scheduled = true
+	state.Updates[0].Recipe = testRecipe{
+		param: "foo",
+	}
+
+	copiedState, ok := state.Copy().(TransactionState[testRecipe])
+	require.True(t, ok)
+	require.True(t, copiedState.Scheduled)
+	require.Len(t, copiedState.Updates, 1)
+	require.Equal(t, "host_update", copiedState.Updates[0].Operation)
+	require.Equal(t, "foo", copiedState.Updates[0].Recipe.param)
+
+	err := copiedState.SetRecipeForUpdate(0, &testRecipe{param: "bar"})
+	require.NoError(t, err)
+	require.Equal(t, "bar", copiedState.Updates[0].Recipe.param)
+	require.Equal(t, "foo", state.Updates[0].Recipe.param)
+}
@@ -23,6 +23,10 @@ const (
 	LockContextKey
 	// A context key for accessing a list of daemon IDs.
 	DaemonsContextKey
+	// A context key for accessing a flag indicating whether the committed
+	// configuration changes should be automatically written to the
+	// configuration files.
+	ConfigWriteContextKey
 )
 
 // Convenience function retrieving a value from the context. If the context
@@ -32,6 +36,14 @@ func GetValueAsInt64(ctx context.Context, key ContextKey) (value int64, ok bool)
 	return
 }
 
+// Checks whether the committed configuration changes should be automatically
+// written to the configuration files. It returns true when the context does
+// not specify it explicitly.
+func IsConfigWriteEnabled(ctx context.Context) bool {
+	enabled, ok := ctx.Value(ConfigWriteContextKey).(bool)
+	return !ok || enabled
+}
+
 // Convenience function retrieving a transaction state from the context. If
 // the context doesn't contain the transaction state, the second returned
 // parameter is false.
@@ -50,6 +62,20 @@ func GetAnyTransactionState(ctx context.Context) (state TransactionStateAccessor
 	return
 }
 
+// Returns a new context holding a copy of the transaction state. The recipes
+// set in the returned context do not affect the transaction state held in the
+// original context. It is useful when the changes should be tentatively
+// applied, e.g., to preview them, without modifying the transaction state
+// remembered by the config manager. It returns an error if the context
+// does not contain a transaction state.
+func CopyTransactionState(ctx context.Context) (context.Context, error) {
+	state, ok := GetAnyTransactionState(ctx)
+	if !ok {
+		return ctx, pkgerrors.New("transaction state does not exist in the context")
+	}
+	return context.WithValue(ctx, StateContextKey, state.Copy()), nil
+}
+
 // Gets a value from the transaction state for a given update index, under the
 // specified name in the recipe. It returns an error if the specified index
 // is out of bounds or when the value doesn't exist.
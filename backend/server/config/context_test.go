@@ -32,6 +32,20 @@ func TestGetValueAsInt64(t *testing.T) {
 	require.False(t, ok)
 }
 
+// Test checking whether the configuration changes should be written to
+// the configuration files.
+func TestIsConfigWriteEnabled(t *testing.T) {
+	// Enabled by default.
+	ctx := context.Background()
+	require.True(t, IsConfigWriteEnabled(ctx))
+
+	ctx = context.WithValue(ctx, ConfigWriteContextKey, false)
+	require.False(t, IsConfigWriteEnabled(ctx))
+
+	ctx = context.WithValue(ctx, ConfigWriteContextKey, true)
+	require.True(t, IsConfigWriteEnabled(ctx))
+}
+
 // Test convenience function returning transaction state.
 func TestGetTransactionState(t *testing.T) {
 	state := TransactionState[testRecipe]{
@@ -169,3 +183,31 @@ func TestGetValueForUpdateInContextIndexOutOfBounds(t *testing.T) {
 	require.Error(t, err)
 	require.Nil(t, returnedRecipe)
 }
+
+// Test that the recipes set in the context holding a copy of the
+// transaction state do not affect the original context.
+func TestCopyTransactionStateInContext(t *testing.T) {
+	state := NewTransactionStateWithUpdate[testRecipe](datamodel.AppTypeKea, "host_update", 1)
+	originalCtx := context.WithValue(context.Background(), StateContextKey, *state)
+
+	ctx, err := CopyTransactionState(originalCtx)
+	require.NoError(t, err)
+
+	ctx, err = SetRecipeForUpdate(ctx, 0, &testRecipe{param: "foo"})
+	require.NoError(t, err)
+
+	returnedRecipe, err := GetRecipeForUpdate[testRecipe](ctx, 0)
+	require.NoError(t, err)
+	require.Equal(t, "foo", returnedRecipe.param)
+
+	originalRecipe, err := GetRecipeForUpdate[testRecipe](originalCtx, 0)
+	require.NoError(t, err)
+	require.Empty(t, originalRecipe.param)
+}
+
+// Test that an error is returned when trying to copy the transaction state
+// when the context does not contain the state.
+func TestCopyTransactionStateInContextNoState(t *testing.T) {
+	_, err := CopyTransactionState(context.Background())
+	require.Error(t, err)
+}
@@ -106,6 +106,176 @@ func (e SubnetNotFoundError) Error() string {
 	return fmt.Sprintf("subnet with ID %d not found", e.subnetID)
 }
 
+// An error returned when specified client class is not found in the
+// daemon's configuration.
+type ClientClassNotFoundError struct {
+	name     string
+	daemonID int64
+}
+
+// Create new instance of the ClientClassNotFoundError.
+func NewClientClassNotFoundError(name string, daemonID int64) error {
+	return &ClientClassNotFoundError{
+		name:     name,
+		daemonID: daemonID,
+	}
+}
+
+// Returns error string.
+func (e ClientClassNotFoundError) Error() string {
+	return fmt.Sprintf("client class %s not found in the configuration of the daemon with ID %d", e.name, e.daemonID)
+}
+
+// An error returned when a client class with the specified name already
+// exists in the daemon's configuration.
+type ClientClassExistsError struct {
+	name     string
+	daemonID int64
+}
+
+// Create new instance of the ClientClassExistsError.
+func NewClientClassExistsError(name string, daemonID int64) error {
+	return &ClientClassExistsError{
+		name:     name,
+		daemonID: daemonID,
+	}
+}
+
+// Returns error string.
+func (e ClientClassExistsError) Error() string {
+	return fmt.Sprintf("client class %s already exists in the configuration of the daemon with ID %d", e.name, e.daemonID)
+}
+
+// An error returned when an option definition with the specified code and
+// space was not found in the daemon's configuration.
+type OptionDefNotFoundError struct {
+	code     uint16
+	space    string
+	daemonID int64
+}
+
+// Create new instance of the OptionDefNotFoundError.
+func NewOptionDefNotFoundError(code uint16, space string, daemonID int64) error {
+	return &OptionDefNotFoundError{
+		code:     code,
+		space:    space,
+		daemonID: daemonID,
+	}
+}
+
+// Returns error string.
+func (e OptionDefNotFoundError) Error() string {
+	return fmt.Sprintf("option definition with code %d in the %s option space not found in the configuration of the daemon with ID %d",
+		e.code, e.space, e.daemonID)
+}
+
+// An error returned when an option definition with the specified code and
+// space already exists in the daemon's configuration.
+type OptionDefExistsError struct {
+	code     uint16
+	space    string
+	daemonID int64
+}
+
+// Create new instance of the OptionDefExistsError.
+func NewOptionDefExistsError(code uint16, space string, daemonID int64) error {
+	return &OptionDefExistsError{
+		code:     code,
+		space:    space,
+		daemonID: daemonID,
+	}
+}
+
+// Returns error string.
+func (e OptionDefExistsError) Error() string {
+	return fmt.Sprintf("option definition with code %d in the %s option space already exists in the configuration of the daemon with ID %d",
+		e.code, e.space, e.daemonID)
+}
+
+// An error returned when an option definition is invalid for the daemon
+// (e.g., it redefines a standard option).
+type InvalidOptionDefError struct {
+	reason   string
+	daemonID int64
+}
+
+// Create new instance of the InvalidOptionDefError.
+func NewInvalidOptionDefError(reason string, daemonID int64) error {
+	return &InvalidOptionDefError{
+		reason:   reason,
+		daemonID: daemonID,
+	}
+}
+
+// Returns error string.
+func (e InvalidOptionDefError) Error() string {
+	return fmt.Sprintf("invalid option definition for the daemon with ID %d: %s", e.daemonID, e.reason)
+}
+
+// An error returned when a DDNS domain with the specified name and
+// direction was not found in the D2 daemon's configuration.
+type DDNSDomainNotFoundError struct {
+	direction string
+	name      string
+	daemonID  int64
+}
+
+// Create new instance of the DDNSDomainNotFoundError.
+func NewDDNSDomainNotFoundError(direction, name string, daemonID int64) error {
+	return &DDNSDomainNotFoundError{
+		direction: direction,
+		name:      name,
+		daemonID:  daemonID,
+	}
+}
+
+// Returns error string.
+func (e DDNSDomainNotFoundError) Error() string {
+	return fmt.Sprintf("DDNS domain %s not found in %s of the daemon with ID %d", e.name, e.direction, e.daemonID)
+}
+
+// An error returned when a DDNS domain with the specified name and
+// direction already exists in the D2 daemon's configuration.
+type DDNSDomainExistsError struct {
+	direction string
+	name      string
+	daemonID  int64
+}
+
+// Create new instance of the DDNSDomainExistsError.
+func NewDDNSDomainExistsError(direction, name string, daemonID int64) error {
+	return &DDNSDomainExistsError{
+		direction: direction,
+		name:      name,
+		daemonID:  daemonID,
+	}
+}
+
+// Returns error string.
+func (e DDNSDomainExistsError) Error() string {
+	return fmt.Sprintf("DDNS domain %s already exists in %s of the daemon with ID %d", e.name, e.direction, e.daemonID)
+}
+
+// An error returned when a DDNS domain is invalid for the D2 daemon (e.g.,
+// it refers to a non-existing TSIG key).
+type InvalidDDNSDomainError struct {
+	reason   string
+	daemonID int64
+}
+
+// Create new instance of the InvalidDDNSDomainError.
+func NewInvalidDDNSDomainError(reason string, daemonID int64) error {
+	return &InvalidDDNSDomainError{
+		reason:   reason,
+		daemonID: daemonID,
+	}
+}
+
+// Returns error string.
+func (e InvalidDDNSDomainError) Error() string {
+	return fmt.Sprintf("invalid DDNS domain for the daemon with ID %d: %s", e.daemonID, e.reason)
+}
+
 // An error returned when some of the daemons have no libdhcp_subnet_cmds hook
 // library configured.
 type NoSubnetCmdsHookError struct{}
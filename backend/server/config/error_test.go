@@ -52,6 +52,54 @@ func TestSubnetNotFoundError(t *testing.T) {
 	require.EqualError(t, err, "subnet with ID 234 not found")
 }
 
+// Test creation of an error which indicates that client class was not found.
+func TestClientClassNotFoundError(t *testing.T) {
+	err := NewClientClassNotFoundError("foo", 3)
+	require.EqualError(t, err, "client class foo not found in the configuration of the daemon with ID 3")
+}
+
+// Test creation of an error which indicates that client class already exists.
+func TestClientClassExistsError(t *testing.T) {
+	err := NewClientClassExistsError("foo", 3)
+	require.EqualError(t, err, "client class foo already exists in the configuration of the daemon with ID 3")
+}
+
+// Test creation of an error which indicates that option definition was not found.
+func TestOptionDefNotFoundError(t *testing.T) {
+	err := NewOptionDefNotFoundError(222, "dhcp4", 3)
+	require.EqualError(t, err, "option definition with code 222 in the dhcp4 option space not found in the configuration of the daemon with ID 3")
+}
+
+// Test creation of an error which indicates that option definition already exists.
+func TestOptionDefExistsError(t *testing.T) {
+	err := NewOptionDefExistsError(222, "dhcp4", 3)
+	require.EqualError(t, err, "option definition with code 222 in the dhcp4 option space already exists in the configuration of the daemon with ID 3")
+}
+
+// Test creation of an error which indicates that option definition is invalid.
+func TestInvalidOptionDefError(t *testing.T) {
+	err := NewInvalidOptionDefError("foo", 3)
+	require.EqualError(t, err, "invalid option definition for the daemon with ID 3: foo")
+}
+
+// Test creation of an error which indicates that DDNS domain was not found.
+func TestDDNSDomainNotFoundError(t *testing.T) {
+	err := NewDDNSDomainNotFoundError("forward-ddns", "example.org.", 3)
+	require.EqualError(t, err, "DDNS domain example.org. not found in forward-ddns of the daemon with ID 3")
+}
+
+// Test creation of an error which indicates that DDNS domain already exists.
+func TestDDNSDomainExistsError(t *testing.T) {
+	err := NewDDNSDomainExistsError("reverse-ddns", "2.0.192.in-addr.arpa.", 3)
+	require.EqualError(t, err, "DDNS domain 2.0.192.in-addr.arpa. already exists in reverse-ddns of the daemon with ID 3")
+}
+
+// Test creation of an error which indicates that DDNS domain is invalid.
+func TestInvalidDDNSDomainError(t *testing.T) {
+	err := NewInvalidDDNSDomainError("foo", 3)
+	require.EqualError(t, err, "invalid DDNS domain for the daemon with ID 3: foo")
+}
+
 // Test creation of an error which indicates that libdhcp_subnet_cmds was not configured.
 func TestNoSubnetCmdsHookError(t *testing.T) {
 	err := NewNoSubnetCmdsHookError()
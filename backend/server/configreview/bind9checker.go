@@ -0,0 +1,401 @@
+package configreview
+
+import (
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+
+	bind9config "isc.org/stork/appcfg/bind9"
+	storkutil "isc.org/stork/util"
+)
+
+// Maximum number of the configuration locations (e.g., views, zones)
+// listed in a single BIND 9 report. The remaining locations are summarized.
+const maxBind9Issues = 10
+
+// Default port of the BIND 9 statistics channels.
+const defaultBind9StatisticsPort = "80"
+
+// The deprecated BIND 9 options mapped to the options replacing them.
+// The replacement is empty if the option has no direct replacement.
+var deprecatedBind9Options = map[string]string{
+	"alt-transfer-source":       "",
+	"alt-transfer-source-v6":    "",
+	"auto-dnssec":               "dnssec-policy",
+	"dialup":                    "",
+	"dnskey-sig-validity":       "dnssec-policy",
+	"dnssec-dnskey-kskonly":     "dnssec-policy",
+	"dnssec-must-be-secure":     "",
+	"dnssec-update-mode":        "dnssec-policy",
+	"glue-cache":                "",
+	"heartbeat-interval":        "",
+	"keep-response-order":       "",
+	"managed-keys":              "trust-anchors",
+	"reserved-sockets":          "",
+	"resolver-nonbackoff-tries": "",
+	"resolver-retry-interval":   "",
+	"root-delegation-only":      "",
+	"sig-validity-interval":     "dnssec-policy",
+	"sortlist":                  "",
+	"tkey-dhkey":                "",
+	"trusted-keys":              "trust-anchors",
+	"update-check-ksk":          "dnssec-policy",
+	"use-alt-transfer-source":   "",
+}
+
+// A zone defined in the BIND 9 configuration and the view it belongs to.
+// The view is nil for the zones defined at the top level.
+type bind9ZoneInView struct {
+	zone *bind9config.Zone
+	view *bind9config.View
+}
+
+// Parses the configuration of the reviewed BIND 9 daemon.
+func getBind9Config(ctx *ReviewContext) (*bind9config.Config, error) {
+	return bind9config.Parse("named.conf", strings.NewReader(ctx.subjectDaemon.Bind9Daemon.Config))
+}
+
+// Returns all zones defined in the BIND 9 configuration, including the
+// zones defined in the views.
+func getBind9Zones(config *bind9config.Config) (zones []bind9ZoneInView) {
+	for _, zone := range config.GetZones() {
+		zones = append(zones, bind9ZoneInView{zone: zone})
+	}
+	for _, view := range config.GetViews() {
+		for _, zone := range view.GetZones() {
+			zones = append(zones, bind9ZoneInView{zone: zone, view: view})
+		}
+	}
+	return
+}
+
+// Returns the zone description used in the reports.
+func (z bind9ZoneInView) String() string {
+	if z.view != nil {
+		return fmt.Sprintf("zone %s in view %s", z.zone.Name, z.view.Name)
+	}
+	return fmt.Sprintf("zone %s", z.zone.Name)
+}
+
+// Returns the option value specified for the zone. If the option is not
+// specified for the zone, it is inherited from the view and the global
+// options. It returns an empty string if the option is not specified at
+// any level.
+func (z bind9ZoneInView) getOptionValue(options *bind9config.Options, identifier string) string {
+	if option := z.zone.GetOption(identifier); option != nil {
+		return option.Contents
+	}
+	return getBind9ViewOptionValue(options, z.view, identifier)
+}
+
+// Returns the also-notify clause specified for the zone, inherited from the
+// view or the global options. It returns nil if the clause is not specified
+// at any level.
+func (z bind9ZoneInView) getAlsoNotify(options *bind9config.Options) *bind9config.AlsoNotify {
+	if alsoNotify := z.zone.GetAlsoNotify(); alsoNotify != nil {
+		return alsoNotify
+	}
+	if z.view != nil {
+		if alsoNotify := z.view.GetAlsoNotify(); alsoNotify != nil {
+			return alsoNotify
+		}
+	}
+	if options != nil {
+		return options.GetAlsoNotify()
+	}
+	return nil
+}
+
+// Returns the option value specified for the view or inherited from the
+// global options. The view may be nil. It returns an empty string if the
+// option is not specified at any level.
+func getBind9ViewOptionValue(options *bind9config.Options, view *bind9config.View, identifier string) string {
+	if view != nil {
+		if option := view.GetOption(identifier); option != nil {
+			return option.Contents
+		}
+	}
+	if options != nil {
+		if option := options.GetOption(identifier); option != nil {
+			return option.Contents
+		}
+	}
+	return ""
+}
+
+// Returns the allow-* clause specified for the view or inherited from the
+// global options. The view may be nil. It returns nil if the clause is not
+// specified at any level.
+func getBind9ViewAllowClause(options *bind9config.Options, view *bind9config.View, identifier string) *bind9config.AllowClause {
+	if view != nil {
+		if allow := view.GetAllowClause(identifier); allow != nil {
+			return allow
+		}
+	}
+	if options != nil {
+		return options.GetAllowClause(identifier)
+	}
+	return nil
+}
+
+// Returns the allow-transfer clauses specified explicitly in the global
+// options, views and zones, with their location descriptions.
+func getBind9AllowTransferClauses(config *bind9config.Config) (locations []string, clauses []*bind9config.AllowClause) {
+	if options := config.GetOptions(); options != nil {
+		if allow := options.GetAllowClause("allow-transfer"); allow != nil {
+			locations = append(locations, "global options")
+			clauses = append(clauses, allow)
+		}
+	}
+	for _, view := range config.GetViews() {
+		if allow := view.GetAllowClause("allow-transfer"); allow != nil {
+			locations = append(locations, fmt.Sprintf("view %s", view.Name))
+			clauses = append(clauses, allow)
+		}
+	}
+	for _, zone := range getBind9Zones(config) {
+		if allow := zone.zone.GetAllowClause("allow-transfer"); allow != nil {
+			locations = append(locations, zone.String())
+			clauses = append(clauses, allow)
+		}
+	}
+	return
+}
+
+// Joins the descriptions of the found issues. If there are more issues
+// than maxBind9Issues, the remaining issues are summarized.
+func joinBind9Issues(issues []string) string {
+	if len(issues) > maxBind9Issues {
+		issues = append(issues[:maxBind9Issues:maxBind9Issues],
+			fmt.Sprintf("and %d more", len(issues)-maxBind9Issues))
+	}
+	return strings.Join(issues, ", ")
+}
+
+// The checker verifying if the BIND 9 server allows recursive queries
+// from any client. Recursion is enabled by default. If allow-recursion is
+// not specified, the server falls back to allow-query-cache and then to
+// allow-query. If none of them is specified, the recursion is only allowed
+// for the local networks. The server acting as an open resolver can be
+// abused in the DNS amplification attacks.
+func bind9RecursionOpen(ctx *ReviewContext) (*Report, error) {
+	config, err := getBind9Config(ctx)
+	if err != nil {
+		return nil, err
+	}
+	options := config.GetOptions()
+	views := config.GetViews()
+	if len(views) == 0 {
+		// Without views, the global options apply to the whole server.
+		views = []*bind9config.View{nil}
+	}
+	var locations []string
+	for _, view := range views {
+		if recursion := getBind9ViewOptionValue(options, view, "recursion"); recursion != "" && recursion != "yes" {
+			continue
+		}
+		var allow *bind9config.AllowClause
+		for _, identifier := range []string{"allow-recursion", "allow-query-cache", "allow-query"} {
+			if allow = getBind9ViewAllowClause(options, view, identifier); allow != nil {
+				break
+			}
+		}
+		if allow == nil || !config.MatchesAnyClient(allow.AdressMatchList) {
+			continue
+		}
+		if view == nil {
+			locations = append(locations, "global options")
+		} else {
+			locations = append(locations, fmt.Sprintf("view %s", view.Name))
+		}
+	}
+	if len(locations) == 0 {
+		return nil, nil
+	}
+	return NewReport(ctx, fmt.Sprintf("BIND 9 {daemon} allows recursive queries "+
+		"from any client (%s). An open resolver can be abused to amplify "+
+		"the DNS traffic in the denial of service attacks against third "+
+		"parties. Consider restricting the recursion to the trusted clients "+
+		"with the allow-recursion clause or disabling it with the "+
+		"recursion no clause.", joinBind9Issues(locations))).
+		referencingDaemon(ctx.subjectDaemon).
+		create()
+}
+
+// The checker verifying if the BIND 9 server allows zone transfers to any
+// client. Anyone can then download the complete zone contents.
+func bind9AllowTransferAny(ctx *ReviewContext) (*Report, error) {
+	config, err := getBind9Config(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var issues []string
+	locations, clauses := getBind9AllowTransferClauses(config)
+	for i, allow := range clauses {
+		if config.MatchesAnyClient(allow.AdressMatchList) {
+			issues = append(issues, locations[i])
+		}
+	}
+	if len(issues) == 0 {
+		return nil, nil
+	}
+	return NewReport(ctx, fmt.Sprintf("BIND 9 {daemon} allows zone transfers "+
+		"to any client (%s). Anyone can download the complete contents of "+
+		"the zones, which reveals the names and addresses of all hosts in "+
+		"these zones. Consider restricting the zone transfers to the "+
+		"secondary servers with the allow-transfer clause.", joinBind9Issues(issues))).
+		referencingDaemon(ctx.subjectDaemon).
+		create()
+}
+
+// The checker verifying if the BIND 9 server allows zone transfers to
+// the clients identified by their IP addresses rather than TSIG keys.
+// The transfers to any client are reported by a different checker, so
+// they are not reported here.
+func bind9TransfersWithoutTSIG(ctx *ReviewContext) (*Report, error) {
+	config, err := getBind9Config(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var issues []string
+	locations, clauses := getBind9AllowTransferClauses(config)
+	for i, allow := range clauses {
+		if !config.MatchesAnyClient(allow.AdressMatchList) && config.MatchesClientsWithoutKey(allow.AdressMatchList) {
+			issues = append(issues, locations[i])
+		}
+	}
+	if len(issues) == 0 {
+		return nil, nil
+	}
+	return NewReport(ctx, fmt.Sprintf("BIND 9 {daemon} allows zone transfers "+
+		"to the clients identified by their IP addresses rather than TSIG "+
+		"keys (%s). The source addresses can be spoofed and the transferred "+
+		"zone contents are not authenticated. Consider authenticating the "+
+		"secondary servers with the TSIG keys specified in the allow-transfer "+
+		"clause.", joinBind9Issues(issues))).
+		referencingDaemon(ctx.subjectDaemon).
+		create()
+}
+
+// The checker verifying if the notify and also-notify settings of the BIND 9
+// primary and secondary zones are consistent. The zone with the notify
+// explicit setting and no also-notify servers sends no NOTIFY messages at
+// all. The also-notify servers are ignored when notify is disabled.
+func bind9NotifyInconsistent(ctx *ReviewContext) (*Report, error) {
+	config, err := getBind9Config(ctx)
+	if err != nil {
+		return nil, err
+	}
+	options := config.GetOptions()
+	var issues []string
+	for _, zone := range getBind9Zones(config) {
+		if zoneType := zone.zone.GetType(); zoneType != "primary" && zoneType != "secondary" {
+			continue
+		}
+		alsoNotify := zone.getAlsoNotify(options)
+		hasServers := alsoNotify != nil && len(alsoNotify.Servers) > 0
+		switch notify := zone.getOptionValue(options, "notify"); {
+		case notify == "explicit" && !hasServers:
+			issues = append(issues, fmt.Sprintf("%s has notify explicit but no also-notify servers", zone))
+		case notify == "no" && hasServers:
+			issues = append(issues, fmt.Sprintf("%s has also-notify servers but notify is disabled", zone))
+		}
+	}
+	if len(issues) == 0 {
+		return nil, nil
+	}
+	return NewReport(ctx, fmt.Sprintf("BIND 9 {daemon} configuration contains "+
+		"%s with inconsistent notify and also-notify settings: %s. The "+
+		"secondary servers may not learn about the zone changes until the "+
+		"zone refresh time elapses. Consider enabling notify or removing the "+
+		"unused also-notify servers.",
+		storkutil.FormatNoun(int64(len(issues)), "zone", "s"),
+		joinBind9Issues(issues))).
+		referencingDaemon(ctx.subjectDaemon).
+		create()
+}
+
+// The checker verifying if the BIND 9 statistics channels are exposed to
+// any client on a non-loopback address. The statistics reveal the server
+// configuration details and the traffic characteristics. The channels are
+// open to any client when the allow clause is not specified.
+func bind9StatisticsChannelsExposed(ctx *ReviewContext) (*Report, error) {
+	config, err := getBind9Config(ctx)
+	if err != nil {
+		return nil, err
+	}
+	channels := config.GetStatisticsChannels()
+	if channels == nil {
+		return nil, nil
+	}
+	var issues []string
+	for _, channel := range channels.Clauses {
+		if ip := net.ParseIP(channel.Address); ip != nil && ip.IsLoopback() {
+			continue
+		}
+		if channel.Allow != nil && !config.MatchesAnyClient(channel.Allow) {
+			continue
+		}
+		port := channel.Port
+		if port == "" {
+			port = defaultBind9StatisticsPort
+		}
+		issues = append(issues, net.JoinHostPort(channel.Address, port))
+	}
+	if len(issues) == 0 {
+		return nil, nil
+	}
+	return NewReport(ctx, fmt.Sprintf("BIND 9 {daemon} exposes the statistics "+
+		"channels to any client (%s). The statistics reveal the server "+
+		"configuration details and the traffic characteristics. Consider "+
+		"restricting the access to the channels with the allow clause or "+
+		"listening on a loopback address.", joinBind9Issues(issues))).
+		referencingDaemon(ctx.subjectDaemon).
+		create()
+}
+
+// The checker verifying if the BIND 9 configuration contains deprecated
+// options. They will be removed in the future BIND 9 releases, causing
+// the server to fail to load the configuration.
+func bind9DeprecatedOptions(ctx *ReviewContext) (*Report, error) {
+	config, err := getBind9Config(ctx)
+	if err != nil {
+		return nil, err
+	}
+	identifiers := config.GetStatementIdentifiers()
+	if options := config.GetOptions(); options != nil {
+		identifiers = append(identifiers, options.GetClauseIdentifiers()...)
+	}
+	for _, view := range config.GetViews() {
+		identifiers = append(identifiers, view.GetClauseIdentifiers()...)
+	}
+	for _, zone := range getBind9Zones(config) {
+		identifiers = append(identifiers, zone.zone.GetClauseIdentifiers()...)
+	}
+	var deprecated []string
+	for _, identifier := range identifiers {
+		if _, ok := deprecatedBind9Options[identifier]; ok && !slices.Contains(deprecated, identifier) {
+			deprecated = append(deprecated, identifier)
+		}
+	}
+	if len(deprecated) == 0 {
+		return nil, nil
+	}
+	slices.Sort(deprecated)
+	var issues []string
+	for _, identifier := range deprecated {
+		if replacement := deprecatedBind9Options[identifier]; replacement != "" {
+			identifier = fmt.Sprintf("%s (replaced by %s)", identifier, replacement)
+		}
+		issues = append(issues, identifier)
+	}
+	return NewReport(ctx, fmt.Sprintf("BIND 9 {daemon} configuration contains "+
+		"%s: %s. The deprecated options will be removed in the future BIND 9 "+
+		"releases and the server will fail to load the configuration containing "+
+		"them. Consider updating the configuration.",
+		storkutil.FormatNoun(int64(len(issues)), "deprecated option", "s"),
+		joinBind9Issues(issues))).
+		referencingDaemon(ctx.subjectDaemon).
+		create()
+}
@@ -0,0 +1,328 @@
+package configreview
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	dbmodel "isc.org/stork/server/database/model"
+)
+
+// Creates review context for a BIND 9 daemon with the specified configuration.
+func createBind9ReviewContext(config string) *ReviewContext {
+	daemon := dbmodel.NewBind9Daemon(true)
+	daemon.ID = 1
+	daemon.Bind9Daemon.Config = config
+	return newReviewContext(nil, daemon, Triggers{ManualRun}, func(i int64, err error) {})
+}
+
+// Tests that the checker finds the server allowing recursion for any client.
+func TestBind9RecursionOpen(t *testing.T) {
+	ctx := createBind9ReviewContext(`
+		options {
+			recursion yes;
+			allow-recursion { any; };
+		};
+	`)
+	report, err := bind9RecursionOpen(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, report)
+	require.Contains(t, *report.content, "allows recursive queries from any client (global options)")
+	require.Len(t, report.refDaemonIDs, 1)
+	require.EqualValues(t, 1, report.refDaemonIDs[0])
+}
+
+// Tests that the checker falls back to the allow-query-cache and allow-query
+// clauses and takes into account the recursion settings in the views.
+func TestBind9RecursionOpenViews(t *testing.T) {
+	ctx := createBind9ReviewContext(`
+		acl everyone { any; };
+		options {
+			allow-query { everyone; };
+		};
+		view "internal" {
+			allow-query-cache { 192.0.2.0/24; };
+		};
+		view "external" {
+		};
+		view "authoritative" {
+			recursion no;
+		};
+	`)
+	report, err := bind9RecursionOpen(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, report)
+	require.Contains(t, *report.content, "allows recursive queries from any client (view external)")
+}
+
+// Tests that the checker doesn't report the recursion restricted to the
+// trusted clients, disabled or allowed by default.
+func TestBind9RecursionNotOpen(t *testing.T) {
+	configs := []string{
+		`options { recursion yes; allow-recursion { localnets; localhost; }; };`,
+		`options { recursion no; allow-recursion { any; }; };`,
+		`options { directory "/var/cache/bind"; };`,
+		`view "internal" { allow-recursion { !any; }; };`,
+	}
+	for _, config := range configs {
+		report, err := bind9RecursionOpen(createBind9ReviewContext(config))
+		require.NoError(t, err, config)
+		require.Nil(t, report, config)
+	}
+}
+
+// Tests that the checker returns an error for an invalid configuration.
+func TestBind9RecursionOpenInvalidConfig(t *testing.T) {
+	report, err := bind9RecursionOpen(createBind9ReviewContext(`options {`))
+	require.Error(t, err)
+	require.Nil(t, report)
+}
+
+// Tests that the checker finds the zone transfers allowed to any client.
+func TestBind9AllowTransferAny(t *testing.T) {
+	ctx := createBind9ReviewContext(`
+		options {
+			allow-transfer { any; };
+		};
+		view "internal" {
+			allow-transfer { 192.0.2.1; };
+			zone "example.com" {
+				type primary;
+				allow-transfer { 0.0.0.0/0; };
+			};
+		};
+		zone "example.org" {
+			type primary;
+			allow-transfer port 853 { any; };
+		};
+		zone "example.net" {
+			type primary;
+			allow-transfer { none; };
+		};
+	`)
+	report, err := bind9AllowTransferAny(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, report)
+	require.Contains(t, *report.content, "allows zone transfers to any client (global options, zone example.org, zone example.com in view internal)")
+	require.Len(t, report.refDaemonIDs, 1)
+}
+
+// Tests that the checker doesn't report the restricted zone transfers.
+func TestBind9AllowTransferNotAny(t *testing.T) {
+	ctx := createBind9ReviewContext(`
+		options {
+			allow-transfer { !any; };
+		};
+		zone "example.org" {
+			type primary;
+			allow-transfer { key "xfr-key"; };
+		};
+	`)
+	report, err := bind9AllowTransferAny(ctx)
+	require.NoError(t, err)
+	require.Nil(t, report)
+}
+
+// Tests that the checker finds the zone transfers authorized by the IP
+// addresses rather than the TSIG keys.
+func TestBind9TransfersWithoutTSIG(t *testing.T) {
+	ctx := createBind9ReviewContext(`
+		acl secondaries { 192.0.2.1; 192.0.2.2; };
+		options {
+			allow-transfer { secondaries; };
+		};
+		view "internal" {
+			allow-transfer { key "xfr-key"; };
+			zone "example.com" {
+				type primary;
+				allow-transfer { localhost; };
+			};
+			zone "example.net" {
+				type primary;
+				allow-transfer { 2001:db8::1; };
+			};
+		};
+		zone "example.org" {
+			type primary;
+			allow-transfer { any; };
+		};
+	`)
+	report, err := bind9TransfersWithoutTSIG(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, report)
+	require.Contains(t, *report.content, "rather than TSIG keys (global options, zone example.net in view internal)")
+	require.Len(t, report.refDaemonIDs, 1)
+}
+
+// Tests that the checker doesn't report the zone transfers authorized
+// by the TSIG keys.
+func TestBind9TransfersWithTSIG(t *testing.T) {
+	ctx := createBind9ReviewContext(`
+		options {
+			allow-transfer { key "xfr-key"; !192.0.2.1; };
+		};
+	`)
+	report, err := bind9TransfersWithoutTSIG(ctx)
+	require.NoError(t, err)
+	require.Nil(t, report)
+}
+
+// Tests that the checker finds the zones with inconsistent notify and
+// also-notify settings.
+func TestBind9NotifyInconsistent(t *testing.T) {
+	ctx := createBind9ReviewContext(`
+		options {
+			notify explicit;
+		};
+		view "internal" {
+			also-notify { 192.0.2.1; };
+			zone "example.com" {
+				type master;
+			};
+			zone "example.net" {
+				type slave;
+				notify no;
+			};
+		};
+		zone "example.org" {
+			type primary;
+		};
+		zone "example.edu" {
+			type primary;
+			also-notify { };
+		};
+		zone "example.info" {
+			type forward;
+		};
+		zone "example.biz" {
+			type primary;
+			notify yes;
+		};
+	`)
+	report, err := bind9NotifyInconsistent(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, report)
+	require.Contains(t, *report.content, "contains 3 zones with inconsistent notify and also-notify settings: "+
+		"zone example.org has notify explicit but no also-notify servers, "+
+		"zone example.edu has notify explicit but no also-notify servers, "+
+		"zone example.net in view internal has also-notify servers but notify is disabled.")
+	require.Len(t, report.refDaemonIDs, 1)
+}
+
+// Tests that the checker doesn't report the consistent notify settings.
+func TestBind9NotifyConsistent(t *testing.T) {
+	ctx := createBind9ReviewContext(`
+		options {
+			notify explicit;
+			also-notify { 192.0.2.1 port 53 key "notify-key"; };
+		};
+		zone "example.org" {
+			type primary;
+		};
+		zone "example.com" {
+			type primary;
+			notify no;
+			also-notify { };
+		};
+	`)
+	report, err := bind9NotifyInconsistent(ctx)
+	require.NoError(t, err)
+	require.Nil(t, report)
+}
+
+// Tests that the checker finds the statistics channels exposed to any
+// client.
+func TestBind9StatisticsChannelsExposed(t *testing.T) {
+	ctx := createBind9ReviewContext(`
+		acl everyone { any; };
+		statistics-channels {
+			inet * port 8053;
+			inet 192.0.2.1 allow { everyone; };
+			inet 2001:db8::1 port 8080 allow { any; };
+			inet 192.0.2.2 port 8053 allow { 192.0.2.0/24; };
+			inet 127.0.0.1 port 8053;
+			inet ::1 port 8053;
+		};
+	`)
+	report, err := bind9StatisticsChannelsExposed(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, report)
+	require.Contains(t, *report.content, "exposes the statistics channels to any client (*:8053, 192.0.2.1:80, [2001:db8::1]:8080)")
+	require.Len(t, report.refDaemonIDs, 1)
+}
+
+// Tests that the checker doesn't report the statistics channels when they
+// are restricted or not configured.
+func TestBind9StatisticsChannelsNotExposed(t *testing.T) {
+	configs := []string{
+		`statistics-channels { inet 127.0.0.1 port 8053 allow { any; }; };`,
+		`statistics-channels { inet * port 8053 allow { localhost; }; };`,
+		`options { directory "/var/cache/bind"; };`,
+	}
+	for _, config := range configs {
+		report, err := bind9StatisticsChannelsExposed(createBind9ReviewContext(config))
+		require.NoError(t, err, config)
+		require.Nil(t, report, config)
+	}
+}
+
+// Tests that the checker finds the deprecated options.
+func TestBind9DeprecatedOptions(t *testing.T) {
+	ctx := createBind9ReviewContext(`
+		managed-keys {
+			"." initial-key 257 3 8 "AwEAAaz/tAm8yTn4Mfeh";
+		};
+		options {
+			glue-cache yes;
+			dnssec-validation auto;
+		};
+		view "internal" {
+			sortlist { { localnets; }; };
+			zone "example.com" {
+				type primary;
+				auto-dnssec maintain;
+			};
+		};
+		zone "example.org" {
+			type primary;
+			auto-dnssec maintain;
+		};
+	`)
+	report, err := bind9DeprecatedOptions(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, report)
+	require.Contains(t, *report.content, "contains 4 deprecated options: "+
+		"auto-dnssec (replaced by dnssec-policy), glue-cache, "+
+		"managed-keys (replaced by trust-anchors), sortlist.")
+	require.Len(t, report.refDaemonIDs, 1)
+}
+
+// Tests that the checker doesn't report the configuration without the
+// deprecated options.
+func TestBind9NoDeprecatedOptions(t *testing.T) {
+	ctx := createBind9ReviewContext(`
+		trust-anchors {
+			"." initial-key 257 3 8 "AwEAAaz/tAm8yTn4Mfeh";
+		};
+		options {
+			dnssec-validation auto;
+		};
+		zone "example.org" {
+			type primary;
+			dnssec-policy default;
+		};
+	`)
+	report, err := bind9DeprecatedOptions(ctx)
+	require.NoError(t, err)
+	require.Nil(t, report)
+}
+
+// Tests that the number of the locations listed in a report is limited.
+func TestJoinBind9Issues(t *testing.T) {
+	var issues []string
+	for i := 0; i < maxBind9Issues; i++ {
+		issues = append(issues, "zone")
+	}
+	require.Equal(t, "zone, zone, zone, zone, zone, zone, zone, zone, zone, zone", joinBind9Issues(issues))
+	issues = append(issues, "zone", "zone")
+	require.Equal(t, "zone, zone, zone, zone, zone, zone, zone, zone, zone, zone, and 2 more", joinBind9Issues(issues))
+}
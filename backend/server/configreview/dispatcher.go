@@ -115,7 +115,10 @@ type taggedReport struct {
 // daemon configuration),
 // - reports: configuration reports produced so far,
 // - callback: user callback to invoke after the review,
-// - trigger: a trigger that started the current review.
+// - trigger: a trigger that started the current review,
+// - checkerName: a name of the currently running checker,
+// - suppressions: active suppressions of the findings related to
+// specific objects (e.g., subnets) applying to the subject daemon.
 type ReviewContext struct {
 	db            *dbops.PgDB
 	subjectDaemon *dbmodel.Daemon
@@ -123,6 +126,8 @@ type ReviewContext struct {
 	reports       []taggedReport
 	callback      CallbackFunc
 	triggers      Triggers
+	checkerName   string
+	suppressions  []dbmodel.ConfigReviewSuppression
 }
 
 // Creates new review context instance.
@@ -136,6 +141,29 @@ func newReviewContext(db *dbops.PgDB, daemon *dbmodel.Daemon, triggers Triggers,
 	return ctx
 }
 
+// Checks if the findings of the currently running checker related to the
+// specified object are suppressed. The checkers skip such objects.
+func (c *ReviewContext) isSuppressed(objectType dbmodel.ConfigReviewSuppressionObjectType, objectKey string) bool {
+	for i := range c.suppressions {
+		if c.suppressions[i].Matches(c.checkerName, objectType, objectKey) {
+			return true
+		}
+	}
+	return false
+}
+
+// Checks if the findings of the currently running checker related to the
+// subnet with the specified prefix are suppressed.
+func (c *ReviewContext) isSubnetSuppressed(prefix string) bool {
+	return c.isSuppressed(dbmodel.ConfigReviewSuppressionObjectSubnet, prefix)
+}
+
+// Checks if the findings of the currently running checker related to the
+// host reservation with the specified ID are suppressed.
+func (c *ReviewContext) isHostSuppressed(hostID int64) bool {
+	return c.isSuppressed(dbmodel.ConfigReviewSuppressionObjectHost, fmt.Sprint(hostID))
+}
+
 // Returns a number of the generated reports.
 func (c *ReviewContext) getReportsCount() int {
 	return len(c.reports)
@@ -437,6 +465,17 @@ func (d *dispatcherImpl) runForDaemon(daemon *dbmodel.Daemon, triggers Triggers,
 
 	ctx := d.newContext(d.db, daemon, triggers, callback)
 
+	// Fetch the suppressions of the findings related to specific objects.
+	// The review proceeds without them in case of an error.
+	if d.db != nil {
+		suppressions, err := dbmodel.GetActiveConfigReviewSuppressions(d.db, daemon.ID)
+		if err != nil {
+			log.WithError(err).WithField("daemon_id", daemon.ID).
+				Error("Problem fetching config review suppressions; the review will include suppressed findings")
+		}
+		ctx.suppressions = suppressions
+	}
+
 	// If this is an internal run, the dispatch group selectors haven't
 	// been determined in the beginReview function.
 	var selectors DispatchGroupSelectors
@@ -455,6 +494,7 @@ func (d *dispatcherImpl) runForDaemon(daemon *dbmodel.Daemon, triggers Triggers,
 				}
 
 				// Execute checker.
+				ctx.checkerName = checker.name
 				report, err := checker.checkFn(ctx)
 				if err != nil {
 					log.Errorf("Malformed report created by the config review checker %s: %+v",
@@ -653,11 +693,15 @@ func (d *dispatcherImpl) populateReports(ctx *ReviewContext) (err error) {
 	}
 
 	// Add configuration review summary.
-	// todo: add config review summary for BIND9. Currently we don't because
-	// BIND 9 does not include a config hash.
-	if ctx.subjectDaemon.KeaDaemon != nil {
+	if ctx.subjectDaemon.KeaDaemon != nil || ctx.subjectDaemon.Bind9Daemon != nil {
+		configHash := ""
+		if ctx.subjectDaemon.KeaDaemon != nil {
+			configHash = ctx.subjectDaemon.KeaDaemon.ConfigHash
+		} else {
+			configHash = ctx.subjectDaemon.Bind9Daemon.ConfigHash
+		}
 		configReview := &dbmodel.ConfigReview{
-			ConfigHash: ctx.subjectDaemon.KeaDaemon.ConfigHash,
+			ConfigHash: configHash,
 			Signature:  d.GetSignature(),
 			DaemonID:   ctx.subjectDaemon.ID,
 		}
@@ -938,8 +982,21 @@ func RegisterDefaultCheckers(dispatcher Dispatcher) {
 	dispatcher.RegisterChecker(KeaDHCPDaemon, "pd_pools_exhausted_by_reservations", ExtendDefaultTriggers(DBHostsModified), delegatedPrefixPoolsExhaustedByReservations)
 	dispatcher.RegisterChecker(KeaDHCPDaemon, "subnet_cmds_and_cb_mutual_exclusion", GetDefaultTriggers(), subnetCmdsAndConfigBackendMutualExclusion)
 	dispatcher.RegisterChecker(KeaDHCPDaemon, "statistics_unavailable_due_to_number_overflow", GetDefaultTriggers(), gatheringStatisticsUnavailableDueToNumberOverflow)
+	dispatcher.RegisterChecker(KeaDHCPDaemon, "duplicated_host_identifiers", ExtendDefaultTriggers(DBHostsModified), hostIdentifiersDuplicated)
+	dispatcher.RegisterChecker(KeaDHCPDaemon, "duplicated_reserved_addresses", ExtendDefaultTriggers(DBHostsModified), reservedAddressesDuplicated)
+	dispatcher.RegisterChecker(KeaDHCPDaemon, "duplicated_reserved_hostnames", ExtendDefaultTriggers(DBHostsModified), reservedHostnamesDuplicated)
+	dispatcher.RegisterChecker(KeaDHCPDaemon, "lease_lifetimes_and_timers", GetDefaultTriggers(), lifetimesAndTimersInconsistent)
+	dispatcher.RegisterChecker(KeaDHCPDaemon, "invalid_option_data", GetDefaultTriggers(), optionDataInvalid)
+	dispatcher.RegisterChecker(KeaDHCPDaemon, "ha_peers_consistency", GetDefaultTriggers(), highAvailabilityPeersInconsistent)
+	dispatcher.RegisterChecker(KeaDHCPDaemon, "invalid_client_classes", GetDefaultTriggers(), clientClassesInvalid)
 	dispatcher.RegisterChecker(KeaCADaemon, "agent_credentials_over_https", GetDefaultTriggers(), credentialsOverHTTPS)
 	dispatcher.RegisterChecker(KeaCADaemon, "ca_control_sockets", GetDefaultTriggers(), controlSocketsCA)
+	dispatcher.RegisterChecker(Bind9Daemon, "bind9_open_recursion", GetDefaultTriggers(), bind9RecursionOpen)
+	dispatcher.RegisterChecker(Bind9Daemon, "bind9_allow_transfer_any", GetDefaultTriggers(), bind9AllowTransferAny)
+	dispatcher.RegisterChecker(Bind9Daemon, "bind9_unsigned_transfers", GetDefaultTriggers(), bind9TransfersWithoutTSIG)
+	dispatcher.RegisterChecker(Bind9Daemon, "bind9_notify_consistency", GetDefaultTriggers(), bind9NotifyInconsistent)
+	dispatcher.RegisterChecker(Bind9Daemon, "bind9_statistics_channels_exposure", GetDefaultTriggers(), bind9StatisticsChannelsExposed)
+	dispatcher.RegisterChecker(Bind9Daemon, "bind9_deprecated_options", GetDefaultTriggers(), bind9DeprecatedOptions)
 }
 
 // Fetches all checker preferences from the database and loads them into
@@ -284,6 +284,10 @@ func TestPopulateBind9Reports(t *testing.T) {
 			{
 				Name:   "named",
 				Active: true,
+				Bind9Daemon: &dbmodel.Bind9Daemon{
+					Config:     "options { recursion no; };",
+					ConfigHash: "1234",
+				},
 			},
 		},
 	}
@@ -330,6 +334,13 @@ func TestPopulateBind9Reports(t *testing.T) {
 	require.Len(t, reports, 1)
 	require.Equal(t, "test_checker", reports[0].CheckerName)
 	require.Equal(t, "Bind9 test output", *reports[0].Content)
+
+	// The review summary should include the configuration hash.
+	review, err := dbmodel.GetConfigReviewByDaemonID(db, daemons[0].ID)
+	require.NoError(t, err)
+	require.NotNil(t, review)
+	require.Equal(t, "1234", review.ConfigHash)
+	require.Equal(t, dispatcher.GetSignature(), review.Signature)
 }
 
 // Tests the scenario when another review for the same daemon is scheduled
@@ -695,6 +706,13 @@ func TestRegisterDefaultCheckers(t *testing.T) {
 	require.Contains(t, checkerNames, "canonical_prefix")
 	require.Contains(t, checkerNames, "subnet_cmds_and_cb_mutual_exclusion")
 	require.Contains(t, checkerNames, "statistics_unavailable_due_to_number_overflow")
+	require.Contains(t, checkerNames, "duplicated_host_identifiers")
+	require.Contains(t, checkerNames, "duplicated_reserved_addresses")
+	require.Contains(t, checkerNames, "duplicated_reserved_hostnames")
+	require.Contains(t, checkerNames, "lease_lifetimes_and_timers")
+	require.Contains(t, checkerNames, "invalid_option_data")
+	require.Contains(t, checkerNames, "ha_peers_consistency")
+	require.Contains(t, checkerNames, "invalid_client_classes")
 
 	checkerNames = []string{}
 	for _, p := range dispatcher.groups[KeaCADaemon].checkers {
@@ -704,20 +722,36 @@ func TestRegisterDefaultCheckers(t *testing.T) {
 	require.Contains(t, checkerNames, "agent_credentials_over_https")
 	require.Contains(t, checkerNames, "ca_control_sockets")
 
+	checkerNames = []string{}
+	for _, p := range dispatcher.groups[Bind9Daemon].checkers {
+		checkerNames = append(checkerNames, p.name)
+	}
+
+	require.Contains(t, checkerNames, "bind9_open_recursion")
+	require.Contains(t, checkerNames, "bind9_allow_transfer_any")
+	require.Contains(t, checkerNames, "bind9_unsigned_transfers")
+	require.Contains(t, checkerNames, "bind9_notify_consistency")
+	require.Contains(t, checkerNames, "bind9_statistics_channels_exposure")
+	require.Contains(t, checkerNames, "bind9_deprecated_options")
+
 	// Ensure that the appropriate triggers were registered for the
 	// default checkers.
 	require.Contains(t, dispatcher.groups[KeaDHCPDaemon].triggerRefCounts, ManualRun)
 	require.Contains(t, dispatcher.groups[KeaDHCPDaemon].triggerRefCounts, ConfigModified)
 	require.Contains(t, dispatcher.groups[KeaDHCPDaemon].triggerRefCounts, DBHostsModified)
 
-	require.EqualValues(t, 14, dispatcher.groups[KeaDHCPDaemon].triggerRefCounts[ManualRun])
-	require.EqualValues(t, 14, dispatcher.groups[KeaDHCPDaemon].triggerRefCounts[ConfigModified])
-	require.EqualValues(t, 4, dispatcher.groups[KeaDHCPDaemon].triggerRefCounts[DBHostsModified])
+	require.EqualValues(t, 21, dispatcher.groups[KeaDHCPDaemon].triggerRefCounts[ManualRun])
+	require.EqualValues(t, 21, dispatcher.groups[KeaDHCPDaemon].triggerRefCounts[ConfigModified])
+	require.EqualValues(t, 7, dispatcher.groups[KeaDHCPDaemon].triggerRefCounts[DBHostsModified])
 	require.EqualValues(t, 0, dispatcher.groups[KeaDHCPDaemon].triggerRefCounts[StorkAgentConfigModified])
 	require.EqualValues(t, 2, dispatcher.groups[KeaCADaemon].triggerRefCounts[ManualRun])
 	require.EqualValues(t, 2, dispatcher.groups[KeaCADaemon].triggerRefCounts[ConfigModified])
 	require.EqualValues(t, 0, dispatcher.groups[KeaCADaemon].triggerRefCounts[DBHostsModified])
 	require.EqualValues(t, 0, dispatcher.groups[KeaCADaemon].triggerRefCounts[StorkAgentConfigModified])
+	require.EqualValues(t, 6, dispatcher.groups[Bind9Daemon].triggerRefCounts[ManualRun])
+	require.EqualValues(t, 6, dispatcher.groups[Bind9Daemon].triggerRefCounts[ConfigModified])
+	require.EqualValues(t, 0, dispatcher.groups[Bind9Daemon].triggerRefCounts[DBHostsModified])
+	require.EqualValues(t, 0, dispatcher.groups[Bind9Daemon].triggerRefCounts[StorkAgentConfigModified])
 }
 
 // Verifies that registering new checkers and bumping up the
@@ -1076,6 +1110,120 @@ func TestReviewContextCounters(t *testing.T) {
 	require.EqualValues(t, 3, ctx.getIssuesCount())
 }
 
+// Test that the review context recognizes the objects suppressed for the
+// currently running checker.
+func TestReviewContextIsSuppressed(t *testing.T) {
+	// Arrange
+	ctx := newReviewContext(nil, &dbmodel.Daemon{ID: 42}, Triggers{ManualRun}, nil)
+	ctx.suppressions = []dbmodel.ConfigReviewSuppression{
+		{
+			CheckerName: "foo",
+			ObjectType:  dbmodel.ConfigReviewSuppressionObjectSubnet,
+			ObjectKey:   "2001:db8:0::/64",
+		},
+		{
+			CheckerName: "bar",
+			ObjectType:  dbmodel.ConfigReviewSuppressionObjectHost,
+			ObjectKey:   "5",
+		},
+	}
+
+	// Act & Assert
+	ctx.checkerName = "foo"
+	require.True(t, ctx.isSubnetSuppressed("2001:db8::/64"))
+	require.False(t, ctx.isSubnetSuppressed("2001:db8:1::/64"))
+	require.False(t, ctx.isHostSuppressed(5))
+
+	ctx.checkerName = "bar"
+	require.False(t, ctx.isSubnetSuppressed("2001:db8::/64"))
+	require.True(t, ctx.isHostSuppressed(5))
+	require.False(t, ctx.isHostSuppressed(6))
+}
+
+// Test that the dispatcher passes the active suppressions applying to the
+// reviewed daemon to the checkers.
+func TestBeginReviewWithSuppressions(t *testing.T) {
+	// Arrange
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	machine := &dbmodel.Machine{
+		Address:   "localhost",
+		AgentPort: 8080,
+	}
+	_ = dbmodel.AddMachine(db, machine)
+	app := &dbmodel.App{
+		Type:      dbmodel.AppTypeKea,
+		MachineID: machine.ID,
+		Daemons: []*dbmodel.Daemon{
+			dbmodel.NewKeaDaemon("dhcp4", true),
+			dbmodel.NewKeaDaemon("dhcp6", true),
+		},
+	}
+	daemons, _ := dbmodel.AddApp(db, app)
+
+	expired := time.Now().UTC().Add(-time.Hour)
+	suppressions := []*dbmodel.ConfigReviewSuppression{
+		{
+			CheckerName: "foo",
+			ObjectType:  dbmodel.ConfigReviewSuppressionObjectSubnet,
+			ObjectKey:   "192.0.2.0/24",
+			Reason:      "all daemons",
+		},
+		{
+			CheckerName: "foo",
+			DaemonID:    &daemons[0].ID,
+			ObjectType:  dbmodel.ConfigReviewSuppressionObjectSubnet,
+			ObjectKey:   "192.0.3.0/24",
+			Reason:      "this daemon",
+		},
+		{
+			CheckerName: "foo",
+			DaemonID:    &daemons[1].ID,
+			ObjectType:  dbmodel.ConfigReviewSuppressionObjectSubnet,
+			ObjectKey:   "192.0.4.0/24",
+			Reason:      "other daemon",
+		},
+		{
+			CheckerName: "foo",
+			DaemonID:    &daemons[0].ID,
+			ObjectType:  dbmodel.ConfigReviewSuppressionObjectSubnet,
+			ObjectKey:   "192.0.5.0/24",
+			Reason:      "expired",
+			ExpiresAt:   &expired,
+		},
+	}
+	for _, suppression := range suppressions {
+		require.NoError(t, dbmodel.AddConfigReviewSuppression(db, suppression))
+	}
+
+	var suppressed []string
+	dispatcher := NewDispatcher(db)
+	dispatcher.RegisterChecker(KeaDHCPDaemon, "foo", Triggers{ManualRun}, func(ctx *ReviewContext) (*Report, error) {
+		for _, prefix := range []string{"192.0.2.0/24", "192.0.3.0/24", "192.0.4.0/24", "192.0.5.0/24"} {
+			if ctx.isSubnetSuppressed(prefix) {
+				suppressed = append(suppressed, prefix)
+			}
+		}
+		return nil, nil
+	})
+	dispatcher.Start()
+	defer dispatcher.Shutdown()
+
+	var wg sync.WaitGroup
+
+	// Act
+	wg.Add(1)
+	ok := dispatcher.BeginReview(daemons[0], Triggers{ManualRun}, func(i int64, err error) {
+		wg.Done()
+	})
+	wg.Wait()
+
+	// Assert
+	require.True(t, ok)
+	require.Equal(t, []string{"192.0.2.0/24", "192.0.3.0/24"}, suppressed)
+}
+
 // Test that the internal run is recognized properly.
 func TestTriggersIsInternalRun(t *testing.T) {
 	t.Run("only internalRun trigger", func(t *testing.T) {
@@ -0,0 +1,93 @@
+package configreview
+
+import (
+	"context"
+	"encoding/json"
+
+	pkgerrors "github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"isc.org/stork/hooks/server/configreviewcallouts"
+	dbmodel "isc.org/stork/server/database/model"
+)
+
+// Converts the daemon to the data transfer object passed to the checkers
+// provided by the hooks.
+func newCalloutDaemon(daemon *dbmodel.Daemon) (*configreviewcallouts.Daemon, error) {
+	calloutDaemon := &configreviewcallouts.Daemon{
+		ID:      daemon.ID,
+		Name:    daemon.Name,
+		Version: daemon.Version,
+		AppID:   daemon.AppID,
+	}
+	switch {
+	case daemon.KeaDaemon != nil && daemon.KeaDaemon.Config != nil:
+		config, err := json.Marshal(daemon.KeaDaemon.Config)
+		if err != nil {
+			return nil, pkgerrors.Wrapf(err, "failed to serialize the configuration of daemon %d", daemon.ID)
+		}
+		calloutDaemon.Config = string(config)
+	case daemon.Bind9Daemon != nil:
+		calloutDaemon.Config = daemon.Bind9Daemon.Config
+	}
+	return calloutDaemon, nil
+}
+
+// Returns a checker function running the checker provided by a hook.
+func newHookCheckerFn(hookChecker configreviewcallouts.Checker) func(*ReviewContext) (*Report, error) {
+	return func(ctx *ReviewContext) (*Report, error) {
+		daemon, err := newCalloutDaemon(ctx.subjectDaemon)
+		if err != nil {
+			return nil, err
+		}
+		report, err := hookChecker.Check(context.Background(), daemon)
+		if err != nil {
+			return nil, pkgerrors.WithMessagef(err, "error occurred in the %s checker provided by a hook", hookChecker.GetName())
+		}
+		if report == nil {
+			return nil, nil
+		}
+		return NewReport(ctx, report.Content).
+			referencingDaemon(ctx.subjectDaemon).
+			create()
+	}
+}
+
+// Registers the configuration checkers provided by the hooks. The checkers
+// with the names colliding with the already registered checkers, and with
+// the unsupported selectors or triggers are skipped.
+func RegisterHookCheckers(dispatcher Dispatcher, hookCheckers []configreviewcallouts.Checker) {
+	registered := make(map[string]bool)
+	if metadata, err := dispatcher.GetCheckersMetadata(nil); err == nil {
+		for _, checker := range metadata {
+			registered[checker.Name] = true
+		}
+	}
+	for _, hookChecker := range hookCheckers {
+		name := hookChecker.GetName()
+		logger := log.WithField("checker", name)
+		if name == "" || registered[name] {
+			logger.Error("Skipping the config review checker provided by a hook because its name is empty or already used")
+			continue
+		}
+		selector := DispatchGroupSelector(hookChecker.GetSelector())
+		if selector < EachDaemon || selector > Bind9Daemon {
+			logger.WithField("selector", int(selector)).Error("Skipping the config review checker provided by a hook because of the unsupported selector")
+			continue
+		}
+		var triggers Triggers
+		for _, trigger := range hookChecker.GetTriggers() {
+			switch Trigger(trigger) {
+			case ManualRun, ConfigModified, DBHostsModified, StorkAgentConfigModified:
+				triggers = append(triggers, Trigger(trigger))
+			default:
+				logger.WithField("trigger", trigger).Warn("Ignoring the unsupported trigger of the config review checker provided by a hook")
+			}
+		}
+		if len(triggers) == 0 {
+			triggers = GetDefaultTriggers()
+		}
+		dispatcher.RegisterChecker(selector, name, triggers, newHookCheckerFn(hookChecker))
+		registered[name] = true
+		logger.WithField("selector", selector).Info("Registered the config review checker provided by a hook")
+	}
+}
@@ -0,0 +1,177 @@
+package configreview
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"isc.org/stork/hooks/server/configreviewcallouts"
+	dbmodel "isc.org/stork/server/database/model"
+)
+
+// Test implementation of the checker provided by a hook.
+type testHookChecker struct {
+	name     string
+	selector configreviewcallouts.DaemonSelector
+	triggers []configreviewcallouts.Trigger
+	report   *configreviewcallouts.Report
+	err      error
+	daemon   *configreviewcallouts.Daemon
+}
+
+// Returns the checker name.
+func (c *testHookChecker) GetName() string {
+	return c.name
+}
+
+// Returns the checker selector.
+func (c *testHookChecker) GetSelector() configreviewcallouts.DaemonSelector {
+	return c.selector
+}
+
+// Returns the checker triggers.
+func (c *testHookChecker) GetTriggers() []configreviewcallouts.Trigger {
+	return c.triggers
+}
+
+// Remembers the reviewed daemon and returns the configured report and error.
+func (c *testHookChecker) Check(ctx context.Context, daemon *configreviewcallouts.Daemon) (*configreviewcallouts.Report, error) {
+	c.daemon = daemon
+	return c.report, c.err
+}
+
+// Test that the checkers provided by the hooks are registered in the
+// dispatcher and the invalid checkers are skipped.
+func TestRegisterHookCheckers(t *testing.T) {
+	// Arrange
+	dispatcher := NewDispatcher(nil).(*dispatcherImpl)
+	RegisterDefaultCheckers(dispatcher)
+
+	hookCheckers := []configreviewcallouts.Checker{
+		&testHookChecker{
+			name:     "subnet_naming",
+			selector: configreviewcallouts.KeaDHCPDaemon,
+			triggers: []configreviewcallouts.Trigger{configreviewcallouts.ManualRun, "unknown", configreviewcallouts.DBHostsModified},
+		},
+		&testHookChecker{
+			name:     "zone_naming",
+			selector: configreviewcallouts.Bind9Daemon,
+		},
+		// Name collides with the built-in checker.
+		&testHookChecker{
+			name:     "stat_cmds_presence",
+			selector: configreviewcallouts.KeaDHCPDaemon,
+		},
+		// Name collides with the checker provided by another hook.
+		&testHookChecker{
+			name:     "subnet_naming",
+			selector: configreviewcallouts.KeaDaemon,
+		},
+		&testHookChecker{
+			name:     "",
+			selector: configreviewcallouts.KeaDHCPDaemon,
+		},
+		&testHookChecker{
+			name:     "unknown_selector",
+			selector: 100,
+		},
+	}
+
+	// Act
+	RegisterHookCheckers(dispatcher, hookCheckers)
+
+	// Assert
+	metadata, err := dispatcher.GetCheckersMetadata(nil)
+	require.NoError(t, err)
+	checkers := make(map[string]*CheckerMetadata)
+	for _, checker := range metadata {
+		checkers[checker.Name] = checker
+	}
+	require.Contains(t, checkers, "subnet_naming")
+	require.Equal(t, DispatchGroupSelectors{KeaDHCPDaemon}, checkers["subnet_naming"].Selectors)
+	require.Equal(t, Triggers{ManualRun, DBHostsModified}, checkers["subnet_naming"].Triggers)
+
+	require.Contains(t, checkers, "zone_naming")
+	require.Equal(t, DispatchGroupSelectors{Bind9Daemon}, checkers["zone_naming"].Selectors)
+	require.Equal(t, GetDefaultTriggers(), checkers["zone_naming"].Triggers)
+
+	require.Equal(t, DispatchGroupSelectors{KeaDHCPDaemon}, checkers["stat_cmds_presence"].Selectors)
+	require.NotContains(t, checkers, "")
+	require.NotContains(t, checkers, "unknown_selector")
+}
+
+// Test that the checker provided by a hook receives the daemon with the
+// configuration and its report is converted to the config review report.
+func TestHookCheckerFn(t *testing.T) {
+	// Arrange
+	daemon := dbmodel.NewKeaDaemon(dbmodel.DaemonNameDHCPv4, true)
+	daemon.ID = 42
+	daemon.AppID = 3
+	daemon.Version = "2.6.0"
+	_ = daemon.SetConfigFromJSON(`{"Dhcp4": {"valid-lifetime": 1000}}`)
+	ctx := newReviewContext(nil, daemon, Triggers{ManualRun}, func(i int64, err error) {})
+
+	hookChecker := &testHookChecker{
+		name: "subnet_naming",
+		report: &configreviewcallouts.Report{
+			Content: "{daemon} has invalid subnet names",
+		},
+	}
+
+	// Act
+	report, err := newHookCheckerFn(hookChecker)(ctx)
+
+	// Assert
+	require.NoError(t, err)
+	require.NotNil(t, report)
+	require.Equal(t, "{daemon} has invalid subnet names", *report.content)
+	require.EqualValues(t, 42, report.daemonID)
+	require.Equal(t, []int64{42}, report.refDaemonIDs)
+
+	require.NotNil(t, hookChecker.daemon)
+	require.EqualValues(t, 42, hookChecker.daemon.ID)
+	require.EqualValues(t, 3, hookChecker.daemon.AppID)
+	require.Equal(t, "dhcp4", hookChecker.daemon.Name)
+	require.Equal(t, "2.6.0", hookChecker.daemon.Version)
+	require.JSONEq(t, `{"Dhcp4": {"valid-lifetime": 1000}}`, hookChecker.daemon.Config)
+}
+
+// Test that the BIND 9 configuration is passed to the checker provided by
+// a hook and that no report is returned when the checker finds no issues.
+func TestHookCheckerFnBind9NoIssues(t *testing.T) {
+	// Arrange
+	daemon := dbmodel.NewBind9Daemon(true)
+	daemon.ID = 42
+	daemon.Bind9Daemon.Config = `options { directory "/var/cache/bind"; };`
+	ctx := newReviewContext(nil, daemon, Triggers{ManualRun}, func(i int64, err error) {})
+
+	hookChecker := &testHookChecker{name: "zone_naming"}
+
+	// Act
+	report, err := newHookCheckerFn(hookChecker)(ctx)
+
+	// Assert
+	require.NoError(t, err)
+	require.Nil(t, report)
+	require.NotNil(t, hookChecker.daemon)
+	require.Equal(t, `options { directory "/var/cache/bind"; };`, hookChecker.daemon.Config)
+}
+
+// Test that an error returned by the checker provided by a hook is
+// propagated.
+func TestHookCheckerFnError(t *testing.T) {
+	// Arrange
+	daemon := dbmodel.NewKeaDaemon(dbmodel.DaemonNameDHCPv4, true)
+	daemon.ID = 42
+	ctx := newReviewContext(nil, daemon, Triggers{ManualRun}, func(i int64, err error) {})
+
+	hookChecker := &testHookChecker{name: "subnet_naming", err: errors.New("test error")}
+
+	// Act
+	report, err := newHookCheckerFn(hookChecker)(ctx)
+
+	// Assert
+	require.ErrorContains(t, err, "error occurred in the subnet_naming checker provided by a hook: test error")
+	require.Nil(t, report)
+}
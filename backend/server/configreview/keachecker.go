@@ -1,9 +1,11 @@
 package configreview
 
 import (
+	"encoding/json"
 	"fmt"
 	"math/big"
 	"net/url"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
@@ -163,6 +165,9 @@ func checkSubnet4Dispensable(ctx *ReviewContext) (*Report, error) {
 	dispensableCount := int64(0)
 	for _, net := range sharedNetworks {
 		for _, subnet := range net.GetSubnets() {
+			if ctx.isSubnetSuppressed(subnet.GetPrefix()) {
+				continue
+			}
 			if len(subnet.GetPools()) == 0 && len(subnet.GetReservations()) == 0 &&
 				(!hostCmds || len(dbHosts[subnet.GetID()]) == 0) {
 				dispensableCount++
@@ -189,6 +194,9 @@ func checkSubnet6Dispensable(ctx *ReviewContext) (*Report, error) {
 	dispensableCount := int64(0)
 	for _, net := range sharedNetworks {
 		for _, subnet := range net.GetSubnets() {
+			if ctx.isSubnetSuppressed(subnet.GetPrefix()) {
+				continue
+			}
 			// Empty address pools.
 			if len(subnet.GetPools()) == 0 &&
 				// Empty delegated prefix pools.
@@ -226,6 +234,12 @@ func subnetDispensable(ctx *ReviewContext) (*Report, error) {
 func getDaemonHostsAndIndexBySubnet(ctx *ReviewContext) (hostCmds bool, dbHosts map[int64][]dbmodel.Host, err error) {
 	dbHosts = make(map[int64][]dbmodel.Host)
 	if _, _, present := ctx.subjectDaemon.KeaDaemon.Config.GetHookLibrary("libdhcp_host_cmds"); present {
+		if ctx.db == nil {
+			// The offline review has no access to the host reservations
+			// in the host database. They are treated as empty, so the
+			// checkers only take the configured reservations into account.
+			return present, dbHosts, nil
+		}
 		hosts, _, err := dbmodel.GetHostsByDaemonID(
 			ctx.db,
 			ctx.subjectDaemon.ID,
@@ -329,6 +343,9 @@ func checkDHCPv4ReservationsOutOfPool(ctx *ReviewContext) (*Report, error) {
 	oopSubnetsCount := int64(0)
 	for _, net := range sharedNetworks {
 		for _, subnet := range net.GetSubnets() {
+			if ctx.isSubnetSuppressed(subnet.GetPrefix()) {
+				continue
+			}
 			// Check if out-of-pool host reservation mode has been enabled at
 			// any level of inheritance from the subnet to the global scope.
 			// If that mode has been already enabled there is nothing to do for
@@ -435,6 +452,9 @@ func checkDHCPv6ReservationsOutOfPool(ctx *ReviewContext) (*Report, error) {
 	oopSubnetsCount := int64(0)
 	for _, net := range sharedNetworks {
 		for _, subnet := range net.GetSubnets() {
+			if ctx.isSubnetSuppressed(subnet.GetPrefix()) {
+				continue
+			}
 			// Check if out-of-pool host reservation mode has been enabled at
 			// any level of inheritance from the subnet to the global scope.
 			// If that mode has been already enabled there is nothing to do for
@@ -547,6 +567,11 @@ func subnetsOverlapping(ctx *ReviewContext) (*Report, error) {
 		subnets = append(subnets, sharedNetwork.GetSubnets()...)
 	}
 
+	// Skip the subnets which overlaps are suppressed.
+	subnets = slices.DeleteFunc(subnets, func(subnet keaconfig.Subnet) bool {
+		return ctx.isSubnetSuppressed(subnet.GetPrefix())
+	})
+
 	// Limits the overlaps count to avoid producing too huge review message.
 	maxOverlaps := 10
 	overlaps := findOverlaps(subnets, maxOverlaps)
@@ -677,7 +702,7 @@ func canonicalPrefixes(ctx *ReviewContext) (*Report, error) {
 
 	for _, subnet := range subnets {
 		prefix, ok := getCanonicalPrefix(subnet.GetPrefix())
-		if ok {
+		if ok || ctx.isSubnetSuppressed(subnet.GetPrefix()) {
 			continue
 		}
 
@@ -924,6 +949,9 @@ func addressPoolsExhaustedByReservations(ctx *ReviewContext) (*Report, error) {
 	}
 
 	for _, subnet := range subnets {
+		if ctx.isSubnetSuppressed(subnet.GetPrefix()) {
+			continue
+		}
 		// Parse all reservations in a subnet.
 		reservedAddresses := []*storkutil.ParsedIP{}
 
@@ -1059,6 +1087,9 @@ func delegatedPrefixPoolsExhaustedByReservations(ctx *ReviewContext) (*Report, e
 	}
 
 	for _, subnet := range subnets {
+		if ctx.isSubnetSuppressed(subnet.GetPrefix()) {
+			continue
+		}
 		// Parse all reservations in a subnet.
 		reservedPrefixes := []*storkutil.ParsedIP{}
 
@@ -1439,3 +1470,967 @@ func findSharedNetworkExceedingDelegatedPrefixLimit(sharedNetworks []keaconfig.S
 	}
 	return false, ""
 }
+
+// Maximum number of conflicts listed in the reports of the checkers
+// detecting conflicting host reservations.
+const maxHostConflicts = 10
+
+// Describes a value (e.g., a reserved address) shared by multiple host
+// reservations.
+type hostConflict struct {
+	value   string
+	hostIDs []int64
+}
+
+// Identifies a compared value within the configuration scope in which
+// it must be unique.
+type hostConflictKey struct {
+	scope string
+	value string
+}
+
+// Returns the configuration scope in which the addresses reserved for the
+// host must be unique. It is the shared network including the host's subnet,
+// the subnet or the global scope. The subnets with the same prefix configured
+// in different servers are represented by the same subnet in the database,
+// so they have the same scope.
+func getHostReservationScope(host *dbmodel.Host) string {
+	switch {
+	case host.Subnet != nil && host.Subnet.SharedNetworkID != 0:
+		return fmt.Sprintf("shared network %d", host.Subnet.SharedNetworkID)
+	case host.SubnetID != 0:
+		return fmt.Sprintf("subnet %d", host.SubnetID)
+	default:
+		return "global"
+	}
+}
+
+// Finds the values shared by multiple host reservations configured in the
+// daemons of the same type as the subject daemon (e.g., all DHCPv4 servers).
+// The hosts are the host reservations to compare. The getScope function
+// returns the configuration scope in which the values of the host must be
+// unique. It may be nil when the values must be unique across all scopes.
+// The getValues function returns the compared values of the host reservation
+// in a given daemon. It returns the conflicts involving the hosts served by
+// the subject daemon and the other daemons serving the conflicting hosts.
+func findHostConflicts(ctx *ReviewContext, hosts []dbmodel.Host, getScope func(host *dbmodel.Host) string, getValues func(host *dbmodel.Host, localHost *dbmodel.LocalHost) []string) (conflicts []hostConflict, daemons []*dbmodel.Daemon) {
+	// Index the hosts by the compared values. Preserve the order in which
+	// the values were found to make the reports deterministic.
+	var keys []hostConflictKey
+	hostsByKey := make(map[hostConflictKey][]*dbmodel.Host)
+	for i := range hosts {
+		host := &hosts[i]
+		if ctx.isHostSuppressed(host.ID) {
+			continue
+		}
+		var scope string
+		if getScope != nil {
+			scope = getScope(host)
+		}
+		for j := range host.LocalHosts {
+			localHost := &host.LocalHosts[j]
+			if localHost.Daemon == nil || localHost.Daemon.Name != ctx.subjectDaemon.Name {
+				continue
+			}
+			for _, value := range getValues(host, localHost) {
+				key := hostConflictKey{scope: scope, value: value}
+				indexed := hostsByKey[key]
+				if slices.Contains(indexed, host) {
+					continue
+				}
+				if len(indexed) == 0 {
+					keys = append(keys, key)
+				}
+				hostsByKey[key] = append(indexed, host)
+			}
+		}
+	}
+
+	referenced := map[int64]bool{ctx.subjectDaemon.ID: true}
+	for _, key := range keys {
+		conflicting := hostsByKey[key]
+		if len(conflicting) < 2 {
+			continue
+		}
+		// Other daemons report their own conflicts.
+		if !slices.ContainsFunc(conflicting, func(host *dbmodel.Host) bool {
+			return host.GetLocalHost(ctx.subjectDaemon.ID) != nil
+		}) {
+			continue
+		}
+		conflict := hostConflict{value: key.value}
+		for _, host := range conflicting {
+			conflict.hostIDs = append(conflict.hostIDs, host.ID)
+			for _, localHost := range host.LocalHosts {
+				if localHost.Daemon == nil || localHost.Daemon.Name != ctx.subjectDaemon.Name || referenced[localHost.DaemonID] {
+					continue
+				}
+				referenced[localHost.DaemonID] = true
+				daemons = append(daemons, localHost.Daemon)
+			}
+		}
+		conflicts = append(conflicts, conflict)
+	}
+	return conflicts, daemons
+}
+
+// Creates a report listing the conflicting host reservations. The noun
+// and its plural postfix describe the conflicting values (e.g., "reserved
+// address"). The consequence explains why the conflict is an issue.
+func createHostConflictsReport(ctx *ReviewContext, conflicts []hostConflict, daemons []*dbmodel.Daemon, noun, postfix, consequence string) (*Report, error) {
+	if len(conflicts) == 0 {
+		return nil, nil
+	}
+	var listed []string
+	for i, conflict := range conflicts {
+		if i == maxHostConflicts {
+			listed = append(listed, "...")
+			break
+		}
+		hostIDs := make([]string, len(conflict.hostIDs))
+		for j, hostID := range conflict.hostIDs {
+			hostIDs[j] = fmt.Sprintf("[%d]", hostID)
+		}
+		listed = append(listed, fmt.Sprintf("%d. %s is used by the hosts %s", i+1,
+			conflict.value, strings.Join(hostIDs, ", ")))
+	}
+	report := NewReport(ctx, fmt.Sprintf("Kea {daemon} configuration includes "+
+		"host reservations with %s also used in other host reservations "+
+		"configured in the servers of the same type. %s\n%s",
+		storkutil.FormatNoun(int64(len(conflicts)), noun, postfix),
+		consequence, strings.Join(listed, "; "))).
+		referencingDaemon(ctx.subjectDaemon)
+	for _, daemon := range daemons {
+		report = report.referencingDaemon(daemon)
+	}
+	return report.create()
+}
+
+// The checker detecting the same host identifiers (e.g., MAC addresses)
+// used in multiple host reservations, e.g., in different subnets or
+// on different servers.
+func hostIdentifiersDuplicated(ctx *ReviewContext) (*Report, error) {
+	if ctx.subjectDaemon.Name != dbmodel.DaemonNameDHCPv4 &&
+		ctx.subjectDaemon.Name != dbmodel.DaemonNameDHCPv6 {
+		return nil, errors.Errorf("unsupported daemon %s", ctx.subjectDaemon.Name)
+	}
+	hosts, err := dbmodel.GetHostsByDaemonName(ctx.db, ctx.subjectDaemon.Name)
+	if err != nil {
+		return nil, err
+	}
+	conflicts, daemons := findHostConflicts(ctx, hosts, nil, func(host *dbmodel.Host, localHost *dbmodel.LocalHost) (values []string) {
+		for _, identifier := range host.HostIdentifiers {
+			values = append(values, fmt.Sprintf("%s %s", identifier.Type, identifier.ToHex(":")))
+		}
+		return values
+	})
+	return createHostConflictsReport(ctx, conflicts, daemons, "host identifier", "s",
+		"The same DHCP client may be assigned different reservations depending "+
+			"on the subnet or server it contacts.")
+}
+
+// The checker detecting the same IP addresses or delegated prefixes
+// reserved in multiple host reservations, e.g., on different servers
+// serving the same shared network. The reservations are only compared
+// within the same subnet, shared network or the global scope. The global
+// reservations are compared with the global reservations of the servers
+// serving any of the same subnets.
+func reservedAddressesDuplicated(ctx *ReviewContext) (*Report, error) {
+	if ctx.subjectDaemon.Name != dbmodel.DaemonNameDHCPv4 &&
+		ctx.subjectDaemon.Name != dbmodel.DaemonNameDHCPv6 {
+		return nil, errors.Errorf("unsupported daemon %s", ctx.subjectDaemon.Name)
+	}
+	hosts, err := dbmodel.GetHostsInDaemonScopes(ctx.db, ctx.subjectDaemon.ID)
+	if err != nil {
+		return nil, err
+	}
+	conflicts, daemons := findHostConflicts(ctx, hosts, getHostReservationScope, func(host *dbmodel.Host, localHost *dbmodel.LocalHost) (values []string) {
+		for _, reservation := range localHost.IPReservations {
+			parsed := storkutil.ParseIP(reservation.Address)
+			if parsed != nil {
+				values = append(values, parsed.NetworkAddress)
+			}
+		}
+		return values
+	})
+	return createHostConflictsReport(ctx, conflicts, daemons, "reserved address", "es",
+		"Different DHCP clients may be assigned the same address or prefix, "+
+			"resulting in an address conflict.")
+}
+
+// The checker detecting the same hostnames reserved in multiple host
+// reservations.
+func reservedHostnamesDuplicated(ctx *ReviewContext) (*Report, error) {
+	if ctx.subjectDaemon.Name != dbmodel.DaemonNameDHCPv4 &&
+		ctx.subjectDaemon.Name != dbmodel.DaemonNameDHCPv6 {
+		return nil, errors.Errorf("unsupported daemon %s", ctx.subjectDaemon.Name)
+	}
+	hosts, err := dbmodel.GetHostsByDaemonName(ctx.db, ctx.subjectDaemon.Name)
+	if err != nil {
+		return nil, err
+	}
+	conflicts, daemons := findHostConflicts(ctx, hosts, nil, func(host *dbmodel.Host, localHost *dbmodel.LocalHost) []string {
+		hostname := strings.ToLower(strings.TrimSuffix(localHost.Hostname, "."))
+		if len(hostname) == 0 {
+			return nil
+		}
+		return []string{hostname}
+	})
+	return createHostConflictsReport(ctx, conflicts, daemons, "reserved hostname", "s",
+		"Different DHCP clients may register the same name in DNS.")
+}
+
+// Maximum number of configuration scopes listed in the report of the
+// checker verifying the lease lifetimes and timers.
+const maxLifetimeIssues = 10
+
+// Default valid lifetime used by Kea when it is not specified at any
+// configuration level.
+const defaultValidLifetime int64 = 7200
+
+// Default preferred lifetime used by the Kea DHCPv6 server when it is not
+// specified at any configuration level.
+const defaultPreferredLifetime int64 = 3600
+
+// Finds the inconsistencies between the effective lease lifetimes and
+// timers. It returns a list of descriptions of the found inconsistencies.
+func findLifetimeInconsistencies(params keaconfig.LifetimeParameters) (inconsistencies []string) {
+	compare := func(lowerName string, lower *int64, upperName string, upper *int64) {
+		if lower != nil && upper != nil && *lower > *upper {
+			inconsistencies = append(inconsistencies, fmt.Sprintf("%s (%d) is greater than %s (%d)", lowerName, *lower, upperName, *upper))
+		}
+	}
+	compare("renew-timer", params.RenewTimer, "rebind-timer", params.RebindTimer)
+	if params.RebindTimer != nil {
+		compare("rebind-timer", params.RebindTimer, "valid-lifetime", params.ValidLifetime)
+	} else {
+		compare("renew-timer", params.RenewTimer, "valid-lifetime", params.ValidLifetime)
+	}
+	compare("preferred-lifetime", params.PreferredLifetime, "valid-lifetime", params.ValidLifetime)
+	compare("min-valid-lifetime", params.MinValidLifetime, "max-valid-lifetime", params.MaxValidLifetime)
+	compare("min-preferred-lifetime", params.MinPreferredLifetime, "max-preferred-lifetime", params.MaxPreferredLifetime)
+	return
+}
+
+// The checker verifying that the effective lease lifetimes and timers are
+// consistent at the global, shared network and subnet levels. The renew
+// timer must not exceed the rebind timer, the timers must not exceed the
+// valid lifetime, the preferred lifetime must not exceed the valid lifetime,
+// and the minimum lifetimes must not exceed the maximum lifetimes. The
+// shared networks and subnets are only verified when they specify any of
+// the lifetime parameters. Otherwise, they inherit the values verified at
+// the higher level.
+func lifetimesAndTimersInconsistent(ctx *ReviewContext) (*Report, error) {
+	if ctx.subjectDaemon.Name != dbmodel.DaemonNameDHCPv4 &&
+		ctx.subjectDaemon.Name != dbmodel.DaemonNameDHCPv6 {
+		return nil, errors.Errorf("unsupported daemon %s", ctx.subjectDaemon.Name)
+	}
+	config := ctx.subjectDaemon.KeaDaemon.Config
+
+	// The values used by Kea when they are not specified at any level.
+	defaults := keaconfig.LifetimeParameters{
+		ValidLifetimeParameters: keaconfig.ValidLifetimeParameters{
+			ValidLifetime: storkutil.Ptr(defaultValidLifetime),
+		},
+	}
+	if config.IsDHCPv6() {
+		defaults.PreferredLifetime = storkutil.Ptr(defaultPreferredLifetime)
+	}
+
+	var issues []string
+	// Verifies the scope and appends the found inconsistencies to the
+	// issues. It returns false when the maximum number of issues has
+	// been reached.
+	verify := func(scope string, levels ...keaconfig.LifetimeParameters) bool {
+		inconsistencies := findLifetimeInconsistencies(keaconfig.GetEffectiveLifetimeParameters(append(levels, defaults)...))
+		if len(inconsistencies) > 0 {
+			issues = append(issues, fmt.Sprintf("%d. %s: %s", len(issues)+1, scope, strings.Join(inconsistencies, ", ")))
+		}
+		return len(issues) < maxLifetimeIssues
+	}
+	verifySubnets := func(subnets []keaconfig.Subnet, levels ...keaconfig.LifetimeParameters) bool {
+		for _, subnet := range subnets {
+			params := subnet.GetSubnetParameters().GetLifetimeParameters()
+			if !params.IsAnySpecified() || ctx.isSubnetSuppressed(subnet.GetPrefix()) {
+				continue
+			}
+			scope := fmt.Sprintf("subnet [%d] %s", subnet.GetID(), subnet.GetPrefix())
+			if !verify(scope, append([]keaconfig.LifetimeParameters{params}, levels...)...) {
+				return false
+			}
+		}
+		return true
+	}
+
+	global := config.GetLifetimeParameters()
+	proceed := verify("global configuration", global)
+	if proceed {
+		for _, sharedNetwork := range config.GetSharedNetworks(false) {
+			params := sharedNetwork.GetSharedNetworkParameters().GetLifetimeParameters()
+			if params.IsAnySpecified() {
+				if proceed = verify(fmt.Sprintf("shared network %s", sharedNetwork.GetName()), params, global); !proceed {
+					break
+				}
+			}
+			if proceed = verifySubnets(sharedNetwork.GetSubnets(), params, global); !proceed {
+				break
+			}
+		}
+	}
+	if proceed {
+		verifySubnets(config.GetSubnets(), global)
+	}
+	if len(issues) == 0 {
+		return nil, nil
+	}
+	atLeast := ""
+	if len(issues) == maxLifetimeIssues {
+		atLeast = " at least"
+	}
+	return NewReport(ctx, fmt.Sprintf("Kea {daemon} configuration contains%s %s with "+
+		"inconsistent lease lifetimes or timers. The renew-timer should be lower "+
+		"than the rebind-timer, the timers should be lower than the valid-lifetime, "+
+		"the preferred-lifetime should not exceed the valid-lifetime, and the "+
+		"minimum lifetimes should not exceed the maximum lifetimes. Otherwise, "+
+		"the clients may fail to renew their leases on time, or Kea may reject "+
+		"the configuration. The values shown are the effective values, including "+
+		"the ones inherited from the higher configuration levels.\n%s",
+		atLeast, storkutil.FormatNoun(int64(len(issues)), "configuration scope", "s"),
+		strings.Join(issues, "; "))).
+		referencingDaemon(ctx.subjectDaemon).
+		create()
+}
+
+// The maximum number of the invalid options listed in the report.
+const maxInvalidOptionData = 10
+
+// Returns a string identifying the host reservation in the report. It uses
+// the first specified host identifier.
+func getReservationLabel(reservation keaconfig.Reservation) string {
+	for _, identifier := range []struct {
+		name  string
+		value string
+	}{
+		{"hw-address", reservation.HWAddress},
+		{"duid", reservation.DUID},
+		{"client-id", reservation.ClientID},
+		{"circuit-id", reservation.CircuitID},
+		{"flex-id", reservation.FlexID},
+	} {
+		if identifier.value != "" {
+			return fmt.Sprintf("%s=%s", identifier.name, identifier.value)
+		}
+	}
+	return reservation.Hostname
+}
+
+// The checker validating the option data specified at all configuration
+// scopes (global, client classes, shared networks, subnets, pools and host
+// reservations in the configuration file) against the standard and custom
+// option definitions. It verifies that the option data values match the
+// option field types, that the options specified as comma separated values
+// have the definitions, and that the DHCPv6 option payloads do not exceed
+// the maximum length. The options lacking the csv-format parameter are
+// validated as comma separated values, like Kea does.
+func optionDataInvalid(ctx *ReviewContext) (*Report, error) {
+	if ctx.subjectDaemon.Name != dbmodel.DaemonNameDHCPv4 &&
+		ctx.subjectDaemon.Name != dbmodel.DaemonNameDHCPv6 {
+		return nil, errors.Errorf("unsupported daemon %s", ctx.subjectDaemon.Name)
+	}
+	config := ctx.subjectDaemon.KeaDaemon.Config
+	universe := storkutil.IPv4
+	if config.IsDHCPv6() {
+		universe = storkutil.IPv6
+	}
+	optionDefs := config.GetOptionDefs()
+
+	var issues []string
+	// Validates the options and appends the found issues. It returns false
+	// when the maximum number of issues has been reached.
+	verify := func(scope string, options []keaconfig.SingleOptionData) bool {
+		for _, option := range options {
+			if err := keaconfig.ValidateSingleOptionData(option, universe, optionDefs); err != nil {
+				issues = append(issues, fmt.Sprintf("%d. %s: %s", len(issues)+1, scope, err))
+				if len(issues) == maxInvalidOptionData {
+					return false
+				}
+			}
+		}
+		return true
+	}
+	verifyReservations := func(scope string, reservations []keaconfig.Reservation) bool {
+		for _, reservation := range reservations {
+			if !verify(fmt.Sprintf("host reservation %s in %s", getReservationLabel(reservation), scope), reservation.OptionData) {
+				return false
+			}
+		}
+		return true
+	}
+	verifySubnets := func(subnets []keaconfig.Subnet) bool {
+		for _, subnet := range subnets {
+			if ctx.isSubnetSuppressed(subnet.GetPrefix()) {
+				continue
+			}
+			scope := fmt.Sprintf("subnet [%d] %s", subnet.GetID(), subnet.GetPrefix())
+			if !verify(scope, subnet.GetDHCPOptions()) {
+				return false
+			}
+			for _, pool := range subnet.GetPools() {
+				if !verify(fmt.Sprintf("pool %s in %s", pool.Pool, scope), pool.OptionData) {
+					return false
+				}
+			}
+			for _, pdPool := range subnet.GetPDPools() {
+				if !verify(fmt.Sprintf("prefix delegation pool %s in %s", pdPool.GetCanonicalPrefix(), scope), pdPool.OptionData) {
+					return false
+				}
+			}
+			if !verifyReservations(scope, subnet.GetReservations()) {
+				return false
+			}
+		}
+		return true
+	}
+
+	proceed := verify("global configuration", config.GetDHCPOptions()) &&
+		verifyReservations("global configuration", config.GetReservations())
+	for _, clientClass := range config.GetClientClasses() {
+		if !proceed {
+			break
+		}
+		proceed = verify(fmt.Sprintf("client class %s", clientClass.Name), clientClass.OptionData)
+	}
+	for _, sharedNetwork := range config.GetSharedNetworks(false) {
+		if !proceed {
+			break
+		}
+		proceed = verify(fmt.Sprintf("shared network %s", sharedNetwork.GetName()), sharedNetwork.GetDHCPOptions()) &&
+			verifySubnets(sharedNetwork.GetSubnets())
+	}
+	if proceed {
+		verifySubnets(config.GetSubnets())
+	}
+	if len(issues) == 0 {
+		return nil, nil
+	}
+	atLeast := ""
+	if len(issues) == maxInvalidOptionData {
+		atLeast = " at least"
+	}
+	return NewReport(ctx, fmt.Sprintf("Kea {daemon} configuration contains%s %s. "+
+		"The option data were validated against the standard option definitions "+
+		"and the option definitions specified in the option-def list. Kea may "+
+		"reject such a configuration or send malformed options to the clients.\n%s",
+		atLeast, storkutil.FormatNoun(int64(len(issues)), "invalid option", "s"),
+		strings.Join(issues, "; "))).
+		referencingDaemon(ctx.subjectDaemon).
+		create()
+}
+
+// The maximum number of the divergences from a single HA partner listed
+// in the report.
+const maxHAPeerDivergences = 10
+
+// Returns the configuration of the HA relationship including the peer with
+// the specified name or nil if such relationship does not exist.
+func findHARelationship(config *keaconfig.Config, peerName string) *keaconfig.HA {
+	_, haConfig, ok := config.GetHookLibraries().GetHAHookLibrary()
+	if !ok {
+		return nil
+	}
+	relationships := haConfig.GetAllRelationships()
+	for i := range relationships {
+		for _, peer := range relationships[i].Peers {
+			if peer.Name != nil && *peer.Name == peerName {
+				return &relationships[i]
+			}
+		}
+	}
+	return nil
+}
+
+// Returns the valid peers of the HA relationship indexed by name.
+func indexHAPeers(relationship *keaconfig.HA) map[string]keaconfig.Peer {
+	peers := make(map[string]keaconfig.Peer)
+	for _, peer := range relationship.Peers {
+		if peer.IsValid() {
+			peers[*peer.Name] = peer
+		}
+	}
+	return peers
+}
+
+// Returns sorted union of the keys of two maps.
+func getSortedKeysUnion[V1, V2 any](m1 map[string]V1, m2 map[string]V2) []string {
+	var keys []string
+	for key := range m1 {
+		keys = append(keys, key)
+	}
+	for key := range m2 {
+		if _, ok := m1[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Compares the HA relationship configurations of the subject daemon and its
+// partner. It verifies that the peers lists are mutually consistent, i.e.,
+// that both servers specify the same peers with the same URLs and roles, and
+// that the servers use different names.
+func compareHARelationships(subject, partner *keaconfig.HA) (divergences []string) {
+	if subject.ThisServerName != nil && partner.ThisServerName != nil && *subject.ThisServerName == *partner.ThisServerName {
+		divergences = append(divergences, fmt.Sprintf("both servers use the same this-server-name %s", *subject.ThisServerName))
+	}
+	if subject.Mode != nil && partner.Mode != nil && *subject.Mode != *partner.Mode {
+		divergences = append(divergences, fmt.Sprintf("HA mode %s differs from the partner's HA mode %s", *subject.Mode, *partner.Mode))
+	}
+	subjectPeers := indexHAPeers(subject)
+	partnerPeers := indexHAPeers(partner)
+	for _, name := range getSortedKeysUnion(subjectPeers, partnerPeers) {
+		subjectPeer, subjectOk := subjectPeers[name]
+		partnerPeer, partnerOk := partnerPeers[name]
+		switch {
+		case !partnerOk:
+			divergences = append(divergences, fmt.Sprintf("peer %s is missing in the partner's peers list", name))
+		case !subjectOk:
+			divergences = append(divergences, fmt.Sprintf("peer %s from the partner's peers list is missing", name))
+		default:
+			if *subjectPeer.URL != *partnerPeer.URL {
+				divergences = append(divergences, fmt.Sprintf("peer %s has URL %s but the partner uses URL %s", name, *subjectPeer.URL, *partnerPeer.URL))
+			}
+			if *subjectPeer.Role != *partnerPeer.Role {
+				divergences = append(divergences, fmt.Sprintf("peer %s has role %s but the partner uses role %s", name, *subjectPeer.Role, *partnerPeer.Role))
+			}
+		}
+	}
+	return
+}
+
+// Serializes the items to JSON and returns them sorted. It is used to
+// compare the lists of configuration items regardless of their order.
+func getSortedJSONs[T any](items []T) []string {
+	var serialized []string
+	for _, item := range items {
+		if data, err := json.Marshal(item); err == nil {
+			serialized = append(serialized, string(data))
+		}
+	}
+	sort.Strings(serialized)
+	return serialized
+}
+
+// Checks if two lists of configuration items contain the same items
+// regardless of their order.
+func areConfigItemsEqual[T any](items1, items2 []T) bool {
+	return slices.Equal(getSortedJSONs(items1), getSortedJSONs(items2))
+}
+
+// Subnet configuration with the name of the shared network it belongs to.
+type subnetWithSharedNetwork struct {
+	subnet        keaconfig.Subnet
+	sharedNetwork string
+}
+
+// Returns the subnets belonging to the HA relationship indexed by prefix.
+// The subnets can be associated with a particular relationship using the
+// ha-server-name parameter in the user context. Such subnets are excluded
+// when this parameter points to a server outside of the relationship.
+func indexHASubnets(config *keaconfig.Config, peers map[string]keaconfig.Peer) map[string]subnetWithSharedNetwork {
+	subnets := make(map[string]subnetWithSharedNetwork)
+	appendSubnets := func(sharedNetwork string, configSubnets []keaconfig.Subnet) {
+		for _, subnet := range configSubnets {
+			if serverName, ok := subnet.GetUserContext()["ha-server-name"].(string); ok {
+				if _, ok := peers[serverName]; !ok {
+					continue
+				}
+			}
+			prefix, err := subnet.GetCanonicalPrefix()
+			if err != nil {
+				prefix = subnet.GetPrefix()
+			}
+			subnets[prefix] = subnetWithSharedNetwork{subnet: subnet, sharedNetwork: sharedNetwork}
+		}
+	}
+	for _, sharedNetwork := range config.GetSharedNetworks(false) {
+		appendSubnets(sharedNetwork.GetName(), sharedNetwork.GetSubnets())
+	}
+	appendSubnets("", config.GetSubnets())
+	return subnets
+}
+
+// Returns the description of the shared network membership used in the
+// report.
+func describeSharedNetwork(sharedNetwork string) string {
+	if sharedNetwork == "" {
+		return "no shared network"
+	}
+	return fmt.Sprintf("shared network %s", sharedNetwork)
+}
+
+// Compares the subnets belonging to the HA relationship.
+func compareHASubnets(prefix string, subject, partner subnetWithSharedNetwork) (divergences []string) {
+	if subject.subnet.GetID() != partner.subnet.GetID() {
+		divergences = append(divergences, fmt.Sprintf("subnet %s has ID %d but the partner uses ID %d",
+			prefix, subject.subnet.GetID(), partner.subnet.GetID()))
+	}
+	if subject.sharedNetwork != partner.sharedNetwork {
+		divergences = append(divergences, fmt.Sprintf("subnet %s belongs to %s but the partner's subnet belongs to %s",
+			prefix, describeSharedNetwork(subject.sharedNetwork), describeSharedNetwork(partner.sharedNetwork)))
+	}
+	if !areConfigItemsEqual(subject.subnet.GetPools(), partner.subnet.GetPools()) {
+		divergences = append(divergences, fmt.Sprintf("address pools of subnet %s differ", prefix))
+	}
+	if !areConfigItemsEqual(subject.subnet.GetPDPools(), partner.subnet.GetPDPools()) {
+		divergences = append(divergences, fmt.Sprintf("prefix delegation pools of subnet %s differ", prefix))
+	}
+	if !areConfigItemsEqual(subject.subnet.GetDHCPOptions(), partner.subnet.GetDHCPOptions()) {
+		divergences = append(divergences, fmt.Sprintf("option data of subnet %s differ", prefix))
+	}
+	if !areConfigItemsEqual(subject.subnet.GetReservations(), partner.subnet.GetReservations()) {
+		divergences = append(divergences, fmt.Sprintf("host reservations of subnet %s differ", prefix))
+	}
+	return
+}
+
+// Compares the DHCP configurations of the HA partners. It compares the
+// global option data and host reservations, the shared networks, and the
+// subnets belonging to the HA relationship.
+func compareHAConfigs(subject, partner *keaconfig.Config, peers map[string]keaconfig.Peer) (divergences []string) {
+	if !areConfigItemsEqual(subject.GetDHCPOptions(), partner.GetDHCPOptions()) {
+		divergences = append(divergences, "global option data differ")
+	}
+	if !areConfigItemsEqual(subject.GetReservations(), partner.GetReservations()) {
+		divergences = append(divergences, "global host reservations differ")
+	}
+
+	subjectSubnets := indexHASubnets(subject, peers)
+	partnerSubnets := indexHASubnets(partner, peers)
+
+	// Shared networks are only compared when they include the subnets
+	// belonging to the relationship or no subnets at all.
+	indexSharedNetworks := func(config *keaconfig.Config, subnets map[string]subnetWithSharedNetwork) map[string]keaconfig.SharedNetwork {
+		sharedNetworks := make(map[string]keaconfig.SharedNetwork)
+		for _, sharedNetwork := range config.GetSharedNetworks(false) {
+			relevant := len(sharedNetwork.GetSubnets()) == 0
+			for _, subnet := range subnets {
+				if subnet.sharedNetwork == sharedNetwork.GetName() {
+					relevant = true
+					break
+				}
+			}
+			if relevant {
+				sharedNetworks[sharedNetwork.GetName()] = sharedNetwork
+			}
+		}
+		return sharedNetworks
+	}
+	subjectSharedNetworks := indexSharedNetworks(subject, subjectSubnets)
+	partnerSharedNetworks := indexSharedNetworks(partner, partnerSubnets)
+	for _, name := range getSortedKeysUnion(subjectSharedNetworks, partnerSharedNetworks) {
+		subjectSharedNetwork, subjectOk := subjectSharedNetworks[name]
+		partnerSharedNetwork, partnerOk := partnerSharedNetworks[name]
+		switch {
+		case !partnerOk:
+			divergences = append(divergences, fmt.Sprintf("shared network %s is missing in the partner's configuration", name))
+		case !subjectOk:
+			divergences = append(divergences, fmt.Sprintf("shared network %s from the partner's configuration is missing", name))
+		case !areConfigItemsEqual(subjectSharedNetwork.GetDHCPOptions(), partnerSharedNetwork.GetDHCPOptions()):
+			divergences = append(divergences, fmt.Sprintf("option data of shared network %s differ", name))
+		}
+	}
+
+	for _, prefix := range getSortedKeysUnion(subjectSubnets, partnerSubnets) {
+		subjectSubnet, subjectOk := subjectSubnets[prefix]
+		partnerSubnet, partnerOk := partnerSubnets[prefix]
+		switch {
+		case !partnerOk:
+			divergences = append(divergences, fmt.Sprintf("subnet %s is missing in the partner's configuration", prefix))
+		case !subjectOk:
+			divergences = append(divergences, fmt.Sprintf("subnet %s from the partner's configuration is missing", prefix))
+		default:
+			divergences = append(divergences, compareHASubnets(prefix, subjectSubnet, partnerSubnet)...)
+		}
+	}
+	return
+}
+
+// The checker verifying that the configurations of the HA partners are
+// consistent. It compares the subject daemon's configuration with the
+// configurations of other daemons belonging to the same HA services. It
+// reports the differences between the subnets, pools, shared networks,
+// option data and host reservations, and the inconsistencies between the
+// peers lists. The partners are referenced in the review, so their
+// configurations are reviewed again when the subject daemon's
+// configuration changes.
+func highAvailabilityPeersInconsistent(ctx *ReviewContext) (*Report, error) {
+	if ctx.subjectDaemon.Name != dbmodel.DaemonNameDHCPv4 &&
+		ctx.subjectDaemon.Name != dbmodel.DaemonNameDHCPv6 {
+		return nil, errors.Errorf("unsupported daemon %s", ctx.subjectDaemon.Name)
+	}
+	services, err := dbmodel.GetDetailedServicesByDaemonID(ctx.db, ctx.subjectDaemon.ID)
+	if err != nil {
+		return nil, err
+	}
+	return createHAPeersInconsistentReport(ctx, services)
+}
+
+// Compares the subject daemon's configuration with its HA partners in the
+// specified services and creates the report. It returns nil if no
+// divergences were found.
+func createHAPeersInconsistentReport(ctx *ReviewContext, services []dbmodel.Service) (*Report, error) {
+	config := ctx.subjectDaemon.KeaDaemon.Config
+	type partnerDivergences struct {
+		daemon      *dbmodel.Daemon
+		divergences []string
+	}
+	var partners []*partnerDivergences
+	for _, service := range services {
+		if service.HAService == nil {
+			continue
+		}
+		subjectRelationship := findHARelationship(config.Config, service.HAService.Relationship)
+		if subjectRelationship == nil {
+			continue
+		}
+		for _, daemon := range service.Daemons {
+			if daemon.ID == ctx.subjectDaemon.ID || daemon.KeaDaemon == nil || daemon.KeaDaemon.Config == nil {
+				continue
+			}
+			// Reference the partner, so its configuration review is
+			// repeated when the subject daemon's configuration changes.
+			if !slices.ContainsFunc(ctx.refDaemons, func(d *dbmodel.Daemon) bool { return d.ID == daemon.ID }) {
+				ctx.refDaemons = append(ctx.refDaemons, daemon)
+			}
+			var divergences []string
+			partnerRelationship := findHARelationship(daemon.KeaDaemon.Config.Config, service.HAService.Relationship)
+			if partnerRelationship == nil {
+				divergences = append(divergences, fmt.Sprintf("the partner lacks the HA relationship including the peer %s",
+					service.HAService.Relationship))
+			} else {
+				divergences = append(divergences, compareHARelationships(subjectRelationship, partnerRelationship)...)
+				divergences = append(divergences, compareHAConfigs(config.Config, daemon.KeaDaemon.Config.Config, indexHAPeers(subjectRelationship))...)
+			}
+			if len(divergences) == 0 {
+				continue
+			}
+			index := slices.IndexFunc(partners, func(p *partnerDivergences) bool { return p.daemon.ID == daemon.ID })
+			if index < 0 {
+				partners = append(partners, &partnerDivergences{daemon: daemon})
+				index = len(partners) - 1
+			}
+			partners[index].divergences = append(partners[index].divergences, divergences...)
+		}
+	}
+	if len(partners) == 0 {
+		return nil, nil
+	}
+	var details []string
+	for _, partner := range partners {
+		divergences := partner.divergences
+		if len(divergences) > maxHAPeerDivergences {
+			divergences = append(divergences[:maxHAPeerDivergences:maxHAPeerDivergences],
+				fmt.Sprintf("and %d more", len(partner.divergences)-maxHAPeerDivergences))
+		}
+		details = append(details, fmt.Sprintf("Differences with {daemon}: %s.", strings.Join(divergences, "; ")))
+	}
+	report := NewReport(ctx, fmt.Sprintf("Kea {daemon} configuration is inconsistent with "+
+		"the configuration of %s. The HA partners should have "+
+		"the same subnets with the same IDs, pools, shared networks, "+
+		"option data and host reservations, and their peers lists should be "+
+		"consistent. Otherwise, the partners may allocate different leases "+
+		"to the same clients or fail to take over the service when one of "+
+		"them is down.\n%s",
+		storkutil.FormatNoun(int64(len(partners)), "HA partner", "s"),
+		strings.Join(details, "\n"))).
+		referencingDaemon(ctx.subjectDaemon)
+	for _, partner := range partners {
+		report = report.referencingDaemon(partner.daemon)
+	}
+	return report.create()
+}
+
+// The maximum number of the client class issues listed in the report.
+const maxClientClassIssues = 10
+
+// A reference to a client class in the require-client-classes,
+// evaluate-additional-classes or client-class parameter of a shared
+// network, subnet or pool.
+type clientClassReference struct {
+	scope     string
+	parameter string
+	name      string
+}
+
+// Returns the references to the client classes in the specified
+// configuration scope.
+func getClientClassReferences(scope string, params keaconfig.ClientClassParameters) (references []clientClassReference) {
+	if params.ClientClass != nil {
+		references = append(references, clientClassReference{scope, "client-class", *params.ClientClass})
+	}
+	for _, name := range params.RequireClientClasses {
+		references = append(references, clientClassReference{scope, "require-client-classes", name})
+	}
+	for _, name := range params.EvaluateAdditionalClasses {
+		references = append(references, clientClassReference{scope, "evaluate-additional-classes", name})
+	}
+	return
+}
+
+// Checks if the client class is evaluated only when it is required by
+// a shared network, subnet or pool.
+func isClientClassOnlyIfRequired(class keaconfig.ClientClass) bool {
+	return (class.OnlyIfRequired != nil && *class.OnlyIfRequired) ||
+		(class.OnlyInAdditionalList != nil && *class.OnlyInAdditionalList)
+}
+
+// The checker validating the client class definitions. It parses the test
+// and template-test expressions and reports the syntax errors, the
+// references to the undefined classes and to the classes defined later
+// (Kea evaluates the classes in order). The undefined classes are only
+// reported for the member() expressions and the required classes. The
+// classes selected with client-class may be assigned by the host
+// reservations or the hooks libraries. It also reports the misuse of the
+// only-if-required (only-in-additional-list) classes, i.e., the classes
+// that are never required, the classes required without being marked as
+// only-if-required, and the references to the only-if-required classes
+// from the regular classes that are always evaluated before them.
+func clientClassesInvalid(ctx *ReviewContext) (*Report, error) {
+	if ctx.subjectDaemon.Name != dbmodel.DaemonNameDHCPv4 &&
+		ctx.subjectDaemon.Name != dbmodel.DaemonNameDHCPv6 {
+		return nil, errors.Errorf("unsupported daemon %s", ctx.subjectDaemon.Name)
+	}
+	config := ctx.subjectDaemon.KeaDaemon.Config
+	classes := config.GetClientClasses()
+
+	// Collect the references to the classes from the shared networks,
+	// subnets and pools.
+	var references []clientClassReference
+	collectSubnetReferences := func(subnets []keaconfig.Subnet) {
+		for _, subnet := range subnets {
+			scope := fmt.Sprintf("subnet [%d] %s", subnet.GetID(), subnet.GetPrefix())
+			references = append(references, getClientClassReferences(scope, subnet.GetSubnetParameters().ClientClassParameters)...)
+			for _, pool := range subnet.GetPools() {
+				references = append(references, getClientClassReferences(fmt.Sprintf("pool %s in %s", pool.Pool, scope), pool.ClientClassParameters)...)
+			}
+			for _, pdPool := range subnet.GetPDPools() {
+				references = append(references, getClientClassReferences(fmt.Sprintf("prefix delegation pool %s in %s", pdPool.GetCanonicalPrefix(), scope), pdPool.ClientClassParameters)...)
+			}
+		}
+	}
+	for _, sharedNetwork := range config.GetSharedNetworks(false) {
+		references = append(references, getClientClassReferences(fmt.Sprintf("shared network %s", sharedNetwork.GetName()),
+			sharedNetwork.GetSharedNetworkParameters().ClientClassParameters)...)
+		collectSubnetReferences(sharedNetwork.GetSubnets())
+	}
+	collectSubnetReferences(config.GetSubnets())
+
+	definedAt := make(map[string]int)
+	for i, class := range classes {
+		if _, ok := definedAt[class.Name]; !ok {
+			definedAt[class.Name] = i
+		}
+	}
+	required := make(map[string]bool)
+	for _, reference := range references {
+		if reference.parameter != "client-class" {
+			required[reference.name] = true
+		}
+	}
+
+	var issues []string
+	addIssue := func(format string, args ...any) bool {
+		issues = append(issues, fmt.Sprintf("%d. %s", len(issues)+1, fmt.Sprintf(format, args...)))
+		return len(issues) < maxClientClassIssues
+	}
+	// Verifies the test or template-test expression of the class.
+	verifyExpression := func(index int, parameter, text string, boolean bool) bool {
+		class := classes[index]
+		expression, err := keaconfig.ParseExpression(text)
+		if err != nil {
+			return addIssue("client class %s: %s: %s", class.Name, parameter, err)
+		}
+		if boolean != expression.IsBoolean() {
+			expected := "a string"
+			if boolean {
+				expected = "a boolean"
+			}
+			if !addIssue("client class %s: %s expression %s must evaluate to %s", class.Name, parameter, text, expected) {
+				return false
+			}
+		}
+		for _, name := range expression.GetReferencedClasses() {
+			if keaconfig.IsBuiltinClientClass(name) {
+				continue
+			}
+			referencedIndex, ok := definedAt[name]
+			switch {
+			case !ok:
+				if !addIssue("client class %s: %s references undefined class %s", class.Name, parameter, name) {
+					return false
+				}
+			case referencedIndex >= index:
+				if !addIssue("client class %s: %s references class %s which is not defined before it", class.Name, parameter, name) {
+					return false
+				}
+			case isClientClassOnlyIfRequired(classes[referencedIndex]) && !isClientClassOnlyIfRequired(class):
+				if !addIssue("client class %s: %s references only-if-required class %s which is evaluated after it, so the membership is always false",
+					class.Name, parameter, name) {
+					return false
+				}
+			}
+		}
+		return true
+	}
+
+	proceed := true
+	for i, class := range classes {
+		if proceed && class.Test != nil {
+			proceed = verifyExpression(i, "test", *class.Test, true)
+		}
+		if proceed && class.TemplateTest != nil {
+			proceed = verifyExpression(i, "template-test", *class.TemplateTest, false)
+		}
+		if proceed && isClientClassOnlyIfRequired(class) && !required[class.Name] {
+			proceed = addIssue("client class %s is only-if-required but it is not listed in any "+
+				"require-client-classes or evaluate-additional-classes, so it is never evaluated", class.Name)
+		}
+		if !proceed {
+			break
+		}
+	}
+	for _, reference := range references {
+		if !proceed {
+			break
+		}
+		if keaconfig.IsBuiltinClientClass(reference.name) {
+			continue
+		}
+		index, ok := definedAt[reference.name]
+		switch {
+		case !ok && reference.parameter == "client-class":
+			// The class may be assigned by the host reservations or
+			// the hooks libraries.
+			continue
+		case !ok:
+			proceed = addIssue("%s: %s references undefined class %s", reference.scope, reference.parameter, reference.name)
+		case reference.parameter != "client-class" && !isClientClassOnlyIfRequired(classes[index]):
+			proceed = addIssue("%s: %s references class %s which is not only-if-required", reference.scope, reference.parameter, reference.name)
+		}
+	}
+	if len(issues) == 0 {
+		return nil, nil
+	}
+	atLeast := ""
+	if len(issues) == maxClientClassIssues {
+		atLeast = " at least"
+	}
+	return NewReport(ctx, fmt.Sprintf("Kea {daemon} configuration contains%s %s "+
+		"in the client class definitions and references. Kea may reject such "+
+		"a configuration or assign the clients to wrong classes.\n%s",
+		atLeast, storkutil.FormatNoun(int64(len(issues)), "client class issue", "s"),
+		strings.Join(issues, "; "))).
+		referencingDaemon(ctx.subjectDaemon).
+		create()
+}
@@ -10,6 +10,7 @@ import (
 	keaconfig "isc.org/stork/appcfg/kea"
 	dbops "isc.org/stork/server/database"
 	dbmodel "isc.org/stork/server/database/model"
+	dbmodeltest "isc.org/stork/server/database/model/test"
 	dbtest "isc.org/stork/server/database/test"
 	storkutil "isc.org/stork/util"
 )
@@ -2351,6 +2352,61 @@ func TestSubnetsOverlappingForMissingSubnetNode(t *testing.T) {
 	require.Nil(t, report)
 }
 
+// Test that the overlaps of the suppressed subnets are not reported while
+// the overlaps of the other subnets are.
+func TestSubnetsOverlappingSuppressed(t *testing.T) {
+	// Arrange
+	daemon := dbmodel.NewKeaDaemon(dbmodel.DaemonNameDHCPv4, true)
+	daemon.ID = 42
+	_ = daemon.SetConfigFromJSON(`{
+        "Dhcp4": {
+            "subnet4": [
+                { "id": 1, "subnet": "10.0.0.0/8" },
+                { "id": 2, "subnet": "10.1.0.0/16" },
+                { "id": 3, "subnet": "192.168.0.0/16" },
+                { "id": 4, "subnet": "192.168.1.0/24" }
+            ]
+        }
+    }`)
+
+	ctx := newReviewContext(nil, daemon, Triggers{ManualRun}, func(i int64, err error) {})
+	ctx.checkerName = "overlapping_subnet"
+	ctx.suppressions = []dbmodel.ConfigReviewSuppression{
+		{
+			CheckerName: "overlapping_subnet",
+			ObjectType:  dbmodel.ConfigReviewSuppressionObjectSubnet,
+			ObjectKey:   "10.1.0.0/16",
+		},
+		// Suppression for another checker.
+		{
+			CheckerName: "canonical_prefix",
+			ObjectType:  dbmodel.ConfigReviewSuppressionObjectSubnet,
+			ObjectKey:   "192.168.1.0/24",
+		},
+	}
+
+	// Act
+	report, err := subnetsOverlapping(ctx)
+
+	// Assert
+	require.NoError(t, err)
+	require.NotNil(t, report)
+	require.NotNil(t, report.content)
+	require.Contains(t, *report.content, "Kea {daemon} configuration includes 1 overlapping subnet pair.")
+	require.Contains(t, *report.content, "1. [3] 192.168.0.0/16 is overlapped by [4] 192.168.1.0/24")
+	require.NotContains(t, *report.content, "10.1.0.0/16")
+
+	// Suppress the other overlapping subnet.
+	ctx.suppressions = append(ctx.suppressions, dbmodel.ConfigReviewSuppression{
+		CheckerName: "overlapping_subnet",
+		ObjectType:  dbmodel.ConfigReviewSuppressionObjectSubnet,
+		ObjectKey:   "192.168.0.0/16",
+	})
+	report, err = subnetsOverlapping(ctx)
+	require.NoError(t, err)
+	require.Nil(t, report)
+}
+
 // Test that shared networks are processed by the overlapping checker.
 func TestSubnetsOverlappingForSharedNetworks(t *testing.T) {
 	// Arrange
@@ -5047,3 +5103,1203 @@ func BenchmarkOverlapsBinaryPrefixesOnly(b *testing.B) {
 		_ = findOverlaps(subnets, maximumOverlaps)
 	}
 }
+
+// Creates three DHCPv4 servers and the host reservations in the database
+// for testing the checkers detecting conflicting host reservations. The
+// first two servers serve the same subnet. The first host is served by the
+// first server, the second host by the second server. These hosts belong
+// to the subnet and have the same identifier, reserved address and
+// hostname. The third host is served by the second server and doesn't
+// conflict with other hosts. It returns the daemons and the hosts.
+func createConflictingHostsInDatabase(t *testing.T, db *dbops.PgDB) ([]*dbmodel.Daemon, []dbmodel.Host) {
+	machine := &dbmodel.Machine{
+		Address:   "localhost",
+		AgentPort: 8080,
+	}
+	err := dbmodel.AddMachine(db, machine)
+	require.NoError(t, err)
+
+	var daemons []*dbmodel.Daemon
+	for i := 0; i < 3; i++ {
+		config, err := dbmodel.NewKeaConfigFromJSON(`{
+			"Dhcp4": { }
+		}`)
+		require.NoError(t, err)
+		app := &dbmodel.App{
+			MachineID: machine.ID,
+			Type:      dbmodel.AppTypeKea,
+			Name:      fmt.Sprintf("kea%d", i),
+			Daemons: []*dbmodel.Daemon{
+				{
+					Name:   dbmodel.DaemonNameDHCPv4,
+					Active: true,
+					KeaDaemon: &dbmodel.KeaDaemon{
+						Config: config,
+					},
+				},
+			},
+		}
+		_, err = dbmodel.AddApp(db, app)
+		require.NoError(t, err)
+		daemons = append(daemons, app.Daemons[0])
+	}
+
+	subnet := dbmodel.Subnet{
+		Prefix: "192.0.2.0/24",
+		LocalSubnets: []*dbmodel.LocalSubnet{
+			{
+				DaemonID:      daemons[0].ID,
+				LocalSubnetID: 1,
+			},
+			{
+				DaemonID:      daemons[1].ID,
+				LocalSubnetID: 1,
+			},
+		},
+	}
+	err = dbmodel.AddSubnet(db, &subnet)
+	require.NoError(t, err)
+	err = dbmodel.AddLocalSubnets(db, &subnet)
+	require.NoError(t, err)
+
+	hosts := []dbmodel.Host{
+		{
+			SubnetID: subnet.ID,
+			HostIdentifiers: []dbmodel.HostIdentifier{
+				{
+					Type:  "hw-address",
+					Value: []byte{1, 2, 3, 4, 5, 6},
+				},
+			},
+			LocalHosts: []dbmodel.LocalHost{
+				{
+					DaemonID:   daemons[0].ID,
+					DataSource: dbmodel.HostDataSourceConfig,
+					Hostname:   "host.example.org",
+					IPReservations: []dbmodel.IPReservation{
+						{
+							Address: "192.0.2.10",
+						},
+					},
+				},
+			},
+		},
+		{
+			SubnetID: subnet.ID,
+			HostIdentifiers: []dbmodel.HostIdentifier{
+				{
+					Type:  "hw-address",
+					Value: []byte{1, 2, 3, 4, 5, 6},
+				},
+			},
+			LocalHosts: []dbmodel.LocalHost{
+				{
+					DaemonID:   daemons[1].ID,
+					DataSource: dbmodel.HostDataSourceAPI,
+					Hostname:   "HOST.example.org.",
+					IPReservations: []dbmodel.IPReservation{
+						{
+							Address: "192.0.2.10/32",
+						},
+					},
+				},
+			},
+		},
+		{
+			SubnetID: subnet.ID,
+			HostIdentifiers: []dbmodel.HostIdentifier{
+				{
+					Type:  "hw-address",
+					Value: []byte{1, 2, 3, 4, 5, 7},
+				},
+			},
+			LocalHosts: []dbmodel.LocalHost{
+				{
+					DaemonID:   daemons[1].ID,
+					DataSource: dbmodel.HostDataSourceAPI,
+					Hostname:   "other.example.org",
+					IPReservations: []dbmodel.IPReservation{
+						{
+							Address: "192.0.2.11",
+						},
+					},
+				},
+			},
+		},
+	}
+	for i := range hosts {
+		err = dbmodel.AddHost(db, &hosts[i])
+		require.NoError(t, err)
+	}
+	return daemons, hosts
+}
+
+// Tests that the checkers detecting conflicting host reservations report
+// the hosts sharing the same identifiers, addresses and hostnames.
+func TestHostReservationsDuplicated(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	daemons, hosts := createConflictingHostsInDatabase(t, db)
+
+	checkers := map[string]func(*ReviewContext) (*Report, error){
+		"1 host identifier":   hostIdentifiersDuplicated,
+		"1 reserved address":  reservedAddressesDuplicated,
+		"1 reserved hostname": reservedHostnamesDuplicated,
+	}
+	for noun, checker := range checkers {
+		t.Run(noun, func(t *testing.T) {
+			// Both servers serving the conflicting hosts should report
+			// the conflict and reference the other server.
+			for i, other := range []int{1, 0} {
+				ctx := newReviewContext(db, daemons[i], Triggers{ManualRun}, nil)
+				report, err := checker(ctx)
+				require.NoError(t, err)
+				require.NotNil(t, report)
+				require.NotNil(t, report.content)
+				require.Contains(t, *report.content, fmt.Sprintf("with %s also used", noun))
+				require.Contains(t, *report.content, fmt.Sprintf("used by the hosts [%d], [%d]", hosts[0].ID, hosts[1].ID))
+				require.NotContains(t, *report.content, fmt.Sprintf("[%d]", hosts[2].ID))
+				require.Equal(t, []int64{daemons[i].ID, daemons[other].ID}, report.refDaemonIDs)
+			}
+
+			// The third server has no host reservations.
+			ctx := newReviewContext(db, daemons[2], Triggers{ManualRun}, nil)
+			report, err := checker(ctx)
+			require.NoError(t, err)
+			require.Nil(t, report)
+		})
+	}
+}
+
+// Tests that the checker detecting duplicated reserved addresses only
+// compares the reservations within the same subnet, shared network or
+// the global scope of the servers serving the same subnets.
+func TestReservedAddressesDuplicatedScopes(t *testing.T) {
+	for _, inSharedNetwork := range []bool{false, true} {
+		t.Run(fmt.Sprintf("shared network %t", inSharedNetwork), func(t *testing.T) {
+			db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+			defer teardown()
+
+			daemons, hosts := createConflictingHostsInDatabase(t, db)
+
+			var networkID int64
+			if inSharedNetwork {
+				network := &dbmodel.SharedNetwork{
+					Name:   "foo",
+					Family: 4,
+				}
+				err := dbmodel.AddSharedNetwork(db, network)
+				require.NoError(t, err)
+				networkID = network.ID
+			}
+
+			// The third server serves other subnets and has the reservations
+			// for the same address as the conflicting hosts in each of them.
+			var addedHosts []dbmodel.Host
+			for i, prefix := range []string{"192.0.2.0/25", "192.0.2.0/26"} {
+				subnet := dbmodel.Subnet{
+					Prefix:          prefix,
+					SharedNetworkID: networkID,
+					LocalSubnets: []*dbmodel.LocalSubnet{
+						{
+							DaemonID:      daemons[2].ID,
+							LocalSubnetID: int64(i + 2),
+						},
+					},
+				}
+				err := dbmodel.AddSubnet(db, &subnet)
+				require.NoError(t, err)
+				err = dbmodel.AddLocalSubnets(db, &subnet)
+				require.NoError(t, err)
+
+				host := dbmodel.Host{
+					SubnetID: subnet.ID,
+					LocalHosts: []dbmodel.LocalHost{
+						{
+							DaemonID:   daemons[2].ID,
+							DataSource: dbmodel.HostDataSourceConfig,
+							IPReservations: []dbmodel.IPReservation{
+								{
+									Address: "192.0.2.10",
+								},
+							},
+						},
+					},
+				}
+				err = dbmodel.AddHost(db, &host)
+				require.NoError(t, err)
+				addedHosts = append(addedHosts, host)
+			}
+
+			// The third server doesn't serve the subnet of the conflicting
+			// hosts, so its global reservations are not compared with them.
+			global := dbmodel.Host{
+				LocalHosts: []dbmodel.LocalHost{
+					{
+						DaemonID:   daemons[0].ID,
+						DataSource: dbmodel.HostDataSourceConfig,
+						IPReservations: []dbmodel.IPReservation{
+							{
+								Address: "192.0.2.20",
+							},
+						},
+					},
+				},
+			}
+			err := dbmodel.AddHost(db, &global)
+			require.NoError(t, err)
+			global = dbmodel.Host{
+				LocalHosts: []dbmodel.LocalHost{
+					{
+						DaemonID:   daemons[2].ID,
+						DataSource: dbmodel.HostDataSourceConfig,
+						IPReservations: []dbmodel.IPReservation{
+							{
+								Address: "192.0.2.20",
+							},
+						},
+					},
+				},
+			}
+			err = dbmodel.AddHost(db, &global)
+			require.NoError(t, err)
+
+			ctx := newReviewContext(db, daemons[2], Triggers{ManualRun}, nil)
+			report, err := reservedAddressesDuplicated(ctx)
+			require.NoError(t, err)
+			if inSharedNetwork {
+				// The reservations in the subnets belonging to the same
+				// shared network conflict.
+				require.NotNil(t, report)
+				require.Contains(t, *report.content, "with 1 reserved address also used")
+				require.Contains(t, *report.content, fmt.Sprintf("used by the hosts [%d], [%d]", addedHosts[0].ID, addedHosts[1].ID))
+				require.Equal(t, []int64{daemons[2].ID}, report.refDaemonIDs)
+			} else {
+				require.Nil(t, report)
+			}
+
+			// The reservations of the third server are not compared with
+			// the reservations in the other subnet.
+			ctx = newReviewContext(db, daemons[0], Triggers{ManualRun}, nil)
+			report, err = reservedAddressesDuplicated(ctx)
+			require.NoError(t, err)
+			require.NotNil(t, report)
+			require.Contains(t, *report.content, "with 1 reserved address also used")
+			require.Contains(t, *report.content, fmt.Sprintf("used by the hosts [%d], [%d]", hosts[0].ID, hosts[1].ID))
+			require.Equal(t, []int64{daemons[0].ID, daemons[1].ID}, report.refDaemonIDs)
+		})
+	}
+}
+
+// Tests that the checkers detecting conflicting host reservations skip
+// the suppressed hosts.
+func TestHostReservationsDuplicatedSuppressed(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	daemons, hosts := createConflictingHostsInDatabase(t, db)
+
+	ctx := newReviewContext(db, daemons[0], Triggers{ManualRun}, nil)
+	ctx.suppressions = []dbmodel.ConfigReviewSuppression{
+		{
+			CheckerName: "duplicated_host_identifiers",
+			ObjectType:  dbmodel.ConfigReviewSuppressionObjectHost,
+			ObjectKey:   fmt.Sprint(hosts[1].ID),
+		},
+	}
+
+	// The conflict with the suppressed host is not reported.
+	ctx.checkerName = "duplicated_host_identifiers"
+	report, err := hostIdentifiersDuplicated(ctx)
+	require.NoError(t, err)
+	require.Nil(t, report)
+
+	// The suppression doesn't apply to the other checkers.
+	ctx.checkerName = "duplicated_reserved_addresses"
+	report, err = reservedAddressesDuplicated(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, report)
+}
+
+// Tests that the checkers detecting conflicting host reservations don't
+// report the hosts served by the servers of a different type.
+func TestHostReservationsDuplicatedDifferentFamily(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	daemons, _ := createConflictingHostsInDatabase(t, db)
+	daemons[0].Name = dbmodel.DaemonNameDHCPv6
+
+	ctx := newReviewContext(db, daemons[0], Triggers{ManualRun}, nil)
+	report, err := hostIdentifiersDuplicated(ctx)
+	require.NoError(t, err)
+	require.Nil(t, report)
+
+	// Unsupported daemon.
+	daemons[0].Name = dbmodel.DaemonNameCA
+	_, err = reservedHostnamesDuplicated(ctx)
+	require.Error(t, err)
+}
+
+// Test that the checker does not report consistent lease lifetimes and
+// timers.
+func TestLifetimesAndTimersConsistent(t *testing.T) {
+	// Arrange
+	daemon := dbmodel.NewKeaDaemon(dbmodel.DaemonNameDHCPv4, true)
+	daemon.ID = 42
+	_ = daemon.SetConfigFromJSON(`{
+        "Dhcp4": {
+            "renew-timer": 900,
+            "rebind-timer": 1800,
+            "valid-lifetime": 3600,
+            "shared-networks": [
+                {
+                    "name": "foo",
+                    "valid-lifetime": 4000,
+                    "subnet4": [
+                        {
+                            "id": 1,
+                            "subnet": "192.0.2.0/24",
+                            "renew-timer": 1000
+                        }
+                    ]
+                }
+            ],
+            "subnet4": [
+                {
+                    "id": 2,
+                    "subnet": "192.0.3.0/24",
+                    "min-valid-lifetime": 2000,
+                    "max-valid-lifetime": 5000
+                }
+            ]
+        }
+    }`)
+
+	ctx := newReviewContext(nil, daemon, Triggers{ManualRun}, func(i int64, err error) {})
+
+	// Act
+	report, err := lifetimesAndTimersInconsistent(ctx)
+
+	// Assert
+	require.NoError(t, err)
+	require.Nil(t, report)
+}
+
+// Test that the checker reports inconsistent timers at the global level.
+func TestLifetimesAndTimersInconsistentGlobal(t *testing.T) {
+	// Arrange
+	daemon := dbmodel.NewKeaDaemon(dbmodel.DaemonNameDHCPv4, true)
+	daemon.ID = 42
+	_ = daemon.SetConfigFromJSON(`{
+        "Dhcp4": {
+            "renew-timer": 2000,
+            "rebind-timer": 1000
+        }
+    }`)
+
+	ctx := newReviewContext(nil, daemon, Triggers{ManualRun}, func(i int64, err error) {})
+
+	// Act
+	report, err := lifetimesAndTimersInconsistent(ctx)
+
+	// Assert
+	require.NoError(t, err)
+	require.NotNil(t, report)
+	require.EqualValues(t, 42, report.daemonID)
+	require.Contains(t, *report.content, "contains 1 configuration scope with inconsistent")
+	require.Contains(t, *report.content, "1. global configuration: renew-timer (2000) is greater than rebind-timer (1000)")
+}
+
+// Test that the checker takes into account the values inherited from the
+// shared network and global levels.
+func TestLifetimesAndTimersInconsistentInherited(t *testing.T) {
+	// Arrange
+	daemon := dbmodel.NewKeaDaemon(dbmodel.DaemonNameDHCPv4, true)
+	daemon.ID = 42
+	_ = daemon.SetConfigFromJSON(`{
+        "Dhcp4": {
+            "rebind-timer": 3000,
+            "shared-networks": [
+                {
+                    "name": "foo",
+                    "min-valid-lifetime": 5000,
+                    "max-valid-lifetime": 4000,
+                    "subnet4": [
+                        {
+                            "id": 1,
+                            "subnet": "192.0.2.0/24",
+                            "valid-lifetime": 2000
+                        }
+                    ]
+                }
+            ],
+            "subnet4": [
+                {
+                    "id": 2,
+                    "subnet": "192.0.3.0/24"
+                }
+            ]
+        }
+    }`)
+
+	ctx := newReviewContext(nil, daemon, Triggers{ManualRun}, func(i int64, err error) {})
+
+	// Act
+	report, err := lifetimesAndTimersInconsistent(ctx)
+
+	// Assert
+	require.NoError(t, err)
+	require.NotNil(t, report)
+	require.Contains(t, *report.content, "contains 2 configuration scopes with inconsistent")
+	require.Contains(t, *report.content, "1. shared network foo: min-valid-lifetime (5000) is greater than max-valid-lifetime (4000)")
+	require.Contains(t, *report.content, "2. subnet [1] 192.0.2.0/24: rebind-timer (3000) is greater than valid-lifetime (2000)")
+	require.NotContains(t, *report.content, "192.0.3.0/24")
+}
+
+// Test that the checker reports the preferred lifetime greater than the
+// valid lifetime in the DHCPv6 configuration.
+func TestLifetimesAndTimersInconsistentPreferredLifetime(t *testing.T) {
+	// Arrange
+	daemon := dbmodel.NewKeaDaemon(dbmodel.DaemonNameDHCPv6, true)
+	daemon.ID = 42
+	_ = daemon.SetConfigFromJSON(`{
+        "Dhcp6": {
+            "subnet6": [
+                {
+                    "id": 1,
+                    "subnet": "2001:db8:1::/64",
+                    "valid-lifetime": 3000,
+                    "preferred-lifetime": 4000
+                }
+            ]
+        }
+    }`)
+
+	ctx := newReviewContext(nil, daemon, Triggers{ManualRun}, func(i int64, err error) {})
+
+	// Act
+	report, err := lifetimesAndTimersInconsistent(ctx)
+
+	// Assert
+	require.NoError(t, err)
+	require.NotNil(t, report)
+	require.Contains(t, *report.content, "1. subnet [1] 2001:db8:1::/64: preferred-lifetime (4000) is greater than valid-lifetime (3000)")
+}
+
+// Test that the number of reported scopes is limited.
+func TestLifetimesAndTimersInconsistentLimit(t *testing.T) {
+	// Arrange
+	var subnets []string
+	for i := 1; i <= 15; i++ {
+		subnets = append(subnets, fmt.Sprintf(`{
+            "id": %d,
+            "subnet": "10.0.%d.0/24",
+            "renew-timer": 9000
+        }`, i, i))
+	}
+	daemon := dbmodel.NewKeaDaemon(dbmodel.DaemonNameDHCPv4, true)
+	daemon.ID = 42
+	_ = daemon.SetConfigFromJSON(fmt.Sprintf(`{
+        "Dhcp4": {
+            "subnet4": [ %s ]
+        }
+    }`, strings.Join(subnets, ",")))
+
+	ctx := newReviewContext(nil, daemon, Triggers{ManualRun}, func(i int64, err error) {})
+
+	// Act
+	report, err := lifetimesAndTimersInconsistent(ctx)
+
+	// Assert
+	require.NoError(t, err)
+	require.NotNil(t, report)
+	require.Contains(t, *report.content, "contains at least 10 configuration scopes")
+	require.Contains(t, *report.content, "10. subnet [10] 10.0.10.0/24")
+	require.NotContains(t, *report.content, "11. subnet")
+}
+
+// Test that the checker returns an error for an unsupported daemon.
+func TestLifetimesAndTimersInconsistentUnsupportedDaemon(t *testing.T) {
+	daemon := dbmodel.NewKeaDaemon(dbmodel.DaemonNameCA, true)
+	_ = daemon.SetConfigFromJSON(`{"Control-agent": {}}`)
+	ctx := newReviewContext(nil, daemon, Triggers{ManualRun}, func(i int64, err error) {})
+	report, err := lifetimesAndTimersInconsistent(ctx)
+	require.Error(t, err)
+	require.Nil(t, report)
+}
+
+// Test that the checker does not report valid option data.
+func TestOptionDataValid(t *testing.T) {
+	// Arrange
+	daemon := dbmodel.NewKeaDaemon(dbmodel.DaemonNameDHCPv4, true)
+	daemon.ID = 42
+	_ = daemon.SetConfigFromJSON(`{
+        "Dhcp4": {
+            "option-def": [
+                {
+                    "code": 222,
+                    "name": "foo",
+                    "type": "uint16"
+                }
+            ],
+            "option-data": [
+                {
+                    "name": "domain-name-servers",
+                    "csv-format": true,
+                    "data": "192.0.2.1, 192.0.2.2"
+                },
+                {
+                    "code": 222,
+                    "csv-format": true,
+                    "data": "1024"
+                }
+            ],
+            "subnet4": [
+                {
+                    "id": 1,
+                    "subnet": "192.0.2.0/24",
+                    "option-data": [
+                        {
+                            "code": 3,
+                            "csv-format": true,
+                            "data": "192.0.2.1"
+                        }
+                    ]
+                }
+            ]
+        }
+    }`)
+
+	ctx := newReviewContext(nil, daemon, Triggers{ManualRun}, func(i int64, err error) {})
+
+	// Act
+	report, err := optionDataInvalid(ctx)
+
+	// Assert
+	require.NoError(t, err)
+	require.Nil(t, report)
+}
+
+// Test that the checker reports invalid option data at different
+// configuration scopes.
+func TestOptionDataInvalid(t *testing.T) {
+	// Arrange
+	daemon := dbmodel.NewKeaDaemon(dbmodel.DaemonNameDHCPv4, true)
+	daemon.ID = 42
+	_ = daemon.SetConfigFromJSON(`{
+        "Dhcp4": {
+            "option-data": [
+                {
+                    "name": "domain-name-servers",
+                    "csv-format": true,
+                    "data": "192.0.2.1, foo"
+                }
+            ],
+            "client-classes": [
+                {
+                    "name": "bar",
+                    "option-data": [
+                        {
+                            "code": 222,
+                            "csv-format": true,
+                            "data": "1"
+                        }
+                    ]
+                }
+            ],
+            "shared-networks": [
+                {
+                    "name": "baz",
+                    "subnet4": [
+                        {
+                            "id": 1,
+                            "subnet": "192.0.2.0/24",
+                            "pools": [
+                                {
+                                    "pool": "192.0.2.10-192.0.2.20",
+                                    "option-data": [
+                                        {
+                                            "code": 15,
+                                            "csv-format": false,
+                                            "data": "xyz"
+                                        }
+                                    ]
+                                }
+                            ],
+                            "reservations": [
+                                {
+                                    "hw-address": "01:02:03:04:05:06",
+                                    "option-data": [
+                                        {
+                                            "code": 1,
+                                            "csv-format": true,
+                                            "data": "255.255.255.0, 255.255.0.0"
+                                        }
+                                    ]
+                                }
+                            ]
+                        }
+                    ]
+                }
+            ],
+            "subnet4": [
+                {
+                    "id": 2,
+                    "subnet": "192.0.3.0/24",
+                    "option-data": [
+                        {
+                            "name": "domain-name",
+                            "data": "` + strings.Repeat("a", 256) + `"
+                        },
+                        {
+                            "code": 223,
+                            "data": "0102"
+                        }
+                    ]
+                }
+            ]
+        }
+    }`)
+
+	ctx := newReviewContext(nil, daemon, Triggers{ManualRun}, func(i int64, err error) {})
+
+	// Act
+	report, err := optionDataInvalid(ctx)
+
+	// Assert
+	require.NoError(t, err)
+	require.NotNil(t, report)
+	require.EqualValues(t, 42, report.daemonID)
+	require.Contains(t, *report.content, "contains 5 invalid options")
+	require.Contains(t, *report.content, "1. global configuration: invalid value of option domain-name-servers: foo is neither an IP address nor prefix")
+	require.Contains(t, *report.content, "2. client class bar: option with code 222 in the dhcp4 option space has no definition")
+	require.Contains(t, *report.content, "3. pool 192.0.2.10-192.0.2.20 in subnet [1] 192.0.2.0/24: option data xyz is not a valid string of hexadecimal digits")
+	require.Contains(t, *report.content, "4. host reservation hw-address=01:02:03:04:05:06 in subnet [1] 192.0.2.0/24: too many values specified for option subnet-mask")
+	// The csv-format defaults to true. The long DHCPv4 options are valid.
+	require.Contains(t, *report.content, "5. subnet [2] 192.0.3.0/24: option with code 223 in the dhcp4 option space has no definition")
+}
+
+// Test that the checker validates the DHCPv6 options in the prefix
+// delegation pools and global host reservations.
+func TestOptionDataInvalidDHCPv6(t *testing.T) {
+	// Arrange
+	daemon := dbmodel.NewKeaDaemon(dbmodel.DaemonNameDHCPv6, true)
+	daemon.ID = 42
+	_ = daemon.SetConfigFromJSON(`{
+        "Dhcp6": {
+            "reservations": [
+                {
+                    "duid": "01:02:03:04",
+                    "option-data": [
+                        {
+                            "name": "dns-servers",
+                            "csv-format": true,
+                            "data": "192.0.2.1"
+                        }
+                    ]
+                }
+            ],
+            "subnet6": [
+                {
+                    "id": 1,
+                    "subnet": "2001:db8:1::/64",
+                    "pd-pools": [
+                        {
+                            "prefix": "3000::",
+                            "prefix-len": 48,
+                            "delegated-len": 64,
+                            "option-data": [
+                                {
+                                    "name": "foo",
+                                    "data": "01"
+                                }
+                            ]
+                        }
+                    ]
+                }
+            ]
+        }
+    }`)
+
+	ctx := newReviewContext(nil, daemon, Triggers{ManualRun}, func(i int64, err error) {})
+
+	// Act
+	report, err := optionDataInvalid(ctx)
+
+	// Assert
+	require.NoError(t, err)
+	require.NotNil(t, report)
+	require.Contains(t, *report.content, "contains 2 invalid options")
+	require.Contains(t, *report.content, "1. host reservation duid=01:02:03:04 in global configuration: invalid value of option dns-servers: 192.0.2.1 is not a valid IPv6 address option field value")
+	require.Contains(t, *report.content, "2. prefix delegation pool 3000::/48 in subnet [1] 2001:db8:1::/64: unknown option foo in the dhcp6 option space")
+}
+
+// Test that the number of reported invalid options is limited.
+func TestOptionDataInvalidLimit(t *testing.T) {
+	// Arrange
+	var options []string
+	for i := 0; i < 15; i++ {
+		options = append(options, `{
+            "code": 3,
+            "csv-format": true,
+            "data": "foo"
+        }`)
+	}
+	daemon := dbmodel.NewKeaDaemon(dbmodel.DaemonNameDHCPv4, true)
+	daemon.ID = 42
+	_ = daemon.SetConfigFromJSON(fmt.Sprintf(`{
+        "Dhcp4": {
+            "option-data": [ %s ]
+        }
+    }`, strings.Join(options, ",")))
+
+	ctx := newReviewContext(nil, daemon, Triggers{ManualRun}, func(i int64, err error) {})
+
+	// Act
+	report, err := optionDataInvalid(ctx)
+
+	// Assert
+	require.NoError(t, err)
+	require.NotNil(t, report)
+	require.Contains(t, *report.content, "contains at least 10 invalid options")
+	require.Contains(t, *report.content, "10. global configuration")
+	require.NotContains(t, *report.content, "11. global configuration")
+}
+
+// Test that the checker returns an error for an unsupported daemon.
+func TestOptionDataInvalidUnsupportedDaemon(t *testing.T) {
+	daemon := dbmodel.NewKeaDaemon(dbmodel.DaemonNameCA, true)
+	_ = daemon.SetConfigFromJSON(`{"Control-agent": {}}`)
+	ctx := newReviewContext(nil, daemon, Triggers{ManualRun}, func(i int64, err error) {})
+	report, err := optionDataInvalid(ctx)
+	require.Error(t, err)
+	require.Nil(t, report)
+}
+
+// Returns a DHCPv4 server configuration with the HA hook library configured
+// for the tests of the checker comparing the HA partners' configurations.
+func getHAPeersTestConfig(thisServerName, peers, subnets string) string {
+	if peers == "" {
+		peers = `
+            {
+                "name": "server1",
+                "url": "http://192.0.2.1:8001/",
+                "role": "primary"
+            },
+            {
+                "name": "server2",
+                "url": "http://192.0.2.2:8001/",
+                "role": "secondary"
+            }`
+	}
+	return fmt.Sprintf(`{
+        "Dhcp4": {
+            "hooks-libraries": [
+                {
+                    "library": "/usr/lib/kea/libdhcp_ha.so",
+                    "parameters": {
+                        "high-availability": [
+                            {
+                                "this-server-name": "%s",
+                                "mode": "load-balancing",
+                                "peers": [ %s ]
+                            }
+                        ]
+                    }
+                }
+            ],
+            "subnet4": [ %s ]
+        }
+    }`, thisServerName, peers, subnets)
+}
+
+// Creates a Kea DHCPv4 daemon with the specified configuration.
+func createHAPeersTestDaemon(t *testing.T, id int64, config string) *dbmodel.Daemon {
+	daemon := dbmodel.NewKeaDaemon(dbmodel.DaemonNameDHCPv4, true)
+	daemon.ID = id
+	require.NoError(t, daemon.SetConfigFromJSON(config))
+	return daemon
+}
+
+// Creates an HA service including the specified daemons.
+func createHAPeersTestService(daemons ...*dbmodel.Daemon) dbmodel.Service {
+	return dbmodel.Service{
+		BaseService: dbmodel.BaseService{
+			Daemons: daemons,
+		},
+		HAService: &dbmodel.BaseHAService{
+			HAType:       dbmodel.DaemonNameDHCPv4,
+			Relationship: "server1",
+		},
+	}
+}
+
+// Test that the checker does not report consistent configurations of the
+// HA partners but it references the partner.
+func TestHighAvailabilityPeersConsistent(t *testing.T) {
+	// Arrange
+	subnets := `
+        {
+            "id": 1,
+            "subnet": "192.0.2.0/24",
+            "pools": [ { "pool": "192.0.2.10-192.0.2.100" } ]
+        }`
+	subject := createHAPeersTestDaemon(t, 1, getHAPeersTestConfig("server1", "", subnets))
+	partner := createHAPeersTestDaemon(t, 2, getHAPeersTestConfig("server2", "", subnets))
+	ctx := newReviewContext(nil, subject, Triggers{ManualRun}, func(i int64, err error) {})
+
+	// Act
+	report, err := createHAPeersInconsistentReport(ctx, []dbmodel.Service{createHAPeersTestService(subject, partner)})
+
+	// Assert
+	require.NoError(t, err)
+	require.Nil(t, report)
+	require.Len(t, ctx.refDaemons, 1)
+	require.EqualValues(t, 2, ctx.refDaemons[0].ID)
+}
+
+// Test that the checker reports the differences between the subnets of
+// the HA partners.
+func TestHighAvailabilityPeersInconsistentSubnets(t *testing.T) {
+	// Arrange
+	subject := createHAPeersTestDaemon(t, 1, getHAPeersTestConfig("server1", "", `
+        {
+            "id": 1,
+            "subnet": "192.0.2.0/24",
+            "pools": [ { "pool": "192.0.2.10-192.0.2.100" } ],
+            "option-data": [ { "name": "routers", "data": "192.0.2.1" } ]
+        },
+        {
+            "id": 2,
+            "subnet": "192.0.3.0/24"
+        },
+        {
+            "id": 3,
+            "subnet": "192.0.4.0/24",
+            "reservations": [ { "hw-address": "01:02:03:04:05:06", "ip-address": "192.0.4.10" } ]
+        },
+        {
+            "id": 4,
+            "subnet": "192.0.5.0/24",
+            "user-context": { "ha-server-name": "server3" }
+        }`))
+	partner := createHAPeersTestDaemon(t, 2, getHAPeersTestConfig("server2", "", `
+        {
+            "id": 1,
+            "subnet": "192.0.2.0/24",
+            "pools": [ { "pool": "192.0.2.10-192.0.2.200" } ],
+            "option-data": [ { "name": "routers", "data": "192.0.2.2" } ]
+        },
+        {
+            "id": 5,
+            "subnet": "192.0.3.0/24"
+        },
+        {
+            "id": 3,
+            "subnet": "192.0.4.0/24"
+        },
+        {
+            "id": 6,
+            "subnet": "192.0.6.0/24"
+        }`))
+	ctx := newReviewContext(nil, subject, Triggers{ManualRun}, func(i int64, err error) {})
+
+	// Act
+	report, err := createHAPeersInconsistentReport(ctx, []dbmodel.Service{createHAPeersTestService(subject, partner)})
+
+	// Assert
+	require.NoError(t, err)
+	require.NotNil(t, report)
+	require.EqualValues(t, 1, report.daemonID)
+	require.Equal(t, []int64{1, 2}, report.refDaemonIDs)
+	require.Contains(t, *report.content, "Kea {daemon} configuration is inconsistent with the configuration of 1 HA partner.")
+	require.Contains(t, *report.content, "Differences with {daemon}: "+
+		"address pools of subnet 192.0.2.0/24 differ; "+
+		"option data of subnet 192.0.2.0/24 differ; "+
+		"subnet 192.0.3.0/24 has ID 2 but the partner uses ID 5; "+
+		"host reservations of subnet 192.0.4.0/24 differ; "+
+		"subnet 192.0.6.0/24 from the partner's configuration is missing.")
+	// The subnet associated with another relationship should be ignored.
+	require.NotContains(t, *report.content, "192.0.5.0/24")
+}
+
+// Test that the checker reports inconsistent peers lists.
+func TestHighAvailabilityPeersInconsistentPeers(t *testing.T) {
+	// Arrange
+	subject := createHAPeersTestDaemon(t, 1, getHAPeersTestConfig("server1", "", ""))
+	partner := createHAPeersTestDaemon(t, 2, getHAPeersTestConfig("server1", `
+        {
+            "name": "server1",
+            "url": "http://192.0.2.1:8001/",
+            "role": "primary"
+        },
+        {
+            "name": "server2",
+            "url": "http://192.0.2.22:8001/",
+            "role": "standby"
+        },
+        {
+            "name": "server3",
+            "url": "http://192.0.2.3:8001/",
+            "role": "backup"
+        }`, ""))
+	ctx := newReviewContext(nil, subject, Triggers{ManualRun}, func(i int64, err error) {})
+
+	// Act
+	report, err := createHAPeersInconsistentReport(ctx, []dbmodel.Service{createHAPeersTestService(subject, partner)})
+
+	// Assert
+	require.NoError(t, err)
+	require.NotNil(t, report)
+	require.Contains(t, *report.content, "Differences with {daemon}: "+
+		"both servers use the same this-server-name server1; "+
+		"peer server2 has URL http://192.0.2.2:8001/ but the partner uses URL http://192.0.2.22:8001/; "+
+		"peer server2 has role secondary but the partner uses role standby; "+
+		"peer server3 from the partner's peers list is missing.")
+}
+
+// Test that the checker reports the partner lacking the HA relationship,
+// and that the number of listed differences is limited.
+func TestHighAvailabilityPeersInconsistentMultiplePartners(t *testing.T) {
+	// Arrange
+	var subnets []string
+	for i := 1; i <= 12; i++ {
+		subnets = append(subnets, fmt.Sprintf(`{ "id": %d, "subnet": "10.0.%d.0/24" }`, i, i))
+	}
+	subject := createHAPeersTestDaemon(t, 1, getHAPeersTestConfig("server1", "", strings.Join(subnets, ",")))
+	partner1 := createHAPeersTestDaemon(t, 2, getHAPeersTestConfig("server2", "", ""))
+	partner2 := createHAPeersTestDaemon(t, 3, `{ "Dhcp4": { } }`)
+	ctx := newReviewContext(nil, subject, Triggers{ManualRun}, func(i int64, err error) {})
+
+	// Act
+	report, err := createHAPeersInconsistentReport(ctx, []dbmodel.Service{createHAPeersTestService(subject, partner1, partner2)})
+
+	// Assert
+	require.NoError(t, err)
+	require.NotNil(t, report)
+	require.Equal(t, []int64{1, 2, 3}, report.refDaemonIDs)
+	require.Contains(t, *report.content, "configuration of 2 HA partners")
+	require.Contains(t, *report.content, "subnet 10.0.7.0/24 is missing in the partner's configuration; and 2 more.")
+	require.Contains(t, *report.content, "Differences with {daemon}: the partner lacks the HA relationship including the peer server1.")
+	require.Len(t, ctx.refDaemons, 2)
+}
+
+// Test that the checker ignores the services which are not of the HA type.
+func TestHighAvailabilityPeersInconsistentNonHAService(t *testing.T) {
+	subject := createHAPeersTestDaemon(t, 1, getHAPeersTestConfig("server1", "", ""))
+	partner := createHAPeersTestDaemon(t, 2, `{ "Dhcp4": { } }`)
+	service := createHAPeersTestService(subject, partner)
+	service.HAService = nil
+	ctx := newReviewContext(nil, subject, Triggers{ManualRun}, func(i int64, err error) {})
+
+	report, err := createHAPeersInconsistentReport(ctx, []dbmodel.Service{service})
+	require.NoError(t, err)
+	require.Nil(t, report)
+	require.Empty(t, ctx.refDaemons)
+}
+
+// Test that the checker fetches the HA services from the database.
+func TestHighAvailabilityPeersInconsistentDatabase(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	server1, err := dbmodeltest.NewKeaDHCPv4Server(db)
+	require.NoError(t, err)
+	require.NoError(t, server1.Configure(getHAPeersTestConfig("server1", "", `{ "id": 1, "subnet": "192.0.2.0/24" }`)))
+	server2, err := dbmodeltest.NewKeaDHCPv4Server(db)
+	require.NoError(t, err)
+	require.NoError(t, server2.Configure(getHAPeersTestConfig("server2", "", `{ "id": 2, "subnet": "192.0.2.0/24" }`)))
+
+	daemon1, err := dbmodel.GetDaemonByID(db, server1.ID)
+	require.NoError(t, err)
+	daemon2, err := dbmodel.GetDaemonByID(db, server2.ID)
+	require.NoError(t, err)
+
+	service := createHAPeersTestService(daemon1, daemon2)
+	require.NoError(t, dbmodel.AddService(db, &service))
+
+	ctx := newReviewContext(db, daemon1, Triggers{ManualRun}, func(i int64, err error) {})
+	report, err := highAvailabilityPeersInconsistent(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, report)
+	require.Contains(t, *report.content, "subnet 192.0.2.0/24 has ID 1 but the partner uses ID 2")
+	require.Len(t, ctx.refDaemons, 1)
+	require.Equal(t, daemon2.ID, ctx.refDaemons[0].ID)
+}
+
+// Test that the checker reports the invalid expressions, the references
+// to the undefined classes and to the classes defined later, and the misuse
+// of the only-if-required classes.
+func TestClientClassesInvalid(t *testing.T) {
+	// Arrange
+	daemon := dbmodel.NewKeaDaemon(dbmodel.DaemonNameDHCPv4, true)
+	daemon.ID = 42
+	_ = daemon.SetConfigFromJSON(`{
+        "Dhcp4": {
+            "client-classes": [
+                {
+                    "name": "syntax",
+                    "test": "substring(option[61].hex,0,3 == 'foo'"
+                },
+                {
+                    "name": "string",
+                    "test": "option[61].hex"
+                },
+                {
+                    "name": "undefined",
+                    "test": "member('foo') and member('KNOWN')"
+                },
+                {
+                    "name": "ordering",
+                    "test": "member('later') or member('ordering')"
+                },
+                {
+                    "name": "required",
+                    "only-if-required": true
+                },
+                {
+                    "name": "depends-on-required",
+                    "test": "member('required')"
+                },
+                {
+                    "name": "never-required",
+                    "only-if-required": true,
+                    "test": "member('required')"
+                },
+                {
+                    "name": "later",
+                    "template-test": "member('required')"
+                }
+            ],
+            "subnet4": [
+                {
+                    "id": 1,
+                    "subnet": "192.0.2.0/24",
+                    "client-class": "bar",
+                    "require-client-classes": [ "required", "later" ],
+                    "pools": [
+                        {
+                            "pool": "192.0.2.10-192.0.2.20",
+                            "client-class": "VENDOR_CLASS_docsis3.0"
+                        }
+                    ]
+                }
+            ]
+        }
+    }`)
+
+	ctx := newReviewContext(nil, daemon, Triggers{ManualRun}, func(i int64, err error) {})
+
+	// Act
+	report, err := clientClassesInvalid(ctx)
+
+	// Assert
+	require.NoError(t, err)
+	require.NotNil(t, report)
+	require.EqualValues(t, 42, report.daemonID)
+	require.Contains(t, *report.content, "contains at least 10 client class issues")
+	require.Contains(t, *report.content, "1. client class syntax: test: failed to parse expression")
+	require.Contains(t, *report.content, "2. client class string: test expression option[61].hex must evaluate to a boolean")
+	require.Contains(t, *report.content, "3. client class undefined: test references undefined class foo")
+	require.Contains(t, *report.content, "4. client class ordering: test references class later which is not defined before it")
+	require.Contains(t, *report.content, "5. client class ordering: test references class ordering which is not defined before it")
+	require.Contains(t, *report.content, "6. client class depends-on-required: test references only-if-required class required which is evaluated after it")
+	require.Contains(t, *report.content, "7. client class never-required is only-if-required but it is not listed in any require-client-classes")
+	require.Contains(t, *report.content, "8. client class later: template-test expression member('required') must evaluate to a string")
+	require.Contains(t, *report.content, "9. client class later: template-test references only-if-required class required")
+	require.Contains(t, *report.content, "10. subnet [1] 192.0.2.0/24: require-client-classes references class later which is not only-if-required")
+	require.NotContains(t, *report.content, "11.")
+	// The class selected with client-class may be assigned by the host
+	// reservations or hooks.
+	require.NotContains(t, *report.content, "undefined class bar")
+}
+
+// Test that the checker reports the required classes which are not
+// only-if-required.
+func TestClientClassesInvalidRequiredNotOnlyIfRequired(t *testing.T) {
+	// Arrange
+	daemon := dbmodel.NewKeaDaemon(dbmodel.DaemonNameDHCPv6, true)
+	daemon.ID = 42
+	_ = daemon.SetConfigFromJSON(`{
+        "Dhcp6": {
+            "client-classes": [
+                {
+                    "name": "foo",
+                    "test": "option[1].exists"
+                }
+            ],
+            "shared-networks": [
+                {
+                    "name": "bar",
+                    "evaluate-additional-classes": [ "foo", "baz" ]
+                }
+            ]
+        }
+    }`)
+
+	ctx := newReviewContext(nil, daemon, Triggers{ManualRun}, func(i int64, err error) {})
+
+	// Act
+	report, err := clientClassesInvalid(ctx)
+
+	// Assert
+	require.NoError(t, err)
+	require.NotNil(t, report)
+	require.Contains(t, *report.content, "contains 2 client class issues")
+	require.Contains(t, *report.content, "1. shared network bar: evaluate-additional-classes references class foo which is not only-if-required")
+	require.Contains(t, *report.content, "2. shared network bar: evaluate-additional-classes references undefined class baz")
+}
+
+// Test that the checker doesn't report the valid client classes.
+func TestClientClassesValid(t *testing.T) {
+	// Arrange
+	daemon := dbmodel.NewKeaDaemon(dbmodel.DaemonNameDHCPv4, true)
+	daemon.ID = 42
+	_ = daemon.SetConfigFromJSON(`{
+        "Dhcp4": {
+            "client-classes": [
+                {
+                    "name": "foo",
+                    "test": "substring(option[61].hex,0,3) == 'foo'"
+                },
+                {
+                    "name": "bar",
+                    "test": "member('foo') and not member('UNKNOWN')"
+                },
+                {
+                    "name": "baz",
+                    "only-if-required": true,
+                    "test": "member('bar')"
+                },
+                {
+                    "name": "spawning",
+                    "template-test": "substring(option[61].hex,0,3)"
+                }
+            ],
+            "subnet4": [
+                {
+                    "id": 1,
+                    "subnet": "192.0.2.0/24",
+                    "client-class": "bar",
+                    "pools": [
+                        {
+                            "pool": "192.0.2.10-192.0.2.20",
+                            "require-client-classes": [ "baz" ]
+                        }
+                    ]
+                }
+            ]
+        }
+    }`)
+
+	ctx := newReviewContext(nil, daemon, Triggers{ManualRun}, func(i int64, err error) {})
+
+	// Act
+	report, err := clientClassesInvalid(ctx)
+
+	// Assert
+	require.NoError(t, err)
+	require.Nil(t, report)
+}
@@ -0,0 +1,216 @@
+package configreview
+
+import (
+	"slices"
+	"strings"
+
+	pkgerrors "github.com/pkg/errors"
+	dbmodel "isc.org/stork/server/database/model"
+)
+
+// Severity of the issues found by a checker.
+type Severity string
+
+// List of the supported severities.
+const (
+	SeverityInfo    Severity = "info"
+	SeverityWarning Severity = "warning"
+	SeverityError   Severity = "error"
+)
+
+// Severities ordered from the lowest to the highest.
+var severities = []Severity{SeverityInfo, SeverityWarning, SeverityError}
+
+// Parses the severity name.
+func ParseSeverity(text string) (Severity, error) {
+	severity := Severity(strings.ToLower(text))
+	if !slices.Contains(severities, severity) {
+		return "", pkgerrors.Errorf("unsupported severity %s; it can be one of 'info', 'warning', 'error'", text)
+	}
+	return severity, nil
+}
+
+// Checks if the severity is equal to or higher than the other severity.
+func (s Severity) IsAtLeast(other Severity) bool {
+	return slices.Index(severities, s) >= slices.Index(severities, other)
+}
+
+// Severities of the issues found by the default checkers. The checkers
+// suggesting improvements report the info issues. The checkers finding
+// the configurations rejected by the servers or causing the servers to
+// misbehave report the errors.
+var checkerSeverities = map[string]Severity{
+	"stat_cmds_presence":                            SeverityInfo,
+	"lease_cmds_presence":                           SeverityInfo,
+	"host_cmds_presence":                            SeverityInfo,
+	"dispensable_shared_network":                    SeverityInfo,
+	"dispensable_subnet":                            SeverityInfo,
+	"out_of_pool_reservation":                       SeverityInfo,
+	"overlapping_subnet":                            SeverityError,
+	"canonical_prefix":                              SeverityWarning,
+	"ha_mt_presence":                                SeverityInfo,
+	"ha_dedicated_ports":                            SeverityWarning,
+	"address_pools_exhausted_by_reservations":       SeverityWarning,
+	"pd_pools_exhausted_by_reservations":            SeverityWarning,
+	"subnet_cmds_and_cb_mutual_exclusion":           SeverityWarning,
+	"statistics_unavailable_due_to_number_overflow": SeverityInfo,
+	"duplicated_host_identifiers":                   SeverityError,
+	"duplicated_reserved_addresses":                 SeverityError,
+	"duplicated_reserved_hostnames":                 SeverityWarning,
+	"lease_lifetimes_and_timers":                    SeverityWarning,
+	"invalid_option_data":                           SeverityError,
+	"ha_peers_consistency":                          SeverityError,
+	"invalid_client_classes":                        SeverityError,
+	"agent_credentials_over_https":                  SeverityWarning,
+	"ca_control_sockets":                            SeverityWarning,
+	"bind9_open_recursion":                          SeverityError,
+	"bind9_allow_transfer_any":                      SeverityWarning,
+	"bind9_unsigned_transfers":                      SeverityWarning,
+	"bind9_notify_consistency":                      SeverityWarning,
+	"bind9_statistics_channels_exposure":            SeverityWarning,
+	"bind9_deprecated_options":                      SeverityInfo,
+}
+
+// Returns the severity of the issues found by the checker. The checkers
+// without the explicitly specified severity (e.g., provided by the hooks)
+// report warnings.
+func GetCheckerSeverity(checkerName string) Severity {
+	if severity, ok := checkerSeverities[checkerName]; ok {
+		return severity
+	}
+	return SeverityWarning
+}
+
+// The default checkers fetching the data from the database. They are not
+// run in the offline review.
+var databaseCheckers = []string{
+	"ha_dedicated_ports",
+	"duplicated_host_identifiers",
+	"duplicated_reserved_addresses",
+	"duplicated_reserved_hostnames",
+}
+
+// A result of running a single checker in the offline review. It holds
+// either the found issue or the checker error.
+type OfflineResult struct {
+	CheckerName string
+	Severity    Severity
+	// The reviewed daemon.
+	Daemon *dbmodel.Daemon
+	// The report content with the {daemon} placeholders.
+	Content string
+	// The daemons replacing the {daemon} placeholders in order.
+	RefDaemons []*dbmodel.Daemon
+	// The checker error. The content is empty if the error is set.
+	Err error
+}
+
+// Returns the report content with the {daemon} placeholders replaced with
+// the labels of the referenced daemons.
+func (r *OfflineResult) FormatContent(label func(daemon *dbmodel.Daemon) string) string {
+	content := r.Content
+	for _, daemon := range r.RefDaemons {
+		content = strings.Replace(content, "{daemon}", label(daemon), 1)
+	}
+	return content
+}
+
+// Returns the checker verifying the consistency of the HA partners'
+// configurations. The partners are looked up among the reviewed daemons
+// rather than fetched from the database. The partner has an HA
+// relationship including the subject daemon's this-server-name.
+func newOfflineHAPeersChecker(daemons []*dbmodel.Daemon) func(*ReviewContext) (*Report, error) {
+	return func(ctx *ReviewContext) (*Report, error) {
+		if ctx.subjectDaemon.Name != dbmodel.DaemonNameDHCPv4 &&
+			ctx.subjectDaemon.Name != dbmodel.DaemonNameDHCPv6 {
+			return nil, pkgerrors.Errorf("unsupported daemon %s", ctx.subjectDaemon.Name)
+		}
+		_, haConfig, ok := ctx.subjectDaemon.KeaDaemon.Config.GetHookLibraries().GetHAHookLibrary()
+		if !ok {
+			return nil, nil
+		}
+		var services []dbmodel.Service
+		for _, relationship := range haConfig.GetAllRelationships() {
+			if relationship.ThisServerName == nil {
+				continue
+			}
+			service := dbmodel.Service{
+				BaseService: dbmodel.BaseService{
+					Daemons: []*dbmodel.Daemon{ctx.subjectDaemon},
+				},
+				HAService: &dbmodel.BaseHAService{
+					Relationship: *relationship.ThisServerName,
+				},
+			}
+			for _, daemon := range daemons {
+				if daemon.ID != ctx.subjectDaemon.ID && daemon.Name == ctx.subjectDaemon.Name &&
+					daemon.KeaDaemon != nil && daemon.KeaDaemon.Config != nil &&
+					findHARelationship(daemon.KeaDaemon.Config.Config, *relationship.ThisServerName) != nil {
+					service.Daemons = append(service.Daemons, daemon)
+				}
+			}
+			services = append(services, service)
+		}
+		return createHAPeersInconsistentReport(ctx, services)
+	}
+}
+
+// Reviews the daemons' configurations without the database, e.g., in the
+// CI pipeline before deploying them. It runs all default checkers except
+// for the ones fetching the data from the database. The HA partners are
+// looked up among the reviewed daemons. The daemons must have unique,
+// non-zero IDs. The results are returned in the order of the daemons and
+// checkers. Only the results with the found issues or errors are returned.
+func ReviewOffline(daemons []*dbmodel.Daemon) []*OfflineResult {
+	dispatcher := NewDispatcher(nil).(*dispatcherImpl)
+	RegisterDefaultCheckers(dispatcher)
+	for selector := range dispatcher.groups {
+		for _, name := range databaseCheckers {
+			dispatcher.UnregisterChecker(selector, name)
+		}
+	}
+	if dispatcher.UnregisterChecker(KeaDHCPDaemon, "ha_peers_consistency") {
+		dispatcher.RegisterChecker(KeaDHCPDaemon, "ha_peers_consistency", GetDefaultTriggers(), newOfflineHAPeersChecker(daemons))
+	}
+
+	var results []*OfflineResult
+	for _, daemon := range daemons {
+		for _, selector := range getDispatchGroupSelectors(daemon.Name) {
+			group := dispatcher.getGroup(selector)
+			if group == nil {
+				continue
+			}
+			for _, checker := range group.checkers {
+				ctx := newReviewContext(nil, daemon, Triggers{ManualRun}, func(int64, error) {})
+				ctx.checkerName = checker.name
+				report, err := checker.checkFn(ctx)
+				result := &OfflineResult{
+					CheckerName: checker.name,
+					Severity:    GetCheckerSeverity(checker.name),
+					Daemon:      daemon,
+				}
+				switch {
+				case err != nil:
+					result.Err = err
+				case report == nil || !report.IsIssueFound():
+					continue
+				default:
+					result.Content = *report.content
+					for _, id := range report.refDaemonIDs {
+						index := slices.IndexFunc(daemons, func(d *dbmodel.Daemon) bool { return d.ID == id })
+						if index < 0 {
+							result.Err = pkgerrors.Errorf("report references unknown daemon %d", id)
+							break
+						}
+						result.RefDaemons = append(result.RefDaemons, daemons[index])
+					}
+					if result.Err != nil {
+						result.Content = ""
+					}
+				}
+				results = append(results, result)
+			}
+		}
+	}
+	return results
+}
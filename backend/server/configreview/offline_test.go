@@ -0,0 +1,185 @@
+package configreview
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	dbmodel "isc.org/stork/server/database/model"
+)
+
+// Test parsing the severities.
+func TestParseSeverity(t *testing.T) {
+	severity, err := ParseSeverity("info")
+	require.NoError(t, err)
+	require.Equal(t, SeverityInfo, severity)
+
+	severity, err = ParseSeverity("Warning")
+	require.NoError(t, err)
+	require.Equal(t, SeverityWarning, severity)
+
+	severity, err = ParseSeverity("ERROR")
+	require.NoError(t, err)
+	require.Equal(t, SeverityError, severity)
+
+	_, err = ParseSeverity("critical")
+	require.ErrorContains(t, err, "unsupported severity critical")
+}
+
+// Test comparing the severities.
+func TestSeverityIsAtLeast(t *testing.T) {
+	require.True(t, SeverityError.IsAtLeast(SeverityInfo))
+	require.True(t, SeverityError.IsAtLeast(SeverityError))
+	require.True(t, SeverityWarning.IsAtLeast(SeverityInfo))
+	require.False(t, SeverityInfo.IsAtLeast(SeverityWarning))
+	require.False(t, SeverityWarning.IsAtLeast(SeverityError))
+}
+
+// Test that all default checkers have explicitly specified severities and
+// that the other checkers report warnings.
+func TestGetCheckerSeverity(t *testing.T) {
+	dispatcher := NewDispatcher(nil).(*dispatcherImpl)
+	RegisterDefaultCheckers(dispatcher)
+	metadata, err := dispatcher.GetCheckersMetadata(nil)
+	require.NoError(t, err)
+	for _, checker := range metadata {
+		require.Contains(t, checkerSeverities, checker.Name)
+	}
+	require.Equal(t, SeverityInfo, GetCheckerSeverity("stat_cmds_presence"))
+	require.Equal(t, SeverityError, GetCheckerSeverity("overlapping_subnet"))
+	require.Equal(t, SeverityWarning, GetCheckerSeverity("subnet_naming"))
+}
+
+// Test that the offline review runs the checkers not requiring the
+// database and compares the configurations of the HA partners.
+func TestReviewOffline(t *testing.T) {
+	// Arrange
+	subject := createHAPeersTestDaemon(t, 1, getHAPeersTestConfig("server1", "", `
+        {
+            "id": 1,
+            "subnet": "192.0.2.0/24",
+            "pools": [ { "pool": "192.0.2.10-192.0.2.100" } ]
+        }`))
+	partner := createHAPeersTestDaemon(t, 2, getHAPeersTestConfig("server2", "", `
+        {
+            "id": 1,
+            "subnet": "192.0.2.0/24",
+            "pools": [ { "pool": "192.0.2.10-192.0.2.200" } ]
+        }`))
+	// The daemon with the same HA configuration but of another type is
+	// not the partner.
+	other := dbmodel.NewKeaDaemon(dbmodel.DaemonNameDHCPv6, true)
+	other.ID = 3
+	require.NoError(t, other.SetConfigFromJSON(`{
+        "Dhcp6": {
+            "hooks-libraries": [
+                {
+                    "library": "/usr/lib/kea/libdhcp_ha.so",
+                    "parameters": {
+                        "high-availability": [
+                            {
+                                "this-server-name": "server3",
+                                "mode": "hot-standby",
+                                "peers": [
+                                    { "name": "server1", "url": "http://192.0.2.1:8001/", "role": "primary" },
+                                    { "name": "server3", "url": "http://192.0.2.3:8001/", "role": "standby" }
+                                ]
+                            }
+                        ]
+                    }
+                }
+            ]
+        }
+    }`))
+
+	// Act
+	results := ReviewOffline([]*dbmodel.Daemon{subject, partner, other})
+
+	// Assert
+	checkers := make(map[int64][]string)
+	var haResults []*OfflineResult
+	for _, result := range results {
+		require.NoError(t, result.Err, result.CheckerName)
+		require.NotEmpty(t, result.Content)
+		checkers[result.Daemon.ID] = append(checkers[result.Daemon.ID], result.CheckerName)
+		if result.CheckerName == "ha_peers_consistency" {
+			haResults = append(haResults, result)
+		}
+	}
+	require.Contains(t, checkers[1], "stat_cmds_presence")
+	require.Contains(t, checkers[1], "ha_peers_consistency")
+	require.Contains(t, checkers[2], "ha_peers_consistency")
+	require.NotContains(t, checkers[3], "ha_peers_consistency")
+	for _, names := range checkers {
+		for _, name := range databaseCheckers {
+			require.NotContains(t, names, name)
+		}
+	}
+
+	require.Len(t, haResults, 2)
+	require.Equal(t, SeverityError, haResults[0].Severity)
+	require.EqualValues(t, 1, haResults[0].Daemon.ID)
+	require.Len(t, haResults[0].RefDaemons, 2)
+	content := haResults[0].FormatContent(func(daemon *dbmodel.Daemon) string {
+		return fmt.Sprintf("server-%d", daemon.ID)
+	})
+	require.Contains(t, content, "Kea server-1 configuration is inconsistent with the configuration of 1 HA partner.")
+	require.Contains(t, content, "Differences with server-2: address pools of subnet 192.0.2.0/24 differ.")
+}
+
+// Test that the checkers using the host reservations from the host database
+// treat the host database as empty in the offline review.
+func TestReviewOfflineHostCmds(t *testing.T) {
+	// Arrange
+	daemon4 := dbmodel.NewKeaDaemon(dbmodel.DaemonNameDHCPv4, true)
+	daemon4.ID = 1
+	require.NoError(t, daemon4.SetConfigFromJSON(`{
+        "Dhcp4": {
+            "hooks-libraries": [
+                { "library": "/usr/lib/kea/libdhcp_host_cmds.so" }
+            ],
+            "subnet4": [
+                {
+                    "id": 1,
+                    "subnet": "192.0.2.0/24"
+                }
+            ]
+        }
+    }`))
+	daemon6 := dbmodel.NewKeaDaemon(dbmodel.DaemonNameDHCPv6, true)
+	daemon6.ID = 2
+	require.NoError(t, daemon6.SetConfigFromJSON(`{
+        "Dhcp6": {
+            "hooks-libraries": [
+                { "library": "/usr/lib/kea/libdhcp_host_cmds.so" }
+            ],
+            "subnet6": [
+                {
+                    "id": 1,
+                    "subnet": "2001:db8:1::/64",
+                    "pd-pools": [
+                        {
+                            "prefix": "3000::",
+                            "prefix-len": 64,
+                            "delegated-len": 96
+                        }
+                    ]
+                }
+            ]
+        }
+    }`))
+
+	// Act
+	results := ReviewOffline([]*dbmodel.Daemon{daemon4, daemon6})
+
+	// Assert
+	checkers := make(map[int64][]string)
+	for _, result := range results {
+		require.NoError(t, result.Err, result.CheckerName)
+		checkers[result.Daemon.ID] = append(checkers[result.Daemon.ID], result.CheckerName)
+	}
+	// The subnet without pools and configured reservations is reported
+	// because there are no reservations in the host database.
+	require.Contains(t, checkers[1], "dispensable_subnet")
+	require.NotContains(t, checkers[2], "dispensable_subnet")
+}
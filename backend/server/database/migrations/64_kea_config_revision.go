@@ -0,0 +1,64 @@
+package dbmigs
+
+import "github.com/go-pg/migrations/v8"
+
+func init() {
+	migrations.MustRegisterTx(func(db migrations.DB) error {
+		_, err := db.Exec(`
+			-- Holds the history of the Kea daemons' configurations. A new revision
+			-- is inserted whenever the state puller fetches a configuration which
+			-- differs from the most recent revision of the daemon's configuration.
+			-- The user_id and operation columns are set when the configuration
+			-- change has been caused by a config update committed by the config
+			-- manager. They are null when the configuration has been changed outside
+			-- of Stork.
+			CREATE TABLE IF NOT EXISTS kea_config_revision (
+				id BIGSERIAL NOT NULL,
+				daemon_id BIGINT NOT NULL,
+				created_at TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT timezone('utc'::text, now()),
+				config JSONB NOT NULL,
+				config_hash TEXT,
+				user_id BIGINT,
+				operation TEXT,
+				CONSTRAINT kea_config_revision_pkey PRIMARY KEY (id),
+				CONSTRAINT kea_config_revision_daemon_id FOREIGN KEY (daemon_id)
+					REFERENCES daemon (id) MATCH SIMPLE
+					ON UPDATE CASCADE
+					ON DELETE CASCADE,
+				CONSTRAINT kea_config_revision_user_id FOREIGN KEY (user_id)
+					REFERENCES system_user (id) MATCH SIMPLE
+					ON UPDATE CASCADE
+					ON DELETE SET NULL
+			);
+			CREATE INDEX kea_config_revision_daemon_id_idx ON kea_config_revision(daemon_id);
+
+			-- Holds the information about the config updates committed by the
+			-- config manager which haven't been yet fetched by the state puller.
+			-- The state puller consumes this information when it inserts the
+			-- next configuration revision for the daemon. There is at most one
+			-- entry per daemon. It holds the most recent config update.
+			CREATE TABLE IF NOT EXISTS kea_config_revision_cause (
+				daemon_id BIGINT NOT NULL,
+				created_at TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT timezone('utc'::text, now()),
+				user_id BIGINT,
+				operation TEXT NOT NULL,
+				CONSTRAINT kea_config_revision_cause_pkey PRIMARY KEY (daemon_id),
+				CONSTRAINT kea_config_revision_cause_daemon_id FOREIGN KEY (daemon_id)
+					REFERENCES daemon (id) MATCH SIMPLE
+					ON UPDATE CASCADE
+					ON DELETE CASCADE,
+				CONSTRAINT kea_config_revision_cause_user_id FOREIGN KEY (user_id)
+					REFERENCES system_user (id) MATCH SIMPLE
+					ON UPDATE CASCADE
+					ON DELETE SET NULL
+			);
+		`)
+		return err
+	}, func(db migrations.DB) error {
+		_, err := db.Exec(`
+			DROP TABLE IF EXISTS kea_config_revision_cause;
+			DROP TABLE IF EXISTS kea_config_revision;
+		`)
+		return err
+	})
+}
@@ -0,0 +1,26 @@
+package dbmigs
+
+import "github.com/go-pg/migrations/v8"
+
+func init() {
+	migrations.MustRegisterTx(func(db migrations.DB) error {
+		_, err := db.Exec(`
+			-- Hash of the daemon's configuration file contents reported
+			-- by the agent, a hash of the running configuration when it
+			-- was last known to match the configuration file, and a flag
+			-- indicating that the running configuration differs from the
+			-- configuration file.
+			ALTER TABLE kea_daemon ADD COLUMN config_file_hash TEXT;
+			ALTER TABLE kea_daemon ADD COLUMN saved_config_hash TEXT;
+			ALTER TABLE kea_daemon ADD COLUMN unsaved_config_changes BOOLEAN NOT NULL DEFAULT FALSE;
+		`)
+		return err
+	}, func(db migrations.DB) error {
+		_, err := db.Exec(`
+			ALTER TABLE kea_daemon DROP COLUMN IF EXISTS unsaved_config_changes;
+			ALTER TABLE kea_daemon DROP COLUMN IF EXISTS saved_config_hash;
+			ALTER TABLE kea_daemon DROP COLUMN IF EXISTS config_file_hash;
+		`)
+		return err
+	})
+}
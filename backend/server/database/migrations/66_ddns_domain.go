@@ -0,0 +1,36 @@
+package dbmigs
+
+import "github.com/go-pg/migrations/v8"
+
+func init() {
+	migrations.MustRegisterTx(func(db migrations.DB) error {
+		_, err := db.Exec(`
+			-- Holds the forward and reverse DDNS domains configured in the
+			-- Kea D2 daemons. The domains are replaced whenever the daemon's
+			-- configuration is fetched. The DNS servers are stored as JSON
+			-- because they are always fetched together with the domain.
+			CREATE TABLE IF NOT EXISTS ddns_domain (
+				id BIGSERIAL NOT NULL,
+				daemon_id BIGINT NOT NULL,
+				direction TEXT NOT NULL,
+				name TEXT NOT NULL,
+				key_name TEXT,
+				dns_servers JSONB,
+				CONSTRAINT ddns_domain_pkey PRIMARY KEY (id),
+				CONSTRAINT ddns_domain_daemon_id_direction_name_unique UNIQUE (daemon_id, direction, name),
+				CONSTRAINT ddns_domain_direction_check CHECK (direction IN ('forward-ddns', 'reverse-ddns')),
+				CONSTRAINT ddns_domain_daemon_id FOREIGN KEY (daemon_id)
+					REFERENCES daemon (id) MATCH SIMPLE
+					ON UPDATE CASCADE
+					ON DELETE CASCADE
+			);
+			CREATE INDEX ddns_domain_daemon_id_idx ON ddns_domain(daemon_id);
+		`)
+		return err
+	}, func(db migrations.DB) error {
+		_, err := db.Exec(`
+			DROP TABLE IF EXISTS ddns_domain;
+		`)
+		return err
+	})
+}
@@ -0,0 +1,22 @@
+package dbmigs
+
+import "github.com/go-pg/migrations/v8"
+
+func init() {
+	migrations.MustRegisterTx(func(db migrations.DB) error {
+		_, err := db.Exec(`
+			-- Preprocessed configuration of the BIND 9 daemon reported
+			-- by the agent and its hash used to detect the configuration
+			-- changes.
+			ALTER TABLE bind9_daemon ADD COLUMN config TEXT;
+			ALTER TABLE bind9_daemon ADD COLUMN config_hash TEXT;
+		`)
+		return err
+	}, func(db migrations.DB) error {
+		_, err := db.Exec(`
+			ALTER TABLE bind9_daemon DROP COLUMN IF EXISTS config_hash;
+			ALTER TABLE bind9_daemon DROP COLUMN IF EXISTS config;
+		`)
+		return err
+	})
+}
@@ -0,0 +1,43 @@
+package dbmigs
+
+import "github.com/go-pg/migrations/v8"
+
+func init() {
+	migrations.MustRegisterTx(func(db migrations.DB) error {
+		_, err := db.Exec(`
+			-- Suppressions of the configuration review findings related
+			-- to specific objects (e.g., subnets or hosts). A suppression
+			-- without the daemon applies to all daemons.
+			CREATE TABLE IF NOT EXISTS config_review_suppression (
+				id BIGSERIAL PRIMARY KEY,
+				created_at TIMESTAMP WITHOUT TIME ZONE DEFAULT timezone('utc'::text, now()) NOT NULL,
+				expires_at TIMESTAMP WITHOUT TIME ZONE,
+				checker_name TEXT NOT NULL,
+				daemon_id BIGINT,
+				object_type TEXT NOT NULL,
+				object_key TEXT NOT NULL,
+				reason TEXT NOT NULL,
+				user_id BIGINT,
+				CONSTRAINT config_review_suppression_daemon_id_fk FOREIGN KEY (daemon_id)
+					REFERENCES daemon (id)
+					ON UPDATE CASCADE
+					ON DELETE CASCADE,
+				CONSTRAINT config_review_suppression_user_id_fk FOREIGN KEY (user_id)
+					REFERENCES system_user (id)
+					ON UPDATE CASCADE
+					ON DELETE SET NULL,
+				CONSTRAINT config_review_suppression_object_type_check CHECK (
+					object_type IN ('subnet', 'host')
+				)
+			);
+
+			CREATE INDEX config_review_suppression_daemon_id_idx ON config_review_suppression (daemon_id);
+		`)
+		return err
+	}, func(db migrations.DB) error {
+		_, err := db.Exec(`
+			DROP TABLE IF EXISTS config_review_suppression;
+		`)
+		return err
+	})
+}
@@ -0,0 +1,44 @@
+package dbmigs
+
+import "github.com/go-pg/migrations/v8"
+
+func init() {
+	migrations.MustRegisterTx(func(db migrations.DB) error {
+		_, err := db.Exec(`
+			-- The time when the zone was transferred from the DNS server and
+			-- the serial of the transferred zone. They are used to determine
+			-- whether the cached resource records are up to date.
+			ALTER TABLE local_zone ADD COLUMN IF NOT EXISTS zone_transfer_at TIMESTAMP WITHOUT TIME ZONE;
+			ALTER TABLE local_zone ADD COLUMN IF NOT EXISTS zone_transfer_serial BIGINT;
+
+			-- Holds the resource records of the zones transferred from the DNS
+			-- servers. The records are associated with the local zones because
+			-- the zone contents may differ between the servers and views.
+			CREATE TABLE IF NOT EXISTS local_zone_rr (
+				id BIGSERIAL NOT NULL,
+				local_zone_id BIGINT NOT NULL,
+				name TEXT NOT NULL,
+				ttl BIGINT NOT NULL,
+				class TEXT NOT NULL,
+				type TEXT NOT NULL,
+				data TEXT NOT NULL,
+				CONSTRAINT local_zone_rr_pkey PRIMARY KEY (id),
+				CONSTRAINT local_zone_rr_local_zone_id FOREIGN KEY (local_zone_id)
+					REFERENCES local_zone (id) MATCH SIMPLE
+					ON UPDATE CASCADE
+					ON DELETE CASCADE
+			);
+			-- The records are queried by the local zone and optionally
+			-- filtered by type.
+			CREATE INDEX local_zone_rr_local_zone_id_type_idx ON local_zone_rr(local_zone_id, type);
+		`)
+		return err
+	}, func(db migrations.DB) error {
+		_, err := db.Exec(`
+			DROP TABLE IF EXISTS local_zone_rr;
+			ALTER TABLE local_zone DROP COLUMN IF EXISTS zone_transfer_serial;
+			ALTER TABLE local_zone DROP COLUMN IF EXISTS zone_transfer_at;
+		`)
+		return err
+	})
+}
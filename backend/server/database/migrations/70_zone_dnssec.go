@@ -0,0 +1,19 @@
+package dbmigs
+
+import "github.com/go-pg/migrations/v8"
+
+func init() {
+	migrations.MustRegisterTx(func(db migrations.DB) error {
+		_, err := db.Exec(`
+			-- DNSSEC state of the zone on a DNS server determined from the
+			-- zone contents and the rndc dnssec -status output.
+			ALTER TABLE local_zone ADD COLUMN IF NOT EXISTS dnssec JSONB;
+		`)
+		return err
+	}, func(db migrations.DB) error {
+		_, err := db.Exec(`
+			ALTER TABLE local_zone DROP COLUMN IF EXISTS dnssec;
+		`)
+		return err
+	})
+}
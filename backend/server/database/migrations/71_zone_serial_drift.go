@@ -0,0 +1,20 @@
+package dbmigs
+
+import "github.com/go-pg/migrations/v8"
+
+func init() {
+	migrations.MustRegisterTx(func(db migrations.DB) error {
+		_, err := db.Exec(`
+			-- Serial drift of a secondary zone, i.e., the primary zone serial
+			-- the secondary zone lags behind and the time when the lag was
+			-- first observed.
+			ALTER TABLE local_zone ADD COLUMN IF NOT EXISTS serial_drift JSONB;
+		`)
+		return err
+	}, func(db migrations.DB) error {
+		_, err := db.Exec(`
+			ALTER TABLE local_zone DROP COLUMN IF EXISTS serial_drift;
+		`)
+		return err
+	})
+}
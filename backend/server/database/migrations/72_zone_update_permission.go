@@ -0,0 +1,35 @@
+package dbmigs
+
+import "github.com/go-pg/migrations/v8"
+
+func init() {
+	migrations.MustRegisterTx(func(db migrations.DB) error {
+		_, err := db.Exec(`
+			-- Associates the users with the zones they are allowed to update.
+			-- The super-admin users are allowed to update all zones, so they
+			-- don't need the entries in this table.
+			CREATE TABLE IF NOT EXISTS zone_update_permission (
+				user_id INTEGER NOT NULL,
+				zone_id BIGINT NOT NULL,
+				CONSTRAINT zone_update_permission_pkey PRIMARY KEY (user_id, zone_id),
+				CONSTRAINT zone_update_permission_user_id FOREIGN KEY (user_id)
+					REFERENCES system_user (id) MATCH SIMPLE
+					ON UPDATE CASCADE
+					ON DELETE CASCADE,
+				CONSTRAINT zone_update_permission_zone_id FOREIGN KEY (zone_id)
+					REFERENCES zone (id) MATCH SIMPLE
+					ON UPDATE CASCADE
+					ON DELETE CASCADE
+			);
+			-- The primary key covers the queries by user_id. The permissions
+			-- are also listed by zone_id.
+			CREATE INDEX zone_update_permission_zone_id_idx ON zone_update_permission(zone_id);
+		`)
+		return err
+	}, func(db migrations.DB) error {
+		_, err := db.Exec(`
+			DROP TABLE IF EXISTS zone_update_permission;
+		`)
+		return err
+	})
+}
@@ -0,0 +1,165 @@
+package dbmodel
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-pg/pg/v10"
+	"github.com/go-pg/pg/v10/orm"
+	pkgerrors "github.com/pkg/errors"
+	dbops "isc.org/stork/server/database"
+	storkutil "isc.org/stork/util"
+)
+
+// Type of the object which configuration review findings are suppressed.
+type ConfigReviewSuppressionObjectType string
+
+// Supported types of the suppressed objects.
+const (
+	// Subnet identified by its prefix, e.g., 192.0.2.0/24.
+	ConfigReviewSuppressionObjectSubnet ConfigReviewSuppressionObjectType = "subnet"
+	// Host reservation identified by its ID in the Stork database.
+	ConfigReviewSuppressionObjectHost ConfigReviewSuppressionObjectType = "host"
+)
+
+// Suppression of the configuration review findings of a checker related to
+// a specific object (e.g., a subnet). The checker skips the suppressed object
+// but still reviews the other objects of the daemon. The suppression without
+// the daemon applies to all daemons. The suppression without the expiration
+// time is permanent.
+type ConfigReviewSuppression struct {
+	ID        int64
+	CreatedAt time.Time
+	ExpiresAt *time.Time
+
+	CheckerName string
+	DaemonID    *int64
+	ObjectType  ConfigReviewSuppressionObjectType
+	ObjectKey   string
+	Reason      string
+
+	UserID *int64
+	User   *SystemUser `pg:"rel:has-one"`
+}
+
+// Returns the object key in the form used for comparisons. The subnet
+// prefixes are converted to the canonical form.
+func normalizeConfigReviewSuppressionKey(objectType ConfigReviewSuppressionObjectType, objectKey string) string {
+	objectKey = strings.TrimSpace(objectKey)
+	if objectType == ConfigReviewSuppressionObjectSubnet {
+		if parsed := storkutil.ParseIP(objectKey); parsed != nil && parsed.Prefix {
+			return parsed.NetworkAddress
+		}
+	}
+	return objectKey
+}
+
+// Validates the suppressed object type and key. The subnet must be
+// identified by a prefix and the host by a positive ID.
+func ValidateConfigReviewSuppressionObject(objectType ConfigReviewSuppressionObjectType, objectKey string) error {
+	switch objectType {
+	case ConfigReviewSuppressionObjectSubnet:
+		if parsed := storkutil.ParseIP(objectKey); parsed == nil || !parsed.Prefix {
+			return pkgerrors.Errorf("invalid subnet prefix %s", objectKey)
+		}
+	case ConfigReviewSuppressionObjectHost:
+		if id, err := strconv.ParseInt(objectKey, 10, 64); err != nil || id <= 0 {
+			return pkgerrors.Errorf("invalid host ID %s", objectKey)
+		}
+	default:
+		return pkgerrors.Errorf("unsupported suppressed object type %s", objectType)
+	}
+	return nil
+}
+
+// Checks if the suppression has expired at the specified time.
+func (s *ConfigReviewSuppression) IsExpired(now time.Time) bool {
+	return s.ExpiresAt != nil && !s.ExpiresAt.After(now)
+}
+
+// Checks if the suppression applies to the object reviewed by the checker.
+func (s *ConfigReviewSuppression) Matches(checkerName string, objectType ConfigReviewSuppressionObjectType, objectKey string) bool {
+	return s.CheckerName == checkerName && s.ObjectType == objectType &&
+		normalizeConfigReviewSuppressionKey(s.ObjectType, s.ObjectKey) == normalizeConfigReviewSuppressionKey(objectType, objectKey)
+}
+
+// Inserts the configuration review suppression into the database.
+func AddConfigReviewSuppression(dbi dbops.DBI, suppression *ConfigReviewSuppression) error {
+	if _, err := dbi.Model(suppression).Insert(); err != nil {
+		return pkgerrors.Wrapf(err, "problem adding config review suppression for checker %s", suppression.CheckerName)
+	}
+	return nil
+}
+
+// Returns all configuration review suppressions, including the expired
+// ones, ordered by ID. The returned suppressions include the users who
+// created them.
+func GetConfigReviewSuppressions(dbi dbops.DBI) ([]ConfigReviewSuppression, error) {
+	var suppressions []ConfigReviewSuppression
+	err := dbi.Model(&suppressions).
+		Relation("User").
+		OrderExpr("config_review_suppression.id ASC").
+		Select()
+	if err != nil && !errors.Is(err, pg.ErrNoRows) {
+		return nil, pkgerrors.Wrap(err, "problem getting config review suppressions")
+	}
+	return suppressions, nil
+}
+
+// Returns the configuration review suppression by ID. It returns nil if the
+// suppression does not exist. The returned suppression includes the user who
+// created it.
+func GetConfigReviewSuppressionByID(dbi dbops.DBI, id int64) (*ConfigReviewSuppression, error) {
+	suppression := &ConfigReviewSuppression{}
+	err := dbi.Model(suppression).
+		Relation("User").
+		Where("config_review_suppression.id = ?", id).
+		Select()
+	if err != nil {
+		if errors.Is(err, pg.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, pkgerrors.Wrapf(err, "problem getting config review suppression with ID %d", id)
+	}
+	return suppression, nil
+}
+
+// Returns the configuration review suppressions applying to the daemon,
+// i.e., the suppressions for this daemon and for all daemons, which have
+// not expired yet.
+func GetActiveConfigReviewSuppressions(dbi dbops.DBI, daemonID int64) ([]ConfigReviewSuppression, error) {
+	var suppressions []ConfigReviewSuppression
+	err := dbi.Model(&suppressions).
+		WhereGroup(func(q *orm.Query) (*orm.Query, error) {
+			return q.WhereOr("config_review_suppression.daemon_id IS NULL").
+				WhereOr("config_review_suppression.daemon_id = ?", daemonID), nil
+		}).
+		WhereGroup(func(q *orm.Query) (*orm.Query, error) {
+			return q.WhereOr("config_review_suppression.expires_at IS NULL").
+				WhereOr("config_review_suppression.expires_at > now() at time zone 'UTC'"), nil
+		}).
+		OrderExpr("config_review_suppression.id ASC").
+		Select()
+	if err != nil && !errors.Is(err, pg.ErrNoRows) {
+		return nil, pkgerrors.Wrapf(err, "problem getting active config review suppressions for daemon %d", daemonID)
+	}
+	return suppressions, nil
+}
+
+// Deletes the configuration review suppression. It returns ErrNotExists
+// if the suppression does not exist.
+func DeleteConfigReviewSuppression(dbi dbops.DBI, id int64) error {
+	suppression := &ConfigReviewSuppression{
+		ID: id,
+	}
+	result, err := dbi.Model(suppression).WherePK().Delete()
+	if err != nil {
+		return pkgerrors.Wrapf(err, "problem deleting config review suppression with ID %d", id)
+	}
+	if result.RowsAffected() <= 0 {
+		return pkgerrors.Wrapf(ErrNotExists, "config review suppression with ID %d does not exist", id)
+	}
+	return nil
+}
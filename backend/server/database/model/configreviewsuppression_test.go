@@ -0,0 +1,183 @@
+package dbmodel
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	dbtest "isc.org/stork/server/database/test"
+	storkutil "isc.org/stork/util"
+)
+
+// Test that the suppressed object type and key are validated.
+func TestValidateConfigReviewSuppressionObject(t *testing.T) {
+	require.NoError(t, ValidateConfigReviewSuppressionObject(ConfigReviewSuppressionObjectSubnet, "192.0.2.0/24"))
+	require.NoError(t, ValidateConfigReviewSuppressionObject(ConfigReviewSuppressionObjectSubnet, "2001:db8:1::/64"))
+	require.NoError(t, ValidateConfigReviewSuppressionObject(ConfigReviewSuppressionObjectHost, "42"))
+
+	require.ErrorContains(t, ValidateConfigReviewSuppressionObject(ConfigReviewSuppressionObjectSubnet, "192.0.2.1"), "invalid subnet prefix 192.0.2.1")
+	require.ErrorContains(t, ValidateConfigReviewSuppressionObject(ConfigReviewSuppressionObjectSubnet, "foo"), "invalid subnet prefix foo")
+	require.ErrorContains(t, ValidateConfigReviewSuppressionObject(ConfigReviewSuppressionObjectHost, "0"), "invalid host ID 0")
+	require.ErrorContains(t, ValidateConfigReviewSuppressionObject(ConfigReviewSuppressionObjectHost, "foo"), "invalid host ID foo")
+	require.ErrorContains(t, ValidateConfigReviewSuppressionObject("pool", "192.0.2.1-192.0.2.10"), "unsupported suppressed object type pool")
+}
+
+// Test that the suppression matches the objects of the same type and
+// with the same key reviewed by the same checker.
+func TestConfigReviewSuppressionMatches(t *testing.T) {
+	subnet := &ConfigReviewSuppression{
+		CheckerName: "overlapping_subnet",
+		ObjectType:  ConfigReviewSuppressionObjectSubnet,
+		ObjectKey:   "2001:db8:0:0::/64",
+	}
+	require.True(t, subnet.Matches("overlapping_subnet", ConfigReviewSuppressionObjectSubnet, "2001:db8::/64"))
+	require.True(t, subnet.Matches("overlapping_subnet", ConfigReviewSuppressionObjectSubnet, "2001:DB8::/64"))
+	require.False(t, subnet.Matches("overlapping_subnet", ConfigReviewSuppressionObjectSubnet, "2001:db8::/48"))
+	require.False(t, subnet.Matches("canonical_prefix", ConfigReviewSuppressionObjectSubnet, "2001:db8::/64"))
+
+	host := &ConfigReviewSuppression{
+		CheckerName: "duplicated_host_identifiers",
+		ObjectType:  ConfigReviewSuppressionObjectHost,
+		ObjectKey:   "42",
+	}
+	require.True(t, host.Matches("duplicated_host_identifiers", ConfigReviewSuppressionObjectHost, "42"))
+	require.False(t, host.Matches("duplicated_host_identifiers", ConfigReviewSuppressionObjectHost, "4"))
+	require.False(t, host.Matches("duplicated_host_identifiers", ConfigReviewSuppressionObjectSubnet, "42"))
+}
+
+// Test that the expiration of the suppression is recognized.
+func TestConfigReviewSuppressionIsExpired(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	suppression := &ConfigReviewSuppression{}
+	require.False(t, suppression.IsExpired(now))
+
+	suppression.ExpiresAt = storkutil.Ptr(now.Add(time.Minute))
+	require.False(t, suppression.IsExpired(now))
+
+	suppression.ExpiresAt = storkutil.Ptr(now)
+	require.True(t, suppression.IsExpired(now))
+}
+
+// Test adding, getting and deleting the config review suppressions.
+func TestAddGetDeleteConfigReviewSuppression(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	user := &SystemUser{
+		Login:    "test",
+		Lastname: "test",
+		Name:     "test",
+	}
+	_, err := CreateUser(db, user)
+	require.NoError(t, err)
+
+	daemon1, daemon2, err := addTestDaemons(db)
+	require.NoError(t, err)
+	daemons := []*Daemon{daemon1, daemon2}
+
+	expiresAt := storkutil.UTCNow().Add(time.Hour).Truncate(time.Second)
+	suppressions := []*ConfigReviewSuppression{
+		{
+			CheckerName: "overlapping_subnet",
+			DaemonID:    &daemons[0].ID,
+			ObjectType:  ConfigReviewSuppressionObjectSubnet,
+			ObjectKey:   "192.0.2.0/24",
+			Reason:      "lab setup",
+			UserID:      storkutil.Ptr(int64(user.ID)),
+			ExpiresAt:   &expiresAt,
+		},
+		{
+			CheckerName: "duplicated_host_identifiers",
+			ObjectType:  ConfigReviewSuppressionObjectHost,
+			ObjectKey:   "42",
+			Reason:      "test host",
+		},
+		{
+			CheckerName: "overlapping_subnet",
+			DaemonID:    &daemons[1].ID,
+			ObjectType:  ConfigReviewSuppressionObjectSubnet,
+			ObjectKey:   "192.0.3.0/24",
+			Reason:      "other daemon",
+		},
+		{
+			CheckerName: "overlapping_subnet",
+			DaemonID:    &daemons[0].ID,
+			ObjectType:  ConfigReviewSuppressionObjectSubnet,
+			ObjectKey:   "192.0.4.0/24",
+			Reason:      "expired",
+			ExpiresAt:   storkutil.Ptr(storkutil.UTCNow().Add(-time.Hour)),
+		},
+	}
+	for _, suppression := range suppressions {
+		err = AddConfigReviewSuppression(db, suppression)
+		require.NoError(t, err)
+		require.NotZero(t, suppression.ID)
+	}
+
+	// Get all suppressions.
+	all, err := GetConfigReviewSuppressions(db)
+	require.NoError(t, err)
+	require.Len(t, all, 4)
+	require.Equal(t, "lab setup", all[0].Reason)
+	require.NotNil(t, all[0].User)
+	require.Equal(t, "test", all[0].User.Login)
+	require.Nil(t, all[1].User)
+	require.NotZero(t, all[0].CreatedAt)
+
+	// Get a suppression by ID.
+	suppression, err := GetConfigReviewSuppressionByID(db, suppressions[0].ID)
+	require.NoError(t, err)
+	require.NotNil(t, suppression)
+	require.Equal(t, "overlapping_subnet", suppression.CheckerName)
+	require.Equal(t, daemons[0].ID, *suppression.DaemonID)
+	require.Equal(t, ConfigReviewSuppressionObjectSubnet, suppression.ObjectType)
+	require.Equal(t, "192.0.2.0/24", suppression.ObjectKey)
+	require.NotNil(t, suppression.ExpiresAt)
+	require.Equal(t, expiresAt, suppression.ExpiresAt.UTC())
+
+	suppression, err = GetConfigReviewSuppressionByID(db, 1000)
+	require.NoError(t, err)
+	require.Nil(t, suppression)
+
+	// Get the active suppressions for the daemon. They include the
+	// suppressions for all daemons but exclude the expired ones.
+	active, err := GetActiveConfigReviewSuppressions(db, daemons[0].ID)
+	require.NoError(t, err)
+	require.Len(t, active, 2)
+	require.Equal(t, suppressions[0].ID, active[0].ID)
+	require.Equal(t, suppressions[1].ID, active[1].ID)
+
+	// Delete the suppression.
+	err = DeleteConfigReviewSuppression(db, suppressions[0].ID)
+	require.NoError(t, err)
+	err = DeleteConfigReviewSuppression(db, suppressions[0].ID)
+	require.ErrorIs(t, err, ErrNotExists)
+
+	all, err = GetConfigReviewSuppressions(db)
+	require.NoError(t, err)
+	require.Len(t, all, 3)
+}
+
+// Test that the suppressions are deleted together with the daemon's app.
+func TestDeleteDaemonWithConfigReviewSuppression(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	daemon, _, err := addTestDaemons(db)
+	require.NoError(t, err)
+	err = AddConfigReviewSuppression(db, &ConfigReviewSuppression{
+		CheckerName: "overlapping_subnet",
+		DaemonID:    &daemon.ID,
+		ObjectType:  ConfigReviewSuppressionObjectSubnet,
+		ObjectKey:   "192.0.2.0/24",
+		Reason:      "lab setup",
+	})
+	require.NoError(t, err)
+
+	err = DeleteApp(db, daemon.App)
+	require.NoError(t, err)
+
+	suppressions, err := GetConfigReviewSuppressions(db)
+	require.NoError(t, err)
+	require.Empty(t, suppressions)
+}
@@ -50,6 +50,20 @@ type KeaDaemon struct {
 	Config     *KeaConfig `pg:",use_zero"`
 	ConfigHash string
 	DaemonID   int64
+	// Hash of the configuration file contents reported by the agent.
+	// It is empty when the agent couldn't determine the file hash.
+	ConfigFileHash string
+	// Content hash of the running configuration when it was last known
+	// to match the configuration file, i.e., when the file changed or the
+	// daemon was restarted. The running configuration returned by the
+	// config-get command includes the default values, so it cannot be
+	// compared with the file directly.
+	SavedConfigHash string
+	// Indicates that the running configuration has changed since it
+	// was last known to match the configuration file, e.g., because the
+	// configuration changes haven't been written to the file with the
+	// config-write command.
+	UnsavedConfigChanges bool `pg:",use_zero"`
 
 	KeaDHCPDaemon *KeaDHCPDaemon `pg:"rel:belongs-to"`
 }
@@ -69,6 +83,11 @@ type Bind9Daemon struct {
 	ID       int64
 	DaemonID int64
 	Stats    Bind9DaemonStats
+	// Preprocessed configuration reported by the agent. The key secrets
+	// are obscured.
+	Config string
+	// Hash of the configuration used to detect the configuration changes.
+	ConfigHash string
 }
 
 // A structure reflecting all SQL tables holding information about the
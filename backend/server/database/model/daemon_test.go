@@ -147,6 +147,8 @@ func TestUpdateBind9Daemon(t *testing.T) {
 	daemon.Version = "9.20"
 
 	daemon.Bind9Daemon.Stats.ZoneCount = 123
+	daemon.Bind9Daemon.Config = "options { recursion no; };"
+	daemon.Bind9Daemon.ConfigHash = "1234"
 
 	err = UpdateDaemon(db, daemon)
 	require.NoError(t, err)
@@ -163,6 +165,8 @@ func TestUpdateBind9Daemon(t *testing.T) {
 	require.Equal(t, "9.20", daemon.Version)
 	require.NotNil(t, daemon.Bind9Daemon)
 	require.EqualValues(t, 123, daemon.Bind9Daemon.Stats.ZoneCount)
+	require.Equal(t, "options { recursion no; };", daemon.Bind9Daemon.Config)
+	require.Equal(t, "1234", daemon.Bind9Daemon.ConfigHash)
 }
 
 // Returns all HA state names to which the daemon belongs and the
@@ -0,0 +1,100 @@
+package dbmodel
+
+import (
+	"context"
+	"errors"
+
+	"github.com/go-pg/pg/v10"
+	pkgerrors "github.com/pkg/errors"
+	keaconfig "isc.org/stork/appcfg/kea"
+	dbops "isc.org/stork/server/database"
+)
+
+// Represents a forward or reverse DDNS domain configured in the Kea D2
+// daemon. The domains are replaced in the database whenever the D2
+// daemon's configuration is fetched or modified by the config manager.
+type DDNSDomain struct {
+	ID         int64
+	DaemonID   int64
+	Daemon     *Daemon `pg:"rel:has-one"`
+	Direction  keaconfig.D2DDNSDirection
+	Name       string
+	KeyName    string
+	DNSServers []keaconfig.D2DNSServer
+}
+
+// Creates the DDNS domains from the D2 daemon's configuration. It returns
+// an empty slice if the daemon has no configuration or it is not a D2
+// daemon.
+func NewDDNSDomainsFromDaemon(daemon *Daemon) (domains []DDNSDomain) {
+	if daemon.KeaDaemon == nil || daemon.KeaDaemon.Config == nil || !daemon.KeaDaemon.Config.IsD2() {
+		return
+	}
+	for _, direction := range []keaconfig.D2DDNSDirection{keaconfig.D2ForwardDDNS, keaconfig.D2ReverseDDNS} {
+		for _, domain := range daemon.KeaDaemon.Config.GetDDNSDomains(direction) {
+			domains = append(domains, DDNSDomain{
+				DaemonID:   daemon.ID,
+				Direction:  direction,
+				Name:       domain.Name,
+				KeyName:    domain.KeyName,
+				DNSServers: domain.DNSServers,
+			})
+		}
+	}
+	return
+}
+
+// Replaces the DDNS domains of the daemon with the specified domains in
+// the transaction.
+func commitDDNSDomains(tx *pg.Tx, daemonID int64, domains []DDNSDomain) error {
+	_, err := tx.Model((*DDNSDomain)(nil)).
+		Where("daemon_id = ?", daemonID).
+		Delete()
+	if err != nil {
+		return pkgerrors.Wrapf(err, "problem deleting DDNS domains of daemon %d", daemonID)
+	}
+	if len(domains) == 0 {
+		return nil
+	}
+	for i := range domains {
+		domains[i].ID = 0
+		domains[i].DaemonID = daemonID
+	}
+	if _, err = tx.Model(&domains).Insert(); err != nil {
+		return pkgerrors.Wrapf(err, "problem inserting DDNS domains of daemon %d", daemonID)
+	}
+	return nil
+}
+
+// Replaces the DDNS domains of the daemon with the specified domains.
+func CommitDDNSDomains(dbi dbops.DBI, daemonID int64, domains []DDNSDomain) error {
+	if db, ok := dbi.(*pg.DB); ok {
+		return db.RunInTransaction(context.Background(), func(tx *pg.Tx) error {
+			return commitDDNSDomains(tx, daemonID, domains)
+		})
+	}
+	return commitDDNSDomains(dbi.(*pg.Tx), daemonID, domains)
+}
+
+// Replaces the DDNS domains of the D2 daemon with the domains found in
+// its configuration. It does nothing for other daemons.
+func CommitDDNSDomainsFromDaemon(dbi dbops.DBI, daemon *Daemon) error {
+	if daemon.Name != DaemonNameD2 || daemon.KeaDaemon == nil || daemon.KeaDaemon.Config == nil {
+		return nil
+	}
+	return CommitDDNSDomains(dbi, daemon.ID, NewDDNSDomainsFromDaemon(daemon))
+}
+
+// Returns the DDNS domains of the daemon ordered by direction and name.
+func GetDDNSDomainsByDaemonID(dbi dbops.DBI, daemonID int64) ([]DDNSDomain, error) {
+	var domains []DDNSDomain
+	err := dbi.Model(&domains).
+		Where("ddns_domain.daemon_id = ?", daemonID).
+		OrderExpr("ddns_domain.direction ASC").
+		OrderExpr("ddns_domain.name ASC").
+		Select()
+	if err != nil && !errors.Is(err, pg.ErrNoRows) {
+		return nil, pkgerrors.Wrapf(err, "problem getting DDNS domains of daemon %d", daemonID)
+	}
+	return domains, nil
+}
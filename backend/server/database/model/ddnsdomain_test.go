@@ -0,0 +1,93 @@
+package dbmodel
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	keaconfig "isc.org/stork/appcfg/kea"
+	dbtest "isc.org/stork/server/database/test"
+)
+
+// Test that the DDNS domains are created from the D2 daemon configuration
+// and that they can be replaced and fetched from the database.
+func TestCommitDDNSDomains(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	daemon, _, err := addTestDaemons(db)
+	require.NoError(t, err)
+
+	// The DHCP daemon has no DDNS domains.
+	require.NoError(t, CommitDDNSDomainsFromDaemon(db, daemon))
+	domains, err := GetDDNSDomainsByDaemonID(db, daemon.ID)
+	require.NoError(t, err)
+	require.Empty(t, domains)
+
+	daemon.Name = DaemonNameD2
+	err = daemon.SetConfigFromJSON(`{
+		"DhcpDdns": {
+			"forward-ddns": {
+				"ddns-domains": [
+					{
+						"name": "example.org.",
+						"dns-servers": [
+							{
+								"ip-address": "192.0.2.1",
+								"port": 5300
+							}
+						]
+					}
+				]
+			},
+			"reverse-ddns": {
+				"ddns-domains": [
+					{
+						"name": "2.0.192.in-addr.arpa.",
+						"key-name": "key1",
+						"dns-servers": [
+							{
+								"ip-address": "192.0.2.1"
+							}
+						]
+					}
+				]
+			},
+			"tsig-keys": [
+				{
+					"name": "key1",
+					"algorithm": "HMAC-MD5",
+					"secret": "LSWXnfkKZjdPJI5QxlpnfQ=="
+				}
+			]
+		}
+	}`)
+	require.NoError(t, err)
+
+	require.NoError(t, CommitDDNSDomainsFromDaemon(db, daemon))
+	domains, err = GetDDNSDomainsByDaemonID(db, daemon.ID)
+	require.NoError(t, err)
+	require.Len(t, domains, 2)
+
+	require.Equal(t, keaconfig.D2ForwardDDNS, domains[0].Direction)
+	require.Equal(t, "example.org.", domains[0].Name)
+	require.Empty(t, domains[0].KeyName)
+	require.Len(t, domains[0].DNSServers, 1)
+	require.Equal(t, "192.0.2.1", domains[0].DNSServers[0].IPAddress)
+	require.EqualValues(t, 5300, domains[0].DNSServers[0].Port)
+
+	require.Equal(t, keaconfig.D2ReverseDDNS, domains[1].Direction)
+	require.Equal(t, "2.0.192.in-addr.arpa.", domains[1].Name)
+	require.Equal(t, "key1", domains[1].KeyName)
+
+	// Committing the domains again should replace the existing ones.
+	require.NoError(t, CommitDDNSDomains(db, daemon.ID, domains[1:]))
+	domains, err = GetDDNSDomainsByDaemonID(db, daemon.ID)
+	require.NoError(t, err)
+	require.Len(t, domains, 1)
+	require.Equal(t, keaconfig.D2ReverseDDNS, domains[0].Direction)
+
+	require.NoError(t, CommitDDNSDomains(db, daemon.ID, nil))
+	domains, err = GetDDNSDomainsByDaemonID(db, daemon.ID)
+	require.NoError(t, err)
+	require.Empty(t, domains)
+}
@@ -376,6 +376,89 @@ func GetHostsByDaemonID(dbi dbops.DBI, daemonID int64, dataSource HostDataSource
 	return hosts, int64(total), err
 }
 
+// Fetches all hosts served by the daemons having the specified name (e.g.,
+// dhcp4). The returned local hosts include the daemons and the reserved
+// addresses. It is used to compare the host reservations configured in
+// different servers of the same type.
+func GetHostsByDaemonName(dbi dbops.DBI, daemonName string) ([]Host, error) {
+	hosts := []Host{}
+	q := dbi.Model(&hosts).
+		DistinctOn("host.id").
+		Join("INNER JOIN local_host AS lh ON host.id = lh.host_id").
+		Join("INNER JOIN daemon AS d ON lh.daemon_id = d.id").
+		Relation("HostIdentifiers", func(q *orm.Query) (*orm.Query, error) {
+			return q.Order("host_identifier.id ASC"), nil
+		}).
+		Relation("LocalHosts.IPReservations", func(q *orm.Query) (*orm.Query, error) {
+			return q.Order("ip_reservation.id ASC"), nil
+		}).
+		Relation("LocalHosts.Daemon").
+		Where("d.name = ?", daemonName).
+		OrderExpr("host.id ASC")
+
+	err := q.Select()
+	if err != nil {
+		if errors.Is(err, pg.ErrNoRows) {
+			return nil, nil
+		}
+		err = pkgerrors.Wrapf(err, "problem getting hosts for %s daemons", daemonName)
+		return nil, err
+	}
+	return hosts, nil
+}
+
+// Fetches the hosts which may conflict with the hosts served by the daemon.
+// They are the hosts served by the daemons of the same type and belonging
+// to the subnets served by the daemon or to the shared networks including
+// these subnets. The global hosts are only returned when they are served by
+// the daemon or by the daemons serving any of the same subnets. The returned
+// hosts include the subnets, and the local hosts include the daemons and
+// the reserved addresses.
+func GetHostsInDaemonScopes(dbi dbops.DBI, daemonID int64) ([]Host, error) {
+	hosts := []Host{}
+	q := dbi.Model(&hosts).
+		DistinctOn("host.id").
+		Join("INNER JOIN local_host AS lh ON host.id = lh.host_id").
+		Join("INNER JOIN daemon AS d ON lh.daemon_id = d.id").
+		Relation("HostIdentifiers", func(q *orm.Query) (*orm.Query, error) {
+			return q.Order("host_identifier.id ASC"), nil
+		}).
+		Relation("LocalHosts.IPReservations", func(q *orm.Query) (*orm.Query, error) {
+			return q.Order("ip_reservation.id ASC"), nil
+		}).
+		Relation("LocalHosts.Daemon").
+		Relation("Subnet").
+		Where("d.name = (SELECT name FROM daemon WHERE id = ?)", daemonID).
+		WhereGroup(func(q *orm.Query) (*orm.Query, error) {
+			q = q.
+				Where(`host.subnet_id IN (
+					SELECT s.id FROM subnet AS s
+					WHERE s.id IN (SELECT ls.subnet_id FROM local_subnet AS ls WHERE ls.daemon_id = ?)
+					OR s.shared_network_id IN (
+						SELECT sn.shared_network_id FROM subnet AS sn
+						INNER JOIN local_subnet AS ls ON sn.id = ls.subnet_id
+						WHERE ls.daemon_id = ?
+					)
+				)`, daemonID, daemonID).
+				WhereOr(`host.subnet_id IS NULL AND (lh.daemon_id = ? OR lh.daemon_id IN (
+					SELECT ls.daemon_id FROM local_subnet AS ls
+					WHERE ls.subnet_id IN (SELECT subnet_id FROM local_subnet WHERE daemon_id = ?)
+				))`, daemonID, daemonID)
+			return q, nil
+		}).
+		OrderExpr("host.id ASC")
+
+	err := q.Select()
+	if err != nil {
+		if errors.Is(err, pg.ErrNoRows) {
+			return nil, nil
+		}
+		err = pkgerrors.Wrapf(err, "problem getting hosts in the scopes of daemon %d", daemonID)
+		return nil, err
+	}
+	return hosts, nil
+}
+
 // Container for values filtering hosts fetched by page.
 //
 // The AppID, if different than 0, is used to fetch hosts whose local hosts belong to
@@ -1679,6 +1679,104 @@ func TestGetHostsByDaemonID(t *testing.T) {
 	require.Empty(t, returned)
 }
 
+// Test fetching the hosts served by the daemons having the specified name.
+func TestGetHostsByDaemonName(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	_, hosts := addTestHosts(t, db)
+
+	returned, err := GetHostsByDaemonName(db, DaemonNameDHCPv4)
+	require.NoError(t, err)
+	require.Len(t, returned, 2)
+	require.Equal(t, hosts[0].ID, returned[0].ID)
+	require.Equal(t, hosts[1].ID, returned[1].ID)
+	require.Len(t, returned[0].HostIdentifiers, 2)
+	require.Len(t, returned[0].LocalHosts, 1)
+	require.NotNil(t, returned[0].LocalHosts[0].Daemon)
+	require.Equal(t, DaemonNameDHCPv4, returned[0].LocalHosts[0].Daemon.Name)
+	require.Len(t, returned[0].LocalHosts[0].IPReservations, 2)
+
+	returned, err = GetHostsByDaemonName(db, DaemonNameDHCPv6)
+	require.NoError(t, err)
+	require.Len(t, returned, 2)
+	require.Equal(t, hosts[2].ID, returned[0].ID)
+	require.Equal(t, hosts[3].ID, returned[1].ID)
+
+	returned, err = GetHostsByDaemonName(db, DaemonNameD2)
+	require.NoError(t, err)
+	require.Empty(t, returned)
+}
+
+// Test fetching the hosts which may conflict with the hosts served by
+// the daemon.
+func TestGetHostsInDaemonScopes(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	apps, hosts := addTestHosts(t, db)
+
+	// Only the first daemon serves the subnet of the first host.
+	subnet := &Subnet{
+		ID:     hosts[0].SubnetID,
+		Prefix: "192.0.2.0/24",
+		LocalSubnets: []*LocalSubnet{
+			{
+				DaemonID:      apps[0].Daemons[0].ID,
+				LocalSubnetID: 1,
+			},
+		},
+	}
+	err := AddLocalSubnets(db, subnet)
+	require.NoError(t, err)
+
+	// The global host of the second daemon should not be returned because
+	// the daemons don't serve the same subnets.
+	returned, err := GetHostsInDaemonScopes(db, apps[0].Daemons[0].ID)
+	require.NoError(t, err)
+	require.Len(t, returned, 1)
+	require.Equal(t, hosts[0].ID, returned[0].ID)
+	require.NotNil(t, returned[0].Subnet)
+	require.Equal(t, "192.0.2.0/24", returned[0].Subnet.Prefix)
+	require.Len(t, returned[0].LocalHosts, 1)
+	require.NotNil(t, returned[0].LocalHosts[0].Daemon)
+	require.Len(t, returned[0].LocalHosts[0].IPReservations, 2)
+
+	// The second daemon serves no subnets. Only its global host should
+	// be returned.
+	returned, err = GetHostsInDaemonScopes(db, apps[1].Daemons[0].ID)
+	require.NoError(t, err)
+	require.Len(t, returned, 1)
+	require.Equal(t, hosts[1].ID, returned[0].ID)
+	require.Nil(t, returned[0].Subnet)
+
+	// When both daemons serve the subnet, both hosts should be returned
+	// for each of them.
+	subnet.LocalSubnets = []*LocalSubnet{
+		{
+			DaemonID:      apps[1].Daemons[0].ID,
+			LocalSubnetID: 1,
+		},
+	}
+	err = AddLocalSubnets(db, subnet)
+	require.NoError(t, err)
+
+	for _, daemon := range []*Daemon{apps[0].Daemons[0], apps[1].Daemons[0]} {
+		returned, err = GetHostsInDaemonScopes(db, daemon.ID)
+		require.NoError(t, err)
+		require.Len(t, returned, 2)
+		require.Equal(t, hosts[0].ID, returned[0].ID)
+		require.Equal(t, hosts[1].ID, returned[1].ID)
+	}
+
+	// The hosts served by the daemons of a different type should not be
+	// returned.
+	returned, err = GetHostsInDaemonScopes(db, apps[1].Daemons[1].ID)
+	require.NoError(t, err)
+	require.Len(t, returned, 1)
+	require.Equal(t, hosts[3].ID, returned[0].ID)
+}
+
 // Test that the host and its identifiers and reservations can be
 // deleted.
 func TestDeleteHost(t *testing.T) {
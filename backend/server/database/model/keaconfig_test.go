@@ -10,6 +10,7 @@ import (
 	dhcpmodel "isc.org/stork/datamodel/dhcp"
 	dbtest "isc.org/stork/server/database/test"
 	storktest "isc.org/stork/server/test"
+	storkutil "isc.org/stork/util"
 )
 
 // Test that KeaConfig isn't constructed from nil.
@@ -555,7 +556,7 @@ func TestNewHostFromKeaDHCPv4Reservation(t *testing.T) {
 			{
 				AlwaysSend: true,
 				Code:       5,
-				CSVFormat:  true,
+				CSVFormat:  storkutil.Ptr(true),
 				Data:       "10.0.1.1",
 				Name:       "domain-name-server",
 				Space:      dhcpmodel.DHCPv4OptionSpace,
@@ -0,0 +1,195 @@
+package dbmodel
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/go-pg/pg/v10"
+	pkgerrors "github.com/pkg/errors"
+	dbops "isc.org/stork/server/database"
+	storkutil "isc.org/stork/util"
+)
+
+// Represents a revision of the Kea daemon's configuration. A revision is
+// stored whenever the state puller fetches a configuration differing from
+// the most recent revision stored for the daemon. If the configuration change
+// has been caused by a config update committed by the config manager, the
+// revision includes the user who committed it and the operation type (e.g.,
+// "client_class_add"). These values are empty when the configuration has
+// been modified outside of Stork.
+type KeaConfigRevision struct {
+	ID         int64
+	DaemonID   int64
+	Daemon     *Daemon `pg:"rel:has-one"`
+	CreatedAt  time.Time
+	Config     *KeaConfig
+	ConfigHash string
+	UserID     int64
+	User       *SystemUser `pg:"rel:has-one"`
+	Operation  string
+}
+
+// Maximum number of the configuration revisions stored for a daemon. The
+// oldest revisions are removed when a new revision exceeds this limit.
+const KeaConfigRevisionsLimit = 100
+
+// Holds the information about the most recent config update committed
+// by the config manager for a daemon. It is consumed by the state puller
+// when it stores the next configuration revision for the daemon.
+type KeaConfigRevisionCause struct {
+	DaemonID  int64 `pg:",pk"`
+	CreatedAt time.Time
+	UserID    int64
+	Operation string
+}
+
+// Remembers the user and the operation which caused a configuration change
+// for a daemon. The subsequent configuration revision stored for the daemon
+// will be associated with this information. If there is already a cause
+// stored for the daemon, it is replaced. The zero user ID indicates that the
+// change hasn't been caused by any particular user.
+func AddKeaConfigRevisionCause(dbi dbops.DBI, daemonID, userID int64, operation string) error {
+	cause := &KeaConfigRevisionCause{
+		DaemonID:  daemonID,
+		CreatedAt: storkutil.UTCNow(),
+		UserID:    userID,
+		Operation: operation,
+	}
+	_, err := dbi.Model(cause).
+		OnConflict("(daemon_id) DO UPDATE").
+		Set("created_at = EXCLUDED.created_at").
+		Set("user_id = EXCLUDED.user_id").
+		Set("operation = EXCLUDED.operation").
+		Insert()
+	if err != nil {
+		return pkgerrors.Wrapf(err, "problem adding config revision cause for daemon %d", daemonID)
+	}
+	return nil
+}
+
+// Inserts a configuration revision for the daemon in the transaction
+// unless the daemon's configuration hash is equal to the hash of the most
+// recent revision stored for the daemon. It associates the revision with
+// the cause remembered for the daemon and removes the cause. It also removes
+// the oldest revisions exceeding the KeaConfigRevisionsLimit. It returns
+// the inserted revision or nil if the revision has not been inserted.
+func addKeaConfigRevision(tx *pg.Tx, daemon *Daemon) (*KeaConfigRevision, error) {
+	// Only the hash of the most recent revision is needed. There is no
+	// need to fetch the whole configuration.
+	var latestHashes []string
+	_, err := tx.Query(&latestHashes, "SELECT config_hash FROM kea_config_revision WHERE daemon_id = ? ORDER BY id DESC LIMIT 1", daemon.ID)
+	if err != nil {
+		return nil, pkgerrors.Wrapf(err, "problem getting latest config revision hash for daemon %d", daemon.ID)
+	}
+	if len(latestHashes) > 0 && latestHashes[0] == daemon.KeaDaemon.ConfigHash {
+		return nil, nil
+	}
+	revision := &KeaConfigRevision{
+		DaemonID:   daemon.ID,
+		CreatedAt:  storkutil.UTCNow(),
+		Config:     daemon.KeaDaemon.Config,
+		ConfigHash: daemon.KeaDaemon.ConfigHash,
+	}
+	var causes []KeaConfigRevisionCause
+	_, err = tx.Query(&causes, "DELETE FROM kea_config_revision_cause WHERE daemon_id = ? RETURNING *", daemon.ID)
+	if err != nil {
+		return nil, pkgerrors.Wrapf(err, "problem getting config revision cause for daemon %d", daemon.ID)
+	}
+	if len(causes) > 0 {
+		revision.UserID = causes[0].UserID
+		revision.Operation = causes[0].Operation
+	}
+	if _, err = tx.Model(revision).Insert(); err != nil {
+		return nil, pkgerrors.Wrapf(err, "problem adding config revision for daemon %d", daemon.ID)
+	}
+	_, err = tx.Exec(`DELETE FROM kea_config_revision
+		WHERE daemon_id = ? AND id NOT IN (
+			SELECT id FROM kea_config_revision WHERE daemon_id = ? ORDER BY id DESC LIMIT ?
+		)`, daemon.ID, daemon.ID, KeaConfigRevisionsLimit)
+	if err != nil {
+		return nil, pkgerrors.Wrapf(err, "problem removing old config revisions for daemon %d", daemon.ID)
+	}
+	return revision, nil
+}
+
+// Inserts a configuration revision for the Kea daemon unless its
+// configuration hasn't changed since the most recent revision. The
+// daemon must have a non-nil configuration. It returns the inserted
+// revision or nil if the revision has not been inserted.
+func AddKeaConfigRevision(dbi dbops.DBI, daemon *Daemon) (revision *KeaConfigRevision, err error) {
+	if daemon.KeaDaemon == nil || daemon.KeaDaemon.Config == nil {
+		return nil, pkgerrors.Errorf("unable to add config revision for daemon %d without Kea configuration", daemon.ID)
+	}
+	if db, ok := dbi.(*pg.DB); ok {
+		err = db.RunInTransaction(context.Background(), func(tx *pg.Tx) error {
+			revision, err = addKeaConfigRevision(tx, daemon)
+			return err
+		})
+		return
+	}
+	return addKeaConfigRevision(dbi.(*pg.Tx), daemon)
+}
+
+// Returns the most recent configuration revision for the daemon or nil
+// if there are no revisions for the daemon.
+func GetLatestKeaConfigRevision(dbi dbops.DBI, daemonID int64) (*KeaConfigRevision, error) {
+	revision := &KeaConfigRevision{}
+	err := dbi.Model(revision).
+		Relation("User").
+		Where("kea_config_revision.daemon_id = ?", daemonID).
+		OrderExpr("kea_config_revision.id DESC").
+		Limit(1).
+		Select()
+	if err != nil {
+		if errors.Is(err, pg.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, pkgerrors.Wrapf(err, "problem getting latest config revision for daemon %d", daemonID)
+	}
+	return revision, nil
+}
+
+// Returns the configuration revision by ID or nil if the revision does
+// not exist.
+func GetKeaConfigRevisionByID(dbi dbops.DBI, id int64) (*KeaConfigRevision, error) {
+	revision := &KeaConfigRevision{}
+	err := dbi.Model(revision).
+		Relation("User").
+		Where("kea_config_revision.id = ?", id).
+		Select()
+	if err != nil {
+		if errors.Is(err, pg.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, pkgerrors.Wrapf(err, "problem getting config revision %d", id)
+	}
+	return revision, nil
+}
+
+// Returns the configuration revisions for the daemon, beginning from the
+// most recent one. The configurations are not fetched to limit the amount
+// of the returned data. Use GetKeaConfigRevisionByID to fetch a revision
+// with the configuration. The offset and limit values are used for paging
+// the results. The limit of 0 causes the function to return all revisions
+// beginning from the offset. Besides the revisions, this function returns
+// the total number of revisions for the daemon.
+func GetKeaConfigRevisionsByDaemonID(dbi dbops.DBI, offset, limit, daemonID int64) ([]KeaConfigRevision, int64, error) {
+	var revisions []KeaConfigRevision
+	q := dbi.Model(&revisions).
+		ExcludeColumn("config").
+		Relation("User").
+		Where("kea_config_revision.daemon_id = ?", daemonID).
+		OrderExpr("kea_config_revision.id DESC").
+		Offset(int(offset))
+
+	if limit != 0 {
+		q = q.Limit(int(limit))
+	}
+
+	total, err := q.SelectAndCount()
+	if err != nil && !errors.Is(err, pg.ErrNoRows) {
+		return revisions, 0, pkgerrors.Wrapf(err, "problem getting config revisions for daemon %d", daemonID)
+	}
+	return revisions, int64(total), nil
+}
@@ -0,0 +1,180 @@
+package dbmodel
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	dbtest "isc.org/stork/server/database/test"
+)
+
+// Test that the configuration revisions are added only when the
+// configuration changes and that they can be fetched from the database.
+func TestAddKeaConfigRevision(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	daemon, _, err := addTestDaemons(db)
+	require.NoError(t, err)
+
+	// There are no revisions yet.
+	latest, err := GetLatestKeaConfigRevision(db, daemon.ID)
+	require.NoError(t, err)
+	require.Nil(t, latest)
+
+	err = daemon.SetConfigFromJSON(`{"Dhcp4": {"valid-lifetime": 1000}}`)
+	require.NoError(t, err)
+
+	revision, err := AddKeaConfigRevision(db, daemon)
+	require.NoError(t, err)
+	require.NotNil(t, revision)
+	require.NotZero(t, revision.ID)
+
+	// The configuration hasn't changed, so the revision shouldn't be added.
+	revision, err = AddKeaConfigRevision(db, daemon)
+	require.NoError(t, err)
+	require.Nil(t, revision)
+
+	// Change the configuration.
+	err = daemon.SetConfigFromJSON(`{"Dhcp4": {"valid-lifetime": 2000}}`)
+	require.NoError(t, err)
+
+	revision, err = AddKeaConfigRevision(db, daemon)
+	require.NoError(t, err)
+	require.NotNil(t, revision)
+
+	// The latest revision should hold the most recent configuration.
+	latest, err = GetLatestKeaConfigRevision(db, daemon.ID)
+	require.NoError(t, err)
+	require.NotNil(t, latest)
+	require.Equal(t, revision.ID, latest.ID)
+	require.Equal(t, daemon.KeaDaemon.ConfigHash, latest.ConfigHash)
+	require.NotNil(t, latest.Config)
+	require.EqualValues(t, 2000, *latest.Config.GetValidLifetimeParameters().ValidLifetime)
+	require.Zero(t, latest.UserID)
+	require.Nil(t, latest.User)
+	require.Empty(t, latest.Operation)
+
+	// Get the revisions for the daemon. The most recent revision goes first.
+	revisions, total, err := GetKeaConfigRevisionsByDaemonID(db, 0, 0, daemon.ID)
+	require.NoError(t, err)
+	require.EqualValues(t, 2, total)
+	require.Len(t, revisions, 2)
+	require.Equal(t, revision.ID, revisions[0].ID)
+	require.Greater(t, revisions[0].ID, revisions[1].ID)
+	// The configurations are not fetched.
+	require.Nil(t, revisions[0].Config)
+	require.Nil(t, revisions[1].Config)
+
+	// Get the revisions with paging.
+	revisions, total, err = GetKeaConfigRevisionsByDaemonID(db, 1, 1, daemon.ID)
+	require.NoError(t, err)
+	require.EqualValues(t, 2, total)
+	require.Len(t, revisions, 1)
+	require.NotEqual(t, revision.ID, revisions[0].ID)
+
+	// Get the older revision by ID.
+	revision, err = GetKeaConfigRevisionByID(db, revisions[0].ID)
+	require.NoError(t, err)
+	require.NotNil(t, revision)
+	require.EqualValues(t, 1000, *revision.Config.GetValidLifetimeParameters().ValidLifetime)
+
+	// Non-existing revision.
+	revision, err = GetKeaConfigRevisionByID(db, revisions[0].ID+100)
+	require.NoError(t, err)
+	require.Nil(t, revision)
+}
+
+// Test that the revision is associated with the cause of the configuration
+// change and that the cause is consumed by the revision.
+func TestAddKeaConfigRevisionWithCause(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	user := &SystemUser{
+		Login:    "test",
+		Lastname: "test",
+		Name:     "test",
+	}
+	_, err := CreateUser(db, user)
+	require.NoError(t, err)
+
+	daemon, _, err := addTestDaemons(db)
+	require.NoError(t, err)
+
+	// The latest cause should replace the previous one.
+	err = AddKeaConfigRevisionCause(db, daemon.ID, 0, "host_add")
+	require.NoError(t, err)
+	err = AddKeaConfigRevisionCause(db, daemon.ID, int64(user.ID), "client_class_add")
+	require.NoError(t, err)
+
+	err = daemon.SetConfigFromJSON(`{"Dhcp4": {"client-classes": [{"name": "foo"}]}}`)
+	require.NoError(t, err)
+
+	revision, err := AddKeaConfigRevision(db, daemon)
+	require.NoError(t, err)
+	require.NotNil(t, revision)
+
+	revision, err = GetKeaConfigRevisionByID(db, revision.ID)
+	require.NoError(t, err)
+	require.NotNil(t, revision)
+	require.EqualValues(t, user.ID, revision.UserID)
+	require.NotNil(t, revision.User)
+	require.Equal(t, "test", revision.User.Login)
+	require.Equal(t, "client_class_add", revision.Operation)
+
+	// The cause should have been consumed.
+	err = daemon.SetConfigFromJSON(`{"Dhcp4": {}}`)
+	require.NoError(t, err)
+
+	revision, err = AddKeaConfigRevision(db, daemon)
+	require.NoError(t, err)
+	require.NotNil(t, revision)
+	require.Zero(t, revision.UserID)
+	require.Empty(t, revision.Operation)
+}
+
+// Test that adding a revision for a daemon without configuration fails.
+func TestAddKeaConfigRevisionNoConfig(t *testing.T) {
+	daemon := NewKeaDaemon(DaemonNameDHCPv4, true)
+	_, err := AddKeaConfigRevision(nil, daemon)
+	require.Error(t, err)
+}
+
+// Test that the oldest configuration revisions are removed when the number
+// of the revisions exceeds the limit.
+func TestAddKeaConfigRevisionLimit(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	daemon, _, err := addTestDaemons(db)
+	require.NoError(t, err)
+
+	var firstRevisionID int64
+	for i := 0; i <= KeaConfigRevisionsLimit; i++ {
+		err = daemon.SetConfigFromJSON(fmt.Sprintf(`{"Dhcp4": {"valid-lifetime": %d}}`, 1000+i))
+		require.NoError(t, err)
+		revision, err := AddKeaConfigRevision(db, daemon)
+		require.NoError(t, err)
+		require.NotNil(t, revision)
+		if i == 0 {
+			firstRevisionID = revision.ID
+		}
+	}
+
+	revisions, total, err := GetKeaConfigRevisionsByDaemonID(db, 0, 0, daemon.ID)
+	require.NoError(t, err)
+	require.EqualValues(t, KeaConfigRevisionsLimit, total)
+	require.Len(t, revisions, KeaConfigRevisionsLimit)
+
+	// The oldest revision should have been removed.
+	revision, err := GetKeaConfigRevisionByID(db, firstRevisionID)
+	require.NoError(t, err)
+	require.Nil(t, revision)
+
+	// The most recent revision should be retained.
+	latest, err := GetLatestKeaConfigRevision(db, daemon.ID)
+	require.NoError(t, err)
+	require.NotNil(t, latest)
+	require.EqualValues(t, 1000+KeaConfigRevisionsLimit, *latest.Config.GetValidLifetimeParameters().ValidLifetime)
+}
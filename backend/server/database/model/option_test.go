@@ -44,7 +44,7 @@ func TestNewDHCPOptionFromKea(t *testing.T) {
 	optionData := keaconfig.SingleOptionData{
 		AlwaysSend: true,
 		Code:       23,
-		CSVFormat:  true,
+		CSVFormat:  storkutil.Ptr(true),
 		Data:       "8",
 		Name:       "option-foo",
 		Space:      dhcpmodel.DHCPv4OptionSpace,
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/go-pg/pg/v10"
+	"github.com/go-pg/pg/v10/orm"
 	pkgerrors "github.com/pkg/errors"
 	dbops "isc.org/stork/server/database"
 )
@@ -32,6 +33,19 @@ type ScheduledConfigChange struct {
 	Error    string
 }
 
+// Status of the scheduled config change.
+type ScheduledConfigChangeStatus string
+
+// Valid statuses of the scheduled config change.
+const (
+	// The config change awaits execution.
+	ScheduledConfigChangeStatusPending ScheduledConfigChangeStatus = "pending"
+	// The config change has been executed successfully.
+	ScheduledConfigChangeStatusExecuted ScheduledConfigChangeStatus = "executed"
+	// The config change has been executed but an error occurred.
+	ScheduledConfigChangeStatusFailed ScheduledConfigChangeStatus = "failed"
+)
+
 // Represents a single config update belonging to a config change.
 type ConfigUpdate struct {
 	// Type of the configured daemon, e.g. "kea".
@@ -58,6 +72,19 @@ func (c ScheduledConfigChange) HasKeaUpdates() bool {
 	return false
 }
 
+// Returns the status of the config change determined from the executed
+// flag and the error text.
+func (c ScheduledConfigChange) GetStatus() ScheduledConfigChangeStatus {
+	switch {
+	case !c.Executed:
+		return ScheduledConfigChangeStatusPending
+	case len(c.Error) > 0:
+		return ScheduledConfigChangeStatusFailed
+	default:
+		return ScheduledConfigChangeStatusExecuted
+	}
+}
+
 // Creates new config update instance.
 func NewConfigUpdate(target AppType, operation string, daemonIDs ...int64) *ConfigUpdate {
 	return &ConfigUpdate{
@@ -100,13 +127,76 @@ func GetScheduledConfigChanges(dbi dbops.DBI) ([]ScheduledConfigChange, error) {
 	return changes, err
 }
 
+// Returns the scheduled config change by ID. It returns nil if the change
+// does not exist. The returned change includes the user who scheduled it.
+func GetScheduledConfigChangeByID(dbi dbops.DBI, changeID int64) (*ScheduledConfigChange, error) {
+	change := &ScheduledConfigChange{}
+	err := dbi.Model(change).
+		Relation("User").
+		Where("scheduled_config_change.id = ?", changeID).
+		Select()
+	if err != nil {
+		if errors.Is(err, pg.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, pkgerrors.Wrapf(err, "problem getting scheduled config change with id %d", changeID)
+	}
+	return change, nil
+}
+
+// Returns a page of the scheduled config changes ordered by deadline. The
+// status parameter is optional. If it is specified, only the changes having
+// this status are returned. The returned changes include the users who
+// scheduled them. The second returned value is the total number of the
+// changes matching the status.
+func GetScheduledConfigChangesByPage(dbi dbops.DBI, offset, limit int64, status *ScheduledConfigChangeStatus) ([]ScheduledConfigChange, int64, error) {
+	var changes []ScheduledConfigChange
+	q := dbi.Model(&changes).
+		Relation("User")
+
+	if status != nil {
+		switch *status {
+		case ScheduledConfigChangeStatusPending:
+			q = q.Where("scheduled_config_change.executed = ?", false)
+		case ScheduledConfigChangeStatusExecuted:
+			q = q.Where("scheduled_config_change.executed = ?", true).
+				WhereGroup(func(q *orm.Query) (*orm.Query, error) {
+					return q.WhereOr("scheduled_config_change.error IS NULL").
+						WhereOr("scheduled_config_change.error = ''"), nil
+				})
+		case ScheduledConfigChangeStatusFailed:
+			q = q.Where("scheduled_config_change.executed = ?", true).
+				Where("scheduled_config_change.error IS NOT NULL").
+				Where("scheduled_config_change.error != ''")
+		default:
+			return changes, 0, pkgerrors.Errorf("invalid scheduled config change status %s", *status)
+		}
+	}
+
+	q = q.OrderExpr("scheduled_config_change.deadline_at ASC").
+		OrderExpr("scheduled_config_change.id ASC").
+		Offset(int(offset))
+
+	if limit != 0 {
+		q = q.Limit(int(limit))
+	}
+
+	total, err := q.SelectAndCount()
+	if err != nil && !errors.Is(err, pg.ErrNoRows) {
+		return changes, 0, pkgerrors.Wrapf(err, "problem getting scheduled config changes")
+	}
+	return changes, int64(total), nil
+}
+
 // Returns scheduled and not executed config changes which deadline has expired.
+// The returned changes include the users who scheduled them.
 func GetDueConfigChanges(dbi dbops.DBI) ([]ScheduledConfigChange, error) {
 	var changes []ScheduledConfigChange
 	err := dbi.Model(&changes).
-		OrderExpr("deadline_at ASC").
-		Where("executed = ?", false).
-		Where("deadline_at < now() at time zone 'UTC'").
+		Relation("User").
+		OrderExpr("scheduled_config_change.deadline_at ASC").
+		Where("scheduled_config_change.executed = ?", false).
+		Where("scheduled_config_change.deadline_at < now() at time zone 'UTC'").
 		Select()
 	if err != nil {
 		if errors.Is(err, pg.ErrNoRows) {
@@ -172,3 +262,45 @@ func DeleteScheduledConfigChange(dbi dbops.DBI, changeID int64) error {
 	}
 	return err
 }
+
+// Sets new deadline for the scheduled config change. Only the config changes
+// that have not been executed yet can be rescheduled. It returns ErrNotExists
+// if the change does not exist or it has already been executed.
+func RescheduleConfigChange(dbi dbops.DBI, changeID int64, deadline time.Time) error {
+	change := &ScheduledConfigChange{
+		ID:         changeID,
+		DeadlineAt: deadline,
+	}
+	result, err := dbi.Model(change).
+		Column("deadline_at").
+		WherePK().
+		Where("executed = ?", false).
+		Update()
+	if err != nil {
+		return pkgerrors.Wrapf(err, "problem with rescheduling config change %d", changeID)
+	}
+	if result.RowsAffected() <= 0 {
+		return pkgerrors.Wrapf(ErrNotExists, "pending config change with id %d does not exist", changeID)
+	}
+	return nil
+}
+
+// Deletes the scheduled config change that has not been executed yet. It
+// returns ErrNotExists if the change does not exist or it has already been
+// executed.
+func CancelScheduledConfigChange(dbi dbops.DBI, changeID int64) error {
+	scc := &ScheduledConfigChange{
+		ID: changeID,
+	}
+	result, err := dbi.Model(scc).
+		WherePK().
+		Where("executed = ?", false).
+		Delete()
+	if err != nil {
+		return pkgerrors.Wrapf(err, "problem with canceling scheduled config change with id %d", changeID)
+	}
+	if result.RowsAffected() <= 0 {
+		return pkgerrors.Wrapf(ErrNotExists, "pending config change with id %d does not exist", changeID)
+	}
+	return nil
+}
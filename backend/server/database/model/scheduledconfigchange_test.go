@@ -403,3 +403,172 @@ func TestHasKeaUpdatesNoKeaUpdate(t *testing.T) {
 	}
 	require.False(t, change.HasKeaUpdates())
 }
+
+// Test determining the status of the scheduled config change.
+func TestScheduledConfigChangeGetStatus(t *testing.T) {
+	change := ScheduledConfigChange{}
+	require.Equal(t, ScheduledConfigChangeStatusPending, change.GetStatus())
+
+	change.Executed = true
+	require.Equal(t, ScheduledConfigChangeStatusExecuted, change.GetStatus())
+
+	change.Error = "error"
+	require.Equal(t, ScheduledConfigChangeStatusFailed, change.GetStatus())
+}
+
+// Test getting a scheduled config change by ID and getting the pages of
+// the scheduled config changes filtered by status.
+func TestGetScheduledConfigChangesByPage(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	// Scheduled config changes must be associated with a user.
+	user := &SystemUser{
+		Login:    "test",
+		Lastname: "test",
+		Name:     "test",
+	}
+	_, err := CreateUser(db, user)
+	require.NoError(t, err)
+	require.NotZero(t, user.ID)
+
+	// Add three config changes. The first one will remain pending, the
+	// second one will be executed successfully and the third one will fail.
+	var changes []*ScheduledConfigChange
+	for i := 0; i < 3; i++ {
+		change := &ScheduledConfigChange{
+			CreatedAt:  storkutil.UTCNow(),
+			DeadlineAt: storkutil.UTCNow().Add(time.Second * time.Duration(10*(i+1))),
+			UserID:     int64(user.ID),
+			Updates: []*ConfigUpdate{
+				NewConfigUpdate(AppTypeKea, "host_add", int64(i+1)),
+			},
+		}
+		err = AddScheduledConfigChange(db, change)
+		require.NoError(t, err)
+		changes = append(changes, change)
+	}
+	err = SetScheduledConfigChangeExecuted(db, changes[1].ID, "")
+	require.NoError(t, err)
+	err = SetScheduledConfigChangeExecuted(db, changes[2].ID, "failed to add host")
+	require.NoError(t, err)
+
+	// Get a single change.
+	returned, err := GetScheduledConfigChangeByID(db, changes[2].ID)
+	require.NoError(t, err)
+	require.NotNil(t, returned)
+	require.Equal(t, ScheduledConfigChangeStatusFailed, returned.GetStatus())
+	require.Equal(t, "failed to add host", returned.Error)
+	require.NotNil(t, returned.User)
+	require.Equal(t, "test", returned.User.Login)
+	require.Len(t, returned.Updates, 1)
+
+	// Non-existing change.
+	returned, err = GetScheduledConfigChangeByID(db, changes[2].ID+1)
+	require.NoError(t, err)
+	require.Nil(t, returned)
+
+	// Get all changes.
+	page, total, err := GetScheduledConfigChangesByPage(db, 0, 0, nil)
+	require.NoError(t, err)
+	require.EqualValues(t, 3, total)
+	require.Len(t, page, 3)
+	for i := range page {
+		require.Equal(t, changes[i].ID, page[i].ID)
+		require.NotNil(t, page[i].User)
+	}
+
+	// Get the second page.
+	page, total, err = GetScheduledConfigChangesByPage(db, 2, 2, nil)
+	require.NoError(t, err)
+	require.EqualValues(t, 3, total)
+	require.Len(t, page, 1)
+	require.Equal(t, changes[2].ID, page[0].ID)
+
+	// Filter by status.
+	for i, status := range []ScheduledConfigChangeStatus{
+		ScheduledConfigChangeStatusPending,
+		ScheduledConfigChangeStatusExecuted,
+		ScheduledConfigChangeStatusFailed,
+	} {
+		page, total, err = GetScheduledConfigChangesByPage(db, 0, 10, &status)
+		require.NoError(t, err)
+		require.EqualValues(t, 1, total)
+		require.Len(t, page, 1)
+		require.Equal(t, changes[i].ID, page[0].ID)
+		require.Equal(t, status, page[0].GetStatus())
+	}
+
+	// Invalid status.
+	status := ScheduledConfigChangeStatus("foo")
+	_, _, err = GetScheduledConfigChangesByPage(db, 0, 10, &status)
+	require.Error(t, err)
+}
+
+// Test that the pending config changes can be rescheduled and canceled.
+func TestRescheduleAndCancelConfigChange(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	// Scheduled config changes must be associated with a user.
+	user := &SystemUser{
+		Login:    "test",
+		Lastname: "test",
+		Name:     "test",
+	}
+	_, err := CreateUser(db, user)
+	require.NoError(t, err)
+	require.NotZero(t, user.ID)
+
+	var changes []*ScheduledConfigChange
+	for i := 0; i < 2; i++ {
+		change := &ScheduledConfigChange{
+			CreatedAt:  storkutil.UTCNow(),
+			DeadlineAt: storkutil.UTCNow().Add(time.Second * 10),
+			UserID:     int64(user.ID),
+			Updates: []*ConfigUpdate{
+				NewConfigUpdate(AppTypeKea, "host_add", 1),
+			},
+		}
+		err = AddScheduledConfigChange(db, change)
+		require.NoError(t, err)
+		changes = append(changes, change)
+	}
+	// The second change has been executed.
+	err = SetScheduledConfigChangeExecuted(db, changes[1].ID, "")
+	require.NoError(t, err)
+
+	// Reschedule the pending change.
+	deadline := storkutil.UTCNow().Add(time.Hour).Truncate(time.Second)
+	err = RescheduleConfigChange(db, changes[0].ID, deadline)
+	require.NoError(t, err)
+
+	returned, err := GetScheduledConfigChangeByID(db, changes[0].ID)
+	require.NoError(t, err)
+	require.NotNil(t, returned)
+	require.Equal(t, deadline, returned.DeadlineAt)
+	require.False(t, returned.Executed)
+
+	// The executed change cannot be rescheduled nor canceled.
+	err = RescheduleConfigChange(db, changes[1].ID, deadline)
+	require.ErrorIs(t, pkgerrors.Cause(err), ErrNotExists)
+	err = CancelScheduledConfigChange(db, changes[1].ID)
+	require.ErrorIs(t, pkgerrors.Cause(err), ErrNotExists)
+
+	// Cancel the pending change.
+	err = CancelScheduledConfigChange(db, changes[0].ID)
+	require.NoError(t, err)
+	returned, err = GetScheduledConfigChangeByID(db, changes[0].ID)
+	require.NoError(t, err)
+	require.Nil(t, returned)
+
+	// It cannot be canceled again.
+	err = CancelScheduledConfigChange(db, changes[0].ID)
+	require.ErrorIs(t, pkgerrors.Cause(err), ErrNotExists)
+
+	// The executed change should remain.
+	all, err := GetScheduledConfigChanges(db)
+	require.NoError(t, err)
+	require.Len(t, all, 1)
+	require.Equal(t, changes[1].ID, all[0].ID)
+}
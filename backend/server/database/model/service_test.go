@@ -470,6 +470,41 @@ func TestGetServicesByAppID(t *testing.T) {
 	require.Equal(t, services[3].Name, appServices[2].Name)
 }
 
+// Test that the services can be fetched by the daemon ID.
+func TestGetServicesByDaemonID(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	services := addTestServices(t, db)
+	require.GreaterOrEqual(t, len(services), 4)
+
+	// The daemon belongs to the service1 and service3.
+	daemonServices, err := GetDetailedServicesByDaemonID(db, services[0].Daemons[0].ID)
+	require.NoError(t, err)
+	require.Len(t, daemonServices, 2)
+	require.Equal(t, services[0].Name, daemonServices[0].Name)
+	require.Equal(t, services[2].Name, daemonServices[1].Name)
+	require.True(t, daemonArraysMatch(daemonServices[0].Daemons, services[0].Daemons))
+	require.NotNil(t, daemonServices[0].Daemons[0].App)
+
+	// Associate the daemon with another service.
+	err = AddDaemonToService(db, services[1].ID, services[0].Daemons[0])
+	require.NoError(t, err)
+
+	daemonServices, err = GetDetailedServicesByDaemonID(db, services[0].Daemons[0].ID)
+	require.NoError(t, err)
+	require.Len(t, daemonServices, 3)
+	require.Equal(t, services[0].Name, daemonServices[0].Name)
+	require.Equal(t, services[1].Name, daemonServices[1].Name)
+	require.NotNil(t, daemonServices[1].HAService)
+	require.Equal(t, services[2].Name, daemonServices[2].Name)
+
+	// Non-existing daemon.
+	daemonServices, err = GetDetailedServicesByDaemonID(db, 12345)
+	require.NoError(t, err)
+	require.Empty(t, daemonServices)
+}
+
 // Test that it is possible to get apps by type and get the services
 // returned along with them.
 func TestGetAppWithServices(t *testing.T) {
@@ -42,11 +42,14 @@ func InitializeSettings(db *pg.DB, initialPullerInterval int64) error {
 	// Init puller intervals.
 	longInterval := "60"
 	mediumInterval := "30"
+	// Checking the DNSSEC state requires transferring the zones.
+	dnssecInterval := "3600"
 
 	if initialPullerInterval > 0 {
 		interval := fmt.Sprint(initialPullerInterval)
 		longInterval = interval
 		mediumInterval = interval
+		dnssecInterval = interval
 	}
 
 	// list of all stork settings with default values
@@ -76,6 +79,23 @@ func InitializeSettings(db *pg.DB, initialPullerInterval int64) error {
 			ValType: SettingValTypeInt,
 			Value:   mediumInterval,
 		},
+		{
+			Name:    "dnssec_puller_interval", // in seconds
+			ValType: SettingValTypeInt,
+			Value:   dnssecInterval,
+		},
+		{
+			Name:    "zone_serial_drift_puller_interval", // in seconds
+			ValType: SettingValTypeInt,
+			Value:   longInterval,
+		},
+		{
+			// The time after which the secondary zone lagging behind
+			// the primary zone is reported as drifted.
+			Name:    "zone_serial_drift_threshold", // in seconds
+			ValType: SettingValTypeInt,
+			Value:   "3600",
+		},
 		{
 			Name:    "grafana_url",
 			ValType: SettingValTypeStr,
@@ -107,6 +127,13 @@ func InitializeSettings(db *pg.DB, initialPullerInterval int64) error {
 			ValType: SettingValTypeBool,
 			Value:   "true",
 		},
+		{
+			// Write the configuration changes to the Kea configuration
+			// files after committing them.
+			Name:    "kea_config_auto_write",
+			ValType: SettingValTypeBool,
+			Value:   "true",
+		},
 	}
 
 	// Check if there are new settings vs existing ones. Add new ones to DB.
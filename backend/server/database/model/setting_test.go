@@ -57,6 +57,10 @@ func TestInitializeSettings(t *testing.T) {
 	require.NoError(t, err)
 	require.True(t, boolVal)
 
+	boolVal, err = GetSettingBool(db, "kea_config_auto_write")
+	require.NoError(t, err)
+	require.True(t, boolVal)
+
 	valStr, err := GetSettingStr(db, "grafana_url")
 	require.NoError(t, err)
 	require.Empty(t, valStr)
@@ -140,6 +144,9 @@ func TestInitializeSettingsWithInterval(t *testing.T) {
 	hostsInterval, err5 := GetSettingInt(db, "kea_hosts_puller_interval")
 	appsStateInterval, err6 := GetSettingInt(db, "apps_state_puller_interval")
 	haStatusInterval, err7 := GetSettingInt(db, "kea_status_puller_interval")
+	dnssecInterval, err8 := GetSettingInt(db, "dnssec_puller_interval")
+	serialDriftInterval, err9 := GetSettingInt(db, "zone_serial_drift_puller_interval")
+	serialDriftThreshold, err10 := GetSettingInt(db, "zone_serial_drift_threshold")
 
 	// Assert
 	require.NoError(t, err1)
@@ -149,12 +156,19 @@ func TestInitializeSettingsWithInterval(t *testing.T) {
 	require.NoError(t, err5)
 	require.NoError(t, err6)
 	require.NoError(t, err7)
+	require.NoError(t, err8)
+	require.NoError(t, err9)
+	require.NoError(t, err10)
 
 	require.EqualValues(t, 42, bind9Interval)
 	require.EqualValues(t, 42, keaStatsInterval)
 	require.EqualValues(t, 42, hostsInterval)
 	require.EqualValues(t, 42, appsStateInterval)
 	require.EqualValues(t, 42, haStatusInterval)
+	require.EqualValues(t, 42, dnssecInterval)
+	require.EqualValues(t, 42, serialDriftInterval)
+	// The threshold is not an interval.
+	require.EqualValues(t, 3600, serialDriftThreshold)
 }
 
 // Check getting and setting settings.
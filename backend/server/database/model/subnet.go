@@ -223,6 +223,16 @@ func (s *Subnet) GetID(daemonID int64) int64 {
 	return 0
 }
 
+// Returns local subnet instance for a daemon ID.
+func (s *Subnet) GetLocalSubnet(daemonID int64) *LocalSubnet {
+	for _, ls := range s.LocalSubnets {
+		if ls.DaemonID == daemonID {
+			return ls
+		}
+	}
+	return nil
+}
+
 // Returns the Kea DHCP parameters for the subnet configured in the specified daemon.
 func (s *Subnet) GetKeaParameters(daemonID int64) *keaconfig.SubnetParameters {
 	for _, ls := range s.LocalSubnets {
@@ -1670,6 +1670,26 @@ func TestSubnetGetID(t *testing.T) {
 	require.Zero(t, subnet.GetID(1000))
 }
 
+// Test getting the local subnet for a daemon.
+func TestSubnetGetLocalSubnet(t *testing.T) {
+	subnet := Subnet{
+		LocalSubnets: []*LocalSubnet{
+			{
+				LocalSubnetID: 15,
+				DaemonID:      110,
+			},
+			{
+				LocalSubnetID: 16,
+				DaemonID:      111,
+			},
+		},
+	}
+	localSubnet := subnet.GetLocalSubnet(111)
+	require.NotNil(t, localSubnet)
+	require.EqualValues(t, 16, localSubnet.LocalSubnetID)
+	require.Nil(t, subnet.GetLocalSubnet(1000))
+}
+
 // Test implementation of the keaconfig.Subnet interface (GetKeaParameters()
 // function).
 func TestSubnetGetKeaParameters(t *testing.T) {
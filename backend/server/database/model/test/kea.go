@@ -54,3 +54,8 @@ func (kea *Kea) NewKeaDHCPv4Server() (*KeaServer, error) {
 func (kea *Kea) NewKeaDHCPv6Server() (*KeaServer, error) {
 	return kea.newServer(dbmodel.DaemonNameDHCPv6)
 }
+
+// Creates D2 server instance for the Kea app.
+func (kea *Kea) NewKeaD2Server() (*KeaServer, error) {
+	return kea.newServer(dbmodel.DaemonNameD2)
+}
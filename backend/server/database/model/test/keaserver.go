@@ -37,6 +37,19 @@ func NewKeaDHCPv6Server(db *pg.DB) (*KeaServer, error) {
 	return dhcp6, nil
 }
 
+// Creates new Kea app and a D2 server daemon in the database.
+func NewKeaD2Server(db *pg.DB) (*KeaServer, error) {
+	kea, err := NewKea(db)
+	if err != nil {
+		return nil, err
+	}
+	d2, err := kea.NewKeaD2Server()
+	if err != nil {
+		return nil, err
+	}
+	return d2, nil
+}
+
 // Applies a new configuration in the Kea server.
 func (server *KeaServer) Configure(config string) error {
 	d, err := dbmodel.GetDaemonByID(server.kea.machine.db, server.ID)
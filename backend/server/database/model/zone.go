@@ -83,6 +83,9 @@ type GetZonesFilter struct {
 	AppType *string
 	// Filter by class (typically, IN).
 	Class *string
+	// Filter by the DNSSEC signatures expiring within the
+	// ZoneDNSSECExpiringSoonThreshold.
+	DNSSECExpiringSoon *bool
 	// Filter by lower bound zone.
 	LowerBound *string
 	// Limit the number of zones returned.
@@ -91,6 +94,13 @@ type GetZonesFilter struct {
 	Offset *int
 	// Filter by partial or exact zone serial.
 	Serial *string
+	// Filter by the secondary zones lagging behind the primary zones for
+	// longer than the SerialDriftThreshold.
+	SerialDrift *bool
+	// The serial drift threshold used by the SerialDrift filter.
+	SerialDriftThreshold time.Duration
+	// Filter signed or unsigned zones.
+	Signed *bool
 	// Filter by zone type (e.g., primary or secondary).
 	Types *GetZonesFilterZoneTypes
 	// Filter by partial zone name, app name or view.
@@ -128,6 +138,20 @@ type LocalZone struct {
 	Type     string
 	LoadedAt time.Time
 
+	// The time when the zone was transferred from the server and the
+	// serial of the transferred zone. They are nil if the zone has not
+	// been transferred yet.
+	ZoneTransferAt     *time.Time
+	ZoneTransferSerial *int64
+
+	// DNSSEC state of the zone. It is nil if the state has not been
+	// determined yet.
+	DNSSEC *ZoneDNSSEC `pg:"dnssec"`
+
+	// Serial drift of the secondary zone. It is nil if the zone is not
+	// lagging behind the primary zone.
+	SerialDrift *ZoneSerialDrift
+
 	Daemon *Daemon `pg:"rel:has-one"`
 	Zone   *Zone   `pg:"rel:has-one"`
 }
@@ -218,7 +242,7 @@ func GetZones(db pg.DBI, filter *GetZonesFilter, relations ...ZoneRelation) ([]*
 		q = q.Offset(*filter.Offset)
 	}
 	// Join relations required for filtering.
-	if filter.Serial != nil || filter.Class != nil || filter.Types != nil && filter.Types.IsAnySpecified() || filter.AppID != nil || filter.AppType != nil || filter.Text != nil {
+	if filter.Serial != nil || filter.Class != nil || filter.Types != nil && filter.Types.IsAnySpecified() || filter.AppID != nil || filter.AppType != nil || filter.Text != nil || filter.Signed != nil || filter.DNSSECExpiringSoon != nil || filter.SerialDrift != nil {
 		q = q.Join("JOIN local_zone AS lz").JoinOn("lz.zone_id = zone.id")
 		if filter.AppID != nil || filter.AppType != nil || filter.Text != nil {
 			q = q.Join("JOIN daemon AS d").JoinOn("d.id = lz.daemon_id").
@@ -240,6 +264,35 @@ func GetZones(db pg.DBI, filter *GetZonesFilter, relations ...ZoneRelation) ([]*
 			q = q.WhereIn("lz.type IN (?)", types)
 		}
 	}
+	// Filter by DNSSEC state.
+	if filter.Signed != nil {
+		q = q.Where("COALESCE(CAST(lz.dnssec->>'Signed' AS BOOLEAN), FALSE) = ?", *filter.Signed)
+	}
+	if filter.DNSSECExpiringSoon != nil {
+		expiresAt := "CAST(lz.dnssec->>'RRSIGExpiresAt' AS TIMESTAMPTZ)"
+		expiresBefore := storkutil.UTCNow().Add(ZoneDNSSECExpiringSoonThreshold)
+		if *filter.DNSSECExpiringSoon {
+			q = q.Where(expiresAt+" < ?", expiresBefore)
+		} else {
+			q = q.WhereGroup(func(q *pg.Query) (*pg.Query, error) {
+				return q.WhereOr(expiresAt+" IS NULL").
+					WhereOr(expiresAt+" >= ?", expiresBefore), nil
+			})
+		}
+	}
+	// Filter by serial drift.
+	if filter.SerialDrift != nil {
+		driftSince := "CAST(lz.serial_drift->>'Since' AS TIMESTAMPTZ)"
+		driftSinceBefore := storkutil.UTCNow().Add(-filter.SerialDriftThreshold)
+		if *filter.SerialDrift {
+			q = q.Where(driftSince+" <= ?", driftSinceBefore)
+		} else {
+			q = q.WhereGroup(func(q *pg.Query) (*pg.Query, error) {
+				return q.WhereOr(driftSince+" IS NULL").
+					WhereOr(driftSince+" > ?", driftSinceBefore), nil
+			})
+		}
+	}
 	// Filter by app ID.
 	if filter.AppID != nil {
 		q = q.Where("a.id = ?", *filter.AppID)
@@ -264,6 +317,24 @@ func GetZones(db pg.DBI, filter *GetZonesFilter, relations ...ZoneRelation) ([]*
 	return zones, count, nil
 }
 
+// Returns the zone by ID with optional relations. It returns nil if the
+// zone does not exist.
+func GetZoneByID(dbi pg.DBI, id int64, relations ...ZoneRelation) (*Zone, error) {
+	zone := &Zone{}
+	q := dbi.Model(zone)
+	for _, relation := range relations {
+		q = q.Relation(string(relation))
+	}
+	err := q.Where("zone.id = ?", id).Select()
+	if err != nil {
+		if errors.Is(err, pg.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, errors.Wrapf(err, "failed to select zone with ID %d from the database", id)
+	}
+	return zone, nil
+}
+
 // Deletes zones which are not associated with any daemons. Returns deleted zone
 // count and an error.
 func DeleteOrphanedZones(dbi dbops.DBI) (int64, error) {
@@ -287,6 +358,22 @@ func DeleteLocalZones(db pg.DBI, daemonID int64) error {
 	return errors.Wrapf(err, "failed to delete local zones for daemon id %d", daemonID)
 }
 
+// Sets the serial of the local zone. It is used when the serial is
+// refreshed by querying the SOA record of the zone from the DNS server.
+func UpdateLocalZoneSerial(dbi pg.DBI, localZoneID, serial int64) error {
+	result, err := dbi.Model((*LocalZone)(nil)).
+		Set("serial = ?", serial).
+		Where("id = ?", localZoneID).
+		Update()
+	if err != nil {
+		return errors.Wrapf(err, "failed to update the serial of the local zone %d", localZoneID)
+	}
+	if result.RowsAffected() <= 0 {
+		return errors.Wrapf(ErrNotExists, "local zone %d does not exist", localZoneID)
+	}
+	return nil
+}
+
 // go-pg hook triggered before zone insert into the database. It sets the
 // rname from name. The rname column is used for ordering the zones in DNS
 // order.
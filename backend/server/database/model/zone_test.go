@@ -712,6 +712,27 @@ func TestDeleteOrphanedZones(t *testing.T) {
 	require.Empty(t, zones)
 }
 
+// Test updating the serial of the local zone.
+func TestUpdateLocalZoneSerial(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	zone := addTestZoneForRRs(t, db)
+	localZone := zone.LocalZones[0]
+
+	err := UpdateLocalZoneSerial(db, localZone.ID, localZone.Serial+1)
+	require.NoError(t, err)
+
+	zone, err = GetZoneByID(db, zone.ID, ZoneRelationLocalZones)
+	require.NoError(t, err)
+	require.Len(t, zone.LocalZones, 1)
+	require.Equal(t, localZone.Serial+1, zone.LocalZones[0].Serial)
+
+	// Updating a non-existing local zone should fail.
+	err = UpdateLocalZoneSerial(db, localZone.ID+1000, 1)
+	require.ErrorIs(t, err, ErrNotExists)
+}
+
 // Test the "before insert" hook for the zone.
 func TestZoneBeforeInsert(t *testing.T) {
 	zone := &Zone{
@@ -0,0 +1,101 @@
+package dbmodel
+
+import (
+	"time"
+
+	"github.com/go-pg/pg/v10"
+	"github.com/pkg/errors"
+)
+
+// The DNSSEC signatures expiring within this time are considered
+// expiring soon. BIND 9 refreshes the signatures 5 days before their
+// expiration by default. The signatures of a correctly maintained
+// zone should never expire within this time.
+const ZoneDNSSECExpiringSoonThreshold = 3 * 24 * time.Hour
+
+// Authenticated denial of existence method used in a signed zone.
+type ZoneDNSSECDenial string
+
+const (
+	ZoneDNSSECDenialNSEC  ZoneDNSSECDenial = "NSEC"
+	ZoneDNSSECDenialNSEC3 ZoneDNSSECDenial = "NSEC3"
+)
+
+// Represents a DNSKEY record of a signed zone.
+type ZoneDNSSECKey struct {
+	KeyTag    uint16
+	Algorithm uint8
+	Flags     uint16
+}
+
+// Represents a DS record of a signed zone. The DS records are published
+// in the parent zone. They are derived from the CDS records or from the
+// key signing keys of the zone.
+type ZoneDNSSECDS struct {
+	KeyTag     uint16
+	Algorithm  uint8
+	DigestType uint8
+}
+
+// Represents the DNSSEC state of a zone on a DNS server. It is held in
+// the "dnssec" column of the "local_zone" table as JSONB.
+type ZoneDNSSEC struct {
+	// Indicates whether the zone contains DNSKEY and RRSIG records.
+	Signed bool
+	// DNSKEY records at the zone apex.
+	Keys []ZoneDNSSECKey
+	// DS records for the parent zone.
+	DS []ZoneDNSSECDS
+	// NSEC or NSEC3. It is empty for the unsigned zones.
+	Denial ZoneDNSSECDenial
+	// The earliest expiration time of the RRSIG records in the zone.
+	RRSIGExpiresAt *time.Time
+	// Output of the rndc dnssec -status command.
+	RndcStatus string
+	// The zone serial for which the DNSSEC state was determined.
+	Serial int64
+	// The time when the DNSSEC state was determined.
+	CheckedAt time.Time
+}
+
+// Checks if the earliest RRSIG record in the zone expires within the
+// ZoneDNSSECExpiringSoonThreshold from the specified time.
+func (dnssec *ZoneDNSSEC) IsExpiringSoon(now time.Time) bool {
+	return dnssec != nil && dnssec.Signed && dnssec.RRSIGExpiresAt != nil &&
+		dnssec.RRSIGExpiresAt.Before(now.Add(ZoneDNSSECExpiringSoonThreshold))
+}
+
+// Sets the DNSSEC state of the local zone.
+func UpdateLocalZoneDNSSEC(dbi pg.DBI, localZoneID int64, dnssec *ZoneDNSSEC) error {
+	result, err := dbi.Model((*LocalZone)(nil)).
+		Set("dnssec = ?", dnssec).
+		Where("id = ?", localZoneID).
+		Update()
+	if err != nil {
+		return errors.Wrapf(err, "failed to update the DNSSEC state of the local zone %d", localZoneID)
+	}
+	if result.RowsAffected() <= 0 {
+		return errors.Wrapf(ErrNotExists, "local zone %d does not exist", localZoneID)
+	}
+	return nil
+}
+
+// Returns the local zones of the specified types for which the DNSSEC state
+// should be checked. The zones are returned with the Zone relation. Whether
+// the DNSSEC state of a zone has to be determined again depends on its
+// current serial and the signatures expiration time, so the caller must
+// check it for each returned zone.
+func GetLocalZonesForDNSSECCheck(dbi pg.DBI, zoneTypes ...string) ([]*LocalZone, error) {
+	var localZones []*LocalZone
+	q := dbi.Model(&localZones).
+		Relation("Zone").
+		OrderExpr("local_zone.id ASC")
+	if len(zoneTypes) > 0 {
+		q = q.WhereIn("local_zone.type IN (?)", zoneTypes)
+	}
+	err := q.Select()
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to select local zones for the DNSSEC check from the database")
+	}
+	return localZones, nil
+}
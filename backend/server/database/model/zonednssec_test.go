@@ -0,0 +1,146 @@
+package dbmodel
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	dbtest "isc.org/stork/server/database/test"
+	storkutil "isc.org/stork/util"
+)
+
+// Test checking whether the DNSSEC signatures expire soon.
+func TestZoneDNSSECIsExpiringSoon(t *testing.T) {
+	now := time.Date(2025, 3, 15, 10, 0, 0, 0, time.UTC)
+
+	var dnssec *ZoneDNSSEC
+	require.False(t, dnssec.IsExpiringSoon(now))
+
+	dnssec = &ZoneDNSSEC{}
+	require.False(t, dnssec.IsExpiringSoon(now))
+
+	dnssec.Signed = true
+	require.False(t, dnssec.IsExpiringSoon(now))
+
+	dnssec.RRSIGExpiresAt = storkutil.Ptr(now.Add(ZoneDNSSECExpiringSoonThreshold + time.Hour))
+	require.False(t, dnssec.IsExpiringSoon(now))
+
+	dnssec.RRSIGExpiresAt = storkutil.Ptr(now.Add(ZoneDNSSECExpiringSoonThreshold - time.Hour))
+	require.True(t, dnssec.IsExpiringSoon(now))
+
+	// Expired signatures.
+	dnssec.RRSIGExpiresAt = storkutil.Ptr(now.Add(-time.Hour))
+	require.True(t, dnssec.IsExpiringSoon(now))
+}
+
+// Test updating the DNSSEC state of the local zone and filtering the
+// zones by the DNSSEC state.
+func TestUpdateLocalZoneDNSSEC(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	zone := addTestZoneForRRs(t, db)
+	localZone := zone.LocalZones[0]
+
+	checkedAt := time.Date(2025, 3, 15, 10, 0, 0, 0, time.UTC)
+	expiresAt := storkutil.UTCNow().Add(ZoneDNSSECExpiringSoonThreshold - time.Hour).Truncate(time.Second)
+	err := UpdateLocalZoneDNSSEC(db, localZone.ID, &ZoneDNSSEC{
+		Signed: true,
+		Keys: []ZoneDNSSECKey{
+			{KeyTag: 12345, Algorithm: 13, Flags: 257},
+			{KeyTag: 23456, Algorithm: 13, Flags: 256},
+		},
+		DS: []ZoneDNSSECDS{
+			{KeyTag: 12345, Algorithm: 13, DigestType: 2},
+		},
+		Denial:         ZoneDNSSECDenialNSEC3,
+		RRSIGExpiresAt: &expiresAt,
+		RndcStatus:     "dnssec-policy: default",
+		Serial:         2024031501,
+		CheckedAt:      checkedAt,
+	})
+	require.NoError(t, err)
+
+	zone, err = GetZoneByID(db, zone.ID, ZoneRelationLocalZones)
+	require.NoError(t, err)
+	require.Len(t, zone.LocalZones, 1)
+	dnssec := zone.LocalZones[0].DNSSEC
+	require.NotNil(t, dnssec)
+	require.True(t, dnssec.Signed)
+	require.Len(t, dnssec.Keys, 2)
+	require.EqualValues(t, 12345, dnssec.Keys[0].KeyTag)
+	require.EqualValues(t, 13, dnssec.Keys[0].Algorithm)
+	require.EqualValues(t, 257, dnssec.Keys[0].Flags)
+	require.Len(t, dnssec.DS, 1)
+	require.EqualValues(t, 2, dnssec.DS[0].DigestType)
+	require.Equal(t, ZoneDNSSECDenialNSEC3, dnssec.Denial)
+	require.NotNil(t, dnssec.RRSIGExpiresAt)
+	require.Equal(t, expiresAt, dnssec.RRSIGExpiresAt.UTC())
+	require.Equal(t, "dnssec-policy: default", dnssec.RndcStatus)
+	require.EqualValues(t, 2024031501, dnssec.Serial)
+	require.Equal(t, checkedAt, dnssec.CheckedAt.UTC())
+
+	// Filter signed zones.
+	zones, total, err := GetZones(db, &GetZonesFilter{Signed: storkutil.Ptr(true)})
+	require.NoError(t, err)
+	require.Equal(t, 1, total)
+	require.Len(t, zones, 1)
+
+	zones, total, err = GetZones(db, &GetZonesFilter{Signed: storkutil.Ptr(false)})
+	require.NoError(t, err)
+	require.Zero(t, total)
+	require.Empty(t, zones)
+
+	// Filter zones with signatures expiring soon.
+	zones, total, err = GetZones(db, &GetZonesFilter{DNSSECExpiringSoon: storkutil.Ptr(true)})
+	require.NoError(t, err)
+	require.Equal(t, 1, total)
+	require.Len(t, zones, 1)
+
+	zones, total, err = GetZones(db, &GetZonesFilter{DNSSECExpiringSoon: storkutil.Ptr(false)})
+	require.NoError(t, err)
+	require.Zero(t, total)
+	require.Empty(t, zones)
+
+	// The zone is no longer signed.
+	err = UpdateLocalZoneDNSSEC(db, localZone.ID, &ZoneDNSSEC{
+		CheckedAt: checkedAt,
+	})
+	require.NoError(t, err)
+
+	_, total, err = GetZones(db, &GetZonesFilter{Signed: storkutil.Ptr(false)})
+	require.NoError(t, err)
+	require.Equal(t, 1, total)
+
+	_, total, err = GetZones(db, &GetZonesFilter{DNSSECExpiringSoon: storkutil.Ptr(false)})
+	require.NoError(t, err)
+	require.Equal(t, 1, total)
+
+	// Updating a non-existing local zone should fail.
+	err = UpdateLocalZoneDNSSEC(db, localZone.ID+1000, &ZoneDNSSEC{})
+	require.ErrorIs(t, err, ErrNotExists)
+}
+
+// Test getting the local zones for which the DNSSEC state should be checked.
+func TestGetLocalZonesForDNSSECCheck(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	addTestZoneForRRs(t, db)
+
+	localZones, err := GetLocalZonesForDNSSECCheck(db, "primary", "secondary")
+	require.NoError(t, err)
+	require.Len(t, localZones, 1)
+	require.NotNil(t, localZones[0].Zone)
+	require.Equal(t, "example.com", localZones[0].Zone.Name)
+
+	// Other zone types are excluded.
+	localZones, err = GetLocalZonesForDNSSECCheck(db, "secondary")
+	require.NoError(t, err)
+	require.Empty(t, localZones)
+
+	// All zones are returned when no type is specified.
+	localZones, err = GetLocalZonesForDNSSECCheck(db)
+	require.NoError(t, err)
+	require.Len(t, localZones, 1)
+}
@@ -0,0 +1,149 @@
+package dbmodel
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/go-pg/pg/v10"
+	"github.com/miekg/dns"
+	"github.com/pkg/errors"
+)
+
+// Maximum number of resource records inserted into the database in a
+// single INSERT statement.
+const localZoneRRInsertBatchSize = 1000
+
+// Represents a resource record of a zone transferred from a DNS server.
+// The records are associated with the local zones because the contents
+// of the zone may differ between the servers and views.
+type LocalZoneRR struct {
+	ID          int64
+	LocalZoneID int64
+	Name        string
+	TTL         int64 `pg:"ttl,use_zero"`
+	Class       string
+	Type        string
+	Data        string `pg:",use_zero"`
+}
+
+// Filter used in the GetLocalZoneRRs function for filtering and paging
+// the resource records returned from the database.
+type GetLocalZoneRRsFilter struct {
+	// Paging offset.
+	Offset *int
+	// Limit the number of records returned.
+	Limit *int
+	// Filter by record type (e.g., AAAA).
+	Type *string
+	// Filter by partial owner name.
+	Text *string
+}
+
+// Converts the resource record to the database format. The record data
+// is stored in the presentation format.
+func NewLocalZoneRR(rr dns.RR) *LocalZoneRR {
+	header := rr.Header()
+	return &LocalZoneRR{
+		Name:  header.Name,
+		TTL:   int64(header.Ttl),
+		Class: dns.ClassToString[header.Class],
+		Type:  dns.TypeToString[header.Rrtype],
+		Data:  strings.TrimPrefix(rr.String(), header.String()),
+	}
+}
+
+// Replaces the resource records of the local zone in a transaction. It
+// also sets the zone transfer time and the serial of the transferred zone.
+// The local zone row is locked until the transaction is committed or rolled
+// back, so the concurrent replacements of the records are serialized and
+// don't duplicate the records.
+func replaceLocalZoneRRs(tx *pg.Tx, localZoneID, serial int64, transferAt time.Time, rrs []*LocalZoneRR) error {
+	err := tx.Model(&LocalZone{}).
+		Column("id").
+		Where("id = ?", localZoneID).
+		For("UPDATE").
+		Select()
+	if err != nil {
+		if errors.Is(err, pg.ErrNoRows) {
+			return errors.Wrapf(ErrNotExists, "local zone %d does not exist", localZoneID)
+		}
+		return errors.Wrapf(err, "failed to lock the local zone %d", localZoneID)
+	}
+	_, err = tx.Model((*LocalZoneRR)(nil)).Where("local_zone_id = ?", localZoneID).Delete()
+	if err != nil {
+		return errors.Wrapf(err, "failed to delete resource records of the local zone %d", localZoneID)
+	}
+	for _, rr := range rrs {
+		rr.LocalZoneID = localZoneID
+	}
+	for start := 0; start < len(rrs); start += localZoneRRInsertBatchSize {
+		batch := rrs[start:min(start+localZoneRRInsertBatchSize, len(rrs))]
+		if _, err = tx.Model(&batch).Insert(); err != nil {
+			return errors.Wrapf(err, "failed to insert %d resource records of the local zone %d", len(batch), localZoneID)
+		}
+	}
+	result, err := tx.Model((*LocalZone)(nil)).
+		Set("zone_transfer_at = ?", transferAt).
+		Set("zone_transfer_serial = ?", serial).
+		Where("id = ?", localZoneID).
+		Update()
+	if err != nil {
+		return errors.Wrapf(err, "failed to update the zone transfer time of the local zone %d", localZoneID)
+	}
+	if result.RowsAffected() <= 0 {
+		return errors.Wrapf(ErrNotExists, "local zone %d does not exist", localZoneID)
+	}
+	return nil
+}
+
+// Replaces the resource records of the local zone with the records from
+// the recent zone transfer. It also sets the zone transfer time and the
+// serial of the transferred zone. It creates new transaction if the
+// transaction has not been started yet. Otherwise, it uses an existing
+// transaction.
+func ReplaceLocalZoneRRs(dbi pg.DBI, localZoneID, serial int64, transferAt time.Time, rrs []*LocalZoneRR) error {
+	if db, ok := dbi.(*pg.DB); ok {
+		return db.RunInTransaction(context.Background(), func(tx *pg.Tx) error {
+			return replaceLocalZoneRRs(tx, localZoneID, serial, transferAt, rrs)
+		})
+	}
+	return replaceLocalZoneRRs(dbi.(*pg.Tx), localZoneID, serial, transferAt, rrs)
+}
+
+// Returns the resource records of the local zone with optional filtering
+// and paging. The records are returned in the order in which they were
+// transferred. It also returns the total number of the records matching
+// the filter.
+func GetLocalZoneRRs(dbi pg.DBI, localZoneID int64, filter *GetLocalZoneRRsFilter) ([]*LocalZoneRR, int, error) {
+	var rrs []*LocalZoneRR
+	q := dbi.Model(&rrs).
+		Where("local_zone_id = ?", localZoneID).
+		OrderExpr("id ASC")
+	if filter != nil {
+		if filter.Type != nil {
+			q = q.Where("type = ?", strings.ToUpper(*filter.Type))
+		}
+		if filter.Text != nil {
+			q = q.Where("name ILIKE ?", "%"+*filter.Text+"%")
+		}
+		if filter.Offset != nil {
+			q = q.Offset(*filter.Offset)
+		}
+		if filter.Limit != nil {
+			q = q.Limit(*filter.Limit)
+		}
+	}
+	count, err := q.SelectAndCount()
+	if err != nil {
+		return nil, 0, errors.Wrapf(err, "failed to select resource records of the local zone %d from the database", localZoneID)
+	}
+	return rrs, count, nil
+}
+
+// Checks if the resource records of the local zone have been transferred
+// and they correspond to the current serial of the zone.
+func (localZone *LocalZone) HasCurrentRRs() bool {
+	return localZone.ZoneTransferAt != nil && localZone.ZoneTransferSerial != nil &&
+		*localZone.ZoneTransferSerial == localZone.Serial
+}
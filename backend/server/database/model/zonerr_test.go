@@ -0,0 +1,243 @@
+package dbmodel
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-pg/pg/v10"
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/require"
+	dbtest "isc.org/stork/server/database/test"
+	storkutil "isc.org/stork/util"
+)
+
+// Adds a zone served by a BIND 9 server to the database. It returns the
+// added zone with the local zone.
+func addTestZoneForRRs(t *testing.T, db pg.DBI) *Zone {
+	machine := &Machine{
+		Address:   "localhost",
+		AgentPort: int64(8080),
+	}
+	err := AddMachine(db, machine)
+	require.NoError(t, err)
+
+	app := &App{
+		MachineID: machine.ID,
+		Type:      AppTypeBind9,
+		Daemons: []*Daemon{
+			NewBind9Daemon(true),
+		},
+	}
+	_, err = AddApp(db, app)
+	require.NoError(t, err)
+
+	err = AddZones(db, &Zone{
+		Name: "example.com",
+		LocalZones: []*LocalZone{
+			{
+				DaemonID: app.Daemons[0].ID,
+				View:     "_default",
+				Class:    "IN",
+				Serial:   2024031501,
+				Type:     "primary",
+				LoadedAt: time.Now().UTC(),
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	zones, _, err := GetZones(db, nil, ZoneRelationLocalZones)
+	require.NoError(t, err)
+	require.Len(t, zones, 1)
+	require.Len(t, zones[0].LocalZones, 1)
+	return zones[0]
+}
+
+// Test converting the resource record to the database format.
+func TestNewLocalZoneRR(t *testing.T) {
+	rr, err := dns.NewRR("example.com. 3600 IN MX 10 mail.example.com.")
+	require.NoError(t, err)
+
+	localZoneRR := NewLocalZoneRR(rr)
+	require.Equal(t, "example.com.", localZoneRR.Name)
+	require.EqualValues(t, 3600, localZoneRR.TTL)
+	require.Equal(t, "IN", localZoneRR.Class)
+	require.Equal(t, "MX", localZoneRR.Type)
+	require.Equal(t, "10 mail.example.com.", localZoneRR.Data)
+}
+
+// Test checking whether the local zone has current resource records.
+func TestLocalZoneHasCurrentRRs(t *testing.T) {
+	localZone := &LocalZone{
+		Serial: 2024031501,
+	}
+	require.False(t, localZone.HasCurrentRRs())
+
+	localZone.ZoneTransferAt = storkutil.Ptr(time.Now())
+	localZone.ZoneTransferSerial = storkutil.Ptr(int64(2024031501))
+	require.True(t, localZone.HasCurrentRRs())
+
+	localZone.Serial = 2024031502
+	require.False(t, localZone.HasCurrentRRs())
+}
+
+// Test replacing and getting the resource records of the local zone.
+func TestReplaceGetLocalZoneRRs(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	zone := addTestZoneForRRs(t, db)
+	localZoneID := zone.LocalZones[0].ID
+
+	// Generate more records than inserted in a single batch.
+	var rrs []*LocalZoneRR
+	for i := 0; i < localZoneRRInsertBatchSize+10; i++ {
+		rr, err := dns.NewRR(fmt.Sprintf("host%d.example.com. 300 IN A 10.0.%d.%d", i, i/256, i%256))
+		require.NoError(t, err)
+		rrs = append(rrs, NewLocalZoneRR(rr))
+	}
+	rr, err := dns.NewRR("www.example.com. 300 IN AAAA 2001:db8::1")
+	require.NoError(t, err)
+	rrs = append(rrs, NewLocalZoneRR(rr))
+
+	transferAt := time.Date(2025, 3, 15, 10, 0, 0, 0, time.UTC)
+	err = ReplaceLocalZoneRRs(db, localZoneID, 2024031501, transferAt, rrs)
+	require.NoError(t, err)
+
+	// The zone transfer time and serial should be set.
+	zone, err = GetZoneByID(db, zone.ID, ZoneRelationLocalZones)
+	require.NoError(t, err)
+	require.NotNil(t, zone)
+	require.Len(t, zone.LocalZones, 1)
+	require.NotNil(t, zone.LocalZones[0].ZoneTransferAt)
+	require.Equal(t, transferAt, zone.LocalZones[0].ZoneTransferAt.UTC())
+	require.True(t, zone.LocalZones[0].HasCurrentRRs())
+
+	// Get all records.
+	returned, total, err := GetLocalZoneRRs(db, localZoneID, nil)
+	require.NoError(t, err)
+	require.Equal(t, len(rrs), total)
+	require.Len(t, returned, len(rrs))
+	require.Equal(t, "host0.example.com.", returned[0].Name)
+	require.Equal(t, "10.0.0.0", returned[0].Data)
+
+	// Get the records by type.
+	returned, total, err = GetLocalZoneRRs(db, localZoneID, &GetLocalZoneRRsFilter{
+		Type: storkutil.Ptr("aaaa"),
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, total)
+	require.Len(t, returned, 1)
+	require.Equal(t, "www.example.com.", returned[0].Name)
+	require.Equal(t, "2001:db8::1", returned[0].Data)
+
+	// Get a page of the records filtered by name.
+	returned, total, err = GetLocalZoneRRs(db, localZoneID, &GetLocalZoneRRsFilter{
+		Text:   storkutil.Ptr("host1"),
+		Offset: storkutil.Ptr(5),
+		Limit:  storkutil.Ptr(10),
+	})
+	require.NoError(t, err)
+	// host1, host10-host19, host100-host199, host1000-host1009
+	require.Equal(t, 1+10+100+10, total)
+	require.Len(t, returned, 10)
+	require.Equal(t, "host14.example.com.", returned[0].Name)
+
+	// Replace the records.
+	err = ReplaceLocalZoneRRs(db, localZoneID, 2024031502, transferAt.Add(time.Hour), rrs[:1])
+	require.NoError(t, err)
+	returned, total, err = GetLocalZoneRRs(db, localZoneID, nil)
+	require.NoError(t, err)
+	require.Equal(t, 1, total)
+	require.Len(t, returned, 1)
+
+	// The serial of the transferred zone differs from the local zone serial.
+	zone, err = GetZoneByID(db, zone.ID, ZoneRelationLocalZones)
+	require.NoError(t, err)
+	require.False(t, zone.LocalZones[0].HasCurrentRRs())
+
+	// Replacing the records of a non-existing local zone should fail.
+	err = ReplaceLocalZoneRRs(db, localZoneID+1000, 1, transferAt, rrs[:1])
+	require.Error(t, err)
+}
+
+// Test that the concurrent replacements of the resource records of the
+// same local zone don't duplicate the records.
+func TestReplaceLocalZoneRRsConcurrently(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	zone := addTestZoneForRRs(t, db)
+	localZoneID := zone.LocalZones[0].ID
+
+	var wg sync.WaitGroup
+	errs := make([]error, 10)
+	for i := range errs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var rrs []*LocalZoneRR
+			for j := 0; j < 100; j++ {
+				rr, err := dns.NewRR(fmt.Sprintf("host%d.example.com. 300 IN A 10.0.0.%d", j, j))
+				if err != nil {
+					errs[i] = err
+					return
+				}
+				rrs = append(rrs, NewLocalZoneRR(rr))
+			}
+			errs[i] = ReplaceLocalZoneRRs(db, localZoneID, 2024031501, time.Now().UTC(), rrs)
+		}()
+	}
+	wg.Wait()
+	for _, err := range errs {
+		require.NoError(t, err)
+	}
+
+	_, total, err := GetLocalZoneRRs(db, localZoneID, nil)
+	require.NoError(t, err)
+	require.Equal(t, 100, total)
+}
+
+// Test that the resource records are deleted with the local zones.
+func TestDeleteLocalZonesWithRRs(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	zone := addTestZoneForRRs(t, db)
+	localZone := zone.LocalZones[0]
+
+	rr, err := dns.NewRR("www.example.com. 300 IN AAAA 2001:db8::1")
+	require.NoError(t, err)
+	err = ReplaceLocalZoneRRs(db, localZone.ID, localZone.Serial, time.Now().UTC(), []*LocalZoneRR{NewLocalZoneRR(rr)})
+	require.NoError(t, err)
+
+	err = DeleteLocalZones(db, localZone.DaemonID)
+	require.NoError(t, err)
+
+	returned, total, err := GetLocalZoneRRs(db, localZone.ID, nil)
+	require.NoError(t, err)
+	require.Zero(t, total)
+	require.Empty(t, returned)
+}
+
+// Test getting the zone by ID.
+func TestGetZoneByID(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	zone := addTestZoneForRRs(t, db)
+
+	returned, err := GetZoneByID(db, zone.ID, ZoneRelationLocalZonesApp)
+	require.NoError(t, err)
+	require.NotNil(t, returned)
+	require.Equal(t, "example.com", returned.Name)
+	require.Len(t, returned.LocalZones, 1)
+	require.NotNil(t, returned.LocalZones[0].Daemon)
+	require.NotNil(t, returned.LocalZones[0].Daemon.App)
+
+	returned, err = GetZoneByID(db, zone.ID+1)
+	require.NoError(t, err)
+	require.Nil(t, returned)
+}
@@ -0,0 +1,79 @@
+package dbmodel
+
+import (
+	"time"
+
+	"github.com/go-pg/pg/v10"
+	"github.com/pkg/errors"
+)
+
+// Represents a serial drift of a secondary zone, i.e., a situation when
+// the secondary zone serial lags behind the serial of the same zone on
+// the primary server. It is held in the "serial_drift" column of the
+// "local_zone" table as JSONB.
+type ZoneSerialDrift struct {
+	// Serial of the secondary zone when the lag was first observed.
+	Serial int64
+	// ID of the daemon serving the primary zone.
+	PrimaryDaemonID int64
+	// Serial of the primary zone.
+	PrimarySerial int64
+	// The time when the secondary zone was first observed lagging
+	// behind the primary zone.
+	Since time.Time
+	// Indicates whether an event has been emitted for the drift
+	// exceeding the threshold.
+	Notified bool
+}
+
+// Checks if the secondary zone has been lagging behind the primary zone
+// for longer than the specified threshold.
+func (drift *ZoneSerialDrift) ExceedsThreshold(now time.Time, threshold time.Duration) bool {
+	return drift != nil && !drift.Since.After(now.Add(-threshold))
+}
+
+// Sets the serial drift of the local zone. The nil drift clears it.
+func UpdateLocalZoneSerialDrift(dbi pg.DBI, localZoneID int64, drift *ZoneSerialDrift) error {
+	result, err := dbi.Model((*LocalZone)(nil)).
+		Set("serial_drift = ?", drift).
+		Where("id = ?", localZoneID).
+		Update()
+	if err != nil {
+		return errors.Wrapf(err, "failed to update the serial drift of the local zone %d", localZoneID)
+	}
+	if result.RowsAffected() <= 0 {
+		return errors.Wrapf(ErrNotExists, "local zone %d does not exist", localZoneID)
+	}
+	return nil
+}
+
+// Returns the primary and secondary local zones that should be analyzed
+// for the serial drift. These are the local zones sharing the zone and the
+// view with at least one primary zone on another server, and the local
+// zones for which the serial drift has been recorded. The zones are
+// returned with the Zone relation and ordered by zone, view and ID.
+func GetLocalZonesForSerialDriftCheck(dbi pg.DBI) ([]*LocalZone, error) {
+	var localZones []*LocalZone
+	err := dbi.Model(&localZones).
+		Relation("Zone").
+		WhereIn("local_zone.type IN (?)", []ZoneType{ZoneTypePrimary, ZoneTypeSecondary}).
+		WhereGroup(func(q *pg.Query) (*pg.Query, error) {
+			return q.WhereOr("local_zone.serial_drift IS NOT NULL").
+				WhereOr(`EXISTS (
+					SELECT 1 FROM local_zone AS other
+					WHERE other.zone_id = local_zone.zone_id
+						AND other.view = local_zone.view
+						AND other.id <> local_zone.id
+						AND other.type IN (?, ?)
+						AND ? IN (other.type, local_zone.type)
+				)`, ZoneTypePrimary, ZoneTypeSecondary, ZoneTypePrimary), nil
+		}).
+		OrderExpr("local_zone.zone_id ASC").
+		OrderExpr("local_zone.view ASC").
+		OrderExpr("local_zone.id ASC").
+		Select()
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to select local zones for the serial drift check from the database")
+	}
+	return localZones, nil
+}
@@ -0,0 +1,207 @@
+package dbmodel
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	dbtest "isc.org/stork/server/database/test"
+	storkutil "isc.org/stork/util"
+)
+
+// Test checking whether the serial drift exceeds the threshold.
+func TestZoneSerialDriftExceedsThreshold(t *testing.T) {
+	now := time.Date(2025, 3, 15, 10, 0, 0, 0, time.UTC)
+
+	var drift *ZoneSerialDrift
+	require.False(t, drift.ExceedsThreshold(now, time.Hour))
+
+	drift = &ZoneSerialDrift{
+		Since: now.Add(-time.Minute),
+	}
+	require.False(t, drift.ExceedsThreshold(now, time.Hour))
+	require.True(t, drift.ExceedsThreshold(now, time.Minute))
+	require.True(t, drift.ExceedsThreshold(now, 0))
+
+	drift.Since = now.Add(-2 * time.Hour)
+	require.True(t, drift.ExceedsThreshold(now, time.Hour))
+}
+
+// Test updating the serial drift of the local zone and filtering the
+// zones by the serial drift.
+func TestUpdateLocalZoneSerialDrift(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	zone := addTestZoneForRRs(t, db)
+	localZone := zone.LocalZones[0]
+
+	since := storkutil.UTCNow().Add(-2 * time.Hour).Truncate(time.Second)
+	err := UpdateLocalZoneSerialDrift(db, localZone.ID, &ZoneSerialDrift{
+		PrimaryDaemonID: 123,
+		PrimarySerial:   2024031510,
+		Since:           since,
+	})
+	require.NoError(t, err)
+
+	zone, err = GetZoneByID(db, zone.ID, ZoneRelationLocalZones)
+	require.NoError(t, err)
+	require.Len(t, zone.LocalZones, 1)
+	drift := zone.LocalZones[0].SerialDrift
+	require.NotNil(t, drift)
+	require.EqualValues(t, 123, drift.PrimaryDaemonID)
+	require.EqualValues(t, 2024031510, drift.PrimarySerial)
+	require.Equal(t, since, drift.Since.UTC())
+	require.False(t, drift.Notified)
+
+	// The zone has been lagging behind for longer than an hour.
+	zones, total, err := GetZones(db, &GetZonesFilter{
+		SerialDrift:          storkutil.Ptr(true),
+		SerialDriftThreshold: time.Hour,
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, total)
+	require.Len(t, zones, 1)
+
+	_, total, err = GetZones(db, &GetZonesFilter{
+		SerialDrift:          storkutil.Ptr(false),
+		SerialDriftThreshold: time.Hour,
+	})
+	require.NoError(t, err)
+	require.Zero(t, total)
+
+	// The zone has not been lagging behind for longer than three hours.
+	_, total, err = GetZones(db, &GetZonesFilter{
+		SerialDrift:          storkutil.Ptr(true),
+		SerialDriftThreshold: 3 * time.Hour,
+	})
+	require.NoError(t, err)
+	require.Zero(t, total)
+
+	_, total, err = GetZones(db, &GetZonesFilter{
+		SerialDrift:          storkutil.Ptr(false),
+		SerialDriftThreshold: 3 * time.Hour,
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, total)
+
+	// Clear the drift.
+	err = UpdateLocalZoneSerialDrift(db, localZone.ID, nil)
+	require.NoError(t, err)
+
+	zone, err = GetZoneByID(db, zone.ID, ZoneRelationLocalZones)
+	require.NoError(t, err)
+	require.Nil(t, zone.LocalZones[0].SerialDrift)
+
+	_, total, err = GetZones(db, &GetZonesFilter{
+		SerialDrift:          storkutil.Ptr(false),
+		SerialDriftThreshold: time.Hour,
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, total)
+
+	// Updating a non-existing local zone should fail.
+	err = UpdateLocalZoneSerialDrift(db, localZone.ID+1000, nil)
+	require.ErrorIs(t, err, ErrNotExists)
+}
+
+// Test selecting the local zones for the serial drift check.
+func TestGetLocalZonesForSerialDriftCheck(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	var daemonIDs []int64
+	for i := 0; i < 3; i++ {
+		machine := &Machine{
+			Address:   fmt.Sprintf("host%d", i),
+			AgentPort: int64(8080),
+		}
+		err := AddMachine(db, machine)
+		require.NoError(t, err)
+
+		app := &App{
+			MachineID: machine.ID,
+			Type:      AppTypeBind9,
+			Daemons: []*Daemon{
+				NewBind9Daemon(true),
+			},
+		}
+		_, err = AddApp(db, app)
+		require.NoError(t, err)
+		daemonIDs = append(daemonIDs, app.Daemons[0].ID)
+	}
+
+	newLocalZone := func(daemonID int64, view, zoneType string) *LocalZone {
+		return &LocalZone{
+			DaemonID: daemonID,
+			View:     view,
+			Class:    "IN",
+			Serial:   1,
+			Type:     zoneType,
+			LoadedAt: time.Now().UTC(),
+		}
+	}
+	err := AddZones(db,
+		// The primary and two secondaries.
+		&Zone{
+			Name: "example.com",
+			LocalZones: []*LocalZone{
+				newLocalZone(daemonIDs[0], "_default", "primary"),
+				newLocalZone(daemonIDs[1], "_default", "secondary"),
+				newLocalZone(daemonIDs[2], "_default", "secondary"),
+			},
+		},
+		// The primary and the secondary in different views.
+		&Zone{
+			Name: "example.org",
+			LocalZones: []*LocalZone{
+				newLocalZone(daemonIDs[0], "_default", "primary"),
+				newLocalZone(daemonIDs[1], "trusted", "secondary"),
+			},
+		},
+		// Secondaries only.
+		&Zone{
+			Name: "example.net",
+			LocalZones: []*LocalZone{
+				newLocalZone(daemonIDs[1], "_default", "secondary"),
+				newLocalZone(daemonIDs[2], "_default", "secondary"),
+			},
+		},
+		// The primary and the zone of another type.
+		&Zone{
+			Name: "example.edu",
+			LocalZones: []*LocalZone{
+				newLocalZone(daemonIDs[0], "_default", "primary"),
+				newLocalZone(daemonIDs[1], "_default", "forward"),
+			},
+		},
+	)
+	require.NoError(t, err)
+
+	localZones, err := GetLocalZonesForSerialDriftCheck(db)
+	require.NoError(t, err)
+	require.Len(t, localZones, 3)
+	for i, localZone := range localZones {
+		require.NotNil(t, localZone.Zone)
+		require.Equal(t, "example.com", localZone.Zone.Name)
+		require.Equal(t, daemonIDs[i], localZone.DaemonID)
+	}
+
+	// The local zone with the recorded drift should be returned even
+	// though there is no primary zone.
+	zones, _, err := GetZones(db, &GetZonesFilter{Text: storkutil.Ptr("example.net")}, ZoneRelationLocalZones)
+	require.NoError(t, err)
+	require.Len(t, zones, 1)
+	err = UpdateLocalZoneSerialDrift(db, zones[0].LocalZones[0].ID, &ZoneSerialDrift{
+		PrimaryDaemonID: daemonIDs[0],
+		PrimarySerial:   2,
+		Since:           time.Now().UTC(),
+	})
+	require.NoError(t, err)
+
+	localZones, err = GetLocalZonesForSerialDriftCheck(db)
+	require.NoError(t, err)
+	require.Len(t, localZones, 4)
+	require.Equal(t, "example.net", localZones[3].Zone.Name)
+}
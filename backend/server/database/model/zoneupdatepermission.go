@@ -0,0 +1,84 @@
+package dbmodel
+
+import (
+	"context"
+
+	"github.com/go-pg/pg/v10"
+	"github.com/pkg/errors"
+)
+
+// Represents a permission of a user to update a zone. The super-admin
+// users are allowed to update all zones. The other users are only allowed
+// to update the zones for which they have been granted this permission.
+type ZoneUpdatePermission struct {
+	UserID int   `pg:",pk"`
+	ZoneID int64 `pg:",pk"`
+
+	User *SystemUser `pg:"rel:has-one"`
+}
+
+// Checks if the user has been granted the permission to update the zone.
+func HasZoneUpdatePermission(dbi pg.DBI, userID int, zoneID int64) (bool, error) {
+	exists, err := dbi.Model((*ZoneUpdatePermission)(nil)).
+		Where("user_id = ?", userID).
+		Where("zone_id = ?", zoneID).
+		Exists()
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to check the permission of user %d to update zone %d", userID, zoneID)
+	}
+	return exists, nil
+}
+
+// Returns the permissions to update the zone with the users they have
+// been granted to. The permissions are ordered by user ID.
+func GetZoneUpdatePermissions(dbi pg.DBI, zoneID int64) ([]*ZoneUpdatePermission, error) {
+	var permissions []*ZoneUpdatePermission
+	err := dbi.Model(&permissions).
+		Relation("User").
+		Where("zone_update_permission.zone_id = ?", zoneID).
+		OrderExpr("zone_update_permission.user_id ASC").
+		Select()
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to select the permissions to update zone %d from the database", zoneID)
+	}
+	return permissions, nil
+}
+
+// Replaces the permissions to update the zone in a transaction.
+func setZoneUpdatePermissions(tx *pg.Tx, zoneID int64, userIDs []int) error {
+	_, err := tx.Model((*ZoneUpdatePermission)(nil)).
+		Where("zone_id = ?", zoneID).
+		Delete()
+	if err != nil {
+		return errors.Wrapf(err, "failed to delete the permissions to update zone %d", zoneID)
+	}
+	if len(userIDs) == 0 {
+		return nil
+	}
+	var permissions []ZoneUpdatePermission
+	for _, userID := range userIDs {
+		permissions = append(permissions, ZoneUpdatePermission{
+			UserID: userID,
+			ZoneID: zoneID,
+		})
+	}
+	_, err = tx.Model(&permissions).OnConflict("DO NOTHING").Insert()
+	if err != nil {
+		return errors.Wrapf(err, "failed to insert the permissions to update zone %d", zoneID)
+	}
+	return nil
+}
+
+// Replaces the permissions to update the zone. The specified users are
+// granted the permission and the permission is revoked from the other
+// users. The empty list of users revokes the permission from all users.
+// It begins a new transaction when dbi has a *pg.DB type or uses an
+// existing transaction when dbi has a *pg.Tx type.
+func SetZoneUpdatePermissions(dbi pg.DBI, zoneID int64, userIDs []int) error {
+	if db, ok := dbi.(*pg.DB); ok {
+		return db.RunInTransaction(context.Background(), func(tx *pg.Tx) error {
+			return setZoneUpdatePermissions(tx, zoneID, userIDs)
+		})
+	}
+	return setZoneUpdatePermissions(dbi.(*pg.Tx), zoneID, userIDs)
+}
@@ -0,0 +1,120 @@
+package dbmodel
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/go-pg/pg/v10"
+	"github.com/stretchr/testify/require"
+	dbtest "isc.org/stork/server/database/test"
+)
+
+// Adds the test users to the database.
+func addTestZoneUpdatePermissionUsers(t *testing.T, db *pg.DB) (users []*SystemUser) {
+	for i := 0; i < 3; i++ {
+		user := &SystemUser{
+			Login:    fmt.Sprintf("user%d", i),
+			Email:    fmt.Sprintf("user%d@example.org", i),
+			Lastname: "Doe",
+			Name:     "John",
+		}
+		_, err := CreateUser(db, user)
+		require.NoError(t, err)
+		users = append(users, user)
+	}
+	return users
+}
+
+// Test granting, checking and revoking the permissions to update a zone.
+func TestZoneUpdatePermissions(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	zone := addTestZoneForRRs(t, db)
+	users := addTestZoneUpdatePermissionUsers(t, db)
+
+	// No permissions initially.
+	permissions, err := GetZoneUpdatePermissions(db, zone.ID)
+	require.NoError(t, err)
+	require.Empty(t, permissions)
+
+	allowed, err := HasZoneUpdatePermission(db, users[0].ID, zone.ID)
+	require.NoError(t, err)
+	require.False(t, allowed)
+
+	// Grant the permissions to two users. The duplicate is ignored.
+	err = SetZoneUpdatePermissions(db, zone.ID, []int{users[2].ID, users[0].ID, users[2].ID})
+	require.NoError(t, err)
+
+	permissions, err = GetZoneUpdatePermissions(db, zone.ID)
+	require.NoError(t, err)
+	require.Len(t, permissions, 2)
+	require.Equal(t, users[0].ID, permissions[0].UserID)
+	require.Equal(t, zone.ID, permissions[0].ZoneID)
+	require.NotNil(t, permissions[0].User)
+	require.Equal(t, "user0", permissions[0].User.Login)
+	require.Equal(t, users[2].ID, permissions[1].UserID)
+	require.NotNil(t, permissions[1].User)
+	require.Equal(t, "user2", permissions[1].User.Login)
+
+	allowed, err = HasZoneUpdatePermission(db, users[0].ID, zone.ID)
+	require.NoError(t, err)
+	require.True(t, allowed)
+
+	allowed, err = HasZoneUpdatePermission(db, users[1].ID, zone.ID)
+	require.NoError(t, err)
+	require.False(t, allowed)
+
+	// The permission is limited to the zone.
+	allowed, err = HasZoneUpdatePermission(db, users[0].ID, zone.ID+1)
+	require.NoError(t, err)
+	require.False(t, allowed)
+
+	// Replace the permissions.
+	err = SetZoneUpdatePermissions(db, zone.ID, []int{users[1].ID})
+	require.NoError(t, err)
+
+	permissions, err = GetZoneUpdatePermissions(db, zone.ID)
+	require.NoError(t, err)
+	require.Len(t, permissions, 1)
+	require.Equal(t, users[1].ID, permissions[0].UserID)
+
+	// Deleting the user revokes the permission.
+	err = DeleteUser(db, users[1])
+	require.NoError(t, err)
+
+	permissions, err = GetZoneUpdatePermissions(db, zone.ID)
+	require.NoError(t, err)
+	require.Empty(t, permissions)
+
+	// Revoke all permissions.
+	err = SetZoneUpdatePermissions(db, zone.ID, []int{users[0].ID})
+	require.NoError(t, err)
+	err = SetZoneUpdatePermissions(db, zone.ID, nil)
+	require.NoError(t, err)
+
+	permissions, err = GetZoneUpdatePermissions(db, zone.ID)
+	require.NoError(t, err)
+	require.Empty(t, permissions)
+}
+
+// Test that the permission cannot be granted to a non-existing user.
+func TestSetZoneUpdatePermissionsNonExistingUser(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	zone := addTestZoneForRRs(t, db)
+	users := addTestZoneUpdatePermissionUsers(t, db)
+
+	err := SetZoneUpdatePermissions(db, zone.ID, []int{users[0].ID})
+	require.NoError(t, err)
+
+	// The transaction is rolled back, so the previous permissions remain.
+	err = SetZoneUpdatePermissions(db, zone.ID, []int{users[1].ID, 12345})
+	require.Error(t, err)
+
+	permissions, err := GetZoneUpdatePermissions(db, zone.ID)
+	require.NoError(t, err)
+	require.Len(t, permissions, 1)
+	require.Equal(t, users[0].ID, permissions[0].UserID)
+}
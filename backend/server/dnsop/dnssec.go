@@ -0,0 +1,79 @@
+package dnsop
+
+import (
+	"time"
+
+	"github.com/miekg/dns"
+	dbmodel "isc.org/stork/server/database/model"
+)
+
+// Determines the DNSSEC state of the zone from its resource records. The
+// zone is considered signed if it contains the DNSKEY records at the apex
+// and the RRSIG records. The DS records are taken from the CDS records if
+// the zone publishes them. Otherwise, they are derived from the key signing
+// keys (i.e., the keys with the SEP flag set) using the SHA-256 digest.
+func newZoneDNSSEC(zoneName string, rrs []dns.RR, checkedAt time.Time) *dbmodel.ZoneDNSSEC {
+	apex := dns.CanonicalName(zoneName)
+	dnssec := &dbmodel.ZoneDNSSEC{
+		CheckedAt: checkedAt,
+	}
+	var (
+		hasRRSIG bool
+		hasCDS   bool
+		ksks     []*dns.DNSKEY
+	)
+	for _, rr := range rrs {
+		isApex := dns.CanonicalName(rr.Header().Name) == apex
+		switch rr := rr.(type) {
+		case *dns.DNSKEY:
+			if !isApex {
+				continue
+			}
+			dnssec.Keys = append(dnssec.Keys, dbmodel.ZoneDNSSECKey{
+				KeyTag:    rr.KeyTag(),
+				Algorithm: rr.Algorithm,
+				Flags:     rr.Flags,
+			})
+			if rr.Flags&dns.SEP != 0 {
+				ksks = append(ksks, rr)
+			}
+		case *dns.CDS:
+			// The algorithm 0 indicates the request to delete the DS
+			// records from the parent zone.
+			if !isApex || rr.Algorithm == 0 {
+				continue
+			}
+			hasCDS = true
+			dnssec.DS = append(dnssec.DS, dbmodel.ZoneDNSSECDS{
+				KeyTag:     rr.KeyTag,
+				Algorithm:  rr.Algorithm,
+				DigestType: rr.DigestType,
+			})
+		case *dns.RRSIG:
+			hasRRSIG = true
+			expiresAt := time.Unix(int64(rr.Expiration), 0).UTC()
+			if dnssec.RRSIGExpiresAt == nil || expiresAt.Before(*dnssec.RRSIGExpiresAt) {
+				dnssec.RRSIGExpiresAt = &expiresAt
+			}
+		case *dns.NSEC3, *dns.NSEC3PARAM:
+			dnssec.Denial = dbmodel.ZoneDNSSECDenialNSEC3
+		case *dns.NSEC:
+			if dnssec.Denial == "" {
+				dnssec.Denial = dbmodel.ZoneDNSSECDenialNSEC
+			}
+		}
+	}
+	if !hasCDS {
+		for _, ksk := range ksks {
+			if ds := ksk.ToDS(dns.SHA256); ds != nil {
+				dnssec.DS = append(dnssec.DS, dbmodel.ZoneDNSSECDS{
+					KeyTag:     ds.KeyTag,
+					Algorithm:  ds.Algorithm,
+					DigestType: ds.DigestType,
+				})
+			}
+		}
+	}
+	dnssec.Signed = len(dnssec.Keys) > 0 && hasRRSIG
+	return dnssec
+}
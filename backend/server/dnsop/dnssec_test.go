@@ -0,0 +1,140 @@
+package dnsop
+
+import (
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/require"
+	dbmodel "isc.org/stork/server/database/model"
+)
+
+// Generates a DNSKEY record for the zone with the specified flags.
+func generateTestDNSKEY(t *testing.T, zoneName string, flags uint16) *dns.DNSKEY {
+	key := &dns.DNSKEY{
+		Hdr: dns.RR_Header{
+			Name:   dns.Fqdn(zoneName),
+			Rrtype: dns.TypeDNSKEY,
+			Class:  dns.ClassINET,
+			Ttl:    3600,
+		},
+		Flags:     flags,
+		Protocol:  3,
+		Algorithm: dns.ECDSAP256SHA256,
+	}
+	_, err := key.Generate(256)
+	require.NoError(t, err)
+	return key
+}
+
+// Returns an RRSIG record covering the specified type and expiring at
+// the specified time.
+func newTestRRSIG(name string, covered uint16, keyTag uint16, expiresAt time.Time) *dns.RRSIG {
+	return &dns.RRSIG{
+		Hdr: dns.RR_Header{
+			Name:   dns.Fqdn(name),
+			Rrtype: dns.TypeRRSIG,
+			Class:  dns.ClassINET,
+			Ttl:    3600,
+		},
+		TypeCovered: covered,
+		Algorithm:   dns.ECDSAP256SHA256,
+		Labels:      uint8(dns.CountLabel(name)),
+		OrigTtl:     3600,
+		Expiration:  uint32(expiresAt.Unix()),
+		Inception:   uint32(expiresAt.Add(-14 * 24 * time.Hour).Unix()),
+		KeyTag:      keyTag,
+		SignerName:  "example.com.",
+		Signature:   "AAAA",
+	}
+}
+
+// Parses the resource record from the presentation format.
+func newTestRR(t *testing.T, text string) dns.RR {
+	rr, err := dns.NewRR(text)
+	require.NoError(t, err)
+	return rr
+}
+
+// Test determining the DNSSEC state of a signed zone using NSEC3.
+func TestNewZoneDNSSECSignedNSEC3(t *testing.T) {
+	ksk := generateTestDNSKEY(t, "example.com", 257)
+	zsk := generateTestDNSKEY(t, "example.com", 256)
+	// The DNSKEY below the apex should be ignored.
+	child := generateTestDNSKEY(t, "child.example.com", 257)
+
+	expiresAt := time.Date(2025, 3, 29, 10, 0, 0, 0, time.UTC)
+	rrs := []dns.RR{
+		newTestRR(t, "example.com. 3600 IN SOA ns1.example.com. admin.example.com. 2024031501 43200 3600 1209600 3600"),
+		newTestRRSIG("example.com", dns.TypeSOA, zsk.KeyTag(), expiresAt.Add(time.Hour)),
+		ksk,
+		zsk,
+		child,
+		newTestRRSIG("example.com", dns.TypeDNSKEY, ksk.KeyTag(), expiresAt),
+		newTestRR(t, "example.com. 0 IN NSEC3PARAM 1 0 0 -"),
+		newTestRR(t, "www.example.com. 300 IN AAAA 2001:db8::1"),
+		newTestRRSIG("www.example.com", dns.TypeAAAA, zsk.KeyTag(), expiresAt.Add(2*time.Hour)),
+	}
+	checkedAt := time.Date(2025, 3, 15, 10, 0, 0, 0, time.UTC)
+	dnssec := newZoneDNSSEC("example.com", rrs, checkedAt)
+	require.NotNil(t, dnssec)
+	require.True(t, dnssec.Signed)
+	require.Equal(t, checkedAt, dnssec.CheckedAt)
+	require.Equal(t, dbmodel.ZoneDNSSECDenialNSEC3, dnssec.Denial)
+	require.NotNil(t, dnssec.RRSIGExpiresAt)
+	require.Equal(t, expiresAt, *dnssec.RRSIGExpiresAt)
+
+	require.Len(t, dnssec.Keys, 2)
+	require.Equal(t, ksk.KeyTag(), dnssec.Keys[0].KeyTag)
+	require.EqualValues(t, dns.ECDSAP256SHA256, dnssec.Keys[0].Algorithm)
+	require.EqualValues(t, 257, dnssec.Keys[0].Flags)
+	require.Equal(t, zsk.KeyTag(), dnssec.Keys[1].KeyTag)
+	require.EqualValues(t, 256, dnssec.Keys[1].Flags)
+
+	// The DS record should be derived from the KSK.
+	require.Len(t, dnssec.DS, 1)
+	require.Equal(t, ksk.KeyTag(), dnssec.DS[0].KeyTag)
+	require.EqualValues(t, dns.ECDSAP256SHA256, dnssec.DS[0].Algorithm)
+	require.EqualValues(t, dns.SHA256, dnssec.DS[0].DigestType)
+}
+
+// Test determining the DNSSEC state of a signed zone using NSEC and
+// publishing the CDS records.
+func TestNewZoneDNSSECSignedNSECWithCDS(t *testing.T) {
+	ksk := generateTestDNSKEY(t, "example.com", 257)
+	expiresAt := time.Date(2025, 3, 29, 10, 0, 0, 0, time.UTC)
+
+	cds := ksk.ToDS(dns.SHA384).ToCDS()
+	rrs := []dns.RR{
+		newTestRR(t, "example.com. 3600 IN SOA ns1.example.com. admin.example.com. 2024031501 43200 3600 1209600 3600"),
+		ksk,
+		newTestRRSIG("example.com", dns.TypeDNSKEY, ksk.KeyTag(), expiresAt),
+		cds,
+		// The CDS record requesting the DS deletion should be ignored.
+		newTestRR(t, "example.com. 3600 IN CDS 0 0 0 00"),
+		newTestRR(t, "example.com. 3600 IN NSEC www.example.com. SOA NS RRSIG NSEC DNSKEY CDS"),
+	}
+	dnssec := newZoneDNSSEC("example.com.", rrs, time.Now())
+	require.True(t, dnssec.Signed)
+	require.Equal(t, dbmodel.ZoneDNSSECDenialNSEC, dnssec.Denial)
+	require.Len(t, dnssec.Keys, 1)
+	require.Len(t, dnssec.DS, 1)
+	require.Equal(t, ksk.KeyTag(), dnssec.DS[0].KeyTag)
+	require.EqualValues(t, dns.SHA384, dnssec.DS[0].DigestType)
+}
+
+// Test determining the DNSSEC state of an unsigned zone.
+func TestNewZoneDNSSECUnsigned(t *testing.T) {
+	rrs := []dns.RR{
+		newTestRR(t, "example.com. 3600 IN SOA ns1.example.com. admin.example.com. 2024031501 43200 3600 1209600 3600"),
+		newTestRR(t, "example.com. 3600 IN NS ns1.example.com."),
+		newTestRR(t, "ns1.example.com. 3600 IN A 192.0.2.1"),
+	}
+	dnssec := newZoneDNSSEC("example.com", rrs, time.Now())
+	require.NotNil(t, dnssec)
+	require.False(t, dnssec.Signed)
+	require.Empty(t, dnssec.Keys)
+	require.Empty(t, dnssec.DS)
+	require.Empty(t, dnssec.Denial)
+	require.Nil(t, dnssec.RRSIGExpiresAt)
+}
@@ -0,0 +1,43 @@
+package dnsop
+
+import (
+	"context"
+
+	"github.com/go-pg/pg/v10"
+	"isc.org/stork/server/agentcomm"
+)
+
+// The puller periodically checking the DNSSEC state of the zones.
+type DNSSECPuller struct {
+	*agentcomm.PeriodicPuller
+	manager Manager
+}
+
+// Creates a DNSSECPuller object that in background checks the DNSSEC state
+// of the zones using the DNS Manager. The serials of the zones are refreshed
+// in each check. The DNSSEC records are queried from the DNS servers when the
+// zones changed since the last check or when their signatures are about to
+// expire.
+func NewDNSSECPuller(db *pg.DB, agents agentcomm.ConnectedAgents, manager Manager) (*DNSSECPuller, error) {
+	dnssecPuller := &DNSSECPuller{
+		manager: manager,
+	}
+	periodicPuller, err := agentcomm.NewPeriodicPuller(db, agents, "DNSSEC puller", "dnssec_puller_interval",
+		dnssecPuller.pullDNSSEC)
+	if err != nil {
+		return nil, err
+	}
+	dnssecPuller.PeriodicPuller = periodicPuller
+	return dnssecPuller, nil
+}
+
+// Shutdown DNSSECPuller. It stops goroutine that checks the zones.
+func (dnssecPuller *DNSSECPuller) Shutdown() {
+	dnssecPuller.PeriodicPuller.Shutdown()
+}
+
+// Checks the DNSSEC state of the zones. The function returns last
+// encountered error.
+func (dnssecPuller *DNSSECPuller) pullDNSSEC() error {
+	return dnssecPuller.manager.CheckZonesDNSSEC(context.Background())
+}
@@ -3,12 +3,18 @@ package dnsop
 import (
 	"context"
 	"errors"
+	"fmt"
 	"sync"
+	"time"
 
 	"github.com/go-pg/pg/v10"
+	"github.com/miekg/dns"
+	pkgerrors "github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 	agentcomm "isc.org/stork/server/agentcomm"
 	dbmodel "isc.org/stork/server/database/model"
+	"isc.org/stork/server/eventcenter"
+	storkutil "isc.org/stork/util"
 )
 
 var _ Manager = (*managerImpl)(nil)
@@ -34,6 +40,8 @@ type ManagerAccessors interface {
 	GetDB() *pg.DB
 	// Returns an interface to the agents the manager communicates with.
 	GetConnectedAgents() agentcomm.ConnectedAgents
+	// Returns an interface to the event center.
+	GetEventCenter() eventcenter.EventCenter
 }
 
 // An interface to the DNS Manager used from external packages. Exposing
@@ -58,6 +66,26 @@ type Manager interface {
 	// parameters indicate the number of apps from which the zones are fetched and the
 	// number of apps from which the zones have been fetched already.
 	GetFetchZonesProgress() (bool, int, int)
+	// Transfers the specified zone from the DNS server via the agent and
+	// caches its resource records in the database. The records are
+	// associated with the specified local zone, i.e., they are specific
+	// to the DNS server and the view. It also updates the DNSSEC state
+	// of the local zone.
+	FetchZoneRRs(ctx context.Context, zoneName string, localZone *dbmodel.LocalZone) error
+	// Updates the DNSSEC state of the primary and secondary zones that
+	// changed since the last check or whose signatures expire soon.
+	CheckZonesDNSSEC(ctx context.Context) error
+	// Refreshes and compares the serials of the zones served by different
+	// DNS servers in the same views and records the serial drift of the
+	// secondary zones lagging behind the primary zones.
+	CheckZonesSerialDrift(ctx context.Context) error
+	// Sends the dynamic update of the primary zone to the DNS server via
+	// the agent on behalf of the user. The specified records are deleted
+	// and then the new records are added in a single update. Only the A,
+	// AAAA, CNAME, PTR and TXT records can be updated. The user must be
+	// a super-admin or must have been granted the permission to update
+	// the zone.
+	UpdateZone(ctx context.Context, user *dbmodel.SystemUser, zoneName string, localZone *dbmodel.LocalZone, deleteRRs, addRRs []dns.RR) error
 }
 
 // A zones fetching state including the flag whether or not the fetch
@@ -122,6 +150,8 @@ type managerImpl struct {
 	db *pg.DB
 	// Interface to the connected agents.
 	agents agentcomm.ConnectedAgents
+	// Interface to the event center.
+	eventCenter eventcenter.EventCenter
 	// A state of fetching zones from the DNS servers by the manager.
 	fetchingState *fetchingState
 }
@@ -138,6 +168,7 @@ func NewManager(owner ManagerAccessors) Manager {
 	return &managerImpl{
 		db:            owner.GetDB(),
 		agents:        owner.GetConnectedAgents(),
+		eventCenter:   owner.GetEventCenter(),
 		fetchingState: &fetchingState{},
 	}
 }
@@ -312,6 +343,216 @@ func (manager *managerImpl) GetFetchZonesProgress() (bool, int, int) {
 	return manager.fetchingState.getFetchZonesProgress()
 }
 
+// Transfers the zone from the DNS server via the agent and caches its
+// resource records in the database. It implements the Manager interface.
+func (manager *managerImpl) FetchZoneRRs(ctx context.Context, zoneName string, localZone *dbmodel.LocalZone) error {
+	daemon, err := dbmodel.GetDaemonByID(manager.db, localZone.DaemonID)
+	if err != nil {
+		return err
+	}
+	if daemon == nil || daemon.App == nil {
+		return pkgerrors.Wrapf(dbmodel.ErrNotExists, "daemon %d serving zone %s does not exist", localZone.DaemonID, zoneName)
+	}
+	var (
+		rrs     []*dbmodel.LocalZoneRR
+		zoneRRs []dns.RR
+		serial  *int64
+	)
+	for chunk, err := range manager.agents.ReceiveZoneRRs(ctx, daemon.App, zoneName, localZone.View) {
+		if err != nil {
+			return err
+		}
+		for _, rr := range chunk {
+			if soa, ok := rr.(*dns.SOA); ok {
+				// The zone transfer begins and ends with the SOA record.
+				// Only the first one is stored.
+				if serial != nil {
+					continue
+				}
+				serial = storkutil.Ptr(int64(soa.Serial))
+			}
+			rrs = append(rrs, dbmodel.NewLocalZoneRR(rr))
+			zoneRRs = append(zoneRRs, rr)
+		}
+	}
+	if serial == nil {
+		return pkgerrors.Errorf("transfer of zone %s from %s returned no SOA record", zoneName, daemon.App.Name)
+	}
+	transferAt := storkutil.UTCNow()
+	err = dbmodel.ReplaceLocalZoneRRs(manager.db, localZone.ID, *serial, transferAt, rrs)
+	if err != nil {
+		return err
+	}
+	log.WithFields(log.Fields{
+		"zone":  zoneName,
+		"view":  localZone.View,
+		"app":   daemon.App.Name,
+		"count": len(rrs),
+	}).Info("Completed transferring the zone from the agent")
+
+	dnssec := newZoneDNSSEC(zoneName, zoneRRs, transferAt)
+	dnssec.Serial = *serial
+	return manager.updateZoneDNSSEC(ctx, daemon, zoneName, localZone, dnssec)
+}
+
+// Queries the SOA record of the zone from the DNS server via the agent and
+// returns the zone serial. If the serial differs from the serial held in
+// the database, the local zone is updated with the new serial. It makes
+// the serials fresh without fetching the zones from the agents.
+func (manager *managerImpl) refreshZoneSerial(ctx context.Context, daemon *dbmodel.Daemon, zoneName string, localZone *dbmodel.LocalZone) (int64, error) {
+	rrs, err := manager.agents.QueryZoneRRs(ctx, daemon.App, zoneName, localZone.View, []uint16{dns.TypeSOA})
+	if err != nil {
+		return 0, err
+	}
+	var soa *dns.SOA
+	for _, rr := range rrs {
+		if rr, ok := rr.(*dns.SOA); ok {
+			soa = rr
+			break
+		}
+	}
+	if soa == nil {
+		return 0, pkgerrors.Errorf("query for zone %s to %s returned no SOA record", zoneName, daemon.App.Name)
+	}
+	serial := int64(soa.Serial)
+	if serial != localZone.Serial {
+		if err = dbmodel.UpdateLocalZoneSerial(manager.db, localZone.ID, serial); err != nil {
+			return 0, err
+		}
+		localZone.Serial = serial
+	}
+	return serial, nil
+}
+
+// Determines the DNSSEC state of the zone from the DNSKEY, CDS, NSEC,
+// NSEC3PARAM and RRSIG records at the zone apex queried from the DNS
+// server. It is much cheaper than transferring the whole zone. The
+// signatures expiration time is taken from the RRSIG records at the zone
+// apex only. They are refreshed together with the other signatures in
+// the zone.
+func (manager *managerImpl) queryZoneDNSSEC(ctx context.Context, daemon *dbmodel.Daemon, zoneName string, localZone *dbmodel.LocalZone, serial int64) error {
+	rrs, err := manager.agents.QueryZoneRRs(ctx, daemon.App, zoneName, localZone.View, []uint16{
+		dns.TypeDNSKEY, dns.TypeCDS, dns.TypeNSEC, dns.TypeNSEC3PARAM, dns.TypeRRSIG,
+	})
+	if err != nil {
+		return err
+	}
+	dnssec := newZoneDNSSEC(zoneName, rrs, storkutil.UTCNow())
+	dnssec.Serial = serial
+	return manager.updateZoneDNSSEC(ctx, daemon, zoneName, localZone, dnssec)
+}
+
+// Saves the DNSSEC state of the local zone in the database. If the zone is
+// signed, it also gets the rndc dnssec -status output from the DNS server.
+// It raises a warning event when the signatures are going to expire soon
+// and they were not expiring soon according to the previous state.
+func (manager *managerImpl) updateZoneDNSSEC(ctx context.Context, daemon *dbmodel.Daemon, zoneName string, localZone *dbmodel.LocalZone, dnssec *dbmodel.ZoneDNSSEC) error {
+	if dnssec.Signed {
+		command := fmt.Sprintf("dnssec -status %s %s %s", zoneName, localZone.Class, localZone.View)
+		output, err := manager.agents.ForwardRndcCommand(ctx, daemon.App, command)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"zone": zoneName,
+				"view": localZone.View,
+				"app":  daemon.App.Name,
+			}).WithError(err).Warn("Failed to get the DNSSEC status of the zone")
+		} else {
+			dnssec.RndcStatus = output.Output
+		}
+	}
+	if err := dbmodel.UpdateLocalZoneDNSSEC(manager.db, localZone.ID, dnssec); err != nil {
+		return err
+	}
+	if dnssec.IsExpiringSoon(dnssec.CheckedAt) && !localZone.DNSSEC.IsExpiringSoon(dnssec.CheckedAt) {
+		manager.eventCenter.AddWarningEvent(
+			fmt.Sprintf("DNSSEC signatures of zone %s in view %s on {daemon} expire at %s",
+				zoneName, localZone.View, dnssec.RRSIGExpiresAt.Format(time.RFC3339)),
+			daemon,
+		)
+	}
+	localZone.DNSSEC = dnssec
+	return nil
+}
+
+// Updates the DNSSEC state of the primary and secondary zones that changed
+// since the last check or whose signatures expire soon. It implements the
+// Manager interface. The serial of each zone is refreshed by querying its
+// SOA record. The DNSSEC records are queried only when the zone has not
+// been checked yet, the serial differs from the serial for which the
+// DNSSEC state was determined, or the signatures expire soon. The zones
+// are never transferred. It returns the last encountered error.
+func (manager *managerImpl) CheckZonesDNSSEC(ctx context.Context) error {
+	localZones, err := dbmodel.GetLocalZonesForDNSSECCheck(manager.db, "primary", "secondary")
+	if err != nil {
+		return err
+	}
+	var lastErr error
+	okCount := 0
+	checkedCount := 0
+	daemons := make(map[int64]*dbmodel.Daemon)
+	for _, localZone := range localZones {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		checked, err := manager.checkZoneDNSSEC(ctx, daemons, localZone)
+		if err != nil {
+			lastErr = err
+			log.WithFields(log.Fields{
+				"zone": localZone.Zone.Name,
+				"view": localZone.View,
+			}).WithError(err).Warn("Failed to check the DNSSEC state of the zone")
+			continue
+		}
+		if checked {
+			checkedCount++
+		}
+		okCount++
+	}
+	log.Infof("Completed checking the DNSSEC state of the zones: %d/%d succeeded, %d changed or expiring soon", okCount, len(localZones), checkedCount)
+	return lastErr
+}
+
+// Returns the daemon serving the local zone with its app. The daemons map
+// caches the daemons, so they are fetched from the database once when
+// checking many zones. The local zone must have the Zone relation.
+func (manager *managerImpl) getLocalZoneDaemon(daemons map[int64]*dbmodel.Daemon, localZone *dbmodel.LocalZone) (*dbmodel.Daemon, error) {
+	daemon, ok := daemons[localZone.DaemonID]
+	if !ok {
+		var err error
+		if daemon, err = dbmodel.GetDaemonByID(manager.db, localZone.DaemonID); err != nil {
+			return nil, err
+		}
+		daemons[localZone.DaemonID] = daemon
+	}
+	if daemon == nil || daemon.App == nil {
+		return nil, pkgerrors.Wrapf(dbmodel.ErrNotExists, "daemon %d serving zone %s does not exist", localZone.DaemonID, localZone.Zone.Name)
+	}
+	return daemon, nil
+}
+
+// Refreshes the serial of the local zone and determines its DNSSEC state
+// if necessary. The daemons map caches the daemons serving the zones. It
+// returns a boolean flag indicating whether the DNSSEC state was determined.
+func (manager *managerImpl) checkZoneDNSSEC(ctx context.Context, daemons map[int64]*dbmodel.Daemon, localZone *dbmodel.LocalZone) (bool, error) {
+	daemon, err := manager.getLocalZoneDaemon(daemons, localZone)
+	if err != nil {
+		return false, err
+	}
+	zoneName := localZone.Zone.Name
+	serial, err := manager.refreshZoneSerial(ctx, daemon, zoneName, localZone)
+	if err != nil {
+		return false, err
+	}
+	if localZone.DNSSEC != nil && localZone.DNSSEC.Serial == serial && !localZone.DNSSEC.IsExpiringSoon(storkutil.UTCNow()) {
+		// Nothing changed since the last check.
+		return false, nil
+	}
+	if err = manager.queryZoneDNSSEC(ctx, daemon, zoneName, localZone, serial); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
 // Convenience function storing a value in a map with mutex protection.
 func storeResult[K comparable, T any](mutex *sync.Mutex, results map[K]T, key K, value T) {
 	mutex.Lock()
@@ -6,6 +6,8 @@ import (
 	"testing"
 	"time"
 
+	"github.com/go-pg/pg/v10"
+	"github.com/miekg/dns"
 	"github.com/stretchr/testify/require"
 	gomock "go.uber.org/mock/gomock"
 	bind9stats "isc.org/stork/appdata/bind9stats"
@@ -13,7 +15,9 @@ import (
 	appstest "isc.org/stork/server/apps/test"
 	dbmodel "isc.org/stork/server/database/model"
 	dbtest "isc.org/stork/server/database/test"
+	storktest "isc.org/stork/server/test/dbmodel"
 	"isc.org/stork/testutil"
+	storkutil "isc.org/stork/util"
 )
 
 //go:generate mockgen -package=dnsop -destination=connectedagentsmock_test.go -source=../agentcomm/agentcomm.go ConnectedAgents
@@ -563,3 +567,323 @@ func TestFetchRepeatedZones(t *testing.T) {
 		require.Len(t, zone.LocalZones, 2)
 	}
 }
+
+// Adds a BIND 9 app serving the example.com zone to the database. It
+// returns the added app and zone.
+func addTestZoneForRRs(t *testing.T, db *pg.DB) (*dbmodel.App, *dbmodel.Zone) {
+	machine := &dbmodel.Machine{
+		ID:        0,
+		Address:   "localhost",
+		AgentPort: int64(8080),
+	}
+	err := dbmodel.AddMachine(db, machine)
+	require.NoError(t, err)
+
+	app := &dbmodel.App{
+		ID:        0,
+		MachineID: machine.ID,
+		Type:      dbmodel.AppTypeBind9,
+		Daemons: []*dbmodel.Daemon{
+			dbmodel.NewBind9Daemon(true),
+		},
+	}
+	_, err = dbmodel.AddApp(db, app)
+	require.NoError(t, err)
+
+	err = dbmodel.AddZones(db, &dbmodel.Zone{
+		Name: "example.com",
+		LocalZones: []*dbmodel.LocalZone{
+			{
+				DaemonID: app.Daemons[0].ID,
+				View:     "trusted",
+				Class:    "IN",
+				Serial:   2024031501,
+				Type:     "primary",
+				LoadedAt: time.Now().UTC(),
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	zones, _, err := dbmodel.GetZones(db, nil, dbmodel.ZoneRelationLocalZones)
+	require.NoError(t, err)
+	require.Len(t, zones, 1)
+	require.Len(t, zones[0].LocalZones, 1)
+	return app, zones[0]
+}
+
+// Test transferring the zone via the agent and caching its resource
+// records in the database.
+func TestFetchZoneRRs(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	controller := gomock.NewController(t)
+	mock := NewMockConnectedAgents(controller)
+
+	app, zone := addTestZoneForRRs(t, db)
+
+	var rrs []dns.RR
+	for _, text := range []string{
+		"example.com. 3600 IN SOA ns1.example.com. admin.example.com. 2024031502 43200 3600 1209600 3600",
+		"example.com. 3600 IN NS ns1.example.com.",
+		"ns1.example.com. 3600 IN A 192.0.2.1",
+		"www.example.com. 300 IN AAAA 2001:db8::1",
+		"example.com. 3600 IN SOA ns1.example.com. admin.example.com. 2024031502 43200 3600 1209600 3600",
+	} {
+		rr, err := dns.NewRR(text)
+		require.NoError(t, err)
+		rrs = append(rrs, rr)
+	}
+
+	mock.EXPECT().ReceiveZoneRRs(gomock.Any(), gomock.Cond(func(a any) bool {
+		return a.(*dbmodel.App).ID == app.ID
+	}), "example.com", "trusted").DoAndReturn(func(context.Context, agentcomm.ControlledApp, string, string) iter.Seq2[[]dns.RR, error] {
+		return func(yield func([]dns.RR, error) bool) {
+			// Return the records in two chunks.
+			if !yield(rrs[:2], nil) {
+				return
+			}
+			_ = yield(rrs[2:], nil)
+		}
+	})
+
+	manager := NewManager(&appstest.ManagerAccessorsWrapper{
+		DB:     db,
+		Agents: mock,
+	})
+	require.NotNil(t, manager)
+
+	err := manager.FetchZoneRRs(context.Background(), zone.Name, zone.LocalZones[0])
+	require.NoError(t, err)
+
+	// The closing SOA record should not be stored.
+	returned, total, err := dbmodel.GetLocalZoneRRs(db, zone.LocalZones[0].ID, nil)
+	require.NoError(t, err)
+	require.Equal(t, 4, total)
+	require.Len(t, returned, 4)
+	require.Equal(t, "SOA", returned[0].Type)
+	require.Equal(t, "AAAA", returned[3].Type)
+	require.Equal(t, "2001:db8::1", returned[3].Data)
+
+	// The serial should be taken from the transferred SOA record.
+	zone, err = dbmodel.GetZoneByID(db, zone.ID, dbmodel.ZoneRelationLocalZones)
+	require.NoError(t, err)
+	require.NotNil(t, zone.LocalZones[0].ZoneTransferAt)
+	require.NotNil(t, zone.LocalZones[0].ZoneTransferSerial)
+	require.EqualValues(t, 2024031502, *zone.LocalZones[0].ZoneTransferSerial)
+
+	// The DNSSEC state should be determined for the transferred serial.
+	require.NotNil(t, zone.LocalZones[0].DNSSEC)
+	require.EqualValues(t, 2024031502, zone.LocalZones[0].DNSSEC.Serial)
+}
+
+// Test that an error is returned when the zone transfer fails. The cached
+// records should remain intact.
+func TestFetchZoneRRsTransferError(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	controller := gomock.NewController(t)
+	mock := NewMockConnectedAgents(controller)
+
+	_, zone := addTestZoneForRRs(t, db)
+
+	rr, err := dns.NewRR("example.com. 3600 IN NS ns1.example.com.")
+	require.NoError(t, err)
+	err = dbmodel.ReplaceLocalZoneRRs(db, zone.LocalZones[0].ID, 2024031501, time.Now().UTC(), []*dbmodel.LocalZoneRR{dbmodel.NewLocalZoneRR(rr)})
+	require.NoError(t, err)
+
+	mock.EXPECT().ReceiveZoneRRs(gomock.Any(), gomock.Any(), "example.com", "trusted").DoAndReturn(func(context.Context, agentcomm.ControlledApp, string, string) iter.Seq2[[]dns.RR, error] {
+		return func(yield func([]dns.RR, error) bool) {
+			_ = yield(nil, &testError{})
+		}
+	})
+
+	manager := NewManager(&appstest.ManagerAccessorsWrapper{
+		DB:     db,
+		Agents: mock,
+	})
+	err = manager.FetchZoneRRs(context.Background(), zone.Name, zone.LocalZones[0])
+	require.ErrorContains(t, err, "test error")
+
+	_, total, err := dbmodel.GetLocalZoneRRs(db, zone.LocalZones[0].ID, nil)
+	require.NoError(t, err)
+	require.Equal(t, 1, total)
+}
+
+// Test that an error is returned when the zone transfer contains no
+// SOA record.
+func TestFetchZoneRRsNoSOA(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	controller := gomock.NewController(t)
+	mock := NewMockConnectedAgents(controller)
+
+	_, zone := addTestZoneForRRs(t, db)
+
+	rr, err := dns.NewRR("example.com. 3600 IN NS ns1.example.com.")
+	require.NoError(t, err)
+
+	mock.EXPECT().ReceiveZoneRRs(gomock.Any(), gomock.Any(), "example.com", "trusted").DoAndReturn(func(context.Context, agentcomm.ControlledApp, string, string) iter.Seq2[[]dns.RR, error] {
+		return func(yield func([]dns.RR, error) bool) {
+			_ = yield([]dns.RR{rr}, nil)
+		}
+	})
+
+	manager := NewManager(&appstest.ManagerAccessorsWrapper{
+		DB:     db,
+		Agents: mock,
+	})
+	err = manager.FetchZoneRRs(context.Background(), zone.Name, zone.LocalZones[0])
+	require.ErrorContains(t, err, "returned no SOA record")
+}
+
+// Test checking the DNSSEC state of the zones. The serial of the zone
+// should be refreshed. The DNSSEC records of the signed zone with the
+// signatures expiring soon should be queried in each check, and the
+// warning event should be raised once.
+func TestCheckZonesDNSSEC(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	controller := gomock.NewController(t)
+	mock := NewMockConnectedAgents(controller)
+
+	app, zone := addTestZoneForRRs(t, db)
+
+	ksk := generateTestDNSKEY(t, "example.com", 257)
+	expiresAt := storkutil.UTCNow().Add(24 * time.Hour).Truncate(time.Second)
+	soa := newTestRR(t, "example.com. 3600 IN SOA ns1.example.com. admin.example.com. 2024031502 43200 3600 1209600 3600")
+	rrs := []dns.RR{
+		ksk,
+		newTestRRSIG("example.com", dns.TypeDNSKEY, ksk.KeyTag(), expiresAt),
+		newTestRR(t, "example.com. 0 IN NSEC3PARAM 1 0 0 -"),
+	}
+
+	isApp := gomock.Cond(func(a any) bool {
+		return a.(*dbmodel.App).ID == app.ID
+	})
+	mock.EXPECT().QueryZoneRRs(gomock.Any(), isApp, "example.com", "trusted", []uint16{dns.TypeSOA}).
+		Times(2).Return([]dns.RR{soa}, nil)
+	// The DNSSEC records should be queried twice because the signatures
+	// expire soon.
+	mock.EXPECT().QueryZoneRRs(gomock.Any(), isApp, "example.com", "trusted", []uint16{
+		dns.TypeDNSKEY, dns.TypeCDS, dns.TypeNSEC, dns.TypeNSEC3PARAM, dns.TypeRRSIG,
+	}).Times(2).Return(rrs, nil)
+	mock.EXPECT().ForwardRndcCommand(gomock.Any(), gomock.Any(), "dnssec -status example.com IN trusted").Times(2).Return(&agentcomm.RndcOutput{
+		Output: "dnssec-policy: default",
+	}, nil)
+
+	eventCenter := &storktest.FakeEventCenter{}
+	manager := NewManager(&appstest.ManagerAccessorsWrapper{
+		DB:          db,
+		Agents:      mock,
+		EventCenter: eventCenter,
+	})
+
+	err := manager.CheckZonesDNSSEC(context.Background())
+	require.NoError(t, err)
+
+	zone, err = dbmodel.GetZoneByID(db, zone.ID, dbmodel.ZoneRelationLocalZones)
+	require.NoError(t, err)
+	require.EqualValues(t, 2024031502, zone.LocalZones[0].Serial)
+	dnssec := zone.LocalZones[0].DNSSEC
+	require.NotNil(t, dnssec)
+	require.True(t, dnssec.Signed)
+	require.EqualValues(t, 2024031502, dnssec.Serial)
+	require.Equal(t, dbmodel.ZoneDNSSECDenialNSEC3, dnssec.Denial)
+	require.Len(t, dnssec.Keys, 1)
+	require.Equal(t, ksk.KeyTag(), dnssec.Keys[0].KeyTag)
+	require.NotNil(t, dnssec.RRSIGExpiresAt)
+	require.Equal(t, expiresAt, dnssec.RRSIGExpiresAt.UTC())
+	require.Equal(t, "dnssec-policy: default", dnssec.RndcStatus)
+
+	require.Len(t, eventCenter.Events, 1)
+	require.Equal(t, dbmodel.EvWarning, eventCenter.Events[0].Level)
+	require.Contains(t, eventCenter.Events[0].Text, "DNSSEC signatures of zone example.com in view trusted")
+	require.Equal(t, app.Daemons[0].ID, eventCenter.Events[0].Relations.DaemonID)
+
+	// Check again. The DNSSEC records should be queried again but no new
+	// event should be raised.
+	err = manager.CheckZonesDNSSEC(context.Background())
+	require.NoError(t, err)
+	require.Len(t, eventCenter.Events, 1)
+}
+
+// Test that the DNSSEC records are not queried again for the zones whose
+// serial has not changed since the last check and whose signatures do not
+// expire soon. The zones should never be transferred.
+func TestCheckZonesDNSSECUnchanged(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	controller := gomock.NewController(t)
+	mock := NewMockConnectedAgents(controller)
+
+	_, zone := addTestZoneForRRs(t, db)
+	soa := newTestRR(t, "example.com. 3600 IN SOA ns1.example.com. admin.example.com. 2024031501 43200 3600 1209600 3600")
+
+	// The serial should be refreshed in each check. The DNSSEC records of
+	// the unsigned zone should be queried once and no rndc command should
+	// be sent.
+	mock.EXPECT().QueryZoneRRs(gomock.Any(), gomock.Any(), "example.com", "trusted", []uint16{dns.TypeSOA}).
+		Times(2).Return([]dns.RR{soa}, nil)
+	mock.EXPECT().QueryZoneRRs(gomock.Any(), gomock.Any(), "example.com", "trusted", gomock.Len(5)).
+		Return(nil, nil)
+
+	eventCenter := &storktest.FakeEventCenter{}
+	manager := NewManager(&appstest.ManagerAccessorsWrapper{
+		DB:          db,
+		Agents:      mock,
+		EventCenter: eventCenter,
+	})
+
+	err := manager.CheckZonesDNSSEC(context.Background())
+	require.NoError(t, err)
+	err = manager.CheckZonesDNSSEC(context.Background())
+	require.NoError(t, err)
+
+	zone, err = dbmodel.GetZoneByID(db, zone.ID, dbmodel.ZoneRelationLocalZones)
+	require.NoError(t, err)
+	require.NotNil(t, zone.LocalZones[0].DNSSEC)
+	require.False(t, zone.LocalZones[0].DNSSEC.Signed)
+	require.EqualValues(t, 2024031501, zone.LocalZones[0].DNSSEC.Serial)
+	require.Empty(t, eventCenter.Events)
+}
+
+// Test that the errors are returned when the zones cannot be queried or
+// the query returns no SOA record.
+func TestCheckZonesDNSSECQueryError(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	controller := gomock.NewController(t)
+	mock := NewMockConnectedAgents(controller)
+
+	_, zone := addTestZoneForRRs(t, db)
+
+	gomock.InOrder(
+		mock.EXPECT().QueryZoneRRs(gomock.Any(), gomock.Any(), "example.com", "trusted", []uint16{dns.TypeSOA}).
+			Return(nil, &testError{}),
+		mock.EXPECT().QueryZoneRRs(gomock.Any(), gomock.Any(), "example.com", "trusted", []uint16{dns.TypeSOA}).
+			Return(nil, nil),
+	)
+
+	manager := NewManager(&appstest.ManagerAccessorsWrapper{
+		DB:          db,
+		Agents:      mock,
+		EventCenter: &storktest.FakeEventCenter{},
+	})
+	err := manager.CheckZonesDNSSEC(context.Background())
+	require.ErrorContains(t, err, "test error")
+
+	err = manager.CheckZonesDNSSEC(context.Background())
+	require.ErrorContains(t, err, "returned no SOA record")
+
+	// The DNSSEC state should not be determined.
+	zone, err = dbmodel.GetZoneByID(db, zone.ID, dbmodel.ZoneRelationLocalZones)
+	require.NoError(t, err)
+	require.Nil(t, zone.LocalZones[0].DNSSEC)
+}
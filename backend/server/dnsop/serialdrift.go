@@ -0,0 +1,195 @@
+package dnsop
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	dbmodel "isc.org/stork/server/database/model"
+	storkutil "isc.org/stork/util"
+)
+
+// Checks if the zone serial is behind the other serial according to the
+// serial number arithmetic (RFC 1982). The serials differing by exactly
+// 2^31 are incomparable and the function returns false for them.
+func isSerialBehind(serial, otherSerial int64) bool {
+	return int32(uint32(otherSerial)-uint32(serial)) > 0 //nolint:gosec
+}
+
+// Copies of the same zone in the same view served by different DNS
+// servers.
+type zoneCopies struct {
+	zoneID     int64
+	view       string
+	localZones []*dbmodel.LocalZone
+}
+
+// Returns the primary zone copy with the highest serial. It returns nil
+// if there are no primary zone copies.
+func (copies *zoneCopies) getPrimary() *dbmodel.LocalZone {
+	var primary *dbmodel.LocalZone
+	for _, localZone := range copies.localZones {
+		if localZone.Type != string(dbmodel.ZoneTypePrimary) {
+			continue
+		}
+		if primary == nil || isSerialBehind(primary.Serial, localZone.Serial) {
+			primary = localZone
+		}
+	}
+	return primary
+}
+
+// Groups the local zones by zone and view. The local zones must be
+// ordered by zone and view.
+func groupZoneCopies(localZones []*dbmodel.LocalZone) []*zoneCopies {
+	var groups []*zoneCopies
+	for _, localZone := range localZones {
+		if len(groups) == 0 || groups[len(groups)-1].zoneID != localZone.ZoneID || groups[len(groups)-1].view != localZone.View {
+			groups = append(groups, &zoneCopies{
+				zoneID: localZone.ZoneID,
+				view:   localZone.View,
+			})
+		}
+		groups[len(groups)-1].localZones = append(groups[len(groups)-1].localZones, localZone)
+	}
+	return groups
+}
+
+// Returns the new serial drift of the secondary zone copy with respect to
+// the primary zone copy. The primary may be nil. It returns nil when the
+// secondary zone is not lagging behind the primary zone. The time when
+// the lag was first observed is preserved from the current drift unless
+// the secondary zone serial has changed since then.
+func newZoneSerialDrift(secondary, primary *dbmodel.LocalZone, now time.Time) *dbmodel.ZoneSerialDrift {
+	if primary == nil || !isSerialBehind(secondary.Serial, primary.Serial) {
+		return nil
+	}
+	drift := &dbmodel.ZoneSerialDrift{
+		Serial:          secondary.Serial,
+		PrimaryDaemonID: primary.DaemonID,
+		PrimarySerial:   primary.Serial,
+		Since:           now,
+	}
+	if secondary.SerialDrift != nil && secondary.SerialDrift.Serial == secondary.Serial {
+		drift.Since = secondary.SerialDrift.Since
+		drift.Notified = secondary.SerialDrift.Notified
+	}
+	return drift
+}
+
+// Compares the serials of the zone copies served by different DNS servers.
+// It records the serial drift of the secondary zones lagging behind the
+// primary zones and raises a warning event when the drift exceeds the
+// configured threshold. It implements the Manager interface. The serials
+// of the zone copies are refreshed by querying their SOA records before
+// the comparison. The drift of a secondary zone whose serial could not be
+// refreshed is left intact, so it is not escalated without observing the
+// current serial. It returns the last encountered error.
+func (manager *managerImpl) CheckZonesSerialDrift(ctx context.Context) error {
+	thresholdSeconds, err := dbmodel.GetSettingInt(manager.db, "zone_serial_drift_threshold")
+	if err != nil {
+		return err
+	}
+	threshold := time.Duration(thresholdSeconds) * time.Second
+
+	localZones, err := dbmodel.GetLocalZonesForSerialDriftCheck(manager.db)
+	if err != nil {
+		return err
+	}
+	now := storkutil.UTCNow()
+	var lastErr error
+	driftCount := 0
+	daemons := make(map[int64]*dbmodel.Daemon)
+	for _, copies := range groupZoneCopies(localZones) {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		observed := make(map[int64]bool)
+		for _, localZone := range copies.localZones {
+			if err := manager.refreshLocalZoneSerial(ctx, daemons, localZone); err != nil {
+				lastErr = err
+				log.WithFields(log.Fields{
+					"zone": localZone.Zone.Name,
+					"view": localZone.View,
+				}).WithError(err).Warn("Failed to refresh the serial of the zone")
+				continue
+			}
+			observed[localZone.ID] = true
+		}
+		// The primary zone copies whose serials could not be refreshed are
+		// still taken into account. Their serials can only be lower than
+		// the current ones.
+		primary := copies.getPrimary()
+		for _, localZone := range copies.localZones {
+			if !observed[localZone.ID] {
+				// The current serial is unknown.
+				if localZone.SerialDrift != nil {
+					driftCount++
+				}
+				continue
+			}
+			// The drift is cleared when the zone is no longer a secondary zone.
+			var drift *dbmodel.ZoneSerialDrift
+			if localZone.Type == string(dbmodel.ZoneTypeSecondary) {
+				drift = newZoneSerialDrift(localZone, primary, now)
+			}
+			if drift != nil {
+				driftCount++
+			}
+			notify := drift.ExceedsThreshold(now, threshold) && !drift.Notified
+			if notify {
+				drift.Notified = true
+			}
+			if drift == nil && localZone.SerialDrift == nil || drift != nil && localZone.SerialDrift != nil && *drift == *localZone.SerialDrift {
+				// Nothing changed.
+				continue
+			}
+			if err := dbmodel.UpdateLocalZoneSerialDrift(manager.db, localZone.ID, drift); err != nil {
+				lastErr = err
+				log.WithFields(log.Fields{
+					"zone": localZone.Zone.Name,
+					"view": localZone.View,
+				}).WithError(err).Warn("Failed to update the serial drift of the zone")
+				continue
+			}
+			localZone.SerialDrift = drift
+			if notify {
+				manager.notifySerialDrift(localZone, primary)
+			}
+		}
+	}
+	log.Infof("Completed checking the serial drift of the zones: %d secondary zones lagging behind the primary zones", driftCount)
+	return lastErr
+}
+
+// Refreshes the serial of the local zone by querying its SOA record from
+// the DNS server. The daemons map caches the daemons serving the zones.
+func (manager *managerImpl) refreshLocalZoneSerial(ctx context.Context, daemons map[int64]*dbmodel.Daemon, localZone *dbmodel.LocalZone) error {
+	daemon, err := manager.getLocalZoneDaemon(daemons, localZone)
+	if err != nil {
+		return err
+	}
+	_, err = manager.refreshZoneSerial(ctx, daemon, localZone.Zone.Name, localZone)
+	return err
+}
+
+// Raises a warning event indicating that the secondary zone has been
+// lagging behind the primary zone for longer than the threshold.
+func (manager *managerImpl) notifySerialDrift(secondary, primary *dbmodel.LocalZone) {
+	daemon, err := dbmodel.GetDaemonByID(manager.db, secondary.DaemonID)
+	if err != nil || daemon == nil {
+		log.WithField("daemon", secondary.DaemonID).WithError(err).Warn("Failed to get the daemon serving the lagging zone")
+		return
+	}
+	primaryServer := fmt.Sprintf("daemon %d", primary.DaemonID)
+	if primaryDaemon, err := dbmodel.GetDaemonByID(manager.db, primary.DaemonID); err == nil && primaryDaemon != nil && primaryDaemon.App != nil {
+		primaryServer = primaryDaemon.App.Name
+	}
+	manager.eventCenter.AddWarningEvent(
+		fmt.Sprintf("Zone %s in view %s on {daemon} has been lagging behind the primary zone on %s since %s: serial %d, primary serial %d",
+			secondary.Zone.Name, secondary.View, primaryServer, secondary.SerialDrift.Since.Format(time.RFC3339),
+			secondary.Serial, primary.Serial),
+		daemon,
+	)
+}
@@ -0,0 +1,417 @@
+package dnsop
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/go-pg/pg/v10"
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/require"
+	gomock "go.uber.org/mock/gomock"
+	agentcomm "isc.org/stork/server/agentcomm"
+	appstest "isc.org/stork/server/apps/test"
+	dbmodel "isc.org/stork/server/database/model"
+	dbtest "isc.org/stork/server/database/test"
+	storktest "isc.org/stork/server/test/dbmodel"
+)
+
+// Test comparing the zone serials using the serial number arithmetic.
+func TestIsSerialBehind(t *testing.T) {
+	require.True(t, isSerialBehind(1, 2))
+	require.False(t, isSerialBehind(2, 1))
+	require.False(t, isSerialBehind(2, 2))
+	require.True(t, isSerialBehind(2024031501, 2024031502))
+
+	// Wrapping around.
+	require.True(t, isSerialBehind(4294967295, 0))
+	require.True(t, isSerialBehind(4294967000, 100))
+	require.False(t, isSerialBehind(100, 4294967000))
+
+	// Incomparable serials.
+	require.False(t, isSerialBehind(0, 2147483648))
+	require.False(t, isSerialBehind(2147483648, 0))
+}
+
+// Test grouping the local zones by zone and view.
+func TestGroupZoneCopies(t *testing.T) {
+	localZones := []*dbmodel.LocalZone{
+		{ID: 1, ZoneID: 1, View: "_default"},
+		{ID: 2, ZoneID: 1, View: "_default"},
+		{ID: 3, ZoneID: 1, View: "trusted"},
+		{ID: 4, ZoneID: 2, View: "trusted"},
+		{ID: 5, ZoneID: 2, View: "trusted"},
+	}
+	groups := groupZoneCopies(localZones)
+	require.Len(t, groups, 3)
+
+	require.EqualValues(t, 1, groups[0].zoneID)
+	require.Equal(t, "_default", groups[0].view)
+	require.Len(t, groups[0].localZones, 2)
+
+	require.EqualValues(t, 1, groups[1].zoneID)
+	require.Equal(t, "trusted", groups[1].view)
+	require.Len(t, groups[1].localZones, 1)
+
+	require.EqualValues(t, 2, groups[2].zoneID)
+	require.Equal(t, "trusted", groups[2].view)
+	require.Len(t, groups[2].localZones, 2)
+
+	require.Empty(t, groupZoneCopies(nil))
+}
+
+// Test selecting the primary zone copy with the highest serial.
+func TestZoneCopiesGetPrimary(t *testing.T) {
+	copies := &zoneCopies{
+		localZones: []*dbmodel.LocalZone{
+			{ID: 1, Type: "secondary", Serial: 5},
+		},
+	}
+	require.Nil(t, copies.getPrimary())
+
+	copies.localZones = append(copies.localZones,
+		&dbmodel.LocalZone{ID: 2, Type: "primary", Serial: 4294967295},
+		&dbmodel.LocalZone{ID: 3, Type: "primary", Serial: 1},
+		&dbmodel.LocalZone{ID: 4, Type: "primary", Serial: 4294967000},
+	)
+	primary := copies.getPrimary()
+	require.NotNil(t, primary)
+	require.EqualValues(t, 3, primary.ID)
+}
+
+// Test determining the serial drift of the secondary zone.
+func TestNewZoneSerialDrift(t *testing.T) {
+	now := time.Date(2025, 3, 15, 10, 0, 0, 0, time.UTC)
+	primary := &dbmodel.LocalZone{DaemonID: 1, Type: "primary", Serial: 10}
+	secondary := &dbmodel.LocalZone{DaemonID: 2, Type: "secondary", Serial: 10}
+
+	// No primary zone.
+	require.Nil(t, newZoneSerialDrift(secondary, nil, now))
+
+	// The secondary zone is up to date.
+	require.Nil(t, newZoneSerialDrift(secondary, primary, now))
+
+	// The secondary zone is lagging behind.
+	secondary.Serial = 9
+	drift := newZoneSerialDrift(secondary, primary, now)
+	require.NotNil(t, drift)
+	require.EqualValues(t, 9, drift.Serial)
+	require.EqualValues(t, 1, drift.PrimaryDaemonID)
+	require.EqualValues(t, 10, drift.PrimarySerial)
+	require.Equal(t, now, drift.Since)
+	require.False(t, drift.Notified)
+
+	// The time when the lag was first observed should be preserved.
+	drift.Notified = true
+	secondary.SerialDrift = drift
+	primary.Serial = 11
+	drift = newZoneSerialDrift(secondary, primary, now.Add(time.Hour))
+	require.NotNil(t, drift)
+	require.EqualValues(t, 11, drift.PrimarySerial)
+	require.Equal(t, now, drift.Since)
+	require.True(t, drift.Notified)
+
+	// The secondary zone has been updated but it is still lagging behind.
+	secondary.Serial = 10
+	drift = newZoneSerialDrift(secondary, primary, now.Add(time.Hour))
+	require.NotNil(t, drift)
+	require.EqualValues(t, 10, drift.Serial)
+	require.Equal(t, now.Add(time.Hour), drift.Since)
+	require.False(t, drift.Notified)
+}
+
+// Adds a zone served by a primary server and two secondary servers.
+// The first secondary zone is up to date and the second one lags behind.
+func addTestZoneForSerialDrift(t *testing.T, db *pg.DB) []*dbmodel.App {
+	var apps []*dbmodel.App
+	var localZones []*dbmodel.LocalZone
+	for i, zone := range []struct {
+		zoneType string
+		serial   int64
+	}{
+		{"primary", 2024031502},
+		{"secondary", 2024031502},
+		{"secondary", 2024031501},
+	} {
+		machine := &dbmodel.Machine{
+			Address:   fmt.Sprintf("host%d", i),
+			AgentPort: int64(8080),
+		}
+		err := dbmodel.AddMachine(db, machine)
+		require.NoError(t, err)
+
+		app := &dbmodel.App{
+			MachineID: machine.ID,
+			Type:      dbmodel.AppTypeBind9,
+			Name:      fmt.Sprintf("bind9-%d", i),
+			Daemons: []*dbmodel.Daemon{
+				dbmodel.NewBind9Daemon(true),
+			},
+		}
+		_, err = dbmodel.AddApp(db, app)
+		require.NoError(t, err)
+		apps = append(apps, app)
+
+		localZones = append(localZones, &dbmodel.LocalZone{
+			DaemonID: app.Daemons[0].ID,
+			View:     "_default",
+			Class:    "IN",
+			Serial:   zone.serial,
+			Type:     zone.zoneType,
+			LoadedAt: time.Now().UTC(),
+		})
+	}
+	err := dbmodel.AddZones(db, &dbmodel.Zone{
+		Name:       "example.com",
+		LocalZones: localZones,
+	})
+	require.NoError(t, err)
+	return apps
+}
+
+// Mocks querying the SOA records of the zone copies. The serials are taken
+// from the map indexed by the app ID. The query fails when the map lacks
+// the serial for the app.
+func mockTestSerialDriftQueries(mock *MockConnectedAgents, serials map[int64]int64) {
+	mock.EXPECT().QueryZoneRRs(gomock.Any(), gomock.Any(), "example.com", "_default", []uint16{dns.TypeSOA}).AnyTimes().
+		DoAndReturn(func(ctx context.Context, app agentcomm.ControlledApp, zoneName, viewName string, rrTypes []uint16) ([]dns.RR, error) {
+			serial, ok := serials[app.(*dbmodel.App).ID]
+			if !ok {
+				return nil, &testError{}
+			}
+			return []dns.RR{
+				&dns.SOA{
+					Hdr: dns.RR_Header{
+						Name:   "example.com.",
+						Rrtype: dns.TypeSOA,
+						Class:  dns.ClassINET,
+						Ttl:    3600,
+					},
+					Ns:     "ns1.example.com.",
+					Mbox:   "admin.example.com.",
+					Serial: uint32(serial), //nolint:gosec
+				},
+			}, nil
+		})
+}
+
+// Returns the serial drift of the local zone served by the daemon.
+func getTestSerialDrift(t *testing.T, db *pg.DB, daemonID int64) *dbmodel.ZoneSerialDrift {
+	zones, _, err := dbmodel.GetZones(db, nil, dbmodel.ZoneRelationLocalZones)
+	require.NoError(t, err)
+	require.Len(t, zones, 1)
+	for _, localZone := range zones[0].LocalZones {
+		if localZone.DaemonID == daemonID {
+			return localZone.SerialDrift
+		}
+	}
+	require.FailNow(t, "local zone not found", "daemon %d", daemonID)
+	return nil
+}
+
+// Test recording the serial drift of the secondary zones and raising
+// the events when the drift exceeds the threshold.
+func TestCheckZonesSerialDrift(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	err := dbmodel.InitializeSettings(db, 0)
+	require.NoError(t, err)
+
+	apps := addTestZoneForSerialDrift(t, db)
+
+	controller := gomock.NewController(t)
+	mock := NewMockConnectedAgents(controller)
+	serials := map[int64]int64{
+		apps[0].ID: 2024031502,
+		apps[1].ID: 2024031502,
+		apps[2].ID: 2024031501,
+	}
+	mockTestSerialDriftQueries(mock, serials)
+
+	eventCenter := &storktest.FakeEventCenter{}
+	manager := NewManager(&appstest.ManagerAccessorsWrapper{
+		DB:          db,
+		Agents:      mock,
+		EventCenter: eventCenter,
+	})
+
+	// The drift should be recorded but it doesn't exceed the default
+	// threshold yet.
+	err = manager.CheckZonesSerialDrift(context.Background())
+	require.NoError(t, err)
+
+	require.Nil(t, getTestSerialDrift(t, db, apps[0].Daemons[0].ID))
+	require.Nil(t, getTestSerialDrift(t, db, apps[1].Daemons[0].ID))
+	drift := getTestSerialDrift(t, db, apps[2].Daemons[0].ID)
+	require.NotNil(t, drift)
+	require.EqualValues(t, 2024031501, drift.Serial)
+	require.Equal(t, apps[0].Daemons[0].ID, drift.PrimaryDaemonID)
+	require.EqualValues(t, 2024031502, drift.PrimarySerial)
+	require.False(t, drift.Notified)
+	since := drift.Since
+	require.Empty(t, eventCenter.Events)
+
+	// Lower the threshold. The event should be raised.
+	err = dbmodel.SetSettingInt(db, "zone_serial_drift_threshold", 0)
+	require.NoError(t, err)
+
+	err = manager.CheckZonesSerialDrift(context.Background())
+	require.NoError(t, err)
+
+	drift = getTestSerialDrift(t, db, apps[2].Daemons[0].ID)
+	require.NotNil(t, drift)
+	require.Equal(t, since, drift.Since)
+	require.True(t, drift.Notified)
+
+	require.Len(t, eventCenter.Events, 1)
+	require.Equal(t, dbmodel.EvWarning, eventCenter.Events[0].Level)
+	require.Contains(t, eventCenter.Events[0].Text, "Zone example.com in view _default on ")
+	require.Contains(t, eventCenter.Events[0].Text, "has been lagging behind the primary zone on bind9-0")
+	require.Contains(t, eventCenter.Events[0].Text, "serial 2024031501, primary serial 2024031502")
+	require.Equal(t, apps[2].Daemons[0].ID, eventCenter.Events[0].Relations.DaemonID)
+
+	// Check again. No new event should be raised.
+	err = manager.CheckZonesSerialDrift(context.Background())
+	require.NoError(t, err)
+	require.Len(t, eventCenter.Events, 1)
+
+	// The secondary zone caught up with the primary zone. The new serial
+	// should be observed without fetching the zones.
+	serials[apps[2].ID] = 2024031502
+
+	err = manager.CheckZonesSerialDrift(context.Background())
+	require.NoError(t, err)
+	require.Nil(t, getTestSerialDrift(t, db, apps[2].Daemons[0].ID))
+	require.Len(t, eventCenter.Events, 1)
+}
+
+// Test that the serials are refreshed before the comparison and that the
+// drift is not escalated when the serial of the secondary zone cannot be
+// refreshed.
+func TestCheckZonesSerialDriftRefresh(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	err := dbmodel.InitializeSettings(db, 0)
+	require.NoError(t, err)
+	err = dbmodel.SetSettingInt(db, "zone_serial_drift_threshold", 0)
+	require.NoError(t, err)
+
+	apps := addTestZoneForSerialDrift(t, db)
+
+	controller := gomock.NewController(t)
+	mock := NewMockConnectedAgents(controller)
+	// The serial of the last secondary zone cannot be refreshed.
+	serials := map[int64]int64{
+		apps[0].ID: 2024031503,
+		apps[1].ID: 2024031503,
+	}
+	mockTestSerialDriftQueries(mock, serials)
+
+	eventCenter := &storktest.FakeEventCenter{}
+	manager := NewManager(&appstest.ManagerAccessorsWrapper{
+		DB:          db,
+		Agents:      mock,
+		EventCenter: eventCenter,
+	})
+
+	err = manager.CheckZonesSerialDrift(context.Background())
+	require.ErrorContains(t, err, "test error")
+
+	// The refreshed serials should be stored in the database.
+	zones, _, err := dbmodel.GetZones(db, nil, dbmodel.ZoneRelationLocalZones)
+	require.NoError(t, err)
+	require.Len(t, zones, 1)
+	for _, localZone := range zones[0].LocalZones {
+		switch localZone.DaemonID {
+		case apps[2].Daemons[0].ID:
+			require.EqualValues(t, 2024031501, localZone.Serial)
+		default:
+			require.EqualValues(t, 2024031503, localZone.Serial)
+		}
+	}
+
+	// The secondary zone whose serial was refreshed caught up with the
+	// primary zone. The drift of the other secondary zone should not be
+	// recorded without observing its serial.
+	require.Nil(t, getTestSerialDrift(t, db, apps[1].Daemons[0].ID))
+	require.Nil(t, getTestSerialDrift(t, db, apps[2].Daemons[0].ID))
+	require.Empty(t, eventCenter.Events)
+
+	// The serial can be refreshed now. The drift should be recorded
+	// against the refreshed primary serial.
+	serials[apps[2].ID] = 2024031502
+
+	err = manager.CheckZonesSerialDrift(context.Background())
+	require.NoError(t, err)
+	drift := getTestSerialDrift(t, db, apps[2].Daemons[0].ID)
+	require.NotNil(t, drift)
+	require.EqualValues(t, 2024031502, drift.Serial)
+	require.EqualValues(t, 2024031503, drift.PrimarySerial)
+	require.True(t, drift.Notified)
+	require.Len(t, eventCenter.Events, 1)
+
+	// The serial cannot be refreshed again. The drift should be left intact.
+	delete(serials, apps[2].ID)
+
+	err = manager.CheckZonesSerialDrift(context.Background())
+	require.ErrorContains(t, err, "test error")
+	require.Equal(t, drift, getTestSerialDrift(t, db, apps[2].Daemons[0].ID))
+	require.Len(t, eventCenter.Events, 1)
+}
+
+// Test that the serial drift is cleared when the primary zone is gone.
+func TestCheckZonesSerialDriftNoPrimary(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	err := dbmodel.InitializeSettings(db, 0)
+	require.NoError(t, err)
+
+	apps := addTestZoneForSerialDrift(t, db)
+
+	controller := gomock.NewController(t)
+	mock := NewMockConnectedAgents(controller)
+	mockTestSerialDriftQueries(mock, map[int64]int64{
+		apps[0].ID: 2024031502,
+		apps[1].ID: 2024031502,
+		apps[2].ID: 2024031501,
+	})
+
+	manager := NewManager(&appstest.ManagerAccessorsWrapper{
+		DB:          db,
+		Agents:      mock,
+		EventCenter: &storktest.FakeEventCenter{},
+	})
+
+	err = manager.CheckZonesSerialDrift(context.Background())
+	require.NoError(t, err)
+	require.NotNil(t, getTestSerialDrift(t, db, apps[2].Daemons[0].ID))
+
+	err = dbmodel.DeleteLocalZones(db, apps[0].Daemons[0].ID)
+	require.NoError(t, err)
+
+	err = manager.CheckZonesSerialDrift(context.Background())
+	require.NoError(t, err)
+	require.Nil(t, getTestSerialDrift(t, db, apps[2].Daemons[0].ID))
+}
+
+// Test that an error is returned when the threshold setting is missing.
+func TestCheckZonesSerialDriftNoSettings(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	controller := gomock.NewController(t)
+	mock := NewMockConnectedAgents(controller)
+
+	manager := NewManager(&appstest.ManagerAccessorsWrapper{
+		DB:          db,
+		Agents:      mock,
+		EventCenter: &storktest.FakeEventCenter{},
+	})
+
+	err := manager.CheckZonesSerialDrift(context.Background())
+	require.Error(t, err)
+}
@@ -0,0 +1,43 @@
+package dnsop
+
+import (
+	"context"
+
+	"github.com/go-pg/pg/v10"
+	"isc.org/stork/server/agentcomm"
+)
+
+// The puller periodically comparing the serials of the zones served by
+// different DNS servers.
+type ZoneSerialDriftPuller struct {
+	*agentcomm.PeriodicPuller
+	manager Manager
+}
+
+// Creates a ZoneSerialDriftPuller object that in background refreshes and
+// compares the serials of the primary and secondary zones using the DNS
+// Manager.
+func NewZoneSerialDriftPuller(db *pg.DB, agents agentcomm.ConnectedAgents, manager Manager) (*ZoneSerialDriftPuller, error) {
+	serialDriftPuller := &ZoneSerialDriftPuller{
+		manager: manager,
+	}
+	periodicPuller, err := agentcomm.NewPeriodicPuller(db, agents, "Zone serial drift puller", "zone_serial_drift_puller_interval",
+		serialDriftPuller.pullSerialDrift)
+	if err != nil {
+		return nil, err
+	}
+	serialDriftPuller.PeriodicPuller = periodicPuller
+	return serialDriftPuller, nil
+}
+
+// Shutdown ZoneSerialDriftPuller. It stops goroutine that compares the
+// zone serials.
+func (serialDriftPuller *ZoneSerialDriftPuller) Shutdown() {
+	serialDriftPuller.PeriodicPuller.Shutdown()
+}
+
+// Compares the serials of the zones. The function returns last
+// encountered error.
+func (serialDriftPuller *ZoneSerialDriftPuller) pullSerialDrift() error {
+	return serialDriftPuller.manager.CheckZonesSerialDrift(context.Background())
+}
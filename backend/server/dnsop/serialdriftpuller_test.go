@@ -0,0 +1,35 @@
+package dnsop
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	gomock "go.uber.org/mock/gomock"
+	appstest "isc.org/stork/server/apps/test"
+	dbmodel "isc.org/stork/server/database/model"
+	dbtest "isc.org/stork/server/database/test"
+)
+
+// Check creating and shutting down ZoneSerialDriftPuller.
+func TestZoneSerialDriftPullerBasic(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	err := dbmodel.InitializeSettings(db, 0)
+	require.NoError(t, err)
+
+	controller := gomock.NewController(t)
+	mock := NewMockConnectedAgents(controller)
+
+	manager := NewManager(&appstest.ManagerAccessorsWrapper{
+		DB:     db,
+		Agents: mock,
+	})
+
+	puller, err := NewZoneSerialDriftPuller(db, mock, manager)
+	require.NoError(t, err)
+	require.NotNil(t, puller)
+	require.Equal(t, "Zone serial drift puller", puller.GetName())
+	require.Equal(t, "zone_serial_drift_puller_interval", puller.GetIntervalSettingName())
+	puller.Shutdown()
+}
@@ -0,0 +1,185 @@
+package dnsop
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/go-pg/pg/v10"
+	"github.com/miekg/dns"
+	pkgerrors "github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	dbmodel "isc.org/stork/server/database/model"
+)
+
+// An error returned when the user is not allowed to update the zone.
+type ManagerPermissionDeniedError struct {
+	zoneName string
+}
+
+// Returns the error as text.
+func (error *ManagerPermissionDeniedError) Error() string {
+	return fmt.Sprintf("user is not allowed to update zone %s", error.zoneName)
+}
+
+// An error returned when the requested zone update is invalid, e.g., it
+// contains records of unsupported types or the records outside the zone.
+type ManagerInvalidZoneUpdateError struct {
+	reason string
+}
+
+// Returns the error as text.
+func (error *ManagerInvalidZoneUpdateError) Error() string {
+	return error.reason
+}
+
+// Creates the error indicating that the zone update is invalid.
+func newManagerInvalidZoneUpdateError(format string, args ...any) *ManagerInvalidZoneUpdateError {
+	return &ManagerInvalidZoneUpdateError{
+		reason: fmt.Sprintf(format, args...),
+	}
+}
+
+// Types of the resource records that can be added and deleted with the
+// zone updates.
+var zoneUpdateRRTypes = map[uint16]bool{
+	dns.TypeA:     true,
+	dns.TypeAAAA:  true,
+	dns.TypeCNAME: true,
+	dns.TypePTR:   true,
+	dns.TypeTXT:   true,
+}
+
+// Checks if the user is allowed to update the zone. The super-admin users
+// are allowed to update all zones. The other users are only allowed to
+// update the zones for which they have been granted the permission.
+func canUpdateZone(db pg.DBI, user *dbmodel.SystemUser, zoneID int64) (bool, error) {
+	if user == nil {
+		return false, nil
+	}
+	if user.InGroup(&dbmodel.SystemGroup{ID: dbmodel.SuperAdminGroupID}) {
+		return true, nil
+	}
+	return dbmodel.HasZoneUpdatePermission(db, user.ID, zoneID)
+}
+
+// Validates the resource records to be deleted or added in the zone. The
+// records must be in the IN class, belong to the zone and have one of the
+// supported types.
+func validateZoneUpdateRRs(zoneName string, rrs []dns.RR) error {
+	origin := dns.Fqdn(zoneName)
+	for _, rr := range rrs {
+		header := rr.Header()
+		if !zoneUpdateRRTypes[header.Rrtype] {
+			return newManagerInvalidZoneUpdateError("updating %s records is not supported; supported types are A, AAAA, CNAME, PTR and TXT", dns.TypeToString[header.Rrtype])
+		}
+		if header.Class != dns.ClassINET {
+			return newManagerInvalidZoneUpdateError("record %s is not in the IN class", header.Name)
+		}
+		if !dns.IsSubDomain(origin, header.Name) {
+			return newManagerInvalidZoneUpdateError("record %s does not belong to zone %s", header.Name, zoneName)
+		}
+	}
+	return nil
+}
+
+// Returns the types of the records as a comma-separated list of counts
+// for the event message, e.g., "2 A, 1 TXT".
+func summarizeZoneUpdateRRs(rrs []dns.RR) string {
+	var (
+		types  []uint16
+		counts = make(map[uint16]int)
+	)
+	for _, rr := range rrs {
+		rrtype := rr.Header().Rrtype
+		if counts[rrtype] == 0 {
+			types = append(types, rrtype)
+		}
+		counts[rrtype]++
+	}
+	var summary []string
+	for _, rrtype := range types {
+		summary = append(summary, fmt.Sprintf("%d %s", counts[rrtype], dns.TypeToString[rrtype]))
+	}
+	if len(summary) == 0 {
+		return "none"
+	}
+	return strings.Join(summary, ", ")
+}
+
+// Returns the records in the presentation format, one per line. They are
+// included in the event details.
+func formatZoneUpdateRRs(rrs []dns.RR) string {
+	var lines []string
+	for _, rr := range rrs {
+		lines = append(lines, rr.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Sends the dynamic update of the primary zone to the DNS server serving
+// the local zone via the agent. It implements the Manager interface. It
+// returns ManagerPermissionDeniedError when the user is not allowed to
+// update the zone and ManagerInvalidZoneUpdateError when the update is
+// invalid. It raises an event for each successful update. If the zone
+// records have been cached, they are transferred again to reflect the
+// update.
+func (manager *managerImpl) UpdateZone(ctx context.Context, user *dbmodel.SystemUser, zoneName string, localZone *dbmodel.LocalZone, deleteRRs, addRRs []dns.RR) error {
+	allowed, err := canUpdateZone(manager.db, user, localZone.ZoneID)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return &ManagerPermissionDeniedError{zoneName: zoneName}
+	}
+	if localZone.Type != string(dbmodel.ZoneTypePrimary) {
+		return newManagerInvalidZoneUpdateError("zone %s in view %s is not a primary zone", zoneName, localZone.View)
+	}
+	if len(deleteRRs) == 0 && len(addRRs) == 0 {
+		return newManagerInvalidZoneUpdateError("no records to delete or add in zone %s", zoneName)
+	}
+	if err := validateZoneUpdateRRs(zoneName, deleteRRs); err != nil {
+		return err
+	}
+	if err := validateZoneUpdateRRs(zoneName, addRRs); err != nil {
+		return err
+	}
+	daemon, err := dbmodel.GetDaemonByID(manager.db, localZone.DaemonID)
+	if err != nil {
+		return err
+	}
+	if daemon == nil || daemon.App == nil {
+		return pkgerrors.Wrapf(dbmodel.ErrNotExists, "daemon %d serving zone %s does not exist", localZone.DaemonID, zoneName)
+	}
+	if err = manager.agents.UpdateZone(ctx, daemon.App, zoneName, localZone.View, deleteRRs, addRRs); err != nil {
+		return err
+	}
+	log.WithFields(log.Fields{
+		"zone":    zoneName,
+		"view":    localZone.View,
+		"app":     daemon.App.Name,
+		"user":    user.Login,
+		"deleted": len(deleteRRs),
+		"added":   len(addRRs),
+	}).Info("Updated the zone")
+
+	details := fmt.Sprintf("Deleted records:\n%s\nAdded records:\n%s", formatZoneUpdateRRs(deleteRRs), formatZoneUpdateRRs(addRRs))
+	manager.eventCenter.AddInfoEvent(
+		fmt.Sprintf("{user} updated zone %s in view %s on {daemon}: deleted %s, added %s",
+			zoneName, localZone.View, summarizeZoneUpdateRRs(deleteRRs), summarizeZoneUpdateRRs(addRRs)),
+		user, daemon, details,
+	)
+
+	// Refresh the cached records. Otherwise, they would remain outdated
+	// until the new zone serial is fetched.
+	if localZone.ZoneTransferAt != nil {
+		if err = manager.FetchZoneRRs(ctx, zoneName, localZone); err != nil {
+			log.WithFields(log.Fields{
+				"zone": zoneName,
+				"view": localZone.View,
+				"app":  daemon.App.Name,
+			}).WithError(err).Warn("Failed to refresh the zone records after the update")
+		}
+	}
+	return nil
+}
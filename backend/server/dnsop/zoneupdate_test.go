@@ -0,0 +1,318 @@
+package dnsop
+
+import (
+	"context"
+	"iter"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/require"
+	gomock "go.uber.org/mock/gomock"
+	agentcomm "isc.org/stork/server/agentcomm"
+	appstest "isc.org/stork/server/apps/test"
+	dbmodel "isc.org/stork/server/database/model"
+	dbtest "isc.org/stork/server/database/test"
+	storktest "isc.org/stork/server/test/dbmodel"
+)
+
+// Returns a user belonging to the super-admin group.
+func getTestSuperAdmin() *dbmodel.SystemUser {
+	return &dbmodel.SystemUser{
+		ID:    1,
+		Login: "admin",
+		Groups: []*dbmodel.SystemGroup{
+			{ID: dbmodel.SuperAdminGroupID},
+		},
+	}
+}
+
+// Parses the resource records from the text.
+func parseTestRRs(t *testing.T, texts ...string) []dns.RR {
+	var rrs []dns.RR
+	for _, text := range texts {
+		rr, err := dns.NewRR(text)
+		require.NoError(t, err)
+		rrs = append(rrs, rr)
+	}
+	return rrs
+}
+
+// Test the errors returned by the zone updates.
+func TestZoneUpdateErrors(t *testing.T) {
+	permissionErr := &ManagerPermissionDeniedError{zoneName: "example.com"}
+	require.Equal(t, "user is not allowed to update zone example.com", permissionErr.Error())
+
+	invalidErr := newManagerInvalidZoneUpdateError("zone %s is invalid", "example.com")
+	require.Equal(t, "zone example.com is invalid", invalidErr.Error())
+}
+
+// Test checking if the user is allowed to update the zone.
+func TestCanUpdateZone(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	_, zone := addTestZoneForRRs(t, db)
+
+	user := &dbmodel.SystemUser{
+		Login:    "user",
+		Email:    "user@example.org",
+		Lastname: "Doe",
+		Name:     "John",
+		Groups:   []*dbmodel.SystemGroup{{ID: dbmodel.AdminGroupID}},
+	}
+	_, err := dbmodel.CreateUser(db, user)
+	require.NoError(t, err)
+
+	allowed, err := canUpdateZone(db, nil, zone.ID)
+	require.NoError(t, err)
+	require.False(t, allowed)
+
+	// The super-admin is allowed to update all zones.
+	allowed, err = canUpdateZone(db, getTestSuperAdmin(), zone.ID)
+	require.NoError(t, err)
+	require.True(t, allowed)
+
+	// The other users need the permission.
+	allowed, err = canUpdateZone(db, user, zone.ID)
+	require.NoError(t, err)
+	require.False(t, allowed)
+
+	err = dbmodel.SetZoneUpdatePermissions(db, zone.ID, []int{user.ID})
+	require.NoError(t, err)
+
+	allowed, err = canUpdateZone(db, user, zone.ID)
+	require.NoError(t, err)
+	require.True(t, allowed)
+}
+
+// Test validating the records to be updated in the zone.
+func TestValidateZoneUpdateRRs(t *testing.T) {
+	require.NoError(t, validateZoneUpdateRRs("example.com", parseTestRRs(t,
+		"example.com. 300 IN A 192.0.2.1",
+		"www.example.com. 300 IN AAAA 2001:db8::1",
+		"ftp.example.com. 300 IN CNAME www.example.com.",
+		"1.example.com. 300 IN PTR www.example.com.",
+		"WWW.EXAMPLE.COM. 300 IN TXT \"hello\"",
+	)))
+	require.NoError(t, validateZoneUpdateRRs("example.com", nil))
+
+	var invalidErr *ManagerInvalidZoneUpdateError
+
+	err := validateZoneUpdateRRs("example.com", parseTestRRs(t, "example.com. 300 IN MX 10 mail.example.com."))
+	require.ErrorAs(t, err, &invalidErr)
+	require.ErrorContains(t, err, "updating MX records is not supported")
+
+	err = validateZoneUpdateRRs("example.com", parseTestRRs(t, "www.example.com. 300 CH TXT \"hello\""))
+	require.ErrorAs(t, err, &invalidErr)
+	require.ErrorContains(t, err, "record www.example.com. is not in the IN class")
+
+	err = validateZoneUpdateRRs("example.com", parseTestRRs(t, "www.example.org. 300 IN A 192.0.2.1"))
+	require.ErrorAs(t, err, &invalidErr)
+	require.ErrorContains(t, err, "record www.example.org. does not belong to zone example.com")
+}
+
+// Test summarizing the updated records for the event message.
+func TestSummarizeZoneUpdateRRs(t *testing.T) {
+	require.Equal(t, "none", summarizeZoneUpdateRRs(nil))
+	require.Equal(t, "2 A, 1 TXT", summarizeZoneUpdateRRs(parseTestRRs(t,
+		"www.example.com. 300 IN A 192.0.2.1",
+		"mail.example.com. 300 IN TXT \"hello\"",
+		"www.example.com. 300 IN A 192.0.2.2",
+	)))
+}
+
+// Test that the invalid zone updates are rejected before contacting the
+// agent.
+func TestUpdateZoneInvalid(t *testing.T) {
+	controller := gomock.NewController(t)
+	mock := NewMockConnectedAgents(controller)
+
+	manager := NewManager(&appstest.ManagerAccessorsWrapper{
+		Agents:      mock,
+		EventCenter: &storktest.FakeEventCenter{},
+	})
+	localZone := &dbmodel.LocalZone{
+		DaemonID: 1,
+		View:     "_default",
+		Type:     "primary",
+	}
+	addRRs := parseTestRRs(t, "www.example.com. 300 IN A 192.0.2.1")
+
+	// Only the primary zones can be updated.
+	var invalidErr *ManagerInvalidZoneUpdateError
+	localZone.Type = "secondary"
+	err := manager.UpdateZone(context.Background(), getTestSuperAdmin(), "example.com", localZone, nil, addRRs)
+	require.ErrorAs(t, err, &invalidErr)
+	require.ErrorContains(t, err, "zone example.com in view _default is not a primary zone")
+	localZone.Type = "primary"
+
+	// No records.
+	err = manager.UpdateZone(context.Background(), getTestSuperAdmin(), "example.com", localZone, nil, nil)
+	require.ErrorAs(t, err, &invalidErr)
+
+	// Invalid records to be deleted.
+	err = manager.UpdateZone(context.Background(), getTestSuperAdmin(), "example.com", localZone,
+		parseTestRRs(t, "example.com. 300 IN NS ns1.example.com."), addRRs)
+	require.ErrorAs(t, err, &invalidErr)
+
+	// Invalid records to be added.
+	err = manager.UpdateZone(context.Background(), getTestSuperAdmin(), "example.org", localZone, nil, addRRs)
+	require.ErrorAs(t, err, &invalidErr)
+}
+
+// Test sending the zone update via the agent and raising an event.
+func TestUpdateZone(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	controller := gomock.NewController(t)
+	mock := NewMockConnectedAgents(controller)
+
+	app, zone := addTestZoneForRRs(t, db)
+
+	deleteRRs := parseTestRRs(t, "www.example.com. 300 IN A 192.0.2.1")
+	addRRs := parseTestRRs(t, "www.example.com. 300 IN A 192.0.2.2", "www.example.com. 300 IN TXT \"hello\"")
+
+	mock.EXPECT().UpdateZone(gomock.Any(), gomock.Cond(func(a any) bool {
+		return a.(*dbmodel.App).ID == app.ID
+	}), "example.com", "trusted", deleteRRs, addRRs).Return(nil)
+
+	eventCenter := &storktest.FakeEventCenter{}
+	manager := NewManager(&appstest.ManagerAccessorsWrapper{
+		DB:          db,
+		Agents:      mock,
+		EventCenter: eventCenter,
+	})
+
+	user := getTestSuperAdmin()
+	err := manager.UpdateZone(context.Background(), user, zone.Name, zone.LocalZones[0], deleteRRs, addRRs)
+	require.NoError(t, err)
+
+	require.Len(t, eventCenter.Events, 1)
+	event := eventCenter.Events[0]
+	require.Equal(t, dbmodel.EvInfo, event.Level)
+	require.Contains(t, event.Text, "updated zone example.com in view trusted on ")
+	require.Contains(t, event.Text, "deleted 1 A, added 1 A, 1 TXT")
+	require.Contains(t, event.Details, "192.0.2.2")
+	require.EqualValues(t, user.ID, event.Relations.UserID)
+	require.Equal(t, app.Daemons[0].ID, event.Relations.DaemonID)
+}
+
+// Test that the cached records are transferred again after the update.
+func TestUpdateZoneRefreshRRs(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	controller := gomock.NewController(t)
+	mock := NewMockConnectedAgents(controller)
+
+	_, zone := addTestZoneForRRs(t, db)
+
+	rrs := parseTestRRs(t, "example.com. 3600 IN NS ns1.example.com.")
+	err := dbmodel.ReplaceLocalZoneRRs(db, zone.LocalZones[0].ID, 2024031501, time.Now().UTC(), []*dbmodel.LocalZoneRR{dbmodel.NewLocalZoneRR(rrs[0])})
+	require.NoError(t, err)
+	zone, err = dbmodel.GetZoneByID(db, zone.ID, dbmodel.ZoneRelationLocalZones)
+	require.NoError(t, err)
+
+	addRRs := parseTestRRs(t, "www.example.com. 300 IN A 192.0.2.2")
+	transferred := parseTestRRs(t,
+		"example.com. 3600 IN SOA ns1.example.com. admin.example.com. 2024031502 43200 3600 1209600 3600",
+		"example.com. 3600 IN NS ns1.example.com.",
+		"www.example.com. 300 IN A 192.0.2.2",
+		"example.com. 3600 IN SOA ns1.example.com. admin.example.com. 2024031502 43200 3600 1209600 3600",
+	)
+	gomock.InOrder(
+		mock.EXPECT().UpdateZone(gomock.Any(), gomock.Any(), "example.com", "trusted", nil, addRRs).Return(nil),
+		mock.EXPECT().ReceiveZoneRRs(gomock.Any(), gomock.Any(), "example.com", "trusted").DoAndReturn(func(context.Context, agentcomm.ControlledApp, string, string) iter.Seq2[[]dns.RR, error] {
+			return func(yield func([]dns.RR, error) bool) {
+				_ = yield(transferred, nil)
+			}
+		}),
+	)
+
+	manager := NewManager(&appstest.ManagerAccessorsWrapper{
+		DB:          db,
+		Agents:      mock,
+		EventCenter: &storktest.FakeEventCenter{},
+	})
+	err = manager.UpdateZone(context.Background(), getTestSuperAdmin(), zone.Name, zone.LocalZones[0], nil, addRRs)
+	require.NoError(t, err)
+
+	returned, total, err := dbmodel.GetLocalZoneRRs(db, zone.LocalZones[0].ID, nil)
+	require.NoError(t, err)
+	require.Equal(t, 3, total)
+	require.Equal(t, "192.0.2.2", returned[2].Data)
+}
+
+// Test that an error is returned and no event is raised when the agent
+// fails to update the zone.
+func TestUpdateZoneAgentError(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	controller := gomock.NewController(t)
+	mock := NewMockConnectedAgents(controller)
+
+	_, zone := addTestZoneForRRs(t, db)
+
+	mock.EXPECT().UpdateZone(gomock.Any(), gomock.Any(), "example.com", "trusted", gomock.Any(), gomock.Any()).Return(&testError{})
+
+	eventCenter := &storktest.FakeEventCenter{}
+	manager := NewManager(&appstest.ManagerAccessorsWrapper{
+		DB:          db,
+		Agents:      mock,
+		EventCenter: eventCenter,
+	})
+	err := manager.UpdateZone(context.Background(), getTestSuperAdmin(), zone.Name, zone.LocalZones[0], nil,
+		parseTestRRs(t, "www.example.com. 300 IN A 192.0.2.2"))
+	require.ErrorContains(t, err, "test error")
+	require.Empty(t, eventCenter.Events)
+}
+
+// Test that the users other than super-admin can only update the zones
+// for which they have been granted the permission.
+func TestUpdateZonePermission(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	controller := gomock.NewController(t)
+	mock := NewMockConnectedAgents(controller)
+
+	_, zone := addTestZoneForRRs(t, db)
+
+	user := &dbmodel.SystemUser{
+		Login:    "user",
+		Email:    "user@example.org",
+		Lastname: "Doe",
+		Name:     "John",
+		Groups:   []*dbmodel.SystemGroup{{ID: dbmodel.AdminGroupID}},
+	}
+	_, err := dbmodel.CreateUser(db, user)
+	require.NoError(t, err)
+
+	addRRs := parseTestRRs(t, "www.example.com. 300 IN A 192.0.2.2")
+
+	// The update is sent only once, after granting the permission.
+	mock.EXPECT().UpdateZone(gomock.Any(), gomock.Any(), "example.com", "trusted", nil, addRRs).Return(nil)
+
+	eventCenter := &storktest.FakeEventCenter{}
+	manager := NewManager(&appstest.ManagerAccessorsWrapper{
+		DB:          db,
+		Agents:      mock,
+		EventCenter: eventCenter,
+	})
+
+	var permissionErr *ManagerPermissionDeniedError
+	err = manager.UpdateZone(context.Background(), user, zone.Name, zone.LocalZones[0], nil, addRRs)
+	require.ErrorAs(t, err, &permissionErr)
+	require.ErrorContains(t, err, "user is not allowed to update zone example.com")
+	require.Empty(t, eventCenter.Events)
+
+	err = dbmodel.SetZoneUpdatePermissions(db, zone.ID, []int{user.ID})
+	require.NoError(t, err)
+
+	err = manager.UpdateZone(context.Background(), user, zone.Name, zone.LocalZones[0], nil, addRRs)
+	require.NoError(t, err)
+	require.Len(t, eventCenter.Events, 1)
+}
@@ -0,0 +1,18 @@
+package hookmanager
+
+import (
+	"isc.org/stork/hooks/server/configreviewcallouts"
+	"isc.org/stork/hooksutil"
+)
+
+// Callout to obtain the configuration review checkers provided by the hooks.
+func (hm *HookManager) GetConfigReviewCheckers() []configreviewcallouts.Checker {
+	var checkers []configreviewcallouts.Checker
+	results := hooksutil.CallSequential(hm.GetExecutor(), func(carrier configreviewcallouts.ConfigReviewCallouts) []configreviewcallouts.Checker {
+		return carrier.GetCheckers()
+	})
+	for _, result := range results {
+		checkers = append(checkers, result...)
+	}
+	return checkers
+}
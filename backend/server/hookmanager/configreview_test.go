@@ -0,0 +1,66 @@
+package hookmanager
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	gomock "go.uber.org/mock/gomock"
+	"isc.org/stork/hooks"
+	"isc.org/stork/hooks/server/configreviewcallouts"
+)
+
+// Carrier mock interface for mockgen.
+type configReviewCalloutCarrier interface { //nolint:unused
+	configreviewcallouts.ConfigReviewCallouts
+	hooks.CalloutCarrier
+}
+
+//go:generate mockgen -package=hookmanager -destination=configreviewcalloutcarriermock_test.go -source=configreview_test.go -mock_names=configReviewCalloutCarrier=MockConfigReviewCalloutCarrier isc.org/server/hookmanager configReviewCalloutCarrier
+//go:generate mockgen -package=hookmanager -destination=configreviewcalloutsmock_test.go -source=../../hooks/server/configreviewcallouts/configreviewcallouts.go isc.org/server/hookmanager Checker
+
+// Test that the checkers provided by all hooks are returned.
+func TestGetConfigReviewCheckers(t *testing.T) {
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	checker1 := NewMockChecker(ctrl)
+	checker2 := NewMockChecker(ctrl)
+	checker3 := NewMockChecker(ctrl)
+
+	mock1 := NewMockConfigReviewCalloutCarrier(ctrl)
+	mock1.EXPECT().
+		GetCheckers().
+		Return([]configreviewcallouts.Checker{checker1, checker2}).
+		Times(1)
+
+	mock2 := NewMockConfigReviewCalloutCarrier(ctrl)
+	mock2.EXPECT().
+		GetCheckers().
+		Return([]configreviewcallouts.Checker{checker3}).
+		Times(1)
+
+	hookManager := NewHookManager()
+	hookManager.RegisterCalloutCarriers([]hooks.CalloutCarrier{mock1, mock2})
+
+	// Act
+	checkers := hookManager.GetConfigReviewCheckers()
+
+	// Assert
+	require.Len(t, checkers, 3)
+	require.Same(t, checker1, checkers[0])
+	require.Same(t, checker2, checkers[1])
+	require.Same(t, checker3, checkers[2])
+}
+
+// Test that no checkers are returned when there are no hooks.
+func TestGetConfigReviewCheckersNoHooks(t *testing.T) {
+	// Arrange
+	hookManager := NewHookManager()
+
+	// Act
+	checkers := hookManager.GetConfigReviewCheckers()
+
+	// Assert
+	require.Empty(t, checkers)
+}
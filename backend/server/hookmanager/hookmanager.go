@@ -4,6 +4,7 @@ import (
 	"reflect"
 
 	"isc.org/stork/hooks/server/authenticationcallouts"
+	"isc.org/stork/hooks/server/configreviewcallouts"
 	"isc.org/stork/hooksutil"
 )
 
@@ -18,6 +19,7 @@ func NewHookManager() *HookManager {
 	return &HookManager{
 		HookManager: *hooksutil.NewHookManager([]reflect.Type{
 			reflect.TypeOf((*authenticationcallouts.AuthenticationCallouts)(nil)).Elem(),
+			reflect.TypeOf((*configreviewcallouts.ConfigReviewCallouts)(nil)).Elem(),
 		}),
 	}
 }
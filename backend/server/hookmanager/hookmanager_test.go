@@ -14,5 +14,5 @@ func TestNewHookManager(t *testing.T) {
 	// Assert
 	require.NotNil(t, hookManager)
 	supportedTypes := hookManager.HookManager.GetExecutor().GetTypesOfSupportedCalloutSpecifications()
-	require.Len(t, supportedTypes, 1)
+	require.Len(t, supportedTypes, 2)
 }
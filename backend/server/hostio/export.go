@@ -0,0 +1,91 @@
+package hostio
+
+import (
+	"io"
+
+	"github.com/pkg/errors"
+	keaconfig "isc.org/stork/appcfg/kea"
+	dbops "isc.org/stork/server/database"
+	dbmodel "isc.org/stork/server/database/model"
+	storkutil "isc.org/stork/util"
+)
+
+// Number of hosts fetched from the database at once during the export.
+const exportPageSize = 1000
+
+// Creates a record from the host reservation held in the database. The
+// host can be associated with many daemons and the DHCP data can differ
+// between them. The record is created from the first local host. If the
+// host has multiple identifiers, the first one is used. The lookup
+// interface must not be nil.
+func NewRecordFromHost(host *dbmodel.Host, lookup keaconfig.DHCPOptionDefinitionLookup) (*Record, error) {
+	if len(host.HostIdentifiers) == 0 {
+		return nil, errors.Errorf("host %d has no identifiers", host.ID)
+	}
+	record := &Record{
+		IdentifierType: host.HostIdentifiers[0].Type,
+		Identifier:     host.HostIdentifiers[0].ToHex(":"),
+		Hostname:       host.GetHostname(),
+	}
+	if host.Subnet != nil {
+		record.Subnet = host.Subnet.Prefix
+	}
+	for _, lh := range host.LocalHosts {
+		for _, reservation := range lh.IPReservations {
+			parsed := storkutil.ParseIP(reservation.Address)
+			switch {
+			case parsed == nil:
+				continue
+			case parsed.Prefix:
+				record.Prefixes = append(record.Prefixes, parsed.NetworkAddress)
+			default:
+				record.IPAddresses = append(record.IPAddresses, parsed.NetworkAddress)
+			}
+		}
+		// All local hosts should have the same reservations.
+		break
+	}
+	if len(host.LocalHosts) > 0 {
+		daemonID := host.LocalHosts[0].DaemonID
+		record.ClientClasses = host.GetClientClasses(daemonID)
+		for _, option := range host.GetDHCPOptions(daemonID) {
+			optionData, err := keaconfig.CreateSingleOptionData(daemonID, lookup, option)
+			if err != nil {
+				return nil, errors.WithMessagef(err, "problem converting DHCP option of host %d", host.ID)
+			}
+			record.OptionData = append(record.OptionData, *optionData)
+		}
+	}
+	return record, nil
+}
+
+// Exports all host reservations held in the database to the writer in the
+// specified format. The hosts without identifiers are skipped. It returns
+// the number of exported host reservations. The lookup interface must not
+// be nil.
+func Export(db dbops.DBI, lookup keaconfig.DHCPOptionDefinitionLookup, writer io.Writer, format Format) (int, error) {
+	records := []Record{}
+	for offset := int64(0); ; offset += exportPageSize {
+		hosts, total, err := dbmodel.GetHostsByPage(db, offset, exportPageSize, dbmodel.HostsByPageFilters{}, "", dbmodel.SortDirAsc)
+		if err != nil {
+			return 0, err
+		}
+		for i := range hosts {
+			if len(hosts[i].HostIdentifiers) == 0 {
+				continue
+			}
+			record, err := NewRecordFromHost(&hosts[i], lookup)
+			if err != nil {
+				return 0, err
+			}
+			records = append(records, *record)
+		}
+		if len(hosts) == 0 || offset+exportPageSize >= total {
+			break
+		}
+	}
+	if err := Write(writer, format, records); err != nil {
+		return 0, err
+	}
+	return len(records), nil
+}
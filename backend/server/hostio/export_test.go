@@ -0,0 +1,146 @@
+package hostio
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	dhcpmodel "isc.org/stork/datamodel/dhcp"
+	dbmodel "isc.org/stork/server/database/model"
+	dbtest "isc.org/stork/server/database/test"
+	storkutil "isc.org/stork/util"
+)
+
+// Test creating a record from a host.
+func TestNewRecordFromHost(t *testing.T) {
+	host := &dbmodel.Host{
+		ID: 1,
+		Subnet: &dbmodel.Subnet{
+			Prefix: "2001:db8:1::/64",
+		},
+		HostIdentifiers: []dbmodel.HostIdentifier{
+			{
+				Type:  "duid",
+				Value: []byte{1, 2, 3, 4},
+			},
+			{
+				Type:  "hw-address",
+				Value: []byte{1, 2, 3, 4, 5, 6},
+			},
+		},
+		LocalHosts: []dbmodel.LocalHost{
+			{
+				DaemonID:      1,
+				Hostname:      "foo.example.org",
+				ClientClasses: []string{"foo", "bar"},
+				IPReservations: []dbmodel.IPReservation{
+					{Address: "2001:db8:1::10"},
+					{Address: "3000::/64"},
+				},
+				DHCPOptionSet: dbmodel.DHCPOptionSet{
+					Options: []dbmodel.DHCPOption{
+						{
+							Code:     23,
+							Space:    dhcpmodel.DHCPv6OptionSpace,
+							Universe: storkutil.IPv6,
+							Fields: []dbmodel.DHCPOptionField{
+								{
+									FieldType: dhcpmodel.IPv6AddressField,
+									Values:    []any{"2001:db8:1::1"},
+								},
+							},
+						},
+					},
+				},
+			},
+			{
+				DaemonID: 2,
+				Hostname: "foo.example.org",
+				IPReservations: []dbmodel.IPReservation{
+					{Address: "2001:db8:1::10"},
+					{Address: "3000::/64"},
+				},
+			},
+		},
+	}
+	record, err := NewRecordFromHost(host, dbmodel.NewDHCPOptionDefinitionLookup())
+	require.NoError(t, err)
+	require.NotNil(t, record)
+	require.Equal(t, "duid", record.IdentifierType)
+	require.Equal(t, "01:02:03:04", record.Identifier)
+	require.Equal(t, "2001:db8:1::/64", record.Subnet)
+	require.Equal(t, []string{"2001:db8:1::10"}, record.IPAddresses)
+	require.Equal(t, []string{"3000::/64"}, record.Prefixes)
+	require.Equal(t, "foo.example.org", record.Hostname)
+	require.Equal(t, []string{"foo", "bar"}, record.ClientClasses)
+	require.Len(t, record.OptionData, 1)
+	require.EqualValues(t, 23, record.OptionData[0].Code)
+	require.Equal(t, "dhcp6", record.OptionData[0].Space)
+	require.Equal(t, "2001:db8:1::1", record.OptionData[0].Data)
+
+	// The host without identifiers cannot be exported.
+	host.HostIdentifiers = nil
+	_, err = NewRecordFromHost(host, dbmodel.NewDHCPOptionDefinitionLookup())
+	require.Error(t, err)
+}
+
+// Test exporting the host reservations from the database.
+func TestExport(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	dhcp4, _ := addTestServers(t, db)
+
+	subnets, err := dbmodel.GetSubnetsByPrefix(db, "192.0.2.0/24")
+	require.NoError(t, err)
+	require.Len(t, subnets, 1)
+
+	for i, address := range []string{"192.0.2.10", "192.0.2.11"} {
+		err = dbmodel.AddHost(db, &dbmodel.Host{
+			SubnetID: subnets[0].ID,
+			HostIdentifiers: []dbmodel.HostIdentifier{
+				{
+					Type:  "hw-address",
+					Value: []byte{1, 2, 3, 4, 5, byte(i)},
+				},
+			},
+			LocalHosts: []dbmodel.LocalHost{
+				{
+					DaemonID:   dhcp4.ID,
+					DataSource: dbmodel.HostDataSourceConfig,
+					Hostname:   "foo",
+					IPReservations: []dbmodel.IPReservation{
+						{
+							Address: address,
+						},
+					},
+				},
+			},
+		})
+		require.NoError(t, err)
+	}
+
+	var buffer bytes.Buffer
+	count, err := Export(db, dbmodel.NewDHCPOptionDefinitionLookup(), &buffer, FormatCSV)
+	require.NoError(t, err)
+	require.Equal(t, 2, count)
+
+	records, err := Read(&buffer, FormatCSV)
+	require.NoError(t, err)
+	require.Equal(t, []Record{
+		{
+			IdentifierType: "hw-address",
+			Identifier:     "01:02:03:04:05:00",
+			Subnet:         "192.0.2.0/24",
+			IPAddresses:    []string{"192.0.2.10"},
+			Hostname:       "foo",
+		},
+		{
+			IdentifierType: "hw-address",
+			Identifier:     "01:02:03:04:05:01",
+			Subnet:         "192.0.2.0/24",
+			IPAddresses:    []string{"192.0.2.11"},
+			Hostname:       "foo",
+		},
+	}, records)
+}
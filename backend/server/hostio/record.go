@@ -0,0 +1,245 @@
+package hostio
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strings"
+
+	"github.com/pkg/errors"
+	keaconfig "isc.org/stork/appcfg/kea"
+)
+
+// Format of the file holding the host reservations.
+type Format string
+
+// Supported formats of the files holding the host reservations.
+const (
+	FormatCSV  Format = "csv"
+	FormatJSON Format = "json"
+)
+
+// Parses the file format name. It returns an error if the format is not
+// supported.
+func ParseFormat(name string) (Format, error) {
+	switch format := Format(strings.ToLower(strings.TrimSpace(name))); format {
+	case FormatCSV, FormatJSON:
+		return format, nil
+	default:
+		return "", errors.Errorf("unsupported host reservations file format '%s'; it must be one of: csv, json", name)
+	}
+}
+
+// Names of the columns in the CSV file. They are the same as the JSON
+// keys of the Record. The identifier-type and identifier columns are
+// mandatory. Other columns are optional and can be specified in any order.
+const (
+	columnIdentifierType = "identifier-type"
+	columnIdentifier     = "identifier"
+	columnSubnet         = "subnet"
+	columnIPAddresses    = "ip-addresses"
+	columnPrefixes       = "prefixes"
+	columnHostname       = "hostname"
+	columnClientClasses  = "client-classes"
+	columnOptionData     = "option-data"
+)
+
+// Ordered list of the columns in the exported CSV file.
+var csvColumns = []string{
+	columnIdentifierType,
+	columnIdentifier,
+	columnSubnet,
+	columnIPAddresses,
+	columnPrefixes,
+	columnHostname,
+	columnClientClasses,
+	columnOptionData,
+}
+
+// Represents a single host reservation in the imported or exported file.
+// The identifier is a string of hexadecimal digits, optionally separated
+// with colons. The subnet is specified as a prefix (e.g., 192.0.2.0/24).
+// An empty subnet denotes a global host reservation. The DHCP options
+// are specified in the Kea format (i.e., as items of the option-data list).
+//
+// In the CSV file, the lists of the IP addresses, prefixes and client
+// classes are specified as comma separated values in a single column.
+// The option-data column holds a JSON list of the options.
+type Record struct {
+	IdentifierType string                       `json:"identifier-type"`
+	Identifier     string                       `json:"identifier"`
+	Subnet         string                       `json:"subnet,omitempty"`
+	IPAddresses    []string                     `json:"ip-addresses,omitempty"`
+	Prefixes       []string                     `json:"prefixes,omitempty"`
+	Hostname       string                       `json:"hostname,omitempty"`
+	ClientClasses  []string                     `json:"client-classes,omitempty"`
+	OptionData     []keaconfig.SingleOptionData `json:"option-data,omitempty"`
+}
+
+// Reads the host reservation records from the reader in the specified
+// format. It returns an error if the file is malformed. The returned
+// records are not validated.
+func Read(reader io.Reader, format Format) ([]Record, error) {
+	switch format {
+	case FormatCSV:
+		return readCSV(reader)
+	case FormatJSON:
+		return readJSON(reader)
+	default:
+		return nil, errors.Errorf("unsupported host reservations file format '%s'", format)
+	}
+}
+
+// Writes the host reservation records to the writer in the specified format.
+func Write(writer io.Writer, format Format, records []Record) error {
+	switch format {
+	case FormatCSV:
+		return writeCSV(writer, records)
+	case FormatJSON:
+		return writeJSON(writer, records)
+	default:
+		return errors.Errorf("unsupported host reservations file format '%s'", format)
+	}
+}
+
+// Reads the records from a JSON list.
+func readJSON(reader io.Reader) ([]Record, error) {
+	records := []Record{}
+	decoder := json.NewDecoder(reader)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&records); err != nil {
+		return nil, errors.Wrap(err, "problem parsing host reservations in JSON format")
+	}
+	return records, nil
+}
+
+// Writes the records as a JSON list.
+func writeJSON(writer io.Writer, records []Record) error {
+	if records == nil {
+		records = []Record{}
+	}
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(records); err != nil {
+		return errors.Wrap(err, "problem writing host reservations in JSON format")
+	}
+	return nil
+}
+
+// Reads the records from a CSV file. The first line of the file must
+// contain the column names.
+func readCSV(reader io.Reader) ([]Record, error) {
+	csvReader := csv.NewReader(reader)
+	csvReader.TrimLeadingSpace = true
+	csvReader.FieldsPerRecord = 0
+
+	header, err := csvReader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, errors.New("host reservations file in CSV format lacks the header")
+		}
+		return nil, errors.Wrap(err, "problem parsing header of the host reservations file in CSV format")
+	}
+	columns := make(map[string]int)
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		found := false
+		for _, column := range csvColumns {
+			if name == column {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, errors.Errorf("unknown column '%s' in the host reservations file in CSV format", name)
+		}
+		if _, exists := columns[name]; exists {
+			return nil, errors.Errorf("duplicated column '%s' in the host reservations file in CSV format", name)
+		}
+		columns[name] = i
+	}
+	for _, column := range []string{columnIdentifierType, columnIdentifier} {
+		if _, ok := columns[column]; !ok {
+			return nil, errors.Errorf("host reservations file in CSV format lacks the mandatory column '%s'", column)
+		}
+	}
+
+	records := []Record{}
+	for {
+		row, err := csvReader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "problem parsing host reservations in CSV format")
+		}
+		value := func(column string) string {
+			if index, ok := columns[column]; ok {
+				return strings.TrimSpace(row[index])
+			}
+			return ""
+		}
+		record := Record{
+			IdentifierType: value(columnIdentifierType),
+			Identifier:     value(columnIdentifier),
+			Subnet:         value(columnSubnet),
+			IPAddresses:    splitList(value(columnIPAddresses)),
+			Prefixes:       splitList(value(columnPrefixes)),
+			Hostname:       value(columnHostname),
+			ClientClasses:  splitList(value(columnClientClasses)),
+		}
+		if optionData := value(columnOptionData); len(optionData) > 0 {
+			if err = json.Unmarshal([]byte(optionData), &record.OptionData); err != nil {
+				line, _ := csvReader.FieldPos(columns[columnOptionData])
+				return nil, errors.Wrapf(err, "problem parsing option-data in line %d of the host reservations file in CSV format", line)
+			}
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// Writes the records to a CSV file. The first line of the file contains
+// the column names.
+func writeCSV(writer io.Writer, records []Record) error {
+	csvWriter := csv.NewWriter(writer)
+	if err := csvWriter.Write(csvColumns); err != nil {
+		return errors.Wrap(err, "problem writing header of the host reservations file in CSV format")
+	}
+	for _, record := range records {
+		var optionData string
+		if len(record.OptionData) > 0 {
+			marshalled, err := json.Marshal(record.OptionData)
+			if err != nil {
+				return errors.Wrapf(err, "problem serializing option-data of the host reservation %s=%s", record.IdentifierType, record.Identifier)
+			}
+			optionData = string(marshalled)
+		}
+		row := []string{
+			record.IdentifierType,
+			record.Identifier,
+			record.Subnet,
+			strings.Join(record.IPAddresses, ","),
+			strings.Join(record.Prefixes, ","),
+			record.Hostname,
+			strings.Join(record.ClientClasses, ","),
+			optionData,
+		}
+		if err := csvWriter.Write(row); err != nil {
+			return errors.Wrap(err, "problem writing host reservations in CSV format")
+		}
+	}
+	csvWriter.Flush()
+	return errors.Wrap(csvWriter.Error(), "problem writing host reservations in CSV format")
+}
+
+// Splits the comma separated list of values. It returns nil for an
+// empty string.
+func splitList(value string) (values []string) {
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); len(v) > 0 {
+			values = append(values, v)
+		}
+	}
+	return
+}
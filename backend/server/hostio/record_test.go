@@ -0,0 +1,201 @@
+package hostio
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	keaconfig "isc.org/stork/appcfg/kea"
+	storkutil "isc.org/stork/util"
+)
+
+// Returns the records used in the tests.
+func getTestRecords() []Record {
+	return []Record{
+		{
+			IdentifierType: "hw-address",
+			Identifier:     "01:02:03:04:05:06",
+			Subnet:         "192.0.2.0/24",
+			IPAddresses:    []string{"192.0.2.10"},
+			Hostname:       "foo.example.org",
+			ClientClasses:  []string{"foo", "bar"},
+			OptionData: []keaconfig.SingleOptionData{
+				{
+					Code:      6,
+					CSVFormat: storkutil.Ptr(true),
+					Data:      "192.0.2.1,192.0.2.2",
+					Name:      "domain-name-servers",
+					Space:     "dhcp4",
+				},
+			},
+		},
+		{
+			IdentifierType: "duid",
+			Identifier:     "01:02:03:04",
+			Subnet:         "2001:db8:1::/64",
+			IPAddresses:    []string{"2001:db8:1::10", "2001:db8:1::11"},
+			Prefixes:       []string{"3000::/64"},
+		},
+		{
+			IdentifierType: "client-id",
+			Identifier:     "aabbcc",
+		},
+	}
+}
+
+// Test parsing the file format names.
+func TestParseFormat(t *testing.T) {
+	format, err := ParseFormat("csv")
+	require.NoError(t, err)
+	require.Equal(t, FormatCSV, format)
+
+	format, err = ParseFormat(" JSON ")
+	require.NoError(t, err)
+	require.Equal(t, FormatJSON, format)
+
+	_, err = ParseFormat("xml")
+	require.ErrorContains(t, err, "unsupported host reservations file format 'xml'")
+
+	_, err = ParseFormat("")
+	require.Error(t, err)
+}
+
+// Test that the records written in the CSV format can be read back.
+func TestWriteReadCSV(t *testing.T) {
+	var buffer bytes.Buffer
+	err := Write(&buffer, FormatCSV, getTestRecords())
+	require.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSpace(buffer.String()), "\n")
+	require.Len(t, lines, 4)
+	require.Equal(t, "identifier-type,identifier,subnet,ip-addresses,prefixes,hostname,client-classes,option-data", lines[0])
+	require.Equal(t, "duid,01:02:03:04,2001:db8:1::/64,\"2001:db8:1::10,2001:db8:1::11\",3000::/64,,,", lines[2])
+	require.Equal(t, "client-id,aabbcc,,,,,,", lines[3])
+
+	records, err := Read(&buffer, FormatCSV)
+	require.NoError(t, err)
+	require.Equal(t, getTestRecords(), records)
+}
+
+// Test that the records written in the JSON format can be read back.
+func TestWriteReadJSON(t *testing.T) {
+	var buffer bytes.Buffer
+	err := Write(&buffer, FormatJSON, getTestRecords())
+	require.NoError(t, err)
+
+	require.JSONEq(t, `[
+		{
+			"identifier-type": "hw-address",
+			"identifier": "01:02:03:04:05:06",
+			"subnet": "192.0.2.0/24",
+			"ip-addresses": ["192.0.2.10"],
+			"hostname": "foo.example.org",
+			"client-classes": ["foo", "bar"],
+			"option-data": [
+				{
+					"code": 6,
+					"csv-format": true,
+					"data": "192.0.2.1,192.0.2.2",
+					"name": "domain-name-servers",
+					"space": "dhcp4"
+				}
+			]
+		},
+		{
+			"identifier-type": "duid",
+			"identifier": "01:02:03:04",
+			"subnet": "2001:db8:1::/64",
+			"ip-addresses": ["2001:db8:1::10", "2001:db8:1::11"],
+			"prefixes": ["3000::/64"]
+		},
+		{
+			"identifier-type": "client-id",
+			"identifier": "aabbcc"
+		}
+	]`, buffer.String())
+
+	records, err := Read(&buffer, FormatJSON)
+	require.NoError(t, err)
+	require.Equal(t, getTestRecords(), records)
+}
+
+// Test that writing no records produces valid files.
+func TestWriteNoRecords(t *testing.T) {
+	var buffer bytes.Buffer
+	err := Write(&buffer, FormatJSON, nil)
+	require.NoError(t, err)
+	require.JSONEq(t, "[]", buffer.String())
+
+	buffer.Reset()
+	err = Write(&buffer, FormatCSV, nil)
+	require.NoError(t, err)
+	records, err := Read(&buffer, FormatCSV)
+	require.NoError(t, err)
+	require.Empty(t, records)
+}
+
+// Test that the CSV columns can be specified in any order and that only
+// the identifier columns are mandatory.
+func TestReadCSVColumnOrder(t *testing.T) {
+	data := `hostname, Identifier, identifier-type
+foo, 01:02:03:04:05:06, hw-address
+`
+	records, err := Read(strings.NewReader(data), FormatCSV)
+	require.NoError(t, err)
+	require.Equal(t, []Record{
+		{
+			IdentifierType: "hw-address",
+			Identifier:     "01:02:03:04:05:06",
+			Hostname:       "foo",
+		},
+	}, records)
+}
+
+// Test that malformed CSV files are rejected.
+func TestReadCSVErrors(t *testing.T) {
+	testCases := map[string]struct {
+		data  string
+		error string
+	}{
+		"empty file": {
+			data:  "",
+			error: "lacks the header",
+		},
+		"unknown column": {
+			data:  "identifier-type,identifier,foo\n",
+			error: "unknown column 'foo'",
+		},
+		"duplicated column": {
+			data:  "identifier-type,identifier,identifier\n",
+			error: "duplicated column 'identifier'",
+		},
+		"missing identifier": {
+			data:  "identifier-type,hostname\n",
+			error: "lacks the mandatory column 'identifier'",
+		},
+		"wrong number of fields": {
+			data:  "identifier-type,identifier\nhw-address\n",
+			error: "wrong number of fields",
+		},
+		"invalid option data": {
+			data:  "identifier-type,identifier,option-data\nhw-address,010203,{\n",
+			error: "problem parsing option-data in line 2",
+		},
+	}
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			_, err := Read(strings.NewReader(testCase.data), FormatCSV)
+			require.ErrorContains(t, err, testCase.error)
+		})
+	}
+}
+
+// Test that malformed JSON files are rejected.
+func TestReadJSONErrors(t *testing.T) {
+	_, err := Read(strings.NewReader(`{"identifier": "010203"}`), FormatJSON)
+	require.Error(t, err)
+
+	_, err = Read(strings.NewReader(`[{"identifier": "010203", "foo": "bar"}]`), FormatJSON)
+	require.ErrorContains(t, err, "unknown field")
+}
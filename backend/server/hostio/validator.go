@@ -0,0 +1,272 @@
+package hostio
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/pkg/errors"
+	keaconfig "isc.org/stork/appcfg/kea"
+	dbops "isc.org/stork/server/database"
+	dbmodel "isc.org/stork/server/database/model"
+	storkutil "isc.org/stork/util"
+)
+
+// Host identifier types supported by Kea.
+var identifierTypes = []string{"hw-address", "duid", "circuit-id", "client-id", "flex-id"}
+
+// Validates the imported host reservation records and converts them to
+// the hosts which can be applied by the config module. Besides checking
+// the record contents, it checks whether the host reservations conflict
+// with the reservations existing in the database or the reservations
+// accepted earlier by the same validator (e.g., reservations specified
+// earlier in the imported file and successfully committed). The validator
+// caches the subnets and hosts fetched from the database, so it should be
+// used for a single import only.
+type Validator struct {
+	db              dbops.DBI
+	lookup          keaconfig.DHCPOptionDefinitionLookup
+	globalDaemonIDs []int64
+	subnets         map[string]*dbmodel.Subnet
+	hosts           map[int64][]dbmodel.Host
+}
+
+// Creates a new validator instance. The global daemon IDs specify the
+// daemons in which the global host reservations (i.e., the records lacking
+// the subnet) are created. If they are not specified, the global host
+// reservations are rejected.
+func NewValidator(db dbops.DBI, lookup keaconfig.DHCPOptionDefinitionLookup, globalDaemonIDs []int64) *Validator {
+	return &Validator{
+		db:              db,
+		lookup:          lookup,
+		globalDaemonIDs: globalDaemonIDs,
+		subnets:         make(map[string]*dbmodel.Subnet),
+		hosts:           make(map[int64][]dbmodel.Host),
+	}
+}
+
+// Validates the record and converts it to a host. The returned host is
+// associated with all daemons owning the subnet specified in the record
+// or with the global daemons when the subnet is not specified. The local
+// hosts include the daemons and the apps, so the host can be passed to the
+// config module directly. The returned error describes why the record is
+// invalid. The validated host is not taken into account when validating
+// the subsequent records until it is accepted with the Accept function.
+func (v *Validator) Validate(record Record) (*dbmodel.Host, error) {
+	host := &dbmodel.Host{}
+
+	// Identifier.
+	if !slices.Contains(identifierTypes, record.IdentifierType) {
+		return nil, errors.Errorf("invalid identifier type '%s'; it must be one of: %s", record.IdentifierType, strings.Join(identifierTypes, ", "))
+	}
+	if !storkutil.IsHexIdentifier(record.Identifier) {
+		return nil, errors.Errorf("invalid identifier '%s'; it must be a string of hexadecimal digits, optionally separated with colons", record.Identifier)
+	}
+	host.HostIdentifiers = []dbmodel.HostIdentifier{
+		{
+			Type:  record.IdentifierType,
+			Value: storkutil.HexToBytes(record.Identifier),
+		},
+	}
+
+	// Subnet and daemons.
+	var (
+		daemonIDs []int64
+		family    int
+		subnet    *storkutil.ParsedIP
+	)
+	if len(record.Subnet) > 0 {
+		subnet = storkutil.ParseIP(record.Subnet)
+		if subnet == nil || !subnet.Prefix {
+			return nil, errors.Errorf("invalid subnet prefix '%s'", record.Subnet)
+		}
+		dbSubnet, err := v.getSubnet(subnet.NetworkAddress)
+		if err != nil {
+			return nil, err
+		}
+		host.SubnetID = dbSubnet.ID
+		host.Subnet = dbSubnet
+		family = dbSubnet.GetFamily()
+		for _, ls := range dbSubnet.LocalSubnets {
+			daemonIDs = append(daemonIDs, ls.DaemonID)
+		}
+	} else {
+		if len(v.globalDaemonIDs) == 0 {
+			return nil, errors.New("subnet not specified and no daemons selected for global host reservations")
+		}
+		daemonIDs = v.globalDaemonIDs
+	}
+	for _, daemonID := range daemonIDs {
+		host.LocalHosts = append(host.LocalHosts, dbmodel.LocalHost{
+			DaemonID:      daemonID,
+			DataSource:    dbmodel.HostDataSourceAPI,
+			Hostname:      record.Hostname,
+			ClientClasses: record.ClientClasses,
+		})
+	}
+	if err := host.PopulateDaemons(v.db); err != nil {
+		return nil, err
+	}
+	for _, lh := range host.LocalHosts {
+		var daemonFamily int
+		switch lh.Daemon.Name {
+		case dbmodel.DaemonNameDHCPv4:
+			daemonFamily = 4
+		case dbmodel.DaemonNameDHCPv6:
+			daemonFamily = 6
+		default:
+			return nil, errors.Errorf("daemon %d is not a DHCP server", lh.DaemonID)
+		}
+		if family == 0 {
+			family = daemonFamily
+		}
+		if daemonFamily != family {
+			return nil, errors.New("host reservation cannot be created in both DHCPv4 and DHCPv6 servers")
+		}
+	}
+
+	// Addresses and prefixes.
+	var reservations []dbmodel.IPReservation
+	for _, address := range record.IPAddresses {
+		parsed := storkutil.ParseIP(address)
+		if parsed == nil || parsed.Prefix {
+			return nil, errors.Errorf("invalid IP address '%s'", address)
+		}
+		if int(parsed.Protocol) != family {
+			return nil, errors.Errorf("IP address %s does not match the IPv%d host reservation", address, family)
+		}
+		if subnet != nil && !subnet.IPNet.Contains(parsed.IP) {
+			return nil, errors.Errorf("IP address %s does not belong to subnet %s", address, subnet.NetworkAddress)
+		}
+		reservations = append(reservations, dbmodel.IPReservation{Address: parsed.NetworkAddress})
+	}
+	if family == 4 && len(record.IPAddresses) > 1 {
+		return nil, errors.New("DHCPv4 host reservation can include at most one IP address")
+	}
+	for _, prefix := range record.Prefixes {
+		if family != 6 {
+			return nil, errors.New("delegated prefixes can only be reserved in the DHCPv6 host reservation")
+		}
+		parsed := storkutil.ParseIP(prefix)
+		if parsed == nil || !parsed.Prefix {
+			return nil, errors.Errorf("invalid delegated prefix '%s'", prefix)
+		}
+		reservations = append(reservations, dbmodel.IPReservation{Address: parsed.NetworkAddress})
+	}
+
+	// Hostname.
+	if len(record.Hostname) > 0 {
+		if _, err := storkutil.ParseFqdn(record.Hostname); err != nil {
+			return nil, errors.Errorf("invalid hostname '%s'", record.Hostname)
+		}
+	}
+
+	// DHCP options.
+	var options []dbmodel.DHCPOption
+	for _, optionData := range record.OptionData {
+		option, err := dbmodel.NewDHCPOptionFromKea(optionData, storkutil.IPType(family), v.lookup)
+		if err != nil {
+			return nil, errors.WithMessagef(err, "invalid DHCP option %s", describeOptionData(optionData))
+		}
+		options = append(options, *option)
+	}
+	for i := range host.LocalHosts {
+		host.LocalHosts[i].IPReservations = slices.Clone(reservations)
+		host.LocalHosts[i].SetDHCPOptions(options, keaconfig.NewHasher())
+	}
+
+	// Conflicts with existing reservations.
+	if err := v.CheckConflicts(host); err != nil {
+		return nil, err
+	}
+	return host, nil
+}
+
+// Remembers the host returned by the Validate function which is going to
+// be committed. The subsequently validated hosts are checked for conflicts
+// with this host. The hosts which failed to commit must be revoked with the
+// Revoke function, so they don't cause false conflicts.
+func (v *Validator) Accept(host *dbmodel.Host) {
+	// If the hosts in the subnet haven't been fetched yet, they will be
+	// fetched from the database together with the committed host.
+	if hosts, ok := v.hosts[host.SubnetID]; ok {
+		v.hosts[host.SubnetID] = append(hosts, *host)
+	}
+}
+
+// Forgets the accepted host which failed to commit. The accepted hosts
+// don't conflict with each other, so the host is found by its identifier.
+func (v *Validator) Revoke(host *dbmodel.Host) {
+	hosts, ok := v.hosts[host.SubnetID]
+	if !ok || len(host.HostIdentifiers) == 0 {
+		return
+	}
+	identifier := host.HostIdentifiers[0]
+	v.hosts[host.SubnetID] = slices.DeleteFunc(hosts, func(other dbmodel.Host) bool {
+		_, equal := other.HasIdentifier(identifier.Type, identifier.Value)
+		return equal
+	})
+}
+
+// Returns the subnet with the specified prefix. It returns an error if
+// the subnet does not exist or if there are multiple subnets with this
+// prefix.
+func (v *Validator) getSubnet(prefix string) (*dbmodel.Subnet, error) {
+	if subnet, ok := v.subnets[prefix]; ok {
+		return subnet, nil
+	}
+	subnets, err := dbmodel.GetSubnetsByPrefix(v.db, prefix)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case len(subnets) == 0:
+		return nil, errors.Errorf("subnet %s does not exist", prefix)
+	case len(subnets) > 1:
+		return nil, errors.Errorf("subnet %s is ambiguous because there are %d subnets with this prefix", prefix, len(subnets))
+	case len(subnets[0].LocalSubnets) == 0:
+		return nil, errors.Errorf("subnet %s is not associated with any daemon", prefix)
+	}
+	v.subnets[prefix] = &subnets[0]
+	return &subnets[0], nil
+}
+
+// Checks whether the host conflicts with the hosts in the same subnet,
+// i.e., whether any of these hosts has the same identifier or reserves
+// any of the same addresses or prefixes. The hosts existing in the database
+// and the accepted hosts are checked.
+func (v *Validator) CheckConflicts(host *dbmodel.Host) error {
+	hosts, ok := v.hosts[host.SubnetID]
+	if !ok {
+		var err error
+		hosts, err = dbmodel.GetHostsBySubnetID(v.db, host.SubnetID)
+		if err != nil {
+			return err
+		}
+		v.hosts[host.SubnetID] = hosts
+	}
+	identifier := host.HostIdentifiers[0]
+	for _, other := range hosts {
+		if _, equal := other.HasIdentifier(identifier.Type, identifier.Value); equal {
+			return errors.Errorf("host reservation for %s %s already exists", identifier.Type, identifier.ToHex(":"))
+		}
+		for _, address := range host.GetIPReservations() {
+			if other.HasIPAddress(address) {
+				return errors.Errorf("IP address or prefix %s is already reserved", address)
+			}
+		}
+	}
+	return nil
+}
+
+// Returns a textual description of the option, used in the error messages.
+func describeOptionData(optionData keaconfig.SingleOptionData) string {
+	if len(optionData.Name) > 0 {
+		return fmt.Sprintf("'%s'", optionData.Name)
+	}
+	space := optionData.Space
+	if len(space) == 0 {
+		space = "default"
+	}
+	return fmt.Sprintf("with code %d in %s space", optionData.Code, space)
+}
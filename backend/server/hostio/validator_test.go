@@ -0,0 +1,310 @@
+package hostio
+
+import (
+	"testing"
+
+	"github.com/go-pg/pg/v10"
+	"github.com/stretchr/testify/require"
+	keaconfig "isc.org/stork/appcfg/kea"
+	dbmodel "isc.org/stork/server/database/model"
+	dbmodeltest "isc.org/stork/server/database/model/test"
+	dbtest "isc.org/stork/server/database/test"
+	storkutil "isc.org/stork/util"
+)
+
+// Adds the DHCPv4 and DHCPv6 servers with a subnet each. It returns
+// the servers' daemons.
+func addTestServers(t *testing.T, db *pg.DB) (*dbmodel.Daemon, *dbmodel.Daemon) {
+	var daemons []*dbmodel.Daemon
+	for _, prefix := range []string{"192.0.2.0/24", "2001:db8:1::/64"} {
+		var (
+			server *dbmodeltest.KeaServer
+			err    error
+		)
+		if prefix == "192.0.2.0/24" {
+			server, err = dbmodeltest.NewKeaDHCPv4Server(db)
+		} else {
+			server, err = dbmodeltest.NewKeaDHCPv6Server(db)
+		}
+		require.NoError(t, err)
+		daemon, err := dbmodel.GetDaemonByID(db, server.ID)
+		require.NoError(t, err)
+
+		subnet := &dbmodel.Subnet{
+			Prefix: prefix,
+		}
+		err = dbmodel.AddSubnet(db, subnet)
+		require.NoError(t, err)
+		err = dbmodel.AddDaemonToSubnet(db, subnet, daemon)
+		require.NoError(t, err)
+		daemons = append(daemons, daemon)
+	}
+	return daemons[0], daemons[1]
+}
+
+// Test that the valid records are converted to the hosts.
+func TestValidate(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	dhcp4, dhcp6 := addTestServers(t, db)
+
+	validator := NewValidator(db, dbmodel.NewDHCPOptionDefinitionLookup(), []int64{dhcp4.ID})
+
+	host, err := validator.Validate(Record{
+		IdentifierType: "hw-address",
+		Identifier:     "01:02:03:04:05:06",
+		Subnet:         "192.0.2.0/24",
+		IPAddresses:    []string{"192.0.2.10"},
+		Hostname:       "foo.example.org",
+		ClientClasses:  []string{"foo"},
+		OptionData: []keaconfig.SingleOptionData{
+			{
+				Code:      6,
+				CSVFormat: storkutil.Ptr(true),
+				Data:      "192.0.2.1",
+				Space:     "dhcp4",
+			},
+		},
+	})
+	require.NoError(t, err)
+	require.NotNil(t, host)
+	require.NotZero(t, host.SubnetID)
+	require.NotNil(t, host.Subnet)
+	require.Len(t, host.HostIdentifiers, 1)
+	require.Equal(t, "hw-address", host.HostIdentifiers[0].Type)
+	require.Equal(t, []byte{1, 2, 3, 4, 5, 6}, host.HostIdentifiers[0].Value)
+	require.Len(t, host.LocalHosts, 1)
+	require.Equal(t, dhcp4.ID, host.LocalHosts[0].DaemonID)
+	require.NotNil(t, host.LocalHosts[0].Daemon)
+	require.NotNil(t, host.LocalHosts[0].Daemon.App)
+	require.Equal(t, dbmodel.HostDataSourceAPI, host.LocalHosts[0].DataSource)
+	require.Equal(t, "foo.example.org", host.LocalHosts[0].Hostname)
+	require.Equal(t, []string{"foo"}, host.LocalHosts[0].ClientClasses)
+	require.Len(t, host.LocalHosts[0].IPReservations, 1)
+	require.Equal(t, "192.0.2.10", host.LocalHosts[0].IPReservations[0].Address)
+	require.Len(t, host.LocalHosts[0].Options, 1)
+	require.NotEmpty(t, host.LocalHosts[0].Hash)
+
+	// DHCPv6 host reservation with the addresses and prefixes.
+	host, err = validator.Validate(Record{
+		IdentifierType: "duid",
+		Identifier:     "01:02:03:04",
+		Subnet:         "2001:db8:1::/64",
+		IPAddresses:    []string{"2001:db8:1::10"},
+		Prefixes:       []string{"3000::/64"},
+	})
+	require.NoError(t, err)
+	require.Len(t, host.LocalHosts, 1)
+	require.Equal(t, dhcp6.ID, host.LocalHosts[0].DaemonID)
+	require.Len(t, host.LocalHosts[0].IPReservations, 2)
+
+	// Global host reservation.
+	host, err = validator.Validate(Record{
+		IdentifierType: "client-id",
+		Identifier:     "aabbcc",
+		IPAddresses:    []string{"10.0.0.1"},
+	})
+	require.NoError(t, err)
+	require.Zero(t, host.SubnetID)
+	require.Len(t, host.LocalHosts, 1)
+	require.Equal(t, dhcp4.ID, host.LocalHosts[0].DaemonID)
+}
+
+// Test that the invalid records are rejected.
+func TestValidateInvalid(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	dhcp4, dhcp6 := addTestServers(t, db)
+
+	testCases := map[string]struct {
+		record    Record
+		daemonIDs []int64
+		error     string
+	}{
+		"invalid subnet": {
+			record:    Record{IdentifierType: "hw-address", Identifier: "010203", Subnet: "192.0.2.1"},
+			daemonIDs: []int64{dhcp4.ID},
+			error:     "invalid subnet prefix",
+		},
+		"non-existing subnet": {
+			record: Record{IdentifierType: "hw-address", Identifier: "010203", Subnet: "192.0.3.0/24"},
+			error:  "subnet 192.0.3.0/24 does not exist",
+		},
+		"no global daemons": {
+			record: Record{IdentifierType: "hw-address", Identifier: "010203"},
+			error:  "no daemons selected for global host reservations",
+		},
+		"mixed global daemons": {
+			record:    Record{IdentifierType: "hw-address", Identifier: "010203"},
+			daemonIDs: []int64{dhcp4.ID, dhcp6.ID},
+			error:     "cannot be created in both DHCPv4 and DHCPv6 servers",
+		},
+		"non-existing daemon": {
+			record:    Record{IdentifierType: "hw-address", Identifier: "010203"},
+			daemonIDs: []int64{dhcp6.ID + 100},
+			error:     "does not exist",
+		},
+		"invalid address": {
+			record: Record{IdentifierType: "hw-address", Identifier: "010203", Subnet: "192.0.2.0/24", IPAddresses: []string{"192.0.2"}},
+			error:  "invalid IP address '192.0.2'",
+		},
+		"address family": {
+			record: Record{IdentifierType: "hw-address", Identifier: "010203", Subnet: "192.0.2.0/24", IPAddresses: []string{"2001:db8:1::1"}},
+			error:  "does not match the IPv4 host reservation",
+		},
+		"address out of subnet": {
+			record: Record{IdentifierType: "hw-address", Identifier: "010203", Subnet: "192.0.2.0/24", IPAddresses: []string{"192.0.3.1"}},
+			error:  "does not belong to subnet 192.0.2.0/24",
+		},
+		"multiple IPv4 addresses": {
+			record: Record{IdentifierType: "hw-address", Identifier: "010203", Subnet: "192.0.2.0/24", IPAddresses: []string{"192.0.2.1", "192.0.2.2"}},
+			error:  "at most one IP address",
+		},
+		"IPv4 prefix": {
+			record: Record{IdentifierType: "hw-address", Identifier: "010203", Subnet: "192.0.2.0/24", Prefixes: []string{"192.0.2.0/28"}},
+			error:  "only be reserved in the DHCPv6 host reservation",
+		},
+		"invalid prefix": {
+			record: Record{IdentifierType: "duid", Identifier: "010203", Subnet: "2001:db8:1::/64", Prefixes: []string{"3000::"}},
+			error:  "invalid delegated prefix '3000::'",
+		},
+		"invalid hostname": {
+			record: Record{IdentifierType: "hw-address", Identifier: "010203", Subnet: "192.0.2.0/24", Hostname: "foo..example.org"},
+			error:  "invalid hostname",
+		},
+		"invalid option": {
+			record: Record{
+				IdentifierType: "hw-address", Identifier: "010203", Subnet: "192.0.2.0/24",
+				OptionData: []keaconfig.SingleOptionData{{Code: 6, CSVFormat: storkutil.Ptr(true), Data: "foo", Space: "dhcp4"}},
+			},
+			error: "invalid DHCP option with code 6 in dhcp4 space",
+		},
+	}
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			validator := NewValidator(db, dbmodel.NewDHCPOptionDefinitionLookup(), testCase.daemonIDs)
+			host, err := validator.Validate(testCase.record)
+			require.ErrorContains(t, err, testCase.error)
+			require.Nil(t, host)
+		})
+	}
+}
+
+// Test that the records conflicting with the existing host reservations
+// or the reservations validated earlier are rejected.
+func TestValidateConflicts(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	dhcp4, _ := addTestServers(t, db)
+
+	subnets, err := dbmodel.GetSubnetsByPrefix(db, "192.0.2.0/24")
+	require.NoError(t, err)
+	require.Len(t, subnets, 1)
+
+	// Existing host reservation.
+	err = dbmodel.AddHost(db, &dbmodel.Host{
+		SubnetID: subnets[0].ID,
+		HostIdentifiers: []dbmodel.HostIdentifier{
+			{
+				Type:  "hw-address",
+				Value: []byte{1, 2, 3, 4, 5, 6},
+			},
+		},
+		LocalHosts: []dbmodel.LocalHost{
+			{
+				DaemonID:   dhcp4.ID,
+				DataSource: dbmodel.HostDataSourceConfig,
+				IPReservations: []dbmodel.IPReservation{
+					{
+						Address: "192.0.2.10",
+					},
+				},
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	validator := NewValidator(db, dbmodel.NewDHCPOptionDefinitionLookup(), nil)
+
+	// Same identifier.
+	_, err = validator.Validate(Record{
+		IdentifierType: "hw-address",
+		Identifier:     "010203040506",
+		Subnet:         "192.0.2.0/24",
+	})
+	require.ErrorContains(t, err, "host reservation for hw-address 01:02:03:04:05:06 already exists")
+
+	// Same address.
+	_, err = validator.Validate(Record{
+		IdentifierType: "hw-address",
+		Identifier:     "01:01:01:01:01:01",
+		Subnet:         "192.0.2.0/24",
+		IPAddresses:    []string{"192.0.2.10"},
+	})
+	require.ErrorContains(t, err, "IP address or prefix 192.0.2.10 is already reserved")
+
+	// Valid host reservation.
+	host, err := validator.Validate(Record{
+		IdentifierType: "hw-address",
+		Identifier:     "01:01:01:01:01:01",
+		Subnet:         "192.0.2.0/24",
+		IPAddresses:    []string{"192.0.2.11"},
+	})
+	require.NoError(t, err)
+
+	// The host reservation validated earlier is not taken into account
+	// until it is accepted (e.g., its commit may fail).
+	conflicting := Record{
+		IdentifierType: "hw-address",
+		Identifier:     "02:02:02:02:02:02",
+		Subnet:         "192.0.2.0/24",
+		IPAddresses:    []string{"192.0.2.11"},
+	}
+	conflictingHost, err := validator.Validate(conflicting)
+	require.NoError(t, err)
+
+	// Accept the first host reservation. The second one now conflicts
+	// with it.
+	validator.Accept(host)
+	err = validator.CheckConflicts(conflictingHost)
+	require.ErrorContains(t, err, "IP address or prefix 192.0.2.11 is already reserved")
+
+	// The accepted host reservation is taken into account.
+	_, err = validator.Validate(Record{
+		IdentifierType: "hw-address",
+		Identifier:     "02:02:02:02:02:02",
+		Subnet:         "192.0.2.0/24",
+		IPAddresses:    []string{"192.0.2.11"},
+	})
+	require.ErrorContains(t, err, "IP address or prefix 192.0.2.11 is already reserved")
+
+	// The revoked host reservation is no longer taken into account.
+	validator.Revoke(host)
+	require.NoError(t, validator.CheckConflicts(conflictingHost))
+}
+
+// Test that the records with invalid identifiers are rejected before
+// accessing the database.
+func TestValidateInvalidIdentifier(t *testing.T) {
+	validator := NewValidator(nil, nil, nil)
+
+	_, err := validator.Validate(Record{
+		IdentifierType: "foo",
+		Identifier:     "010203",
+	})
+	require.ErrorContains(t, err, "invalid identifier type 'foo'")
+
+	_, err = validator.Validate(Record{
+		IdentifierType: "hw-address",
+		Identifier:     "01:02:0g",
+	})
+	require.ErrorContains(t, err, "invalid identifier '01:02:0g'")
+
+	_, err = validator.Validate(Record{
+		IdentifierType: "hw-address",
+	})
+	require.ErrorContains(t, err, "invalid identifier ''")
+}
@@ -0,0 +1,736 @@
+package restservice
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-openapi/runtime/middleware"
+	"github.com/go-openapi/strfmt"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	keaconfig "isc.org/stork/appcfg/kea"
+	"isc.org/stork/server/apps/kea"
+	"isc.org/stork/server/config"
+	dbmodel "isc.org/stork/server/database/model"
+	"isc.org/stork/server/gen/models"
+	dhcp "isc.org/stork/server/gen/restapi/operations/d_h_c_p"
+	storkutil "isc.org/stork/util"
+)
+
+// Returns the IP type (universe) of the daemon's configuration.
+func getDaemonIPType(daemon *dbmodel.Daemon) storkutil.IPType {
+	if daemon.KeaDaemon != nil && daemon.KeaDaemon.Config != nil && daemon.KeaDaemon.Config.IsDHCPv6() {
+		return storkutil.IPv6
+	}
+	return storkutil.IPv4
+}
+
+// Converts the client class from the Kea configuration to the format used
+// in the REST API. The universe is required to interpret the class options.
+func (r *RestAPI) convertClientClassParametersToRestAPI(clientClass *keaconfig.ClientClass, universe storkutil.IPType) *models.KeaConfigClientClassDefinitionParameters {
+	var convertedOptions []dbmodel.DHCPOption
+	for _, option := range clientClass.OptionData {
+		convertedOption, err := dbmodel.NewDHCPOptionFromKea(option, universe, r.DHCPOptionDefinitionLookup)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"class":  clientClass.Name,
+				"code":   option.Code,
+				"space":  option.Space,
+				"option": option.Name,
+			}).WithError(err).Warn("Failed to convert the client class option; skipping it")
+			continue
+		}
+		convertedOptions = append(convertedOptions, *convertedOption)
+	}
+	return &models.KeaConfigClientClassDefinitionParameters{
+		KeaConfigPreferredLifetimeParameters: models.KeaConfigPreferredLifetimeParameters{
+			PreferredLifetime:    clientClass.PreferredLifetime,
+			MinPreferredLifetime: clientClass.MinPreferredLifetime,
+			MaxPreferredLifetime: clientClass.MaxPreferredLifetime,
+		},
+		KeaConfigValidLifetimeParameters: models.KeaConfigValidLifetimeParameters{
+			ValidLifetime:    clientClass.ValidLifetime,
+			MinValidLifetime: clientClass.MinValidLifetime,
+			MaxValidLifetime: clientClass.MaxValidLifetime,
+		},
+		KeaConfigAssortedClientClassParameters: models.KeaConfigAssortedClientClassParameters{
+			Test:                 clientClass.Test,
+			TemplateTest:         clientClass.TemplateTest,
+			OnlyIfRequired:       clientClass.OnlyIfRequired,
+			OnlyInAdditionalList: clientClass.OnlyInAdditionalList,
+			BootFileName:         clientClass.BootFileName,
+			NextServer:           clientClass.NextServer,
+			ServerHostname:       clientClass.ServerHostname,
+		},
+		DHCPOptions: models.DHCPOptions{
+			Options:     r.unflattenDHCPOptions(convertedOptions, "", 0),
+			OptionsHash: keaconfig.NewHasher().Hash(convertedOptions),
+		},
+	}
+}
+
+// Converts the client class from the REST API format to the format used
+// in the Kea configuration. The daemon ID is used to find the option
+// definitions for the class options.
+func (r *RestAPI) convertClientClassFromRestAPI(restClientClass *models.ClientClass, daemonID int64) (*keaconfig.ClientClass, error) {
+	clientClass := &keaconfig.ClientClass{
+		Name: restClientClass.Name,
+	}
+	parameters := restClientClass.KeaConfigClientClassParameters
+	if parameters == nil {
+		return clientClass, nil
+	}
+	clientClass.PreferredLifetime = parameters.PreferredLifetime
+	clientClass.MinPreferredLifetime = parameters.MinPreferredLifetime
+	clientClass.MaxPreferredLifetime = parameters.MaxPreferredLifetime
+	clientClass.ValidLifetime = parameters.ValidLifetime
+	clientClass.MinValidLifetime = parameters.MinValidLifetime
+	clientClass.MaxValidLifetime = parameters.MaxValidLifetime
+	clientClass.Test = parameters.Test
+	clientClass.TemplateTest = parameters.TemplateTest
+	clientClass.OnlyIfRequired = parameters.OnlyIfRequired
+	clientClass.OnlyInAdditionalList = parameters.OnlyInAdditionalList
+	clientClass.BootFileName = parameters.BootFileName
+	clientClass.NextServer = parameters.NextServer
+	clientClass.ServerHostname = parameters.ServerHostname
+
+	options, err := r.flattenDHCPOptions("", parameters.Options, 0)
+	if err != nil {
+		return nil, err
+	}
+	for _, option := range options {
+		optionData, err := keaconfig.CreateSingleOptionData(daemonID, r.DHCPOptionDefinitionLookup, option)
+		if err != nil {
+			return nil, err
+		}
+		clientClass.OptionData = append(clientClass.OptionData, *optionData)
+	}
+	return clientClass, nil
+}
+
+// Converts the daemon owning a client class to the format used in the
+// REST API.
+func convertLocalClientClassToRestAPI(daemon *dbmodel.Daemon) *models.LocalClientClass {
+	localClientClass := &models.LocalClientClass{
+		DaemonID:   daemon.ID,
+		DaemonName: daemon.Name,
+	}
+	if daemon.App != nil {
+		localClientClass.AppID = daemon.App.ID
+		localClientClass.AppName = daemon.App.Name
+	}
+	return localClientClass
+}
+
+// Returns the client classes configured in the specified daemons. The
+// classes having the same name and definition in several daemons are
+// returned as a single class associated with all these daemons. The
+// returned classes are sorted by name.
+func (r *RestAPI) getClientClasses(daemons []dbmodel.Daemon, text string) []*models.ClientClass {
+	type groupedClientClass struct {
+		definition  string
+		clientClass *models.ClientClass
+	}
+	var groups []groupedClientClass
+	for i := range daemons {
+		if daemons[i].KeaDaemon == nil || daemons[i].KeaDaemon.Config == nil {
+			continue
+		}
+		for _, clientClass := range daemons[i].KeaDaemon.Config.GetClientClasses() {
+			if text != "" && !strings.Contains(strings.ToLower(clientClass.Name), strings.ToLower(text)) {
+				continue
+			}
+			// Serialized class definition is used to detect the same classes
+			// in the different daemons.
+			definition, err := json.Marshal(clientClass)
+			if err != nil {
+				continue
+			}
+			found := false
+			for _, group := range groups {
+				if group.definition == string(definition) {
+					group.clientClass.LocalClientClasses = append(group.clientClass.LocalClientClasses, convertLocalClientClassToRestAPI(&daemons[i]))
+					found = true
+					break
+				}
+			}
+			if !found {
+				groups = append(groups, groupedClientClass{
+					definition: string(definition),
+					clientClass: &models.ClientClass{
+						Name:                           clientClass.Name,
+						KeaConfigClientClassParameters: r.convertClientClassParametersToRestAPI(&clientClass, getDaemonIPType(&daemons[i])),
+						LocalClientClasses: []*models.LocalClientClass{
+							convertLocalClientClassToRestAPI(&daemons[i]),
+						},
+					},
+				})
+			}
+		}
+	}
+	clientClasses := []*models.ClientClass{}
+	for _, group := range groups {
+		clientClasses = append(clientClasses, group.clientClass)
+	}
+	sort.SliceStable(clientClasses, func(i, j int) bool {
+		return clientClasses[i].Name < clientClasses[j].Name
+	})
+	return clientClasses
+}
+
+// Get client classes configured in the Kea servers.
+func (r *RestAPI) GetClientClasses(ctx context.Context, params dhcp.GetClientClassesParams) middleware.Responder {
+	daemons, err := dbmodel.GetKeaDHCPDaemons(r.DB)
+	if err != nil {
+		msg := "Cannot get Kea daemons from db"
+		log.WithError(err).Error(msg)
+		rsp := dhcp.NewGetClientClassesDefault(http.StatusInternalServerError).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	if params.DaemonID != nil {
+		var filteredDaemons []dbmodel.Daemon
+		for _, daemon := range daemons {
+			if daemon.ID == *params.DaemonID {
+				filteredDaemons = append(filteredDaemons, daemon)
+			}
+		}
+		daemons = filteredDaemons
+	}
+	var text string
+	if params.Text != nil {
+		text = *params.Text
+	}
+	clientClasses := r.getClientClasses(daemons, text)
+	rsp := dhcp.NewGetClientClassesOK().WithPayload(&models.ClientClasses{
+		Items: clientClasses,
+		Total: int64(len(clientClasses)),
+	})
+	return rsp
+}
+
+// Common function executed when creating a new transaction for adding or
+// updating a client class. It fetches available DHCP daemons and the names
+// of the configured client classes. It also creates transaction context. If
+// an error occurs, an http error code and message are returned.
+func (r *RestAPI) commonCreateOrUpdateClientClassBegin(ctx context.Context) ([]*models.KeaDaemon, []string, context.Context, int, string) {
+	// A list of Kea DHCP daemons will be needed in the user form,
+	// so the user can select which servers send the client class to.
+	daemons, err := dbmodel.GetKeaDHCPDaemons(r.DB)
+	if err != nil {
+		msg := "Problem with fetching Kea daemons from the database"
+		log.Error(err)
+		return nil, nil, nil, http.StatusInternalServerError, msg
+	}
+	respDaemons := []*models.KeaDaemon{}
+	respClientClasses := []string{}
+	clientClassesMap := make(map[string]bool)
+	for i := range daemons {
+		if daemons[i].KeaDaemon != nil && daemons[i].KeaDaemon.Config != nil {
+			respDaemons = append(respDaemons, keaDaemonToRestAPI(&daemons[i]))
+			for _, c := range daemons[i].KeaDaemon.Config.GetClientClasses() {
+				clientClassesMap[c.Name] = true
+			}
+		}
+	}
+	// Turn the class map to a slice and sort it by a class name.
+	for c := range clientClassesMap {
+		respClientClasses = append(respClientClasses, c)
+	}
+	sort.Strings(respClientClasses)
+
+	if len(respDaemons) == 0 {
+		msg := "Unable to begin transaction because there are no Kea servers with configurations available"
+		log.Error(msg)
+		return nil, nil, nil, http.StatusBadRequest, msg
+	}
+	// Create configuration context.
+	_, user := r.SessionManager.Logged(ctx)
+	cctx, err := r.ConfigManager.CreateContext(int64(user.ID))
+	if err != nil {
+		msg := "Problem with creating transaction context"
+		log.WithError(err).Error(msg)
+		return nil, nil, nil, http.StatusInternalServerError, msg
+	}
+	return respDaemons, respClientClasses, cctx, 0, ""
+}
+
+// Implements the POST call to create new transaction for adding a new
+// client class (client-classes/new/transaction).
+func (r *RestAPI) CreateClientClassBegin(ctx context.Context, params dhcp.CreateClientClassBeginParams) middleware.Responder {
+	respDaemons, respClientClasses, cctx, code, msg := r.commonCreateOrUpdateClientClassBegin(ctx)
+	if code != 0 {
+		// Error case.
+		rsp := dhcp.NewCreateClientClassBeginDefault(code).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	// Begin client class add transaction.
+	var err error
+	if cctx, err = r.ConfigManager.GetKeaModule().BeginClientClassAdd(cctx); err != nil {
+		msg := "Problem with initializing transaction for creating client class"
+		log.WithError(err).Error(msg)
+		rsp := dhcp.NewCreateClientClassBeginDefault(http.StatusInternalServerError).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	// Retrieve the generated context ID.
+	cctxID, ok := config.GetValueAsInt64(cctx, config.ContextIDKey)
+	if !ok {
+		msg := "problem with retrieving context ID for a transaction to create a client class"
+		log.Error(msg)
+		rsp := dhcp.NewCreateClientClassBeginDefault(http.StatusInternalServerError).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	// Remember the context, i.e. new transaction has been successfully created.
+	_ = r.ConfigManager.RememberContext(cctx, time.Minute*10)
+
+	// Return transaction ID and daemons to the user.
+	contents := &models.CreateClientClassBeginResponse{
+		ID:            cctxID,
+		Daemons:       respDaemons,
+		ClientClasses: respClientClasses,
+	}
+	rsp := dhcp.NewCreateClientClassBeginOK().WithPayload(contents)
+	return rsp
+}
+
+// Implements the POST call and commits a new client class
+// (client-classes/new/transaction/{id}/submit).
+func (r *RestAPI) CreateClientClassSubmit(ctx context.Context, params dhcp.CreateClientClassSubmitParams) middleware.Responder {
+	if code, msg := r.commonCreateOrUpdateClientClassSubmit(ctx, params.ID, params.ClientClass, true, params.ScheduledAt); code != 0 {
+		// Error case.
+		rsp := dhcp.NewCreateClientClassSubmitDefault(code).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	rsp := dhcp.NewCreateClientClassSubmitOK()
+	return rsp
+}
+
+// Implements the POST call to preview the changes of the new client class
+// (client-classes/new/transaction/{id}/preview).
+func (r *RestAPI) CreateClientClassPreview(ctx context.Context, params dhcp.CreateClientClassPreviewParams) middleware.Responder {
+	preview, code, msg := r.commonCreateOrUpdateClientClassPreview(ctx, params.ID, params.ClientClass, true)
+	if code != 0 {
+		// Error case.
+		rsp := dhcp.NewCreateClientClassPreviewDefault(code).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	rsp := dhcp.NewCreateClientClassPreviewOK().WithPayload(preview)
+	return rsp
+}
+
+// Implements the DELETE call to cancel creating a client class
+// (client-classes/new/transaction/{id}). It removes the specified
+// transaction from the config manager, if the transaction exists.
+func (r *RestAPI) CreateClientClassDelete(ctx context.Context, params dhcp.CreateClientClassDeleteParams) middleware.Responder {
+	if code, msg := r.commonCreateOrUpdateClientClassDelete(ctx, params.ID); code != 0 {
+		// Error case.
+		rsp := dhcp.NewCreateClientClassDeleteDefault(code).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	rsp := dhcp.NewCreateClientClassDeleteOK()
+	return rsp
+}
+
+// Implements the POST call to create new transaction for updating an
+// existing client class (client-classes/{name}/transaction).
+func (r *RestAPI) UpdateClientClassBegin(ctx context.Context, params dhcp.UpdateClientClassBeginParams) middleware.Responder {
+	if params.Request == nil || len(params.Request.DaemonIds) == 0 {
+		msg := "No daemons specified for the client class update"
+		log.Error(msg)
+		rsp := dhcp.NewUpdateClientClassBeginDefault(http.StatusBadRequest).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	respDaemons, _, cctx, code, msg := r.commonCreateOrUpdateClientClassBegin(ctx)
+	if code != 0 {
+		// Error case.
+		rsp := dhcp.NewUpdateClientClassBeginDefault(code).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	// Begin client class update transaction. It retrieves current daemons'
+	// configurations and locks the daemons for updates.
+	var err error
+	cctx, err = r.ConfigManager.GetKeaModule().BeginClientClassUpdate(cctx, params.Name, params.Request.DaemonIds)
+	if err != nil {
+		var (
+			clientClassNotFound *config.ClientClassNotFoundError
+			daemonsNotFound     *config.SomeDaemonsNotFoundError
+			lock                *config.LockError
+		)
+		switch {
+		case errors.As(err, &clientClassNotFound):
+			// Failed to find client class.
+			msg := fmt.Sprintf("Unable to edit the client class %s because it cannot be found", params.Name)
+			log.WithError(err).Error(msg)
+			rsp := dhcp.NewUpdateClientClassBeginDefault(http.StatusBadRequest).WithPayload(&models.APIError{
+				Message: &msg,
+			})
+			return rsp
+		case errors.As(err, &daemonsNotFound):
+			// Failed to find some of the daemons.
+			msg := fmt.Sprintf("Unable to edit the client class %s because some daemons cannot be found", params.Name)
+			log.WithError(err).Error(msg)
+			rsp := dhcp.NewUpdateClientClassBeginDefault(http.StatusBadRequest).WithPayload(&models.APIError{
+				Message: &msg,
+			})
+			return rsp
+		case errors.As(err, &lock):
+			// Failed to lock daemons.
+			msg := fmt.Sprintf("Unable to edit the client class %s because it may be currently edited by another user", params.Name)
+			log.WithError(err).Error(msg)
+			rsp := dhcp.NewUpdateClientClassBeginDefault(http.StatusLocked).WithPayload(&models.APIError{
+				Message: &msg,
+			})
+			return rsp
+		default:
+			// Other error.
+			msg := fmt.Sprintf("Problem with initializing transaction for an update of the client class %s", params.Name)
+			log.WithError(err).Error(msg)
+			rsp := dhcp.NewUpdateClientClassBeginDefault(http.StatusInternalServerError).WithPayload(&models.APIError{
+				Message: &msg,
+			})
+			return rsp
+		}
+	}
+	state, _ := config.GetTransactionState[kea.ConfigRecipe](cctx)
+	daemons := state.Updates[0].Recipe.KeaDaemonsBeforeConfigUpdate
+
+	// Retrieve the generated context ID.
+	cctxID, ok := config.GetValueAsInt64(cctx, config.ContextIDKey)
+	if !ok {
+		msg := "problem with retrieving context ID for a transaction to update a client class"
+		log.Error(msg)
+		rsp := dhcp.NewUpdateClientClassBeginDefault(http.StatusInternalServerError).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	// Remember the context, i.e. new transaction has been successfully created.
+	_ = r.ConfigManager.RememberContext(cctx, time.Minute*10)
+
+	// Return the class definition from the first daemon. The class definitions
+	// may differ between the daemons but they are overwritten with the same
+	// definition upon submission.
+	var restClientClass *models.ClientClass
+	for _, clientClass := range r.getClientClasses(daemons[:1], "") {
+		if clientClass.Name == params.Name {
+			restClientClass = clientClass
+			break
+		}
+	}
+	if restClientClass != nil {
+		restClientClass.LocalClientClasses = []*models.LocalClientClass{}
+		for i := range daemons {
+			restClientClass.LocalClientClasses = append(restClientClass.LocalClientClasses, convertLocalClientClassToRestAPI(&daemons[i]))
+		}
+	}
+
+	// Return transaction ID and daemons to the user.
+	contents := &models.UpdateClientClassBeginResponse{
+		ID:          cctxID,
+		ClientClass: restClientClass,
+		Daemons:     respDaemons,
+	}
+	rsp := dhcp.NewUpdateClientClassBeginOK().WithPayload(contents)
+	return rsp
+}
+
+// Implements the POST call and commits an updated client class
+// (client-classes/{name}/transaction/{id}/submit).
+func (r *RestAPI) UpdateClientClassSubmit(ctx context.Context, params dhcp.UpdateClientClassSubmitParams) middleware.Responder {
+	if params.ClientClass != nil && params.ClientClass.Name != params.Name {
+		msg := "Client class name in the request body does not match the client class name in the URL"
+		log.Error(msg)
+		rsp := dhcp.NewUpdateClientClassSubmitDefault(http.StatusBadRequest).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	if code, msg := r.commonCreateOrUpdateClientClassSubmit(ctx, params.ID, params.ClientClass, false, params.ScheduledAt); code != 0 {
+		// Error case.
+		rsp := dhcp.NewUpdateClientClassSubmitDefault(code).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	rsp := dhcp.NewUpdateClientClassSubmitOK()
+	return rsp
+}
+
+// Implements the POST call to preview the changes of the updated client class
+// (client-classes/{name}/transaction/{id}/preview).
+func (r *RestAPI) UpdateClientClassPreview(ctx context.Context, params dhcp.UpdateClientClassPreviewParams) middleware.Responder {
+	if params.ClientClass != nil && params.ClientClass.Name != params.Name {
+		msg := "Client class name in the request body does not match the client class name in the URL"
+		log.Error(msg)
+		rsp := dhcp.NewUpdateClientClassPreviewDefault(http.StatusBadRequest).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	preview, code, msg := r.commonCreateOrUpdateClientClassPreview(ctx, params.ID, params.ClientClass, false)
+	if code != 0 {
+		// Error case.
+		rsp := dhcp.NewUpdateClientClassPreviewDefault(code).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	rsp := dhcp.NewUpdateClientClassPreviewOK().WithPayload(preview)
+	return rsp
+}
+
+// Implements the DELETE call to cancel updating a client class
+// (client-classes/{name}/transaction/{id}). It removes the specified
+// transaction from the config manager, if the transaction exists.
+func (r *RestAPI) UpdateClientClassDelete(ctx context.Context, params dhcp.UpdateClientClassDeleteParams) middleware.Responder {
+	if code, msg := r.commonCreateOrUpdateClientClassDelete(ctx, params.ID); code != 0 {
+		// Error case.
+		rsp := dhcp.NewUpdateClientClassDeleteDefault(code).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	rsp := dhcp.NewUpdateClientClassDeleteOK()
+	return rsp
+}
+
+// Common function that applies a new or updated client class. The transactionID
+// is the identifier of the current configuration transaction used by the
+// function to recover the transaction context. The restClientClass is the
+// pointer to the client class specified by the user. The isNew flag indicates
+// whether a new class is added or an existing class is updated. In the former
+// case, the class is added to the daemons specified in the local client
+// classes. In the latter case, the class is updated in the daemons specified
+// when the transaction began. This function returns the transaction context
+// with the applied changes. It returns the HTTP error code if an error occurs
+// or 0 when there is no error. In addition it returns an error string to be
+// included in the HTTP response or an empty string if there is no error.
+func (r *RestAPI) commonCreateOrUpdateClientClassApply(ctx context.Context, transactionID int64, restClientClass *models.ClientClass, isNew bool) (context.Context, int, string) {
+	// Make sure that the client class information is present.
+	if restClientClass == nil || restClientClass.Name == "" {
+		msg := "Client class information not specified"
+		log.Errorf("Problem with submitting a client class because the client class information is missing")
+		return nil, http.StatusBadRequest, msg
+	}
+	// Retrieve the context from the config manager.
+	_, user := r.SessionManager.Logged(ctx)
+	cctx, _ := r.ConfigManager.RecoverContext(transactionID, int64(user.ID))
+	if cctx == nil {
+		msg := "Transaction expired for the client class update"
+		log.Errorf("Problem with recovering transaction context for transaction ID %d and user ID %d", transactionID, user.ID)
+		return nil, http.StatusNotFound, msg
+	}
+	var (
+		daemons []dbmodel.Daemon
+		err     error
+	)
+	// Apply the changes to a copy of the transaction state. The transaction
+	// remembered by the config manager is only updated upon commit.
+	cctx, err = config.CopyTransactionState(cctx)
+	if err != nil {
+		msg := "Problem with copying the client class transaction state"
+		log.WithError(err).Error(msg)
+		return nil, http.StatusInternalServerError, msg
+	}
+	if isNew {
+		var daemonIDs []int64
+		for _, lcc := range restClientClass.LocalClientClasses {
+			daemonIDs = append(daemonIDs, lcc.DaemonID)
+		}
+		daemons, err = dbmodel.GetDaemonsByIDs(r.DB, daemonIDs)
+		if err != nil {
+			msg := "Problem with fetching daemons from the database"
+			log.WithError(err).Error(msg)
+			return nil, http.StatusInternalServerError, msg
+		}
+		if len(daemons) == 0 || len(daemons) != len(daemonIDs) {
+			msg := "Specified client class is associated with daemons that no longer exist"
+			log.Error(msg)
+			return nil, http.StatusNotFound, msg
+		}
+	} else {
+		state, _ := config.GetTransactionState[kea.ConfigRecipe](cctx)
+		daemons = state.Updates[0].Recipe.KeaDaemonsBeforeConfigUpdate
+		if len(daemons) == 0 {
+			msg := "Transaction contains no daemons for the client class update"
+			log.Error(msg)
+			return nil, http.StatusInternalServerError, msg
+		}
+	}
+	// Convert client class information from REST API to Kea format.
+	clientClass, err := r.convertClientClassFromRestAPI(restClientClass, daemons[0].ID)
+	if err != nil {
+		msg := "Error parsing specified client class"
+		log.WithError(err).Error(msg)
+		return nil, http.StatusBadRequest, msg
+	}
+	// Apply the client class information (create Kea commands).
+	if isNew {
+		cctx, err = r.ConfigManager.GetKeaModule().ApplyClientClassAdd(cctx, clientClass, daemons)
+	} else {
+		cctx, err = r.ConfigManager.GetKeaModule().ApplyClientClassUpdate(cctx, clientClass)
+	}
+	if err != nil {
+		var clientClassExists *config.ClientClassExistsError
+		if errors.As(err, &clientClassExists) {
+			msg := fmt.Sprintf("Problem with applying client class information: %s", clientClassExists)
+			log.WithError(err).Error(msg)
+			return nil, http.StatusConflict, msg
+		}
+		msg := fmt.Sprintf("Problem with applying client class information: %s", err)
+		log.WithError(err).Error(msg)
+		return nil, http.StatusInternalServerError, msg
+	}
+	return cctx, 0, ""
+}
+
+// Common function that implements the POST calls to apply and commit a new or
+// updated client class. The parameters are described in the documentation of
+// the commonCreateOrUpdateClientClassApply function. This function returns the
+// HTTP error code if an error occurs or 0 when there is no error. It also
+// returns an error string to be included in the HTTP response or an empty
+// string if there is no error.
+// The scheduledAt parameter optionally specifies the time when the changes
+// should be committed. If it is nil, the changes are committed immediately.
+func (r *RestAPI) commonCreateOrUpdateClientClassSubmit(ctx context.Context, transactionID int64, restClientClass *models.ClientClass, isNew bool, scheduledAt *strfmt.DateTime) (int, string) {
+	if code, msg := validateConfigChangeDeadline(scheduledAt); code != 0 {
+		return code, msg
+	}
+	cctx, code, msg := r.commonCreateOrUpdateClientClassApply(ctx, transactionID, restClientClass, isNew)
+	if code != 0 {
+		return code, msg
+	}
+	// Send the commands to Kea servers or schedule sending them.
+	cctx, err := r.commitOrScheduleConfigChanges(cctx, scheduledAt)
+	if err != nil {
+		msg := fmt.Sprintf("Problem with committing client class information: %s", err)
+		log.WithError(err).Error(msg)
+		return http.StatusConflict, msg
+	}
+	// Everything ok. Cleanup and send OK to the client.
+	r.ConfigManager.Done(cctx)
+	return 0, ""
+}
+
+// Common function that implements the POST calls to preview the changes of a
+// new or updated client class. The parameters are described in the
+// documentation of the commonCreateOrUpdateClientClassApply function. The
+// changes are neither committed nor remembered in the transaction. This
+// function returns the preview of the changes. It returns the HTTP error code
+// if an error occurs or 0 when there is no error. In addition it returns an
+// error string to be included in the HTTP response or an empty string if there
+// is no error.
+func (r *RestAPI) commonCreateOrUpdateClientClassPreview(ctx context.Context, transactionID int64, restClientClass *models.ClientClass, isNew bool) (*models.ConfigChangesPreview, int, string) {
+	cctx, code, msg := r.commonCreateOrUpdateClientClassApply(ctx, transactionID, restClientClass, isNew)
+	if code != 0 {
+		return nil, code, msg
+	}
+	return r.previewConfigChanges(ctx, cctx)
+}
+
+// Common function that implements the DELETE calls to cancel adding new
+// or updating a client class. It removes the specified transaction from the
+// config manager, if the transaction exists. It returns the HTTP error code
+// if an error occurs or 0 when there is no error. In addition it returns an
+// error string to be included in the HTTP response or an empty string if there
+// is no error.
+func (r *RestAPI) commonCreateOrUpdateClientClassDelete(ctx context.Context, transactionID int64) (int, string) {
+	// Retrieve the context from the config manager.
+	_, user := r.SessionManager.Logged(ctx)
+	cctx, _ := r.ConfigManager.RecoverContext(transactionID, int64(user.ID))
+	if cctx == nil {
+		msg := "Transaction expired for the client class update"
+		log.Errorf("Problem with recovering transaction context for transaction ID %d and user ID %d", transactionID, user.ID)
+		return http.StatusNotFound, msg
+	}
+	r.ConfigManager.Done(cctx)
+	return 0, ""
+}
+
+// Implements the DELETE call for a client class (client-classes/{name}). It
+// sends suitable commands to the specified Kea servers. Similarly to deleting
+// a subnet, deleting a client class is not transactional.
+func (r *RestAPI) DeleteClientClass(ctx context.Context, params dhcp.DeleteClientClassParams) middleware.Responder {
+	daemons, err := dbmodel.GetDaemonsByIDs(r.DB, params.DaemonIds)
+	if err != nil {
+		// Error while communicating with the database.
+		msg := "Problem fetching daemons from db"
+		log.WithError(err).Error(msg)
+		rsp := dhcp.NewDeleteClientClassDefault(http.StatusInternalServerError).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	if len(daemons) == 0 || len(daemons) != len(params.DaemonIds) {
+		msg := fmt.Sprintf("Cannot find some daemons from which the client class %s should be deleted", params.Name)
+		rsp := dhcp.NewDeleteClientClassDefault(http.StatusNotFound).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	// Create configuration context.
+	_, user := r.SessionManager.Logged(ctx)
+	cctx, err := r.ConfigManager.CreateContext(int64(user.ID))
+	if err != nil {
+		msg := "Problem with creating transaction context for deleting the client class"
+		log.WithError(err).Error(err)
+		rsp := dhcp.NewDeleteClientClassDefault(http.StatusInternalServerError).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	// Create Kea commands to delete the client class.
+	cctx, err = r.ConfigManager.GetKeaModule().ApplyClientClassDelete(cctx, params.Name, daemons)
+	if err != nil {
+		var clientClassNotFound *config.ClientClassNotFoundError
+		if errors.As(err, &clientClassNotFound) {
+			msg := fmt.Sprintf("Cannot find the client class %s in some of the daemons", params.Name)
+			log.WithError(err).Error(msg)
+			rsp := dhcp.NewDeleteClientClassDefault(http.StatusNotFound).WithPayload(&models.APIError{
+				Message: &msg,
+			})
+			return rsp
+		}
+		msg := "Problem with preparing commands for deleting the client class"
+		log.WithError(err).Error(msg)
+		rsp := dhcp.NewDeleteClientClassDefault(http.StatusInternalServerError).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	// Send the commands to Kea servers.
+	_, err = r.ConfigManager.Commit(cctx)
+	if err != nil {
+		msg := fmt.Sprintf("Problem with deleting a client class: %s", err)
+		log.WithError(err).Error(msg)
+		rsp := dhcp.NewDeleteClientClassDefault(http.StatusConflict).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	// Send OK to the client.
+	rsp := dhcp.NewDeleteClientClassOK()
+	return rsp
+}
@@ -0,0 +1,588 @@
+package restservice
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	keaconfig "isc.org/stork/appcfg/kea"
+	agentcommtest "isc.org/stork/server/agentcomm/test"
+	"isc.org/stork/server/apps"
+	"isc.org/stork/server/apps/kea"
+	appstest "isc.org/stork/server/apps/test"
+	dbops "isc.org/stork/server/database"
+	dbmodel "isc.org/stork/server/database/model"
+	dbmodeltest "isc.org/stork/server/database/model/test"
+	dbtest "isc.org/stork/server/database/test"
+	"isc.org/stork/server/gen/models"
+	dhcp "isc.org/stork/server/gen/restapi/operations/d_h_c_p"
+	storktest "isc.org/stork/server/test/dbmodel"
+	"isc.org/stork/testutil"
+	storkutil "isc.org/stork/util"
+)
+
+// Adds two DHCPv4 servers with the specified configurations to the database.
+// It returns the daemons sorted by ID.
+func addTestClientClassServers(t *testing.T, db *dbops.PgDB, serverConfigs ...string) []dbmodel.Daemon {
+	var daemonIDs []int64
+	for _, serverConfig := range serverConfigs {
+		server, err := dbmodeltest.NewKeaDHCPv4Server(db)
+		require.NoError(t, err)
+		err = server.Configure(serverConfig)
+		require.NoError(t, err)
+
+		app, err := server.GetKea()
+		require.NoError(t, err)
+
+		err = kea.CommitAppIntoDB(db, app, &storktest.FakeEventCenter{}, nil, dbmodel.NewDHCPOptionDefinitionLookup())
+		require.NoError(t, err)
+		daemonIDs = append(daemonIDs, app.Daemons[0].ID)
+	}
+	daemons, err := dbmodel.GetDaemonsByIDs(db, daemonIDs)
+	require.NoError(t, err)
+	require.Len(t, daemons, len(serverConfigs))
+	return daemons
+}
+
+// Creates REST API instance with the config manager and fake agents
+// for the client class tests. It returns the API, the fake agents and
+// the context with the logged user.
+func newTestClientClassRestAPI(t *testing.T, db *dbops.PgDB, dbSettings *dbops.DatabaseSettings) (*RestAPI, *agentcommtest.FakeAgents, context.Context) {
+	// Create fake agents receiving commands.
+	fa := agentcommtest.NewFakeAgents(nil, nil)
+	require.NotNil(t, fa)
+
+	lookup := dbmodel.NewDHCPOptionDefinitionLookup()
+	require.NotNil(t, lookup)
+
+	// Create the config manager.
+	cm := apps.NewManager(&appstest.ManagerAccessorsWrapper{
+		DB:        db,
+		Agents:    fa,
+		DefLookup: lookup,
+	})
+	require.NotNil(t, cm)
+
+	// Create API.
+	rapi, err := NewRestAPI(dbSettings, db, fa, cm, lookup)
+	require.NoError(t, err)
+
+	// Create session manager.
+	ctx, err := rapi.SessionManager.Load(context.Background(), "")
+	require.NoError(t, err)
+
+	// Create user session.
+	user := &dbmodel.SystemUser{
+		ID: 1234,
+	}
+	err = rapi.SessionManager.LoginHandler(ctx, user)
+	require.NoError(t, err)
+
+	return rapi, fa, ctx
+}
+
+// Test converting a client class from the REST API format to the Kea
+// format and back.
+func TestConvertClientClassRestAPI(t *testing.T) {
+	rapi := &RestAPI{}
+	restClientClass := &models.ClientClass{
+		Name: "foo",
+		KeaConfigClientClassParameters: &models.KeaConfigClientClassDefinitionParameters{
+			KeaConfigValidLifetimeParameters: models.KeaConfigValidLifetimeParameters{
+				ValidLifetime: storkutil.Ptr(int64(1000)),
+			},
+			KeaConfigAssortedClientClassParameters: models.KeaConfigAssortedClientClassParameters{
+				Test:       storkutil.Ptr("member('KNOWN')"),
+				NextServer: storkutil.Ptr("192.0.2.1"),
+			},
+		},
+	}
+	clientClass, err := rapi.convertClientClassFromRestAPI(restClientClass, 1)
+	require.NoError(t, err)
+	require.NotNil(t, clientClass)
+	require.Equal(t, "foo", clientClass.Name)
+	require.NotNil(t, clientClass.ValidLifetime)
+	require.EqualValues(t, 1000, *clientClass.ValidLifetime)
+	require.NotNil(t, clientClass.Test)
+	require.Equal(t, "member('KNOWN')", *clientClass.Test)
+	require.NotNil(t, clientClass.NextServer)
+	require.Equal(t, "192.0.2.1", *clientClass.NextServer)
+	require.Empty(t, clientClass.OptionData)
+
+	parameters := rapi.convertClientClassParametersToRestAPI(clientClass, storkutil.IPv4)
+	require.NotNil(t, parameters)
+	require.Equal(t, restClientClass.KeaConfigClientClassParameters.KeaConfigValidLifetimeParameters, parameters.KeaConfigValidLifetimeParameters)
+	require.Equal(t, restClientClass.KeaConfigClientClassParameters.KeaConfigAssortedClientClassParameters, parameters.KeaConfigAssortedClientClassParameters)
+	require.Empty(t, parameters.Options)
+}
+
+// Test that an option which cannot be converted is skipped and logged
+// while the remaining class options are returned.
+func TestConvertClientClassParametersToRestAPIInvalidOption(t *testing.T) {
+	rapi := &RestAPI{
+		DHCPOptionDefinitionLookup: dbmodel.NewDHCPOptionDefinitionLookup(),
+	}
+	clientClass := &keaconfig.ClientClass{
+		Name: "foo",
+		OptionData: []keaconfig.SingleOptionData{
+			{
+				Code:      3,
+				Space:     "dhcp4",
+				CSVFormat: storkutil.Ptr(true),
+				Data:      "not-an-address",
+			},
+			{
+				Code:      6,
+				Space:     "dhcp4",
+				CSVFormat: storkutil.Ptr(true),
+				Data:      "192.0.2.1",
+			},
+		},
+	}
+	var parameters *models.KeaConfigClientClassDefinitionParameters
+	stdout, _, err := testutil.CaptureOutput(func() {
+		parameters = rapi.convertClientClassParametersToRestAPI(clientClass, storkutil.IPv4)
+	})
+	require.NoError(t, err)
+	require.NotNil(t, parameters)
+	require.Len(t, parameters.Options, 1)
+	require.EqualValues(t, 6, parameters.Options[0].Code)
+	require.Contains(t, string(stdout), "Failed to convert the client class option")
+	require.Contains(t, string(stdout), "class=foo")
+}
+
+// Test that the client classes are returned over the REST API and the
+// identical classes are grouped.
+func TestGetClientClasses(t *testing.T) {
+	db, dbSettings, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	daemons := addTestClientClassServers(t, db, `{
+		"Dhcp4": {
+			"client-classes": [
+				{
+					"name": "foo",
+					"test": "member('KNOWN')"
+				},
+				{
+					"name": "bar",
+					"next-server": "192.0.2.1"
+				}
+			]
+		}
+	}`, `{
+		"Dhcp4": {
+			"client-classes": [
+				{
+					"name": "foo",
+					"test": "member('KNOWN')"
+				},
+				{
+					"name": "bar",
+					"next-server": "192.0.2.2"
+				}
+			]
+		}
+	}`)
+
+	rapi, _, ctx := newTestClientClassRestAPI(t, db, dbSettings)
+
+	rsp := rapi.GetClientClasses(ctx, dhcp.GetClientClassesParams{})
+	require.IsType(t, &dhcp.GetClientClassesOK{}, rsp)
+	clientClasses := rsp.(*dhcp.GetClientClassesOK).Payload
+	require.EqualValues(t, 3, clientClasses.Total)
+	require.Len(t, clientClasses.Items, 3)
+
+	// The bar classes differ, so they are returned separately.
+	require.Equal(t, "bar", clientClasses.Items[0].Name)
+	require.Len(t, clientClasses.Items[0].LocalClientClasses, 1)
+	require.Equal(t, daemons[0].ID, clientClasses.Items[0].LocalClientClasses[0].DaemonID)
+	require.Equal(t, "192.0.2.1", *clientClasses.Items[0].KeaConfigClientClassParameters.NextServer)
+	require.Equal(t, "bar", clientClasses.Items[1].Name)
+	require.Len(t, clientClasses.Items[1].LocalClientClasses, 1)
+	require.Equal(t, daemons[1].ID, clientClasses.Items[1].LocalClientClasses[0].DaemonID)
+	require.Equal(t, "192.0.2.2", *clientClasses.Items[1].KeaConfigClientClassParameters.NextServer)
+
+	// The foo classes are identical.
+	require.Equal(t, "foo", clientClasses.Items[2].Name)
+	require.Len(t, clientClasses.Items[2].LocalClientClasses, 2)
+	require.Equal(t, "member('KNOWN')", *clientClasses.Items[2].KeaConfigClientClassParameters.Test)
+
+	// Filter by daemon.
+	rsp = rapi.GetClientClasses(ctx, dhcp.GetClientClassesParams{
+		DaemonID: storkutil.Ptr(daemons[1].ID),
+	})
+	require.IsType(t, &dhcp.GetClientClassesOK{}, rsp)
+	clientClasses = rsp.(*dhcp.GetClientClassesOK).Payload
+	require.EqualValues(t, 2, clientClasses.Total)
+
+	// Filter by text.
+	rsp = rapi.GetClientClasses(ctx, dhcp.GetClientClassesParams{
+		Text: storkutil.Ptr("FO"),
+	})
+	require.IsType(t, &dhcp.GetClientClassesOK{}, rsp)
+	clientClasses = rsp.(*dhcp.GetClientClassesOK).Payload
+	require.EqualValues(t, 1, clientClasses.Total)
+	require.Equal(t, "foo", clientClasses.Items[0].Name)
+}
+
+// Test the calls for creating new transaction and submitting a new client class.
+func TestCreateClientClassBeginSubmit(t *testing.T) {
+	db, dbSettings, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	daemons := addTestClientClassServers(t, db, `{
+		"Dhcp4": {
+			"client-classes": [
+				{
+					"name": "foo"
+				}
+			],
+			"hooks-libraries": [
+				{
+					"library": "libdhcp_class_cmds"
+				}
+			]
+		}
+	}`, `{
+		"Dhcp4": {}
+	}`)
+
+	rapi, fa, ctx := newTestClientClassRestAPI(t, db, dbSettings)
+
+	// Begin transaction.
+	rsp := rapi.CreateClientClassBegin(ctx, dhcp.CreateClientClassBeginParams{})
+	require.IsType(t, &dhcp.CreateClientClassBeginOK{}, rsp)
+	contents := rsp.(*dhcp.CreateClientClassBeginOK).Payload
+	transactionID := contents.ID
+	require.NotZero(t, transactionID)
+	require.Len(t, contents.Daemons, 2)
+	require.Equal(t, []string{"foo"}, contents.ClientClasses)
+
+	// Submit the class.
+	params := dhcp.CreateClientClassSubmitParams{
+		ID: transactionID,
+		ClientClass: &models.ClientClass{
+			Name: "bar",
+			KeaConfigClientClassParameters: &models.KeaConfigClientClassDefinitionParameters{
+				KeaConfigAssortedClientClassParameters: models.KeaConfigAssortedClientClassParameters{
+					Test: storkutil.Ptr("member('KNOWN')"),
+				},
+			},
+			LocalClientClasses: []*models.LocalClientClass{
+				{
+					DaemonID: daemons[0].ID,
+				},
+				{
+					DaemonID: daemons[1].ID,
+				},
+			},
+		},
+	}
+	rsp = rapi.CreateClientClassSubmit(ctx, params)
+	require.IsType(t, &dhcp.CreateClientClassSubmitOK{}, rsp)
+
+	// The class-add should be sent to the first server, config-set to the
+	// second server and config-write to both.
+	require.Len(t, fa.RecordedCommands, 4)
+	require.JSONEq(t, `{
+		"command": "class-add",
+		"service": ["dhcp4"],
+		"arguments": {
+			"client-classes": [
+				{
+					"name": "bar",
+					"test": "member('KNOWN')"
+				}
+			]
+		}
+	}`, fa.RecordedCommands[0].Marshal())
+	require.JSONEq(t, `{
+		"command": "config-set",
+		"service": ["dhcp4"],
+		"arguments": {
+			"Dhcp4": {
+				"client-classes": [
+					{
+						"name": "bar",
+						"test": "member('KNOWN')"
+					}
+				]
+			}
+		}
+	}`, fa.RecordedCommands[1].Marshal())
+
+	// The class should be stored in the database.
+	updatedDaemons, err := dbmodel.GetDaemonsByIDs(db, []int64{daemons[0].ID, daemons[1].ID})
+	require.NoError(t, err)
+	require.Len(t, updatedDaemons, 2)
+	for _, daemon := range updatedDaemons {
+		require.NotNil(t, daemon.KeaDaemon.Config.GetClientClass("bar"))
+	}
+
+	// The transaction should have been removed.
+	rsp = rapi.CreateClientClassSubmit(ctx, params)
+	require.IsType(t, &dhcp.CreateClientClassSubmitDefault{}, rsp)
+	require.Equal(t, http.StatusNotFound, getStatusCode(*rsp.(*dhcp.CreateClientClassSubmitDefault)))
+}
+
+// Test that submitting a client class which already exists fails.
+func TestCreateClientClassSubmitExisting(t *testing.T) {
+	db, dbSettings, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	daemons := addTestClientClassServers(t, db, `{
+		"Dhcp4": {
+			"client-classes": [
+				{
+					"name": "foo"
+				}
+			]
+		}
+	}`)
+
+	rapi, fa, ctx := newTestClientClassRestAPI(t, db, dbSettings)
+
+	rsp := rapi.CreateClientClassBegin(ctx, dhcp.CreateClientClassBeginParams{})
+	require.IsType(t, &dhcp.CreateClientClassBeginOK{}, rsp)
+	transactionID := rsp.(*dhcp.CreateClientClassBeginOK).Payload.ID
+
+	rsp = rapi.CreateClientClassSubmit(ctx, dhcp.CreateClientClassSubmitParams{
+		ID: transactionID,
+		ClientClass: &models.ClientClass{
+			Name: "foo",
+			LocalClientClasses: []*models.LocalClientClass{
+				{
+					DaemonID: daemons[0].ID,
+				},
+			},
+		},
+	})
+	require.IsType(t, &dhcp.CreateClientClassSubmitDefault{}, rsp)
+	require.Equal(t, http.StatusConflict, getStatusCode(*rsp.(*dhcp.CreateClientClassSubmitDefault)))
+	require.Empty(t, fa.RecordedCommands)
+}
+
+// Test the calls for creating new transaction and updating a client class.
+func TestUpdateClientClassBeginSubmit(t *testing.T) {
+	db, dbSettings, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	serverConfig := `{
+		"Dhcp4": {
+			"client-classes": [
+				{
+					"name": "foo",
+					"test": "member('KNOWN')"
+				},
+				{
+					"name": "bar"
+				}
+			]
+		}
+	}`
+	daemons := addTestClientClassServers(t, db, serverConfig, serverConfig)
+
+	rapi, fa, ctx := newTestClientClassRestAPI(t, db, dbSettings)
+
+	// Begin transaction.
+	rsp := rapi.UpdateClientClassBegin(ctx, dhcp.UpdateClientClassBeginParams{
+		Name: "foo",
+		Request: &models.UpdateClientClassBeginRequest{
+			DaemonIds: []int64{daemons[0].ID, daemons[1].ID},
+		},
+	})
+	require.IsType(t, &dhcp.UpdateClientClassBeginOK{}, rsp)
+	contents := rsp.(*dhcp.UpdateClientClassBeginOK).Payload
+	transactionID := contents.ID
+	require.NotZero(t, transactionID)
+	require.Len(t, contents.Daemons, 2)
+	require.NotNil(t, contents.ClientClass)
+	require.Equal(t, "foo", contents.ClientClass.Name)
+	require.Len(t, contents.ClientClass.LocalClientClasses, 2)
+	require.Equal(t, "member('KNOWN')", *contents.ClientClass.KeaConfigClientClassParameters.Test)
+
+	// Renaming the class is not allowed.
+	contents.ClientClass.Name = "baz"
+	rsp = rapi.UpdateClientClassSubmit(ctx, dhcp.UpdateClientClassSubmitParams{
+		ID:          transactionID,
+		Name:        "foo",
+		ClientClass: contents.ClientClass,
+	})
+	require.IsType(t, &dhcp.UpdateClientClassSubmitDefault{}, rsp)
+	require.Equal(t, http.StatusBadRequest, getStatusCode(*rsp.(*dhcp.UpdateClientClassSubmitDefault)))
+
+	// Submit the updated class.
+	contents.ClientClass.Name = "foo"
+	contents.ClientClass.KeaConfigClientClassParameters.Test = storkutil.Ptr("member('UNKNOWN')")
+	rsp = rapi.UpdateClientClassSubmit(ctx, dhcp.UpdateClientClassSubmitParams{
+		ID:          transactionID,
+		Name:        "foo",
+		ClientClass: contents.ClientClass,
+	})
+	require.IsType(t, &dhcp.UpdateClientClassSubmitOK{}, rsp)
+
+	// The config-set and config-write commands should be sent to both servers.
+	require.Len(t, fa.RecordedCommands, 4)
+	for i, command := range fa.RecordedCommands {
+		switch {
+		case i < 2:
+			require.JSONEq(t, `{
+				"command": "config-set",
+				"service": ["dhcp4"],
+				"arguments": {
+					"Dhcp4": {
+						"client-classes": [
+							{
+								"name": "foo",
+								"test": "member('UNKNOWN')"
+							},
+							{
+								"name": "bar"
+							}
+						]
+					}
+				}
+			}`, command.Marshal())
+		default:
+			require.JSONEq(t, `{
+				"command": "config-write",
+				"service": ["dhcp4"]
+			}`, command.Marshal())
+		}
+	}
+
+	// The updated class should be stored in the database.
+	updatedDaemons, err := dbmodel.GetDaemonsByIDs(db, []int64{daemons[0].ID, daemons[1].ID})
+	require.NoError(t, err)
+	for _, daemon := range updatedDaemons {
+		clientClass := daemon.KeaDaemon.Config.GetClientClass("foo")
+		require.NotNil(t, clientClass)
+		require.Equal(t, "member('UNKNOWN')", *clientClass.Test)
+	}
+}
+
+// Test that a transaction cannot be started for a non-existing client class.
+func TestUpdateClientClassBeginNonExisting(t *testing.T) {
+	db, dbSettings, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	daemons := addTestClientClassServers(t, db, `{
+		"Dhcp4": {}
+	}`)
+
+	rapi, _, ctx := newTestClientClassRestAPI(t, db, dbSettings)
+
+	rsp := rapi.UpdateClientClassBegin(ctx, dhcp.UpdateClientClassBeginParams{
+		Name: "foo",
+		Request: &models.UpdateClientClassBeginRequest{
+			DaemonIds: []int64{daemons[0].ID},
+		},
+	})
+	require.IsType(t, &dhcp.UpdateClientClassBeginDefault{}, rsp)
+	require.Equal(t, http.StatusBadRequest, getStatusCode(*rsp.(*dhcp.UpdateClientClassBeginDefault)))
+}
+
+// Test cancelling the transaction for updating a client class.
+func TestUpdateClientClassBeginCancel(t *testing.T) {
+	db, dbSettings, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	daemons := addTestClientClassServers(t, db, `{
+		"Dhcp4": {
+			"client-classes": [
+				{
+					"name": "foo"
+				}
+			]
+		}
+	}`)
+
+	rapi, _, ctx := newTestClientClassRestAPI(t, db, dbSettings)
+
+	rsp := rapi.UpdateClientClassBegin(ctx, dhcp.UpdateClientClassBeginParams{
+		Name: "foo",
+		Request: &models.UpdateClientClassBeginRequest{
+			DaemonIds: []int64{daemons[0].ID},
+		},
+	})
+	require.IsType(t, &dhcp.UpdateClientClassBeginOK{}, rsp)
+	transactionID := rsp.(*dhcp.UpdateClientClassBeginOK).Payload.ID
+
+	// The daemon is locked, so another transaction cannot be started.
+	rsp = rapi.UpdateClientClassBegin(ctx, dhcp.UpdateClientClassBeginParams{
+		Name: "foo",
+		Request: &models.UpdateClientClassBeginRequest{
+			DaemonIds: []int64{daemons[0].ID},
+		},
+	})
+	require.IsType(t, &dhcp.UpdateClientClassBeginDefault{}, rsp)
+	require.Equal(t, http.StatusLocked, getStatusCode(*rsp.(*dhcp.UpdateClientClassBeginDefault)))
+
+	rsp = rapi.UpdateClientClassDelete(ctx, dhcp.UpdateClientClassDeleteParams{
+		Name: "foo",
+		ID:   transactionID,
+	})
+	require.IsType(t, &dhcp.UpdateClientClassDeleteOK{}, rsp)
+
+	// The daemon has been unlocked.
+	rsp = rapi.UpdateClientClassBegin(ctx, dhcp.UpdateClientClassBeginParams{
+		Name: "foo",
+		Request: &models.UpdateClientClassBeginRequest{
+			DaemonIds: []int64{daemons[0].ID},
+		},
+	})
+	require.IsType(t, &dhcp.UpdateClientClassBeginOK{}, rsp)
+}
+
+// Test deleting a client class.
+func TestDeleteClientClass(t *testing.T) {
+	db, dbSettings, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	daemons := addTestClientClassServers(t, db, `{
+		"Dhcp4": {
+			"client-classes": [
+				{
+					"name": "foo"
+				}
+			],
+			"hooks-libraries": [
+				{
+					"library": "libdhcp_class_cmds"
+				}
+			]
+		}
+	}`)
+
+	rapi, fa, ctx := newTestClientClassRestAPI(t, db, dbSettings)
+
+	// The class does not exist.
+	rsp := rapi.DeleteClientClass(ctx, dhcp.DeleteClientClassParams{
+		Name:      "bar",
+		DaemonIds: []int64{daemons[0].ID},
+	})
+	require.IsType(t, &dhcp.DeleteClientClassDefault{}, rsp)
+	require.Equal(t, http.StatusNotFound, getStatusCode(*rsp.(*dhcp.DeleteClientClassDefault)))
+
+	rsp = rapi.DeleteClientClass(ctx, dhcp.DeleteClientClassParams{
+		Name:      "foo",
+		DaemonIds: []int64{daemons[0].ID},
+	})
+	require.IsType(t, &dhcp.DeleteClientClassOK{}, rsp)
+
+	require.Len(t, fa.RecordedCommands, 2)
+	require.JSONEq(t, `{
+		"command": "class-del",
+		"service": ["dhcp4"],
+		"arguments": {
+			"name": "foo"
+		}
+	}`, fa.RecordedCommands[0].Marshal())
+
+	updatedDaemons, err := dbmodel.GetDaemonsByIDs(db, []int64{daemons[0].ID})
+	require.NoError(t, err)
+	require.Len(t, updatedDaemons, 1)
+	require.Empty(t, updatedDaemons[0].KeaDaemon.Config.GetClientClasses())
+}
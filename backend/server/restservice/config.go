@@ -219,16 +219,17 @@ func (r *RestAPI) PutDaemonConfigReview(ctx context.Context, params services.Put
 		})
 		return rsp
 	}
-	// Config review is currently only supported for Kea.
-	if daemon.KeaDaemon == nil {
-		msg := fmt.Sprintf("Daemon with ID %d is not a Kea daemon", params.ID)
+	// Config review is currently only supported for Kea and BIND 9.
+	if daemon.KeaDaemon == nil && daemon.Bind9Daemon == nil {
+		msg := fmt.Sprintf("Daemon with ID %d is not a Kea or BIND 9 daemon", params.ID)
 		rsp := services.NewPutDaemonConfigReviewDefault(http.StatusBadRequest).WithPayload(&models.APIError{
 			Message: &msg,
 		})
 		return rsp
 	}
 	// Config must be present to perform the review.
-	if daemon.KeaDaemon.Config == nil {
+	if (daemon.KeaDaemon != nil && daemon.KeaDaemon.Config == nil) ||
+		(daemon.Bind9Daemon != nil && len(daemon.Bind9Daemon.Config) == 0) {
 		msg := fmt.Sprintf("Configuration not found for daemon with ID %d", params.ID)
 		rsp := services.NewPutDaemonConfigReviewDefault(http.StatusBadRequest).WithPayload(&models.APIError{
 			Message: &msg,
@@ -506,6 +507,69 @@ func (r *RestAPI) DeleteKeaDaemonConfigHashes(ctx context.Context, params servic
 	return rsp
 }
 
+// Writes the daemon's running configuration to the configuration file. It
+// allows for persisting the configuration changes that haven't been
+// written to the file automatically.
+func (r *RestAPI) WriteDaemonConfig(ctx context.Context, params services.WriteDaemonConfigParams) middleware.Responder {
+	dbDaemon, err := dbmodel.GetDaemonByID(r.DB, params.ID)
+	if err != nil {
+		msg := fmt.Sprintf("Cannot get daemon with ID %d from db", params.ID)
+		log.WithError(err).Error(msg)
+		rsp := services.NewWriteDaemonConfigDefault(http.StatusInternalServerError).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	if dbDaemon == nil {
+		msg := fmt.Sprintf("Cannot find daemon with ID %d", params.ID)
+		rsp := services.NewWriteDaemonConfigDefault(http.StatusNotFound).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	if dbDaemon.KeaDaemon == nil {
+		msg := fmt.Sprintf("Daemon with ID %d is not a Kea daemon", params.ID)
+		rsp := services.NewWriteDaemonConfigDefault(http.StatusBadRequest).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	// Create configuration context.
+	_, dbUser := r.SessionManager.Logged(ctx)
+	cctx, err := r.ConfigManager.CreateContext(int64(dbUser.ID))
+	if err != nil {
+		msg := "Problem with creating transaction context for writing the configuration"
+		log.WithError(err).Error(msg)
+		rsp := services.NewWriteDaemonConfigDefault(http.StatusInternalServerError).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	cctx, err = r.ConfigManager.GetKeaModule().ApplyConfigWrite(cctx, *dbDaemon)
+	if err != nil {
+		msg := fmt.Sprintf("Problem with preparing the command for writing the configuration: %s", err)
+		log.WithError(err).Error(msg)
+		rsp := services.NewWriteDaemonConfigDefault(http.StatusBadRequest).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	// Send the command to the Kea server.
+	_, err = r.ConfigManager.Commit(cctx)
+	if err != nil {
+		msg := fmt.Sprintf("Problem with writing the configuration: %s", err)
+		log.WithError(err).Error(msg)
+		rsp := services.NewWriteDaemonConfigDefault(http.StatusConflict).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	r.EventCenter.AddInfoEvent("{user} wrote configuration of {daemon} to the configuration file", dbUser, dbDaemon, dbDaemon.App, dbDaemon.App.Machine)
+
+	rsp := services.NewWriteDaemonConfigOK()
+	return rsp
+}
+
 // Implements the POST call to create new transaction for updating global
 // Kea configurations (kea-global-parameters/transaction).
 func (r *RestAPI) UpdateKeaGlobalParametersBegin(ctx context.Context, params dhcp.UpdateKeaGlobalParametersBeginParams) middleware.Responder {
@@ -621,34 +685,42 @@ func (r *RestAPI) UpdateKeaGlobalParametersBegin(ctx context.Context, params dhc
 	return rsp
 }
 
-// Implements the POST call and commits updated global Kea configurations
-// (kea-global-parameters/transaction/{id}/submit).
-func (r *RestAPI) UpdateKeaGlobalParametersSubmit(ctx context.Context, params dhcp.UpdateKeaGlobalParametersSubmitParams) middleware.Responder {
+// Common function that applies the updated global Kea configurations in the
+// transaction context. The transactionID is the identifier of the current
+// configuration transaction used by the function to recover the transaction
+// context. The request holds the partial configurations specified by the user.
+// This function returns the transaction context with the applied changes. It
+// returns the HTTP error code if an error occurs or 0 when there is no error.
+// In addition it returns an error string to be included in the HTTP response
+// or an empty string if there is no error.
+func (r *RestAPI) commonUpdateKeaGlobalParametersApply(ctx context.Context, transactionID int64, request *models.UpdateKeaDaemonsGlobalParametersSubmitRequest) (context.Context, int, string) {
 	// Retrieve the context from the config manager.
 	_, user := r.SessionManager.Logged(ctx)
-	cctx, _ := r.ConfigManager.RecoverContext(params.ID, int64(user.ID))
+	cctx, _ := r.ConfigManager.RecoverContext(transactionID, int64(user.ID))
 	if cctx == nil {
 		msg := "Transaction expired for the Kea configs update"
-		log.Errorf("Problem with recovering transaction context for transaction ID %d and user ID %d", params.ID, user.ID)
-		rsp := dhcp.NewUpdateKeaGlobalParametersSubmitDefault(http.StatusNotFound).WithPayload(&models.APIError{
-			Message: &msg,
-		})
-		return rsp
+		log.Errorf("Problem with recovering transaction context for transaction ID %d and user ID %d", transactionID, user.ID)
+		return nil, http.StatusNotFound, msg
+	}
+	// Apply the changes to a copy of the transaction state. The transaction
+	// remembered by the config manager is only updated upon commit.
+	cctx, err := config.CopyTransactionState(cctx)
+	if err != nil {
+		msg := "Problem with copying the Kea configs transaction state"
+		log.WithError(err).Error(msg)
+		return nil, http.StatusInternalServerError, msg
 	}
 
 	// Configs are mandatory
-	if len(params.Request.Configs) == 0 {
+	if request == nil || len(request.Configs) == 0 {
 		msg := "No configs for update have been specified"
 		log.Error(msg)
-		rsp := dhcp.NewUpdateKeaGlobalParametersSubmitDefault(http.StatusBadRequest).WithPayload(&models.APIError{
-			Message: &msg,
-		})
-		return rsp
+		return nil, http.StatusBadRequest, msg
 	}
 
 	var settableConfigs []config.AnnotatedEntity[*keaconfig.SettableConfig]
-	for i := range params.Request.Configs {
-		receivedConfig := params.Request.Configs[i]
+	for i := range request.Configs {
+		receivedConfig := request.Configs[i]
 		var settableConfig *keaconfig.SettableConfig
 		switch receivedConfig.DaemonName {
 		case dbmodel.DaemonNameDHCPv4:
@@ -705,10 +777,7 @@ func (r *RestAPI) UpdateKeaGlobalParametersSubmit(ctx context.Context, params dh
 			if err != nil {
 				msg := fmt.Sprintf("Problem with flattening DHCP options: %s", err)
 				log.WithError(err).Error(msg)
-				rsp := dhcp.NewUpdateKeaGlobalParametersSubmitDefault(http.StatusBadRequest).WithPayload(&models.APIError{
-					Message: &msg,
-				})
-				return rsp
+				return nil, http.StatusBadRequest, msg
 			}
 
 			singleOptions := make([]keaconfig.SingleOptionData, 0, len(options))
@@ -731,10 +800,7 @@ func (r *RestAPI) UpdateKeaGlobalParametersSubmit(ctx context.Context, params dh
 					// parser results and doesn't reveal any internal issues.
 					msg = fmt.Sprintf("%s: %v", msg, err)
 
-					rsp := dhcp.NewUpdateKeaGlobalParametersSubmitDefault(http.StatusBadRequest).WithPayload(&models.APIError{
-						Message: &msg,
-					})
-					return rsp
+					return nil, http.StatusBadRequest, msg
 				}
 				singleOptions = append(singleOptions, *singleOption)
 			}
@@ -751,7 +817,6 @@ func (r *RestAPI) UpdateKeaGlobalParametersSubmit(ctx context.Context, params dh
 		}
 		settableConfigs = append(settableConfigs, *config.NewAnnotatedEntity(receivedConfig.DaemonID, settableConfig))
 	}
-	var err error
 	cctx, err = r.ConfigManager.GetKeaModule().ApplyGlobalParametersUpdate(cctx, settableConfigs)
 	if err != nil {
 		var invalidConfigs *config.InvalidConfigsError
@@ -760,22 +825,35 @@ func (r *RestAPI) UpdateKeaGlobalParametersSubmit(ctx context.Context, params dh
 			// Invalid configs applied.
 			msg := "Problem with applying Kea global parameters because invalid set of configurations have been specified"
 			log.WithError(err).Error(msg)
-			rsp := dhcp.NewUpdateKeaGlobalParametersSubmitDefault(http.StatusBadRequest).WithPayload(&models.APIError{
-				Message: &msg,
-			})
-			return rsp
+			return nil, http.StatusBadRequest, msg
 		default:
 			// Other error.
 			msg := "Problem with applying Kea global parameters"
 			log.WithError(err).Error(msg)
-			rsp := dhcp.NewUpdateKeaGlobalParametersSubmitDefault(http.StatusInternalServerError).WithPayload(&models.APIError{
-				Message: &msg,
-			})
-			return rsp
+			return nil, http.StatusInternalServerError, msg
 		}
 	}
-	// Send the commands to Kea servers.
-	cctx, err = r.ConfigManager.Commit(cctx)
+	return cctx, 0, ""
+}
+
+// Implements the POST call and commits updated global Kea configurations
+// (kea-global-parameters/transaction/{id}/submit).
+func (r *RestAPI) UpdateKeaGlobalParametersSubmit(ctx context.Context, params dhcp.UpdateKeaGlobalParametersSubmitParams) middleware.Responder {
+	if code, msg := validateConfigChangeDeadline(params.ScheduledAt); code != 0 {
+		rsp := dhcp.NewUpdateKeaGlobalParametersSubmitDefault(code).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	cctx, code, msg := r.commonUpdateKeaGlobalParametersApply(ctx, params.ID, params.Request)
+	if code != 0 {
+		rsp := dhcp.NewUpdateKeaGlobalParametersSubmitDefault(code).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	// Send the commands to Kea servers or schedule sending them.
+	cctx, err := r.commitOrScheduleConfigChanges(cctx, params.ScheduledAt)
 	if err != nil {
 		msg := fmt.Sprintf("Problem with committing Kea config: %s", err)
 		log.WithError(err).Error(msg)
@@ -791,6 +869,25 @@ func (r *RestAPI) UpdateKeaGlobalParametersSubmit(ctx context.Context, params dh
 	return rsp
 }
 
+// Implements the POST call to preview the changes of the updated global Kea
+// configurations (kea-global-parameters/transaction/{id}/preview).
+func (r *RestAPI) UpdateKeaGlobalParametersPreview(ctx context.Context, params dhcp.UpdateKeaGlobalParametersPreviewParams) middleware.Responder {
+	cctx, code, msg := r.commonUpdateKeaGlobalParametersApply(ctx, params.ID, params.Request)
+	var preview *models.ConfigChangesPreview
+	if code == 0 {
+		preview, code, msg = r.previewConfigChanges(ctx, cctx)
+	}
+	if code != 0 {
+		// Error case.
+		rsp := dhcp.NewUpdateKeaGlobalParametersPreviewDefault(code).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	rsp := dhcp.NewUpdateKeaGlobalParametersPreviewOK().WithPayload(preview)
+	return rsp
+}
+
 // Implements the DELETE call to cancel updating Kea global parameters (kea-global-parameters/transaction/{id}).
 // It removes the specified transaction from the config manager, if the transaction exists.
 func (r *RestAPI) UpdateKeaGlobalParametersDelete(ctx context.Context, params dhcp.UpdateKeaGlobalParametersDeleteParams) middleware.Responder {
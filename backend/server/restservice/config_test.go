@@ -2,6 +2,7 @@ package restservice
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"testing"
@@ -1009,8 +1010,57 @@ func TestPutDaemonConfigReviewDatabaseError(t *testing.T) {
 }
 
 // Test that HTTP Bad Request status is returned as a result of requesting
-// a configuration review for a non-Kea daemon.
-func TestPutDaemonConfigReviewNotKeaDaemon(t *testing.T) {
+// a configuration review for a daemon which is neither Kea nor BIND 9 daemon.
+func TestPutDaemonConfigReviewUnsupportedDaemon(t *testing.T) {
+	db, dbSettings, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	machine := &dbmodel.Machine{
+		Address:   "localhost",
+		AgentPort: 8080,
+	}
+	err := dbmodel.AddMachine(db, machine)
+	require.NoError(t, err)
+
+	// Create an app instance with a daemon lacking the type-specific details.
+	var bind9Points []*dbmodel.AccessPoint
+	bind9Points = dbmodel.AppendAccessPoint(bind9Points, dbmodel.AccessPointControl, "1.2.3.4", "abcd", 124, true)
+	app := &dbmodel.App{
+		MachineID:    machine.ID,
+		Machine:      machine,
+		Type:         dbmodel.AppTypeBind9,
+		AccessPoints: bind9Points,
+		Daemons: []*dbmodel.Daemon{
+			{
+				Name: dbmodel.DaemonNameBind9,
+			},
+		},
+	}
+	daemons, err := dbmodel.AddApp(db, app)
+	require.NoError(t, err)
+
+	fa := agentcommtest.NewFakeAgents(nil, nil)
+	fd := &storktest.FakeDispatcher{}
+	rapi, err := NewRestAPI(dbSettings, db, fa, fd)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	params := services.PutDaemonConfigReviewParams{
+		ID: daemons[0].ID,
+	}
+	rsp := rapi.PutDaemonConfigReview(ctx, params)
+	require.IsType(t, &services.PutDaemonConfigReviewDefault{}, rsp)
+	defaultRsp := rsp.(*services.PutDaemonConfigReviewDefault)
+	require.NotNil(t, defaultRsp)
+	require.Equal(t, http.StatusBadRequest, getStatusCode(*defaultRsp))
+	require.Equal(t, fmt.Sprintf("Daemon with ID %d is not a Kea or BIND 9 daemon", daemons[0].ID),
+		*defaultRsp.Payload.Message)
+}
+
+// Test that the BIND 9 daemon configuration review is started on demand
+// when the configuration is present, and that HTTP Bad Request status is
+// returned when the configuration is not present.
+func TestPutDaemonConfigReviewBind9Daemon(t *testing.T) {
 	db, dbSettings, teardown := dbtest.SetupDatabaseTestCase(t)
 	defer teardown()
 
@@ -1031,6 +1081,7 @@ func TestPutDaemonConfigReviewNotKeaDaemon(t *testing.T) {
 		AccessPoints: bind9Points,
 		Daemons: []*dbmodel.Daemon{
 			{
+				Name:        dbmodel.DaemonNameBind9,
 				Bind9Daemon: &dbmodel.Bind9Daemon{},
 			},
 		},
@@ -1044,6 +1095,7 @@ func TestPutDaemonConfigReviewNotKeaDaemon(t *testing.T) {
 	require.NoError(t, err)
 	ctx := context.Background()
 
+	// No configuration.
 	params := services.PutDaemonConfigReviewParams{
 		ID: daemons[0].ID,
 	}
@@ -1052,8 +1104,19 @@ func TestPutDaemonConfigReviewNotKeaDaemon(t *testing.T) {
 	defaultRsp := rsp.(*services.PutDaemonConfigReviewDefault)
 	require.NotNil(t, defaultRsp)
 	require.Equal(t, http.StatusBadRequest, getStatusCode(*defaultRsp))
-	require.Equal(t, fmt.Sprintf("Daemon with ID %d is not a Kea daemon", daemons[0].ID),
+	require.Equal(t, fmt.Sprintf("Configuration not found for daemon with ID %d", daemons[0].ID),
 		*defaultRsp.Payload.Message)
+	require.Empty(t, fd.CallLog)
+
+	// Set the configuration.
+	daemons[0].Bind9Daemon.Config = "options { recursion no; };"
+	err = dbmodel.UpdateDaemon(db, daemons[0])
+	require.NoError(t, err)
+
+	rsp = rapi.PutDaemonConfigReview(ctx, params)
+	require.IsType(t, &services.PutDaemonConfigReviewAccepted{}, rsp)
+	require.Len(t, fd.CallLog, 1)
+	require.Equal(t, "BeginReview", fd.CallLog[0].CallName)
 }
 
 // Test that HTTP Bad Request status is returned as a result of requesting
@@ -1551,6 +1614,44 @@ func TestPutDaemonConfigCheckerPreferencesForMissingDaemon(t *testing.T) {
 	require.Empty(t, preferences)
 }
 
+// Test writing the Kea daemon configuration to the configuration file.
+func TestWriteDaemonConfig(t *testing.T) {
+	db, dbSettings, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	daemons := addTestClientClassServers(t, db, `{
+		"Dhcp4": {
+			"valid-lifetime": 1000
+		}
+	}`)
+
+	rapi, fa, ctx := newTestClientClassRestAPI(t, db, dbSettings)
+	eventCenter := &storktest.FakeEventCenter{}
+	rapi.EventCenter = eventCenter
+
+	rsp := rapi.WriteDaemonConfig(ctx, services.WriteDaemonConfigParams{
+		ID: daemons[0].ID,
+	})
+	require.IsType(t, &services.WriteDaemonConfigOK{}, rsp)
+
+	require.Len(t, fa.RecordedCommands, 1)
+	require.JSONEq(t, `{
+		"command": "config-write",
+		"service": ["dhcp4"]
+	}`, fa.RecordedCommands[0].Marshal())
+
+	// The event should be recorded.
+	require.Len(t, eventCenter.Events, 1)
+	require.Contains(t, eventCenter.Events[0].Text, "wrote configuration")
+
+	// Non-existing daemon.
+	rsp = rapi.WriteDaemonConfig(ctx, services.WriteDaemonConfigParams{
+		ID: daemons[0].ID + 100,
+	})
+	require.IsType(t, &services.WriteDaemonConfigDefault{}, rsp)
+	require.Equal(t, http.StatusNotFound, getStatusCode(*rsp.(*services.WriteDaemonConfigDefault)))
+}
+
 // Test resetting Kea daemons' config hashes.
 func TestDeleteKeaConfigHashes(t *testing.T) {
 	db, dbSettings, teardown := dbtest.SetupDatabaseTestCase(t)
@@ -2225,6 +2326,108 @@ func TestUpdateGlobalParametersBeginNoDaemon(t *testing.T) {
 	require.Equal(t, http.StatusBadRequest, getStatusCode(*defaultRsp))
 }
 
+// Test that the preview of the global parameters update hides the
+// sensitive data from the users who are not super-admins.
+func TestUpdateGlobalParametersPreviewHideSensitiveData(t *testing.T) {
+	db, dbSettings, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	server, err := dbmodeltest.NewKeaDHCPv4Server(db)
+	require.NoError(t, err)
+	err = server.Configure(`{
+		"Dhcp4": {
+			"valid-lifetime": 2222,
+			"lease-database": {
+				"type": "postgresql",
+				"user": "kea",
+				"password": "secret-password"
+			}
+		}
+	}`)
+	require.NoError(t, err)
+
+	app, err := server.GetKea()
+	require.NoError(t, err)
+
+	err = kea.CommitAppIntoDB(db, app, &storktest.FakeEventCenter{}, nil, dbmodel.NewDHCPOptionDefinitionLookup())
+	require.NoError(t, err)
+
+	daemonID := app.Daemons[0].GetID()
+
+	fa := agentcommtest.NewFakeAgents(nil, nil)
+	lookup := dbmodel.NewDHCPOptionDefinitionLookup()
+
+	cm := apps.NewManager(&appstest.ManagerAccessorsWrapper{
+		DB:        db,
+		Agents:    fa,
+		DefLookup: lookup,
+	})
+	require.NotNil(t, cm)
+
+	rapi, err := NewRestAPI(dbSettings, db, fa, cm, lookup)
+	require.NoError(t, err)
+
+	ctx, err := rapi.SessionManager.Load(context.Background(), "")
+	require.NoError(t, err)
+
+	// The user is not a super-admin.
+	user := &dbmodel.SystemUser{
+		ID: 1234,
+	}
+	err = rapi.SessionManager.LoginHandler(ctx, user)
+	require.NoError(t, err)
+
+	rsp := rapi.UpdateKeaGlobalParametersBegin(ctx, dhcp.UpdateKeaGlobalParametersBeginParams{
+		Request: &models.UpdateKeaDaemonsGlobalParametersBeginRequest{
+			DaemonIds: []int64{daemonID},
+		},
+	})
+	require.IsType(t, &dhcp.UpdateKeaGlobalParametersBeginOK{}, rsp)
+	transactionID := rsp.(*dhcp.UpdateKeaGlobalParametersBeginOK).Payload.ID
+
+	previewParams := dhcp.UpdateKeaGlobalParametersPreviewParams{
+		ID: transactionID,
+		Request: &models.UpdateKeaDaemonsGlobalParametersSubmitRequest{
+			Configs: []*models.KeaDaemonConfigurableGlobalParameters{
+				{
+					DaemonID:   daemonID,
+					DaemonName: dbmodel.DaemonNameDHCPv4,
+					PartialConfig: &models.KeaConfigurableGlobalParameters{
+						KeaConfigValidLifetimeParameters: models.KeaConfigValidLifetimeParameters{
+							ValidLifetime: storkutil.Ptr(int64(1111)),
+						},
+					},
+				},
+			},
+		},
+	}
+	rsp = rapi.UpdateKeaGlobalParametersPreview(ctx, previewParams)
+	require.IsType(t, &dhcp.UpdateKeaGlobalParametersPreviewOK{}, rsp)
+	preview := rsp.(*dhcp.UpdateKeaGlobalParametersPreviewOK).Payload
+	require.Len(t, preview.Items, 1)
+	require.NotEmpty(t, preview.Items[0].Commands)
+
+	// The password must not be included in the commands or the changes.
+	serialized, err := json.Marshal(preview)
+	require.NoError(t, err)
+	require.NotContains(t, string(serialized), "secret-password")
+	require.Contains(t, string(serialized), "lease-database")
+
+	// The super-admin can see the password.
+	user.Groups = []*dbmodel.SystemGroup{{ID: dbmodel.SuperAdminGroupID}}
+	err = rapi.SessionManager.LoginHandler(ctx, user)
+	require.NoError(t, err)
+
+	rsp = rapi.UpdateKeaGlobalParametersPreview(ctx, previewParams)
+	require.IsType(t, &dhcp.UpdateKeaGlobalParametersPreviewOK{}, rsp)
+	serialized, err = json.Marshal(rsp.(*dhcp.UpdateKeaGlobalParametersPreviewOK).Payload)
+	require.NoError(t, err)
+	require.Contains(t, string(serialized), "secret-password")
+
+	// Nothing should have been sent to the servers.
+	require.Empty(t, fa.RecordedCommands)
+}
+
 // Test error cases for submitting global parameters update.
 func TestUpdateGlobalParametersSubmitError(t *testing.T) {
 	db, dbSettings, teardown := dbtest.SetupDatabaseTestCase(t)
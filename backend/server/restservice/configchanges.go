@@ -0,0 +1,256 @@
+package restservice
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-openapi/runtime/middleware"
+	"github.com/go-openapi/strfmt"
+	pkgerrors "github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	keaconfig "isc.org/stork/appcfg/kea"
+	dbmodel "isc.org/stork/server/database/model"
+	"isc.org/stork/server/gen/models"
+	"isc.org/stork/server/gen/restapi/operations/services"
+	storkutil "isc.org/stork/util"
+)
+
+// Checks if the time specified for committing the configuration changes
+// is in the future. The nil deadline is valid and means that the changes
+// should be committed immediately. It returns the HTTP error code if the
+// deadline is invalid or 0 when it is valid. In addition, it returns an
+// error string to be included in the HTTP response or an empty string if
+// the deadline is valid.
+func validateConfigChangeDeadline(deadline *strfmt.DateTime) (int, string) {
+	if deadline == nil {
+		return 0, ""
+	}
+	if !time.Time(*deadline).After(storkutil.UTCNow()) {
+		return http.StatusBadRequest, "Time when the configuration changes should be committed must be in the future"
+	}
+	return 0, ""
+}
+
+// Commits the configuration changes queued in the context or, when the
+// scheduledAt time is specified, stores them in the database to commit
+// them when this time passes.
+func (r *RestAPI) commitOrScheduleConfigChanges(cctx context.Context, scheduledAt *strfmt.DateTime) (context.Context, error) {
+	if scheduledAt == nil {
+		return r.ConfigManager.Commit(cctx)
+	}
+	return r.ConfigManager.Schedule(cctx, time.Time(*scheduledAt).UTC())
+}
+
+// Converts the scheduled config change to the REST API format. The
+// sensitive data are removed from the update recipes when the
+// hideSensitiveData flag is set.
+func convertScheduledConfigChangeToRestAPI(change *dbmodel.ScheduledConfigChange, hideSensitiveData bool) *models.ScheduledConfigChange {
+	restChange := &models.ScheduledConfigChange{
+		ID:         change.ID,
+		CreatedAt:  strfmt.DateTime(change.CreatedAt),
+		DeadlineAt: strfmt.DateTime(change.DeadlineAt),
+		UserID:     change.UserID,
+		Status:     string(change.GetStatus()),
+		Error:      change.Error,
+		Updates:    []*models.ScheduledConfigUpdate{},
+	}
+	if change.User != nil {
+		restChange.UserLogin = change.User.Login
+	}
+	for _, update := range change.Updates {
+		restUpdate := &models.ScheduledConfigUpdate{
+			Target:    string(update.Target),
+			Operation: update.Operation,
+			DaemonIds: update.DaemonIDs,
+		}
+		if update.Recipe != nil {
+			var recipe map[string]any
+			if err := json.Unmarshal(*update.Recipe, &recipe); err != nil {
+				log.WithError(err).Warnf("Problem parsing the recipe of the scheduled config change %d", change.ID)
+			} else {
+				if hideSensitiveData {
+					(&keaconfig.Config{Raw: recipe}).HideSensitiveData()
+				}
+				restUpdate.Recipe = recipe
+			}
+		}
+		restChange.Updates = append(restChange.Updates, restUpdate)
+	}
+	return restChange
+}
+
+// Checks if the sensitive data should be hidden from the logged user.
+// They are only visible to the super-admin users.
+func (r *RestAPI) isSensitiveDataHidden(ctx context.Context) bool {
+	_, dbUser := r.SessionManager.Logged(ctx)
+	return dbUser == nil || !dbUser.InGroup(&dbmodel.SystemGroup{ID: dbmodel.SuperAdminGroupID})
+}
+
+// Returns the scheduled config changes. The start and limit values are
+// optional. They are used to retrieve paged changes. The status value is
+// optional and it filters the changes by their status.
+func (r *RestAPI) GetScheduledConfigChanges(ctx context.Context, params services.GetScheduledConfigChangesParams) middleware.Responder {
+	start := int64(0)
+	if params.Start != nil {
+		start = *params.Start
+	}
+
+	limit := int64(0)
+	if params.Limit != nil {
+		limit = *params.Limit
+	}
+
+	var status *dbmodel.ScheduledConfigChangeStatus
+	if params.Status != nil {
+		status = storkutil.Ptr(dbmodel.ScheduledConfigChangeStatus(*params.Status))
+	}
+
+	dbChanges, total, err := dbmodel.GetScheduledConfigChangesByPage(r.DB, start, limit, status)
+	if err != nil {
+		msg := "Cannot get scheduled config changes from db"
+		log.WithError(err).Error(msg)
+		rsp := services.NewGetScheduledConfigChangesDefault(http.StatusInternalServerError).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+
+	hideSensitiveData := r.isSensitiveDataHidden(ctx)
+	changes := &models.ScheduledConfigChanges{
+		Items: []*models.ScheduledConfigChange{},
+		Total: total,
+	}
+	for i := range dbChanges {
+		changes.Items = append(changes.Items, convertScheduledConfigChangeToRestAPI(&dbChanges[i], hideSensitiveData))
+	}
+	rsp := services.NewGetScheduledConfigChangesOK().WithPayload(changes)
+	return rsp
+}
+
+// Returns the scheduled config change by ID.
+func (r *RestAPI) GetScheduledConfigChange(ctx context.Context, params services.GetScheduledConfigChangeParams) middleware.Responder {
+	dbChange, err := dbmodel.GetScheduledConfigChangeByID(r.DB, params.ID)
+	if err != nil {
+		msg := fmt.Sprintf("Cannot get scheduled config change with ID %d from db", params.ID)
+		log.WithError(err).Error(msg)
+		rsp := services.NewGetScheduledConfigChangeDefault(http.StatusInternalServerError).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	if dbChange == nil {
+		msg := fmt.Sprintf("Cannot find scheduled config change with ID %d", params.ID)
+		rsp := services.NewGetScheduledConfigChangeDefault(http.StatusNotFound).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	rsp := services.NewGetScheduledConfigChangeOK().WithPayload(convertScheduledConfigChangeToRestAPI(dbChange, r.isSensitiveDataHidden(ctx)))
+	return rsp
+}
+
+// Fetches the pending scheduled config change from the database. It returns
+// the HTTP error code if an error occurs, the change does not exist or it
+// has already been executed. Otherwise, it returns 0. In addition it returns
+// an error string to be included in the HTTP response or an empty string if
+// there is no error.
+func (r *RestAPI) getPendingScheduledConfigChange(changeID int64) (*dbmodel.ScheduledConfigChange, int, string) {
+	dbChange, err := dbmodel.GetScheduledConfigChangeByID(r.DB, changeID)
+	if err != nil {
+		msg := fmt.Sprintf("Cannot get scheduled config change with ID %d from db", changeID)
+		log.WithError(err).Error(msg)
+		return nil, http.StatusInternalServerError, msg
+	}
+	if dbChange == nil {
+		msg := fmt.Sprintf("Cannot find scheduled config change with ID %d", changeID)
+		return nil, http.StatusNotFound, msg
+	}
+	if dbChange.Executed {
+		msg := fmt.Sprintf("Scheduled config change with ID %d has already been executed", changeID)
+		return nil, http.StatusConflict, msg
+	}
+	return dbChange, 0, ""
+}
+
+// Cancels the pending scheduled config change. The change is removed from
+// the database.
+func (r *RestAPI) CancelScheduledConfigChange(ctx context.Context, params services.CancelScheduledConfigChangeParams) middleware.Responder {
+	dbChange, code, msg := r.getPendingScheduledConfigChange(params.ID)
+	if code != 0 {
+		rsp := services.NewCancelScheduledConfigChangeDefault(code).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	if err := dbmodel.CancelScheduledConfigChange(r.DB, dbChange.ID); err != nil {
+		code := http.StatusInternalServerError
+		msg := fmt.Sprintf("Problem with canceling scheduled config change with ID %d", params.ID)
+		if errors.Is(pkgerrors.Cause(err), dbmodel.ErrNotExists) {
+			// The change has been executed or canceled in the meantime.
+			code = http.StatusConflict
+			msg = fmt.Sprintf("Scheduled config change with ID %d is no longer pending", params.ID)
+		}
+		log.WithError(err).Error(msg)
+		rsp := services.NewCancelScheduledConfigChangeDefault(code).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	_, dbUser := r.SessionManager.Logged(ctx)
+	r.EventCenter.AddInfoEvent(fmt.Sprintf("{user} canceled scheduled config change %d", dbChange.ID), dbUser)
+
+	rsp := services.NewCancelScheduledConfigChangeOK()
+	return rsp
+}
+
+// Sets new deadline for the pending scheduled config change.
+func (r *RestAPI) RescheduleConfigChange(ctx context.Context, params services.RescheduleConfigChangeParams) middleware.Responder {
+	if params.Deadline == nil || params.Deadline.DeadlineAt == nil {
+		msg := "Missing new deadline of the scheduled config change"
+		log.Error(msg)
+		rsp := services.NewRescheduleConfigChangeDefault(http.StatusBadRequest).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	if code, msg := validateConfigChangeDeadline(params.Deadline.DeadlineAt); code != 0 {
+		rsp := services.NewRescheduleConfigChangeDefault(code).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	dbChange, code, msg := r.getPendingScheduledConfigChange(params.ID)
+	if code != 0 {
+		rsp := services.NewRescheduleConfigChangeDefault(code).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	deadline := time.Time(*params.Deadline.DeadlineAt).UTC()
+	if err := dbmodel.RescheduleConfigChange(r.DB, dbChange.ID, deadline); err != nil {
+		code := http.StatusInternalServerError
+		msg := fmt.Sprintf("Problem with rescheduling config change with ID %d", params.ID)
+		if errors.Is(pkgerrors.Cause(err), dbmodel.ErrNotExists) {
+			// The change has been executed or canceled in the meantime.
+			code = http.StatusConflict
+			msg = fmt.Sprintf("Scheduled config change with ID %d is no longer pending", params.ID)
+		}
+		log.WithError(err).Error(msg)
+		rsp := services.NewRescheduleConfigChangeDefault(code).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	dbChange.DeadlineAt = deadline
+
+	_, dbUser := r.SessionManager.Logged(ctx)
+	r.EventCenter.AddInfoEvent(fmt.Sprintf("{user} rescheduled config change %d to %s", dbChange.ID, deadline.Format(time.RFC3339)), dbUser)
+
+	rsp := services.NewRescheduleConfigChangeOK().WithPayload(convertScheduledConfigChangeToRestAPI(dbChange, r.isSensitiveDataHidden(ctx)))
+	return rsp
+}
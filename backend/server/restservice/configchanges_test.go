@@ -0,0 +1,311 @@
+package restservice
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/stretchr/testify/require"
+	agentcommtest "isc.org/stork/server/agentcomm/test"
+	"isc.org/stork/server/apps"
+	appstest "isc.org/stork/server/apps/test"
+	dbmodel "isc.org/stork/server/database/model"
+	dbtest "isc.org/stork/server/database/test"
+	"isc.org/stork/server/gen/models"
+	dhcp "isc.org/stork/server/gen/restapi/operations/d_h_c_p"
+	"isc.org/stork/server/gen/restapi/operations/services"
+	storktest "isc.org/stork/server/test/dbmodel"
+	storkutil "isc.org/stork/util"
+)
+
+// Test validating the time when the configuration changes should be
+// committed.
+func TestValidateConfigChangeDeadline(t *testing.T) {
+	code, msg := validateConfigChangeDeadline(nil)
+	require.Zero(t, code)
+	require.Empty(t, msg)
+
+	code, msg = validateConfigChangeDeadline(storkutil.Ptr(strfmt.DateTime(storkutil.UTCNow().Add(time.Hour))))
+	require.Zero(t, code)
+	require.Empty(t, msg)
+
+	code, msg = validateConfigChangeDeadline(storkutil.Ptr(strfmt.DateTime(storkutil.UTCNow().Add(-time.Hour))))
+	require.Equal(t, http.StatusBadRequest, code)
+	require.Contains(t, msg, "must be in the future")
+}
+
+// Test converting the scheduled config change to the REST API format.
+func TestConvertScheduledConfigChangeToRestAPI(t *testing.T) {
+	recipe := json.RawMessage(`{
+		"commands": [
+			{
+				"command": "config-set",
+				"arguments": {
+					"Dhcp4": {
+						"hosts-database": {
+							"user": "kea",
+							"password": "secret"
+						}
+					}
+				}
+			}
+		]
+	}`)
+	change := &dbmodel.ScheduledConfigChange{
+		ID:         1,
+		CreatedAt:  time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC),
+		DeadlineAt: time.Date(2024, 1, 2, 10, 0, 0, 0, time.UTC),
+		UserID:     2,
+		User: &dbmodel.SystemUser{
+			Login: "admin",
+		},
+		Updates: []*dbmodel.ConfigUpdate{
+			{
+				Target:    dbmodel.AppTypeKea,
+				Operation: "global_parameters_update",
+				DaemonIDs: []int64{3, 4},
+				Recipe:    &recipe,
+			},
+		},
+		Executed: true,
+		Error:    "failed",
+	}
+	restChange := convertScheduledConfigChangeToRestAPI(change, false)
+	require.NotNil(t, restChange)
+	require.EqualValues(t, 1, restChange.ID)
+	require.Equal(t, "2024-01-01T10:00:00.000Z", restChange.CreatedAt.String())
+	require.Equal(t, "2024-01-02T10:00:00.000Z", restChange.DeadlineAt.String())
+	require.EqualValues(t, 2, restChange.UserID)
+	require.Equal(t, "admin", restChange.UserLogin)
+	require.Equal(t, "failed", restChange.Status)
+	require.Equal(t, "failed", restChange.Error)
+	require.Len(t, restChange.Updates, 1)
+	require.Equal(t, "kea", restChange.Updates[0].Target)
+	require.Equal(t, "global_parameters_update", restChange.Updates[0].Operation)
+	require.Equal(t, []int64{3, 4}, restChange.Updates[0].DaemonIds)
+	marshalled, err := json.Marshal(restChange.Updates[0].Recipe)
+	require.NoError(t, err)
+	require.Contains(t, string(marshalled), `"password":"secret"`)
+
+	// Hide the sensitive data.
+	restChange = convertScheduledConfigChangeToRestAPI(change, true)
+	marshalled, err = json.Marshal(restChange.Updates[0].Recipe)
+	require.NoError(t, err)
+	require.Contains(t, string(marshalled), `"password":null`)
+	require.Contains(t, string(marshalled), `"user":"kea"`)
+}
+
+// Test scheduling a config change upon the transaction submission,
+// listing, rescheduling and canceling the scheduled changes.
+func TestScheduleConfigChange(t *testing.T) {
+	db, dbSettings, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	daemons := addTestClientClassServers(t, db, `{
+		"Dhcp4": {
+			"subnet4": [
+				{
+					"id": 1,
+					"subnet": "192.0.2.0/24"
+				}
+			],
+			"hooks-libraries": [
+				{
+					"library": "/usr/lib/kea/libdhcp_host_cmds.so"
+				}
+			]
+		}
+	}`)
+
+	fa := agentcommtest.NewFakeAgents(nil, nil)
+	lookup := dbmodel.NewDHCPOptionDefinitionLookup()
+	cm := apps.NewManager(&appstest.ManagerAccessorsWrapper{
+		DB:        db,
+		Agents:    fa,
+		DefLookup: lookup,
+	})
+	eventCenter := &storktest.FakeEventCenter{}
+	rapi, err := NewRestAPI(dbSettings, db, fa, cm, lookup, eventCenter)
+	require.NoError(t, err)
+
+	ctx, err := rapi.SessionManager.Load(context.Background(), "")
+	require.NoError(t, err)
+
+	// The scheduled config changes must be associated with an existing
+	// user. Use the default admin.
+	user, err := dbmodel.GetUserByID(db, 1)
+	require.NoError(t, err)
+	require.NotNil(t, user)
+	err = rapi.SessionManager.LoginHandler(ctx, user)
+	require.NoError(t, err)
+
+	subnets, err := dbmodel.GetSubnetsByPrefix(db, "192.0.2.0/24")
+	require.NoError(t, err)
+	require.Len(t, subnets, 1)
+
+	// Begin the transaction.
+	rsp := rapi.CreateHostBegin(ctx, dhcp.CreateHostBeginParams{})
+	require.IsType(t, &dhcp.CreateHostBeginOK{}, rsp)
+	transactionID := rsp.(*dhcp.CreateHostBeginOK).Payload.ID
+
+	host := &models.Host{
+		SubnetID: subnets[0].ID,
+		HostIdentifiers: []*models.HostIdentifier{
+			{
+				IDType:     "hw-address",
+				IDHexValue: "010203040506",
+			},
+		},
+		LocalHosts: []*models.LocalHost{
+			{
+				DaemonID:   daemons[0].ID,
+				DataSource: dbmodel.HostDataSourceAPI.String(),
+			},
+		},
+	}
+
+	// The deadline in the past is rejected.
+	rsp = rapi.CreateHostSubmit(ctx, dhcp.CreateHostSubmitParams{
+		ID:          transactionID,
+		Host:        host,
+		ScheduledAt: storkutil.Ptr(strfmt.DateTime(storkutil.UTCNow().Add(-time.Hour))),
+	})
+	require.IsType(t, &dhcp.CreateHostSubmitDefault{}, rsp)
+	require.Equal(t, http.StatusBadRequest, getStatusCode(*rsp.(*dhcp.CreateHostSubmitDefault)))
+
+	// Schedule the change.
+	rsp = rapi.CreateHostSubmit(ctx, dhcp.CreateHostSubmitParams{
+		ID:          transactionID,
+		Host:        host,
+		ScheduledAt: storkutil.Ptr(strfmt.DateTime(storkutil.UTCNow().Add(time.Hour))),
+	})
+	require.IsType(t, &dhcp.CreateHostSubmitOK{}, rsp)
+
+	// No commands should be sent and no hosts should be added.
+	require.Empty(t, fa.RecordedCommands)
+	hosts, _, err := dbmodel.GetHostsByDaemonID(db, daemons[0].ID, dbmodel.HostDataSourceAPI)
+	require.NoError(t, err)
+	require.Empty(t, hosts)
+
+	// List the pending changes.
+	rsp = rapi.GetScheduledConfigChanges(ctx, services.GetScheduledConfigChangesParams{
+		Status: storkutil.Ptr("pending"),
+	})
+	require.IsType(t, &services.GetScheduledConfigChangesOK{}, rsp)
+	changes := rsp.(*services.GetScheduledConfigChangesOK).Payload
+	require.EqualValues(t, 1, changes.Total)
+	require.Len(t, changes.Items, 1)
+	change := changes.Items[0]
+	require.Equal(t, "pending", change.Status)
+	require.Equal(t, "admin", change.UserLogin)
+	require.Len(t, change.Updates, 1)
+	require.Equal(t, "host_add", change.Updates[0].Operation)
+	require.Equal(t, []int64{daemons[0].ID}, change.Updates[0].DaemonIds)
+	require.NotEmpty(t, change.Updates[0].Recipe)
+
+	// There are no executed changes.
+	rsp = rapi.GetScheduledConfigChanges(ctx, services.GetScheduledConfigChangesParams{
+		Status: storkutil.Ptr("executed"),
+	})
+	require.IsType(t, &services.GetScheduledConfigChangesOK{}, rsp)
+	require.Zero(t, rsp.(*services.GetScheduledConfigChangesOK).Payload.Total)
+
+	// Reschedule the change.
+	deadline := storkutil.UTCNow().Add(2 * time.Hour).Truncate(time.Second)
+	rsp = rapi.RescheduleConfigChange(ctx, services.RescheduleConfigChangeParams{
+		ID: change.ID,
+		Deadline: &models.ScheduledConfigChangeDeadline{
+			DeadlineAt: storkutil.Ptr(strfmt.DateTime(deadline)),
+		},
+	})
+	require.IsType(t, &services.RescheduleConfigChangeOK{}, rsp)
+	require.Equal(t, strfmt.DateTime(deadline).String(), rsp.(*services.RescheduleConfigChangeOK).Payload.DeadlineAt.String())
+
+	rsp = rapi.GetScheduledConfigChange(ctx, services.GetScheduledConfigChangeParams{
+		ID: change.ID,
+	})
+	require.IsType(t, &services.GetScheduledConfigChangeOK{}, rsp)
+	require.Equal(t, strfmt.DateTime(deadline).String(), rsp.(*services.GetScheduledConfigChangeOK).Payload.DeadlineAt.String())
+
+	// The deadline in the past is rejected.
+	rsp = rapi.RescheduleConfigChange(ctx, services.RescheduleConfigChangeParams{
+		ID: change.ID,
+		Deadline: &models.ScheduledConfigChangeDeadline{
+			DeadlineAt: storkutil.Ptr(strfmt.DateTime(storkutil.UTCNow().Add(-time.Hour))),
+		},
+	})
+	require.IsType(t, &services.RescheduleConfigChangeDefault{}, rsp)
+	require.Equal(t, http.StatusBadRequest, getStatusCode(*rsp.(*services.RescheduleConfigChangeDefault)))
+
+	// Cancel the change.
+	rsp = rapi.CancelScheduledConfigChange(ctx, services.CancelScheduledConfigChangeParams{
+		ID: change.ID,
+	})
+	require.IsType(t, &services.CancelScheduledConfigChangeOK{}, rsp)
+
+	rsp = rapi.GetScheduledConfigChange(ctx, services.GetScheduledConfigChangeParams{
+		ID: change.ID,
+	})
+	require.IsType(t, &services.GetScheduledConfigChangeDefault{}, rsp)
+	require.Equal(t, http.StatusNotFound, getStatusCode(*rsp.(*services.GetScheduledConfigChangeDefault)))
+
+	// The events should be issued for rescheduling and canceling.
+	require.Len(t, eventCenter.Events, 2)
+	require.Contains(t, eventCenter.Events[0].Text, "rescheduled config change")
+	require.Contains(t, eventCenter.Events[1].Text, "canceled scheduled config change")
+}
+
+// Test that the executed config changes can be neither canceled nor
+// rescheduled.
+func TestCancelRescheduleExecutedConfigChange(t *testing.T) {
+	db, dbSettings, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	rapi, _, ctx := newTestClientClassRestAPI(t, db, dbSettings)
+
+	change := &dbmodel.ScheduledConfigChange{
+		DeadlineAt: storkutil.UTCNow().Add(-time.Hour),
+		UserID:     1,
+		Updates: []*dbmodel.ConfigUpdate{
+			dbmodel.NewConfigUpdate(dbmodel.AppTypeKea, "host_add", 1),
+		},
+	}
+	err := dbmodel.AddScheduledConfigChange(db, change)
+	require.NoError(t, err)
+	err = dbmodel.SetScheduledConfigChangeExecuted(db, change.ID, "")
+	require.NoError(t, err)
+
+	rsp := rapi.CancelScheduledConfigChange(ctx, services.CancelScheduledConfigChangeParams{
+		ID: change.ID,
+	})
+	require.IsType(t, &services.CancelScheduledConfigChangeDefault{}, rsp)
+	require.Equal(t, http.StatusConflict, getStatusCode(*rsp.(*services.CancelScheduledConfigChangeDefault)))
+
+	rsp = rapi.RescheduleConfigChange(ctx, services.RescheduleConfigChangeParams{
+		ID: change.ID,
+		Deadline: &models.ScheduledConfigChangeDeadline{
+			DeadlineAt: storkutil.Ptr(strfmt.DateTime(storkutil.UTCNow().Add(time.Hour))),
+		},
+	})
+	require.IsType(t, &services.RescheduleConfigChangeDefault{}, rsp)
+	require.Equal(t, http.StatusConflict, getStatusCode(*rsp.(*services.RescheduleConfigChangeDefault)))
+
+	// Non-existing change.
+	rsp = rapi.CancelScheduledConfigChange(ctx, services.CancelScheduledConfigChangeParams{
+		ID: change.ID + 1,
+	})
+	require.IsType(t, &services.CancelScheduledConfigChangeDefault{}, rsp)
+	require.Equal(t, http.StatusNotFound, getStatusCode(*rsp.(*services.CancelScheduledConfigChangeDefault)))
+
+	// The executed change should be listed.
+	rsp = rapi.GetScheduledConfigChanges(ctx, services.GetScheduledConfigChangesParams{
+		Status: storkutil.Ptr("executed"),
+	})
+	require.IsType(t, &services.GetScheduledConfigChangesOK{}, rsp)
+	changes := rsp.(*services.GetScheduledConfigChangesOK).Payload
+	require.EqualValues(t, 1, changes.Total)
+	require.Equal(t, "executed", changes.Items[0].Status)
+}
@@ -0,0 +1,69 @@
+package restservice
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	log "github.com/sirupsen/logrus"
+	"isc.org/stork/server/config"
+	"isc.org/stork/server/gen/models"
+	storkutil "isc.org/stork/util"
+)
+
+// Converts the structural differences between the configurations to the
+// REST API format.
+func convertConfigChangesToRestAPI(changes []storkutil.JSONDiffEntry) []*models.ConfigChange {
+	restChanges := []*models.ConfigChange{}
+	for _, change := range changes {
+		restChanges = append(restChanges, &models.ConfigChange{
+			Path:   change.Path,
+			Op:     string(change.Operation),
+			Before: change.Before,
+			After:  change.After,
+		})
+	}
+	return restChanges
+}
+
+// Converts the preview of the configuration changes returned by the config
+// manager to the REST API format.
+func convertConfigChangesPreviewToRestAPI(previews []config.DaemonChangesPreview) *models.ConfigChangesPreview {
+	restPreview := &models.ConfigChangesPreview{
+		Items: []*models.DaemonConfigChangesPreview{},
+	}
+	for _, preview := range previews {
+		restDaemonPreview := &models.DaemonConfigChangesPreview{
+			DaemonID:   preview.DaemonID,
+			DaemonName: preview.DaemonName,
+			AppID:      preview.AppID,
+			AppName:    preview.AppName,
+			Commands:   []any{},
+			Changes:    convertConfigChangesToRestAPI(preview.Changes),
+		}
+		for _, command := range preview.Commands {
+			restDaemonPreview.Commands = append(restDaemonPreview.Commands, command)
+		}
+		restPreview.Items = append(restPreview.Items, restDaemonPreview)
+	}
+	return restPreview
+}
+
+// Returns the preview of the changes applied in the transaction context
+// (cctx). The transaction context must include the applied changes. This
+// function neither commits the changes nor cancels the transaction. The
+// request context (ctx) is used to check whether the logged user may see
+// the sensitive data. They are only included in the preview for the
+// super-admin users. The function returns the HTTP error code if an error
+// occurs or 0 when there is no error. In addition it returns an error
+// string to be included in the HTTP response or an empty string if there
+// is no error.
+func (r *RestAPI) previewConfigChanges(ctx, cctx context.Context) (*models.ConfigChangesPreview, int, string) {
+	previews, err := r.ConfigManager.GetKeaModule().Preview(cctx, r.isSensitiveDataHidden(ctx))
+	if err != nil {
+		msg := fmt.Sprintf("Problem with preparing the preview of the configuration changes: %s", err)
+		log.WithError(err).Error(msg)
+		return nil, http.StatusInternalServerError, msg
+	}
+	return convertConfigChangesPreviewToRestAPI(previews), 0, ""
+}
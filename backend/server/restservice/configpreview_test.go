@@ -0,0 +1,58 @@
+package restservice
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	keactrl "isc.org/stork/appctrl/kea"
+	"isc.org/stork/server/config"
+	storkutil "isc.org/stork/util"
+)
+
+// Test converting the preview of the configuration changes to the REST API
+// format.
+func TestConvertConfigChangesPreviewToRestAPI(t *testing.T) {
+	command := keactrl.NewCommandBase(keactrl.ConfigWrite, keactrl.DHCPv4)
+	previews := []config.DaemonChangesPreview{
+		{
+			DaemonID:   1,
+			DaemonName: "dhcp4",
+			AppID:      2,
+			AppName:    "kea@192.0.2.1",
+			Commands:   []*keactrl.Command{command},
+			Changes: []storkutil.JSONDiffEntry{
+				{
+					Path:      "/Dhcp4/valid-lifetime",
+					Operation: storkutil.JSONDiffOperationReplace,
+					Before:    1000,
+					After:     2000,
+				},
+			},
+		},
+		{
+			DaemonID:   3,
+			DaemonName: "dhcp6",
+		},
+	}
+	restPreview := convertConfigChangesPreviewToRestAPI(previews)
+	require.NotNil(t, restPreview)
+	require.Len(t, restPreview.Items, 2)
+
+	require.EqualValues(t, 1, restPreview.Items[0].DaemonID)
+	require.Equal(t, "dhcp4", restPreview.Items[0].DaemonName)
+	require.EqualValues(t, 2, restPreview.Items[0].AppID)
+	require.Equal(t, "kea@192.0.2.1", restPreview.Items[0].AppName)
+	require.Len(t, restPreview.Items[0].Commands, 1)
+	require.Equal(t, command, restPreview.Items[0].Commands[0])
+	require.Len(t, restPreview.Items[0].Changes, 1)
+	require.Equal(t, "/Dhcp4/valid-lifetime", restPreview.Items[0].Changes[0].Path)
+	require.Equal(t, "replace", restPreview.Items[0].Changes[0].Op)
+	require.Equal(t, 1000, restPreview.Items[0].Changes[0].Before)
+	require.Equal(t, 2000, restPreview.Items[0].Changes[0].After)
+
+	// Empty lists rather than nil should be returned.
+	require.NotNil(t, restPreview.Items[1].Commands)
+	require.Empty(t, restPreview.Items[1].Commands)
+	require.NotNil(t, restPreview.Items[1].Changes)
+	require.Empty(t, restPreview.Items[1].Changes)
+}
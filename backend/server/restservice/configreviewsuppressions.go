@@ -0,0 +1,192 @@
+package restservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-openapi/runtime/middleware"
+	"github.com/go-openapi/strfmt"
+	pkgerrors "github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	dbmodel "isc.org/stork/server/database/model"
+	"isc.org/stork/server/gen/models"
+	"isc.org/stork/server/gen/restapi/operations/services"
+	storkutil "isc.org/stork/util"
+)
+
+// Converts the config review suppression to the REST API format.
+func convertConfigReviewSuppressionToRestAPI(suppression *dbmodel.ConfigReviewSuppression) *models.ConfigReviewSuppression {
+	restSuppression := &models.ConfigReviewSuppression{
+		ID:          suppression.ID,
+		CreatedAt:   strfmt.DateTime(suppression.CreatedAt),
+		CheckerName: storkutil.Ptr(suppression.CheckerName),
+		DaemonID:    suppression.DaemonID,
+		ObjectType:  storkutil.Ptr(string(suppression.ObjectType)),
+		ObjectKey:   storkutil.Ptr(suppression.ObjectKey),
+		Reason:      storkutil.Ptr(suppression.Reason),
+		Expired:     storkutil.Ptr(suppression.IsExpired(storkutil.UTCNow())),
+	}
+	if suppression.ExpiresAt != nil {
+		restSuppression.ExpiresAt = storkutil.Ptr(strfmt.DateTime(*suppression.ExpiresAt))
+	}
+	if suppression.UserID != nil {
+		restSuppression.UserID = *suppression.UserID
+	}
+	if suppression.User != nil {
+		restSuppression.UserLogin = suppression.User.Login
+	}
+	return restSuppression
+}
+
+// Returns the config review suppressions. If the daemon ID is specified,
+// only the suppressions applying to this daemon are returned, i.e., the
+// suppressions for this daemon and for all daemons.
+func (r *RestAPI) GetConfigReviewSuppressions(ctx context.Context, params services.GetConfigReviewSuppressionsParams) middleware.Responder {
+	dbSuppressions, err := dbmodel.GetConfigReviewSuppressions(r.DB)
+	if err != nil {
+		msg := "Cannot get config review suppressions from db"
+		log.WithError(err).Error(msg)
+		rsp := services.NewGetConfigReviewSuppressionsDefault(http.StatusInternalServerError).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+
+	suppressions := &models.ConfigReviewSuppressions{
+		Items: []*models.ConfigReviewSuppression{},
+	}
+	for i := range dbSuppressions {
+		daemonID := dbSuppressions[i].DaemonID
+		if params.DaemonID != nil && daemonID != nil && *daemonID != *params.DaemonID {
+			continue
+		}
+		suppressions.Items = append(suppressions.Items, convertConfigReviewSuppressionToRestAPI(&dbSuppressions[i]))
+	}
+	suppressions.Total = int64(len(suppressions.Items))
+	rsp := services.NewGetConfigReviewSuppressionsOK().WithPayload(suppressions)
+	return rsp
+}
+
+// Validates the config review suppression received over the REST API and
+// converts it to the database model. It returns the HTTP error code if the
+// suppression is invalid or 0 when it is valid. In addition, it returns an
+// error string to be included in the HTTP response or an empty string if
+// the suppression is valid.
+func (r *RestAPI) validateConfigReviewSuppression(restSuppression *models.ConfigReviewSuppression) (*dbmodel.ConfigReviewSuppression, int, string) {
+	if restSuppression == nil || restSuppression.CheckerName == nil || restSuppression.ObjectType == nil ||
+		restSuppression.ObjectKey == nil || restSuppression.Reason == nil {
+		return nil, http.StatusBadRequest, "Missing config review suppression parameters"
+	}
+	if *restSuppression.Reason == "" {
+		return nil, http.StatusBadRequest, "Reason for suppressing the config review findings must not be empty"
+	}
+
+	metadata, err := r.ReviewDispatcher.GetCheckersMetadata(nil)
+	if err != nil {
+		msg := "Cannot get the config checkers metadata"
+		log.WithError(err).Error(msg)
+		return nil, http.StatusInternalServerError, msg
+	}
+	found := false
+	for _, checker := range metadata {
+		if checker.Name == *restSuppression.CheckerName {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return nil, http.StatusBadRequest, fmt.Sprintf("Config checker %s does not exist", *restSuppression.CheckerName)
+	}
+
+	if restSuppression.DaemonID != nil {
+		daemon, err := dbmodel.GetDaemonByID(r.DB, *restSuppression.DaemonID)
+		if err != nil {
+			msg := fmt.Sprintf("Cannot get daemon with ID %d from db", *restSuppression.DaemonID)
+			log.WithError(err).Error(msg)
+			return nil, http.StatusInternalServerError, msg
+		}
+		if daemon == nil {
+			return nil, http.StatusBadRequest, fmt.Sprintf("Cannot find daemon with ID %d", *restSuppression.DaemonID)
+		}
+	}
+
+	objectType := dbmodel.ConfigReviewSuppressionObjectType(*restSuppression.ObjectType)
+	if err := dbmodel.ValidateConfigReviewSuppressionObject(objectType, *restSuppression.ObjectKey); err != nil {
+		return nil, http.StatusBadRequest, fmt.Sprintf("Cannot suppress config review findings: %s", err)
+	}
+
+	suppression := &dbmodel.ConfigReviewSuppression{
+		CheckerName: *restSuppression.CheckerName,
+		DaemonID:    restSuppression.DaemonID,
+		ObjectType:  objectType,
+		ObjectKey:   *restSuppression.ObjectKey,
+		Reason:      *restSuppression.Reason,
+	}
+	if restSuppression.ExpiresAt != nil {
+		expiresAt := time.Time(*restSuppression.ExpiresAt).UTC()
+		if !expiresAt.After(storkutil.UTCNow()) {
+			return nil, http.StatusBadRequest, "Time when the config review suppression expires must be in the future"
+		}
+		suppression.ExpiresAt = &expiresAt
+	}
+	return suppression, 0, ""
+}
+
+// Suppresses the config review findings of a checker related to a subnet or
+// a host. The suppression is honored in the next config reviews.
+func (r *RestAPI) CreateConfigReviewSuppression(ctx context.Context, params services.CreateConfigReviewSuppressionParams) middleware.Responder {
+	suppression, code, msg := r.validateConfigReviewSuppression(params.Suppression)
+	if code != 0 {
+		rsp := services.NewCreateConfigReviewSuppressionDefault(code).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+
+	_, dbUser := r.SessionManager.Logged(ctx)
+	if dbUser != nil {
+		suppression.UserID = storkutil.Ptr(int64(dbUser.ID))
+		suppression.User = dbUser
+	}
+	if err := dbmodel.AddConfigReviewSuppression(r.DB, suppression); err != nil {
+		msg := "Problem with adding config review suppression to db"
+		log.WithError(err).Error(msg)
+		rsp := services.NewCreateConfigReviewSuppressionDefault(http.StatusInternalServerError).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+
+	r.EventCenter.AddInfoEvent(fmt.Sprintf("{user} suppressed findings of the %s config checker for %s %s",
+		suppression.CheckerName, suppression.ObjectType, suppression.ObjectKey), dbUser)
+
+	rsp := services.NewCreateConfigReviewSuppressionOK().WithPayload(convertConfigReviewSuppressionToRestAPI(suppression))
+	return rsp
+}
+
+// Deletes the config review suppression. The findings are reported again
+// in the next config reviews.
+func (r *RestAPI) DeleteConfigReviewSuppression(ctx context.Context, params services.DeleteConfigReviewSuppressionParams) middleware.Responder {
+	if err := dbmodel.DeleteConfigReviewSuppression(r.DB, params.ID); err != nil {
+		code := http.StatusInternalServerError
+		msg := fmt.Sprintf("Problem with deleting config review suppression with ID %d", params.ID)
+		if errors.Is(pkgerrors.Cause(err), dbmodel.ErrNotExists) {
+			code = http.StatusNotFound
+			msg = fmt.Sprintf("Cannot find config review suppression with ID %d", params.ID)
+		}
+		log.WithError(err).Error(msg)
+		rsp := services.NewDeleteConfigReviewSuppressionDefault(code).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	_, dbUser := r.SessionManager.Logged(ctx)
+	r.EventCenter.AddInfoEvent(fmt.Sprintf("{user} deleted config review suppression %d", params.ID), dbUser)
+
+	rsp := services.NewDeleteConfigReviewSuppressionOK()
+	return rsp
+}
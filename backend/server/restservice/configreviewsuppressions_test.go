@@ -0,0 +1,196 @@
+package restservice
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/stretchr/testify/require"
+	"isc.org/stork/server/configreview"
+	dbmodel "isc.org/stork/server/database/model"
+	dbtest "isc.org/stork/server/database/test"
+	"isc.org/stork/server/gen/models"
+	"isc.org/stork/server/gen/restapi/operations/services"
+	storktest "isc.org/stork/server/test/dbmodel"
+	storkutil "isc.org/stork/util"
+)
+
+// Test converting the config review suppression to the REST API format.
+func TestConvertConfigReviewSuppressionToRestAPI(t *testing.T) {
+	suppression := &dbmodel.ConfigReviewSuppression{
+		ID:          1,
+		CreatedAt:   time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC),
+		ExpiresAt:   storkutil.Ptr(time.Date(2024, 1, 2, 10, 0, 0, 0, time.UTC)),
+		CheckerName: "overlapping_subnet",
+		DaemonID:    storkutil.Ptr(int64(3)),
+		ObjectType:  dbmodel.ConfigReviewSuppressionObjectSubnet,
+		ObjectKey:   "192.0.2.0/24",
+		Reason:      "lab setup",
+		UserID:      storkutil.Ptr(int64(2)),
+		User: &dbmodel.SystemUser{
+			Login: "admin",
+		},
+	}
+	restSuppression := convertConfigReviewSuppressionToRestAPI(suppression)
+	require.NotNil(t, restSuppression)
+	require.EqualValues(t, 1, restSuppression.ID)
+	require.Equal(t, "2024-01-01T10:00:00.000Z", restSuppression.CreatedAt.String())
+	require.NotNil(t, restSuppression.ExpiresAt)
+	require.Equal(t, "2024-01-02T10:00:00.000Z", restSuppression.ExpiresAt.String())
+	require.Equal(t, "overlapping_subnet", *restSuppression.CheckerName)
+	require.EqualValues(t, 3, *restSuppression.DaemonID)
+	require.Equal(t, "subnet", *restSuppression.ObjectType)
+	require.Equal(t, "192.0.2.0/24", *restSuppression.ObjectKey)
+	require.Equal(t, "lab setup", *restSuppression.Reason)
+	require.EqualValues(t, 2, restSuppression.UserID)
+	require.Equal(t, "admin", restSuppression.UserLogin)
+	require.True(t, *restSuppression.Expired)
+
+	// Permanent suppression for all daemons.
+	suppression.ExpiresAt = nil
+	suppression.DaemonID = nil
+	suppression.UserID = nil
+	suppression.User = nil
+	restSuppression = convertConfigReviewSuppressionToRestAPI(suppression)
+	require.Nil(t, restSuppression.ExpiresAt)
+	require.Nil(t, restSuppression.DaemonID)
+	require.Zero(t, restSuppression.UserID)
+	require.Empty(t, restSuppression.UserLogin)
+	require.False(t, *restSuppression.Expired)
+}
+
+// Test creating, listing and deleting the config review suppressions.
+func TestCreateGetDeleteConfigReviewSuppression(t *testing.T) {
+	db, dbSettings, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	machine := &dbmodel.Machine{
+		Address:   "localhost",
+		AgentPort: 8080,
+	}
+	err := dbmodel.AddMachine(db, machine)
+	require.NoError(t, err)
+	app := &dbmodel.App{
+		Type: dbmodel.AppTypeKea,
+		Daemons: []*dbmodel.Daemon{
+			dbmodel.NewKeaDaemon(dbmodel.DaemonNameDHCPv4, true),
+			dbmodel.NewKeaDaemon(dbmodel.DaemonNameDHCPv6, true),
+		},
+		MachineID: machine.ID,
+	}
+	daemons, err := dbmodel.AddApp(db, app)
+	require.NoError(t, err)
+
+	fd := &storktest.FakeDispatcher{}
+	_ = fd.SetCheckerState(nil, "overlapping_subnet", configreview.CheckerStateEnabled)
+	eventCenter := &storktest.FakeEventCenter{}
+	rapi, err := NewRestAPI(dbSettings, db, fd, eventCenter)
+	require.NoError(t, err)
+
+	ctx, err := rapi.SessionManager.Load(context.Background(), "")
+	require.NoError(t, err)
+	user, err := dbmodel.GetUserByID(db, 1)
+	require.NoError(t, err)
+	require.NotNil(t, user)
+	err = rapi.SessionManager.LoginHandler(ctx, user)
+	require.NoError(t, err)
+
+	newSuppression := func(checkerName, objectType, objectKey string, daemonID *int64) *models.ConfigReviewSuppression {
+		return &models.ConfigReviewSuppression{
+			CheckerName: storkutil.Ptr(checkerName),
+			DaemonID:    daemonID,
+			ObjectType:  storkutil.Ptr(objectType),
+			ObjectKey:   storkutil.Ptr(objectKey),
+			Reason:      storkutil.Ptr("lab setup"),
+		}
+	}
+
+	t.Run("invalid suppressions", func(t *testing.T) {
+		invalid := []*models.ConfigReviewSuppression{
+			nil,
+			newSuppression("foo", "subnet", "192.0.2.0/24", nil),
+			newSuppression("overlapping_subnet", "subnet", "192.0.2.1", nil),
+			newSuppression("overlapping_subnet", "pool", "192.0.2.0/24", nil),
+			newSuppression("overlapping_subnet", "subnet", "192.0.2.0/24", storkutil.Ptr(int64(1000))),
+		}
+		emptyReason := newSuppression("overlapping_subnet", "subnet", "192.0.2.0/24", nil)
+		emptyReason.Reason = storkutil.Ptr("")
+		expired := newSuppression("overlapping_subnet", "subnet", "192.0.2.0/24", nil)
+		expired.ExpiresAt = storkutil.Ptr(strfmt.DateTime(storkutil.UTCNow().Add(-time.Hour)))
+		invalid = append(invalid, emptyReason, expired)
+
+		for _, suppression := range invalid {
+			rsp := rapi.CreateConfigReviewSuppression(ctx, services.CreateConfigReviewSuppressionParams{
+				Suppression: suppression,
+			})
+			require.IsType(t, &services.CreateConfigReviewSuppressionDefault{}, rsp)
+			require.Equal(t, http.StatusBadRequest, getStatusCode(*rsp.(*services.CreateConfigReviewSuppressionDefault)))
+		}
+	})
+
+	// Suppress the findings for a subnet on the first daemon.
+	suppression := newSuppression("overlapping_subnet", "subnet", "192.0.2.0/24", &daemons[0].ID)
+	suppression.ExpiresAt = storkutil.Ptr(strfmt.DateTime(storkutil.UTCNow().Add(time.Hour)))
+	rsp := rapi.CreateConfigReviewSuppression(ctx, services.CreateConfigReviewSuppressionParams{
+		Suppression: suppression,
+	})
+	require.IsType(t, &services.CreateConfigReviewSuppressionOK{}, rsp)
+	created := rsp.(*services.CreateConfigReviewSuppressionOK).Payload
+	require.NotZero(t, created.ID)
+	require.EqualValues(t, user.ID, created.UserID)
+	require.Equal(t, user.Login, created.UserLogin)
+	require.False(t, *created.Expired)
+
+	// Suppress the findings for a subnet on the second daemon.
+	rsp = rapi.CreateConfigReviewSuppression(ctx, services.CreateConfigReviewSuppressionParams{
+		Suppression: newSuppression("overlapping_subnet", "subnet", "192.0.3.0/24", &daemons[1].ID),
+	})
+	require.IsType(t, &services.CreateConfigReviewSuppressionOK{}, rsp)
+
+	// Suppress the findings for a subnet on all daemons.
+	rsp = rapi.CreateConfigReviewSuppression(ctx, services.CreateConfigReviewSuppressionParams{
+		Suppression: newSuppression("overlapping_subnet", "subnet", "192.0.4.0/24", nil),
+	})
+	require.IsType(t, &services.CreateConfigReviewSuppressionOK{}, rsp)
+
+	require.Len(t, eventCenter.Events, 3)
+	require.Contains(t, eventCenter.Events[0].Text, "suppressed findings of the overlapping_subnet config checker for subnet 192.0.2.0/24")
+
+	// Get all suppressions.
+	rsp = rapi.GetConfigReviewSuppressions(ctx, services.GetConfigReviewSuppressionsParams{})
+	require.IsType(t, &services.GetConfigReviewSuppressionsOK{}, rsp)
+	suppressions := rsp.(*services.GetConfigReviewSuppressionsOK).Payload
+	require.EqualValues(t, 3, suppressions.Total)
+	require.Len(t, suppressions.Items, 3)
+	require.Equal(t, user.Login, suppressions.Items[0].UserLogin)
+
+	// Get the suppressions applying to the first daemon.
+	rsp = rapi.GetConfigReviewSuppressions(ctx, services.GetConfigReviewSuppressionsParams{
+		DaemonID: &daemons[0].ID,
+	})
+	require.IsType(t, &services.GetConfigReviewSuppressionsOK{}, rsp)
+	suppressions = rsp.(*services.GetConfigReviewSuppressionsOK).Payload
+	require.EqualValues(t, 2, suppressions.Total)
+	require.Equal(t, "192.0.2.0/24", *suppressions.Items[0].ObjectKey)
+	require.Equal(t, "192.0.4.0/24", *suppressions.Items[1].ObjectKey)
+
+	// Delete the suppression.
+	rsp = rapi.DeleteConfigReviewSuppression(ctx, services.DeleteConfigReviewSuppressionParams{
+		ID: created.ID,
+	})
+	require.IsType(t, &services.DeleteConfigReviewSuppressionOK{}, rsp)
+	require.Len(t, eventCenter.Events, 4)
+
+	// The suppression no longer exists.
+	rsp = rapi.DeleteConfigReviewSuppression(ctx, services.DeleteConfigReviewSuppressionParams{
+		ID: created.ID,
+	})
+	require.IsType(t, &services.DeleteConfigReviewSuppressionDefault{}, rsp)
+	require.Equal(t, http.StatusNotFound, getStatusCode(*rsp.(*services.DeleteConfigReviewSuppressionDefault)))
+
+	rsp = rapi.GetConfigReviewSuppressions(ctx, services.GetConfigReviewSuppressionsParams{})
+	require.IsType(t, &services.GetConfigReviewSuppressionsOK{}, rsp)
+	require.EqualValues(t, 2, rsp.(*services.GetConfigReviewSuppressionsOK).Payload.Total)
+}
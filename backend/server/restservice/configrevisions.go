@@ -0,0 +1,261 @@
+package restservice
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/go-openapi/runtime/middleware"
+	"github.com/go-openapi/strfmt"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	"isc.org/stork/server/config"
+	dbmodel "isc.org/stork/server/database/model"
+	"isc.org/stork/server/gen/models"
+	"isc.org/stork/server/gen/restapi/operations/services"
+	storkutil "isc.org/stork/util"
+)
+
+// Converts the Kea configuration revision to the REST API format. The
+// configuration is included only if it has been fetched from the database.
+func convertKeaConfigRevisionToRestAPI(revision *dbmodel.KeaConfigRevision) *models.KeaConfigRevision {
+	restRevision := &models.KeaConfigRevision{
+		ID:         revision.ID,
+		DaemonID:   revision.DaemonID,
+		CreatedAt:  strfmt.DateTime(revision.CreatedAt),
+		ConfigHash: revision.ConfigHash,
+		Operation:  revision.Operation,
+	}
+	if revision.UserID != 0 {
+		restRevision.UserID = storkutil.Ptr(revision.UserID)
+	}
+	if revision.User != nil {
+		restRevision.UserLogin = revision.User.Login
+	}
+	if revision.Config != nil {
+		restRevision.Config = revision.Config
+	}
+	return restRevision
+}
+
+// Fetches the configuration revision of the specified daemon from the
+// database. It returns the HTTP error code if an error occurs or 0 when
+// there is no error. In addition it returns an error string to be included
+// in the HTTP response or an empty string if there is no error.
+func (r *RestAPI) getKeaConfigRevision(daemonID, revisionID int64) (*dbmodel.KeaConfigRevision, int, string) {
+	revision, err := dbmodel.GetKeaConfigRevisionByID(r.DB, revisionID)
+	if err != nil {
+		msg := fmt.Sprintf("Cannot get configuration revision with ID %d from db", revisionID)
+		log.WithError(err).Error(msg)
+		return nil, http.StatusInternalServerError, msg
+	}
+	// The revision must belong to the specified daemon.
+	if revision == nil || revision.DaemonID != daemonID {
+		msg := fmt.Sprintf("Cannot find configuration revision with ID %d for daemon with ID %d", revisionID, daemonID)
+		return nil, http.StatusNotFound, msg
+	}
+	return revision, 0, ""
+}
+
+// Returns the revisions of the daemon configuration beginning from the most
+// recent one. The start and limit values are optional. They are used to
+// retrieve paged revisions. The returned revisions lack the configurations.
+func (r *RestAPI) GetDaemonConfigRevisions(ctx context.Context, params services.GetDaemonConfigRevisionsParams) middleware.Responder {
+	start := int64(0)
+	if params.Start != nil {
+		start = *params.Start
+	}
+
+	limit := int64(0)
+	if params.Limit != nil {
+		limit = *params.Limit
+	}
+
+	dbRevisions, total, err := dbmodel.GetKeaConfigRevisionsByDaemonID(r.DB, start, limit, params.ID)
+	if err != nil {
+		msg := fmt.Sprintf("Cannot get configuration revisions for daemon with ID %d from db", params.ID)
+		log.WithError(err).Error(msg)
+		rsp := services.NewGetDaemonConfigRevisionsDefault(http.StatusInternalServerError).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+
+	revisions := &models.KeaConfigRevisions{
+		Items: []*models.KeaConfigRevision{},
+		Total: total,
+	}
+	for i := range dbRevisions {
+		revisions.Items = append(revisions.Items, convertKeaConfigRevisionToRestAPI(&dbRevisions[i]))
+	}
+
+	rsp := services.NewGetDaemonConfigRevisionsOK().WithPayload(revisions)
+	return rsp
+}
+
+// Returns the revision of the daemon configuration including the
+// configuration. The sensitive data are hidden from the users who
+// don't belong to the super-admin group.
+func (r *RestAPI) GetDaemonConfigRevision(ctx context.Context, params services.GetDaemonConfigRevisionParams) middleware.Responder {
+	revision, code, msg := r.getKeaConfigRevision(params.ID, params.RevisionID)
+	if code != 0 {
+		rsp := services.NewGetDaemonConfigRevisionDefault(code).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+
+	_, dbUser := r.SessionManager.Logged(ctx)
+	if !dbUser.InGroup(&dbmodel.SystemGroup{ID: dbmodel.SuperAdminGroupID}) {
+		revision.Config.HideSensitiveData()
+	}
+
+	rsp := services.NewGetDaemonConfigRevisionOK().WithPayload(convertKeaConfigRevisionToRestAPI(revision))
+	return rsp
+}
+
+// Returns the structural differences between the base configuration and
+// the configuration held in the specified revision. The base configuration
+// is held in the revision specified with the base revision ID. If the base
+// revision ID is not specified, the current daemon configuration is used.
+// The sensitive data are hidden from the users who don't belong to the
+// super-admin group.
+func (r *RestAPI) GetDaemonConfigRevisionDiff(ctx context.Context, params services.GetDaemonConfigRevisionDiffParams) middleware.Responder {
+	revision, code, msg := r.getKeaConfigRevision(params.ID, params.RevisionID)
+	if code != 0 {
+		rsp := services.NewGetDaemonConfigRevisionDiffDefault(code).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+
+	var baseConfig *dbmodel.KeaConfig
+	if params.BaseRevisionID != nil {
+		var baseRevision *dbmodel.KeaConfigRevision
+		baseRevision, code, msg = r.getKeaConfigRevision(params.ID, *params.BaseRevisionID)
+		if code != 0 {
+			rsp := services.NewGetDaemonConfigRevisionDiffDefault(code).WithPayload(&models.APIError{
+				Message: &msg,
+			})
+			return rsp
+		}
+		baseConfig = baseRevision.Config
+	} else {
+		dbDaemon, err := dbmodel.GetDaemonByID(r.DB, params.ID)
+		if err != nil {
+			msg := fmt.Sprintf("Cannot get daemon with ID %d from db", params.ID)
+			log.WithError(err).Error(msg)
+			rsp := services.NewGetDaemonConfigRevisionDiffDefault(http.StatusInternalServerError).WithPayload(&models.APIError{
+				Message: &msg,
+			})
+			return rsp
+		}
+		if dbDaemon == nil || dbDaemon.KeaDaemon == nil || dbDaemon.KeaDaemon.Config == nil {
+			msg := fmt.Sprintf("Cannot find configuration of the daemon with ID %d", params.ID)
+			rsp := services.NewGetDaemonConfigRevisionDiffDefault(http.StatusNotFound).WithPayload(&models.APIError{
+				Message: &msg,
+			})
+			return rsp
+		}
+		baseConfig = dbDaemon.KeaDaemon.Config
+	}
+
+	_, dbUser := r.SessionManager.Logged(ctx)
+	if !dbUser.InGroup(&dbmodel.SystemGroup{ID: dbmodel.SuperAdminGroupID}) {
+		baseConfig.HideSensitiveData()
+		revision.Config.HideSensitiveData()
+	}
+
+	changes, err := storkutil.JSONDiff(baseConfig, revision.Config)
+	if err != nil {
+		msg := "Problem with comparing the configurations"
+		log.WithError(err).Error(msg)
+		rsp := services.NewGetDaemonConfigRevisionDiffDefault(http.StatusInternalServerError).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+
+	rsp := services.NewGetDaemonConfigRevisionDiffOK().WithPayload(&models.KeaConfigRevisionDiff{
+		RevisionID:     revision.ID,
+		BaseRevisionID: params.BaseRevisionID,
+		Changes:        convertConfigChangesToRestAPI(changes),
+	})
+	return rsp
+}
+
+// Restores the daemon configuration from the specified revision. It sends
+// the config-set command with the configuration held in the revision and,
+// optionally, the config-write command to the daemon.
+func (r *RestAPI) RollbackDaemonConfigRevision(ctx context.Context, params services.RollbackDaemonConfigRevisionParams) middleware.Responder {
+	revision, code, msg := r.getKeaConfigRevision(params.ID, params.RevisionID)
+	if code != 0 {
+		rsp := services.NewRollbackDaemonConfigRevisionDefault(code).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	dbDaemon, err := dbmodel.GetDaemonByID(r.DB, params.ID)
+	if err != nil {
+		msg := fmt.Sprintf("Cannot get daemon with ID %d from db", params.ID)
+		log.WithError(err).Error(msg)
+		rsp := services.NewRollbackDaemonConfigRevisionDefault(http.StatusInternalServerError).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	if dbDaemon == nil {
+		msg := fmt.Sprintf("Cannot find daemon with ID %d", params.ID)
+		rsp := services.NewRollbackDaemonConfigRevisionDefault(http.StatusNotFound).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	// Create configuration context.
+	_, user := r.SessionManager.Logged(ctx)
+	cctx, err := r.ConfigManager.CreateContext(int64(user.ID))
+	if err != nil {
+		msg := "Problem with creating transaction context for restoring the configuration revision"
+		log.WithError(err).Error(msg)
+		rsp := services.NewRollbackDaemonConfigRevisionDefault(http.StatusInternalServerError).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	// Create Kea commands to restore the configuration.
+	writeConfig := params.Rollback != nil && params.Rollback.WriteConfig
+	cctx, err = r.ConfigManager.GetKeaModule().ApplyConfigRollback(cctx, *dbDaemon, revision, writeConfig)
+	// Release the daemon's configuration lock when done.
+	defer r.ConfigManager.Done(cctx)
+	if err != nil {
+		var lock *config.LockError
+		if errors.As(err, &lock) {
+			msg := fmt.Sprintf("Unable to restore the configuration revision because the configuration of the daemon with ID %d may be currently edited by another user", params.ID)
+			log.WithError(err).Error(msg)
+			rsp := services.NewRollbackDaemonConfigRevisionDefault(http.StatusLocked).WithPayload(&models.APIError{
+				Message: &msg,
+			})
+			return rsp
+		}
+		msg := fmt.Sprintf("Problem with preparing commands for restoring the configuration revision: %s", err)
+		log.WithError(err).Error(msg)
+		rsp := services.NewRollbackDaemonConfigRevisionDefault(http.StatusBadRequest).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	// Send the commands to Kea servers.
+	_, err = r.ConfigManager.Commit(cctx)
+	if err != nil {
+		msg := fmt.Sprintf("Problem with restoring the configuration revision: %s", err)
+		log.WithError(err).Error(msg)
+		rsp := services.NewRollbackDaemonConfigRevisionDefault(http.StatusConflict).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	// Send OK to the client.
+	rsp := services.NewRollbackDaemonConfigRevisionOK()
+	return rsp
+}
@@ -0,0 +1,327 @@
+package restservice
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/stretchr/testify/require"
+	dbmodel "isc.org/stork/server/database/model"
+	dbtest "isc.org/stork/server/database/test"
+	"isc.org/stork/server/gen/models"
+	dhcp "isc.org/stork/server/gen/restapi/operations/d_h_c_p"
+	"isc.org/stork/server/gen/restapi/operations/services"
+	storkutil "isc.org/stork/util"
+)
+
+// Test converting the configuration revision to the REST API format.
+func TestConvertKeaConfigRevisionToRestAPI(t *testing.T) {
+	config, err := dbmodel.NewKeaConfigFromJSON(`{"Dhcp4": {}}`)
+	require.NoError(t, err)
+
+	createdAt := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	revision := &dbmodel.KeaConfigRevision{
+		ID:         1,
+		DaemonID:   2,
+		CreatedAt:  createdAt,
+		Config:     config,
+		ConfigHash: "abc",
+		UserID:     3,
+		User: &dbmodel.SystemUser{
+			ID:    3,
+			Login: "admin",
+		},
+		Operation: "client_class_add",
+	}
+	restRevision := convertKeaConfigRevisionToRestAPI(revision)
+	require.NotNil(t, restRevision)
+	require.EqualValues(t, 1, restRevision.ID)
+	require.EqualValues(t, 2, restRevision.DaemonID)
+	require.Equal(t, strfmt.DateTime(createdAt), restRevision.CreatedAt)
+	require.Equal(t, config, restRevision.Config)
+	require.Equal(t, "abc", restRevision.ConfigHash)
+	require.NotNil(t, restRevision.UserID)
+	require.EqualValues(t, 3, *restRevision.UserID)
+	require.Equal(t, "admin", restRevision.UserLogin)
+	require.Equal(t, "client_class_add", restRevision.Operation)
+
+	// The revision without the configuration and the user.
+	revision.Config = nil
+	revision.UserID = 0
+	revision.User = nil
+	restRevision = convertKeaConfigRevisionToRestAPI(revision)
+	require.Nil(t, restRevision.Config)
+	require.Nil(t, restRevision.UserID)
+	require.Empty(t, restRevision.UserLogin)
+}
+
+// Test getting the configuration revisions of a daemon and comparing them.
+func TestGetDaemonConfigRevisions(t *testing.T) {
+	db, dbSettings, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	// Committing the app stores the first revision.
+	daemons := addTestClientClassServers(t, db, `{
+		"Dhcp4": {
+			"valid-lifetime": 1000
+		}
+	}`)
+
+	// Add another revision.
+	err := daemons[0].SetConfigFromJSON(`{
+		"Dhcp4": {
+			"valid-lifetime": 2000,
+			"renew-timer": 500
+		}
+	}`)
+	require.NoError(t, err)
+	err = dbmodel.UpdateDaemon(db, &daemons[0])
+	require.NoError(t, err)
+	_, err = dbmodel.AddKeaConfigRevision(db, &daemons[0])
+	require.NoError(t, err)
+
+	rapi, _, ctx := newTestClientClassRestAPI(t, db, dbSettings)
+
+	// List the revisions.
+	rsp := rapi.GetDaemonConfigRevisions(ctx, services.GetDaemonConfigRevisionsParams{
+		ID: daemons[0].ID,
+	})
+	require.IsType(t, &services.GetDaemonConfigRevisionsOK{}, rsp)
+	revisions := rsp.(*services.GetDaemonConfigRevisionsOK).Payload
+	require.EqualValues(t, 2, revisions.Total)
+	require.Len(t, revisions.Items, 2)
+	require.Greater(t, revisions.Items[0].ID, revisions.Items[1].ID)
+	require.Nil(t, revisions.Items[0].Config)
+
+	// Get the older revision.
+	rsp = rapi.GetDaemonConfigRevision(ctx, services.GetDaemonConfigRevisionParams{
+		ID:         daemons[0].ID,
+		RevisionID: revisions.Items[1].ID,
+	})
+	require.IsType(t, &services.GetDaemonConfigRevisionOK{}, rsp)
+	revision := rsp.(*services.GetDaemonConfigRevisionOK).Payload
+	require.Equal(t, revisions.Items[1].ID, revision.ID)
+	require.NotNil(t, revision.Config)
+
+	// The revision doesn't belong to another daemon.
+	rsp = rapi.GetDaemonConfigRevision(ctx, services.GetDaemonConfigRevisionParams{
+		ID:         daemons[0].ID + 1,
+		RevisionID: revisions.Items[1].ID,
+	})
+	require.IsType(t, &services.GetDaemonConfigRevisionDefault{}, rsp)
+	require.Equal(t, http.StatusNotFound, getStatusCode(*rsp.(*services.GetDaemonConfigRevisionDefault)))
+
+	// Compare the revisions.
+	rsp = rapi.GetDaemonConfigRevisionDiff(ctx, services.GetDaemonConfigRevisionDiffParams{
+		ID:             daemons[0].ID,
+		RevisionID:     revisions.Items[0].ID,
+		BaseRevisionID: storkutil.Ptr(revisions.Items[1].ID),
+	})
+	require.IsType(t, &services.GetDaemonConfigRevisionDiffOK{}, rsp)
+	diff := rsp.(*services.GetDaemonConfigRevisionDiffOK).Payload
+	require.Equal(t, revisions.Items[0].ID, diff.RevisionID)
+	require.NotNil(t, diff.BaseRevisionID)
+	require.Equal(t, revisions.Items[1].ID, *diff.BaseRevisionID)
+	require.Len(t, diff.Changes, 2)
+	require.Equal(t, &models.ConfigChange{
+		Path:  "/Dhcp4/renew-timer",
+		Op:    "add",
+		After: float64(500),
+	}, diff.Changes[0])
+	require.Equal(t, &models.ConfigChange{
+		Path:   "/Dhcp4/valid-lifetime",
+		Op:     "replace",
+		Before: float64(1000),
+		After:  float64(2000),
+	}, diff.Changes[1])
+
+	// Compare the older revision with the current configuration.
+	rsp = rapi.GetDaemonConfigRevisionDiff(ctx, services.GetDaemonConfigRevisionDiffParams{
+		ID:         daemons[0].ID,
+		RevisionID: revisions.Items[1].ID,
+	})
+	require.IsType(t, &services.GetDaemonConfigRevisionDiffOK{}, rsp)
+	diff = rsp.(*services.GetDaemonConfigRevisionDiffOK).Payload
+	require.Nil(t, diff.BaseRevisionID)
+	require.Len(t, diff.Changes, 2)
+	require.Equal(t, "remove", diff.Changes[0].Op)
+	require.Equal(t, "replace", diff.Changes[1].Op)
+
+	// Non-existing base revision.
+	rsp = rapi.GetDaemonConfigRevisionDiff(ctx, services.GetDaemonConfigRevisionDiffParams{
+		ID:             daemons[0].ID,
+		RevisionID:     revisions.Items[1].ID,
+		BaseRevisionID: storkutil.Ptr(revisions.Items[0].ID + 100),
+	})
+	require.IsType(t, &services.GetDaemonConfigRevisionDiffDefault{}, rsp)
+	require.Equal(t, http.StatusNotFound, getStatusCode(*rsp.(*services.GetDaemonConfigRevisionDiffDefault)))
+}
+
+// Test restoring the daemon configuration from a revision.
+func TestRollbackDaemonConfigRevision(t *testing.T) {
+	db, dbSettings, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	daemons := addTestClientClassServers(t, db, `{
+		"Dhcp4": {
+			"valid-lifetime": 1000
+		}
+	}`)
+	revision, err := dbmodel.GetLatestKeaConfigRevision(db, daemons[0].ID)
+	require.NoError(t, err)
+	require.NotNil(t, revision)
+
+	// Modify the configuration.
+	err = daemons[0].SetConfigFromJSON(`{
+		"Dhcp4": {
+			"valid-lifetime": 2000
+		}
+	}`)
+	require.NoError(t, err)
+	err = dbmodel.UpdateDaemon(db, &daemons[0])
+	require.NoError(t, err)
+
+	rapi, fa, ctx := newTestClientClassRestAPI(t, db, dbSettings)
+
+	rsp := rapi.RollbackDaemonConfigRevision(ctx, services.RollbackDaemonConfigRevisionParams{
+		ID:         daemons[0].ID,
+		RevisionID: revision.ID,
+		Rollback: &models.KeaConfigRevisionRollback{
+			WriteConfig: true,
+		},
+	})
+	require.IsType(t, &services.RollbackDaemonConfigRevisionOK{}, rsp)
+
+	// The config-set and config-write commands should be sent.
+	require.Len(t, fa.RecordedCommands, 2)
+	require.JSONEq(t, `{
+		"command": "config-set",
+		"service": ["dhcp4"],
+		"arguments": {
+			"Dhcp4": {
+				"valid-lifetime": 1000
+			}
+		}
+	}`, fa.RecordedCommands[0].Marshal())
+	require.EqualValues(t, "config-write", fa.RecordedCommands[1].GetCommand())
+
+	// The configuration should be restored in the database.
+	daemon, err := dbmodel.GetDaemonByID(db, daemons[0].ID)
+	require.NoError(t, err)
+	require.EqualValues(t, 1000, *daemon.KeaDaemon.Config.GetValidLifetimeParameters().ValidLifetime)
+
+	// Non-existing revision.
+	rsp = rapi.RollbackDaemonConfigRevision(ctx, services.RollbackDaemonConfigRevisionParams{
+		ID:         daemons[0].ID,
+		RevisionID: revision.ID + 100,
+	})
+	require.IsType(t, &services.RollbackDaemonConfigRevisionDefault{}, rsp)
+	require.Equal(t, http.StatusNotFound, getStatusCode(*rsp.(*services.RollbackDaemonConfigRevisionDefault)))
+}
+
+// Test that the configuration of the daemon using the configuration backend
+// cannot be restored from a revision.
+func TestRollbackDaemonConfigRevisionConfigBackend(t *testing.T) {
+	db, dbSettings, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	daemons := addTestClientClassServers(t, db, `{
+		"Dhcp4": {
+			"server-tag": "server1",
+			"config-control": {
+				"config-databases": [
+					{
+						"type": "mysql",
+						"name": "kea"
+					}
+				]
+			},
+			"hooks-libraries": [
+				{
+					"library": "/usr/lib/kea/libdhcp_cb_cmds.so"
+				}
+			]
+		}
+	}`)
+	revision, err := dbmodel.GetLatestKeaConfigRevision(db, daemons[0].ID)
+	require.NoError(t, err)
+	require.NotNil(t, revision)
+
+	rapi, fa, ctx := newTestClientClassRestAPI(t, db, dbSettings)
+
+	rsp := rapi.RollbackDaemonConfigRevision(ctx, services.RollbackDaemonConfigRevisionParams{
+		ID:         daemons[0].ID,
+		RevisionID: revision.ID,
+	})
+	require.IsType(t, &services.RollbackDaemonConfigRevisionDefault{}, rsp)
+	defaultRsp := rsp.(*services.RollbackDaemonConfigRevisionDefault)
+	require.Equal(t, http.StatusBadRequest, getStatusCode(*defaultRsp))
+	require.Contains(t, *defaultRsp.Payload.Message, "configuration backend")
+	require.Empty(t, fa.RecordedCommands)
+}
+
+// Test that the daemon configuration cannot be restored while it is locked
+// by another transaction and that the rollback releases the lock.
+func TestRollbackDaemonConfigRevisionLocked(t *testing.T) {
+	db, dbSettings, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	daemons := addTestClientClassServers(t, db, `{
+		"Dhcp4": {
+			"client-classes": [
+				{
+					"name": "foo"
+				}
+			]
+		}
+	}`)
+	revision, err := dbmodel.GetLatestKeaConfigRevision(db, daemons[0].ID)
+	require.NoError(t, err)
+	require.NotNil(t, revision)
+
+	rapi, fa, ctx := newTestClientClassRestAPI(t, db, dbSettings)
+
+	// Lock the daemon by beginning another transaction.
+	rsp := rapi.UpdateClientClassBegin(ctx, dhcp.UpdateClientClassBeginParams{
+		Name: "foo",
+		Request: &models.UpdateClientClassBeginRequest{
+			DaemonIds: []int64{daemons[0].ID},
+		},
+	})
+	require.IsType(t, &dhcp.UpdateClientClassBeginOK{}, rsp)
+	transactionID := rsp.(*dhcp.UpdateClientClassBeginOK).Payload.ID
+
+	// The daemon is locked, so the configuration cannot be restored.
+	rsp = rapi.RollbackDaemonConfigRevision(ctx, services.RollbackDaemonConfigRevisionParams{
+		ID:         daemons[0].ID,
+		RevisionID: revision.ID,
+	})
+	require.IsType(t, &services.RollbackDaemonConfigRevisionDefault{}, rsp)
+	require.Equal(t, http.StatusLocked, getStatusCode(*rsp.(*services.RollbackDaemonConfigRevisionDefault)))
+	require.Empty(t, fa.RecordedCommands)
+
+	// Cancel the other transaction.
+	rsp = rapi.UpdateClientClassDelete(ctx, dhcp.UpdateClientClassDeleteParams{
+		Name: "foo",
+		ID:   transactionID,
+	})
+	require.IsType(t, &dhcp.UpdateClientClassDeleteOK{}, rsp)
+
+	// The configuration can be restored now.
+	rsp = rapi.RollbackDaemonConfigRevision(ctx, services.RollbackDaemonConfigRevisionParams{
+		ID:         daemons[0].ID,
+		RevisionID: revision.ID,
+	})
+	require.IsType(t, &services.RollbackDaemonConfigRevisionOK{}, rsp)
+	require.Len(t, fa.RecordedCommands, 1)
+
+	// The rollback should have released the lock.
+	rsp = rapi.UpdateClientClassBegin(ctx, dhcp.UpdateClientClassBeginParams{
+		Name: "foo",
+		Request: &models.UpdateClientClassBeginRequest{
+			DaemonIds: []int64{daemons[0].ID},
+		},
+	})
+	require.IsType(t, &dhcp.UpdateClientClassBeginOK{}, rsp)
+}
@@ -0,0 +1,369 @@
+package restservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/go-openapi/runtime/middleware"
+	"github.com/go-openapi/strfmt"
+	log "github.com/sirupsen/logrus"
+	keaconfig "isc.org/stork/appcfg/kea"
+	"isc.org/stork/server/apps/kea"
+	"isc.org/stork/server/config"
+	dbmodel "isc.org/stork/server/database/model"
+	"isc.org/stork/server/gen/models"
+	dhcp "isc.org/stork/server/gen/restapi/operations/d_h_c_p"
+	storkutil "isc.org/stork/util"
+)
+
+// Converts the DNS servers of the DDNS domain to the format used in the
+// REST API.
+func convertDDNSDomainServersToRestAPI(servers []keaconfig.D2DNSServer) []*models.DDNSDomainServer {
+	restServers := []*models.DDNSDomainServer{}
+	for _, server := range servers {
+		restServers = append(restServers, &models.DDNSDomainServer{
+			Hostname:  server.HostName,
+			IPAddress: server.IPAddress,
+			Port:      server.Port,
+			KeyName:   server.KeyName,
+		})
+	}
+	return restServers
+}
+
+// Converts the DDNS domain held in the database to the format used in the
+// REST API. The domain never includes the TSIG key secrets, only the key
+// names.
+func convertDDNSDomainToRestAPI(domain *dbmodel.DDNSDomain) *models.DDNSDomain {
+	return &models.DDNSDomain{
+		ID:         domain.ID,
+		DaemonID:   domain.DaemonID,
+		Direction:  storkutil.Ptr(string(domain.Direction)),
+		Name:       storkutil.Ptr(domain.Name),
+		KeyName:    domain.KeyName,
+		DNSServers: convertDDNSDomainServersToRestAPI(domain.DNSServers),
+	}
+}
+
+// Converts the DDNS domain received over the REST API to the format used
+// in the D2 configuration.
+func convertDDNSDomainFromRestAPI(restDomain *models.DDNSDomain) (keaconfig.D2DDNSDirection, *keaconfig.D2DDNSDomain) {
+	var (
+		direction keaconfig.D2DDNSDirection
+		domain    keaconfig.D2DDNSDomain
+	)
+	if restDomain == nil {
+		return direction, &domain
+	}
+	if restDomain.Direction != nil {
+		direction = keaconfig.D2DDNSDirection(*restDomain.Direction)
+	}
+	if restDomain.Name != nil {
+		domain.Name = *restDomain.Name
+	}
+	domain.KeyName = restDomain.KeyName
+	for _, server := range restDomain.DNSServers {
+		if server == nil {
+			continue
+		}
+		domain.DNSServers = append(domain.DNSServers, keaconfig.D2DNSServer{
+			HostName:  server.Hostname,
+			IPAddress: server.IPAddress,
+			Port:      server.Port,
+			KeyName:   server.KeyName,
+		})
+	}
+	return direction, &domain
+}
+
+// Returns the DDNS domains of the daemon in the format used in the REST API.
+func (r *RestAPI) getDDNSDomains(daemonID int64) (*models.DDNSDomains, error) {
+	domains, err := dbmodel.GetDDNSDomainsByDaemonID(r.DB, daemonID)
+	if err != nil {
+		return nil, err
+	}
+	restDomains := &models.DDNSDomains{
+		Items: []*models.DDNSDomain{},
+		Total: int64(len(domains)),
+	}
+	for i := range domains {
+		restDomains.Items = append(restDomains.Items, convertDDNSDomainToRestAPI(&domains[i]))
+	}
+	return restDomains, nil
+}
+
+// Get the forward and reverse DDNS domains configured in the Kea D2 daemon.
+func (r *RestAPI) GetDDNSDomains(ctx context.Context, params dhcp.GetDDNSDomainsParams) middleware.Responder {
+	daemon, err := dbmodel.GetDaemonByID(r.DB, params.DaemonID)
+	if err != nil {
+		msg := fmt.Sprintf("Cannot get daemon with ID %d from db", params.DaemonID)
+		log.WithError(err).Error(msg)
+		rsp := dhcp.NewGetDDNSDomainsDefault(http.StatusInternalServerError).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	if daemon == nil || daemon.Name != dbmodel.DaemonNameD2 {
+		msg := fmt.Sprintf("Cannot find Kea D2 daemon with ID %d", params.DaemonID)
+		rsp := dhcp.NewGetDDNSDomainsDefault(http.StatusNotFound).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	domains, err := r.getDDNSDomains(daemon.ID)
+	if err != nil {
+		msg := fmt.Sprintf("Cannot get DDNS domains of daemon with ID %d from db", daemon.ID)
+		log.WithError(err).Error(msg)
+		rsp := dhcp.NewGetDDNSDomainsDefault(http.StatusInternalServerError).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	rsp := dhcp.NewGetDDNSDomainsOK().WithPayload(domains)
+	return rsp
+}
+
+// Implements the POST call to create new transaction for updating the
+// DDNS domains of the Kea D2 daemon (ddns-domains/{daemonId}/transaction).
+func (r *RestAPI) UpdateDDNSDomainsBegin(ctx context.Context, params dhcp.UpdateDDNSDomainsBeginParams) middleware.Responder {
+	// Create configuration context.
+	_, user := r.SessionManager.Logged(ctx)
+	cctx, err := r.ConfigManager.CreateContext(int64(user.ID))
+	if err != nil {
+		msg := "Problem with creating transaction context"
+		log.WithError(err).Error(msg)
+		rsp := dhcp.NewUpdateDDNSDomainsBeginDefault(http.StatusInternalServerError).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	// Begin DDNS domains update transaction. It retrieves current daemon's
+	// configuration and locks the daemon for updates.
+	cctx, err = r.ConfigManager.GetKeaModule().BeginDDNSDomainsUpdate(cctx, params.DaemonID)
+	if err != nil {
+		var (
+			daemonsNotFound *config.SomeDaemonsNotFoundError
+			lock            *config.LockError
+		)
+		switch {
+		case errors.As(err, &daemonsNotFound):
+			msg := fmt.Sprintf("Unable to edit the DDNS domains because the daemon with ID %d cannot be found", params.DaemonID)
+			log.WithError(err).Error(msg)
+			rsp := dhcp.NewUpdateDDNSDomainsBeginDefault(http.StatusNotFound).WithPayload(&models.APIError{
+				Message: &msg,
+			})
+			return rsp
+		case errors.As(err, &lock):
+			msg := fmt.Sprintf("Unable to edit the DDNS domains of the daemon with ID %d because it may be currently edited by another user", params.DaemonID)
+			log.WithError(err).Error(msg)
+			rsp := dhcp.NewUpdateDDNSDomainsBeginDefault(http.StatusLocked).WithPayload(&models.APIError{
+				Message: &msg,
+			})
+			return rsp
+		default:
+			msg := fmt.Sprintf("Problem with initializing transaction for an update of the DDNS domains of the daemon with ID %d: %s", params.DaemonID, err)
+			log.WithError(err).Error(msg)
+			rsp := dhcp.NewUpdateDDNSDomainsBeginDefault(http.StatusBadRequest).WithPayload(&models.APIError{
+				Message: &msg,
+			})
+			return rsp
+		}
+	}
+	state, _ := config.GetTransactionState[kea.ConfigRecipe](cctx)
+	daemon := state.Updates[0].Recipe.KeaDaemonsBeforeConfigUpdate[0]
+
+	// Retrieve the generated context ID.
+	cctxID, ok := config.GetValueAsInt64(cctx, config.ContextIDKey)
+	if !ok {
+		msg := "problem with retrieving context ID for a transaction to update the DDNS domains"
+		log.Error(msg)
+		rsp := dhcp.NewUpdateDDNSDomainsBeginDefault(http.StatusInternalServerError).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	// Remember the context, i.e. new transaction has been successfully created.
+	_ = r.ConfigManager.RememberContext(cctx, time.Minute*10)
+
+	// Return the domains from the daemon's configuration rather than from
+	// the database because they are modified in the configuration.
+	domains := &models.DDNSDomains{
+		Items: []*models.DDNSDomain{},
+	}
+	for _, domain := range dbmodel.NewDDNSDomainsFromDaemon(&daemon) {
+		domains.Items = append(domains.Items, convertDDNSDomainToRestAPI(&domain))
+	}
+	domains.Total = int64(len(domains.Items))
+
+	// Only the names of the TSIG keys are returned. The secrets must not
+	// be exposed.
+	keyNames := []string{}
+	for _, key := range daemon.KeaDaemon.Config.D2Config.TSIGKeys {
+		keyNames = append(keyNames, key.Name)
+	}
+	contents := &models.UpdateDDNSDomainsBeginResponse{
+		ID:           cctxID,
+		Domains:      domains,
+		TsigKeyNames: keyNames,
+	}
+	rsp := dhcp.NewUpdateDDNSDomainsBeginOK().WithPayload(contents)
+	return rsp
+}
+
+// Implements the POST call and commits the DDNS domain changes
+// (ddns-domains/{daemonId}/transaction/{id}/submit).
+func (r *RestAPI) UpdateDDNSDomainsSubmit(ctx context.Context, params dhcp.UpdateDDNSDomainsSubmitParams) middleware.Responder {
+	if code, msg := r.commonUpdateDDNSDomainsSubmit(ctx, params.DaemonID, params.ID, params.Changes, params.ScheduledAt); code != 0 {
+		// Error case.
+		rsp := dhcp.NewUpdateDDNSDomainsSubmitDefault(code).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	rsp := dhcp.NewUpdateDDNSDomainsSubmitOK()
+	return rsp
+}
+
+// Implements the POST call to preview the DDNS domain changes
+// (ddns-domains/{daemonId}/transaction/{id}/preview).
+func (r *RestAPI) UpdateDDNSDomainsPreview(ctx context.Context, params dhcp.UpdateDDNSDomainsPreviewParams) middleware.Responder {
+	cctx, code, msg := r.commonUpdateDDNSDomainsApply(ctx, params.DaemonID, params.ID, params.Changes)
+	if code != 0 {
+		// Error case.
+		rsp := dhcp.NewUpdateDDNSDomainsPreviewDefault(code).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	preview, code, msg := r.previewConfigChanges(ctx, cctx)
+	if code != 0 {
+		rsp := dhcp.NewUpdateDDNSDomainsPreviewDefault(code).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	rsp := dhcp.NewUpdateDDNSDomainsPreviewOK().WithPayload(preview)
+	return rsp
+}
+
+// Implements the DELETE call to cancel updating the DDNS domains
+// (ddns-domains/{daemonId}/transaction/{id}). It removes the specified
+// transaction from the config manager, if the transaction exists.
+func (r *RestAPI) UpdateDDNSDomainsDelete(ctx context.Context, params dhcp.UpdateDDNSDomainsDeleteParams) middleware.Responder {
+	// Retrieve the context from the config manager.
+	_, user := r.SessionManager.Logged(ctx)
+	cctx, _ := r.ConfigManager.RecoverContext(params.ID, int64(user.ID))
+	if cctx == nil {
+		msg := "Transaction expired for the DDNS domains update"
+		log.Errorf("Problem with recovering transaction context for transaction ID %d and user ID %d", params.ID, user.ID)
+		rsp := dhcp.NewUpdateDDNSDomainsDeleteDefault(http.StatusNotFound).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	r.ConfigManager.Done(cctx)
+	rsp := dhcp.NewUpdateDDNSDomainsDeleteOK()
+	return rsp
+}
+
+// Common function that applies the DDNS domain changes to a copy of the
+// transaction state. The daemonID must match the daemon for which the
+// transaction has been created. The deleted domains are applied first,
+// followed by the updated and the added domains. It returns the transaction
+// context with the applied changes. It returns the HTTP error code if an
+// error occurs or 0 when there is no error. In addition it returns an error
+// string to be included in the HTTP response or an empty string if there is
+// no error.
+func (r *RestAPI) commonUpdateDDNSDomainsApply(ctx context.Context, daemonID, transactionID int64, changes *models.DDNSDomainsChanges) (context.Context, int, string) {
+	if changes == nil || len(changes.Added)+len(changes.Updated)+len(changes.Deleted) == 0 {
+		msg := "No DDNS domain changes specified"
+		log.Error(msg)
+		return nil, http.StatusBadRequest, msg
+	}
+	// Retrieve the context from the config manager.
+	_, user := r.SessionManager.Logged(ctx)
+	cctx, _ := r.ConfigManager.RecoverContext(transactionID, int64(user.ID))
+	if cctx == nil {
+		msg := "Transaction expired for the DDNS domains update"
+		log.Errorf("Problem with recovering transaction context for transaction ID %d and user ID %d", transactionID, user.ID)
+		return nil, http.StatusNotFound, msg
+	}
+	if state, ok := config.GetTransactionState[kea.ConfigRecipe](cctx); !ok || len(state.Updates) == 0 || !slices.Contains(state.Updates[0].DaemonIDs, daemonID) {
+		msg := fmt.Sprintf("Transaction %d does not pertain to the daemon with ID %d", transactionID, daemonID)
+		log.Error(msg)
+		return nil, http.StatusBadRequest, msg
+	}
+	// Apply the changes to a copy of the transaction state. The transaction
+	// remembered by the config manager is only updated upon commit.
+	cctx, err := config.CopyTransactionState(cctx)
+	if err != nil {
+		msg := "Problem with copying the DDNS domains transaction state"
+		log.WithError(err).Error(msg)
+		return nil, http.StatusInternalServerError, msg
+	}
+	module := r.ConfigManager.GetKeaModule()
+	for _, restDomain := range changes.Deleted {
+		direction, domain := convertDDNSDomainFromRestAPI(restDomain)
+		if cctx, err = module.ApplyDDNSDomainDelete(cctx, direction, domain.Name); err != nil {
+			break
+		}
+	}
+	for i := 0; err == nil && i < len(changes.Updated); i++ {
+		direction, domain := convertDDNSDomainFromRestAPI(changes.Updated[i])
+		cctx, err = module.ApplyDDNSDomainUpdate(cctx, direction, domain)
+	}
+	for i := 0; err == nil && i < len(changes.Added); i++ {
+		direction, domain := convertDDNSDomainFromRestAPI(changes.Added[i])
+		cctx, err = module.ApplyDDNSDomainAdd(cctx, direction, domain)
+	}
+	if err != nil {
+		var (
+			domainExists   *config.DDNSDomainExistsError
+			domainNotFound *config.DDNSDomainNotFoundError
+			invalidDomain  *config.InvalidDDNSDomainError
+		)
+		msg := fmt.Sprintf("Problem with applying DDNS domain changes: %s", err)
+		log.WithError(err).Error(msg)
+		switch {
+		case errors.As(err, &domainExists):
+			return nil, http.StatusConflict, msg
+		case errors.As(err, &domainNotFound):
+			return nil, http.StatusNotFound, msg
+		case errors.As(err, &invalidDomain):
+			return nil, http.StatusBadRequest, msg
+		default:
+			return nil, http.StatusInternalServerError, msg
+		}
+	}
+	return cctx, 0, ""
+}
+
+// Common function that applies and commits the DDNS domain changes. The
+// scheduledAt parameter optionally specifies the time when the changes
+// should be committed. If it is nil, the changes are committed immediately.
+// It returns the HTTP error code if an error occurs or 0 when there is no
+// error. It also returns an error string to be included in the HTTP response
+// or an empty string if there is no error.
+func (r *RestAPI) commonUpdateDDNSDomainsSubmit(ctx context.Context, daemonID, transactionID int64, changes *models.DDNSDomainsChanges, scheduledAt *strfmt.DateTime) (int, string) {
+	if code, msg := validateConfigChangeDeadline(scheduledAt); code != 0 {
+		return code, msg
+	}
+	cctx, code, msg := r.commonUpdateDDNSDomainsApply(ctx, daemonID, transactionID, changes)
+	if code != 0 {
+		return code, msg
+	}
+	// Send the commands to Kea servers or schedule sending them.
+	cctx, err := r.commitOrScheduleConfigChanges(cctx, scheduledAt)
+	if err != nil {
+		msg := fmt.Sprintf("Problem with committing DDNS domain changes: %s", err)
+		log.WithError(err).Error(msg)
+		return http.StatusConflict, msg
+	}
+	// Everything ok. Cleanup and send OK to the client.
+	r.ConfigManager.Done(cctx)
+	return 0, ""
+}
@@ -0,0 +1,194 @@
+package restservice
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	keaconfig "isc.org/stork/appcfg/kea"
+	"isc.org/stork/server/apps/kea"
+	dbops "isc.org/stork/server/database"
+	dbmodel "isc.org/stork/server/database/model"
+	dbmodeltest "isc.org/stork/server/database/model/test"
+	dbtest "isc.org/stork/server/database/test"
+	"isc.org/stork/server/gen/models"
+	dhcp "isc.org/stork/server/gen/restapi/operations/d_h_c_p"
+	storktest "isc.org/stork/server/test/dbmodel"
+	storkutil "isc.org/stork/util"
+)
+
+// Adds a D2 server with the DDNS domains to the database. It returns
+// the daemon ID.
+func addTestDDNSDomainsServer(t *testing.T, db *dbops.PgDB) int64 {
+	server, err := dbmodeltest.NewKeaD2Server(db)
+	require.NoError(t, err)
+	err = server.Configure(`{
+		"DhcpDdns": {
+			"tsig-keys": [
+				{
+					"name": "key1",
+					"algorithm": "HMAC-SHA256",
+					"secret": "LSWXnfkKZjdPJI5QxlpnfQ=="
+				}
+			],
+			"forward-ddns": {
+				"ddns-domains": [
+					{
+						"name": "example.org.",
+						"key-name": "key1",
+						"dns-servers": [
+							{
+								"ip-address": "192.0.2.1",
+								"port": 53
+							}
+						]
+					}
+				]
+			}
+		}
+	}`)
+	require.NoError(t, err)
+
+	app, err := server.GetKea()
+	require.NoError(t, err)
+
+	err = kea.CommitAppIntoDB(db, app, &storktest.FakeEventCenter{}, nil, dbmodel.NewDHCPOptionDefinitionLookup())
+	require.NoError(t, err)
+	return server.ID
+}
+
+// Test getting the DDNS domains of the D2 daemon.
+func TestGetDDNSDomains(t *testing.T) {
+	db, dbSettings, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	daemonID := addTestDDNSDomainsServer(t, db)
+
+	rapi, _, ctx := newTestClientClassRestAPI(t, db, dbSettings)
+
+	rsp := rapi.GetDDNSDomains(ctx, dhcp.GetDDNSDomainsParams{
+		DaemonID: daemonID,
+	})
+	require.IsType(t, &dhcp.GetDDNSDomainsOK{}, rsp)
+	domains := rsp.(*dhcp.GetDDNSDomainsOK).Payload
+	require.EqualValues(t, 1, domains.Total)
+	require.Len(t, domains.Items, 1)
+	require.Equal(t, string(keaconfig.D2ForwardDDNS), *domains.Items[0].Direction)
+	require.Equal(t, "example.org.", *domains.Items[0].Name)
+	require.Equal(t, "key1", domains.Items[0].KeyName)
+	require.Len(t, domains.Items[0].DNSServers, 1)
+	require.Equal(t, "192.0.2.1", domains.Items[0].DNSServers[0].IPAddress)
+	require.EqualValues(t, 53, domains.Items[0].DNSServers[0].Port)
+
+	// Non-existing daemon.
+	rsp = rapi.GetDDNSDomains(ctx, dhcp.GetDDNSDomainsParams{
+		DaemonID: daemonID + 1000,
+	})
+	require.IsType(t, &dhcp.GetDDNSDomainsDefault{}, rsp)
+	require.Equal(t, http.StatusNotFound, getStatusCode(*rsp.(*dhcp.GetDDNSDomainsDefault)))
+}
+
+// Test adding, updating and deleting the DDNS domains in a transaction.
+func TestUpdateDDNSDomainsBeginSubmit(t *testing.T) {
+	db, dbSettings, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	daemonID := addTestDDNSDomainsServer(t, db)
+
+	rapi, fa, ctx := newTestClientClassRestAPI(t, db, dbSettings)
+
+	rsp := rapi.UpdateDDNSDomainsBegin(ctx, dhcp.UpdateDDNSDomainsBeginParams{
+		DaemonID: daemonID,
+	})
+	require.IsType(t, &dhcp.UpdateDDNSDomainsBeginOK{}, rsp)
+	contents := rsp.(*dhcp.UpdateDDNSDomainsBeginOK).Payload
+	require.NotZero(t, contents.ID)
+	require.EqualValues(t, 1, contents.Domains.Total)
+	require.Equal(t, []string{"key1"}, contents.TsigKeyNames)
+
+	// The domain refers to a non-existing TSIG key.
+	rsp = rapi.UpdateDDNSDomainsSubmit(ctx, dhcp.UpdateDDNSDomainsSubmitParams{
+		DaemonID: daemonID,
+		ID:       contents.ID,
+		Changes: &models.DDNSDomainsChanges{
+			Added: []*models.DDNSDomain{
+				{
+					Direction: storkutil.Ptr(string(keaconfig.D2ReverseDDNS)),
+					Name:      storkutil.Ptr("2.0.192.in-addr.arpa."),
+					KeyName:   "key2",
+					DNSServers: []*models.DDNSDomainServer{
+						{
+							IPAddress: "192.0.2.1",
+						},
+					},
+				},
+			},
+		},
+	})
+	require.IsType(t, &dhcp.UpdateDDNSDomainsSubmitDefault{}, rsp)
+	require.Equal(t, http.StatusBadRequest, getStatusCode(*rsp.(*dhcp.UpdateDDNSDomainsSubmitDefault)))
+	require.Empty(t, fa.RecordedCommands)
+
+	rsp = rapi.UpdateDDNSDomainsSubmit(ctx, dhcp.UpdateDDNSDomainsSubmitParams{
+		DaemonID: daemonID,
+		ID:       contents.ID,
+		Changes: &models.DDNSDomainsChanges{
+			Added: []*models.DDNSDomain{
+				{
+					Direction: storkutil.Ptr(string(keaconfig.D2ReverseDDNS)),
+					Name:      storkutil.Ptr("2.0.192.in-addr.arpa."),
+					KeyName:   "key1",
+					DNSServers: []*models.DDNSDomainServer{
+						{
+							IPAddress: "192.0.2.1",
+						},
+					},
+				},
+			},
+			Deleted: []*models.DDNSDomain{
+				{
+					Direction: storkutil.Ptr(string(keaconfig.D2ForwardDDNS)),
+					Name:      storkutil.Ptr("example.org."),
+				},
+			},
+		},
+	})
+	require.IsType(t, &dhcp.UpdateDDNSDomainsSubmitOK{}, rsp)
+	require.Len(t, fa.RecordedCommands, 2)
+
+	domains, err := dbmodel.GetDDNSDomainsByDaemonID(db, daemonID)
+	require.NoError(t, err)
+	require.Len(t, domains, 1)
+	require.Equal(t, keaconfig.D2ReverseDDNS, domains[0].Direction)
+	require.Equal(t, "2.0.192.in-addr.arpa.", domains[0].Name)
+}
+
+// Test that the transaction to update the DDNS domains can be canceled.
+func TestUpdateDDNSDomainsBeginCancel(t *testing.T) {
+	db, dbSettings, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	daemonID := addTestDDNSDomainsServer(t, db)
+
+	rapi, _, ctx := newTestClientClassRestAPI(t, db, dbSettings)
+
+	rsp := rapi.UpdateDDNSDomainsBegin(ctx, dhcp.UpdateDDNSDomainsBeginParams{
+		DaemonID: daemonID,
+	})
+	require.IsType(t, &dhcp.UpdateDDNSDomainsBeginOK{}, rsp)
+	transactionID := rsp.(*dhcp.UpdateDDNSDomainsBeginOK).Payload.ID
+
+	rsp = rapi.UpdateDDNSDomainsDelete(ctx, dhcp.UpdateDDNSDomainsDeleteParams{
+		DaemonID: daemonID,
+		ID:       transactionID,
+	})
+	require.IsType(t, &dhcp.UpdateDDNSDomainsDeleteOK{}, rsp)
+
+	// The transaction no longer exists.
+	rsp = rapi.UpdateDDNSDomainsDelete(ctx, dhcp.UpdateDDNSDomainsDeleteParams{
+		DaemonID: daemonID,
+		ID:       transactionID,
+	})
+	require.IsType(t, &dhcp.UpdateDDNSDomainsDeleteDefault{}, rsp)
+	require.Equal(t, http.StatusNotFound, getStatusCode(*rsp.(*dhcp.UpdateDDNSDomainsDeleteDefault)))
+}
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/go-openapi/runtime/middleware"
+	"github.com/go-openapi/strfmt"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 
@@ -350,26 +351,26 @@ func (r *RestAPI) CreateHostBegin(ctx context.Context, params dhcp.CreateHostBeg
 	return rsp
 }
 
-// Common function that implements the POST calls to apply and commit a new
-// or updated reservation. The ctx parameter is the REST API context. The
+// Common function that applies a new or updated reservation in the
+// transaction context. The ctx parameter is the REST API context. The
 // transactionID is the identifier of the current configuration transaction
 // used by the function to recover the transaction context. The restHost is
 // the pointer to the host reservation specified by the user. It is converted
 // by this function to the database model. The applyFunc is the function of
 // of the Kea config module that applies the specified reservation. It is
 // one of the ApplyHostAdd or ApplyHostUpdate, depending on whether the
-// new host is created (via CreateHostSubmit) or updated (via UpdateHostSubmit).
-// The apply functions receive the transaction context and a pointer to the
-// host reservation. They return the updated context and error. This function
-// returns the HTTP error code if an error occurs or 0 when there is no error.
-// In addition it returns an error string to be included in the HTTP response
-// or an empty string if there is no error.
-func (r *RestAPI) commonCreateOrUpdateHostSubmit(ctx context.Context, transactionID int64, restHost *models.Host, applyFunc func(context.Context, *dbmodel.Host) (context.Context, error)) (int, string) {
+// new host is created or updated. The apply functions receive the transaction
+// context and a pointer to the host reservation. They return the updated
+// context and error. This function returns the transaction context with the
+// applied changes. It returns the HTTP error code if an error occurs or 0
+// when there is no error. In addition it returns an error string to be
+// included in the HTTP response or an empty string if there is no error.
+func (r *RestAPI) commonCreateOrUpdateHostApply(ctx context.Context, transactionID int64, restHost *models.Host, applyFunc func(context.Context, *dbmodel.Host) (context.Context, error)) (context.Context, int, string) {
 	// Make sure that the host information is present.
 	if restHost == nil {
 		msg := "Host information not specified"
 		log.Errorf("Problem with submitting a host reservation because the host information is missing")
-		return http.StatusBadRequest, msg
+		return nil, http.StatusBadRequest, msg
 	}
 	// Retrieve the context from the config manager.
 	_, user := r.SessionManager.Logged(ctx)
@@ -377,7 +378,15 @@ func (r *RestAPI) commonCreateOrUpdateHostSubmit(ctx context.Context, transactio
 	if cctx == nil {
 		msg := "Transaction for host reservation expired"
 		log.Errorf("Problem with recovering transaction context for transaction ID %d and user ID %d", transactionID, user.ID)
-		return http.StatusNotFound, msg
+		return nil, http.StatusNotFound, msg
+	}
+	// Apply the changes to a copy of the transaction state. The transaction
+	// remembered by the config manager is only updated upon commit.
+	cctx, err := config.CopyTransactionState(cctx)
+	if err != nil {
+		msg := "Problem with copying the host reservation transaction state"
+		log.WithError(err).Error(msg)
+		return nil, http.StatusInternalServerError, msg
 	}
 
 	// Convert host information from REST API to database format.
@@ -385,29 +394,48 @@ func (r *RestAPI) commonCreateOrUpdateHostSubmit(ctx context.Context, transactio
 	if err != nil {
 		msg := fmt.Sprintf("Error parsing specified host reservation: %s", err)
 		log.WithError(err).Error(msg)
-		return http.StatusBadRequest, msg
+		return nil, http.StatusBadRequest, msg
 	}
 	err = host.PopulateDaemons(r.DB)
 	if err != nil {
 		msg := "Specified host is associated with daemons that no longer exist"
 		log.WithError(err).Error(msg)
-		return http.StatusNotFound, msg
+		return nil, http.StatusNotFound, msg
 	}
 	err = host.PopulateSubnet(r.DB)
 	if err != nil {
 		msg := "Problem with retrieving subnet association with the host"
 		log.WithError(err).Error(msg)
-		return http.StatusInternalServerError, msg
+		return nil, http.StatusInternalServerError, msg
 	}
 	// Apply the host information (create Kea commands).
 	cctx, err = applyFunc(cctx, host)
 	if err != nil {
 		msg := fmt.Sprintf("Problem with applying host information: %s", err)
 		log.WithError(err).Error(msg)
-		return http.StatusInternalServerError, msg
+		return nil, http.StatusInternalServerError, msg
 	}
-	// Send the commands to Kea servers.
-	cctx, err = r.ConfigManager.Commit(cctx)
+	return cctx, 0, ""
+}
+
+// Common function that implements the POST calls to apply and commit a new
+// or updated reservation. The parameters are described in the documentation
+// of the commonCreateOrUpdateHostApply function. This function returns the
+// HTTP error code if an error occurs or 0 when there is no error. In addition
+// it returns an error string to be included in the HTTP response or an empty
+// string if there is no error.
+// The scheduledAt parameter optionally specifies the time when the changes
+// should be committed. If it is nil, the changes are committed immediately.
+func (r *RestAPI) commonCreateOrUpdateHostSubmit(ctx context.Context, transactionID int64, restHost *models.Host, applyFunc func(context.Context, *dbmodel.Host) (context.Context, error), scheduledAt *strfmt.DateTime) (int, string) {
+	if code, msg := validateConfigChangeDeadline(scheduledAt); code != 0 {
+		return code, msg
+	}
+	cctx, code, msg := r.commonCreateOrUpdateHostApply(ctx, transactionID, restHost, applyFunc)
+	if code != 0 {
+		return code, msg
+	}
+	// Send the commands to Kea servers or schedule sending them.
+	cctx, err := r.commitOrScheduleConfigChanges(cctx, scheduledAt)
 	if err != nil {
 		msg := fmt.Sprintf("Problem with committing host information: %s", err)
 		log.WithError(err).Error(msg)
@@ -418,9 +446,25 @@ func (r *RestAPI) commonCreateOrUpdateHostSubmit(ctx context.Context, transactio
 	return 0, ""
 }
 
+// Common function that implements the POST calls to preview the changes of
+// a new or updated reservation. The parameters are described in the
+// documentation of the commonCreateOrUpdateHostApply function. The changes
+// are neither committed nor remembered in the transaction. This function
+// returns the preview of the changes. It returns the HTTP error code if an
+// error occurs or 0 when there is no error. In addition it returns an error
+// string to be included in the HTTP response or an empty string if there is
+// no error.
+func (r *RestAPI) commonCreateOrUpdateHostPreview(ctx context.Context, transactionID int64, restHost *models.Host, applyFunc func(context.Context, *dbmodel.Host) (context.Context, error)) (*models.ConfigChangesPreview, int, string) {
+	cctx, code, msg := r.commonCreateOrUpdateHostApply(ctx, transactionID, restHost, applyFunc)
+	if code != 0 {
+		return nil, code, msg
+	}
+	return r.previewConfigChanges(ctx, cctx)
+}
+
 // Implements the POST call to apply and commit host reservation (hosts/new/transaction/{id}/submit).
 func (r *RestAPI) CreateHostSubmit(ctx context.Context, params dhcp.CreateHostSubmitParams) middleware.Responder {
-	if code, msg := r.commonCreateOrUpdateHostSubmit(ctx, params.ID, params.Host, r.ConfigManager.GetKeaModule().ApplyHostAdd); code != 0 {
+	if code, msg := r.commonCreateOrUpdateHostSubmit(ctx, params.ID, params.Host, r.ConfigManager.GetKeaModule().ApplyHostAdd, params.ScheduledAt); code != 0 {
 		// Error case.
 		rsp := dhcp.NewCreateHostSubmitDefault(code).WithPayload(&models.APIError{
 			Message: &msg,
@@ -431,6 +475,21 @@ func (r *RestAPI) CreateHostSubmit(ctx context.Context, params dhcp.CreateHostSu
 	return rsp
 }
 
+// Implements the POST call to preview the changes of the new host reservation
+// (hosts/new/transaction/{id}/preview).
+func (r *RestAPI) CreateHostPreview(ctx context.Context, params dhcp.CreateHostPreviewParams) middleware.Responder {
+	preview, code, msg := r.commonCreateOrUpdateHostPreview(ctx, params.ID, params.Host, r.ConfigManager.GetKeaModule().ApplyHostAdd)
+	if code != 0 {
+		// Error case.
+		rsp := dhcp.NewCreateHostPreviewDefault(code).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	rsp := dhcp.NewCreateHostPreviewOK().WithPayload(preview)
+	return rsp
+}
+
 // Common function that implements the DELETE calls to cancel adding new
 // or updating a host reservation. It removes the specified transaction
 // from the config manager, if the transaction exists. It  returns the
@@ -543,7 +602,7 @@ func (r *RestAPI) UpdateHostBegin(ctx context.Context, params dhcp.UpdateHostBeg
 
 // Implements the POST call and commit an updated host reservation (hosts/{hostId}/transaction/{id}/submit).
 func (r *RestAPI) UpdateHostSubmit(ctx context.Context, params dhcp.UpdateHostSubmitParams) middleware.Responder {
-	if code, msg := r.commonCreateOrUpdateHostSubmit(ctx, params.ID, params.Host, r.ConfigManager.GetKeaModule().ApplyHostUpdate); code != 0 {
+	if code, msg := r.commonCreateOrUpdateHostSubmit(ctx, params.ID, params.Host, r.ConfigManager.GetKeaModule().ApplyHostUpdate, params.ScheduledAt); code != 0 {
 		// Error case.
 		rsp := dhcp.NewUpdateHostSubmitDefault(code).WithPayload(&models.APIError{
 			Message: &msg,
@@ -554,6 +613,21 @@ func (r *RestAPI) UpdateHostSubmit(ctx context.Context, params dhcp.UpdateHostSu
 	return rsp
 }
 
+// Implements the POST call to preview the changes of the updated host
+// reservation (hosts/{hostId}/transaction/{id}/preview).
+func (r *RestAPI) UpdateHostPreview(ctx context.Context, params dhcp.UpdateHostPreviewParams) middleware.Responder {
+	preview, code, msg := r.commonCreateOrUpdateHostPreview(ctx, params.ID, params.Host, r.ConfigManager.GetKeaModule().ApplyHostUpdate)
+	if code != 0 {
+		// Error case.
+		rsp := dhcp.NewUpdateHostPreviewDefault(code).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	rsp := dhcp.NewUpdateHostPreviewOK().WithPayload(preview)
+	return rsp
+}
+
 // Implements the DELETE call to cancel updating host reservation (hosts/{hostId}/transaction/{id}).
 // It removes the specified transaction from the config manager, if the transaction exists.
 func (r *RestAPI) UpdateHostDelete(ctx context.Context, params dhcp.UpdateHostDeleteParams) middleware.Responder {
@@ -538,6 +538,68 @@ func TestCreateHostBeginSubmit(t *testing.T) {
 	}
 }
 
+// Test that the changes of the new host reservation can be previewed before
+// submitting them and that previewing doesn't send any commands.
+func TestCreateHostBeginPreview(t *testing.T) {
+	db, dbSettings, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	rapi, fa, ctx := newTestClientClassRestAPI(t, db, dbSettings)
+
+	// Make sure we have some Kea apps in the database.
+	hosts, apps := storktestdbmodel.AddTestHosts(t, db)
+	// Drop the hosts associations.
+	for _, host := range hosts {
+		_, _ = dbmodel.DeleteDaemonsFromHost(db, host.ID, dbmodel.HostDataSourceUnspecified)
+	}
+
+	// Begin transaction.
+	rsp := rapi.CreateHostBegin(ctx, dhcp.CreateHostBeginParams{})
+	require.IsType(t, &dhcp.CreateHostBeginOK{}, rsp)
+	transactionID := rsp.(*dhcp.CreateHostBeginOK).Payload.ID
+
+	// Preview the changes.
+	rsp = rapi.CreateHostPreview(ctx, dhcp.CreateHostPreviewParams{
+		ID: transactionID,
+		Host: &models.Host{
+			SubnetID: 1,
+			Hostname: "example.org",
+			HostIdentifiers: []*models.HostIdentifier{
+				{
+					IDType:     "hw-address",
+					IDHexValue: "010203040506",
+				},
+			},
+			LocalHosts: []*models.LocalHost{
+				{
+					DaemonID:   apps[0].Daemons[0].ID,
+					DataSource: dbmodel.HostDataSourceAPI.String(),
+				},
+			},
+		},
+	})
+	require.IsType(t, &dhcp.CreateHostPreviewOK{}, rsp)
+	preview := rsp.(*dhcp.CreateHostPreviewOK).Payload
+	require.Len(t, preview.Items, 1)
+	require.Equal(t, apps[0].Daemons[0].ID, preview.Items[0].DaemonID)
+	require.Len(t, preview.Items[0].Commands, 1)
+
+	// The whole reservation should be reported as added.
+	require.Len(t, preview.Items[0].Changes, 1)
+	change := preview.Items[0].Changes[0]
+	require.Equal(t, "/reservation", change.Path)
+	require.Equal(t, "add", change.Op)
+	require.Nil(t, change.Before)
+	require.IsType(t, map[string]any{}, change.After)
+	reservation := change.After.(map[string]any)
+	require.Equal(t, "example.org", reservation["hostname"])
+	require.Equal(t, "010203040506", reservation["hw-address"])
+	require.EqualValues(t, 111, reservation["subnet-id"])
+
+	// Nothing should have been sent to the servers.
+	require.Empty(t, fa.RecordedCommands)
+}
+
 // Test the calls for creating transaction and submitting a new host
 // reservation with the IP reservations and a hostname. The hostname and IP
 // reservations included in the local hosts should be ignored.
@@ -0,0 +1,220 @@
+package restservice
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-openapi/runtime/middleware"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	dbmodel "isc.org/stork/server/database/model"
+	"isc.org/stork/server/gen/models"
+	dhcp "isc.org/stork/server/gen/restapi/operations/d_h_c_p"
+	"isc.org/stork/server/hostio"
+)
+
+// Default number of host reservations validated and applied in a single
+// batch during the import.
+const defaultHostsImportBatchSize = 100
+
+// Statuses of the imported host reservations.
+const (
+	hostsImportStatusImported = "imported"
+	hostsImportStatusInvalid  = "invalid"
+	hostsImportStatusFailed   = "failed"
+)
+
+// Creates the host reservations in the Kea servers and in the database in
+// a dedicated config manager transaction. It returns an error if the
+// transaction cannot be created or committed.
+func (r *RestAPI) commitImportedHosts(userID int64, hosts []*dbmodel.Host) error {
+	cctx, err := r.ConfigManager.CreateContext(userID)
+	if err != nil {
+		return errors.WithMessage(err, "problem with creating transaction context")
+	}
+	if cctx, err = r.ConfigManager.GetKeaModule().BeginHostsImport(cctx); err != nil {
+		return errors.WithMessage(err, "problem with initializing transaction")
+	}
+	if cctx, err = r.ConfigManager.GetKeaModule().ApplyHostsImport(cctx, hosts); err != nil {
+		return errors.WithMessage(err, "problem with preparing commands")
+	}
+	if _, err = r.ConfigManager.Commit(cctx); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Implements the POST call to import host reservations in bulk (hosts/import).
+// The imported host reservations are processed in batches. The records in
+// a batch are validated first and the valid host reservations are then
+// applied via the config manager in a single transaction per batch. The
+// validation takes the host reservations applied in the previous batches
+// into account. The invalid host reservations don't stop the import. If the
+// transaction fails, all host reservations in the batch are reported as
+// failed, and the import continues with the next batch.
+func (r *RestAPI) ImportHosts(ctx context.Context, params dhcp.ImportHostsParams) middleware.Responder {
+	if params.Hosts == nil || params.Hosts.Format == nil || params.Hosts.Data == nil {
+		msg := "Imported host reservations not specified"
+		rsp := dhcp.NewImportHostsDefault(http.StatusBadRequest).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	format, err := hostio.ParseFormat(*params.Hosts.Format)
+	if err != nil {
+		msg := fmt.Sprintf("Problem with importing host reservations: %s", err)
+		rsp := dhcp.NewImportHostsDefault(http.StatusBadRequest).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	records, err := hostio.Read(strings.NewReader(*params.Hosts.Data), format)
+	if err != nil {
+		msg := fmt.Sprintf("Problem with importing host reservations: %s", err)
+		log.WithError(err).Error("Problem with parsing imported host reservations")
+		rsp := dhcp.NewImportHostsDefault(http.StatusBadRequest).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	batchSize := params.Hosts.BatchSize
+	if batchSize <= 0 {
+		batchSize = defaultHostsImportBatchSize
+	}
+
+	_, user := r.SessionManager.Logged(ctx)
+	validator := hostio.NewValidator(r.DB, r.DHCPOptionDefinitionLookup, params.Hosts.DaemonIds)
+
+	result := &models.HostsImportResult{
+		Total: int64(len(records)),
+		Rows:  []*models.HostsImportRowResult{},
+	}
+	for start := int64(0); start < int64(len(records)); start += batchSize {
+		end := min(start+batchSize, int64(len(records)))
+		batch := start/batchSize + 1
+
+		// Validate all records in the batch.
+		var hosts []*dbmodel.Host
+		var rows []*models.HostsImportRowResult
+		for i := start; i < end; i++ {
+			record := records[i]
+			row := &models.HostsImportRowResult{
+				Row:            i + 1,
+				Batch:          batch,
+				IdentifierType: record.IdentifierType,
+				Identifier:     record.Identifier,
+				Subnet:         record.Subnet,
+			}
+			rows = append(rows, row)
+			result.Rows = append(result.Rows, row)
+			host, err := validator.Validate(record)
+			if err != nil {
+				row.Status = hostsImportStatusInvalid
+				row.Error = err.Error()
+				result.Invalid++
+				continue
+			}
+			hosts = append(hosts, host)
+		}
+
+		// Select the valid host reservations which don't conflict with the
+		// existing ones. The rows in the batch were validated before
+		// accepting any of them, so they may also conflict with each other.
+		var batchHosts []*dbmodel.Host
+		var batchRows []*models.HostsImportRowResult
+		for _, row := range rows {
+			if row.Status == hostsImportStatusInvalid {
+				continue
+			}
+			host := hosts[0]
+			hosts = hosts[1:]
+			if err := validator.CheckConflicts(host); err != nil {
+				row.Status = hostsImportStatusInvalid
+				row.Error = err.Error()
+				result.Invalid++
+				continue
+			}
+			validator.Accept(host)
+			batchHosts = append(batchHosts, host)
+			batchRows = append(batchRows, row)
+		}
+
+		// Apply the selected host reservations.
+		if len(batchHosts) > 0 {
+			err := r.commitImportedHosts(int64(user.ID), batchHosts)
+			if err != nil {
+				log.WithError(err).Errorf("Problem with importing batch %d of host reservations", batch)
+			}
+			for i, row := range batchRows {
+				if err != nil {
+					validator.Revoke(batchHosts[i])
+					row.Status = hostsImportStatusFailed
+					row.Error = err.Error()
+					result.Failed++
+					continue
+				}
+				row.Status = hostsImportStatusImported
+				result.Imported++
+			}
+		}
+		log.WithFields(log.Fields{
+			"batch":    batch,
+			"imported": result.Imported,
+			"invalid":  result.Invalid,
+			"failed":   result.Failed,
+		}).Info("Processed batch of imported host reservations")
+	}
+
+	rsp := dhcp.NewImportHostsOK().WithPayload(result)
+	return rsp
+}
+
+// Implements the GET call to export all host reservations (hosts/export).
+// The host reservations are exported in the format that can be imported
+// with the ImportHosts call.
+func (r *RestAPI) ExportHosts(ctx context.Context, params dhcp.ExportHostsParams) middleware.Responder {
+	formatName := string(hostio.FormatCSV)
+	if params.Format != nil {
+		formatName = *params.Format
+	}
+	format, err := hostio.ParseFormat(formatName)
+	if err != nil {
+		msg := fmt.Sprintf("Problem with exporting host reservations: %s", err)
+		rsp := dhcp.NewExportHostsDefault(http.StatusBadRequest).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	var buffer bytes.Buffer
+	if _, err = hostio.Export(r.DB, r.DHCPOptionDefinitionLookup, &buffer, format); err != nil {
+		msg := "Problem with exporting host reservations"
+		log.WithError(err).Error(msg)
+		rsp := dhcp.NewExportHostsDefault(http.StatusInternalServerError).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+
+	contentType := "text/csv"
+	if format == hostio.FormatJSON {
+		contentType = "application/json"
+	}
+	dispositionHeaderValue := fmt.Sprintf(
+		"attachment; filename=\"stork-hosts_%s.%s\"",
+		strings.ReplaceAll(time.Now().UTC().Format(time.RFC3339), ":", "-"),
+		format,
+	)
+
+	rsp := dhcp.
+		NewExportHostsOK().
+		WithContentType(contentType).
+		WithContentDisposition(dispositionHeaderValue).
+		WithPayload(io.NopCloser(&buffer))
+	return rsp
+}
@@ -0,0 +1,345 @@
+package restservice
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	agentcommtest "isc.org/stork/server/agentcomm/test"
+	"isc.org/stork/server/apps"
+	appstest "isc.org/stork/server/apps/test"
+	dbmodel "isc.org/stork/server/database/model"
+	dbtest "isc.org/stork/server/database/test"
+	"isc.org/stork/server/gen/models"
+	dhcp "isc.org/stork/server/gen/restapi/operations/d_h_c_p"
+	storkutil "isc.org/stork/util"
+)
+
+// Test importing host reservations in bulk and exporting them.
+func TestImportExportHosts(t *testing.T) {
+	db, dbSettings, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	daemons := addTestClientClassServers(t, db, `{
+		"Dhcp4": {
+			"subnet4": [
+				{
+					"id": 1,
+					"subnet": "192.0.2.0/24"
+				}
+			],
+			"hooks-libraries": [
+				{
+					"library": "/usr/lib/kea/libdhcp_host_cmds.so"
+				}
+			]
+		}
+	}`)
+
+	rapi, fa, ctx := newTestClientClassRestAPI(t, db, dbSettings)
+
+	// The first two host reservations are valid. The third one conflicts
+	// with the first one. The fourth one lacks the subnet and no daemons
+	// have been selected for the global reservations.
+	data := `identifier-type,identifier,subnet,ip-addresses,hostname,client-classes
+hw-address,01:02:03:04:05:06,192.0.2.0/24,192.0.2.10,foo.example.org,"foo,bar"
+hw-address,01:02:03:04:05:07,192.0.2.0/24,192.0.2.11,,
+hw-address,01:02:03:04:05:08,192.0.2.0/24,192.0.2.10,,
+hw-address,01:02:03:04:05:09,,,,
+`
+	rsp := rapi.ImportHosts(ctx, dhcp.ImportHostsParams{
+		Hosts: &models.HostsImport{
+			Format:    storkutil.Ptr("csv"),
+			Data:      storkutil.Ptr(data),
+			BatchSize: 2,
+		},
+	})
+	require.IsType(t, &dhcp.ImportHostsOK{}, rsp)
+	result := rsp.(*dhcp.ImportHostsOK).Payload
+	require.EqualValues(t, 4, result.Total)
+	require.EqualValues(t, 2, result.Imported)
+	require.EqualValues(t, 2, result.Invalid)
+	require.Zero(t, result.Failed)
+	require.Len(t, result.Rows, 4)
+
+	require.EqualValues(t, 1, result.Rows[0].Row)
+	require.EqualValues(t, 1, result.Rows[0].Batch)
+	require.Equal(t, "imported", result.Rows[0].Status)
+	require.Empty(t, result.Rows[0].Error)
+
+	require.EqualValues(t, 1, result.Rows[1].Batch)
+	require.Equal(t, "imported", result.Rows[1].Status)
+
+	require.EqualValues(t, 2, result.Rows[2].Batch)
+	require.Equal(t, "invalid", result.Rows[2].Status)
+	require.Contains(t, result.Rows[2].Error, "192.0.2.10 is already reserved")
+
+	require.EqualValues(t, 4, result.Rows[3].Row)
+	require.Equal(t, "invalid", result.Rows[3].Status)
+	require.Contains(t, result.Rows[3].Error, "no daemons selected")
+
+	// The reservation-add commands should be sent for the valid hosts.
+	require.Len(t, fa.RecordedCommands, 2)
+	for _, command := range fa.RecordedCommands {
+		require.EqualValues(t, "reservation-add", command.GetCommand())
+	}
+
+	// The hosts should be added to the database.
+	hosts, _, err := dbmodel.GetHostsByDaemonID(db, daemons[0].ID, dbmodel.HostDataSourceAPI)
+	require.NoError(t, err)
+	require.Len(t, hosts, 2)
+
+	// Export the hosts.
+	rsp = rapi.ExportHosts(ctx, dhcp.ExportHostsParams{
+		Format: storkutil.Ptr("json"),
+	})
+	require.IsType(t, &dhcp.ExportHostsOK{}, rsp)
+	okRsp := rsp.(*dhcp.ExportHostsOK)
+	require.Equal(t, "application/json", okRsp.ContentType)
+	require.Contains(t, okRsp.ContentDisposition, ".json")
+	exported, err := io.ReadAll(okRsp.Payload)
+	require.NoError(t, err)
+	require.JSONEq(t, `[
+		{
+			"identifier-type": "hw-address",
+			"identifier": "01:02:03:04:05:06",
+			"subnet": "192.0.2.0/24",
+			"ip-addresses": ["192.0.2.10"],
+			"hostname": "foo.example.org",
+			"client-classes": ["foo", "bar"]
+		},
+		{
+			"identifier-type": "hw-address",
+			"identifier": "01:02:03:04:05:07",
+			"subnet": "192.0.2.0/24",
+			"ip-addresses": ["192.0.2.11"]
+		}
+	]`, string(exported))
+}
+
+// Test that a host reservation which failed to commit doesn't cause
+// conflicts with the subsequent host reservations.
+func TestImportHostsCommitFailure(t *testing.T) {
+	db, dbSettings, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	daemons := addTestClientClassServers(t, db, `{
+		"Dhcp4": {
+			"subnet4": [
+				{
+					"id": 1,
+					"subnet": "192.0.2.0/24"
+				}
+			],
+			"hooks-libraries": [
+				{
+					"library": "/usr/lib/kea/libdhcp_host_cmds.so"
+				}
+			]
+		}
+	}`)
+
+	// The first reservation-add command fails.
+	fa := agentcommtest.NewFakeAgents(func(callNo int, cmdResponses []interface{}) {
+		if callNo == 0 {
+			mockStatusError("reservation-add", cmdResponses)
+		}
+	}, nil)
+	lookup := dbmodel.NewDHCPOptionDefinitionLookup()
+	cm := apps.NewManager(&appstest.ManagerAccessorsWrapper{
+		DB:        db,
+		Agents:    fa,
+		DefLookup: lookup,
+	})
+	rapi, err := NewRestAPI(dbSettings, db, fa, cm, lookup)
+	require.NoError(t, err)
+	ctx, err := rapi.SessionManager.Load(context.Background(), "")
+	require.NoError(t, err)
+	err = rapi.SessionManager.LoginHandler(ctx, &dbmodel.SystemUser{ID: 1234})
+	require.NoError(t, err)
+
+	// The host reservations reserve the same address. The second one
+	// conflicts with the first one in the same batch. The first one fails
+	// to commit, so the third one in the next batch doesn't conflict with
+	// it.
+	data := `identifier-type,identifier,subnet,ip-addresses
+hw-address,01:02:03:04:05:06,192.0.2.0/24,192.0.2.10
+hw-address,01:02:03:04:05:07,192.0.2.0/24,192.0.2.10
+hw-address,01:02:03:04:05:08,192.0.2.0/24,192.0.2.10
+`
+	rsp := rapi.ImportHosts(ctx, dhcp.ImportHostsParams{
+		Hosts: &models.HostsImport{
+			Format:    storkutil.Ptr("csv"),
+			Data:      storkutil.Ptr(data),
+			BatchSize: 2,
+		},
+	})
+	require.IsType(t, &dhcp.ImportHostsOK{}, rsp)
+	result := rsp.(*dhcp.ImportHostsOK).Payload
+	require.EqualValues(t, 1, result.Imported)
+	require.EqualValues(t, 1, result.Invalid)
+	require.EqualValues(t, 1, result.Failed)
+	require.Len(t, result.Rows, 3)
+
+	require.Equal(t, "failed", result.Rows[0].Status)
+	require.Equal(t, "invalid", result.Rows[1].Status)
+	require.Contains(t, result.Rows[1].Error, "192.0.2.10 is already reserved")
+	require.Equal(t, "imported", result.Rows[2].Status)
+
+	hosts, _, err := dbmodel.GetHostsByDaemonID(db, daemons[0].ID, dbmodel.HostDataSourceAPI)
+	require.NoError(t, err)
+	require.Len(t, hosts, 1)
+	require.Equal(t, []byte{1, 2, 3, 4, 5, 8}, hosts[0].HostIdentifiers[0].Value)
+}
+
+// Test that the host reservations in a batch are committed in a single
+// transaction and that all of them fail when the transaction fails.
+func TestImportHostsBatchCommitFailure(t *testing.T) {
+	db, dbSettings, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	daemons := addTestClientClassServers(t, db, `{
+		"Dhcp4": {
+			"subnet4": [
+				{
+					"id": 1,
+					"subnet": "192.0.2.0/24"
+				}
+			],
+			"hooks-libraries": [
+				{
+					"library": "/usr/lib/kea/libdhcp_host_cmds.so"
+				}
+			]
+		}
+	}`)
+
+	// The second reservation-add command fails.
+	fa := agentcommtest.NewFakeAgents(func(callNo int, cmdResponses []interface{}) {
+		if callNo == 1 {
+			mockStatusError("reservation-add", cmdResponses)
+		}
+	}, nil)
+	lookup := dbmodel.NewDHCPOptionDefinitionLookup()
+	cm := apps.NewManager(&appstest.ManagerAccessorsWrapper{
+		DB:        db,
+		Agents:    fa,
+		DefLookup: lookup,
+	})
+	rapi, err := NewRestAPI(dbSettings, db, fa, cm, lookup)
+	require.NoError(t, err)
+	ctx, err := rapi.SessionManager.Load(context.Background(), "")
+	require.NoError(t, err)
+	err = rapi.SessionManager.LoginHandler(ctx, &dbmodel.SystemUser{ID: 1234})
+	require.NoError(t, err)
+
+	// The first batch fails as a whole. The second batch is imported.
+	data := `identifier-type,identifier,subnet,ip-addresses
+hw-address,01:02:03:04:05:06,192.0.2.0/24,192.0.2.10
+hw-address,01:02:03:04:05:07,192.0.2.0/24,192.0.2.11
+hw-address,01:02:03:04:05:08,192.0.2.0/24,192.0.2.10
+`
+	rsp := rapi.ImportHosts(ctx, dhcp.ImportHostsParams{
+		Hosts: &models.HostsImport{
+			Format:    storkutil.Ptr("csv"),
+			Data:      storkutil.Ptr(data),
+			BatchSize: 2,
+		},
+	})
+	require.IsType(t, &dhcp.ImportHostsOK{}, rsp)
+	result := rsp.(*dhcp.ImportHostsOK).Payload
+	require.EqualValues(t, 1, result.Imported)
+	require.Zero(t, result.Invalid)
+	require.EqualValues(t, 2, result.Failed)
+	require.Len(t, result.Rows, 3)
+
+	require.Equal(t, "failed", result.Rows[0].Status)
+	require.NotEmpty(t, result.Rows[0].Error)
+	require.Equal(t, "failed", result.Rows[1].Status)
+	require.Equal(t, result.Rows[0].Error, result.Rows[1].Error)
+	require.Equal(t, "imported", result.Rows[2].Status)
+
+	// The commands should be sent in a single transaction per batch.
+	require.Len(t, fa.RecordedCommands, 3)
+
+	hosts, _, err := dbmodel.GetHostsByDaemonID(db, daemons[0].ID, dbmodel.HostDataSourceAPI)
+	require.NoError(t, err)
+	require.Len(t, hosts, 1)
+	require.Equal(t, []byte{1, 2, 3, 4, 5, 8}, hosts[0].HostIdentifiers[0].Value)
+}
+
+// Test that the rows validated in the same batch are checked for the
+// conflicts with each other before committing them.
+func TestImportHostsConflictsInBatch(t *testing.T) {
+	db, dbSettings, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	_ = addTestClientClassServers(t, db, `{
+		"Dhcp4": {
+			"subnet4": [
+				{
+					"id": 1,
+					"subnet": "192.0.2.0/24"
+				}
+			],
+			"hooks-libraries": [
+				{
+					"library": "/usr/lib/kea/libdhcp_host_cmds.so"
+				}
+			]
+		}
+	}`)
+
+	rapi, fa, ctx := newTestClientClassRestAPI(t, db, dbSettings)
+
+	data := `identifier-type,identifier,subnet,ip-addresses
+hw-address,01:02:03:04:05:06,192.0.2.0/24,192.0.2.10
+hw-address,01:02:03:04:05:06,192.0.2.0/24,192.0.2.11
+`
+	rsp := rapi.ImportHosts(ctx, dhcp.ImportHostsParams{
+		Hosts: &models.HostsImport{
+			Format: storkutil.Ptr("csv"),
+			Data:   storkutil.Ptr(data),
+		},
+	})
+	require.IsType(t, &dhcp.ImportHostsOK{}, rsp)
+	result := rsp.(*dhcp.ImportHostsOK).Payload
+	require.EqualValues(t, 1, result.Imported)
+	require.EqualValues(t, 1, result.Invalid)
+	require.Equal(t, "imported", result.Rows[0].Status)
+	require.Equal(t, "invalid", result.Rows[1].Status)
+	require.Contains(t, result.Rows[1].Error, "already exists")
+	require.Len(t, fa.RecordedCommands, 1)
+}
+
+// Test that importing malformed data fails.
+func TestImportHostsMalformed(t *testing.T) {
+	db, dbSettings, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	rapi, _, ctx := newTestClientClassRestAPI(t, db, dbSettings)
+
+	// No data.
+	rsp := rapi.ImportHosts(ctx, dhcp.ImportHostsParams{})
+	require.IsType(t, &dhcp.ImportHostsDefault{}, rsp)
+	require.Equal(t, http.StatusBadRequest, getStatusCode(*rsp.(*dhcp.ImportHostsDefault)))
+
+	// Malformed JSON.
+	rsp = rapi.ImportHosts(ctx, dhcp.ImportHostsParams{
+		Hosts: &models.HostsImport{
+			Format: storkutil.Ptr("json"),
+			Data:   storkutil.Ptr("{"),
+		},
+	})
+	require.IsType(t, &dhcp.ImportHostsDefault{}, rsp)
+	require.Equal(t, http.StatusBadRequest, getStatusCode(*rsp.(*dhcp.ImportHostsDefault)))
+
+	// Unsupported export format.
+	rsp = rapi.ExportHosts(ctx, dhcp.ExportHostsParams{
+		Format: storkutil.Ptr("xml"),
+	})
+	require.IsType(t, &dhcp.ExportHostsDefault{}, rsp)
+	require.Equal(t, http.StatusBadRequest, getStatusCode(*rsp.(*dhcp.ExportHostsDefault)))
+}
@@ -1,20 +1,80 @@
 package restservice
 
 import (
+	"bytes"
 	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
 	"net/http"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/go-openapi/runtime/middleware"
+	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 
+	keactrl "isc.org/stork/appctrl/kea"
 	"isc.org/stork/server/apps/kea"
 	dbmodel "isc.org/stork/server/database/model"
 	"isc.org/stork/server/gen/models"
 	dhcp "isc.org/stork/server/gen/restapi/operations/d_h_c_p"
+	storkutil "isc.org/stork/util"
 )
 
+// Converts the lease returned by Kea to the format used in the REST API.
+func convertLeaseToRestAPI(l *dbmodel.Lease) *models.Lease {
+	var appName string
+	if l.App != nil {
+		appName = l.App.Name
+	}
+	cltt := int64(l.CLTT)
+	state := int64(l.State)
+	subnetID := int64(l.SubnetID)
+	validLifetime := int64(l.ValidLifetime)
+
+	// Handle a special case when returned DUID is equal to 00. Kea returns such DUID
+	// in declined DHCPv6 leases. We treat is as empty DUID.
+	duid := ""
+	if len(l.DUID) > 0 && l.DUID != "00" {
+		duid = l.DUID
+	}
+	return &models.Lease{
+		ID:                &l.ID,
+		AppID:             &l.AppID,
+		AppName:           &appName,
+		ClientID:          l.ClientID,
+		Cltt:              &cltt,
+		Duid:              duid,
+		FqdnFwd:           l.FqdnFwd,
+		FqdnRev:           l.FqdnRev,
+		Hostname:          l.Hostname,
+		HwAddress:         l.HWAddress,
+		Iaid:              int64(l.IAID),
+		IPAddress:         &l.IPAddress,
+		LeaseType:         l.Type,
+		PreferredLifetime: int64(l.PreferredLifetime),
+		PrefixLength:      int64(l.PrefixLength),
+		State:             &state,
+		SubnetID:          &subnetID,
+		ValidLifetime:     &validLifetime,
+		UserContext:       l.UserContext,
+	}
+}
+
+// Converts the apps for which there was an error communicating with the
+// Kea servers to the format used in the REST API.
+func convertLeasesErredAppsToRestAPI(erredApps []*dbmodel.App) (restApps []*models.LeasesSearchErredApp) {
+	for i := range erredApps {
+		restApps = append(restApps, &models.LeasesSearchErredApp{
+			ID:   &erredApps[i].ID,
+			Name: &erredApps[i].Name,
+		})
+	}
+	return restApps
+}
+
 // This call searches for leases allocated by monitored DHCP servers.
 // The text parameter may contain an IP address, delegated prefix,
 // MAC address, client identifier, hostname or the text state:declined.
@@ -77,44 +137,7 @@ func (r *RestAPI) GetLeases(ctx context.Context, params dhcp.GetLeasesParams) mi
 
 	// Return leases over the REST API.
 	for i := range keaLeases {
-		l := keaLeases[i]
-		var appName string
-		if l.App != nil {
-			appName = l.App.Name
-		}
-		cltt := int64(l.CLTT)
-		state := int64(l.State)
-		subnetID := int64(l.SubnetID)
-		validLifetime := int64(l.ValidLifetime)
-
-		// Handle a special case when returned DUID is equal to 00. Kea returns such DUID
-		// in declined DHCPv6 leases. We treat is as empty DUID.
-		duid := ""
-		if len(l.DUID) > 0 && l.DUID != "00" {
-			duid = l.DUID
-		}
-		lease := models.Lease{
-			ID:                &l.ID,
-			AppID:             &l.AppID,
-			AppName:           &appName,
-			ClientID:          l.ClientID,
-			Cltt:              &cltt,
-			Duid:              duid,
-			FqdnFwd:           l.FqdnFwd,
-			FqdnRev:           l.FqdnRev,
-			Hostname:          l.Hostname,
-			HwAddress:         l.HWAddress,
-			Iaid:              int64(l.IAID),
-			IPAddress:         &l.IPAddress,
-			LeaseType:         l.Type,
-			PreferredLifetime: int64(l.PreferredLifetime),
-			PrefixLength:      int64(l.PrefixLength),
-			State:             &state,
-			SubnetID:          &subnetID,
-			ValidLifetime:     &validLifetime,
-			UserContext:       l.UserContext,
-		}
-		leases.Items = append(leases.Items, &lease)
+		leases.Items = append(leases.Items, convertLeaseToRestAPI(&keaLeases[i]))
 	}
 
 	// Record conflicting leases and leases count.
@@ -122,13 +145,400 @@ func (r *RestAPI) GetLeases(ctx context.Context, params dhcp.GetLeasesParams) mi
 	leases.Total = int64(len(leases.Items))
 
 	// Record apps for which there was an error communicating with the Kea servers.
-	for i := range erredApps {
-		leases.ErredApps = append(leases.ErredApps, &models.LeasesSearchErredApp{
-			ID:   &erredApps[i].ID,
-			Name: &erredApps[i].Name,
+	leases.ErredApps = convertLeasesErredAppsToRestAPI(erredApps)
+
+	rsp := dhcp.NewGetLeasesOK().WithPayload(leases)
+	return rsp
+}
+
+// Fetches the daemon to which the command manipulating the leases should be
+// sent. It returns the HTTP status code and the error message when the daemon
+// can't be fetched or it is not a Kea DHCP daemon.
+func (r *RestAPI) getLeaseDaemon(daemonID int64) (*dbmodel.Daemon, int, string) {
+	dbDaemon, err := dbmodel.GetDaemonByID(r.DB, daemonID)
+	if err != nil {
+		msg := fmt.Sprintf("Cannot get daemon with ID %d from db", daemonID)
+		log.WithError(err).Error(msg)
+		return nil, http.StatusInternalServerError, msg
+	}
+	if dbDaemon == nil {
+		msg := fmt.Sprintf("Cannot find daemon with ID %d", daemonID)
+		return nil, http.StatusNotFound, msg
+	}
+	if dbDaemon.KeaDaemon == nil || (dbDaemon.Name != dbmodel.DaemonNameDHCPv4 && dbDaemon.Name != dbmodel.DaemonNameDHCPv6) {
+		msg := fmt.Sprintf("Daemon with ID %d is not a Kea DHCP daemon", daemonID)
+		return nil, http.StatusBadRequest, msg
+	}
+	return dbDaemon, 0, ""
+}
+
+// Converts the lease received over the REST API to the lease parameters
+// sent to Kea.
+func convertLeaseFromRestAPI(lease *models.LeaseRequest) (*keactrl.LeaseParams, error) {
+	params := &keactrl.LeaseParams{
+		SubnetID:          lease.SubnetID,
+		HWAddress:         lease.HwAddress,
+		ClientID:          lease.ClientID,
+		DUID:              lease.Duid,
+		IAID:              lease.Iaid,
+		Type:              keactrl.LeaseType(lease.LeaseType),
+		PrefixLength:      lease.PrefixLength,
+		ValidLifetime:     lease.ValidLifetime,
+		PreferredLifetime: lease.PreferredLifetime,
+		Hostname:          lease.Hostname,
+		FqdnFwd:           lease.FqdnFwd,
+		FqdnRev:           lease.FqdnRev,
+		State:             lease.State,
+		ForceCreate:       lease.ForceCreate,
+	}
+	if lease.IPAddress != nil {
+		params.IPAddress = *lease.IPAddress
+	}
+	if lease.UserContext != nil {
+		userContext, ok := lease.UserContext.(map[string]any)
+		if !ok {
+			return nil, errors.New("user context must be a map")
+		}
+		params.UserContext = userContext
+	}
+	return params, nil
+}
+
+// Adds a lease to the Kea server. It sends the lease4-add or lease6-add
+// command depending on the daemon type.
+func (r *RestAPI) AddLease(ctx context.Context, params dhcp.AddLeaseParams) middleware.Responder {
+	if params.Lease == nil || params.Lease.DaemonID == nil || params.Lease.IPAddress == nil {
+		msg := "Daemon ID and IP address are required to add a lease"
+		rsp := dhcp.NewAddLeaseDefault(http.StatusBadRequest).WithPayload(&models.APIError{
+			Message: &msg,
 		})
+		return rsp
 	}
+	dbDaemon, code, msg := r.getLeaseDaemon(*params.Lease.DaemonID)
+	if code != 0 {
+		rsp := dhcp.NewAddLeaseDefault(code).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	lease, err := convertLeaseFromRestAPI(params.Lease)
+	if err != nil {
+		msg := fmt.Sprintf("Invalid lease: %s", err)
+		rsp := dhcp.NewAddLeaseDefault(http.StatusBadRequest).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	if err = kea.AddLease(r.Agents, dbDaemon, lease); err != nil {
+		msg := fmt.Sprintf("Problem with adding the lease %s: %s", lease.IPAddress, err)
+		log.WithError(err).Error(msg)
+		rsp := dhcp.NewAddLeaseDefault(http.StatusConflict).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	_, dbUser := r.SessionManager.Logged(ctx)
+	r.EventCenter.AddInfoEvent(fmt.Sprintf("{user} added lease %s in {daemon}", lease.IPAddress), dbUser, dbDaemon, dbDaemon.App, dbDaemon.App.Machine)
 
-	rsp := dhcp.NewGetLeasesOK().WithPayload(leases)
+	rsp := dhcp.NewAddLeaseOK()
+	return rsp
+}
+
+// Updates a lease in the Kea server. It sends the lease4-update or
+// lease6-update command depending on the daemon type.
+func (r *RestAPI) UpdateLease(ctx context.Context, params dhcp.UpdateLeaseParams) middleware.Responder {
+	if params.Lease == nil || params.Lease.DaemonID == nil || params.Lease.IPAddress == nil {
+		msg := "Daemon ID and IP address are required to update a lease"
+		rsp := dhcp.NewUpdateLeaseDefault(http.StatusBadRequest).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	dbDaemon, code, msg := r.getLeaseDaemon(*params.Lease.DaemonID)
+	if code != 0 {
+		rsp := dhcp.NewUpdateLeaseDefault(code).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	lease, err := convertLeaseFromRestAPI(params.Lease)
+	if err != nil {
+		msg := fmt.Sprintf("Invalid lease: %s", err)
+		rsp := dhcp.NewUpdateLeaseDefault(http.StatusBadRequest).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	if err = kea.UpdateLease(r.Agents, dbDaemon, lease); err != nil {
+		msg := fmt.Sprintf("Problem with updating the lease %s: %s", lease.IPAddress, err)
+		log.WithError(err).Error(msg)
+		rsp := dhcp.NewUpdateLeaseDefault(http.StatusConflict).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	_, dbUser := r.SessionManager.Logged(ctx)
+	r.EventCenter.AddInfoEvent(fmt.Sprintf("{user} updated lease %s in {daemon}", lease.IPAddress), dbUser, dbDaemon, dbDaemon.App, dbDaemon.App.Machine)
+
+	rsp := dhcp.NewUpdateLeaseOK()
+	return rsp
+}
+
+// Deletes a lease from the Kea server. It sends the lease4-del or lease6-del
+// command depending on the daemon type.
+func (r *RestAPI) DeleteLease(ctx context.Context, params dhcp.DeleteLeaseParams) middleware.Responder {
+	dbDaemon, code, msg := r.getLeaseDaemon(params.DaemonID)
+	if code != 0 {
+		rsp := dhcp.NewDeleteLeaseDefault(code).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	leaseType := keactrl.LeaseTypeNA
+	if params.LeaseType != nil {
+		leaseType = keactrl.LeaseType(*params.LeaseType)
+	}
+	deleted, err := kea.DeleteLease(r.Agents, dbDaemon, leaseType, params.IPAddress)
+	if err != nil {
+		msg := fmt.Sprintf("Problem with deleting the lease %s: %s", params.IPAddress, err)
+		log.WithError(err).Error(msg)
+		rsp := dhcp.NewDeleteLeaseDefault(http.StatusConflict).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	if !deleted {
+		msg := fmt.Sprintf("Cannot find the lease %s", params.IPAddress)
+		rsp := dhcp.NewDeleteLeaseDefault(http.StatusNotFound).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	_, dbUser := r.SessionManager.Logged(ctx)
+	r.EventCenter.AddInfoEvent(fmt.Sprintf("{user} deleted lease %s in {daemon}", params.IPAddress), dbUser, dbDaemon, dbDaemon.App, dbDaemon.App.Machine)
+
+	rsp := dhcp.NewDeleteLeaseOK()
+	return rsp
+}
+
+// Removes all DHCPv4 leases from the subnet or from all subnets in the Kea
+// server. This operation is only allowed for the super-admin users.
+func (r *RestAPI) WipeLeases(ctx context.Context, params dhcp.WipeLeasesParams) middleware.Responder {
+	_, dbUser := r.SessionManager.Logged(ctx)
+	if dbUser == nil || !dbUser.InGroup(&dbmodel.SystemGroup{ID: dbmodel.SuperAdminGroupID}) {
+		msg := "Only super-admin users are allowed to wipe the leases"
+		rsp := dhcp.NewWipeLeasesDefault(http.StatusForbidden).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	if params.Wipe == nil || params.Wipe.DaemonID == nil {
+		msg := "Daemon ID is required to wipe the leases"
+		rsp := dhcp.NewWipeLeasesDefault(http.StatusBadRequest).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	dbDaemon, code, msg := r.getLeaseDaemon(*params.Wipe.DaemonID)
+	if code != 0 {
+		rsp := dhcp.NewWipeLeasesDefault(code).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	if dbDaemon.Name != dbmodel.DaemonNameDHCPv4 {
+		msg := fmt.Sprintf("Wiping the leases is only supported by the %s daemon", dbmodel.DaemonNameDHCPv4)
+		rsp := dhcp.NewWipeLeasesDefault(http.StatusBadRequest).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	if err := kea.WipeLeases4(r.Agents, dbDaemon, params.Wipe.SubnetID); err != nil {
+		msg := fmt.Sprintf("Problem with wiping the leases: %s", err)
+		log.WithError(err).Error(msg)
+		rsp := dhcp.NewWipeLeasesDefault(http.StatusConflict).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	text := "{user} wiped all leases in {daemon}"
+	if params.Wipe.SubnetID > 0 {
+		text = fmt.Sprintf("{user} wiped leases in subnet %d in {daemon}", params.Wipe.SubnetID)
+	}
+	r.EventCenter.AddWarningEvent(text, dbUser, dbDaemon, dbDaemon.App, dbDaemon.App.Machine)
+
+	rsp := dhcp.NewWipeLeasesOK()
+	return rsp
+}
+
+// Creates the filter for selecting the subnet leases from the REST API
+// parameters.
+func newSubnetLeasesFilter(state, expiresAfter, expiresBefore *int64, pool *string) (*kea.LeaseFilter, error) {
+	filter := &kea.LeaseFilter{
+		ExpiresAfter:  expiresAfter,
+		ExpiresBefore: expiresBefore,
+	}
+	if state != nil {
+		filter.State = storkutil.Ptr(int(*state))
+	}
+	if pool != nil && len(strings.TrimSpace(*pool)) > 0 {
+		if err := filter.SetPool(strings.TrimSpace(*pool)); err != nil {
+			return nil, errors.WithMessagef(err, "invalid pool %s", *pool)
+		}
+	}
+	return filter, nil
+}
+
+// Fetches the subnet for which the leases should be returned. It returns
+// the HTTP status code and the error message when the subnet can't be
+// fetched.
+func (r *RestAPI) getLeasesSubnet(subnetID int64) (*dbmodel.Subnet, int, string) {
+	subnet, err := dbmodel.GetSubnet(r.DB, subnetID)
+	if err != nil {
+		msg := fmt.Sprintf("Cannot get subnet with ID %d from db", subnetID)
+		log.WithError(err).Error(msg)
+		return nil, http.StatusInternalServerError, msg
+	}
+	if subnet == nil {
+		return nil, http.StatusNotFound, fmt.Sprintf("Cannot find subnet with ID %d", subnetID)
+	}
+	return subnet, http.StatusOK, ""
+}
+
+// Returns the leases belonging to the subnet. It pages through the leases
+// held by all Kea servers serving the subnet.
+func (r *RestAPI) GetSubnetLeases(ctx context.Context, params dhcp.GetSubnetLeasesParams) middleware.Responder {
+	var start int64
+	if params.Start != nil {
+		start = *params.Start
+	}
+	var limit int64 = 10
+	if params.Limit != nil {
+		limit = *params.Limit
+	}
+
+	filter, err := newSubnetLeasesFilter(params.State, params.ExpiresAfter, params.ExpiresBefore, params.Pool)
+	if err != nil {
+		msg := fmt.Sprintf("Problem with getting subnet leases: %s", err)
+		rsp := dhcp.NewGetSubnetLeasesDefault(http.StatusBadRequest).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+
+	subnet, code, msg := r.getLeasesSubnet(params.ID)
+	if subnet == nil {
+		rsp := dhcp.NewGetSubnetLeasesDefault(code).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+
+	keaLeases, total, erredApps := kea.GetSubnetLeases(r.Agents, subnet, filter, start, limit)
+
+	leases := &models.Leases{
+		Total:     total,
+		ErredApps: convertLeasesErredAppsToRestAPI(erredApps),
+	}
+	for i := range keaLeases {
+		leases.Items = append(leases.Items, convertLeaseToRestAPI(&keaLeases[i]))
+	}
+	rsp := dhcp.NewGetSubnetLeasesOK().WithPayload(leases)
+	return rsp
+}
+
+// Writes the leases to the CSV file.
+func writeLeasesCSV(w io.Writer, leases []dbmodel.Lease) error {
+	writer := csv.NewWriter(w)
+	err := writer.Write([]string{
+		"address", "prefix_len", "type", "subnet_id", "hw_address",
+		"client_id", "duid", "iaid", "hostname", "state", "cltt",
+		"valid_lifetime", "expire", "app",
+	})
+	if err != nil {
+		return err
+	}
+	for _, lease := range leases {
+		var appName string
+		if lease.App != nil {
+			appName = lease.App.Name
+		}
+		err = writer.Write([]string{
+			lease.IPAddress,
+			fmt.Sprint(lease.PrefixLength),
+			lease.Type,
+			fmt.Sprint(lease.SubnetID),
+			lease.HWAddress,
+			lease.ClientID,
+			lease.DUID,
+			fmt.Sprint(lease.IAID),
+			lease.Hostname,
+			fmt.Sprint(lease.State),
+			fmt.Sprint(lease.CLTT),
+			fmt.Sprint(lease.ValidLifetime),
+			fmt.Sprint(lease.CLTT + uint64(lease.ValidLifetime)),
+			appName,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// Exports the leases belonging to the subnet to a CSV file.
+func (r *RestAPI) ExportSubnetLeases(ctx context.Context, params dhcp.ExportSubnetLeasesParams) middleware.Responder {
+	filter, err := newSubnetLeasesFilter(params.State, params.ExpiresAfter, params.ExpiresBefore, params.Pool)
+	if err != nil {
+		msg := fmt.Sprintf("Problem with exporting subnet leases: %s", err)
+		rsp := dhcp.NewExportSubnetLeasesDefault(http.StatusBadRequest).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+
+	subnet, code, msg := r.getLeasesSubnet(params.ID)
+	if subnet == nil {
+		rsp := dhcp.NewExportSubnetLeasesDefault(code).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+
+	keaLeases, erredApps := kea.GetAllSubnetLeases(r.Agents, subnet, filter)
+	if len(erredApps) > 0 {
+		var names []string
+		for _, app := range erredApps {
+			names = append(names, app.Name)
+		}
+		msg := fmt.Sprintf("Problem with fetching leases from %s", strings.Join(names, ", "))
+		rsp := dhcp.NewExportSubnetLeasesDefault(http.StatusConflict).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+
+	var buffer bytes.Buffer
+	if err = writeLeasesCSV(&buffer, keaLeases); err != nil {
+		msg := "Problem with exporting subnet leases"
+		log.WithError(err).Error(msg)
+		rsp := dhcp.NewExportSubnetLeasesDefault(http.StatusInternalServerError).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+
+	dispositionHeaderValue := fmt.Sprintf(
+		"attachment; filename=\"stork-leases_%d_%s.csv\"",
+		subnet.ID,
+		strings.ReplaceAll(time.Now().UTC().Format(time.RFC3339), ":", "-"),
+	)
+
+	rsp := dhcp.
+		NewExportSubnetLeasesOK().
+		WithContentType("text/csv").
+		WithContentDisposition(dispositionHeaderValue).
+		WithPayload(io.NopCloser(&buffer))
 	return rsp
 }
@@ -2,14 +2,21 @@ package restservice
 
 import (
 	"context"
+	"io"
+	"net/http"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
 	keactrl "isc.org/stork/appctrl/kea"
 	agentcommtest "isc.org/stork/server/agentcomm/test"
 	dbmodel "isc.org/stork/server/database/model"
+	dbmodeltest "isc.org/stork/server/database/model/test"
 	dbtest "isc.org/stork/server/database/test"
+	"isc.org/stork/server/gen/models"
 	dhcp "isc.org/stork/server/gen/restapi/operations/d_h_c_p"
+	storktest "isc.org/stork/server/test/dbmodel"
+	storkutil "isc.org/stork/util"
 )
 
 // Generates a success mock response to a command fetching a DHCPv4
@@ -716,3 +723,394 @@ func TestFindLeasesByHostID(t *testing.T) {
 	require.Len(t, okRsp.Payload.Conflicts, 1)
 	require.EqualValues(t, *okRsp.Payload.Items[1].ID, okRsp.Payload.Conflicts[0])
 }
+
+// Generates a success mock response to a command modifying a lease.
+func mockLeaseChange(callNo int, responses []interface{}) {
+	json := []byte(`[
+        {
+            "result": 0,
+            "text": "Lease changed"
+        }
+    ]`)
+	command := keactrl.NewCommandBase(keactrl.Lease4Update, keactrl.DHCPv4)
+	_ = keactrl.UnmarshalResponseList(command, json, responses[0])
+}
+
+// Generates a mock response to a command deleting a non-existing lease.
+func mockLeaseNotFound(callNo int, responses []interface{}) {
+	json := []byte(`[
+        {
+            "result": 3,
+            "text": "Lease not found"
+        }
+    ]`)
+	command := keactrl.NewCommandBase(keactrl.Lease4Del, keactrl.DHCPv4)
+	_ = keactrl.UnmarshalResponseList(command, json, responses[0])
+}
+
+// Creates the REST API instance and the DHCPv4 server with the lease_cmds
+// hooks library for the tests manipulating the leases. The logged user
+// belongs to the super-admin group if the superAdmin flag is true. It
+// returns the API, the fake agents, the fake event center, the context
+// with the logged user and the DHCPv4 daemon.
+func newTestLeaseRestAPI(t *testing.T, superAdmin bool, mock func(int, []interface{})) (*RestAPI, *agentcommtest.FakeAgents, *storktest.FakeEventCenter, context.Context, *dbmodel.Daemon) {
+	db, dbSettings, teardown := dbtest.SetupDatabaseTestCase(t)
+	t.Cleanup(teardown)
+
+	server, err := dbmodeltest.NewKeaDHCPv4Server(db)
+	require.NoError(t, err)
+	err = server.Configure(`{
+		"Dhcp4": {
+			"hooks-libraries": [
+				{
+					"library": "libdhcp_lease_cmds.so"
+				}
+			]
+		}
+	}`)
+	require.NoError(t, err)
+	daemon, err := dbmodel.GetDaemonByID(db, server.ID)
+	require.NoError(t, err)
+
+	agents := agentcommtest.NewFakeAgents(mock, nil)
+	eventCenter := &storktest.FakeEventCenter{}
+	rapi, err := NewRestAPI(dbSettings, db, agents, eventCenter)
+	require.NoError(t, err)
+
+	ctx, err := rapi.SessionManager.Load(context.Background(), "")
+	require.NoError(t, err)
+	user := &dbmodel.SystemUser{
+		ID: 1234,
+	}
+	if superAdmin {
+		user, err = dbmodel.GetUserByID(rapi.DB, 1)
+		require.NoError(t, err)
+	}
+	err = rapi.SessionManager.LoginHandler(ctx, user)
+	require.NoError(t, err)
+
+	return rapi, agents, eventCenter, ctx, daemon
+}
+
+// Test adding a lease over the REST API.
+func TestAddLease(t *testing.T) {
+	rapi, agents, eventCenter, ctx, daemon := newTestLeaseRestAPI(t, false, mockLeaseChange)
+
+	params := dhcp.AddLeaseParams{
+		Lease: &models.LeaseRequest{
+			DaemonID:      &daemon.ID,
+			IPAddress:     storkutil.Ptr("192.0.2.1"),
+			HwAddress:     "01:02:03:04:05:06",
+			ValidLifetime: storkutil.Ptr(int64(3600)),
+			UserContext: map[string]any{
+				"foo": "bar",
+			},
+		},
+	}
+	rsp := rapi.AddLease(ctx, params)
+	require.IsType(t, &dhcp.AddLeaseOK{}, rsp)
+
+	require.Len(t, agents.RecordedCommands, 1)
+	require.JSONEq(t, `{
+		"command": "lease4-add",
+		"service": [ "dhcp4" ],
+		"arguments": {
+			"ip-address": "192.0.2.1",
+			"hw-address": "01:02:03:04:05:06",
+			"valid-lft": 3600,
+			"user-context": {
+				"foo": "bar"
+			}
+		}
+	}`, agents.RecordedCommands[0].Marshal())
+
+	require.Len(t, eventCenter.Events, 1)
+	require.Contains(t, eventCenter.Events[0].Text, "added lease 192.0.2.1")
+	require.EqualValues(t, daemon.ID, eventCenter.Events[0].Relations.DaemonID)
+
+	// Non-existing daemon.
+	params.Lease.DaemonID = storkutil.Ptr(daemon.ID + 100)
+	rsp = rapi.AddLease(ctx, params)
+	require.IsType(t, &dhcp.AddLeaseDefault{}, rsp)
+	require.Equal(t, http.StatusNotFound, getStatusCode(*rsp.(*dhcp.AddLeaseDefault)))
+}
+
+// Test updating a lease over the REST API.
+func TestUpdateLease(t *testing.T) {
+	rapi, agents, eventCenter, ctx, daemon := newTestLeaseRestAPI(t, false, mockLeaseChange)
+
+	params := dhcp.UpdateLeaseParams{
+		Lease: &models.LeaseRequest{
+			DaemonID:    &daemon.ID,
+			IPAddress:   storkutil.Ptr("192.0.2.1"),
+			Hostname:    "myhost",
+			ForceCreate: true,
+		},
+	}
+	rsp := rapi.UpdateLease(ctx, params)
+	require.IsType(t, &dhcp.UpdateLeaseOK{}, rsp)
+
+	require.Len(t, agents.RecordedCommands, 1)
+	require.JSONEq(t, `{
+		"command": "lease4-update",
+		"service": [ "dhcp4" ],
+		"arguments": {
+			"ip-address": "192.0.2.1",
+			"hostname": "myhost",
+			"force-create": true
+		}
+	}`, agents.RecordedCommands[0].Marshal())
+
+	require.Len(t, eventCenter.Events, 1)
+	require.Contains(t, eventCenter.Events[0].Text, "updated lease 192.0.2.1")
+}
+
+// Test deleting a lease over the REST API.
+func TestDeleteLease(t *testing.T) {
+	rapi, agents, eventCenter, ctx, daemon := newTestLeaseRestAPI(t, false, mockLeaseChange)
+
+	params := dhcp.DeleteLeaseParams{
+		DaemonID:  daemon.ID,
+		IPAddress: "192.0.2.1",
+	}
+	rsp := rapi.DeleteLease(ctx, params)
+	require.IsType(t, &dhcp.DeleteLeaseOK{}, rsp)
+
+	require.Len(t, agents.RecordedCommands, 1)
+	require.JSONEq(t, `{
+		"command": "lease4-del",
+		"service": [ "dhcp4" ],
+		"arguments": {
+			"ip-address": "192.0.2.1"
+		}
+	}`, agents.RecordedCommands[0].Marshal())
+
+	require.Len(t, eventCenter.Events, 1)
+	require.Contains(t, eventCenter.Events[0].Text, "deleted lease 192.0.2.1")
+}
+
+// Test that deleting a non-existing lease returns the not found status.
+func TestDeleteLeaseNotFound(t *testing.T) {
+	rapi, _, eventCenter, ctx, daemon := newTestLeaseRestAPI(t, false, mockLeaseNotFound)
+
+	params := dhcp.DeleteLeaseParams{
+		DaemonID:  daemon.ID,
+		IPAddress: "192.0.2.1",
+	}
+	rsp := rapi.DeleteLease(ctx, params)
+	require.IsType(t, &dhcp.DeleteLeaseDefault{}, rsp)
+	require.Equal(t, http.StatusNotFound, getStatusCode(*rsp.(*dhcp.DeleteLeaseDefault)))
+	require.Empty(t, eventCenter.Events)
+}
+
+// Test wiping the leases over the REST API.
+func TestWipeLeases(t *testing.T) {
+	rapi, agents, eventCenter, ctx, daemon := newTestLeaseRestAPI(t, true, mockLeaseChange)
+
+	params := dhcp.WipeLeasesParams{
+		Wipe: &models.LeasesWipeRequest{
+			DaemonID: &daemon.ID,
+			SubnetID: 1,
+		},
+	}
+	rsp := rapi.WipeLeases(ctx, params)
+	require.IsType(t, &dhcp.WipeLeasesOK{}, rsp)
+
+	require.Len(t, agents.RecordedCommands, 1)
+	require.JSONEq(t, `{
+		"command": "lease4-wipe",
+		"service": [ "dhcp4" ],
+		"arguments": {
+			"subnet-id": 1
+		}
+	}`, agents.RecordedCommands[0].Marshal())
+
+	require.Len(t, eventCenter.Events, 1)
+	require.Equal(t, dbmodel.EvWarning, eventCenter.Events[0].Level)
+	require.Contains(t, eventCenter.Events[0].Text, "wiped leases in subnet 1")
+}
+
+// Test that only the super-admin users can wipe the leases.
+func TestWipeLeasesNotSuperAdmin(t *testing.T) {
+	rapi, agents, eventCenter, ctx, daemon := newTestLeaseRestAPI(t, false, mockLeaseChange)
+
+	params := dhcp.WipeLeasesParams{
+		Wipe: &models.LeasesWipeRequest{
+			DaemonID: &daemon.ID,
+		},
+	}
+	rsp := rapi.WipeLeases(ctx, params)
+	require.IsType(t, &dhcp.WipeLeasesDefault{}, rsp)
+	require.Equal(t, http.StatusForbidden, getStatusCode(*rsp.(*dhcp.WipeLeasesDefault)))
+
+	require.Empty(t, agents.RecordedCommands)
+	require.Empty(t, eventCenter.Events)
+}
+
+// Test that wiping the leases is rejected when there is no logged user.
+func TestWipeLeasesNoUser(t *testing.T) {
+	rapi, agents, eventCenter, _, daemon := newTestLeaseRestAPI(t, true, mockLeaseChange)
+
+	ctx, err := rapi.SessionManager.Load(context.Background(), "")
+	require.NoError(t, err)
+
+	params := dhcp.WipeLeasesParams{
+		Wipe: &models.LeasesWipeRequest{
+			DaemonID: &daemon.ID,
+		},
+	}
+	rsp := rapi.WipeLeases(ctx, params)
+	require.IsType(t, &dhcp.WipeLeasesDefault{}, rsp)
+	require.Equal(t, http.StatusForbidden, getStatusCode(*rsp.(*dhcp.WipeLeasesDefault)))
+
+	require.Empty(t, agents.RecordedCommands)
+	require.Empty(t, eventCenter.Events)
+}
+
+// Generates a mock response to a command fetching multiple leases
+// belonging to the subnet.
+func mockSubnetLeases(callNo int, responses []interface{}) {
+	json := []byte(`[
+        {
+            "result": 0,
+            "text": "Leases found",
+            "arguments": {
+                "leases": [
+                    {
+                        "ip-address": "192.0.2.1",
+                        "hw-address": "01:02:03:04:05:06",
+                        "subnet-id": 1,
+                        "cltt": 1000,
+                        "valid-lft": 3600,
+                        "state": 0
+                    },
+                    {
+                        "ip-address": "192.0.2.2",
+                        "hw-address": "01:02:03:04:05:07",
+                        "subnet-id": 1,
+                        "cltt": 1000,
+                        "valid-lft": 3600,
+                        "state": 1
+                    },
+                    {
+                        "ip-address": "192.0.3.1",
+                        "hw-address": "01:02:03:04:05:08",
+                        "subnet-id": 2,
+                        "cltt": 1000,
+                        "valid-lft": 3600,
+                        "state": 0
+                    }
+                ],
+                "count": 3
+            }
+        }
+    ]`)
+	command := keactrl.NewCommandBase(keactrl.Lease4GetPage, keactrl.DHCPv4)
+	_ = keactrl.UnmarshalResponseList(command, json, responses[0])
+}
+
+// Adds a subnet served by the daemon to the database.
+func addTestLeasesSubnet(t *testing.T, rapi *RestAPI, daemon *dbmodel.Daemon) *dbmodel.Subnet {
+	subnet := &dbmodel.Subnet{
+		Prefix: "192.0.2.0/24",
+		LocalSubnets: []*dbmodel.LocalSubnet{
+			{
+				DaemonID:      daemon.ID,
+				LocalSubnetID: 1,
+			},
+		},
+	}
+	err := dbmodel.AddSubnet(rapi.DB, subnet)
+	require.NoError(t, err)
+	err = dbmodel.AddLocalSubnets(rapi.DB, subnet)
+	require.NoError(t, err)
+	return subnet
+}
+
+// Test getting the leases belonging to the subnet over the REST API.
+func TestGetSubnetLeases(t *testing.T) {
+	rapi, agents, _, ctx, daemon := newTestLeaseRestAPI(t, false, mockSubnetLeases)
+	subnet := addTestLeasesSubnet(t, rapi, daemon)
+
+	params := dhcp.GetSubnetLeasesParams{
+		ID: subnet.ID,
+	}
+	rsp := rapi.GetSubnetLeases(ctx, params)
+	require.IsType(t, &dhcp.GetSubnetLeasesOK{}, rsp)
+	okRsp := rsp.(*dhcp.GetSubnetLeasesOK)
+	require.EqualValues(t, 2, okRsp.Payload.Total)
+	require.Len(t, okRsp.Payload.Items, 2)
+	require.Equal(t, "192.0.2.1", *okRsp.Payload.Items[0].IPAddress)
+	require.Equal(t, "192.0.2.2", *okRsp.Payload.Items[1].IPAddress)
+	require.Empty(t, okRsp.Payload.ErredApps)
+
+	require.Len(t, agents.RecordedCommands, 1)
+	require.Equal(t, keactrl.Lease4GetPage, agents.RecordedCommands[0].GetCommand())
+
+	// Get the second page.
+	params.Start = storkutil.Ptr(int64(1))
+	params.Limit = storkutil.Ptr(int64(1))
+	rsp = rapi.GetSubnetLeases(ctx, params)
+	require.IsType(t, &dhcp.GetSubnetLeasesOK{}, rsp)
+	okRsp = rsp.(*dhcp.GetSubnetLeasesOK)
+	require.EqualValues(t, 2, okRsp.Payload.Total)
+	require.Len(t, okRsp.Payload.Items, 1)
+	require.Equal(t, "192.0.2.2", *okRsp.Payload.Items[0].IPAddress)
+	params.Start = nil
+	params.Limit = nil
+
+	// Filter the leases by state and pool.
+	params.State = storkutil.Ptr(int64(1))
+	params.Pool = storkutil.Ptr("192.0.2.1 - 192.0.2.10")
+	rsp = rapi.GetSubnetLeases(ctx, params)
+	require.IsType(t, &dhcp.GetSubnetLeasesOK{}, rsp)
+	okRsp = rsp.(*dhcp.GetSubnetLeasesOK)
+	require.EqualValues(t, 1, okRsp.Payload.Total)
+	require.Len(t, okRsp.Payload.Items, 1)
+	require.Equal(t, "192.0.2.2", *okRsp.Payload.Items[0].IPAddress)
+
+	// Invalid pool.
+	params.Pool = storkutil.Ptr("foo")
+	rsp = rapi.GetSubnetLeases(ctx, params)
+	require.IsType(t, &dhcp.GetSubnetLeasesDefault{}, rsp)
+	require.Equal(t, http.StatusBadRequest, getStatusCode(*rsp.(*dhcp.GetSubnetLeasesDefault)))
+
+	// Non-existing subnet.
+	params.Pool = nil
+	params.ID = subnet.ID + 1
+	rsp = rapi.GetSubnetLeases(ctx, params)
+	require.IsType(t, &dhcp.GetSubnetLeasesDefault{}, rsp)
+	require.Equal(t, http.StatusNotFound, getStatusCode(*rsp.(*dhcp.GetSubnetLeasesDefault)))
+}
+
+// Test exporting the leases belonging to the subnet to a CSV file.
+func TestExportSubnetLeases(t *testing.T) {
+	rapi, agents, _, ctx, daemon := newTestLeaseRestAPI(t, false, mockSubnetLeases)
+	subnet := addTestLeasesSubnet(t, rapi, daemon)
+
+	params := dhcp.ExportSubnetLeasesParams{
+		ID:    subnet.ID,
+		State: storkutil.Ptr(int64(1)),
+	}
+	rsp := rapi.ExportSubnetLeases(ctx, params)
+	require.IsType(t, &dhcp.ExportSubnetLeasesOK{}, rsp)
+	okRsp := rsp.(*dhcp.ExportSubnetLeasesOK)
+	require.Equal(t, "text/csv", okRsp.ContentType)
+	require.Contains(t, okRsp.ContentDisposition, "stork-leases_")
+
+	content, err := io.ReadAll(okRsp.Payload)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	require.Len(t, lines, 2)
+	require.True(t, strings.HasPrefix(lines[0], "address,"))
+	require.True(t, strings.HasPrefix(lines[1], "192.0.2.2,"))
+
+	require.Len(t, agents.RecordedCommands, 1)
+	require.JSONEq(t, `{
+		"command": "lease4-get-all",
+		"service": [ "dhcp4" ],
+		"arguments": {
+			"subnets": [ 1 ]
+		}
+	}`, agents.RecordedCommands[0].Marshal())
+}
@@ -1329,6 +1329,9 @@ func keaDaemonToRestAPI(dbDaemon *dbmodel.Daemon) *models.KeaDaemon {
 	if dbDaemon.KeaDaemon != nil && dbDaemon.KeaDaemon.Config != nil {
 		daemon.Files, daemon.Backends = getKeaStorages(dbDaemon.KeaDaemon.Config.Config)
 	}
+	if dbDaemon.KeaDaemon != nil {
+		daemon.UnsavedConfigChanges = dbDaemon.KeaDaemon.UnsavedConfigChanges
+	}
 	return daemon
 }
 
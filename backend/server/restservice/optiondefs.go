@@ -0,0 +1,675 @@
+package restservice
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-openapi/runtime/middleware"
+	"github.com/go-openapi/strfmt"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	keaconfig "isc.org/stork/appcfg/kea"
+	dhcpmodel "isc.org/stork/datamodel/dhcp"
+	"isc.org/stork/server/apps/kea"
+	"isc.org/stork/server/config"
+	dbmodel "isc.org/stork/server/database/model"
+	"isc.org/stork/server/gen/models"
+	dhcp "isc.org/stork/server/gen/restapi/operations/d_h_c_p"
+	storkutil "isc.org/stork/util"
+)
+
+// Converts the custom option definition from the Kea configuration to the
+// format used in the REST API. If the option space is not specified in the
+// configuration, the top-level option space for the universe is returned.
+func convertOptionDefToRestAPI(optionDef *keaconfig.OptionDef, universe storkutil.IPType) *models.OptionDef {
+	restOptionDef := &models.OptionDef{
+		Name:        optionDef.Name,
+		Code:        int64(optionDef.Code),
+		Space:       optionDef.Space,
+		Type:        optionDef.OptionType,
+		Array:       optionDef.Array,
+		Encapsulate: optionDef.Encapsulate,
+		RecordTypes: optionDef.GetRecordTypes(),
+	}
+	if restOptionDef.Space == "" {
+		restOptionDef.Space = dhcpmodel.DHCPv4OptionSpace
+		if universe == storkutil.IPv6 {
+			restOptionDef.Space = dhcpmodel.DHCPv6OptionSpace
+		}
+	}
+	return restOptionDef
+}
+
+// Converts the custom option definition from the REST API format to the
+// format used in the Kea configuration.
+func convertOptionDefFromRestAPI(restOptionDef *models.OptionDef) (*keaconfig.OptionDef, error) {
+	if restOptionDef.Code <= 0 || restOptionDef.Code > math.MaxUint16 {
+		return nil, errors.Errorf("invalid option code %d", restOptionDef.Code)
+	}
+	return &keaconfig.OptionDef{
+		Name:        restOptionDef.Name,
+		Code:        uint16(restOptionDef.Code),
+		Space:       restOptionDef.Space,
+		OptionType:  restOptionDef.Type,
+		Array:       restOptionDef.Array,
+		Encapsulate: restOptionDef.Encapsulate,
+		RecordTypes: strings.Join(restOptionDef.RecordTypes, ","),
+	}, nil
+}
+
+// Converts the daemon owning an option definition to the format used in the
+// REST API.
+func convertLocalOptionDefToRestAPI(daemon *dbmodel.Daemon) *models.LocalOptionDef {
+	localOptionDef := &models.LocalOptionDef{
+		DaemonID:   daemon.ID,
+		DaemonName: daemon.Name,
+	}
+	if daemon.App != nil {
+		localOptionDef.AppID = daemon.App.ID
+		localOptionDef.AppName = daemon.App.Name
+	}
+	return localOptionDef
+}
+
+// Returns the custom option definitions configured in the specified daemons.
+// The definitions having the same contents in several daemons are returned
+// as a single definition associated with all these daemons. The returned
+// definitions are sorted by option space and code.
+func getOptionDefs(daemons []dbmodel.Daemon) []*models.OptionDef {
+	type groupedOptionDef struct {
+		definition string
+		optionDef  *models.OptionDef
+	}
+	var groups []groupedOptionDef
+	for i := range daemons {
+		if daemons[i].KeaDaemon == nil || daemons[i].KeaDaemon.Config == nil {
+			continue
+		}
+		for _, optionDef := range daemons[i].KeaDaemon.Config.GetOptionDefs() {
+			restOptionDef := convertOptionDefToRestAPI(&optionDef, getDaemonIPType(&daemons[i]))
+			// Serialized option definition is used to detect the same
+			// definitions in the different daemons.
+			definition, err := json.Marshal(restOptionDef)
+			if err != nil {
+				continue
+			}
+			found := false
+			for _, group := range groups {
+				if group.definition == string(definition) {
+					group.optionDef.LocalOptionDefs = append(group.optionDef.LocalOptionDefs, convertLocalOptionDefToRestAPI(&daemons[i]))
+					found = true
+					break
+				}
+			}
+			if !found {
+				restOptionDef.LocalOptionDefs = []*models.LocalOptionDef{
+					convertLocalOptionDefToRestAPI(&daemons[i]),
+				}
+				groups = append(groups, groupedOptionDef{
+					definition: string(definition),
+					optionDef:  restOptionDef,
+				})
+			}
+		}
+	}
+	optionDefs := []*models.OptionDef{}
+	for _, group := range groups {
+		optionDefs = append(optionDefs, group.optionDef)
+	}
+	sort.SliceStable(optionDefs, func(i, j int) bool {
+		if optionDefs[i].Space != optionDefs[j].Space {
+			return optionDefs[i].Space < optionDefs[j].Space
+		}
+		return optionDefs[i].Code < optionDefs[j].Code
+	})
+	return optionDefs
+}
+
+// Get custom option definitions configured in the Kea servers.
+func (r *RestAPI) GetOptionDefs(ctx context.Context, params dhcp.GetOptionDefsParams) middleware.Responder {
+	daemons, err := dbmodel.GetKeaDHCPDaemons(r.DB)
+	if err != nil {
+		msg := "Cannot get Kea daemons from db"
+		log.WithError(err).Error(msg)
+		rsp := dhcp.NewGetOptionDefsDefault(http.StatusInternalServerError).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	if params.DaemonID != nil {
+		var filteredDaemons []dbmodel.Daemon
+		for _, daemon := range daemons {
+			if daemon.ID == *params.DaemonID {
+				filteredDaemons = append(filteredDaemons, daemon)
+			}
+		}
+		daemons = filteredDaemons
+	}
+	optionDefs := getOptionDefs(daemons)
+	rsp := dhcp.NewGetOptionDefsOK().WithPayload(&models.OptionDefs{
+		Items: optionDefs,
+		Total: int64(len(optionDefs)),
+	})
+	return rsp
+}
+
+// Common function executed when creating a new transaction for adding or
+// updating an option definition. It fetches available DHCP daemons and
+// creates transaction context. If an error occurs, an http error code and
+// message are returned.
+func (r *RestAPI) commonCreateOrUpdateOptionDefBegin(ctx context.Context) ([]*models.KeaDaemon, context.Context, int, string) {
+	// A list of Kea DHCP daemons will be needed in the user form,
+	// so the user can select which servers send the option definition to.
+	daemons, err := dbmodel.GetKeaDHCPDaemons(r.DB)
+	if err != nil {
+		msg := "Problem with fetching Kea daemons from the database"
+		log.Error(err)
+		return nil, nil, http.StatusInternalServerError, msg
+	}
+	respDaemons := []*models.KeaDaemon{}
+	for i := range daemons {
+		if daemons[i].KeaDaemon != nil && daemons[i].KeaDaemon.Config != nil {
+			respDaemons = append(respDaemons, keaDaemonToRestAPI(&daemons[i]))
+		}
+	}
+	if len(respDaemons) == 0 {
+		msg := "Unable to begin transaction because there are no Kea servers with configurations available"
+		log.Error(msg)
+		return nil, nil, http.StatusBadRequest, msg
+	}
+	// Create configuration context.
+	_, user := r.SessionManager.Logged(ctx)
+	cctx, err := r.ConfigManager.CreateContext(int64(user.ID))
+	if err != nil {
+		msg := "Problem with creating transaction context"
+		log.WithError(err).Error(msg)
+		return nil, nil, http.StatusInternalServerError, msg
+	}
+	return respDaemons, cctx, 0, ""
+}
+
+// Implements the POST call to create new transaction for adding a new
+// option definition (option-defs/new/transaction).
+func (r *RestAPI) CreateOptionDefBegin(ctx context.Context, params dhcp.CreateOptionDefBeginParams) middleware.Responder {
+	respDaemons, cctx, code, msg := r.commonCreateOrUpdateOptionDefBegin(ctx)
+	if code != 0 {
+		// Error case.
+		rsp := dhcp.NewCreateOptionDefBeginDefault(code).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	// Begin option definition add transaction.
+	var err error
+	if cctx, err = r.ConfigManager.GetKeaModule().BeginOptionDefAdd(cctx); err != nil {
+		msg := "Problem with initializing transaction for creating option definition"
+		log.WithError(err).Error(msg)
+		rsp := dhcp.NewCreateOptionDefBeginDefault(http.StatusInternalServerError).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	// Retrieve the generated context ID.
+	cctxID, ok := config.GetValueAsInt64(cctx, config.ContextIDKey)
+	if !ok {
+		msg := "problem with retrieving context ID for a transaction to create an option definition"
+		log.Error(msg)
+		rsp := dhcp.NewCreateOptionDefBeginDefault(http.StatusInternalServerError).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	// Remember the context, i.e. new transaction has been successfully created.
+	_ = r.ConfigManager.RememberContext(cctx, time.Minute*10)
+
+	// Return transaction ID and daemons to the user.
+	contents := &models.CreateOptionDefBeginResponse{
+		ID:      cctxID,
+		Daemons: respDaemons,
+	}
+	rsp := dhcp.NewCreateOptionDefBeginOK().WithPayload(contents)
+	return rsp
+}
+
+// Implements the POST call and commits a new option definition
+// (option-defs/new/transaction/{id}/submit).
+func (r *RestAPI) CreateOptionDefSubmit(ctx context.Context, params dhcp.CreateOptionDefSubmitParams) middleware.Responder {
+	if code, msg := r.commonCreateOrUpdateOptionDefSubmit(ctx, params.ID, params.OptionDef, true, params.ScheduledAt); code != 0 {
+		// Error case.
+		rsp := dhcp.NewCreateOptionDefSubmitDefault(code).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	rsp := dhcp.NewCreateOptionDefSubmitOK()
+	return rsp
+}
+
+// Implements the POST call to preview the changes of the new option definition
+// (option-defs/new/transaction/{id}/preview).
+func (r *RestAPI) CreateOptionDefPreview(ctx context.Context, params dhcp.CreateOptionDefPreviewParams) middleware.Responder {
+	preview, code, msg := r.commonCreateOrUpdateOptionDefPreview(ctx, params.ID, params.OptionDef, true)
+	if code != 0 {
+		// Error case.
+		rsp := dhcp.NewCreateOptionDefPreviewDefault(code).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	rsp := dhcp.NewCreateOptionDefPreviewOK().WithPayload(preview)
+	return rsp
+}
+
+// Implements the DELETE call to cancel creating an option definition
+// (option-defs/new/transaction/{id}). It removes the specified transaction
+// from the config manager, if the transaction exists.
+func (r *RestAPI) CreateOptionDefDelete(ctx context.Context, params dhcp.CreateOptionDefDeleteParams) middleware.Responder {
+	if code, msg := r.commonCreateOrUpdateOptionDefDelete(ctx, params.ID); code != 0 {
+		// Error case.
+		rsp := dhcp.NewCreateOptionDefDeleteDefault(code).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	rsp := dhcp.NewCreateOptionDefDeleteOK()
+	return rsp
+}
+
+// Implements the POST call to create new transaction for updating an
+// existing option definition (option-defs/{space}/{code}/transaction).
+func (r *RestAPI) UpdateOptionDefBegin(ctx context.Context, params dhcp.UpdateOptionDefBeginParams) middleware.Responder {
+	if params.Request == nil || len(params.Request.DaemonIds) == 0 {
+		msg := "No daemons specified for the option definition update"
+		log.Error(msg)
+		rsp := dhcp.NewUpdateOptionDefBeginDefault(http.StatusBadRequest).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	if params.Code <= 0 || params.Code > math.MaxUint16 {
+		msg := fmt.Sprintf("Invalid option code %d", params.Code)
+		log.Error(msg)
+		rsp := dhcp.NewUpdateOptionDefBeginDefault(http.StatusBadRequest).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	respDaemons, cctx, code, msg := r.commonCreateOrUpdateOptionDefBegin(ctx)
+	if code != 0 {
+		// Error case.
+		rsp := dhcp.NewUpdateOptionDefBeginDefault(code).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	// Begin option definition update transaction. It retrieves current
+	// daemons' configurations and locks the daemons for updates.
+	var err error
+	cctx, err = r.ConfigManager.GetKeaModule().BeginOptionDefUpdate(cctx, uint16(params.Code), params.Space, params.Request.DaemonIds)
+	if err != nil {
+		var (
+			optionDefNotFound *config.OptionDefNotFoundError
+			daemonsNotFound   *config.SomeDaemonsNotFoundError
+			lock              *config.LockError
+		)
+		switch {
+		case errors.As(err, &optionDefNotFound):
+			// Failed to find option definition.
+			msg := fmt.Sprintf("Unable to edit the option definition with code %d in the %s option space because it cannot be found", params.Code, params.Space)
+			log.WithError(err).Error(msg)
+			rsp := dhcp.NewUpdateOptionDefBeginDefault(http.StatusBadRequest).WithPayload(&models.APIError{
+				Message: &msg,
+			})
+			return rsp
+		case errors.As(err, &daemonsNotFound):
+			// Failed to find some of the daemons.
+			msg := fmt.Sprintf("Unable to edit the option definition with code %d in the %s option space because some daemons cannot be found", params.Code, params.Space)
+			log.WithError(err).Error(msg)
+			rsp := dhcp.NewUpdateOptionDefBeginDefault(http.StatusBadRequest).WithPayload(&models.APIError{
+				Message: &msg,
+			})
+			return rsp
+		case errors.As(err, &lock):
+			// Failed to lock daemons.
+			msg := fmt.Sprintf("Unable to edit the option definition with code %d in the %s option space because it may be currently edited by another user", params.Code, params.Space)
+			log.WithError(err).Error(msg)
+			rsp := dhcp.NewUpdateOptionDefBeginDefault(http.StatusLocked).WithPayload(&models.APIError{
+				Message: &msg,
+			})
+			return rsp
+		default:
+			// Other error.
+			msg := fmt.Sprintf("Problem with initializing transaction for an update of the option definition with code %d in the %s option space", params.Code, params.Space)
+			log.WithError(err).Error(msg)
+			rsp := dhcp.NewUpdateOptionDefBeginDefault(http.StatusInternalServerError).WithPayload(&models.APIError{
+				Message: &msg,
+			})
+			return rsp
+		}
+	}
+	state, _ := config.GetTransactionState[kea.ConfigRecipe](cctx)
+	daemons := state.Updates[0].Recipe.KeaDaemonsBeforeConfigUpdate
+
+	// Retrieve the generated context ID.
+	cctxID, ok := config.GetValueAsInt64(cctx, config.ContextIDKey)
+	if !ok {
+		msg := "problem with retrieving context ID for a transaction to update an option definition"
+		log.Error(msg)
+		rsp := dhcp.NewUpdateOptionDefBeginDefault(http.StatusInternalServerError).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	// Remember the context, i.e. new transaction has been successfully created.
+	_ = r.ConfigManager.RememberContext(cctx, time.Minute*10)
+
+	// Return the option definition from the first daemon. The definitions
+	// may differ between the daemons but they are overwritten with the same
+	// definition upon submission.
+	var restOptionDef *models.OptionDef
+	if optionDef := daemons[0].KeaDaemon.Config.GetOptionDef(uint16(params.Code), params.Space); optionDef != nil {
+		restOptionDef = convertOptionDefToRestAPI(optionDef, getDaemonIPType(&daemons[0]))
+		restOptionDef.LocalOptionDefs = []*models.LocalOptionDef{}
+		for i := range daemons {
+			restOptionDef.LocalOptionDefs = append(restOptionDef.LocalOptionDefs, convertLocalOptionDefToRestAPI(&daemons[i]))
+		}
+	}
+
+	// Return transaction ID and daemons to the user.
+	contents := &models.UpdateOptionDefBeginResponse{
+		ID:        cctxID,
+		OptionDef: restOptionDef,
+		Daemons:   respDaemons,
+	}
+	rsp := dhcp.NewUpdateOptionDefBeginOK().WithPayload(contents)
+	return rsp
+}
+
+// Implements the POST call and commits an updated option definition
+// (option-defs/{space}/{code}/transaction/{id}/submit).
+func (r *RestAPI) UpdateOptionDefSubmit(ctx context.Context, params dhcp.UpdateOptionDefSubmitParams) middleware.Responder {
+	if params.OptionDef != nil && (params.OptionDef.Code != params.Code || params.OptionDef.Space != params.Space) {
+		msg := "Option definition code or space in the request body does not match the code or space in the URL"
+		log.Error(msg)
+		rsp := dhcp.NewUpdateOptionDefSubmitDefault(http.StatusBadRequest).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	if code, msg := r.commonCreateOrUpdateOptionDefSubmit(ctx, params.ID, params.OptionDef, false, params.ScheduledAt); code != 0 {
+		// Error case.
+		rsp := dhcp.NewUpdateOptionDefSubmitDefault(code).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	rsp := dhcp.NewUpdateOptionDefSubmitOK()
+	return rsp
+}
+
+// Implements the POST call to preview the changes of the updated option definition
+// (option-defs/{space}/{code}/transaction/{id}/preview).
+func (r *RestAPI) UpdateOptionDefPreview(ctx context.Context, params dhcp.UpdateOptionDefPreviewParams) middleware.Responder {
+	if params.OptionDef != nil && (params.OptionDef.Code != params.Code || params.OptionDef.Space != params.Space) {
+		msg := "Option definition code or space in the request body does not match the code or space in the URL"
+		log.Error(msg)
+		rsp := dhcp.NewUpdateOptionDefPreviewDefault(http.StatusBadRequest).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	preview, code, msg := r.commonCreateOrUpdateOptionDefPreview(ctx, params.ID, params.OptionDef, false)
+	if code != 0 {
+		// Error case.
+		rsp := dhcp.NewUpdateOptionDefPreviewDefault(code).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	rsp := dhcp.NewUpdateOptionDefPreviewOK().WithPayload(preview)
+	return rsp
+}
+
+// Implements the DELETE call to cancel updating an option definition
+// (option-defs/{space}/{code}/transaction/{id}). It removes the specified
+// transaction from the config manager, if the transaction exists.
+func (r *RestAPI) UpdateOptionDefDelete(ctx context.Context, params dhcp.UpdateOptionDefDeleteParams) middleware.Responder {
+	if code, msg := r.commonCreateOrUpdateOptionDefDelete(ctx, params.ID); code != 0 {
+		// Error case.
+		rsp := dhcp.NewUpdateOptionDefDeleteDefault(code).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	rsp := dhcp.NewUpdateOptionDefDeleteOK()
+	return rsp
+}
+
+// Common function that applies a new or updated option definition. The
+// transactionID is the identifier of the current configuration transaction used
+// by the function to recover the transaction context. The restOptionDef is the
+// pointer to the option definition specified by the user. The isNew flag
+// indicates whether a new definition is added or an existing definition is
+// updated. In the former case, the definition is added to the daemons specified
+// in the local option definitions. In the latter case, the definition is
+// updated in the daemons specified when the transaction began. This function
+// returns the transaction context with the applied changes. It returns the HTTP
+// error code if an error occurs or 0 when there is no error. In addition it
+// returns an error string to be included in the HTTP response or an empty
+// string if there is no error.
+func (r *RestAPI) commonCreateOrUpdateOptionDefApply(ctx context.Context, transactionID int64, restOptionDef *models.OptionDef, isNew bool) (context.Context, int, string) {
+	// Make sure that the option definition information is present.
+	if restOptionDef == nil || restOptionDef.Name == "" {
+		msg := "Option definition information not specified"
+		log.Errorf("Problem with submitting an option definition because the option definition information is missing")
+		return nil, http.StatusBadRequest, msg
+	}
+	// Convert option definition information from REST API to Kea format.
+	optionDef, err := convertOptionDefFromRestAPI(restOptionDef)
+	if err != nil {
+		msg := "Error parsing specified option definition"
+		log.WithError(err).Error(msg)
+		return nil, http.StatusBadRequest, msg
+	}
+	// Retrieve the context from the config manager.
+	_, user := r.SessionManager.Logged(ctx)
+	cctx, _ := r.ConfigManager.RecoverContext(transactionID, int64(user.ID))
+	if cctx == nil {
+		msg := "Transaction expired for the option definition update"
+		log.Errorf("Problem with recovering transaction context for transaction ID %d and user ID %d", transactionID, user.ID)
+		return nil, http.StatusNotFound, msg
+	}
+	// Apply the changes to a copy of the transaction state. The transaction
+	// remembered by the config manager is only updated upon commit.
+	cctx, err = config.CopyTransactionState(cctx)
+	if err != nil {
+		msg := "Problem with copying the option definition transaction state"
+		log.WithError(err).Error(msg)
+		return nil, http.StatusInternalServerError, msg
+	}
+	// Apply the option definition information (create Kea commands).
+	if isNew {
+		var daemonIDs []int64
+		for _, lod := range restOptionDef.LocalOptionDefs {
+			daemonIDs = append(daemonIDs, lod.DaemonID)
+		}
+		daemons, err := dbmodel.GetDaemonsByIDs(r.DB, daemonIDs)
+		if err != nil {
+			msg := "Problem with fetching daemons from the database"
+			log.WithError(err).Error(msg)
+			return nil, http.StatusInternalServerError, msg
+		}
+		if len(daemons) == 0 || len(daemons) != len(daemonIDs) {
+			msg := "Specified option definition is associated with daemons that no longer exist"
+			log.Error(msg)
+			return nil, http.StatusNotFound, msg
+		}
+		cctx, err = r.ConfigManager.GetKeaModule().ApplyOptionDefAdd(cctx, optionDef, daemons)
+	} else {
+		cctx, err = r.ConfigManager.GetKeaModule().ApplyOptionDefUpdate(cctx, optionDef)
+	}
+	if err != nil {
+		var (
+			optionDefExists  *config.OptionDefExistsError
+			invalidOptionDef *config.InvalidOptionDefError
+		)
+		switch {
+		case errors.As(err, &optionDefExists):
+			msg := fmt.Sprintf("Problem with applying option definition information: %s", optionDefExists)
+			log.WithError(err).Error(msg)
+			return nil, http.StatusConflict, msg
+		case errors.As(err, &invalidOptionDef):
+			msg := fmt.Sprintf("Problem with applying option definition information: %s", invalidOptionDef)
+			log.WithError(err).Error(msg)
+			return nil, http.StatusBadRequest, msg
+		default:
+			msg := fmt.Sprintf("Problem with applying option definition information: %s", err)
+			log.WithError(err).Error(msg)
+			return nil, http.StatusInternalServerError, msg
+		}
+	}
+	return cctx, 0, ""
+}
+
+// Common function that implements the POST calls to apply and commit a new or
+// updated option definition. The parameters are described in the documentation
+// of the commonCreateOrUpdateOptionDefApply function. This function returns the
+// HTTP error code if an error occurs or 0 when there is no error. It also
+// returns an error string to be included in the HTTP response or an empty
+// string if there is no error.
+// The scheduledAt parameter optionally specifies the time when the changes
+// should be committed. If it is nil, the changes are committed immediately.
+func (r *RestAPI) commonCreateOrUpdateOptionDefSubmit(ctx context.Context, transactionID int64, restOptionDef *models.OptionDef, isNew bool, scheduledAt *strfmt.DateTime) (int, string) {
+	if code, msg := validateConfigChangeDeadline(scheduledAt); code != 0 {
+		return code, msg
+	}
+	cctx, code, msg := r.commonCreateOrUpdateOptionDefApply(ctx, transactionID, restOptionDef, isNew)
+	if code != 0 {
+		return code, msg
+	}
+	// Send the commands to Kea servers or schedule sending them.
+	cctx, err := r.commitOrScheduleConfigChanges(cctx, scheduledAt)
+	if err != nil {
+		msg := fmt.Sprintf("Problem with committing option definition information: %s", err)
+		log.WithError(err).Error(msg)
+		return http.StatusConflict, msg
+	}
+	// Everything ok. Cleanup and send OK to the client.
+	r.ConfigManager.Done(cctx)
+	return 0, ""
+}
+
+// Common function that implements the POST calls to preview the changes of a
+// new or updated option definition. The parameters are described in the
+// documentation of the commonCreateOrUpdateOptionDefApply function. The changes
+// are neither committed nor remembered in the transaction. This function
+// returns the preview of the changes. It returns the HTTP error code if an
+// error occurs or 0 when there is no error. In addition it returns an error
+// string to be included in the HTTP response or an empty string if there is no
+// error.
+func (r *RestAPI) commonCreateOrUpdateOptionDefPreview(ctx context.Context, transactionID int64, restOptionDef *models.OptionDef, isNew bool) (*models.ConfigChangesPreview, int, string) {
+	cctx, code, msg := r.commonCreateOrUpdateOptionDefApply(ctx, transactionID, restOptionDef, isNew)
+	if code != 0 {
+		return nil, code, msg
+	}
+	return r.previewConfigChanges(ctx, cctx)
+}
+
+// Common function that implements the DELETE calls to cancel adding new
+// or updating an option definition. It removes the specified transaction
+// from the config manager, if the transaction exists. It returns the HTTP
+// error code if an error occurs or 0 when there is no error. In addition it
+// returns an error string to be included in the HTTP response or an empty
+// string if there is no error.
+func (r *RestAPI) commonCreateOrUpdateOptionDefDelete(ctx context.Context, transactionID int64) (int, string) {
+	// Retrieve the context from the config manager.
+	_, user := r.SessionManager.Logged(ctx)
+	cctx, _ := r.ConfigManager.RecoverContext(transactionID, int64(user.ID))
+	if cctx == nil {
+		msg := "Transaction expired for the option definition update"
+		log.Errorf("Problem with recovering transaction context for transaction ID %d and user ID %d", transactionID, user.ID)
+		return http.StatusNotFound, msg
+	}
+	r.ConfigManager.Done(cctx)
+	return 0, ""
+}
+
+// Implements the DELETE call for an option definition (option-defs/{space}/{code}).
+// It sends suitable commands to the specified Kea servers. Similarly to deleting
+// a client class, deleting an option definition is not transactional.
+func (r *RestAPI) DeleteOptionDef(ctx context.Context, params dhcp.DeleteOptionDefParams) middleware.Responder {
+	if params.Code <= 0 || params.Code > math.MaxUint16 {
+		msg := fmt.Sprintf("Invalid option code %d", params.Code)
+		log.Error(msg)
+		rsp := dhcp.NewDeleteOptionDefDefault(http.StatusBadRequest).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	daemons, err := dbmodel.GetDaemonsByIDs(r.DB, params.DaemonIds)
+	if err != nil {
+		// Error while communicating with the database.
+		msg := "Problem fetching daemons from db"
+		log.WithError(err).Error(msg)
+		rsp := dhcp.NewDeleteOptionDefDefault(http.StatusInternalServerError).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	if len(daemons) == 0 || len(daemons) != len(params.DaemonIds) {
+		msg := fmt.Sprintf("Cannot find some daemons from which the option definition with code %d in the %s option space should be deleted", params.Code, params.Space)
+		rsp := dhcp.NewDeleteOptionDefDefault(http.StatusNotFound).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	// Create configuration context.
+	_, user := r.SessionManager.Logged(ctx)
+	cctx, err := r.ConfigManager.CreateContext(int64(user.ID))
+	if err != nil {
+		msg := "Problem with creating transaction context for deleting the option definition"
+		log.WithError(err).Error(err)
+		rsp := dhcp.NewDeleteOptionDefDefault(http.StatusInternalServerError).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	// Create Kea commands to delete the option definition.
+	cctx, err = r.ConfigManager.GetKeaModule().ApplyOptionDefDelete(cctx, uint16(params.Code), params.Space, daemons)
+	if err != nil {
+		var optionDefNotFound *config.OptionDefNotFoundError
+		if errors.As(err, &optionDefNotFound) {
+			msg := fmt.Sprintf("Cannot find the option definition with code %d in the %s option space in some of the daemons", params.Code, params.Space)
+			log.WithError(err).Error(msg)
+			rsp := dhcp.NewDeleteOptionDefDefault(http.StatusNotFound).WithPayload(&models.APIError{
+				Message: &msg,
+			})
+			return rsp
+		}
+		msg := "Problem with preparing commands for deleting the option definition"
+		log.WithError(err).Error(msg)
+		rsp := dhcp.NewDeleteOptionDefDefault(http.StatusInternalServerError).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	// Send the commands to Kea servers.
+	_, err = r.ConfigManager.Commit(cctx)
+	if err != nil {
+		msg := fmt.Sprintf("Problem with deleting an option definition: %s", err)
+		log.WithError(err).Error(msg)
+		rsp := dhcp.NewDeleteOptionDefDefault(http.StatusConflict).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	// Send OK to the client.
+	rsp := dhcp.NewDeleteOptionDefOK()
+	return rsp
+}
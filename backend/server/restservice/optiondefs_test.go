@@ -0,0 +1,476 @@
+package restservice
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	keaconfig "isc.org/stork/appcfg/kea"
+	dbmodel "isc.org/stork/server/database/model"
+	dbtest "isc.org/stork/server/database/test"
+	"isc.org/stork/server/gen/models"
+	dhcp "isc.org/stork/server/gen/restapi/operations/d_h_c_p"
+	storkutil "isc.org/stork/util"
+)
+
+// Test converting an option definition from the REST API format to the Kea
+// format and back.
+func TestConvertOptionDefRestAPI(t *testing.T) {
+	restOptionDef := &models.OptionDef{
+		Name:        "foo",
+		Code:        222,
+		Space:       "dhcp4",
+		Type:        keaconfig.RecordOption,
+		Array:       true,
+		RecordTypes: []string{"uint8", "ipv4-address"},
+	}
+	optionDef, err := convertOptionDefFromRestAPI(restOptionDef)
+	require.NoError(t, err)
+	require.NotNil(t, optionDef)
+	require.Equal(t, "foo", optionDef.Name)
+	require.EqualValues(t, 222, optionDef.Code)
+	require.Equal(t, "dhcp4", optionDef.Space)
+	require.Equal(t, keaconfig.RecordOption, optionDef.OptionType)
+	require.True(t, optionDef.Array)
+	require.Equal(t, "uint8,ipv4-address", optionDef.RecordTypes)
+
+	require.Equal(t, restOptionDef, convertOptionDefToRestAPI(optionDef, storkutil.IPv4))
+
+	// The top-level option space should be returned when the space is not
+	// specified.
+	optionDef.Space = ""
+	require.Equal(t, "dhcp6", convertOptionDefToRestAPI(optionDef, storkutil.IPv6).Space)
+
+	// Invalid option code.
+	restOptionDef.Code = 65536
+	_, err = convertOptionDefFromRestAPI(restOptionDef)
+	require.ErrorContains(t, err, "invalid option code 65536")
+}
+
+// Test that the option definitions are returned over the REST API and the
+// identical definitions are grouped.
+func TestGetOptionDefs(t *testing.T) {
+	db, dbSettings, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	daemons := addTestClientClassServers(t, db, `{
+		"Dhcp4": {
+			"option-def": [
+				{
+					"name": "foo",
+					"code": 222,
+					"type": "uint8"
+				},
+				{
+					"name": "bar",
+					"code": 1,
+					"type": "string",
+					"space": "bar-space"
+				}
+			]
+		}
+	}`, `{
+		"Dhcp4": {
+			"option-def": [
+				{
+					"name": "foo",
+					"code": 222,
+					"type": "uint8",
+					"space": "dhcp4"
+				}
+			]
+		}
+	}`)
+
+	rapi, _, ctx := newTestClientClassRestAPI(t, db, dbSettings)
+
+	rsp := rapi.GetOptionDefs(ctx, dhcp.GetOptionDefsParams{})
+	require.IsType(t, &dhcp.GetOptionDefsOK{}, rsp)
+	optionDefs := rsp.(*dhcp.GetOptionDefsOK).Payload
+	require.EqualValues(t, 2, optionDefs.Total)
+	require.Len(t, optionDefs.Items, 2)
+	require.Equal(t, "bar", optionDefs.Items[0].Name)
+	require.Len(t, optionDefs.Items[0].LocalOptionDefs, 1)
+	require.Equal(t, "foo", optionDefs.Items[1].Name)
+	require.Equal(t, "dhcp4", optionDefs.Items[1].Space)
+	require.Len(t, optionDefs.Items[1].LocalOptionDefs, 2)
+
+	// Filter by daemon.
+	rsp = rapi.GetOptionDefs(ctx, dhcp.GetOptionDefsParams{
+		DaemonID: &daemons[1].ID,
+	})
+	require.IsType(t, &dhcp.GetOptionDefsOK{}, rsp)
+	optionDefs = rsp.(*dhcp.GetOptionDefsOK).Payload
+	require.Len(t, optionDefs.Items, 1)
+	require.Equal(t, "foo", optionDefs.Items[0].Name)
+}
+
+// Test the calls for creating new transaction and adding an option definition.
+func TestCreateOptionDefBeginSubmit(t *testing.T) {
+	db, dbSettings, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	daemons := addTestClientClassServers(t, db, `{
+		"Dhcp4": {}
+	}`, `{
+		"Dhcp4": {}
+	}`)
+
+	rapi, fa, ctx := newTestClientClassRestAPI(t, db, dbSettings)
+
+	// Begin transaction.
+	rsp := rapi.CreateOptionDefBegin(ctx, dhcp.CreateOptionDefBeginParams{})
+	require.IsType(t, &dhcp.CreateOptionDefBeginOK{}, rsp)
+	contents := rsp.(*dhcp.CreateOptionDefBeginOK).Payload
+	transactionID := contents.ID
+	require.NotZero(t, transactionID)
+	require.Len(t, contents.Daemons, 2)
+
+	// Submit the option definition.
+	params := dhcp.CreateOptionDefSubmitParams{
+		ID: transactionID,
+		OptionDef: &models.OptionDef{
+			Name:  "foo",
+			Code:  222,
+			Space: "dhcp4",
+			Type:  keaconfig.StringOption,
+			LocalOptionDefs: []*models.LocalOptionDef{
+				{
+					DaemonID: daemons[0].ID,
+				},
+				{
+					DaemonID: daemons[1].ID,
+				},
+			},
+		},
+	}
+	rsp = rapi.CreateOptionDefSubmit(ctx, params)
+	require.IsType(t, &dhcp.CreateOptionDefSubmitOK{}, rsp)
+
+	// The config-set should be sent to both servers followed by the
+	// config-write.
+	require.Len(t, fa.RecordedCommands, 4)
+	require.JSONEq(t, `{
+		"command": "config-set",
+		"service": ["dhcp4"],
+		"arguments": {
+			"Dhcp4": {
+				"option-def": [
+					{
+						"name": "foo",
+						"code": 222,
+						"space": "dhcp4",
+						"type": "string"
+					}
+				]
+			}
+		}
+	}`, fa.RecordedCommands[0].Marshal())
+
+	// The option definition should be stored in the database.
+	updatedDaemons, err := dbmodel.GetDaemonsByIDs(db, []int64{daemons[0].ID, daemons[1].ID})
+	require.NoError(t, err)
+	require.Len(t, updatedDaemons, 2)
+	for _, daemon := range updatedDaemons {
+		require.NotNil(t, daemon.KeaDaemon.Config.GetOptionDef(222, "dhcp4"))
+	}
+
+	// The transaction should have been removed.
+	rsp = rapi.CreateOptionDefSubmit(ctx, params)
+	require.IsType(t, &dhcp.CreateOptionDefSubmitDefault{}, rsp)
+	require.Equal(t, http.StatusNotFound, getStatusCode(*rsp.(*dhcp.CreateOptionDefSubmitDefault)))
+}
+
+// Test that the changes of the option definition transaction can be previewed
+// before submitting them and that previewing doesn't commit anything.
+func TestCreateOptionDefBeginPreviewSubmit(t *testing.T) {
+	db, dbSettings, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	daemons := addTestClientClassServers(t, db, `{
+		"Dhcp4": {}
+	}`, `{
+		"Dhcp4": {}
+	}`)
+
+	rapi, fa, ctx := newTestClientClassRestAPI(t, db, dbSettings)
+
+	// Begin transaction.
+	rsp := rapi.CreateOptionDefBegin(ctx, dhcp.CreateOptionDefBeginParams{})
+	require.IsType(t, &dhcp.CreateOptionDefBeginOK{}, rsp)
+	transactionID := rsp.(*dhcp.CreateOptionDefBeginOK).Payload.ID
+
+	optionDef := &models.OptionDef{
+		Name:  "foo",
+		Code:  222,
+		Space: "dhcp4",
+		Type:  keaconfig.StringOption,
+		LocalOptionDefs: []*models.LocalOptionDef{
+			{
+				DaemonID: daemons[0].ID,
+			},
+		},
+	}
+
+	// Preview the changes.
+	rsp = rapi.CreateOptionDefPreview(ctx, dhcp.CreateOptionDefPreviewParams{
+		ID:        transactionID,
+		OptionDef: optionDef,
+	})
+	require.IsType(t, &dhcp.CreateOptionDefPreviewOK{}, rsp)
+	preview := rsp.(*dhcp.CreateOptionDefPreviewOK).Payload
+	require.Len(t, preview.Items, 1)
+	require.Equal(t, daemons[0].ID, preview.Items[0].DaemonID)
+	require.Equal(t, dbmodel.DaemonNameDHCPv4, preview.Items[0].DaemonName)
+	require.Len(t, preview.Items[0].Commands, 2)
+	require.Len(t, preview.Items[0].Changes, 1)
+	require.Equal(t, "/Dhcp4/option-def", preview.Items[0].Changes[0].Path)
+	require.Equal(t, "add", preview.Items[0].Changes[0].Op)
+
+	// Nothing should have been sent to the servers nor stored in the database.
+	require.Empty(t, fa.RecordedCommands)
+	daemon, err := dbmodel.GetDaemonByID(db, daemons[0].ID)
+	require.NoError(t, err)
+	require.Nil(t, daemon.KeaDaemon.Config.GetOptionDef(222, "dhcp4"))
+
+	// Previewing again should yield the same result because the transaction
+	// was not modified.
+	rsp = rapi.CreateOptionDefPreview(ctx, dhcp.CreateOptionDefPreviewParams{
+		ID:        transactionID,
+		OptionDef: optionDef,
+	})
+	require.IsType(t, &dhcp.CreateOptionDefPreviewOK{}, rsp)
+	require.Equal(t, preview, rsp.(*dhcp.CreateOptionDefPreviewOK).Payload)
+
+	// The transaction should still be available for submission.
+	rsp = rapi.CreateOptionDefSubmit(ctx, dhcp.CreateOptionDefSubmitParams{
+		ID:        transactionID,
+		OptionDef: optionDef,
+	})
+	require.IsType(t, &dhcp.CreateOptionDefSubmitOK{}, rsp)
+	require.Len(t, fa.RecordedCommands, 2)
+
+	// The transaction no longer exists.
+	rsp = rapi.CreateOptionDefPreview(ctx, dhcp.CreateOptionDefPreviewParams{
+		ID:        transactionID,
+		OptionDef: optionDef,
+	})
+	require.IsType(t, &dhcp.CreateOptionDefPreviewDefault{}, rsp)
+	require.Equal(t, http.StatusNotFound, getStatusCode(*rsp.(*dhcp.CreateOptionDefPreviewDefault)))
+}
+
+// Test that submitting an option definition which redefines a standard
+// option fails.
+func TestCreateOptionDefSubmitStdConflict(t *testing.T) {
+	db, dbSettings, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	daemons := addTestClientClassServers(t, db, `{
+		"Dhcp4": {}
+	}`)
+
+	rapi, fa, ctx := newTestClientClassRestAPI(t, db, dbSettings)
+
+	rsp := rapi.CreateOptionDefBegin(ctx, dhcp.CreateOptionDefBeginParams{})
+	require.IsType(t, &dhcp.CreateOptionDefBeginOK{}, rsp)
+	transactionID := rsp.(*dhcp.CreateOptionDefBeginOK).Payload.ID
+
+	// The code 6 is the standard DNS servers option.
+	rsp = rapi.CreateOptionDefSubmit(ctx, dhcp.CreateOptionDefSubmitParams{
+		ID: transactionID,
+		OptionDef: &models.OptionDef{
+			Name:  "foo",
+			Code:  6,
+			Space: "dhcp4",
+			Type:  keaconfig.IPv4AddressOption,
+			LocalOptionDefs: []*models.LocalOptionDef{
+				{
+					DaemonID: daemons[0].ID,
+				},
+			},
+		},
+	})
+	require.IsType(t, &dhcp.CreateOptionDefSubmitDefault{}, rsp)
+	require.Equal(t, http.StatusBadRequest, getStatusCode(*rsp.(*dhcp.CreateOptionDefSubmitDefault)))
+	require.Empty(t, fa.RecordedCommands)
+}
+
+// Test the calls for creating new transaction and updating an option
+// definition.
+func TestUpdateOptionDefBeginSubmit(t *testing.T) {
+	db, dbSettings, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	daemons := addTestClientClassServers(t, db, `{
+		"Dhcp4": {
+			"option-def": [
+				{
+					"name": "foo",
+					"code": 222,
+					"type": "uint8"
+				}
+			]
+		}
+	}`)
+
+	rapi, fa, ctx := newTestClientClassRestAPI(t, db, dbSettings)
+
+	// Non-existing option definition.
+	rsp := rapi.UpdateOptionDefBegin(ctx, dhcp.UpdateOptionDefBeginParams{
+		Code:  223,
+		Space: "dhcp4",
+		Request: &models.UpdateOptionDefBeginRequest{
+			DaemonIds: []int64{daemons[0].ID},
+		},
+	})
+	require.IsType(t, &dhcp.UpdateOptionDefBeginDefault{}, rsp)
+	require.Equal(t, http.StatusBadRequest, getStatusCode(*rsp.(*dhcp.UpdateOptionDefBeginDefault)))
+
+	rsp = rapi.UpdateOptionDefBegin(ctx, dhcp.UpdateOptionDefBeginParams{
+		Code:  222,
+		Space: "dhcp4",
+		Request: &models.UpdateOptionDefBeginRequest{
+			DaemonIds: []int64{daemons[0].ID},
+		},
+	})
+	require.IsType(t, &dhcp.UpdateOptionDefBeginOK{}, rsp)
+	contents := rsp.(*dhcp.UpdateOptionDefBeginOK).Payload
+	require.NotZero(t, contents.ID)
+	require.NotNil(t, contents.OptionDef)
+	require.Equal(t, "foo", contents.OptionDef.Name)
+	require.Equal(t, "dhcp4", contents.OptionDef.Space)
+	require.Len(t, contents.OptionDef.LocalOptionDefs, 1)
+
+	// Mismatched code.
+	rsp = rapi.UpdateOptionDefSubmit(ctx, dhcp.UpdateOptionDefSubmitParams{
+		ID:    contents.ID,
+		Code:  222,
+		Space: "dhcp4",
+		OptionDef: &models.OptionDef{
+			Name:  "foo",
+			Code:  223,
+			Space: "dhcp4",
+			Type:  keaconfig.StringOption,
+		},
+	})
+	require.IsType(t, &dhcp.UpdateOptionDefSubmitDefault{}, rsp)
+	require.Equal(t, http.StatusBadRequest, getStatusCode(*rsp.(*dhcp.UpdateOptionDefSubmitDefault)))
+
+	rsp = rapi.UpdateOptionDefSubmit(ctx, dhcp.UpdateOptionDefSubmitParams{
+		ID:    contents.ID,
+		Code:  222,
+		Space: "dhcp4",
+		OptionDef: &models.OptionDef{
+			Name:  "foo",
+			Code:  222,
+			Space: "dhcp4",
+			Type:  keaconfig.StringOption,
+		},
+	})
+	require.IsType(t, &dhcp.UpdateOptionDefSubmitOK{}, rsp)
+	require.Len(t, fa.RecordedCommands, 2)
+
+	updatedDaemons, err := dbmodel.GetDaemonsByIDs(db, []int64{daemons[0].ID})
+	require.NoError(t, err)
+	require.Len(t, updatedDaemons, 1)
+	optionDef := updatedDaemons[0].KeaDaemon.Config.GetOptionDef(222, "dhcp4")
+	require.NotNil(t, optionDef)
+	require.Equal(t, keaconfig.StringOption, optionDef.OptionType)
+}
+
+// Test that the transaction to update an option definition can be canceled.
+func TestUpdateOptionDefBeginCancel(t *testing.T) {
+	db, dbSettings, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	daemons := addTestClientClassServers(t, db, `{
+		"Dhcp4": {
+			"option-def": [
+				{
+					"name": "foo",
+					"code": 222,
+					"type": "uint8"
+				}
+			]
+		}
+	}`)
+
+	rapi, _, ctx := newTestClientClassRestAPI(t, db, dbSettings)
+
+	rsp := rapi.UpdateOptionDefBegin(ctx, dhcp.UpdateOptionDefBeginParams{
+		Code:  222,
+		Space: "dhcp4",
+		Request: &models.UpdateOptionDefBeginRequest{
+			DaemonIds: []int64{daemons[0].ID},
+		},
+	})
+	require.IsType(t, &dhcp.UpdateOptionDefBeginOK{}, rsp)
+	transactionID := rsp.(*dhcp.UpdateOptionDefBeginOK).Payload.ID
+
+	rsp = rapi.UpdateOptionDefDelete(ctx, dhcp.UpdateOptionDefDeleteParams{
+		ID:    transactionID,
+		Code:  222,
+		Space: "dhcp4",
+	})
+	require.IsType(t, &dhcp.UpdateOptionDefDeleteOK{}, rsp)
+
+	// The daemons should have been unlocked, so a new transaction can begin.
+	rsp = rapi.UpdateOptionDefBegin(ctx, dhcp.UpdateOptionDefBeginParams{
+		Code:  222,
+		Space: "dhcp4",
+		Request: &models.UpdateOptionDefBeginRequest{
+			DaemonIds: []int64{daemons[0].ID},
+		},
+	})
+	require.IsType(t, &dhcp.UpdateOptionDefBeginOK{}, rsp)
+}
+
+// Test deleting an option definition.
+func TestDeleteOptionDef(t *testing.T) {
+	db, dbSettings, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	daemons := addTestClientClassServers(t, db, `{
+		"Dhcp4": {
+			"option-def": [
+				{
+					"name": "foo",
+					"code": 222,
+					"type": "uint8"
+				}
+			]
+		}
+	}`)
+
+	rapi, fa, ctx := newTestClientClassRestAPI(t, db, dbSettings)
+
+	// The option definition does not exist.
+	rsp := rapi.DeleteOptionDef(ctx, dhcp.DeleteOptionDefParams{
+		Code:      223,
+		Space:     "dhcp4",
+		DaemonIds: []int64{daemons[0].ID},
+	})
+	require.IsType(t, &dhcp.DeleteOptionDefDefault{}, rsp)
+	require.Equal(t, http.StatusNotFound, getStatusCode(*rsp.(*dhcp.DeleteOptionDefDefault)))
+
+	rsp = rapi.DeleteOptionDef(ctx, dhcp.DeleteOptionDefParams{
+		Code:      222,
+		Space:     "dhcp4",
+		DaemonIds: []int64{daemons[0].ID},
+	})
+	require.IsType(t, &dhcp.DeleteOptionDefOK{}, rsp)
+
+	require.Len(t, fa.RecordedCommands, 2)
+	require.JSONEq(t, `{
+		"command": "config-set",
+		"service": ["dhcp4"],
+		"arguments": {
+			"Dhcp4": {}
+		}
+	}`, fa.RecordedCommands[0].Marshal())
+
+	updatedDaemons, err := dbmodel.GetDaemonsByIDs(db, []int64{daemons[0].ID})
+	require.NoError(t, err)
+	require.Len(t, updatedDaemons, 1)
+	require.Empty(t, updatedDaemons[0].KeaDaemon.Config.GetOptionDefs())
+}
@@ -10,6 +10,7 @@ import (
 	dbmodel "isc.org/stork/server/database/model"
 	"isc.org/stork/server/gen/models"
 	"isc.org/stork/server/gen/restapi/operations/settings"
+	storkutil "isc.org/stork/util"
 )
 
 // Get global settings.
@@ -25,16 +26,20 @@ func (r *RestAPI) GetSettings(ctx context.Context, params settings.GetSettingsPa
 	}
 
 	s := &models.Settings{
-		Bind9StatsPullerInterval:     dbSettingsMap["bind9_stats_puller_interval"].(int64),
-		GrafanaURL:                   dbSettingsMap["grafana_url"].(string),
-		GrafanaDhcp4DashboardID:      dbSettingsMap["grafana_dhcp4_dashboard_id"].(string),
-		GrafanaDhcp6DashboardID:      dbSettingsMap["grafana_dhcp6_dashboard_id"].(string),
-		KeaHostsPullerInterval:       dbSettingsMap["kea_hosts_puller_interval"].(int64),
-		KeaStatsPullerInterval:       dbSettingsMap["kea_stats_puller_interval"].(int64),
-		KeaStatusPullerInterval:      dbSettingsMap["kea_status_puller_interval"].(int64),
-		AppsStatePullerInterval:      dbSettingsMap["apps_state_puller_interval"].(int64),
-		EnableMachineRegistration:    dbSettingsMap["enable_machine_registration"].(bool),
-		EnableOnlineSoftwareVersions: dbSettingsMap["enable_online_software_versions"].(bool),
+		Bind9StatsPullerInterval:      dbSettingsMap["bind9_stats_puller_interval"].(int64),
+		DnssecPullerInterval:          dbSettingsMap["dnssec_puller_interval"].(int64),
+		GrafanaURL:                    dbSettingsMap["grafana_url"].(string),
+		GrafanaDhcp4DashboardID:       dbSettingsMap["grafana_dhcp4_dashboard_id"].(string),
+		GrafanaDhcp6DashboardID:       dbSettingsMap["grafana_dhcp6_dashboard_id"].(string),
+		KeaHostsPullerInterval:        dbSettingsMap["kea_hosts_puller_interval"].(int64),
+		KeaStatsPullerInterval:        dbSettingsMap["kea_stats_puller_interval"].(int64),
+		KeaStatusPullerInterval:       dbSettingsMap["kea_status_puller_interval"].(int64),
+		AppsStatePullerInterval:       dbSettingsMap["apps_state_puller_interval"].(int64),
+		ZoneSerialDriftPullerInterval: dbSettingsMap["zone_serial_drift_puller_interval"].(int64),
+		ZoneSerialDriftThreshold:      dbSettingsMap["zone_serial_drift_threshold"].(int64),
+		EnableMachineRegistration:     dbSettingsMap["enable_machine_registration"].(bool),
+		EnableOnlineSoftwareVersions:  dbSettingsMap["enable_online_software_versions"].(bool),
+		KeaConfigAutoWrite:            storkutil.Ptr(dbSettingsMap["kea_config_auto_write"].(bool)),
 	}
 	rsp := settings.NewGetSettingsOK().WithPayload(s)
 
@@ -63,6 +68,11 @@ func (r *RestAPI) UpdateSettings(ctx context.Context, params settings.UpdateSett
 		log.WithError(err).Error("Cannot update bind9_stats_puller_interval")
 		return errRsp
 	}
+	err = dbmodel.SetSettingInt(r.DB, "dnssec_puller_interval", s.DnssecPullerInterval)
+	if err != nil {
+		log.WithError(err).Error("Cannot update dnssec_puller_interval")
+		return errRsp
+	}
 	err = dbmodel.SetSettingStr(r.DB, "grafana_url", s.GrafanaURL)
 	if err != nil {
 		log.WithError(err).Error("Cannot update grafana_url")
@@ -98,6 +108,16 @@ func (r *RestAPI) UpdateSettings(ctx context.Context, params settings.UpdateSett
 		log.WithError(err).Error("Cannot update apps_state_puller_interval")
 		return errRsp
 	}
+	err = dbmodel.SetSettingInt(r.DB, "zone_serial_drift_puller_interval", s.ZoneSerialDriftPullerInterval)
+	if err != nil {
+		log.WithError(err).Error("Cannot update zone_serial_drift_puller_interval")
+		return errRsp
+	}
+	err = dbmodel.SetSettingInt(r.DB, "zone_serial_drift_threshold", s.ZoneSerialDriftThreshold)
+	if err != nil {
+		log.WithError(err).Error("Cannot update zone_serial_drift_threshold")
+		return errRsp
+	}
 	err = dbmodel.SetSettingBool(r.DB, "enable_machine_registration", s.EnableMachineRegistration)
 	if err != nil {
 		log.WithError(err).Error("Cannot update enable_machine_registration")
@@ -108,6 +128,13 @@ func (r *RestAPI) UpdateSettings(ctx context.Context, params settings.UpdateSett
 		log.WithError(err).Error("Cannot update enable_online_software_versions")
 		return errRsp
 	}
+	if s.KeaConfigAutoWrite != nil {
+		err = dbmodel.SetSettingBool(r.DB, "kea_config_auto_write", *s.KeaConfigAutoWrite)
+		if err != nil {
+			log.WithError(err).Error("Cannot update kea_config_auto_write")
+			return errRsp
+		}
+	}
 	r.EndpointControl.SetEnabled(EndpointOpCreateNewMachine, s.EnableMachineRegistration)
 
 	rsp := settings.NewUpdateSettingsOK()
@@ -11,6 +11,7 @@ import (
 	"isc.org/stork/server/gen/models"
 	"isc.org/stork/server/gen/restapi/operations/settings"
 	storktest "isc.org/stork/server/test/dbmodel"
+	storkutil "isc.org/stork/util"
 )
 
 // Check getting and setting global settings via rest api functions.
@@ -38,23 +39,32 @@ func TestSettings(t *testing.T) {
 	require.IsType(t, &settings.GetSettingsOK{}, rsp)
 	okRsp := rsp.(*settings.GetSettingsOK)
 	require.EqualValues(t, 60, okRsp.Payload.Bind9StatsPullerInterval)
+	require.EqualValues(t, 3600, okRsp.Payload.DnssecPullerInterval)
+	require.EqualValues(t, 60, okRsp.Payload.ZoneSerialDriftPullerInterval)
+	require.EqualValues(t, 3600, okRsp.Payload.ZoneSerialDriftThreshold)
 	require.Empty(t, okRsp.Payload.GrafanaURL)
 	require.Equal(t, "hRf18FvWz", okRsp.Payload.GrafanaDhcp4DashboardID)
 	require.Equal(t, "AQPHKJUGz", okRsp.Payload.GrafanaDhcp6DashboardID)
+	require.NotNil(t, okRsp.Payload.KeaConfigAutoWrite)
+	require.True(t, *okRsp.Payload.KeaConfigAutoWrite)
 
 	// Update settings.
 	paramsUS := settings.UpdateSettingsParams{
 		Settings: &models.Settings{
-			Bind9StatsPullerInterval:     1,
-			AppsStatePullerInterval:      2,
-			KeaHostsPullerInterval:       3,
-			KeaStatsPullerInterval:       4,
-			KeaStatusPullerInterval:      5,
-			GrafanaURL:                   "http://foo:3000",
-			GrafanaDhcp4DashboardID:      "dhcp4",
-			GrafanaDhcp6DashboardID:      "dhcp6",
-			EnableMachineRegistration:    false,
-			EnableOnlineSoftwareVersions: false,
+			Bind9StatsPullerInterval:      1,
+			AppsStatePullerInterval:       2,
+			KeaHostsPullerInterval:        3,
+			KeaStatsPullerInterval:        4,
+			KeaStatusPullerInterval:       5,
+			DnssecPullerInterval:          6,
+			ZoneSerialDriftPullerInterval: 7,
+			ZoneSerialDriftThreshold:      8,
+			GrafanaURL:                    "http://foo:3000",
+			GrafanaDhcp4DashboardID:       "dhcp4",
+			GrafanaDhcp6DashboardID:       "dhcp6",
+			EnableMachineRegistration:     false,
+			EnableOnlineSoftwareVersions:  false,
+			KeaConfigAutoWrite:            storkutil.Ptr(false),
 		},
 	}
 	rsp = rapi.UpdateSettings(ctx, paramsUS)
@@ -71,6 +81,9 @@ func TestSettings(t *testing.T) {
 	require.EqualValues(t, 3, okRsp.Payload.KeaHostsPullerInterval)
 	require.EqualValues(t, 4, okRsp.Payload.KeaStatsPullerInterval)
 	require.EqualValues(t, 5, okRsp.Payload.KeaStatusPullerInterval)
+	require.EqualValues(t, 6, okRsp.Payload.DnssecPullerInterval)
+	require.EqualValues(t, 7, okRsp.Payload.ZoneSerialDriftPullerInterval)
+	require.EqualValues(t, 8, okRsp.Payload.ZoneSerialDriftThreshold)
 
 	require.EqualValues(t, "http://foo:3000", okRsp.Payload.GrafanaURL)
 	require.EqualValues(t, "dhcp4", okRsp.Payload.GrafanaDhcp4DashboardID)
@@ -78,4 +91,15 @@ func TestSettings(t *testing.T) {
 
 	require.False(t, okRsp.Payload.EnableMachineRegistration)
 	require.False(t, okRsp.Payload.EnableOnlineSoftwareVersions)
+	require.NotNil(t, okRsp.Payload.KeaConfigAutoWrite)
+	require.False(t, *okRsp.Payload.KeaConfigAutoWrite)
+
+	// The automatic configuration write setting should be left unchanged
+	// when it is not specified.
+	paramsUS.Settings.KeaConfigAutoWrite = nil
+	rsp = rapi.UpdateSettings(ctx, paramsUS)
+	require.IsType(t, &settings.UpdateSettingsOK{}, rsp)
+	autoWrite, err := dbmodel.GetSettingBool(db, "kea_config_auto_write")
+	require.NoError(t, err)
+	require.False(t, autoWrite)
 }
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/go-openapi/runtime/middleware"
+	"github.com/go-openapi/strfmt"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 	keaconfig "isc.org/stork/appcfg/kea"
@@ -350,27 +351,26 @@ func (r *RestAPI) GetSharedNetwork(ctx context.Context, params dhcp.GetSharedNet
 	return rsp
 }
 
-// Common function that implements the POST calls to apply and commit a new
-// or updated shared network. The ctx parameter is the REST API context. The
-// transactionID is the identifier of the current configuration transaction
-// used by the function to recover the transaction context. The restSharedNetwork
-// is the pointer to the shared network specified by the user. It is converted
-// by this function to the database model. The applyFunc is the function of the Kea
-// config module that applies the specified shared network. It is one of the
-// ApplySharedNetworkAdd or ApplySharedNetworkUpdate, depending on whether the
-// new shared network is created (via CreateSharedNetworkSubmit) or updated
-// (via UpdateSharedNetworkSubmit). The apply functions receive the transaction
-// context and a pointer to the shared network. They return the updated context
-// and error. This function returns the HTTP error code if an error occurs or 0
-// when there is no error. It also returns an ID of the created or modified shared
-// network. Finally, it returns an error string to be included in the HTTP response
-// or an empty string if there is no error.
-func (r *RestAPI) commonCreateOrUpdateSharedNetworkSubmit(ctx context.Context, transactionID int64, restSharedNetwork *models.SharedNetwork, applyFunc func(context.Context, *dbmodel.SharedNetwork) (context.Context, error)) (int, int64, string) {
+// Common function that applies a new or updated shared network. The ctx
+// parameter is the REST API context. The transactionID is the identifier of the
+// current configuration transaction used by the function to recover the
+// transaction context. The restSharedNetwork is the pointer to the shared
+// network specified by the user. It is converted by this function to the
+// database model. The applyFunc is the function of the Kea config module that
+// applies the specified shared network. It is one of the ApplySharedNetworkAdd
+// or ApplySharedNetworkUpdate, depending on whether the new shared network is
+// created or updated. The apply functions receive the transaction context and a
+// pointer to the shared network. They return the updated context and error.
+// This function returns the transaction context with the applied changes. It
+// returns the HTTP error code if an error occurs or 0 when there is no error.
+// In addition it returns an error string to be included in the HTTP response or
+// an empty string if there is no error.
+func (r *RestAPI) commonCreateOrUpdateSharedNetworkApply(ctx context.Context, transactionID int64, restSharedNetwork *models.SharedNetwork, applyFunc func(context.Context, *dbmodel.SharedNetwork) (context.Context, error)) (context.Context, int, string) {
 	// Make sure that the shared network information is present.
 	if restSharedNetwork == nil {
 		msg := "Shared network information not specified"
 		log.Errorf("Problem with submitting a shared network because the shared network information is missing")
-		return http.StatusBadRequest, 0, msg
+		return nil, http.StatusBadRequest, msg
 	}
 	// Retrieve the context from the config manager.
 	_, user := r.SessionManager.Logged(ctx)
@@ -378,7 +378,15 @@ func (r *RestAPI) commonCreateOrUpdateSharedNetworkSubmit(ctx context.Context, t
 	if cctx == nil {
 		msg := "Transaction expired for the shared network update"
 		log.Errorf("Problem with recovering transaction context for transaction ID %d and user ID %d", transactionID, user.ID)
-		return http.StatusNotFound, 0, msg
+		return nil, http.StatusNotFound, msg
+	}
+	// Apply the changes to a copy of the transaction state. The transaction
+	// remembered by the config manager is only updated upon commit.
+	cctx, err := config.CopyTransactionState(cctx)
+	if err != nil {
+		msg := "Problem with copying the shared network transaction state"
+		log.WithError(err).Error(msg)
+		return nil, http.StatusInternalServerError, msg
 	}
 
 	// Convert shared network information from REST API to database format.
@@ -386,23 +394,43 @@ func (r *RestAPI) commonCreateOrUpdateSharedNetworkSubmit(ctx context.Context, t
 	if err != nil {
 		msg := "Error parsing specified shared network"
 		log.WithError(err).Error(msg)
-		return http.StatusBadRequest, 0, msg
+		return nil, http.StatusBadRequest, msg
 	}
 	err = sharedNetwork.PopulateDaemons(r.DB)
 	if err != nil {
 		msg := "Specified shared network is associated with daemons that no longer exist"
 		log.WithError(err).Error(msg)
-		return http.StatusNotFound, 0, msg
+		return nil, http.StatusNotFound, msg
 	}
 	// Apply the shared network information (create Kea commands).
 	cctx, err = applyFunc(cctx, sharedNetwork)
 	if err != nil {
 		msg := fmt.Sprintf("Problem with applying shared network information: %s", err)
 		log.WithError(err).Error(msg)
-		return http.StatusInternalServerError, 0, msg
+		return nil, http.StatusInternalServerError, msg
 	}
-	// Send the commands to Kea servers.
-	cctx, err = r.ConfigManager.Commit(cctx)
+	return cctx, 0, ""
+}
+
+// Common function that implements the POST calls to apply and commit a new or
+// updated shared network. The parameters are described in the documentation of
+// the commonCreateOrUpdateSharedNetworkApply function. This function returns
+// the HTTP error code if an error occurs or 0 when there is no error. It also
+// returns an ID of the created or modified shared network. Finally, it returns
+// an error string to be included in the HTTP response or an empty string if
+// there is no error.
+// The scheduledAt parameter optionally specifies the time when the changes
+// should be committed. If it is nil, the changes are committed immediately.
+func (r *RestAPI) commonCreateOrUpdateSharedNetworkSubmit(ctx context.Context, transactionID int64, restSharedNetwork *models.SharedNetwork, applyFunc func(context.Context, *dbmodel.SharedNetwork) (context.Context, error), scheduledAt *strfmt.DateTime) (int, int64, string) {
+	if code, msg := validateConfigChangeDeadline(scheduledAt); code != 0 {
+		return code, 0, msg
+	}
+	cctx, code, msg := r.commonCreateOrUpdateSharedNetworkApply(ctx, transactionID, restSharedNetwork, applyFunc)
+	if code != 0 {
+		return code, 0, msg
+	}
+	// Send the commands to Kea servers or schedule sending them.
+	cctx, err := r.commitOrScheduleConfigChanges(cctx, scheduledAt)
 	if err != nil {
 		msg := fmt.Sprintf("Problem with committing shared network information: %s", err)
 		log.WithError(err).Error(msg)
@@ -425,6 +453,22 @@ func (r *RestAPI) commonCreateOrUpdateSharedNetworkSubmit(ctx context.Context, t
 	return 0, sharedNetworkID, ""
 }
 
+// Common function that implements the POST calls to preview the changes of a
+// new or updated shared network. The parameters are described in the
+// documentation of the commonCreateOrUpdateSharedNetworkApply function. The
+// changes are neither committed nor remembered in the transaction. This
+// function returns the preview of the changes. It returns the HTTP error code
+// if an error occurs or 0 when there is no error. In addition it returns an
+// error string to be included in the HTTP response or an empty string if there
+// is no error.
+func (r *RestAPI) commonCreateOrUpdateSharedNetworkPreview(ctx context.Context, transactionID int64, restSharedNetwork *models.SharedNetwork, applyFunc func(context.Context, *dbmodel.SharedNetwork) (context.Context, error)) (*models.ConfigChangesPreview, int, string) {
+	cctx, code, msg := r.commonCreateOrUpdateSharedNetworkApply(ctx, transactionID, restSharedNetwork, applyFunc)
+	if code != 0 {
+		return nil, code, msg
+	}
+	return r.previewConfigChanges(ctx, cctx)
+}
+
 // Common function that implements the DELETE calls to cancel adding new
 // or updating a shared network. It removes the specified transaction from the
 // config manager, if the transaction exists. It returns the HTTP error code
@@ -500,7 +544,7 @@ func (r *RestAPI) CreateSharedNetworkBegin(ctx context.Context, params dhcp.Crea
 // Implements the POST call and commits a new shared network
 // (shared-networks/new/transaction/{id}/submit).
 func (r *RestAPI) CreateSharedNetworkSubmit(ctx context.Context, params dhcp.CreateSharedNetworkSubmitParams) middleware.Responder {
-	code, sharedNetworkID, msg := r.commonCreateOrUpdateSharedNetworkSubmit(ctx, params.ID, params.SharedNetwork, r.ConfigManager.GetKeaModule().ApplySharedNetworkAdd)
+	code, sharedNetworkID, msg := r.commonCreateOrUpdateSharedNetworkSubmit(ctx, params.ID, params.SharedNetwork, r.ConfigManager.GetKeaModule().ApplySharedNetworkAdd, params.ScheduledAt)
 	if code != 0 {
 		// Error case.
 		rsp := dhcp.NewCreateSharedNetworkSubmitDefault(code).WithPayload(&models.APIError{
@@ -515,6 +559,21 @@ func (r *RestAPI) CreateSharedNetworkSubmit(ctx context.Context, params dhcp.Cre
 	return rsp
 }
 
+// Implements the POST call to preview the changes of the new shared network
+// (shared-networks/new/transaction/{id}/preview).
+func (r *RestAPI) CreateSharedNetworkPreview(ctx context.Context, params dhcp.CreateSharedNetworkPreviewParams) middleware.Responder {
+	preview, code, msg := r.commonCreateOrUpdateSharedNetworkPreview(ctx, params.ID, params.SharedNetwork, r.ConfigManager.GetKeaModule().ApplySharedNetworkAdd)
+	if code != 0 {
+		// Error case.
+		rsp := dhcp.NewCreateSharedNetworkPreviewDefault(code).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	rsp := dhcp.NewCreateSharedNetworkPreviewOK().WithPayload(preview)
+	return rsp
+}
+
 // Implements the DELETE call to cancel creating a shared network
 // (shared-networks/new/transaction/{id}).
 // It removes the specified transaction from the config manager,
@@ -625,7 +684,7 @@ func (r *RestAPI) UpdateSharedNetworkBegin(ctx context.Context, params dhcp.Upda
 // Implements the POST call and commits an updated shared network
 // (shared-networks/{sharedNetworkId}/transaction/{id}/submit).
 func (r *RestAPI) UpdateSharedNetworkSubmit(ctx context.Context, params dhcp.UpdateSharedNetworkSubmitParams) middleware.Responder {
-	if code, _, msg := r.commonCreateOrUpdateSharedNetworkSubmit(ctx, params.ID, params.SharedNetwork, r.ConfigManager.GetKeaModule().ApplySharedNetworkUpdate); code != 0 {
+	if code, _, msg := r.commonCreateOrUpdateSharedNetworkSubmit(ctx, params.ID, params.SharedNetwork, r.ConfigManager.GetKeaModule().ApplySharedNetworkUpdate, params.ScheduledAt); code != 0 {
 		// Error case.
 		rsp := dhcp.NewUpdateSharedNetworkSubmitDefault(code).WithPayload(&models.APIError{
 			Message: &msg,
@@ -636,6 +695,21 @@ func (r *RestAPI) UpdateSharedNetworkSubmit(ctx context.Context, params dhcp.Upd
 	return rsp
 }
 
+// Implements the POST call to preview the changes of the updated shared network
+// (shared-networks/{sharedNetworkId}/transaction/{id}/preview).
+func (r *RestAPI) UpdateSharedNetworkPreview(ctx context.Context, params dhcp.UpdateSharedNetworkPreviewParams) middleware.Responder {
+	preview, code, msg := r.commonCreateOrUpdateSharedNetworkPreview(ctx, params.ID, params.SharedNetwork, r.ConfigManager.GetKeaModule().ApplySharedNetworkUpdate)
+	if code != 0 {
+		// Error case.
+		rsp := dhcp.NewUpdateSharedNetworkPreviewDefault(code).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	rsp := dhcp.NewUpdateSharedNetworkPreviewOK().WithPayload(preview)
+	return rsp
+}
+
 // Implements the DELETE call to cancel updating a shared network
 // (shared-networks/{sharedNetworkId}/transaction/{id}).
 // It removes the specified transaction from the config manager,
@@ -1083,6 +1083,74 @@ func TestCreateSharedNetwork4BeginSubmit(t *testing.T) {
 	}
 }
 
+// Test that the changes of the new shared network can be previewed before
+// submitting them and that previewing doesn't send any commands.
+func TestCreateSharedNetwork4BeginPreview(t *testing.T) {
+	db, dbSettings, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	daemons := addTestClientClassServers(t, db, `{
+		"Dhcp4": {
+			"hooks-libraries": [
+				{
+					"library": "libdhcp_subnet_cmds"
+				}
+			]
+		}
+	}`)
+
+	rapi, fa, ctx := newTestClientClassRestAPI(t, db, dbSettings)
+
+	// Begin transaction.
+	rsp := rapi.CreateSharedNetworkBegin(ctx, dhcp.CreateSharedNetworkBeginParams{})
+	require.IsType(t, &dhcp.CreateSharedNetworkBeginOK{}, rsp)
+	transactionID := rsp.(*dhcp.CreateSharedNetworkBeginOK).Payload.ID
+
+	// Preview the changes.
+	rsp = rapi.CreateSharedNetworkPreview(ctx, dhcp.CreateSharedNetworkPreviewParams{
+		ID: transactionID,
+		SharedNetwork: &models.SharedNetwork{
+			Name:     "bar",
+			Universe: int64(4),
+			Subnets:  []*models.Subnet{},
+			LocalSharedNetworks: []*models.LocalSharedNetwork{
+				{
+					DaemonID: daemons[0].ID,
+					KeaConfigSharedNetworkParameters: &models.KeaConfigSharedNetworkParameters{
+						SharedNetworkLevelParameters: &models.KeaConfigSubnetDerivedParameters{
+							KeaConfigValidLifetimeParameters: models.KeaConfigValidLifetimeParameters{
+								ValidLifetime: storkutil.Ptr[int64](4500),
+							},
+						},
+					},
+				},
+			},
+		},
+	})
+	require.IsType(t, &dhcp.CreateSharedNetworkPreviewOK{}, rsp)
+	preview := rsp.(*dhcp.CreateSharedNetworkPreviewOK).Payload
+	require.Len(t, preview.Items, 1)
+	require.Equal(t, daemons[0].ID, preview.Items[0].DaemonID)
+	require.NotEmpty(t, preview.Items[0].Commands)
+
+	// The whole shared network should be reported as added.
+	require.Len(t, preview.Items[0].Changes, 1)
+	change := preview.Items[0].Changes[0]
+	require.Equal(t, "/shared-network4", change.Path)
+	require.Equal(t, "add", change.Op)
+	require.Nil(t, change.Before)
+	require.IsType(t, map[string]any{}, change.After)
+	sharedNetwork := change.After.(map[string]any)
+	require.Equal(t, "bar", sharedNetwork["name"])
+	require.EqualValues(t, 4500, sharedNetwork["valid-lifetime"])
+
+	// Nothing should have been sent to the servers nor stored in the database.
+	require.Empty(t, fa.RecordedCommands)
+	sharedNetworks, err := dbmodel.GetAllSharedNetworks(db, 4)
+	require.NoError(t, err)
+	require.Empty(t, sharedNetworks)
+}
+
 // Test error cases for submitting new shared network.
 func TestCreateSharedNetwork4BeginSubmitError(t *testing.T) {
 	db, dbSettings, teardown := dbtest.SetupDatabaseTestCase(t)
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/go-openapi/runtime/middleware"
+	"github.com/go-openapi/strfmt"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 	keaconfig "isc.org/stork/appcfg/kea"
@@ -549,8 +550,9 @@ func (r *RestAPI) commonCreateOrUpdateNetworkBegin(ctx context.Context) ([]*mode
 	clientClassesMap := make(map[string]bool)
 	for i := range daemons {
 		if daemons[i].KeaDaemon != nil && daemons[i].KeaDaemon.Config != nil {
-			// Filter the daemons with subnet_cmds hook library.
-			if _, _, exists := daemons[i].KeaDaemon.Config.GetHookLibrary("libdhcp_subnet_cmds"); exists {
+			// Filter the daemons with subnet_cmds hook library or using the
+			// configuration backend with the cb_cmds hook library.
+			if _, _, exists := daemons[i].KeaDaemon.Config.GetHookLibrary("libdhcp_subnet_cmds"); exists || daemons[i].KeaDaemon.Config.GetConfigBackend() != nil {
 				respDaemons = append(respDaemons, keaDaemonToRestAPI(&daemons[i]))
 			}
 			clientClasses := daemons[i].KeaDaemon.Config.GetClientClasses()
@@ -596,26 +598,25 @@ func (r *RestAPI) commonCreateOrUpdateNetworkBegin(ctx context.Context) ([]*mode
 	return respDaemons, respIPv4SharedNetworks, respIPv6SharedNetworks, respClientClasses, cctx, 0, ""
 }
 
-// Common function that implements the POST calls to apply and commit a new
-// or updated subnet. The ctx parameter is the REST API context. The
-// transactionID is the identifier of the current configuration transaction
-// used by the function to recover the transaction context. The restSubnet is
-// the pointer to the subnet specified by the user. It is converted by this
-// function to the database model. The applyFunc is the function of the Kea
-// config module that applies the specified subnet. It is one of the ApplySubnetAdd
-// or ApplySubnetUpdate, depending on whether the new subnet is created (via
-// CreateSubnetSubmit) or updated (via UpdateSubnetSubmit). The apply functions
-// receive the transaction context and a pointer to the subnet. They return the
-// updated context and error. This function returns the HTTP error code if an
-// error occurs or 0 when there is no error. It also returns an ID of the
-// created or modified subnet. Finally, it returns an error string to be included
-// in the HTTP response or an empty string if there is no error.
-func (r *RestAPI) commonCreateOrUpdateSubnetSubmit(ctx context.Context, transactionID int64, restSubnet *models.Subnet, applyFunc func(context.Context, *dbmodel.Subnet) (context.Context, error)) (int, int64, string) {
+// Common function that applies a new or updated subnet. The ctx parameter is
+// the REST API context. The transactionID is the identifier of the current
+// configuration transaction used by the function to recover the transaction
+// context. The restSubnet is the pointer to the subnet specified by the user.
+// It is converted by this function to the database model. The applyFunc is the
+// function of the Kea config module that applies the specified subnet. It is
+// one of the ApplySubnetAdd or ApplySubnetUpdate, depending on whether the new
+// subnet is created or updated. The apply functions receive the transaction
+// context and a pointer to the subnet. They return the updated context and error. This
+// function returns the transaction context with the applied changes. It returns
+// the HTTP error code if an error occurs or 0 when there is no error. In
+// addition it returns an error string to be included in the HTTP response or an
+// empty string if there is no error.
+func (r *RestAPI) commonCreateOrUpdateSubnetApply(ctx context.Context, transactionID int64, restSubnet *models.Subnet, applyFunc func(context.Context, *dbmodel.Subnet) (context.Context, error)) (context.Context, int, string) {
 	// Make sure that the subnet information is present.
 	if restSubnet == nil {
 		msg := "Subnet information not specified"
 		log.Errorf("Problem with submitting a subnet because the subnet information is missing")
-		return http.StatusBadRequest, 0, msg
+		return nil, http.StatusBadRequest, msg
 	}
 	// Retrieve the context from the config manager.
 	_, user := r.SessionManager.Logged(ctx)
@@ -623,7 +624,15 @@ func (r *RestAPI) commonCreateOrUpdateSubnetSubmit(ctx context.Context, transact
 	if cctx == nil {
 		msg := "Transaction expired for the subnet update"
 		log.Errorf("Problem with recovering transaction context for transaction ID %d and user ID %d", transactionID, user.ID)
-		return http.StatusNotFound, 0, msg
+		return nil, http.StatusNotFound, msg
+	}
+	// Apply the changes to a copy of the transaction state. The transaction
+	// remembered by the config manager is only updated upon commit.
+	cctx, err := config.CopyTransactionState(cctx)
+	if err != nil {
+		msg := "Problem with copying the subnet transaction state"
+		log.WithError(err).Error(msg)
+		return nil, http.StatusInternalServerError, msg
 	}
 
 	// Convert subnet information from REST API to database format.
@@ -631,13 +640,13 @@ func (r *RestAPI) commonCreateOrUpdateSubnetSubmit(ctx context.Context, transact
 	if err != nil {
 		msg := "Error parsing specified subnet"
 		log.WithError(err).Error(msg)
-		return http.StatusBadRequest, 0, msg
+		return nil, http.StatusBadRequest, msg
 	}
 	err = subnet.PopulateDaemons(r.DB)
 	if err != nil {
 		msg := "Specified subnet is associated with daemons that no longer exist"
 		log.WithError(err).Error(msg)
-		return http.StatusNotFound, 0, msg
+		return nil, http.StatusNotFound, msg
 	}
 	if restSubnet.SharedNetwork != "" {
 		subnet.SharedNetwork = &dbmodel.SharedNetwork{
@@ -649,10 +658,29 @@ func (r *RestAPI) commonCreateOrUpdateSubnetSubmit(ctx context.Context, transact
 	if err != nil {
 		msg := fmt.Sprintf("Problem with applying subnet information: %s", err)
 		log.WithError(err).Error(msg)
-		return http.StatusInternalServerError, 0, msg
+		return nil, http.StatusInternalServerError, msg
 	}
-	// Send the commands to Kea servers.
-	cctx, err = r.ConfigManager.Commit(cctx)
+	return cctx, 0, ""
+}
+
+// Common function that implements the POST calls to apply and commit a new or
+// updated subnet. The parameters are described in the documentation of the
+// commonCreateOrUpdateSubnetApply function. This function returns the HTTP
+// error code if an error occurs or 0 when there is no error. It also returns an
+// ID of the created or modified subnet. Finally, it returns an error string to
+// be included in the HTTP response or an empty string if there is no error.
+// The scheduledAt parameter optionally specifies the time when the changes
+// should be committed. If it is nil, the changes are committed immediately.
+func (r *RestAPI) commonCreateOrUpdateSubnetSubmit(ctx context.Context, transactionID int64, restSubnet *models.Subnet, applyFunc func(context.Context, *dbmodel.Subnet) (context.Context, error), scheduledAt *strfmt.DateTime) (int, int64, string) {
+	if code, msg := validateConfigChangeDeadline(scheduledAt); code != 0 {
+		return code, 0, msg
+	}
+	cctx, code, msg := r.commonCreateOrUpdateSubnetApply(ctx, transactionID, restSubnet, applyFunc)
+	if code != 0 {
+		return code, 0, msg
+	}
+	// Send the commands to Kea servers or schedule sending them.
+	cctx, err := r.commitOrScheduleConfigChanges(cctx, scheduledAt)
 	if err != nil {
 		msg := fmt.Sprintf("Problem with committing subnet information: %s", err)
 		log.WithError(err).Error(msg)
@@ -675,6 +703,21 @@ func (r *RestAPI) commonCreateOrUpdateSubnetSubmit(ctx context.Context, transact
 	return 0, subnetID, ""
 }
 
+// Common function that implements the POST calls to preview the changes of a
+// new or updated subnet. The parameters are described in the documentation of
+// the commonCreateOrUpdateSubnetApply function. The changes are neither
+// committed nor remembered in the transaction. This function returns the
+// preview of the changes. It returns the HTTP error code if an error occurs or
+// 0 when there is no error. In addition it returns an error string to be
+// included in the HTTP response or an empty string if there is no error.
+func (r *RestAPI) commonCreateOrUpdateSubnetPreview(ctx context.Context, transactionID int64, restSubnet *models.Subnet, applyFunc func(context.Context, *dbmodel.Subnet) (context.Context, error)) (*models.ConfigChangesPreview, int, string) {
+	cctx, code, msg := r.commonCreateOrUpdateSubnetApply(ctx, transactionID, restSubnet, applyFunc)
+	if code != 0 {
+		return nil, code, msg
+	}
+	return r.previewConfigChanges(ctx, cctx)
+}
+
 // Common function that implements the DELETE calls to cancel adding new
 // or updating a subnet. It removes the specified transaction from the
 // config manager, if the transaction exists. It returns the HTTP error code
@@ -754,7 +797,7 @@ func (r *RestAPI) CreateSubnetBegin(ctx context.Context, params dhcp.CreateSubne
 
 // Implements the POST call and commits a new subnet (subnets/new/transaction/{id}/submit).
 func (r *RestAPI) CreateSubnetSubmit(ctx context.Context, params dhcp.CreateSubnetSubmitParams) middleware.Responder {
-	code, subnetID, msg := r.commonCreateOrUpdateSubnetSubmit(ctx, params.ID, params.Subnet, r.ConfigManager.GetKeaModule().ApplySubnetAdd)
+	code, subnetID, msg := r.commonCreateOrUpdateSubnetSubmit(ctx, params.ID, params.Subnet, r.ConfigManager.GetKeaModule().ApplySubnetAdd, params.ScheduledAt)
 	if code != 0 {
 		// Error case.
 		rsp := dhcp.NewCreateSubnetSubmitDefault(code).WithPayload(&models.APIError{
@@ -769,6 +812,21 @@ func (r *RestAPI) CreateSubnetSubmit(ctx context.Context, params dhcp.CreateSubn
 	return rsp
 }
 
+// Implements the POST call to preview the changes of the new subnet
+// (subnets/new/transaction/{id}/preview).
+func (r *RestAPI) CreateSubnetPreview(ctx context.Context, params dhcp.CreateSubnetPreviewParams) middleware.Responder {
+	preview, code, msg := r.commonCreateOrUpdateSubnetPreview(ctx, params.ID, params.Subnet, r.ConfigManager.GetKeaModule().ApplySubnetAdd)
+	if code != 0 {
+		// Error case.
+		rsp := dhcp.NewCreateSubnetPreviewDefault(code).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	rsp := dhcp.NewCreateSubnetPreviewOK().WithPayload(preview)
+	return rsp
+}
+
 // Implements the DELETE call to cancel creating a subnet (subnets/new/transaction/{id}).
 // It removes the specified transaction from the config manager, if the transaction exists.
 func (r *RestAPI) CreateSubnetDelete(ctx context.Context, params dhcp.CreateSubnetDeleteParams) middleware.Responder {
@@ -872,7 +930,7 @@ func (r *RestAPI) UpdateSubnetBegin(ctx context.Context, params dhcp.UpdateSubne
 
 // Implements the POST call and commits an updated subnet (subnets/{subnetId}/transaction/{id}/submit).
 func (r *RestAPI) UpdateSubnetSubmit(ctx context.Context, params dhcp.UpdateSubnetSubmitParams) middleware.Responder {
-	if code, _, msg := r.commonCreateOrUpdateSubnetSubmit(ctx, params.ID, params.Subnet, r.ConfigManager.GetKeaModule().ApplySubnetUpdate); code != 0 {
+	if code, _, msg := r.commonCreateOrUpdateSubnetSubmit(ctx, params.ID, params.Subnet, r.ConfigManager.GetKeaModule().ApplySubnetUpdate, params.ScheduledAt); code != 0 {
 		// Error case.
 		rsp := dhcp.NewUpdateSubnetSubmitDefault(code).WithPayload(&models.APIError{
 			Message: &msg,
@@ -883,6 +941,21 @@ func (r *RestAPI) UpdateSubnetSubmit(ctx context.Context, params dhcp.UpdateSubn
 	return rsp
 }
 
+// Implements the POST call to preview the changes of the updated subnet
+// (subnets/{subnetId}/transaction/{id}/preview).
+func (r *RestAPI) UpdateSubnetPreview(ctx context.Context, params dhcp.UpdateSubnetPreviewParams) middleware.Responder {
+	preview, code, msg := r.commonCreateOrUpdateSubnetPreview(ctx, params.ID, params.Subnet, r.ConfigManager.GetKeaModule().ApplySubnetUpdate)
+	if code != 0 {
+		// Error case.
+		rsp := dhcp.NewUpdateSubnetPreviewDefault(code).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	rsp := dhcp.NewUpdateSubnetPreviewOK().WithPayload(preview)
+	return rsp
+}
+
 // Implements the DELETE call to cancel updating a subnet (subnets/{subnetId}/transaction/{id}).
 // It removes the specified transaction from the config manager, if the transaction exists.
 func (r *RestAPI) UpdateSubnetDelete(ctx context.Context, params dhcp.UpdateSubnetDeleteParams) middleware.Responder {
@@ -1614,6 +1614,72 @@ func TestCreateSubnet4BeginSubmit(t *testing.T) {
 	}
 }
 
+// Test that the changes of the new subnet can be previewed before
+// submitting them and that previewing doesn't send any commands.
+func TestCreateSubnet4BeginPreview(t *testing.T) {
+	db, dbSettings, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	daemons := addTestClientClassServers(t, db, `{
+		"Dhcp4": {
+			"hooks-libraries": [
+				{
+					"library": "libdhcp_subnet_cmds"
+				}
+			]
+		}
+	}`)
+
+	rapi, fa, ctx := newTestClientClassRestAPI(t, db, dbSettings)
+
+	// Begin transaction.
+	rsp := rapi.CreateSubnetBegin(ctx, dhcp.CreateSubnetBeginParams{})
+	require.IsType(t, &dhcp.CreateSubnetBeginOK{}, rsp)
+	transactionID := rsp.(*dhcp.CreateSubnetBeginOK).Payload.ID
+
+	// Preview the changes.
+	rsp = rapi.CreateSubnetPreview(ctx, dhcp.CreateSubnetPreviewParams{
+		ID: transactionID,
+		Subnet: &models.Subnet{
+			Subnet: "192.0.2.0/24",
+			LocalSubnets: []*models.LocalSubnet{
+				{
+					ID:       1,
+					DaemonID: daemons[0].ID,
+					Pools: []*models.Pool{
+						{
+							Pool: storkutil.Ptr("192.0.2.10-192.0.2.20"),
+						},
+					},
+				},
+			},
+		},
+	})
+	require.IsType(t, &dhcp.CreateSubnetPreviewOK{}, rsp)
+	preview := rsp.(*dhcp.CreateSubnetPreviewOK).Payload
+	require.Len(t, preview.Items, 1)
+	require.Equal(t, daemons[0].ID, preview.Items[0].DaemonID)
+	require.NotEmpty(t, preview.Items[0].Commands)
+
+	// The whole subnet should be reported as added.
+	require.Len(t, preview.Items[0].Changes, 1)
+	change := preview.Items[0].Changes[0]
+	require.Equal(t, "/subnet4", change.Path)
+	require.Equal(t, "add", change.Op)
+	require.Nil(t, change.Before)
+	require.IsType(t, map[string]any{}, change.After)
+	subnet := change.After.(map[string]any)
+	require.EqualValues(t, 1, subnet["id"])
+	require.Equal(t, "192.0.2.0/24", subnet["subnet"])
+	require.Len(t, subnet["pools"], 1)
+
+	// Nothing should have been sent to the servers nor stored in the database.
+	require.Empty(t, fa.RecordedCommands)
+	subnets, err := dbmodel.GetAllSubnets(db, 4)
+	require.NoError(t, err)
+	require.Empty(t, subnets)
+}
+
 // Test error case when a user attempts to begin a new transaction when
 // there are no servers with subnet_cmds hook library found.
 func TestCreateSubnetBeginSubmitNoServers(t *testing.T) {
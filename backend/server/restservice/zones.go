@@ -2,10 +2,13 @@ package restservice
 
 import (
 	"context"
+	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/go-openapi/runtime/middleware"
 	"github.com/go-openapi/strfmt"
+	mdns "github.com/miekg/dns"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 	dbmodel "isc.org/stork/server/database/model"
@@ -15,6 +18,55 @@ import (
 	storkutil "isc.org/stork/util"
 )
 
+// Converts the DNSSEC state of a zone to the REST API format. It returns
+// nil if the DNSSEC state has not been determined yet.
+func convertZoneDNSSECToRestAPI(dnssec *dbmodel.ZoneDNSSEC) *models.ZoneDNSSEC {
+	if dnssec == nil {
+		return nil
+	}
+	restDNSSEC := &models.ZoneDNSSEC{
+		Signed:       dnssec.Signed,
+		Keys:         []*models.ZoneDNSSECKey{},
+		Ds:           []*models.ZoneDNSSECDS{},
+		Denial:       string(dnssec.Denial),
+		ExpiringSoon: dnssec.IsExpiringSoon(storkutil.UTCNow()),
+		RndcStatus:   dnssec.RndcStatus,
+		CheckedAt:    strfmt.DateTime(dnssec.CheckedAt),
+	}
+	for _, key := range dnssec.Keys {
+		restDNSSEC.Keys = append(restDNSSEC.Keys, &models.ZoneDNSSECKey{
+			KeyTag:    int64(key.KeyTag),
+			Algorithm: int64(key.Algorithm),
+			Flags:     int64(key.Flags),
+		})
+	}
+	for _, ds := range dnssec.DS {
+		restDNSSEC.Ds = append(restDNSSEC.Ds, &models.ZoneDNSSECDS{
+			KeyTag:     int64(ds.KeyTag),
+			Algorithm:  int64(ds.Algorithm),
+			DigestType: int64(ds.DigestType),
+		})
+	}
+	if dnssec.RRSIGExpiresAt != nil {
+		restDNSSEC.RrsigExpiresAt = strfmt.DateTime(*dnssec.RRSIGExpiresAt)
+	}
+	return restDNSSEC
+}
+
+// Converts the serial drift of a secondary zone to the REST API format.
+// It returns nil if the zone is not lagging behind the primary zone.
+func convertZoneSerialDriftToRestAPI(drift *dbmodel.ZoneSerialDrift, threshold time.Duration) *models.ZoneSerialDrift {
+	if drift == nil {
+		return nil
+	}
+	return &models.ZoneSerialDrift{
+		PrimaryDaemonID:  drift.PrimaryDaemonID,
+		PrimarySerial:    drift.PrimarySerial,
+		Since:            strfmt.DateTime(drift.Since),
+		ExceedsThreshold: drift.ExceedsThreshold(storkutil.UTCNow(), threshold),
+	}
+}
+
 // Returns a list DNS zones with paging.
 func (r *RestAPI) GetZones(ctx context.Context, params dns.GetZonesParams) middleware.Responder {
 	// Set paging parameters.
@@ -26,15 +78,31 @@ func (r *RestAPI) GetZones(ctx context.Context, params dns.GetZonesParams) middl
 	if params.Limit != nil {
 		limit = int(*params.Limit)
 	}
+	// The serial drift threshold is required to filter the zones and to
+	// indicate which secondary zones are drifted.
+	thresholdSeconds, err := dbmodel.GetSettingInt(r.DB, "zone_serial_drift_threshold")
+	if err != nil {
+		msg := "Failed to get zones from the database"
+		log.WithError(err).Error(msg)
+		rsp := dns.NewGetZonesDefault(http.StatusInternalServerError).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	serialDriftThreshold := time.Duration(thresholdSeconds) * time.Second
 	// Apply paging parameters and zone-specific filters.
 	filter := &dbmodel.GetZonesFilter{
-		AppID:   params.AppID,
-		AppType: params.AppType,
-		Class:   params.Class,
-		Serial:  params.Serial,
-		Text:    params.Text,
-		Offset:  storkutil.Ptr(offset),
-		Limit:   storkutil.Ptr(limit),
+		AppID:                params.AppID,
+		AppType:              params.AppType,
+		Class:                params.Class,
+		DNSSECExpiringSoon:   params.DnssecExpiringSoon,
+		Serial:               params.Serial,
+		SerialDrift:          params.SerialDrift,
+		SerialDriftThreshold: serialDriftThreshold,
+		Signed:               params.Signed,
+		Text:                 params.Text,
+		Offset:               storkutil.Ptr(offset),
+		Limit:                storkutil.Ptr(limit),
 	}
 	for _, zoneType := range params.ZoneType {
 		filter.EnableZoneType(dbmodel.ZoneType(zoneType))
@@ -56,14 +124,16 @@ func (r *RestAPI) GetZones(ctx context.Context, params dns.GetZonesParams) middl
 		var restLocalZones []*models.LocalZone
 		for _, localZone := range zone.LocalZones {
 			restLocalZones = append(restLocalZones, &models.LocalZone{
-				AppID:    localZone.Daemon.App.ID,
-				AppName:  localZone.Daemon.App.Name,
-				Class:    localZone.Class,
-				DaemonID: localZone.DaemonID,
-				LoadedAt: strfmt.DateTime(localZone.LoadedAt),
-				Serial:   localZone.Serial,
-				View:     localZone.View,
-				ZoneType: localZone.Type,
+				AppID:       localZone.Daemon.App.ID,
+				AppName:     localZone.Daemon.App.Name,
+				Class:       localZone.Class,
+				DaemonID:    localZone.DaemonID,
+				LoadedAt:    strfmt.DateTime(localZone.LoadedAt),
+				Serial:      localZone.Serial,
+				View:        localZone.View,
+				ZoneType:    localZone.Type,
+				Dnssec:      convertZoneDNSSECToRestAPI(localZone.DNSSEC),
+				SerialDrift: convertZoneSerialDriftToRestAPI(localZone.SerialDrift, serialDriftThreshold),
 			})
 		}
 		restZones = append(restZones, &models.Zone{
@@ -142,3 +212,365 @@ func (r *RestAPI) PutZonesFetch(ctx context.Context, params dns.PutZonesFetchPar
 		return rsp
 	}
 }
+
+// Selects the local zone for which the resource records are returned. The
+// local zone can be selected by the daemon ID and/or view. If multiple local
+// zones match, the one from the primary server is preferred. Otherwise, the
+// local zone with the lowest ID is selected. It returns nil if no local zone
+// matches.
+func selectLocalZone(zone *dbmodel.Zone, daemonID *int64, view *string) *dbmodel.LocalZone {
+	var selected *dbmodel.LocalZone
+	for _, localZone := range zone.LocalZones {
+		if (daemonID != nil && localZone.DaemonID != *daemonID) || (view != nil && localZone.View != *view) {
+			continue
+		}
+		switch {
+		case selected == nil:
+			selected = localZone
+		case (localZone.Type == "primary") != (selected.Type == "primary"):
+			if localZone.Type == "primary" {
+				selected = localZone
+			}
+		case localZone.ID < selected.ID:
+			selected = localZone
+		}
+	}
+	return selected
+}
+
+// Returns the resource records of the DNS zone with paging. The records
+// are transferred from the DNS server via the agent when they have not
+// been cached in the database for the current zone serial or when the
+// refresh is requested.
+func (r *RestAPI) GetZoneRRs(ctx context.Context, params dns.GetZoneRRsParams) middleware.Responder {
+	zone, err := dbmodel.GetZoneByID(r.DB, params.ID, dbmodel.ZoneRelationLocalZones)
+	if err != nil {
+		msg := fmt.Sprintf("Failed to get zone with ID %d from the database", params.ID)
+		log.WithError(err).Error(msg)
+		rsp := dns.NewGetZoneRRsDefault(http.StatusInternalServerError).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	if zone == nil {
+		msg := fmt.Sprintf("Cannot find zone with ID %d", params.ID)
+		rsp := dns.NewGetZoneRRsDefault(http.StatusNotFound).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	localZone := selectLocalZone(zone, params.DaemonID, params.View)
+	if localZone == nil {
+		msg := fmt.Sprintf("Cannot find zone %s served by the specified DNS server and view", zone.Name)
+		rsp := dns.NewGetZoneRRsDefault(http.StatusNotFound).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	// Transfer the zone if the cached records are outdated.
+	if !localZone.HasCurrentRRs() || (params.Refresh != nil && *params.Refresh) {
+		if err = r.DNSManager.FetchZoneRRs(ctx, zone.Name, localZone); err != nil {
+			msg := fmt.Sprintf("Failed to transfer zone %s from the DNS server", zone.Name)
+			log.WithError(err).Error(msg)
+			msg = fmt.Sprintf("%s: %s", msg, err)
+			rsp := dns.NewGetZoneRRsDefault(http.StatusInternalServerError).WithPayload(&models.APIError{
+				Message: &msg,
+			})
+			return rsp
+		}
+		// Get the zone transfer time.
+		if transferred, err := dbmodel.GetZoneByID(r.DB, params.ID, dbmodel.ZoneRelationLocalZones); err == nil && transferred != nil {
+			for _, lz := range transferred.LocalZones {
+				if lz.ID == localZone.ID {
+					localZone = lz
+				}
+			}
+		}
+	}
+	// Set paging parameters.
+	var offset int
+	if params.Start != nil {
+		offset = int(*params.Start)
+	}
+	limit := 10
+	if params.Limit != nil {
+		limit = int(*params.Limit)
+	}
+	filter := &dbmodel.GetLocalZoneRRsFilter{
+		Offset: storkutil.Ptr(offset),
+		Limit:  storkutil.Ptr(limit),
+		Type:   params.RrType,
+		Text:   params.Text,
+	}
+	rrs, total, err := dbmodel.GetLocalZoneRRs(r.DB, localZone.ID, filter)
+	if err != nil {
+		msg := fmt.Sprintf("Failed to get resource records of zone %s from the database", zone.Name)
+		log.WithError(err).Error(msg)
+		rsp := dns.NewGetZoneRRsDefault(http.StatusInternalServerError).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	// Convert the records to the REST API format.
+	restRRs := []*models.ZoneRR{}
+	for _, rr := range rrs {
+		restRRs = append(restRRs, &models.ZoneRR{
+			Name:    rr.Name,
+			TTL:     rr.TTL,
+			RrClass: rr.Class,
+			RrType:  rr.Type,
+			Data:    rr.Data,
+		})
+	}
+	payload := models.ZoneRRs{
+		Items:    restRRs,
+		Total:    int64(total),
+		DaemonID: localZone.DaemonID,
+		View:     localZone.View,
+	}
+	if localZone.ZoneTransferAt != nil {
+		payload.ZoneTransferAt = strfmt.DateTime(*localZone.ZoneTransferAt)
+	}
+	rsp := dns.NewGetZoneRRsOK().WithPayload(&payload)
+	return rsp
+}
+
+// Converts the record change received over the REST API to the resource
+// record. The owner name not ending with a dot is relative to the zone
+// name and the @ character denotes the zone apex.
+func convertZoneRRChangeFromRestAPI(zoneName string, change *models.ZoneRRChange) (mdns.RR, error) {
+	name := *change.Name
+	switch {
+	case name == "@":
+		name = mdns.Fqdn(zoneName)
+	case !mdns.IsFqdn(name):
+		name = fmt.Sprintf("%s.%s", name, mdns.Fqdn(zoneName))
+	}
+	rr, err := mdns.NewRR(fmt.Sprintf("%s %d IN %s %s", name, change.TTL, *change.RrType, *change.Data))
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid %s record %s", *change.RrType, *change.Name)
+	}
+	if rr == nil {
+		return nil, errors.Errorf("empty %s record %s", *change.RrType, *change.Name)
+	}
+	return rr, nil
+}
+
+// Deletes and adds the resource records of the DNS zone served by the
+// selected DNS server. The changes are sent to the DNS server in a single
+// dynamic update via the agent. The DNS Manager verifies that the user is
+// allowed to update the zone and raises an event upon success.
+func (r *RestAPI) UpdateZoneRRs(ctx context.Context, params dns.UpdateZoneRRsParams) middleware.Responder {
+	if params.Update == nil || len(params.Update.Changes) == 0 {
+		msg := "No resource record changes specified"
+		rsp := dns.NewUpdateZoneRRsDefault(http.StatusBadRequest).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	zone, err := dbmodel.GetZoneByID(r.DB, params.ID, dbmodel.ZoneRelationLocalZones)
+	if err != nil {
+		msg := fmt.Sprintf("Failed to get zone with ID %d from the database", params.ID)
+		log.WithError(err).Error(msg)
+		rsp := dns.NewUpdateZoneRRsDefault(http.StatusInternalServerError).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	if zone == nil {
+		msg := fmt.Sprintf("Cannot find zone with ID %d", params.ID)
+		rsp := dns.NewUpdateZoneRRsDefault(http.StatusNotFound).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	var (
+		daemonID *int64
+		view     *string
+	)
+	if params.Update.DaemonID != 0 {
+		daemonID = &params.Update.DaemonID
+	}
+	if params.Update.View != "" {
+		view = &params.Update.View
+	}
+	localZone := selectLocalZone(zone, daemonID, view)
+	if localZone == nil {
+		msg := fmt.Sprintf("Cannot find zone %s served by the specified DNS server and view", zone.Name)
+		rsp := dns.NewUpdateZoneRRsDefault(http.StatusNotFound).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	var deleteRRs, addRRs []mdns.RR
+	for _, change := range params.Update.Changes {
+		rr, err := convertZoneRRChangeFromRestAPI(zone.Name, change)
+		if err != nil {
+			msg := fmt.Sprintf("Failed to parse the resource record change: %s", err)
+			rsp := dns.NewUpdateZoneRRsDefault(http.StatusBadRequest).WithPayload(&models.APIError{
+				Message: &msg,
+			})
+			return rsp
+		}
+		if *change.Operation == models.ZoneRRChangeOperationDelete {
+			deleteRRs = append(deleteRRs, rr)
+		} else {
+			addRRs = append(addRRs, rr)
+		}
+	}
+	_, dbUser := r.SessionManager.Logged(ctx)
+	err = r.DNSManager.UpdateZone(ctx, dbUser, zone.Name, localZone, deleteRRs, addRRs)
+	if err != nil {
+		var (
+			permissionErr *dnsop.ManagerPermissionDeniedError
+			invalidErr    *dnsop.ManagerInvalidZoneUpdateError
+			code          int
+			msg           string
+		)
+		switch {
+		case errors.As(err, &permissionErr):
+			code = http.StatusForbidden
+			msg = fmt.Sprintf("You are not allowed to update zone %s", zone.Name)
+		case errors.As(err, &invalidErr):
+			code = http.StatusBadRequest
+			msg = fmt.Sprintf("Invalid update of zone %s: %s", zone.Name, err)
+		default:
+			code = http.StatusInternalServerError
+			msg = fmt.Sprintf("Failed to update zone %s", zone.Name)
+			log.WithError(err).Error(msg)
+			msg = fmt.Sprintf("%s: %s", msg, err)
+		}
+		rsp := dns.NewUpdateZoneRRsDefault(code).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	rsp := dns.NewUpdateZoneRRsOK()
+	return rsp
+}
+
+// Returns the users who have been granted the permission to update the
+// zone. Only the super-admin users are allowed to list them.
+func (r *RestAPI) GetZoneUpdatePermissions(ctx context.Context, params dns.GetZoneUpdatePermissionsParams) middleware.Responder {
+	_, dbUser := r.SessionManager.Logged(ctx)
+	if dbUser == nil || !dbUser.InGroup(&dbmodel.SystemGroup{ID: dbmodel.SuperAdminGroupID}) {
+		msg := "Only super-admin users are allowed to get the permissions to update the DNS zones"
+		rsp := dns.NewGetZoneUpdatePermissionsDefault(http.StatusForbidden).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	zone, err := dbmodel.GetZoneByID(r.DB, params.ID)
+	if err != nil {
+		msg := fmt.Sprintf("Failed to get zone with ID %d from the database", params.ID)
+		log.WithError(err).Error(msg)
+		rsp := dns.NewGetZoneUpdatePermissionsDefault(http.StatusInternalServerError).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	if zone == nil {
+		msg := fmt.Sprintf("Cannot find zone with ID %d", params.ID)
+		rsp := dns.NewGetZoneUpdatePermissionsDefault(http.StatusNotFound).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	permissions, err := dbmodel.GetZoneUpdatePermissions(r.DB, zone.ID)
+	if err != nil {
+		msg := fmt.Sprintf("Failed to get the permissions to update zone %s from the database", zone.Name)
+		log.WithError(err).Error(msg)
+		rsp := dns.NewGetZoneUpdatePermissionsDefault(http.StatusInternalServerError).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	payload := &models.ZoneUpdatePermissions{
+		Items: []*models.ZoneUpdatePermission{},
+		Total: int64(len(permissions)),
+	}
+	for _, permission := range permissions {
+		item := &models.ZoneUpdatePermission{
+			UserID: int64(permission.UserID),
+		}
+		if permission.User != nil {
+			item.Login = permission.User.Login
+			item.Email = permission.User.Email
+		}
+		payload.Items = append(payload.Items, item)
+	}
+	rsp := dns.NewGetZoneUpdatePermissionsOK().WithPayload(payload)
+	return rsp
+}
+
+// Grants the permission to update the zone to the specified users and
+// revokes it from the other users. Only the super-admin users are allowed
+// to manage the permissions.
+func (r *RestAPI) UpdateZoneUpdatePermissions(ctx context.Context, params dns.UpdateZoneUpdatePermissionsParams) middleware.Responder {
+	_, dbUser := r.SessionManager.Logged(ctx)
+	if dbUser == nil || !dbUser.InGroup(&dbmodel.SystemGroup{ID: dbmodel.SuperAdminGroupID}) {
+		msg := "Only super-admin users are allowed to set the permissions to update the DNS zones"
+		rsp := dns.NewUpdateZoneUpdatePermissionsDefault(http.StatusForbidden).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	if params.Permissions == nil {
+		msg := "No users specified"
+		rsp := dns.NewUpdateZoneUpdatePermissionsDefault(http.StatusBadRequest).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	zone, err := dbmodel.GetZoneByID(r.DB, params.ID)
+	if err != nil {
+		msg := fmt.Sprintf("Failed to get zone with ID %d from the database", params.ID)
+		log.WithError(err).Error(msg)
+		rsp := dns.NewUpdateZoneUpdatePermissionsDefault(http.StatusInternalServerError).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	if zone == nil {
+		msg := fmt.Sprintf("Cannot find zone with ID %d", params.ID)
+		rsp := dns.NewUpdateZoneUpdatePermissionsDefault(http.StatusNotFound).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	var userIDs []int
+	for _, userID := range params.Permissions.UserIds {
+		user, err := dbmodel.GetUserByID(r.DB, int(userID))
+		if err != nil {
+			msg := fmt.Sprintf("Failed to get user with ID %d from the database", userID)
+			log.WithError(err).Error(msg)
+			rsp := dns.NewUpdateZoneUpdatePermissionsDefault(http.StatusInternalServerError).WithPayload(&models.APIError{
+				Message: &msg,
+			})
+			return rsp
+		}
+		if user == nil {
+			msg := fmt.Sprintf("Cannot find user with ID %d", userID)
+			rsp := dns.NewUpdateZoneUpdatePermissionsDefault(http.StatusBadRequest).WithPayload(&models.APIError{
+				Message: &msg,
+			})
+			return rsp
+		}
+		userIDs = append(userIDs, user.ID)
+	}
+	if err = dbmodel.SetZoneUpdatePermissions(r.DB, zone.ID, userIDs); err != nil {
+		msg := fmt.Sprintf("Failed to set the permissions to update zone %s", zone.Name)
+		log.WithError(err).Error(msg)
+		rsp := dns.NewUpdateZoneUpdatePermissionsDefault(http.StatusInternalServerError).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	log.WithFields(log.Fields{
+		"zone":  zone.Name,
+		"user":  dbUser.Login,
+		"users": userIDs,
+	}).Info("Set the permissions to update the zone")
+	rsp := dns.NewUpdateZoneUpdatePermissionsOK()
+	return rsp
+}
@@ -8,8 +8,11 @@ import (
 	"testing"
 	"time"
 
+	"github.com/go-pg/pg/v10"
+	mdns "github.com/miekg/dns"
 	"github.com/stretchr/testify/require"
 	gomock "go.uber.org/mock/gomock"
+	dbops "isc.org/stork/server/database"
 	dbmodel "isc.org/stork/server/database/model"
 	dbtest "isc.org/stork/server/database/test"
 	"isc.org/stork/server/dnsop"
@@ -34,6 +37,9 @@ func TestGetZones(t *testing.T) {
 	db, dbSettings, teardown := dbtest.SetupDatabaseTestCase(t)
 	defer teardown()
 
+	err := dbmodel.InitializeSettings(db, 0)
+	require.NoError(t, err)
+
 	settings := RestAPISettings{}
 	rapi, err := NewRestAPI(&settings, dbSettings, db)
 	require.NoError(t, err)
@@ -554,3 +560,683 @@ func TestPutZonesFetchError(t *testing.T) {
 	require.Equal(t, http.StatusInternalServerError, getStatusCode(*defaultRsp))
 	require.Equal(t, "Failed to start fetching the zones", *defaultRsp.Payload.Message)
 }
+
+// Test selecting the local zone for which the resource records are returned.
+func TestSelectLocalZone(t *testing.T) {
+	zone := &dbmodel.Zone{
+		Name: "example.com",
+		LocalZones: []*dbmodel.LocalZone{
+			{ID: 4, DaemonID: 1, View: "_default", Type: "secondary"},
+			{ID: 3, DaemonID: 2, View: "_default", Type: "secondary"},
+			{ID: 2, DaemonID: 3, View: "_default", Type: "primary"},
+			{ID: 1, DaemonID: 3, View: "guest", Type: "secondary"},
+		},
+	}
+	t.Run("primary preferred", func(t *testing.T) {
+		localZone := selectLocalZone(zone, nil, nil)
+		require.NotNil(t, localZone)
+		require.EqualValues(t, 2, localZone.ID)
+	})
+	t.Run("lowest ID", func(t *testing.T) {
+		localZone := selectLocalZone(zone, nil, storkutil.Ptr("guest"))
+		require.NotNil(t, localZone)
+		require.EqualValues(t, 1, localZone.ID)
+	})
+	t.Run("by daemon", func(t *testing.T) {
+		localZone := selectLocalZone(zone, storkutil.Ptr(int64(2)), nil)
+		require.NotNil(t, localZone)
+		require.EqualValues(t, 3, localZone.ID)
+	})
+	t.Run("by daemon and view", func(t *testing.T) {
+		localZone := selectLocalZone(zone, storkutil.Ptr(int64(3)), storkutil.Ptr("guest"))
+		require.NotNil(t, localZone)
+		require.EqualValues(t, 1, localZone.ID)
+	})
+	t.Run("no match", func(t *testing.T) {
+		require.Nil(t, selectLocalZone(zone, storkutil.Ptr(int64(1)), storkutil.Ptr("guest")))
+	})
+}
+
+// Adds a zone served by two BIND 9 servers to the database.
+func addTestZoneForRRs(t *testing.T, db *pg.DB) *dbmodel.Zone {
+	var localZones []*dbmodel.LocalZone
+	for i, zoneType := range []string{"secondary", "primary"} {
+		machine := &dbmodel.Machine{
+			ID:        0,
+			Address:   "localhost",
+			AgentPort: int64(8080 + i),
+		}
+		err := dbmodel.AddMachine(db, machine)
+		require.NoError(t, err)
+
+		app := &dbmodel.App{
+			ID:        0,
+			MachineID: machine.ID,
+			Type:      dbmodel.AppTypeBind9,
+			Name:      fmt.Sprintf("app-%d", i),
+			Daemons: []*dbmodel.Daemon{
+				dbmodel.NewBind9Daemon(true),
+			},
+		}
+		addedDaemons, err := dbmodel.AddApp(db, app)
+		require.NoError(t, err)
+		localZones = append(localZones, &dbmodel.LocalZone{
+			DaemonID: addedDaemons[0].ID,
+			View:     "_default",
+			Class:    "IN",
+			Serial:   2024031501,
+			Type:     zoneType,
+			LoadedAt: time.Now().UTC(),
+		})
+	}
+	err := dbmodel.AddZones(db, &dbmodel.Zone{
+		Name:       "example.com",
+		LocalZones: localZones,
+	})
+	require.NoError(t, err)
+
+	zones, _, err := dbmodel.GetZones(db, nil, dbmodel.ZoneRelationLocalZones)
+	require.NoError(t, err)
+	require.Len(t, zones, 1)
+	return zones[0]
+}
+
+// Test getting the resource records of a zone over the REST API. The
+// records should be transferred only when they are not cached for the
+// current serial or when the refresh is requested.
+func TestGetZoneRRs(t *testing.T) {
+	db, dbSettings, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	zone := addTestZoneForRRs(t, db)
+
+	ctrl := gomock.NewController(t)
+	mockManager := NewMockManager(ctrl)
+	mockManager.EXPECT().FetchZoneRRs(gomock.Any(), "example.com", gomock.Any()).Times(2).DoAndReturn(func(ctx context.Context, zoneName string, localZone *dbmodel.LocalZone) error {
+		var rrs []*dbmodel.LocalZoneRR
+		for _, text := range []string{
+			"example.com. 3600 IN SOA ns1.example.com. admin.example.com. 2024031501 43200 3600 1209600 3600",
+			"example.com. 3600 IN NS ns1.example.com.",
+			"ns1.example.com. 3600 IN A 192.0.2.1",
+			"www.example.com. 300 IN AAAA 2001:db8::1",
+		} {
+			rr, err := mdns.NewRR(text)
+			require.NoError(t, err)
+			rrs = append(rrs, dbmodel.NewLocalZoneRR(rr))
+		}
+		return dbmodel.ReplaceLocalZoneRRs(db, localZone.ID, localZone.Serial, time.Now().UTC(), rrs)
+	})
+
+	settings := RestAPISettings{}
+	rapi, err := NewRestAPI(&settings, dbSettings, db, mockManager)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	// The records are transferred from the primary server.
+	params := dns.GetZoneRRsParams{
+		ID: zone.ID,
+	}
+	rsp := rapi.GetZoneRRs(ctx, params)
+	require.IsType(t, &dns.GetZoneRRsOK{}, rsp)
+	payload := rsp.(*dns.GetZoneRRsOK).Payload
+	require.EqualValues(t, 4, payload.Total)
+	require.Len(t, payload.Items, 4)
+	require.Equal(t, "_default", payload.View)
+	require.Equal(t, zone.LocalZones[1].DaemonID, payload.DaemonID)
+	require.NotZero(t, payload.ZoneTransferAt)
+	require.Equal(t, "www.example.com.", payload.Items[3].Name)
+	require.EqualValues(t, 300, payload.Items[3].TTL)
+	require.Equal(t, "IN", payload.Items[3].RrClass)
+	require.Equal(t, "AAAA", payload.Items[3].RrType)
+	require.Equal(t, "2001:db8::1", payload.Items[3].Data)
+
+	// The records are cached. Filter them by type.
+	params.RrType = storkutil.Ptr("A")
+	rsp = rapi.GetZoneRRs(ctx, params)
+	require.IsType(t, &dns.GetZoneRRsOK{}, rsp)
+	payload = rsp.(*dns.GetZoneRRsOK).Payload
+	require.EqualValues(t, 1, payload.Total)
+	require.Equal(t, "ns1.example.com.", payload.Items[0].Name)
+
+	// Filter by name with paging.
+	params = dns.GetZoneRRsParams{
+		ID:    zone.ID,
+		Text:  storkutil.Ptr("example"),
+		Start: storkutil.Ptr(int64(1)),
+		Limit: storkutil.Ptr(int64(2)),
+	}
+	rsp = rapi.GetZoneRRs(ctx, params)
+	require.IsType(t, &dns.GetZoneRRsOK{}, rsp)
+	payload = rsp.(*dns.GetZoneRRsOK).Payload
+	require.EqualValues(t, 4, payload.Total)
+	require.Len(t, payload.Items, 2)
+	require.Equal(t, "NS", payload.Items[0].RrType)
+
+	// Force the zone transfer.
+	params = dns.GetZoneRRsParams{
+		ID:      zone.ID,
+		Refresh: storkutil.Ptr(true),
+	}
+	rsp = rapi.GetZoneRRs(ctx, params)
+	require.IsType(t, &dns.GetZoneRRsOK{}, rsp)
+}
+
+// Test that an error is returned when the zone or the local zone does
+// not exist.
+func TestGetZoneRRsNotFound(t *testing.T) {
+	db, dbSettings, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	zone := addTestZoneForRRs(t, db)
+
+	ctrl := gomock.NewController(t)
+	mockManager := NewMockManager(ctrl)
+
+	settings := RestAPISettings{}
+	rapi, err := NewRestAPI(&settings, dbSettings, db, mockManager)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	params := dns.GetZoneRRsParams{
+		ID: zone.ID + 1,
+	}
+	rsp := rapi.GetZoneRRs(ctx, params)
+	require.IsType(t, &dns.GetZoneRRsDefault{}, rsp)
+	defaultRsp := rsp.(*dns.GetZoneRRsDefault)
+	require.Equal(t, http.StatusNotFound, getStatusCode(*defaultRsp))
+	require.Equal(t, fmt.Sprintf("Cannot find zone with ID %d", zone.ID+1), *defaultRsp.Payload.Message)
+
+	params = dns.GetZoneRRsParams{
+		ID:   zone.ID,
+		View: storkutil.Ptr("guest"),
+	}
+	rsp = rapi.GetZoneRRs(ctx, params)
+	require.IsType(t, &dns.GetZoneRRsDefault{}, rsp)
+	defaultRsp = rsp.(*dns.GetZoneRRsDefault)
+	require.Equal(t, http.StatusNotFound, getStatusCode(*defaultRsp))
+	require.Equal(t, "Cannot find zone example.com served by the specified DNS server and view", *defaultRsp.Payload.Message)
+}
+
+// Test that an error is returned when the zone transfer fails.
+func TestGetZoneRRsTransferError(t *testing.T) {
+	db, dbSettings, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	zone := addTestZoneForRRs(t, db)
+
+	ctrl := gomock.NewController(t)
+	mockManager := NewMockManager(ctrl)
+	mockManager.EXPECT().FetchZoneRRs(gomock.Any(), "example.com", gomock.Any()).Return(&testError{})
+
+	settings := RestAPISettings{}
+	rapi, err := NewRestAPI(&settings, dbSettings, db, mockManager)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	params := dns.GetZoneRRsParams{
+		ID:       zone.ID,
+		DaemonID: storkutil.Ptr(zone.LocalZones[0].DaemonID),
+	}
+	rsp := rapi.GetZoneRRs(ctx, params)
+	require.IsType(t, &dns.GetZoneRRsDefault{}, rsp)
+	defaultRsp := rsp.(*dns.GetZoneRRsDefault)
+	require.Equal(t, http.StatusInternalServerError, getStatusCode(*defaultRsp))
+	require.Equal(t, "Failed to transfer zone example.com from the DNS server: test error", *defaultRsp.Payload.Message)
+}
+
+// Test converting the DNSSEC state of a zone to the REST API format.
+func TestConvertZoneDNSSECToRestAPI(t *testing.T) {
+	require.Nil(t, convertZoneDNSSECToRestAPI(nil))
+
+	checkedAt := time.Date(2025, 3, 15, 10, 0, 0, 0, time.UTC)
+	expiresAt := storkutil.UTCNow().Add(time.Hour)
+	dnssec := convertZoneDNSSECToRestAPI(&dbmodel.ZoneDNSSEC{
+		Signed: true,
+		Keys: []dbmodel.ZoneDNSSECKey{
+			{KeyTag: 12345, Algorithm: 13, Flags: 257},
+		},
+		DS: []dbmodel.ZoneDNSSECDS{
+			{KeyTag: 12345, Algorithm: 13, DigestType: 2},
+		},
+		Denial:         dbmodel.ZoneDNSSECDenialNSEC,
+		RRSIGExpiresAt: &expiresAt,
+		RndcStatus:     "dnssec-policy: default",
+		CheckedAt:      checkedAt,
+	})
+	require.NotNil(t, dnssec)
+	require.True(t, dnssec.Signed)
+	require.True(t, dnssec.ExpiringSoon)
+	require.Equal(t, "NSEC", dnssec.Denial)
+	require.Len(t, dnssec.Keys, 1)
+	require.EqualValues(t, 12345, dnssec.Keys[0].KeyTag)
+	require.EqualValues(t, 13, dnssec.Keys[0].Algorithm)
+	require.EqualValues(t, 257, dnssec.Keys[0].Flags)
+	require.Len(t, dnssec.Ds, 1)
+	require.EqualValues(t, 12345, dnssec.Ds[0].KeyTag)
+	require.EqualValues(t, 2, dnssec.Ds[0].DigestType)
+	require.Equal(t, expiresAt, time.Time(dnssec.RrsigExpiresAt))
+	require.Equal(t, "dnssec-policy: default", dnssec.RndcStatus)
+	require.Equal(t, checkedAt, time.Time(dnssec.CheckedAt))
+
+	// Unsigned zone.
+	dnssec = convertZoneDNSSECToRestAPI(&dbmodel.ZoneDNSSEC{
+		CheckedAt: checkedAt,
+	})
+	require.NotNil(t, dnssec)
+	require.False(t, dnssec.Signed)
+	require.False(t, dnssec.ExpiringSoon)
+	require.Empty(t, dnssec.Keys)
+	require.Zero(t, dnssec.RrsigExpiresAt)
+}
+
+// Test converting the serial drift of a secondary zone to the REST API format.
+func TestConvertZoneSerialDriftToRestAPI(t *testing.T) {
+	require.Nil(t, convertZoneSerialDriftToRestAPI(nil, time.Hour))
+
+	since := storkutil.UTCNow().Add(-2 * time.Hour)
+	drift := convertZoneSerialDriftToRestAPI(&dbmodel.ZoneSerialDrift{
+		Serial:          2024031501,
+		PrimaryDaemonID: 5,
+		PrimarySerial:   2024031502,
+		Since:           since,
+	}, time.Hour)
+	require.NotNil(t, drift)
+	require.EqualValues(t, 5, drift.PrimaryDaemonID)
+	require.EqualValues(t, 2024031502, drift.PrimarySerial)
+	require.Equal(t, since, time.Time(drift.Since))
+	require.True(t, drift.ExceedsThreshold)
+
+	// The secondary zone has not been lagging behind for long enough.
+	drift = convertZoneSerialDriftToRestAPI(&dbmodel.ZoneSerialDrift{
+		Since: since,
+	}, 3*time.Hour)
+	require.NotNil(t, drift)
+	require.False(t, drift.ExceedsThreshold)
+}
+
+// Test getting the zones with the serial drift over the REST API.
+func TestGetZonesSerialDrift(t *testing.T) {
+	db, dbSettings, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	err := dbmodel.InitializeSettings(db, 0)
+	require.NoError(t, err)
+
+	zone := addTestZoneForRRs(t, db)
+	secondary, primary := zone.LocalZones[0], zone.LocalZones[1]
+	if secondary.Type != "secondary" {
+		secondary, primary = primary, secondary
+	}
+	err = dbmodel.UpdateLocalZoneSerialDrift(db, secondary.ID, &dbmodel.ZoneSerialDrift{
+		Serial:          secondary.Serial,
+		PrimaryDaemonID: primary.DaemonID,
+		PrimarySerial:   secondary.Serial + 1,
+		Since:           storkutil.UTCNow().Add(-2 * time.Hour),
+	})
+	require.NoError(t, err)
+
+	settings := RestAPISettings{}
+	rapi, err := NewRestAPI(&settings, dbSettings, db)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	rsp := rapi.GetZones(ctx, dns.GetZonesParams{
+		SerialDrift: storkutil.Ptr(true),
+	})
+	require.IsType(t, &dns.GetZonesOK{}, rsp)
+	zones := rsp.(*dns.GetZonesOK).Payload
+	require.EqualValues(t, 1, zones.Total)
+	require.Len(t, zones.Items, 1)
+
+	var drifted []*models.ZoneSerialDrift
+	for _, localZone := range zones.Items[0].LocalZones {
+		if localZone.SerialDrift != nil {
+			drifted = append(drifted, localZone.SerialDrift)
+		}
+	}
+	require.Len(t, drifted, 1)
+	require.True(t, drifted[0].ExceedsThreshold)
+	require.Equal(t, primary.DaemonID, drifted[0].PrimaryDaemonID)
+
+	// Increase the threshold so the drift is no longer reported.
+	err = dbmodel.SetSettingInt(db, "zone_serial_drift_threshold", 3*3600)
+	require.NoError(t, err)
+
+	rsp = rapi.GetZones(ctx, dns.GetZonesParams{
+		SerialDrift: storkutil.Ptr(true),
+	})
+	require.IsType(t, &dns.GetZonesOK{}, rsp)
+	require.Zero(t, rsp.(*dns.GetZonesOK).Payload.Total)
+}
+
+// Test converting the record change received over the REST API to the
+// resource record.
+func TestConvertZoneRRChangeFromRestAPI(t *testing.T) {
+	change := &models.ZoneRRChange{
+		Operation: storkutil.Ptr(models.ZoneRRChangeOperationAdd),
+		Name:      storkutil.Ptr("www"),
+		TTL:       300,
+		RrType:    storkutil.Ptr("A"),
+		Data:      storkutil.Ptr("192.0.2.1"),
+	}
+	rr, err := convertZoneRRChangeFromRestAPI("example.com", change)
+	require.NoError(t, err)
+	require.Equal(t, "www.example.com.\t300\tIN\tA\t192.0.2.1", rr.String())
+
+	// Zone apex.
+	change.Name = storkutil.Ptr("@")
+	change.RrType = storkutil.Ptr("TXT")
+	change.Data = storkutil.Ptr(`"v=spf1 -all"`)
+	rr, err = convertZoneRRChangeFromRestAPI("example.com.", change)
+	require.NoError(t, err)
+	require.Equal(t, "example.com.\t300\tIN\tTXT\t\"v=spf1 -all\"", rr.String())
+
+	// Fully qualified name.
+	change.Name = storkutil.Ptr("mail.example.com.")
+	change.RrType = storkutil.Ptr("CNAME")
+	change.Data = storkutil.Ptr("www.example.com.")
+	rr, err = convertZoneRRChangeFromRestAPI("example.com", change)
+	require.NoError(t, err)
+	require.Equal(t, "mail.example.com.\t300\tIN\tCNAME\twww.example.com.", rr.String())
+
+	// Invalid data.
+	change.RrType = storkutil.Ptr("AAAA")
+	change.Data = storkutil.Ptr("192.0.2.1")
+	_, err = convertZoneRRChangeFromRestAPI("example.com", change)
+	require.ErrorContains(t, err, "invalid AAAA record mail.example.com.")
+}
+
+// Creates the REST API instance with a logged in super-admin user.
+func newTestZoneUpdateRestAPI(t *testing.T, db *pg.DB, dbSettings *dbops.DatabaseSettings, mockManager *MockManager) (*RestAPI, context.Context) {
+	settings := RestAPISettings{}
+	rapi, err := NewRestAPI(&settings, dbSettings, db, mockManager)
+	require.NoError(t, err)
+
+	ctx, err := rapi.SessionManager.Load(context.Background(), "")
+	require.NoError(t, err)
+	user, err := dbmodel.GetUserByID(rapi.DB, 1)
+	require.NoError(t, err)
+	err = rapi.SessionManager.LoginHandler(ctx, user)
+	require.NoError(t, err)
+	return rapi, ctx
+}
+
+// Test updating the zone records over the REST API.
+func TestUpdateZoneRRs(t *testing.T) {
+	db, dbSettings, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	zone := addTestZoneForRRs(t, db)
+
+	ctrl := gomock.NewController(t)
+	mockManager := NewMockManager(ctrl)
+	mockManager.EXPECT().UpdateZone(gomock.Any(), gomock.Cond(func(a any) bool {
+		return a.(*dbmodel.SystemUser).ID == 1
+	}), "example.com", gomock.Cond(func(a any) bool {
+		// The primary zone should be selected.
+		return a.(*dbmodel.LocalZone).ID == zone.LocalZones[1].ID
+	}), gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, user *dbmodel.SystemUser, zoneName string, localZone *dbmodel.LocalZone, deleteRRs, addRRs []mdns.RR) error {
+		require.Len(t, deleteRRs, 1)
+		require.Equal(t, "www.example.com.\t0\tIN\tA\t192.0.2.1", deleteRRs[0].String())
+		require.Len(t, addRRs, 2)
+		require.Equal(t, "www.example.com.\t300\tIN\tA\t192.0.2.2", addRRs[0].String())
+		require.Equal(t, "example.com.\t300\tIN\tTXT\t\"hello\"", addRRs[1].String())
+		return nil
+	})
+
+	rapi, ctx := newTestZoneUpdateRestAPI(t, db, dbSettings, mockManager)
+
+	params := dns.UpdateZoneRRsParams{
+		ID: zone.ID,
+		Update: &models.ZoneRRsUpdate{
+			Changes: []*models.ZoneRRChange{
+				{
+					Operation: storkutil.Ptr(models.ZoneRRChangeOperationDelete),
+					Name:      storkutil.Ptr("www"),
+					RrType:    storkutil.Ptr("A"),
+					Data:      storkutil.Ptr("192.0.2.1"),
+				},
+				{
+					Operation: storkutil.Ptr(models.ZoneRRChangeOperationAdd),
+					Name:      storkutil.Ptr("www"),
+					TTL:       300,
+					RrType:    storkutil.Ptr("A"),
+					Data:      storkutil.Ptr("192.0.2.2"),
+				},
+				{
+					Operation: storkutil.Ptr(models.ZoneRRChangeOperationAdd),
+					Name:      storkutil.Ptr("@"),
+					TTL:       300,
+					RrType:    storkutil.Ptr("TXT"),
+					Data:      storkutil.Ptr(`"hello"`),
+				},
+			},
+		},
+	}
+	rsp := rapi.UpdateZoneRRs(ctx, params)
+	require.IsType(t, &dns.UpdateZoneRRsOK{}, rsp)
+}
+
+// Test the errors returned when updating the zone records over the
+// REST API.
+func TestUpdateZoneRRsErrors(t *testing.T) {
+	db, dbSettings, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	zone := addTestZoneForRRs(t, db)
+
+	ctrl := gomock.NewController(t)
+	mockManager := NewMockManager(ctrl)
+	gomock.InOrder(
+		mockManager.EXPECT().UpdateZone(gomock.Any(), gomock.Any(), "example.com", gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(&dnsop.ManagerPermissionDeniedError{}),
+		mockManager.EXPECT().UpdateZone(gomock.Any(), gomock.Any(), "example.com", gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(&dnsop.ManagerInvalidZoneUpdateError{}),
+		mockManager.EXPECT().UpdateZone(gomock.Any(), gomock.Any(), "example.com", gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(&testError{}),
+	)
+
+	rapi, ctx := newTestZoneUpdateRestAPI(t, db, dbSettings, mockManager)
+
+	newParams := func(zoneID int64, data string) dns.UpdateZoneRRsParams {
+		return dns.UpdateZoneRRsParams{
+			ID: zoneID,
+			Update: &models.ZoneRRsUpdate{
+				Changes: []*models.ZoneRRChange{
+					{
+						Operation: storkutil.Ptr(models.ZoneRRChangeOperationAdd),
+						Name:      storkutil.Ptr("www"),
+						TTL:       300,
+						RrType:    storkutil.Ptr("A"),
+						Data:      storkutil.Ptr(data),
+					},
+				},
+			},
+		}
+	}
+	getCode := func(rsp any) int {
+		require.IsType(t, &dns.UpdateZoneRRsDefault{}, rsp)
+		return getStatusCode(*rsp.(*dns.UpdateZoneRRsDefault))
+	}
+
+	// No changes.
+	rsp := rapi.UpdateZoneRRs(ctx, dns.UpdateZoneRRsParams{
+		ID:     zone.ID,
+		Update: &models.ZoneRRsUpdate{},
+	})
+	require.Equal(t, http.StatusBadRequest, getCode(rsp))
+
+	// Zone not found.
+	rsp = rapi.UpdateZoneRRs(ctx, newParams(zone.ID+1, "192.0.2.1"))
+	require.Equal(t, http.StatusNotFound, getCode(rsp))
+
+	// View not found.
+	params := newParams(zone.ID, "192.0.2.1")
+	params.Update.View = "guest"
+	rsp = rapi.UpdateZoneRRs(ctx, params)
+	require.Equal(t, http.StatusNotFound, getCode(rsp))
+
+	// Invalid record.
+	rsp = rapi.UpdateZoneRRs(ctx, newParams(zone.ID, "foo"))
+	require.Equal(t, http.StatusBadRequest, getCode(rsp))
+
+	// Permission denied.
+	rsp = rapi.UpdateZoneRRs(ctx, newParams(zone.ID, "192.0.2.1"))
+	require.Equal(t, http.StatusForbidden, getCode(rsp))
+	require.Equal(t, "You are not allowed to update zone example.com", *rsp.(*dns.UpdateZoneRRsDefault).Payload.Message)
+
+	// Invalid update.
+	rsp = rapi.UpdateZoneRRs(ctx, newParams(zone.ID, "192.0.2.1"))
+	require.Equal(t, http.StatusBadRequest, getCode(rsp))
+
+	// Agent error.
+	rsp = rapi.UpdateZoneRRs(ctx, newParams(zone.ID, "192.0.2.1"))
+	require.Equal(t, http.StatusInternalServerError, getCode(rsp))
+	require.Contains(t, *rsp.(*dns.UpdateZoneRRsDefault).Payload.Message, "test error")
+}
+
+// Test setting and getting the permissions to update the zone over the
+// REST API.
+func TestZoneUpdatePermissions(t *testing.T) {
+	db, dbSettings, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	zone := addTestZoneForRRs(t, db)
+
+	user := &dbmodel.SystemUser{
+		Login:    "user",
+		Email:    "user@example.org",
+		Lastname: "Doe",
+		Name:     "John",
+	}
+	_, err := dbmodel.CreateUser(db, user)
+	require.NoError(t, err)
+
+	ctrl := gomock.NewController(t)
+	rapi, ctx := newTestZoneUpdateRestAPI(t, db, dbSettings, NewMockManager(ctrl))
+
+	// Grant the permission.
+	rsp := rapi.UpdateZoneUpdatePermissions(ctx, dns.UpdateZoneUpdatePermissionsParams{
+		ID: zone.ID,
+		Permissions: &models.ZoneUpdatePermissionsUpdate{
+			UserIds: []int64{int64(user.ID)},
+		},
+	})
+	require.IsType(t, &dns.UpdateZoneUpdatePermissionsOK{}, rsp)
+
+	allowed, err := dbmodel.HasZoneUpdatePermission(db, user.ID, zone.ID)
+	require.NoError(t, err)
+	require.True(t, allowed)
+
+	rsp = rapi.GetZoneUpdatePermissions(ctx, dns.GetZoneUpdatePermissionsParams{
+		ID: zone.ID,
+	})
+	require.IsType(t, &dns.GetZoneUpdatePermissionsOK{}, rsp)
+	permissions := rsp.(*dns.GetZoneUpdatePermissionsOK).Payload
+	require.EqualValues(t, 1, permissions.Total)
+	require.Len(t, permissions.Items, 1)
+	require.EqualValues(t, user.ID, permissions.Items[0].UserID)
+	require.Equal(t, "user", permissions.Items[0].Login)
+	require.Equal(t, "user@example.org", permissions.Items[0].Email)
+
+	// Revoke the permission.
+	rsp = rapi.UpdateZoneUpdatePermissions(ctx, dns.UpdateZoneUpdatePermissionsParams{
+		ID:          zone.ID,
+		Permissions: &models.ZoneUpdatePermissionsUpdate{},
+	})
+	require.IsType(t, &dns.UpdateZoneUpdatePermissionsOK{}, rsp)
+
+	rsp = rapi.GetZoneUpdatePermissions(ctx, dns.GetZoneUpdatePermissionsParams{
+		ID: zone.ID,
+	})
+	require.IsType(t, &dns.GetZoneUpdatePermissionsOK{}, rsp)
+	permissions = rsp.(*dns.GetZoneUpdatePermissionsOK).Payload
+	require.Zero(t, permissions.Total)
+	require.Empty(t, permissions.Items)
+}
+
+// Test the errors returned when setting and getting the permissions to
+// update the zone over the REST API.
+func TestZoneUpdatePermissionsErrors(t *testing.T) {
+	db, dbSettings, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	zone := addTestZoneForRRs(t, db)
+
+	user := &dbmodel.SystemUser{
+		Login:    "user",
+		Email:    "user@example.org",
+		Lastname: "Doe",
+		Name:     "John",
+		Groups:   []*dbmodel.SystemGroup{{ID: dbmodel.AdminGroupID}},
+	}
+	_, err := dbmodel.CreateUser(db, user)
+	require.NoError(t, err)
+
+	ctrl := gomock.NewController(t)
+	rapi, ctx := newTestZoneUpdateRestAPI(t, db, dbSettings, NewMockManager(ctrl))
+
+	getCode := func(rsp any) int {
+		switch r := rsp.(type) {
+		case *dns.GetZoneUpdatePermissionsDefault:
+			return getStatusCode(*r)
+		case *dns.UpdateZoneUpdatePermissionsDefault:
+			return getStatusCode(*r)
+		default:
+			require.FailNow(t, "unexpected response type")
+			return 0
+		}
+	}
+
+	// Zone not found.
+	rsp := rapi.GetZoneUpdatePermissions(ctx, dns.GetZoneUpdatePermissionsParams{
+		ID: zone.ID + 1,
+	})
+	require.Equal(t, http.StatusNotFound, getCode(rsp))
+
+	rsp = rapi.UpdateZoneUpdatePermissions(ctx, dns.UpdateZoneUpdatePermissionsParams{
+		ID: zone.ID + 1,
+		Permissions: &models.ZoneUpdatePermissionsUpdate{
+			UserIds: []int64{int64(user.ID)},
+		},
+	})
+	require.Equal(t, http.StatusNotFound, getCode(rsp))
+
+	// No users specified.
+	rsp = rapi.UpdateZoneUpdatePermissions(ctx, dns.UpdateZoneUpdatePermissionsParams{
+		ID: zone.ID,
+	})
+	require.Equal(t, http.StatusBadRequest, getCode(rsp))
+
+	// User not found.
+	rsp = rapi.UpdateZoneUpdatePermissions(ctx, dns.UpdateZoneUpdatePermissionsParams{
+		ID: zone.ID,
+		Permissions: &models.ZoneUpdatePermissionsUpdate{
+			UserIds: []int64{int64(user.ID), 12345},
+		},
+	})
+	require.Equal(t, http.StatusBadRequest, getCode(rsp))
+
+	allowed, err := dbmodel.HasZoneUpdatePermission(db, user.ID, zone.ID)
+	require.NoError(t, err)
+	require.False(t, allowed)
+
+	// Only the super-admin users can manage the permissions.
+	ctx, err = rapi.SessionManager.Load(context.Background(), "")
+	require.NoError(t, err)
+	err = rapi.SessionManager.LoginHandler(ctx, user)
+	require.NoError(t, err)
+
+	rsp = rapi.GetZoneUpdatePermissions(ctx, dns.GetZoneUpdatePermissionsParams{
+		ID: zone.ID,
+	})
+	require.Equal(t, http.StatusForbidden, getCode(rsp))
+
+	rsp = rapi.UpdateZoneUpdatePermissions(ctx, dns.UpdateZoneUpdatePermissionsParams{
+		ID: zone.ID,
+		Permissions: &models.ZoneUpdatePermissionsUpdate{
+			UserIds: []int64{int64(user.ID)},
+		},
+	})
+	require.Equal(t, http.StatusForbidden, getCode(rsp))
+}
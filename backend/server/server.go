@@ -25,6 +25,7 @@ import (
 	"isc.org/stork/server/hookmanager"
 	"isc.org/stork/server/metrics"
 	"isc.org/stork/server/restservice"
+	storkutil "isc.org/stork/util"
 )
 
 // Global Stork Server state.
@@ -50,6 +51,8 @@ type StorkServer struct {
 	// Configuration manager instance. Note that it inherits some fields
 	// maintained by the server.
 	ConfigManager config.Manager
+	// Periodically commits the scheduled configuration changes.
+	ScheduledConfigChangesExecutor *storkutil.PeriodicExecutor
 	// Provides lookup functionality for DHCP option definitions.
 	DHCPOptionDefinitionLookup keaconfig.DHCPOptionDefinitionLookup
 	// Provides locking mechanism for daemon configurations.
@@ -147,6 +150,7 @@ func (ss *StorkServer) Bootstrap(reload bool) (err error) {
 	// Setup configuration review dispatcher.
 	ss.ReviewDispatcher = configreview.NewDispatcher(ss.DB)
 	configreview.RegisterDefaultCheckers(ss.ReviewDispatcher)
+	configreview.RegisterHookCheckers(ss.ReviewDispatcher, ss.HookManager.GetConfigReviewCheckers())
 	err = configreview.LoadAndValidateCheckerPreferences(ss.DB, ss.ReviewDispatcher)
 	if err != nil {
 		return err
@@ -199,6 +203,21 @@ func (ss *StorkServer) Bootstrap(reload bool) (err error) {
 		return err
 	}
 
+	// Create DNS Manager.
+	dnsManager := dnsop.NewManager(ss)
+
+	// Setup DNSSEC puller.
+	ss.Pullers.DNSSECPuller, err = dnsop.NewDNSSECPuller(ss.DB, ss.Agents, dnsManager)
+	if err != nil {
+		return err
+	}
+
+	// Setup zone serial drift puller.
+	ss.Pullers.SerialDriftPuller, err = dnsop.NewZoneSerialDriftPuller(ss.DB, ss.Agents, dnsManager)
+	if err != nil {
+		return err
+	}
+
 	if ss.GeneralSettings.EnableMetricsEndpoint {
 		ss.MetricsCollector, err = metrics.NewCollector(
 			metrics.NewDatabaseMetricsSource(ss.DB),
@@ -219,6 +238,12 @@ func (ss *StorkServer) Bootstrap(reload bool) (err error) {
 	// server startup.
 	ss.ConfigManager = apps.NewManager(ss)
 
+	// Setup the executor committing the scheduled configuration changes.
+	ss.ScheduledConfigChangesExecutor, err = apps.NewScheduledConfigChangesExecutor(ss.ConfigManager)
+	if err != nil {
+		return err
+	}
+
 	// Check if the machine registration endpoint should be disabled.
 	enableMachineRegistration, err := dbmodel.GetSettingBool(ss.DB, "enable_machine_registration")
 	if err != nil {
@@ -229,9 +254,6 @@ func (ss *StorkServer) Bootstrap(reload bool) (err error) {
 	endpointControl := restservice.NewEndpointControl()
 	endpointControl.SetEnabled(restservice.EndpointOpCreateNewMachine, enableMachineRegistration)
 
-	// Create DNS Manager.
-	dnsManager := dnsop.NewManager(ss)
-
 	// setup ReST API service
 	r, err := restservice.NewRestAPI(&ss.RestAPISettings, &ss.DBSettings,
 		ss.DB, ss.Agents, ss.EventCenter,
@@ -239,6 +261,9 @@ func (ss *StorkServer) Bootstrap(reload bool) (err error) {
 		ss.DHCPOptionDefinitionLookup, ss.HookManager, endpointControl,
 		dnsManager)
 	if err != nil {
+		ss.ScheduledConfigChangesExecutor.Shutdown()
+		ss.Pullers.SerialDriftPuller.Shutdown()
+		ss.Pullers.DNSSECPuller.Shutdown()
 		ss.Pullers.HAStatusPuller.Shutdown()
 		ss.Pullers.KeaHostsPuller.Shutdown()
 		ss.Pullers.KeaStatsPuller.Shutdown()
@@ -286,6 +311,9 @@ func (ss *StorkServer) Shutdown(reload bool) {
 			ss.EventCenter.AddInfoEvent("shutting down Stork Server")
 			log.Println("Shutting down Stork Server")
 		}
+		ss.ScheduledConfigChangesExecutor.Shutdown()
+		ss.Pullers.SerialDriftPuller.Shutdown()
+		ss.Pullers.DNSSECPuller.Shutdown()
 		ss.Pullers.HAStatusPuller.Shutdown()
 		ss.Pullers.KeaHostsPuller.Shutdown()
 		ss.Pullers.KeaStatsPuller.Shutdown()
@@ -328,3 +356,8 @@ func (ss *StorkServer) GetDHCPOptionDefinitionLookup() keaconfig.DHCPOptionDefin
 func (ss *StorkServer) GetDaemonLocker() config.DaemonLocker {
 	return ss.DaemonLocker
 }
+
+// Returns an interface to the event center.
+func (ss *StorkServer) GetEventCenter() eventcenter.EventCenter {
+	return ss.EventCenter
+}
@@ -0,0 +1,140 @@
+package storkutil
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Type of the change between two JSON documents.
+type JSONDiffOperation string
+
+const (
+	// The value exists only in the second document.
+	JSONDiffOperationAdd JSONDiffOperation = "add"
+	// The value exists only in the first document.
+	JSONDiffOperationRemove JSONDiffOperation = "remove"
+	// The value exists in both documents but it differs.
+	JSONDiffOperationReplace JSONDiffOperation = "replace"
+)
+
+// Represents a single difference between two JSON documents. The path is
+// the JSON pointer (RFC 6901) to the changed value. The Before value is
+// nil for the added values. The After value is nil for the removed values.
+// A key set to null is distinct from a missing key, so replacing null
+// with a value is reported as a replacement rather than an addition.
+type JSONDiffEntry struct {
+	Path      string            `json:"path"`
+	Operation JSONDiffOperation `json:"op"`
+	Before    any               `json:"before,omitempty"`
+	After     any               `json:"after,omitempty"`
+}
+
+// Computes a structural difference between two values serializable to
+// JSON. Both values are first converted to their generic JSON representation
+// (maps, slices and scalars), so they can be of different types as long as
+// their JSON forms are comparable. The maps are compared key by key, and the
+// arrays are compared element by element at the same positions. The changes
+// are returned in a deterministic order, with the map keys sorted
+// alphabetically. The nil before or after value denotes a missing document.
+func JSONDiff(before, after any) ([]JSONDiffEntry, error) {
+	normalizedBefore, err := normalizeJSONValue(before)
+	if err != nil {
+		return nil, err
+	}
+	normalizedAfter, err := normalizeJSONValue(after)
+	if err != nil {
+		return nil, err
+	}
+	var entries []JSONDiffEntry
+	diffJSONValues("", normalizedBefore, before != nil, normalizedAfter, after != nil, &entries)
+	return entries, nil
+}
+
+// Converts the value to its generic JSON representation.
+func normalizeJSONValue(value any) (any, error) {
+	if value == nil {
+		return nil, nil
+	}
+	marshalled, err := json.Marshal(value)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal the value to compare")
+	}
+	var normalized any
+	if err = json.Unmarshal(marshalled, &normalized); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal the value to compare")
+	}
+	return normalized, nil
+}
+
+// Escapes the map key to be used as a JSON pointer token.
+func escapeJSONPointerToken(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1")
+}
+
+// Recursively compares two normalized JSON values and appends the found
+// differences to the entries. The flags indicate whether the values are
+// present in the respective documents, which distinguishes the null values
+// from the missing ones.
+func diffJSONValues(path string, before any, beforeExists bool, after any, afterExists bool, entries *[]JSONDiffEntry) {
+	switch {
+	case !beforeExists && !afterExists:
+		return
+	case !beforeExists:
+		*entries = append(*entries, JSONDiffEntry{Path: path, Operation: JSONDiffOperationAdd, After: after})
+		return
+	case !afterExists:
+		*entries = append(*entries, JSONDiffEntry{Path: path, Operation: JSONDiffOperationRemove, Before: before})
+		return
+	}
+	switch beforeValue := before.(type) {
+	case map[string]any:
+		afterValue, ok := after.(map[string]any)
+		if !ok {
+			break
+		}
+		keys := make(map[string]bool)
+		for key := range beforeValue {
+			keys[key] = true
+		}
+		for key := range afterValue {
+			keys[key] = true
+		}
+		sortedKeys := make([]string, 0, len(keys))
+		for key := range keys {
+			sortedKeys = append(sortedKeys, key)
+		}
+		sort.Strings(sortedKeys)
+		for _, key := range sortedKeys {
+			beforeElement, beforeElementExists := beforeValue[key]
+			afterElement, afterElementExists := afterValue[key]
+			diffJSONValues(path+"/"+escapeJSONPointerToken(key), beforeElement, beforeElementExists, afterElement, afterElementExists, entries)
+		}
+		return
+	case []any:
+		afterValue, ok := after.([]any)
+		if !ok {
+			break
+		}
+		for i := 0; i < len(beforeValue) || i < len(afterValue); i++ {
+			var beforeElement, afterElement any
+			if i < len(beforeValue) {
+				beforeElement = beforeValue[i]
+			}
+			if i < len(afterValue) {
+				afterElement = afterValue[i]
+			}
+			diffJSONValues(fmt.Sprintf("%s/%d", path, i), beforeElement, i < len(beforeValue), afterElement, i < len(afterValue), entries)
+		}
+		return
+	default:
+		if reflect.DeepEqual(before, after) {
+			return
+		}
+	}
+	*entries = append(*entries, JSONDiffEntry{Path: path, Operation: JSONDiffOperationReplace, Before: before, After: after})
+}
@@ -0,0 +1,147 @@
+package storkutil
+
+import (
+	"testing"
+
+	require "github.com/stretchr/testify/require"
+)
+
+// Test that no differences are returned for equal values.
+func TestJSONDiffEqual(t *testing.T) {
+	diff, err := JSONDiff(
+		map[string]any{"foo": []int{1, 2}, "bar": map[string]any{"baz": "qux"}},
+		map[string]any{"foo": []int{1, 2}, "bar": map[string]any{"baz": "qux"}},
+	)
+	require.NoError(t, err)
+	require.Empty(t, diff)
+}
+
+// Test that the added, removed and replaced values are detected in nested maps.
+func TestJSONDiffMaps(t *testing.T) {
+	diff, err := JSONDiff(
+		map[string]any{"foo": 1, "bar": map[string]any{"baz": "qux", "a/b": true}},
+		map[string]any{"bar": map[string]any{"baz": "quux", "c~d": false}, "foo": 1, "new": "value"},
+	)
+	require.NoError(t, err)
+	require.Len(t, diff, 4)
+
+	require.Equal(t, "/bar/a~1b", diff[0].Path)
+	require.Equal(t, JSONDiffOperationRemove, diff[0].Operation)
+	require.Equal(t, true, diff[0].Before)
+	require.Nil(t, diff[0].After)
+
+	require.Equal(t, "/bar/baz", diff[1].Path)
+	require.Equal(t, JSONDiffOperationReplace, diff[1].Operation)
+	require.Equal(t, "qux", diff[1].Before)
+	require.Equal(t, "quux", diff[1].After)
+
+	require.Equal(t, "/bar/c~0d", diff[2].Path)
+	require.Equal(t, JSONDiffOperationAdd, diff[2].Operation)
+	require.Nil(t, diff[2].Before)
+	require.Equal(t, false, diff[2].After)
+
+	require.Equal(t, "/new", diff[3].Path)
+	require.Equal(t, JSONDiffOperationAdd, diff[3].Operation)
+	require.Equal(t, "value", diff[3].After)
+}
+
+// Test that the arrays are compared element by element.
+func TestJSONDiffArrays(t *testing.T) {
+	diff, err := JSONDiff(
+		map[string]any{"list": []any{1, map[string]any{"name": "foo"}, 3}},
+		map[string]any{"list": []any{1, map[string]any{"name": "bar"}}},
+	)
+	require.NoError(t, err)
+	require.Len(t, diff, 2)
+
+	require.Equal(t, "/list/1/name", diff[0].Path)
+	require.Equal(t, JSONDiffOperationReplace, diff[0].Operation)
+	require.Equal(t, "foo", diff[0].Before)
+	require.Equal(t, "bar", diff[0].After)
+
+	require.Equal(t, "/list/2", diff[1].Path)
+	require.Equal(t, JSONDiffOperationRemove, diff[1].Operation)
+	require.EqualValues(t, 3, diff[1].Before)
+}
+
+// Test that a value changing its type is reported as replaced.
+func TestJSONDiffTypeChange(t *testing.T) {
+	diff, err := JSONDiff(
+		map[string]any{"foo": []any{1}},
+		map[string]any{"foo": map[string]any{"bar": 1}},
+	)
+	require.NoError(t, err)
+	require.Len(t, diff, 1)
+	require.Equal(t, "/foo", diff[0].Path)
+	require.Equal(t, JSONDiffOperationReplace, diff[0].Operation)
+}
+
+// Test that the whole document is reported as added or removed when
+// one of the compared values is nil.
+func TestJSONDiffNil(t *testing.T) {
+	diff, err := JSONDiff(nil, map[string]any{"foo": "bar"})
+	require.NoError(t, err)
+	require.Len(t, diff, 1)
+	require.Empty(t, diff[0].Path)
+	require.Equal(t, JSONDiffOperationAdd, diff[0].Operation)
+
+	diff, err = JSONDiff(map[string]any{"foo": "bar"}, nil)
+	require.NoError(t, err)
+	require.Len(t, diff, 1)
+	require.Empty(t, diff[0].Path)
+	require.Equal(t, JSONDiffOperationRemove, diff[0].Operation)
+}
+
+// Test that the null value is distinguished from the missing key.
+func TestJSONDiffNullValue(t *testing.T) {
+	diff, err := JSONDiff(map[string]any{"foo": nil}, map[string]any{"foo": "bar"})
+	require.NoError(t, err)
+	require.Len(t, diff, 1)
+	require.Equal(t, "/foo", diff[0].Path)
+	require.Equal(t, JSONDiffOperationReplace, diff[0].Operation)
+	require.Nil(t, diff[0].Before)
+	require.Equal(t, "bar", diff[0].After)
+
+	diff, err = JSONDiff(map[string]any{}, map[string]any{"foo": nil})
+	require.NoError(t, err)
+	require.Len(t, diff, 1)
+	require.Equal(t, "/foo", diff[0].Path)
+	require.Equal(t, JSONDiffOperationAdd, diff[0].Operation)
+
+	diff, err = JSONDiff(map[string]any{"foo": nil}, map[string]any{})
+	require.NoError(t, err)
+	require.Len(t, diff, 1)
+	require.Equal(t, "/foo", diff[0].Path)
+	require.Equal(t, JSONDiffOperationRemove, diff[0].Operation)
+
+	diff, err = JSONDiff([]any{nil}, []any{nil, nil})
+	require.NoError(t, err)
+	require.Len(t, diff, 1)
+	require.Equal(t, "/1", diff[0].Path)
+	require.Equal(t, JSONDiffOperationAdd, diff[0].Operation)
+
+	diff, err = JSONDiff(map[string]any{"foo": nil}, map[string]any{"foo": nil})
+	require.NoError(t, err)
+	require.Empty(t, diff)
+}
+
+// Test that the values of different Go types are compared using their
+// JSON representations.
+func TestJSONDiffDifferentTypes(t *testing.T) {
+	type testStruct struct {
+		Foo string `json:"foo"`
+		Bar int    `json:"bar"`
+	}
+	diff, err := JSONDiff(testStruct{Foo: "baz", Bar: 1}, map[string]any{"foo": "baz", "bar": 2})
+	require.NoError(t, err)
+	require.Len(t, diff, 1)
+	require.Equal(t, "/bar", diff[0].Path)
+	require.EqualValues(t, 1, diff[0].Before)
+	require.EqualValues(t, 2, diff[0].After)
+}
+
+// Test that an error is returned for a value that cannot be marshalled.
+func TestJSONDiffMarshalError(t *testing.T) {
+	_, err := JSONDiff(map[string]any{"foo": make(chan int)}, nil)
+	require.Error(t, err)
+}
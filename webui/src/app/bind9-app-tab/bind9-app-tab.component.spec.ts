@@ -29,6 +29,12 @@ import { TableModule } from 'primeng/table'
 import { VersionStatusComponent } from '../version-status/version-status.component'
 import { Severity, VersionService } from '../version.service'
 import { provideHttpClient, withInterceptorsFromDi } from '@angular/common/http'
+import { ConfigReviewPanelComponent } from '../config-review-panel/config-review-panel.component'
+import { HelpTipComponent } from '../help-tip/help-tip.component'
+import { ButtonModule } from 'primeng/button'
+import { ToggleButtonModule } from 'primeng/togglebutton'
+import { DividerModule } from 'primeng/divider'
+import { TagModule } from 'primeng/tag'
 
 class Daemon {
     name = 'named'
@@ -79,6 +85,8 @@ describe('Bind9AppTabComponent', () => {
                 EventsPanelComponent,
                 EventTextComponent,
                 VersionStatusComponent,
+                ConfigReviewPanelComponent,
+                HelpTipComponent,
             ],
             imports: [
                 FormsModule,
@@ -91,6 +99,10 @@ describe('Bind9AppTabComponent', () => {
                 OverlayPanelModule,
                 DataViewModule,
                 TableModule,
+                ButtonModule,
+                ToggleButtonModule,
+                DividerModule,
+                TagModule,
             ],
             providers: [
                 UsersService,
@@ -230,6 +230,38 @@ export class ConfigCheckerPreferencePickerComponent {
                     'unavailable or inaccurate due to a number overflow in ' +
                     'the statistics returned by the Kea DHCP daemon.'
                 )
+            case 'duplicated_host_identifiers':
+                return (
+                    'This checker detects whether the same host identifiers are used ' +
+                    'in multiple host reservations in different subnets or servers.'
+                )
+            case 'duplicated_reserved_addresses':
+                return (
+                    'This checker detects whether the same IP addresses or delegated ' +
+                    'prefixes are reserved in multiple host reservations.'
+                )
+            case 'duplicated_reserved_hostnames':
+                return 'This checker detects whether the same hostnames are reserved in multiple host reservations.'
+            case 'lease_lifetimes_and_timers':
+                return 'This checker verifies that the effective renew and rebind timers, valid and preferred lifetimes are consistent in the global, shared network and subnet scopes.'
+            case 'invalid_option_data':
+                return 'This checker validates the option data specified at all configuration scopes and in the host reservations against the standard and custom option definitions.'
+            case 'ha_peers_consistency':
+                return 'This checker compares the configurations of the High Availability partners and reports differences in the subnets, pools, shared networks, option data and host reservations, as well as inconsistent peers lists.'
+            case 'invalid_client_classes':
+                return 'This checker parses the test and template-test expressions of the client classes. It reports syntax errors, references to undefined classes or to classes defined later, and misuse of the only-if-required classes.'
+            case 'bind9_open_recursion':
+                return 'This checker verifies if the BIND 9 server allows recursive queries from any client. An open resolver can be abused in DNS amplification attacks.'
+            case 'bind9_allow_transfer_any':
+                return 'This checker verifies if the BIND 9 server allows zone transfers to any client.'
+            case 'bind9_unsigned_transfers':
+                return 'This checker verifies if the BIND 9 server allows zone transfers to clients identified by their IP addresses rather than TSIG keys.'
+            case 'bind9_notify_consistency':
+                return 'This checker verifies if the notify and also-notify settings of the BIND 9 zones are consistent, e.g., it reports zones with notify explicit and no also-notify servers.'
+            case 'bind9_statistics_channels_exposure':
+                return 'This checker verifies if the BIND 9 statistics channels are exposed to any client on a non-loopback address.'
+            case 'bind9_deprecated_options':
+                return 'This checker verifies if the BIND 9 configuration contains deprecated options that will be removed in future BIND 9 releases.'
             default:
                 return ''
         }
@@ -71,12 +71,15 @@ describe('SettingsPageComponent', () => {
         expect(component).toBeTruthy()
         expect(component.settingsForm.get('appsStatePullerInterval')?.value).toBe(0)
         expect(component.settingsForm.get('bind9StatsPullerInterval')?.value).toBe(0)
+        expect(component.settingsForm.get('dnssecPullerInterval')?.value).toBe(0)
         expect(component.settingsForm.get('grafanaUrl')?.value).toBe('')
         expect(component.settingsForm.get('grafanaDhcp4DashboardId')?.value).toBe('hRf18FvWz')
         expect(component.settingsForm.get('grafanaDhcp6DashboardId')?.value).toBe('AQPHKJUGz')
         expect(component.settingsForm.get('keaHostsPullerInterval')?.value).toBe(0)
         expect(component.settingsForm.get('keaStatsPullerInterval')?.value).toBe(0)
         expect(component.settingsForm.get('keaStatusPullerInterval')?.value).toBe(0)
+        expect(component.settingsForm.get('zoneSerialDriftPullerInterval')?.value).toBe(0)
+        expect(component.settingsForm.get('zoneSerialDriftThreshold')?.value).toBe(0)
         expect(component.settingsForm.get('enableMachineRegistration')?.value).toBeFalse()
         expect(component.settingsForm.get('enableOnlineSoftwareVersions')?.value).toBeFalse()
     })
@@ -103,12 +106,15 @@ describe('SettingsPageComponent', () => {
         const settings: any = {
             appsStatePullerInterval: 28,
             bind9StatsPullerInterval: 29,
+            dnssecPullerInterval: 33,
             grafanaUrl: 'http://localhost:1234',
             grafanaDhcp4DashboardId: 'dhcp4',
             grafanaDhcp6DashboardId: 'dhcp6',
             keaHostsPullerInterval: 30,
             keaStatsPullerInterval: 31,
             keaStatusPullerInterval: 32,
+            zoneSerialDriftPullerInterval: 34,
+            zoneSerialDriftThreshold: 35,
             enableMachineRegistration: true,
             enableOnlineSoftwareVersions: true,
         }
@@ -120,6 +126,7 @@ describe('SettingsPageComponent', () => {
         expect(settingsApi.getSettings).toHaveBeenCalled()
         expect(component.settingsForm.get('appsStatePullerInterval')?.value).toBe(28)
         expect(component.settingsForm.get('bind9StatsPullerInterval')?.value).toBe(29)
+        expect(component.settingsForm.get('dnssecPullerInterval')?.value).toBe(33)
         expect(component.settingsForm.get('grafanaUrl')?.value).toBe('http://localhost:1234')
         expect(component.settingsForm.get('grafanaDhcp4DashboardId')?.value).toBe('dhcp4')
         expect(component.settingsForm.get('grafanaDhcp6DashboardId')?.value).toBe('dhcp6')
@@ -127,6 +134,8 @@ describe('SettingsPageComponent', () => {
         expect(component.settingsForm.get('keaHostsPullerInterval')?.value).toBe(30)
         expect(component.settingsForm.get('keaStatsPullerInterval')?.value).toBe(31)
         expect(component.settingsForm.get('keaStatusPullerInterval')?.value).toBe(32)
+        expect(component.settingsForm.get('zoneSerialDriftPullerInterval')?.value).toBe(34)
+        expect(component.settingsForm.get('zoneSerialDriftThreshold')?.value).toBe(35)
         expect(component.settingsForm.get('enableMachineRegistration')?.value).toBeTrue()
         expect(component.settingsForm.get('enableOnlineSoftwareVersions')?.value).toBeTrue()
     }))
@@ -158,24 +167,30 @@ describe('SettingsPageComponent', () => {
         const settings: any = {
             appsStatePullerInterval: 28,
             bind9StatsPullerInterval: 29,
+            dnssecPullerInterval: 33,
             grafanaUrl: 'http://localhost:1234',
             grafanaDhcp4DashboardId: 'dhcp4',
             grafanaDhcp6DashboardId: 'dhcp6',
             keaHostsPullerInterval: 30,
             keaStatsPullerInterval: 31,
             keaStatusPullerInterval: 32,
+            zoneSerialDriftPullerInterval: 34,
+            zoneSerialDriftThreshold: 35,
             enableMachineRegistration: true,
             enableOnlineSoftwareVersions: true,
         }
         const updatedSettings: any = {
             appsStatePullerInterval: 13,
             bind9StatsPullerInterval: 13,
+            dnssecPullerInterval: 13,
             grafanaUrl: 'http://localhost:4234',
             grafanaDhcp4DashboardId: 'dhcp4',
             grafanaDhcp6DashboardId: 'dhcp6',
             keaHostsPullerInterval: 13,
             keaStatsPullerInterval: 13,
             keaStatusPullerInterval: 13,
+            zoneSerialDriftPullerInterval: 13,
+            zoneSerialDriftThreshold: 13,
             enableMachineRegistration: false,
             enableOnlineSoftwareVersions: false,
         }
@@ -196,9 +211,12 @@ describe('SettingsPageComponent', () => {
         const settings: any = {
             appsStatePullerInterval: null,
             bind9StatsPullerInterval: null,
+            dnssecPullerInterval: null,
             keaHostsPullerInterval: null,
             keaStatsPullerInterval: null,
             keaStatusPullerInterval: null,
+            zoneSerialDriftPullerInterval: null,
+            zoneSerialDriftThreshold: null,
         }
         spyOn(settingsApi, 'getSettings').and.returnValue(of(settings))
         spyOn(settingsApi, 'updateSettings').and.callThrough()
@@ -24,6 +24,7 @@ import { InputTextModule } from 'primeng/inputtext'
 
 let mockGetSettingsResponse: Settings = {
     bind9StatsPullerInterval: 10,
+    dnssecPullerInterval: 3600,
     grafanaUrl: 'http://grafana.org',
     grafanaDhcp4DashboardId: 'dhcp4',
     grafanaDhcp6DashboardId: 'dhcp6',
@@ -31,6 +32,8 @@ let mockGetSettingsResponse: Settings = {
     keaStatsPullerInterval: 15,
     keaStatusPullerInterval: 23,
     appsStatePullerInterval: 44,
+    zoneSerialDriftPullerInterval: 60,
+    zoneSerialDriftThreshold: 3600,
     enableMachineRegistration: true,
 }
 
@@ -12,9 +12,12 @@ import { getErrorMessage } from '../utils'
 interface SettingsForm {
     appsStatePullerInterval: FormControl<number>
     bind9StatsPullerInterval: FormControl<number>
+    dnssecPullerInterval: FormControl<number>
     keaHostsPullerInterval: FormControl<number>
     keaStatsPullerInterval: FormControl<number>
     keaStatusPullerInterval: FormControl<number>
+    zoneSerialDriftPullerInterval: FormControl<number>
+    zoneSerialDriftThreshold: FormControl<number>
     grafanaUrl: FormControl<string>
     grafanaDhcp4DashboardId: FormControl<string>
     grafanaDhcp6DashboardId: FormControl<string>
@@ -64,6 +67,11 @@ export class SettingsPageComponent implements OnInit {
             formControlName: 'bind9StatsPullerInterval',
             help: 'This puller refreshes statistics from the BIND 9 servers.',
         },
+        {
+            title: 'DNSSEC Puller Interval',
+            formControlName: 'dnssecPullerInterval',
+            help: 'This puller checks the DNSSEC state of the zones, transferring the zones that changed or whose signatures expire soon.',
+        },
         {
             title: 'Kea Hosts Puller Interval',
             formControlName: 'keaHostsPullerInterval',
@@ -79,6 +87,16 @@ export class SettingsPageComponent implements OnInit {
             formControlName: 'keaStatusPullerInterval',
             help: 'This puller fetches the high-availability status from the Kea servers.',
         },
+        {
+            title: 'Zone Serial Drift Puller Interval',
+            formControlName: 'zoneSerialDriftPullerInterval',
+            help: 'This puller compares the serials of the zones served by the primary and secondary DNS servers.',
+        },
+        {
+            title: 'Zone Serial Drift Threshold',
+            formControlName: 'zoneSerialDriftThreshold',
+            help: 'A secondary zone lagging behind the primary zone for longer than this time is reported as drifted.',
+        },
     ]
 
     /**
@@ -139,9 +157,12 @@ export class SettingsPageComponent implements OnInit {
         this.settingsForm = this.fb.group({
             appsStatePullerInterval: [0, [Validators.required, Validators.min(0)]],
             bind9StatsPullerInterval: [0, [Validators.required, Validators.min(0)]],
+            dnssecPullerInterval: [0, [Validators.required, Validators.min(0)]],
             keaHostsPullerInterval: [0, [Validators.required, Validators.min(0)]],
             keaStatsPullerInterval: [0, [Validators.required, Validators.min(0)]],
             keaStatusPullerInterval: [0, [Validators.required, Validators.min(0)]],
+            zoneSerialDriftPullerInterval: [0, [Validators.required, Validators.min(0)]],
+            zoneSerialDriftThreshold: [0, [Validators.required, Validators.min(0)]],
             grafanaUrl: [''],
             grafanaDhcp4DashboardId: ['hRf18FvWz'],
             grafanaDhcp6DashboardId: ['AQPHKJUGz'],